@@ -41,6 +41,13 @@ func main() {
 		}
 	}()
 
+	// 启动管理接口
+	go func() {
+		if err := app.HTTPServer.Start(ctx); err != nil {
+			logger.Logger.Error("http server error", zap.Error(err))
+		}
+	}()
+
 	logger.Logger.Info("scan service started")
 
 	// 优雅停机处理
@@ -48,6 +55,7 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	app.HTTPServer.Stop(shutdownCtx)
 	app.ScanService.Stop(shutdownCtx)
 	logger.Logger.Info("service stopped gracefully")
 }
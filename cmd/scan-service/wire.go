@@ -5,8 +5,10 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/scan/service"
+	"github.com/blackarbiter/go-sac/internal/scan/transport/http"
 	"github.com/blackarbiter/go-sac/pkg/cache/redis"
 	"github.com/blackarbiter/go-sac/pkg/config"
 	"github.com/blackarbiter/go-sac/pkg/domain"
@@ -15,12 +17,15 @@ import (
 	"github.com/blackarbiter/go-sac/pkg/mq/rabbitmq"
 	"github.com/blackarbiter/go-sac/pkg/scanner"
 	scanner_impl "github.com/blackarbiter/go-sac/pkg/scanner/impl"
+	"github.com/blackarbiter/go-sac/pkg/storage/minio"
 	"github.com/google/wire"
+	"go.uber.org/zap"
 )
 
 // Application 聚合所有核心组件
 type Application struct {
 	ScanService *service.ScanService
+	HTTPServer  *http.Server
 }
 
 var (
@@ -31,13 +36,15 @@ var (
 
 		// 服务组件
 		service.ProviderSet,
+		http.ProviderSet,
 
 		// 基础设施组件
 		provideConnectionManager,
 		provideMetrics,
 		provideTimeoutController,
 		provideRedisConnector,
-		provideCircuitBreaker,
+		provideCircuitBreakerRegistry,
+		provideLogUploader,
 		wire.Bind(new(scanner.ScannerFactory), new(*scanner.ScannerFactoryImpl)),
 		provideScannerFactory,
 	)
@@ -56,8 +63,11 @@ func provideMetrics() *metrics.ScannerMetrics {
 }
 
 // provideTimeoutController 提供超时控制器
-func provideTimeoutController(metrics *metrics.ScannerMetrics) *scanner.TimeoutController {
-	return scanner.NewTimeoutController(metrics)
+func provideTimeoutController(metrics *metrics.ScannerMetrics, cfg *config.Config) *scanner.TimeoutController {
+	tc := scanner.NewTimeoutController(metrics)
+	tc.SetPolicy(cfg.GetTimeoutPolicy())
+	tc.SetWatchdogSettings(cfg.GetWatchdogSettings())
+	return tc
 }
 
 // provideRedisConnector 提供 Redis 连接器
@@ -71,23 +81,61 @@ func provideRedisConnector(cfg *config.Config) (*redis.Connector, error) {
 	)
 }
 
-func provideCircuitBreaker(cfg *config.Config) *scanner.CircuitBreaker {
-	threshold, criticalThreshold, resetTimeout := cfg.GetCircuitBreakerConfig()
-	return scanner.NewCircuitBreaker(threshold, criticalThreshold, resetTimeout)
+// provideCircuitBreakerRegistry 提供按扫描类型隔离的熔断器，由扫描器工厂和扫描器共用
+func provideCircuitBreakerRegistry(cfg *config.Config) *scanner.CircuitBreakerRegistry {
+	return scanner.NewCircuitBreakerRegistry(cfg.GetCircuitBreakerSettings)
+}
+
+// provideLogUploader 提供扫描命令输出的上传客户端，未启用或 MinIO 不可用时只保留输出尾部
+func provideLogUploader(cfg *config.Config) scanner_impl.LogUploader {
+	if !cfg.GetOutputCaptureConfig().Upload {
+		return nil
+	}
+	mc := cfg.Storage.MinIO
+	client, err := minio.NewClient(minio.ClientConfig{
+		Endpoint:       mc.Endpoint,
+		AccessKey:      mc.AccessKey,
+		SecretKey:      mc.SecretKey,
+		UseSSL:         mc.UseSSL,
+		RequestTimeout: 10 * time.Second,
+		DefaultBucket:  mc.Bucket,
+	}, logger.Logger)
+	if err != nil {
+		logger.Logger.Warn("minio client unavailable, command output upload disabled", zap.Error(err))
+		return nil
+	}
+	return client
 }
 
 // provideScannerFactory provides a scanner factory with default scanners
 func provideScannerFactory(
 	timeoutCtrl *scanner.TimeoutController,
 	metrics *metrics.ScannerMetrics,
-	circuitBreaker *scanner.CircuitBreaker,
+	breakers *scanner.CircuitBreakerRegistry,
+	uploader scanner_impl.LogUploader,
 	cfg *config.Config,
 ) *scanner.ScannerFactoryImpl {
-	return scanner.NewScannerFactory(
+	cgroup := scanner_impl.NewCgroupManagerFromConfig(cfg, logger.Logger)
+	factory := scanner.NewScannerFactory(
 		func() map[domain.ScanType]scanner.TaskExecutor {
-			return scanner_impl.CreateDefaultScanners(timeoutCtrl, logger.Logger, metrics, nil, cfg)
-		}, metrics, circuitBreaker,
+			return scanner_impl.CreateDefaultScanners(timeoutCtrl, logger.Logger, metrics, cgroup, cfg,
+				scanner_impl.WithCircuitBreakerRegistry(breakers),
+				scanner_impl.WithLogUploader(uploader),
+			)
+		}, metrics, breakers,
 	)
+
+	// 发现并热注册外部扫描插件
+	if pluginCfg := cfg.GetPluginConfig(); pluginCfg.Enabled {
+		source := scanner_impl.NewPluginDirectory(timeoutCtrl, logger.Logger, cfg,
+			scanner_impl.WithMetricsRecorder(metrics),
+			scanner_impl.WithCgroupManager(cgroup),
+			scanner_impl.WithCircuitBreakerRegistry(breakers),
+			scanner_impl.WithLogUploader(uploader),
+		)
+		factory.WatchPlugins(context.Background(), source, pluginCfg.DiscoveryInterval)
+	}
+	return factory
 }
 
 // InitializeApplication 通过Wire自动生成
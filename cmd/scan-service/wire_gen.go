@@ -9,6 +9,7 @@ package main
 import (
 	"context"
 	"github.com/blackarbiter/go-sac/internal/scan/service"
+	"github.com/blackarbiter/go-sac/internal/scan/transport/http"
 	"github.com/blackarbiter/go-sac/pkg/cache/redis"
 	"github.com/blackarbiter/go-sac/pkg/config"
 	"github.com/blackarbiter/go-sac/pkg/domain"
@@ -17,7 +18,10 @@ import (
 	"github.com/blackarbiter/go-sac/pkg/mq/rabbitmq"
 	"github.com/blackarbiter/go-sac/pkg/scanner"
 	"github.com/blackarbiter/go-sac/pkg/scanner/impl"
+	"github.com/blackarbiter/go-sac/pkg/storage/minio"
 	"github.com/google/wire"
+	"go.uber.org/zap"
+	"time"
 )
 
 // Injectors from wire.go:
@@ -26,9 +30,10 @@ import (
 func InitializeApplication(cfg *config.Config) (*Application, func(), error) {
 	connectionManager := provideConnectionManager(cfg)
 	scannerMetrics := provideMetrics()
-	timeoutController := provideTimeoutController(scannerMetrics)
-	circuitBreaker := provideCircuitBreaker(cfg)
-	scannerFactoryImpl := provideScannerFactory(timeoutController, scannerMetrics, circuitBreaker, cfg)
+	timeoutController := provideTimeoutController(scannerMetrics, cfg)
+	circuitBreakerRegistry := provideCircuitBreakerRegistry(cfg)
+	logUploader := provideLogUploader(cfg)
+	scannerFactoryImpl := provideScannerFactory(timeoutController, scannerMetrics, circuitBreakerRegistry, logUploader, cfg)
 	connector, err := provideRedisConnector(cfg)
 	if err != nil {
 		return nil, nil, err
@@ -37,8 +42,10 @@ func InitializeApplication(cfg *config.Config) (*Application, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	server := http.NewServer(cfg, timeoutController)
 	application := &Application{
 		ScanService: scanService,
+		HTTPServer:  server,
 	}
 	return application, func() {
 	}, nil
@@ -49,15 +56,17 @@ func InitializeApplication(cfg *config.Config) (*Application, func(), error) {
 // Application 聚合所有核心组件
 type Application struct {
 	ScanService *service.ScanService
+	HTTPServer  *http.Server
 }
 
 var (
 	// ApplicationSet 是整个应用的依赖集合
-	ApplicationSet = wire.NewSet(wire.Struct(new(Application), "*"), service.ProviderSet, provideConnectionManager,
+	ApplicationSet = wire.NewSet(wire.Struct(new(Application), "*"), service.ProviderSet, http.ProviderSet, provideConnectionManager,
 		provideMetrics,
 		provideTimeoutController,
 		provideRedisConnector,
-		provideCircuitBreaker, wire.Bind(new(scanner.ScannerFactory), new(*scanner.ScannerFactoryImpl)), provideScannerFactory,
+		provideCircuitBreakerRegistry,
+		provideLogUploader, wire.Bind(new(scanner.ScannerFactory), new(*scanner.ScannerFactoryImpl)), provideScannerFactory,
 	)
 )
 
@@ -75,8 +84,11 @@ func provideMetrics() *metrics.ScannerMetrics {
 }
 
 // provideTimeoutController 提供超时控制器
-func provideTimeoutController(metrics2 *metrics.ScannerMetrics) *scanner.TimeoutController {
-	return scanner.NewTimeoutController(metrics2)
+func provideTimeoutController(metrics2 *metrics.ScannerMetrics, cfg *config.Config) *scanner.TimeoutController {
+	tc := scanner.NewTimeoutController(metrics2)
+	tc.SetPolicy(cfg.GetTimeoutPolicy())
+	tc.SetWatchdogSettings(cfg.GetWatchdogSettings())
+	return tc
 }
 
 // provideRedisConnector 提供 Redis 连接器
@@ -88,20 +100,58 @@ func provideRedisConnector(cfg *config.Config) (*redis.Connector, error) {
 	)
 }
 
-func provideCircuitBreaker(cfg *config.Config) *scanner.CircuitBreaker {
-	threshold, criticalThreshold, resetTimeout := cfg.GetCircuitBreakerConfig()
-	return scanner.NewCircuitBreaker(threshold, criticalThreshold, resetTimeout)
+// provideCircuitBreakerRegistry 提供按扫描类型隔离的熔断器，由扫描器工厂和扫描器共用
+func provideCircuitBreakerRegistry(cfg *config.Config) *scanner.CircuitBreakerRegistry {
+	return scanner.NewCircuitBreakerRegistry(cfg.GetCircuitBreakerSettings)
+}
+
+// provideLogUploader 提供扫描命令输出的上传客户端，未启用或 MinIO 不可用时只保留输出尾部
+func provideLogUploader(cfg *config.Config) scanner_impl.LogUploader {
+	if !cfg.GetOutputCaptureConfig().Upload {
+		return nil
+	}
+	mc := cfg.Storage.MinIO
+	client, err := minio.NewClient(minio.ClientConfig{
+		Endpoint:       mc.Endpoint,
+		AccessKey:      mc.AccessKey,
+		SecretKey:      mc.SecretKey,
+		UseSSL:         mc.UseSSL,
+		RequestTimeout: 10 * time.Second,
+		DefaultBucket:  mc.Bucket,
+	}, logger.Logger)
+	if err != nil {
+		logger.Logger.Warn("minio client unavailable, command output upload disabled", zap.Error(err))
+		return nil
+	}
+	return client
 }
 
 // provideScannerFactory provides a scanner factory with default scanners
 func provideScannerFactory(
 	timeoutCtrl *scanner.TimeoutController, metrics2 *metrics.ScannerMetrics,
-	circuitBreaker *scanner.CircuitBreaker,
+	breakers *scanner.CircuitBreakerRegistry,
+	uploader scanner_impl.LogUploader,
 	cfg *config.Config,
 ) *scanner.ScannerFactoryImpl {
-	return scanner.NewScannerFactory(
+	cgroup := scanner_impl.NewCgroupManagerFromConfig(cfg, logger.Logger)
+	factory := scanner.NewScannerFactory(
 		func() map[domain.ScanType]scanner.TaskExecutor {
-			return scanner_impl.CreateDefaultScanners(timeoutCtrl, logger.Logger, metrics2, nil, cfg)
-		}, metrics2, circuitBreaker,
+			return scanner_impl.CreateDefaultScanners(timeoutCtrl, logger.Logger, metrics2, cgroup, cfg,
+				scanner_impl.WithCircuitBreakerRegistry(breakers),
+				scanner_impl.WithLogUploader(uploader),
+			)
+		}, metrics2, breakers,
 	)
+
+	// 发现并热注册外部扫描插件
+	if pluginCfg := cfg.GetPluginConfig(); pluginCfg.Enabled {
+		source := scanner_impl.NewPluginDirectory(timeoutCtrl, logger.Logger, cfg,
+			scanner_impl.WithMetricsRecorder(metrics2),
+			scanner_impl.WithCgroupManager(cgroup),
+			scanner_impl.WithCircuitBreakerRegistry(breakers),
+			scanner_impl.WithLogUploader(uploader),
+		)
+		factory.WatchPlugins(context.Background(), source, pluginCfg.DiscoveryInterval)
+	}
+	return factory
 }
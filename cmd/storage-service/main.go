@@ -67,13 +67,16 @@ func main() {
 	if err := app.MQConsumer.Close(); err != nil {
 		logger.Logger.Error("MQ consumer close error", zap.Error(err))
 	}
+	if err := app.AssetPublisher.Close(); err != nil {
+		logger.Logger.Error("asset publisher close error", zap.Error(err))
+	}
 
 	logger.Logger.Info("rabbitmq stopped gracefully")
 }
 
 // registerStorageProcessors 注册所有结果处理器
 func registerStorageProcessors(app *Application) {
-	app.Factory.RegisterDefaultProcessors(app.Repository)
+	app.Factory.RegisterDefaultProcessors(app.Repository, app.AssetPublisher)
 
 	logger.Logger.Info("all storage processors registered",
 		zap.Strings("processors", []string{
@@ -5,6 +5,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/blackarbiter/go-sac/internal/storage/repository"
 	"github.com/blackarbiter/go-sac/internal/storage/service"
 	"github.com/blackarbiter/go-sac/internal/storage/transport/http"
@@ -24,6 +26,7 @@ type Application struct {
 	Factory        *service.ProcessorFactory
 	Repository     repository.Repository
 	MQConsumer     *rabbitmq.ResultConsumer
+	AssetPublisher *rabbitmq.TaskPublisher
 	StorageHandler *mq.StorageMessageHandler
 }
 
@@ -35,6 +38,7 @@ var (
 		ProvideLogger,
 		ProvideProcessorFactory,
 		ProvideMinIOStorage,
+		ProvideMinIOClient,
 
 		// 类型绑定
 		wire.Bind(new(service.StorageProcessorFactory), new(*service.ProcessorFactory)),
@@ -64,6 +68,18 @@ func ProvideMinIOStorage(cfg *config.Config) (*minio.Storage, error) {
 	)
 }
 
+// ProvideMinIOClient 提供MinIO客户端，用于读取扫描命令输出
+func ProvideMinIOClient(cfg *config.Config, logger *zap.Logger) (*minio.Client, error) {
+	return minio.NewClient(minio.ClientConfig{
+		Endpoint:       cfg.Storage.MinIO.Endpoint,
+		AccessKey:      cfg.Storage.MinIO.AccessKey,
+		SecretKey:      cfg.Storage.MinIO.SecretKey,
+		UseSSL:         cfg.Storage.MinIO.UseSSL,
+		RequestTimeout: 10 * time.Second,
+		DefaultBucket:  cfg.Storage.MinIO.Bucket,
+	}, logger)
+}
+
 // InitializeApplication 通过Wire自动生成
 func InitializeApplication(cfg *config.Config) (*Application, func(), error) {
 	panic(wire.Build(ApplicationSet))
@@ -37,8 +37,8 @@ var (
 )
 
 // ProvideHTTPServer 提供HTTP服务实例
-func ProvideHTTPServer(cfg *config.Config, taskService service.TaskService) *http.Server {
-	return http.NewServer(cfg, taskService)
+func ProvideHTTPServer(cfg *config.Config, taskService service.TaskService, profileService service.ProfileService) *http.Server {
+	return http.NewServer(cfg, taskService, profileService)
 }
 
 // ProvideLogger 提供日志实例
@@ -31,8 +31,12 @@ func InitializeApplication(cfg *config.Config) (*Application, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	taskService := service.ProvideTaskService(taskRepository, taskPublisher)
-	server := ProvideHTTPServer(cfg, taskService)
+	profileRepository := repository.ProvideProfileRepository(db)
+	profileService := service.ProvideProfileService(profileRepository, cfg)
+	pipelineRepository := repository.ProvidePipelineRepository(db)
+	assetRepository := repository.ProvideAssetRepository(db)
+	taskService := service.ProvideTaskService(taskRepository, pipelineRepository, assetRepository, taskPublisher, profileService)
+	server := ProvideHTTPServer(cfg, taskService, profileService)
 	application := &Application{
 		HTTPServer: server,
 		DB:         db,
@@ -57,8 +61,8 @@ var (
 )
 
 // ProvideHTTPServer 提供HTTP服务实例
-func ProvideHTTPServer(cfg *config.Config, taskService service.TaskService) *http.Server {
-	return http.NewServer(cfg, taskService)
+func ProvideHTTPServer(cfg *config.Config, taskService service.TaskService, profileService service.ProfileService) *http.Server {
+	return http.NewServer(cfg, taskService, profileService)
 }
 
 // ProvideLogger 提供日志实例
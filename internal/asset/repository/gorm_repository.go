@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/asset/repository/model"
 	"gorm.io/gorm"
@@ -177,6 +178,17 @@ func (r *GormRepository) GetRepository(ctx context.Context, id uint) (*model.Bas
 	return &base, &ext, nil
 }
 
+// UpdateRepositoryCommit 回写最近扫描的提交，乱序到达的扫描结果不会把资产回退到更早的提交
+func (r *GormRepository) UpdateRepositoryCommit(ctx context.Context, id uint, commit string, commitTime time.Time) error {
+	updates := map[string]interface{}{"last_commit_hash": commit}
+	query := r.db.WithContext(ctx).Model(&model.RepositoryAsset{}).Where("id = ?", id)
+	if !commitTime.IsZero() {
+		updates["last_commit_time"] = commitTime
+		query = query.Where("(last_commit_time IS NULL OR last_commit_time <= ?)", commitTime)
+	}
+	return query.Updates(updates).Error
+}
+
 // 上传文件资产操作实现
 func (r *GormRepository) CreateUploadedFile(ctx context.Context, base *model.BaseAsset, ext *model.UploadedFileAsset) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
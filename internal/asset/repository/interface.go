@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/asset/repository/model"
 )
@@ -29,6 +30,7 @@ type Repository interface {
 	CreateRepository(ctx context.Context, base *model.BaseAsset, ext *model.RepositoryAsset) error
 	UpdateRepository(ctx context.Context, base *model.BaseAsset, ext *model.RepositoryAsset) error
 	GetRepository(ctx context.Context, id uint) (*model.BaseAsset, *model.RepositoryAsset, error)
+	UpdateRepositoryCommit(ctx context.Context, id uint, commit string, commitTime time.Time) error
 
 	// 上传文件资产操作
 	CreateUploadedFile(ctx context.Context, base *model.BaseAsset, ext *model.UploadedFileAsset) error
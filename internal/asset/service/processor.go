@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/asset/repository/model"
 )
@@ -35,6 +36,12 @@ type AssetProcessor interface {
 	Validate(base *model.BaseAsset, extension interface{}) error
 }
 
+// RepositoryCommitUpdater 支持回写最近扫描提交的处理器
+type RepositoryCommitUpdater interface {
+	// UpdateCommit 更新资产最近扫描的提交
+	UpdateCommit(ctx context.Context, id uint, commit string, commitTime time.Time) error
+}
+
 // AssetProcessorFactory 资产处理器工厂接口
 type AssetProcessorFactory interface {
 	// GetProcessor 获取指定类型的处理器
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/asset/dto"
 	"github.com/blackarbiter/go-sac/internal/asset/repository"
@@ -74,6 +75,14 @@ func (p *RepositoryProcessor) Get(ctx context.Context, id uint) (*model.BaseAsse
 	return base, repo, nil
 }
 
+// UpdateCommit 回写扫描服务上报的最近扫描提交
+func (p *RepositoryProcessor) UpdateCommit(ctx context.Context, id uint, commit string, commitTime time.Time) error {
+	if commit == "" {
+		return fmt.Errorf("commit is required")
+	}
+	return p.repo.UpdateRepositoryCommit(ctx, id, commit, commitTime)
+}
+
 // Validate 验证代码仓库资产数据
 func (p *RepositoryProcessor) Validate(base *model.BaseAsset, extension interface{}) error {
 	if err := p.BaseProcessor.Validate(base, nil); err != nil {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/domain"
 	"github.com/blackarbiter/go-sac/pkg/utils/type_parse"
@@ -53,6 +55,8 @@ func (h *AssetMessageHandler) HandleMessage(ctx context.Context, body []byte) er
 		return h.handleUpdate(ctx, processor, assetTaskPayload.AssetType.String(), *data)
 	case "delete":
 		return h.handleDelete(ctx, processor, *data)
+	case domain.AssetOperationCommit:
+		return h.handleCommit(ctx, processor, assetTaskPayload.AssetID, *data)
 	default:
 		return fmt.Errorf("unsupported action: %s", assetTaskPayload.Operation)
 	}
@@ -118,6 +122,33 @@ func (h *AssetMessageHandler) handleUpdate(
 	return processor.Update(ctx, updateMsg.ID, baseAsset, req)
 }
 
+// handleCommit 处理扫描结果回写的最近扫描提交，仅代码仓库资产支持
+func (h *AssetMessageHandler) handleCommit(
+	ctx context.Context,
+	processor service.AssetProcessor,
+	assetID string,
+	payload json.RawMessage,
+) error {
+	updater, ok := processor.(service.RepositoryCommitUpdater)
+	if !ok {
+		return fmt.Errorf("commit update is not supported for this asset type")
+	}
+	id, err := strconv.ParseUint(assetID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid asset id %q: %w", assetID, err)
+	}
+
+	// 解析提交消息
+	var commitMsg struct {
+		LastCommitHash string    `json:"last_commit_hash"`
+		LastCommitTime time.Time `json:"last_commit_time"`
+	}
+	if err := json.Unmarshal(payload, &commitMsg); err != nil {
+		return fmt.Errorf("failed to unmarshal commit message: %w", err)
+	}
+	return updater.UpdateCommit(ctx, uint(id), commitMsg.LastCommitHash, commitMsg.LastCommitTime)
+}
+
 // handleDelete 处理删除操作
 func (h *AssetMessageHandler) handleDelete(
 	ctx context.Context,
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// cancelMarkRetention 取消标记保留时间，超过后认为任务不会再被本实例执行
+const cancelMarkRetention = time.Hour
+
+// CancelHandler 处理任务取消指令
+// 已在本实例执行的任务直接终止；尚在全局队列中的任务打上标记，出队时跳过
+type CancelHandler struct {
+	scannerFactory scanner.ScannerFactory
+	cancelled      sync.Map // taskID -> 取消时间
+}
+
+// NewCancelHandler 创建取消指令处理器
+func NewCancelHandler(scannerFactory scanner.ScannerFactory) *CancelHandler {
+	return &CancelHandler{scannerFactory: scannerFactory}
+}
+
+// HandleMessage 实现消息处理接口
+func (h *CancelHandler) HandleMessage(ctx context.Context, message []byte) error {
+	var payload domain.TaskCancelPayload
+	if err := json.Unmarshal(message, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal cancel payload: %w", err)
+	}
+	if payload.TaskID == "" {
+		return fmt.Errorf("cancel payload missing task_id")
+	}
+
+	h.purge()
+	h.cancelled.Store(payload.TaskID, time.Now())
+
+	// 取消不经过熔断器，直接调用原始扫描器
+	executor, ok := h.scannerFactory.GetAllScanners()[payload.ScanType]
+	if !ok {
+		return nil
+	}
+	if err := executor.Cancel(payload.TaskID); err != nil {
+		if errors.Is(err, scanner.ErrExecutionNotFound) {
+			// 任务不在本实例执行
+			return nil
+		}
+		return fmt.Errorf("cancel task %s failed: %w", payload.TaskID, err)
+	}
+	logger.Logger.Info("Scan task cancelled",
+		zap.String("taskID", payload.TaskID),
+		zap.String("scanType", payload.ScanType.String()))
+	return nil
+}
+
+// TakeCancelled 任务出队时检查并清除取消标记
+func (h *CancelHandler) TakeCancelled(taskID string) bool {
+	_, ok := h.cancelled.LoadAndDelete(taskID)
+	return ok
+}
+
+// purge 清理过期的取消标记
+func (h *CancelHandler) purge() {
+	now := time.Now()
+	h.cancelled.Range(func(key, value any) bool {
+		if now.Sub(value.(time.Time)) > cancelMarkRetention {
+			h.cancelled.Delete(key)
+		}
+		return true
+	})
+}
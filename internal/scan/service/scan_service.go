@@ -29,6 +29,8 @@ type ScanService struct {
 	connManager       *rabbitmq.ConnectionManager
 	scannerFactory    scanner.ScannerFactory
 	scanConsumer      *rabbitmq.ScanConsumer
+	controlConsumer   *rabbitmq.TaskControlConsumer
+	cancelHandler     *CancelHandler
 	resultPublisher   *rabbitmq.ResultPublisher
 	timeoutCtrl       *scanner.TimeoutController
 	metrics           *metrics.ScannerMetrics
@@ -82,6 +84,7 @@ func NewScanService(
 		globalWorkerPool:  make(chan struct{}, maxWorkers),
 		globalTaskQueue:   make(chan func(), queueSize),
 		state:             service.NewSystemState(),
+		cancelHandler:     NewCancelHandler(scannerFactory),
 	}
 	go ss.startGlobalWorkerPool()
 
@@ -142,6 +145,22 @@ func (s *ScanService) Start(ctx context.Context) error {
 		}
 	}
 
+	// 订阅任务取消指令
+	s.controlConsumer, err = rabbitmq.NewTaskControlConsumer(conn)
+	if err != nil {
+		return fmt.Errorf("failed to create task control consumer: %w", err)
+	}
+	if err := s.controlConsumer.ConsumeControl(ctx, s.cancelHandler); err != nil {
+		return fmt.Errorf("failed to consume task control: %w", err)
+	}
+
+	// 巡检卡死的扫描子进程
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.timeoutCtrl.StartWatchdog(ctx)
+	}()
+
 	// 创建带缓冲的通道（大小根据吞吐量配置）
 	scheduler := service.NewPriorityScheduler(s, s.state, s.config)
 	// 将scheduler传递给消费者
@@ -170,6 +189,9 @@ func (s *ScanService) Stop(ctx context.Context) {
 	if s.scanConsumer != nil {
 		s.scanConsumer.Close()
 	}
+	if s.controlConsumer != nil {
+		s.controlConsumer.Close()
+	}
 	if s.resultPublisher != nil {
 		s.resultPublisher.Close()
 	}
@@ -212,6 +234,13 @@ func (s *ScanService) HandleMessage(ctx context.Context, message []byte) error {
 	// 提交任务到全局队列
 	select {
 	case s.globalTaskQueue <- func() {
+		// 排队期间已被取消
+		if s.cancelHandler.TakeCancelled(task.TaskID) {
+			logger.Logger.Info("Skipping cancelled scan task",
+				zap.String("taskID", task.TaskID))
+			return
+		}
+
 		// 获取对应的扫描器
 		scanner, err := s.scannerFactory.GetScanner(task.ScanType)
 		if err != nil {
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles admin HTTP requests
+type Handler struct {
+	timeoutCtrl *scanner.TimeoutController
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(timeoutCtrl *scanner.TimeoutController) *Handler {
+	return &Handler{timeoutCtrl: timeoutCtrl}
+}
+
+// RegisterRoutes registers HTTP routes
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin")
+	{
+		admin.GET("/watchdog", h.handleWatchdogSnapshot)
+	}
+}
+
+// handleWatchdogSnapshot 返回看门狗当前监视的全部扫描子进程
+func (h *Handler) handleWatchdogSnapshot(c *gin.Context) {
+	c.JSON(http.StatusOK, h.timeoutCtrl.WatchdogSnapshot())
+}
@@ -0,0 +1,16 @@
+package dto
+
+// SARIFImportRequest identifies the task a third-party SARIF log belongs to
+type SARIFImportRequest struct {
+	TaskID    string `form:"task_id" binding:"required"`
+	AssetID   string `form:"asset_id" binding:"required"`
+	AssetType string `form:"asset_type" binding:"required"`
+	ScanType  string `form:"scan_type" binding:"required"`
+}
+
+// LatestFindingsRequest selects the latest stored findings of an asset, one result per scan type
+type LatestFindingsRequest struct {
+	AssetID   string   `form:"asset_id" binding:"required"`
+	AssetType string   `form:"asset_type" binding:"required"`
+	ScanTypes []string `form:"scan_type" binding:"required"`
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/blackarbiter/go-sac/internal/storage/repository/model"
 	"gorm.io/gorm"
@@ -23,6 +24,9 @@ func (r *GormRepository) AutoMigrate() error {
 		&model.SASTModel{},
 		&model.DASTModel{},
 		&model.SCAModel{},
+		&model.ImageScanModel{},
+		&model.FindingModel{},
+		&model.ScanHeadModel{},
 	)
 }
 
@@ -118,3 +122,94 @@ func (r *GormRepository) FindSCAByTaskIDs(ctx context.Context, taskIDs []string)
 func (r *GormRepository) UpdateSCA(ctx context.Context, result *model.SCAModel) error {
 	return r.db.WithContext(ctx).Save(result).Error
 }
+
+// Container image operations
+func (r *GormRepository) CreateImageScan(ctx context.Context, result *model.ImageScanModel) error {
+	return r.db.WithContext(ctx).Create(result).Error
+}
+
+func (r *GormRepository) BatchCreateImageScan(ctx context.Context, results []*model.ImageScanModel) error {
+	return r.db.WithContext(ctx).CreateInBatches(results, 100).Error
+}
+
+func (r *GormRepository) FindImageScanByTaskIDs(ctx context.Context, taskIDs []string) ([]*model.ImageScanModel, error) {
+	var results []*model.ImageScanModel
+	err := r.db.WithContext(ctx).Where("task_id IN ?", taskIDs).Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UpdateImageAssetScan updates the image asset extension table owned by the asset service;
+// the extension row shares its primary key with assets_base
+func (r *GormRepository) UpdateImageAssetScan(ctx context.Context, assetID string, digest string, size int64, vulnerabilities string) error {
+	updates := map[string]interface{}{"vulnerabilities": vulnerabilities}
+	if digest != "" {
+		updates["digest"] = digest
+	}
+	if size > 0 {
+		updates["size"] = size
+	}
+	return r.db.WithContext(ctx).Table("assets_image").Where("id = ?", assetID).Updates(updates).Error
+}
+
+// SARIF finding operations
+func (r *GormRepository) BatchCreateFindings(ctx context.Context, findings []*model.FindingModel) error {
+	return r.db.WithContext(ctx).CreateInBatches(findings, 100).Error
+}
+
+func (r *GormRepository) FindFindingsByTaskIDs(ctx context.Context, taskIDs []string) ([]*model.FindingModel, error) {
+	var results []*model.FindingModel
+	err := r.db.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FindPreviousTaskID returns the task recorded before taskID; a redelivered result of the
+// latest task resolves to the task before it
+func (r *GormRepository) FindPreviousTaskID(ctx context.Context, assetID, scanType, taskID string) (string, error) {
+	var head model.ScanHeadModel
+	err := r.db.WithContext(ctx).Where("asset_id = ? AND scan_type = ?", assetID, scanType).First(&head).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if head.TaskID == taskID {
+		return head.PreviousTaskID, nil
+	}
+	return head.TaskID, nil
+}
+
+func (r *GormRepository) RecordScanHead(ctx context.Context, assetID, scanType, taskID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var head model.ScanHeadModel
+		err := tx.Where("asset_id = ? AND scan_type = ?", assetID, scanType).First(&head).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&model.ScanHeadModel{AssetID: assetID, ScanType: scanType, TaskID: taskID}).Error
+		}
+		if err != nil || head.TaskID == taskID {
+			return err
+		}
+		head.PreviousTaskID, head.TaskID = head.TaskID, taskID
+		return tx.Save(&head).Error
+	})
+}
+
+func (r *GormRepository) FindLatestFindings(ctx context.Context, assetID, assetType, scanType string) ([]*model.FindingModel, error) {
+	var latest model.FindingModel
+	err := r.db.WithContext(ctx).Select("task_id").
+		Where("asset_id = ? AND asset_type = ? AND scan_type = ?", assetID, assetType, scanType).
+		Order("id DESC").First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.FindFindingsByTaskIDs(ctx, []string{latest.TaskID})
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// FindingModel represents a single SARIF result ingested from any scanner
+type FindingModel struct {
+	ID          uint   `gorm:"primaryKey"`
+	TaskID      string `gorm:"type:varchar(64);not null;index"`
+	AssetID     string `gorm:"type:varchar(64);not null;index"`
+	AssetType   string `gorm:"type:varchar(32);not null"`
+	ScanType    string `gorm:"type:varchar(32);not null;index"`
+	Tool        string `gorm:"type:varchar(64);not null"`
+	ToolVersion string `gorm:"type:varchar(32)"`
+
+	// SARIF result fields
+	RuleID          string `gorm:"type:varchar(128);index"`
+	RuleName        string `gorm:"type:varchar(256)"`
+	Severity        string `gorm:"type:varchar(16);index"`
+	Message         string `gorm:"type:text"`
+	FilePath        string `gorm:"type:varchar(1024)"`
+	StartLine       int    `gorm:"type:int"`
+	EndLine         int    `gorm:"type:int"`
+	LogicalLocation string `gorm:"type:varchar(512)"`
+	LogicalKind     string `gorm:"type:varchar(32)"`
+	Fingerprint     string `gorm:"type:varchar(64);index"`
+	CWEID           string `gorm:"type:varchar(16)"`
+	Category        string `gorm:"type:varchar(64)"`
+	Remediation     string `gorm:"type:text"`
+	Properties      string `gorm:"type:json"`
+
+	// Incremental tracking against the previous result of the same asset and scan type
+	Status string `gorm:"type:varchar(16);index"`
+	Commit string `gorm:"type:varchar(64)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for FindingModel
+func (FindingModel) TableName() string {
+	return "scan_findings"
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// ImageScanModel represents a package found by the container image scan
+type ImageScanModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	TaskID    string `gorm:"type:varchar(64);not null;index"`
+	AssetID   string `gorm:"type:varchar(64);not null;index"`
+	AssetType string `gorm:"type:varchar(32);not null"`
+	Status    string `gorm:"type:varchar(16);not null"`
+	Error     string `gorm:"type:text"`
+
+	// Image specific fields
+	ImageDigest     string `gorm:"type:varchar(128);index"`
+	OSRelease       string `gorm:"type:varchar(64)"`
+	PackageName     string `gorm:"type:varchar(256)"`
+	PackageVersion  string `gorm:"type:varchar(128)"`
+	SourcePackage   string `gorm:"type:varchar(256)"`
+	PackagePath     string `gorm:"type:text"`
+	Vulnerabilities string `gorm:"type:json"`
+	FixedVersion    string `gorm:"type:varchar(128)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for ImageScanModel
+func (ImageScanModel) TableName() string {
+	return "image_scan_results"
+}
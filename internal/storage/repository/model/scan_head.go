@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ScanHeadModel records the latest ingested task of each asset and scan type, so that the
+// next result is diffed against it even when that task produced no findings
+type ScanHeadModel struct {
+	AssetID        string `gorm:"type:varchar(64);primaryKey"`
+	ScanType       string `gorm:"type:varchar(32);primaryKey"`
+	TaskID         string `gorm:"type:varchar(64);not null"`
+	PreviousTaskID string `gorm:"type:varchar(64)"`
+
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for ScanHeadModel
+func (ScanHeadModel) TableName() string {
+	return "scan_heads"
+}
@@ -31,4 +31,23 @@ type Repository interface {
 	FindSCAByTaskID(ctx context.Context, taskID string) (*model.SCAModel, error)
 	FindSCAByTaskIDs(ctx context.Context, taskIDs []string) ([]*model.SCAModel, error)
 	UpdateSCA(ctx context.Context, result *model.SCAModel) error
+
+	// Container image operations
+	CreateImageScan(ctx context.Context, result *model.ImageScanModel) error
+	BatchCreateImageScan(ctx context.Context, results []*model.ImageScanModel) error
+	FindImageScanByTaskIDs(ctx context.Context, taskIDs []string) ([]*model.ImageScanModel, error)
+	// UpdateImageAssetScan writes the scanned digest, size and vulnerability summary back to the image asset
+	UpdateImageAssetScan(ctx context.Context, assetID string, digest string, size int64, vulnerabilities string) error
+
+	// SARIF finding operations
+	BatchCreateFindings(ctx context.Context, findings []*model.FindingModel) error
+	FindFindingsByTaskIDs(ctx context.Context, taskIDs []string) ([]*model.FindingModel, error)
+	// FindLatestFindings returns the findings of the latest task of the asset and scan type
+	FindLatestFindings(ctx context.Context, assetID, assetType, scanType string) ([]*model.FindingModel, error)
+
+	// Scan head operations
+	// FindPreviousTaskID returns the task ingested before taskID for the asset and scan type, empty for the first scan
+	FindPreviousTaskID(ctx context.Context, assetID, scanType, taskID string) (string, error)
+	// RecordScanHead records taskID as the latest ingested task of the asset and scan type
+	RecordScanHead(ctx context.Context, assetID, scanType, taskID string) error
 }
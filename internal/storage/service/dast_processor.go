@@ -19,28 +19,73 @@ func NewDASTProcessor(repo repository.Repository) *DASTProcessor {
 	return &DASTProcessor{repo: repo}
 }
 
-// Process handles DAST scan results
+// dastResultPayload mirrors the result map emitted by the DAST scanner
+type dastResultPayload struct {
+	Findings []struct {
+		URL         string  `json:"url"`
+		Method      string  `json:"method"`
+		Parameter   string  `json:"parameter"`
+		Payload     string  `json:"payload"`
+		Severity    string  `json:"severity"`
+		VulnType    string  `json:"vuln_type"`
+		CVSSScore   float64 `json:"cvss_score"`
+		Remediation string  `json:"remediation"`
+	} `json:"findings"`
+}
+
+// Process handles DAST scan results, persisting one row per finding
 func (p *DASTProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
-	dastResult := &model.DASTModel{
-		TaskID:    result.TaskID,
-		AssetID:   result.AssetID,
-		AssetType: string(result.AssetType),
-		Status:    result.Status,
-		Error:     result.Error,
+	newRow := func() *model.DASTModel {
+		return &model.DASTModel{
+			TaskID:    result.TaskID,
+			AssetID:   result.AssetID,
+			AssetType: result.AssetType.String(),
+			Status:    result.Status,
+			Error:     result.Error,
+		}
+	}
+
+	if result.Status != "success" {
+		return p.repo.CreateDAST(ctx, newRow())
 	}
 
 	// Parse DAST specific fields from result.Result
-	if result.Status == "success" {
-		jsonBytes, err := json.Marshal(result.Result)
-		if err != nil {
-			return err
-		}
-		if err := json.Unmarshal(jsonBytes, dastResult); err != nil {
-			return err
-		}
+	jsonBytes, err := json.Marshal(result.Result)
+	if err != nil {
+		return err
+	}
+	var payload dastResultPayload
+	if err := json.Unmarshal(jsonBytes, &payload); err != nil {
+		return err
+	}
+
+	// 无发现项时仍保留一条任务状态记录
+	if len(payload.Findings) == 0 {
+		return p.repo.CreateDAST(ctx, newRow())
 	}
 
-	return p.repo.CreateDAST(ctx, dastResult)
+	rows := make([]*model.DASTModel, 0, len(payload.Findings))
+	for _, f := range payload.Findings {
+		row := newRow()
+		row.URL = clip(f.URL, 1024)
+		row.Method = f.Method
+		row.Parameter = clip(f.Parameter, 512)
+		row.Payload = f.Payload
+		row.Severity = f.Severity
+		row.VulnType = f.VulnType
+		row.CVSSScore = f.CVSSScore
+		row.Remediation = f.Remediation
+		rows = append(rows, row)
+	}
+	return p.repo.BatchCreateDAST(ctx, rows)
+}
+
+// clip truncates s to at most n bytes so it fits the column size
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
 }
 
 // GetScanType returns the scan type this processor handles
@@ -48,9 +93,9 @@ func (p *DASTProcessor) GetScanType() domain.ScanType {
 	return domain.ScanTypeDast
 }
 
-// Query retrieves DAST scan results by task ID
+// Query retrieves all DAST findings of a task
 func (p *DASTProcessor) Query(ctx context.Context, taskID string) (interface{}, error) {
-	return p.repo.FindDASTByTaskID(ctx, taskID)
+	return p.repo.FindDASTByTaskIDs(ctx, []string{taskID})
 }
 
 // BatchQuery retrieves DAST scan results by multiple task IDs
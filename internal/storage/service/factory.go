@@ -11,6 +11,7 @@ import (
 // ProcessorFactory 处理器工厂实现
 type ProcessorFactory struct {
 	processors map[string]StorageProcessor
+	findings   *FindingProcessor
 	mu         sync.RWMutex
 }
 
@@ -35,14 +36,39 @@ func (f *ProcessorFactory) RegisterProcessor(scanType domain.ScanType, processor
 	f.processors[scanType.String()] = processor
 }
 
+// GetFindingProcessor returns the processor of the unified SARIF findings table
+func (f *ProcessorFactory) GetFindingProcessor() (*FindingProcessor, error) {
+	if f.findings == nil {
+		return nil, fmt.Errorf("finding processor not registered")
+	}
+	return f.findings, nil
+}
+
 // RegisterDefaultProcessors 注册默认处理器
-func (f *ProcessorFactory) RegisterDefaultProcessors(repo repository.Repository) {
+// assets 用于将扫描到的提交回写到资产服务
+func (f *ProcessorFactory) RegisterDefaultProcessors(repo repository.Repository, assets AssetUpdatePublisher) {
+	// 所有扫描结果中的 SARIF 发现项统一入库
+	f.findings = NewFindingProcessor(repo, assets, domain.ScanTypeUnknown)
+
 	// 注册DAST处理器
-	f.RegisterProcessor(domain.ScanTypeDast, NewDASTProcessor(repo))
+	f.RegisterProcessor(domain.ScanTypeDast, withFindingIngest(NewDASTProcessor(repo), f.findings))
 
 	// 注册SAST处理器
-	f.RegisterProcessor(domain.ScanTypeStaticCodeAnalysis, NewSASTProcessor(repo))
+	f.RegisterProcessor(domain.ScanTypeStaticCodeAnalysis, withFindingIngest(NewSASTProcessor(repo), f.findings))
 
 	// 注册SCA处理器
-	f.RegisterProcessor(domain.ScanTypeSca, NewSCAProcessor(repo))
+	f.RegisterProcessor(domain.ScanTypeSca, withFindingIngest(NewSCAProcessor(repo), f.findings))
+
+	// 注册容器镜像处理器
+	f.RegisterProcessor(domain.ScanTypeContainerImageScan, withFindingIngest(NewImageScanProcessor(repo), f.findings))
+
+	// 无专用结果表的扫描类型仅保存 SARIF 发现项
+	for _, scanType := range []domain.ScanType{
+		domain.ScanTypeRequirementAnalysis,
+		domain.ScanTypeThreatModeling,
+		domain.ScanTypePortScanning,
+		domain.ScanTypeSecretsDetection,
+	} {
+		f.RegisterProcessor(scanType, NewFindingProcessor(repo, assets, scanType))
+	}
 }
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/blackarbiter/go-sac/internal/storage/repository"
+	"github.com/blackarbiter/go-sac/internal/storage/repository/model"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// AssetUpdatePublisher publishes asset updates to the asset service through the asset task queue
+type AssetUpdatePublisher interface {
+	PublishAssetTask(ctx context.Context, operation string, payload []byte) error
+}
+
+// FindingProcessor persists the SARIF findings carried by scan results.
+// It is the default processor for scan types without a dedicated result table
+type FindingProcessor struct {
+	repo     repository.Repository
+	assets   AssetUpdatePublisher
+	scanType domain.ScanType
+}
+
+// NewFindingProcessor creates a new FindingProcessor instance; scanned commits are sent to
+// the asset service through assets
+func NewFindingProcessor(repo repository.Repository, assets AssetUpdatePublisher, scanType domain.ScanType) *FindingProcessor {
+	return &FindingProcessor{repo: repo, assets: assets, scanType: scanType}
+}
+
+// Process handles scan results, persisting one row per SARIF result
+func (p *FindingProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
+	return p.Ingest(ctx, result)
+}
+
+// Ingest persists the SARIF findings of a successful scan result. Results that carry a
+// source revision are merged with the previous result of the asset, and the scanned
+// commit is sent to the asset service
+func (p *FindingProcessor) Ingest(ctx context.Context, result *domain.ScanResult) error {
+	if result.Status != "success" || (result.SARIF == nil && result.Revision == nil) {
+		return nil
+	}
+
+	var rows []*model.FindingModel
+	for _, report := range result.SARIF.Reports() {
+		for _, f := range report.Findings {
+			props := "{}"
+			if len(f.Properties) > 0 {
+				data, err := json.Marshal(f.Properties)
+				if err != nil {
+					return err
+				}
+				props = string(data)
+			}
+			rows = append(rows, &model.FindingModel{
+				TaskID:          result.TaskID,
+				AssetID:         result.AssetID,
+				AssetType:       result.AssetType.String(),
+				ScanType:        result.ScanType.String(),
+				Tool:            clip(report.Tool, 64),
+				ToolVersion:     clip(report.ToolVersion, 32),
+				RuleID:          clip(f.RuleID, 128),
+				RuleName:        clip(f.RuleName, 256),
+				Severity:        f.Severity,
+				Message:         f.Message,
+				FilePath:        clip(f.Location.Path, 1024),
+				StartLine:       f.Location.StartLine,
+				EndLine:         f.Location.EndLine,
+				LogicalLocation: clip(f.Location.Logical, 512),
+				LogicalKind:     clip(f.Location.LogicalKind, 32),
+				Fingerprint:     f.Fingerprint,
+				CWEID:           f.CWEID,
+				Category:        clip(f.Category, 64),
+				Remediation:     f.Remediation,
+				Properties:      props,
+			})
+		}
+	}
+
+	if rev := result.Revision; rev != nil {
+		// 与上一次入库的任务比较，即使它没有任何发现项
+		previousTaskID, err := p.repo.FindPreviousTaskID(ctx, result.AssetID, result.ScanType.String(), result.TaskID)
+		if err != nil {
+			return err
+		}
+		var previous []*model.FindingModel
+		if previousTaskID != "" {
+			if previous, err = p.repo.FindFindingsByTaskIDs(ctx, []string{previousTaskID}); err != nil {
+				return err
+			}
+		}
+		rows = mergeFindings(previous, rows, result.TaskID, rev)
+	}
+	if len(rows) > 0 {
+		if err := p.repo.BatchCreateFindings(ctx, rows); err != nil {
+			return err
+		}
+	}
+	if err := p.repo.RecordScanHead(ctx, result.AssetID, result.ScanType.String(), result.TaskID); err != nil {
+		return err
+	}
+
+	if rev := result.Revision; rev != nil && rev.Commit != "" && result.AssetType == domain.AssetTypeRepository {
+		return p.publishCommit(ctx, result.AssetID, rev)
+	}
+	return nil
+}
+
+// publishCommit sends the scanned commit to the asset service, which owns the repository asset
+func (p *FindingProcessor) publishCommit(ctx context.Context, assetID string, rev *domain.SourceRevision) error {
+	if p.assets == nil {
+		return nil
+	}
+	task, err := domain.NewAssetTask(domain.AssetTypeRepository, assetID, domain.AssetOperationCommit, map[string]interface{}{
+		"last_commit_hash": rev.Commit,
+		"last_commit_time": rev.CommitTime,
+	}, 0)
+	if err != nil {
+		return err
+	}
+	return p.assets.PublishAssetTask(ctx, domain.AssetOperationCommit, task.Payload)
+}
+
+// mergeFindings marks the current findings as new or unchanged against the previous result.
+// Previous findings that are gone are recorded as fixed when the scan covered their file;
+// an incremental scan does not cover unchanged files, so their findings are carried forward
+func mergeFindings(previous, current []*model.FindingModel, taskID string, rev *domain.SourceRevision) []*model.FindingModel {
+	open := make(map[string]*model.FindingModel, len(previous))
+	for _, row := range previous {
+		if row.Status != domain.FindingStatusFixed {
+			open[findingKey(row)] = row
+		}
+	}
+
+	merged := make([]*model.FindingModel, 0, len(current)+len(open))
+	for _, row := range current {
+		row.Status = domain.FindingStatusNew
+		row.Commit = rev.Commit
+		if _, ok := open[findingKey(row)]; ok {
+			row.Status = domain.FindingStatusUnchanged
+			delete(open, findingKey(row))
+		}
+		merged = append(merged, row)
+	}
+
+	changed := make(map[string]bool, len(rev.ChangedFiles))
+	for _, f := range rev.ChangedFiles {
+		changed[f] = true
+	}
+	for _, row := range previous {
+		if open[findingKey(row)] != row {
+			continue
+		}
+		carried := *row
+		carried.ID = 0
+		carried.TaskID = taskID
+		carried.CreatedAt, carried.UpdatedAt = time.Time{}, time.Time{}
+		if rev.Incremental && !changed[row.FilePath] {
+			carried.Status = domain.FindingStatusUnchanged
+		} else {
+			carried.Status = domain.FindingStatusFixed
+			carried.Commit = rev.Commit
+		}
+		merged = append(merged, &carried)
+	}
+	return merged
+}
+
+// findingKey identifies a finding across scans, falling back to its rule and location
+// when the tool reported no fingerprint
+func findingKey(row *model.FindingModel) string {
+	if row.Fingerprint != "" {
+		return row.Fingerprint
+	}
+	return row.RuleID + "\x00" + row.FilePath + "\x00" + row.LogicalLocation + "\x00" + row.Message
+}
+
+// GetScanType returns the scan type this processor handles
+func (p *FindingProcessor) GetScanType() domain.ScanType {
+	return p.scanType
+}
+
+// Query retrieves all findings of a task
+func (p *FindingProcessor) Query(ctx context.Context, taskID string) (interface{}, error) {
+	return p.repo.FindFindingsByTaskIDs(ctx, []string{taskID})
+}
+
+// BatchQuery retrieves findings by multiple task IDs
+func (p *FindingProcessor) BatchQuery(ctx context.Context, taskIDs []string) ([]interface{}, error) {
+	results, err := p.repo.FindFindingsByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert []*model.FindingModel to []interface{}
+	interfaceResults := make([]interface{}, len(results))
+	for i, result := range results {
+		interfaceResults[i] = result
+	}
+	return interfaceResults, nil
+}
+
+// Export rebuilds a SARIF log from the stored findings, one run per scan type and tool
+func (p *FindingProcessor) Export(ctx context.Context, taskIDs []string) (*domain.SarifLog, error) {
+	rows, err := p.repo.FindFindingsByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []domain.FindingReport
+	index := make(map[string]int)
+	for _, row := range rows {
+		key := row.ScanType + "\x00" + row.Tool + "\x00" + row.ToolVersion
+		i, ok := index[key]
+		if !ok {
+			i = len(reports)
+			index[key] = i
+			reports = append(reports, domain.FindingReport{
+				Tool:        row.Tool,
+				ToolVersion: row.ToolVersion,
+				Properties:  map[string]interface{}{"scan_type": row.ScanType},
+			})
+		}
+
+		finding, err := findingFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		reports[i].Findings = append(reports[i].Findings, finding)
+	}
+	return domain.NewSarifLog(reports...), nil
+}
+
+// Latest returns the findings of the latest stored result of the asset for each scan type.
+// Scan types without stored findings are omitted
+func (p *FindingProcessor) Latest(ctx context.Context, assetID, assetType string, scanTypes []string) ([]domain.ScanFindings, error) {
+	results := make([]domain.ScanFindings, 0, len(scanTypes))
+	for _, scanType := range scanTypes {
+		rows, err := p.repo.FindLatestFindings(ctx, assetID, assetType, scanType)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		result := domain.ScanFindings{ScanType: scanType, TaskID: rows[0].TaskID}
+		for _, row := range rows {
+			finding, err := findingFromRow(row)
+			if err != nil {
+				return nil, err
+			}
+			result.Findings = append(result.Findings, domain.StoredFinding{ID: row.ID, TaskID: row.TaskID, Finding: finding})
+			if row.CreatedAt.After(result.ScannedAt) {
+				result.ScannedAt = row.CreatedAt
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// findingFromRow converts a stored row back into a SARIF finding
+func findingFromRow(row *model.FindingModel) (domain.Finding, error) {
+	var props map[string]interface{}
+	if row.Properties != "" {
+		if err := json.Unmarshal([]byte(row.Properties), &props); err != nil {
+			return domain.Finding{}, err
+		}
+	}
+	return domain.Finding{
+		RuleID:   row.RuleID,
+		RuleName: row.RuleName,
+		Severity: row.Severity,
+		Message:  row.Message,
+		Category: row.Category,
+		CWEID:    row.CWEID,
+		Location: domain.FindingLocation{
+			Path:        row.FilePath,
+			StartLine:   row.StartLine,
+			EndLine:     row.EndLine,
+			Logical:     row.LogicalLocation,
+			LogicalKind: row.LogicalKind,
+		},
+		Fingerprint: row.Fingerprint,
+		Remediation: row.Remediation,
+		Status:      row.Status,
+		Properties:  props,
+	}, nil
+}
+
+// findingIngestProcessor wraps a dedicated processor so that the SARIF findings
+// of the same result are also persisted to the unified findings table
+type findingIngestProcessor struct {
+	StorageProcessor
+	findings *FindingProcessor
+}
+
+// withFindingIngest wraps processor with SARIF finding ingestion
+func withFindingIngest(processor StorageProcessor, findings *FindingProcessor) StorageProcessor {
+	return &findingIngestProcessor{StorageProcessor: processor, findings: findings}
+}
+
+// Process runs the dedicated processor first, then ingests the SARIF findings
+func (p *findingIngestProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
+	if err := p.StorageProcessor.Process(ctx, result); err != nil {
+		return err
+	}
+	return p.findings.Ingest(ctx, result)
+}
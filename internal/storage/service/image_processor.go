@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/blackarbiter/go-sac/internal/storage/repository"
+	"github.com/blackarbiter/go-sac/internal/storage/repository/model"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// ImageScanProcessor implements the Processor interface for container image scan results
+type ImageScanProcessor struct {
+	repo repository.Repository
+}
+
+// NewImageScanProcessor creates a new ImageScanProcessor instance
+func NewImageScanProcessor(repo repository.Repository) *ImageScanProcessor {
+	return &ImageScanProcessor{repo: repo}
+}
+
+// imageAdvisory mirrors the advisory fields needed for the asset summary
+type imageAdvisory struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+}
+
+// imageResultPayload mirrors the result map emitted by the image scanner
+type imageResultPayload struct {
+	Image struct {
+		Digest string `json:"digest"`
+		Size   int64  `json:"size"`
+	} `json:"image"`
+	OS struct {
+		ID        string `json:"id"`
+		VersionID string `json:"version_id"`
+	} `json:"os"`
+	Components []struct {
+		Name               string            `json:"name"`
+		Version            string            `json:"version"`
+		Ecosystem          string            `json:"ecosystem"`
+		SourcePackage      string            `json:"source_package"`
+		Source             string            `json:"source"`
+		Advisories         []json.RawMessage `json:"advisories"`
+		RecommendedVersion string            `json:"recommended_version"`
+	} `json:"components"`
+}
+
+// imageVulnerabilitySummary is written back to ImageAsset.Vulnerabilities
+type imageVulnerabilitySummary struct {
+	TaskID     string         `json:"task_id"`
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+	IDs        []string       `json:"ids"`
+}
+
+// Process handles image scan results, persisting one row per package and
+// updating the scanned image asset
+func (p *ImageScanProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
+	newRow := func() *model.ImageScanModel {
+		return &model.ImageScanModel{
+			TaskID:          result.TaskID,
+			AssetID:         result.AssetID,
+			AssetType:       result.AssetType.String(),
+			Status:          result.Status,
+			Error:           result.Error,
+			Vulnerabilities: "[]",
+		}
+	}
+
+	if result.Status != "success" {
+		return p.repo.CreateImageScan(ctx, newRow())
+	}
+
+	// Parse image specific fields from result.Result
+	jsonBytes, err := json.Marshal(result.Result)
+	if err != nil {
+		return err
+	}
+	var payload imageResultPayload
+	if err := json.Unmarshal(jsonBytes, &payload); err != nil {
+		return err
+	}
+
+	osRelease := payload.OS.ID
+	if payload.OS.VersionID != "" {
+		osRelease += ":" + payload.OS.VersionID
+	}
+	summary := imageVulnerabilitySummary{
+		TaskID:     result.TaskID,
+		BySeverity: make(map[string]int),
+		IDs:        []string{},
+	}
+	seen := make(map[string]bool)
+
+	rows := make([]*model.ImageScanModel, 0, len(payload.Components))
+	for _, c := range payload.Components {
+		row := newRow()
+		row.ImageDigest = payload.Image.Digest
+		row.OSRelease = osRelease
+		row.PackageName = c.Ecosystem + ":" + c.Name
+		row.PackageVersion = c.Version
+		row.SourcePackage = c.SourcePackage
+		row.PackagePath = c.Source
+		row.FixedVersion = c.RecommendedVersion
+		if len(c.Advisories) > 0 {
+			vulns, err := json.Marshal(c.Advisories)
+			if err != nil {
+				return err
+			}
+			row.Vulnerabilities = string(vulns)
+		}
+		for _, raw := range c.Advisories {
+			var adv imageAdvisory
+			if err := json.Unmarshal(raw, &adv); err != nil || seen[adv.ID] {
+				continue
+			}
+			seen[adv.ID] = true
+			summary.Total++
+			severity := adv.Severity
+			if severity == "" {
+				severity = "unknown"
+			}
+			summary.BySeverity[severity]++
+			summary.IDs = append(summary.IDs, adv.ID)
+		}
+		rows = append(rows, row)
+	}
+
+	// 未识别到软件包时仍保留一条任务状态记录
+	if len(rows) == 0 {
+		row := newRow()
+		row.ImageDigest = payload.Image.Digest
+		row.OSRelease = osRelease
+		rows = append(rows, row)
+	}
+	if err := p.repo.BatchCreateImageScan(ctx, rows); err != nil {
+		return err
+	}
+
+	// 回写镜像资产的 Digest/Size 与漏洞摘要
+	if result.AssetID == "" {
+		return nil
+	}
+	vulns, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return p.repo.UpdateImageAssetScan(ctx, result.AssetID, payload.Image.Digest, payload.Image.Size, string(vulns))
+}
+
+// GetScanType returns the scan type this processor handles
+func (p *ImageScanProcessor) GetScanType() domain.ScanType {
+	return domain.ScanTypeContainerImageScan
+}
+
+// Query retrieves all packages of an image scan task
+func (p *ImageScanProcessor) Query(ctx context.Context, taskID string) (interface{}, error) {
+	return p.repo.FindImageScanByTaskIDs(ctx, []string{taskID})
+}
+
+// BatchQuery retrieves image scan results by multiple task IDs
+func (p *ImageScanProcessor) BatchQuery(ctx context.Context, taskIDs []string) ([]interface{}, error) {
+	results, err := p.repo.FindImageScanByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert []*model.ImageScanModel to []interface{}
+	interfaceResults := make([]interface{}, len(results))
+	for i, result := range results {
+		interfaceResults[i] = result
+	}
+	return interfaceResults, nil
+}
@@ -24,4 +24,6 @@ type StorageProcessorFactory interface {
 	GetProcessor(scanType domain.ScanType) (StorageProcessor, error)
 	// RegisterProcessor registers a processor for a scan type
 	RegisterProcessor(scanType domain.ScanType, processor StorageProcessor)
+	// GetFindingProcessor returns the processor of the unified SARIF findings table
+	GetFindingProcessor() (*FindingProcessor, error)
 }
@@ -9,6 +9,8 @@ import (
 // ProviderSet 是 service 层的依赖注入集合
 var ProviderSet = wire.NewSet(
 	ProvideResultConsumer,
+	ProvideAssetPublisher,
+	NewScanLogStore,
 )
 
 // ProvideResultConsumer 提供任务发布者实例
@@ -38,3 +40,15 @@ func ProvideResultConsumer(cfg *config.Config) (*rabbitmq.ResultConsumer, error)
 
 	return consumer, nil
 }
+
+// ProvideAssetPublisher 提供资产任务发布者，用于将扫描到的提交回写到资产服务
+func ProvideAssetPublisher(cfg *config.Config) (*rabbitmq.TaskPublisher, error) {
+	conn, err := rabbitmq.NewConnectionManager(cfg.GetRabbitMQURL(), 3).GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	if err := rabbitmq.Setup(conn); err != nil {
+		return nil, err
+	}
+	return rabbitmq.NewTaskPublisher(conn)
+}
@@ -19,28 +19,66 @@ func NewSASTProcessor(repo repository.Repository) *SASTProcessor {
 	return &SASTProcessor{repo: repo}
 }
 
-// Process handles SAST scan results
+// sastResultPayload mirrors the result map emitted by the SAST scanner
+type sastResultPayload struct {
+	Tool     string `json:"tool"`
+	Findings []struct {
+		FilePath      string `json:"file_path"`
+		LineNumber    int    `json:"line_number"`
+		Severity      string `json:"severity"`
+		RuleID        string `json:"rule_id"`
+		RuleName      string `json:"rule_name"`
+		Description   string `json:"description"`
+		CWEID         string `json:"cwe_id"`
+		FixSuggestion string `json:"fix_suggestion"`
+	} `json:"findings"`
+}
+
+// Process handles SAST scan results, persisting one row per finding
 func (p *SASTProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
-	sastResult := &model.SASTModel{
-		TaskID:    result.TaskID,
-		AssetID:   result.AssetID,
-		AssetType: result.AssetType.String(),
-		Status:    result.Status,
-		Error:     result.Error,
+	newRow := func() *model.SASTModel {
+		return &model.SASTModel{
+			TaskID:    result.TaskID,
+			AssetID:   result.AssetID,
+			AssetType: result.AssetType.String(),
+			Status:    result.Status,
+			Error:     result.Error,
+		}
+	}
+
+	if result.Status != "success" {
+		return p.repo.CreateSAST(ctx, newRow())
 	}
 
 	// Parse SAST specific fields from result.Result
-	if result.Status == "success" {
-		jsonBytes, err := json.Marshal(result.Result)
-		if err != nil {
-			return err
-		}
-		if err := json.Unmarshal(jsonBytes, sastResult); err != nil {
-			return err
-		}
+	jsonBytes, err := json.Marshal(result.Result)
+	if err != nil {
+		return err
+	}
+	var payload sastResultPayload
+	if err := json.Unmarshal(jsonBytes, &payload); err != nil {
+		return err
 	}
 
-	return p.repo.CreateSAST(ctx, sastResult)
+	// 无发现项时仍保留一条任务状态记录
+	if len(payload.Findings) == 0 {
+		return p.repo.CreateSAST(ctx, newRow())
+	}
+
+	rows := make([]*model.SASTModel, 0, len(payload.Findings))
+	for _, f := range payload.Findings {
+		row := newRow()
+		row.FilePath = f.FilePath
+		row.LineNumber = f.LineNumber
+		row.Severity = f.Severity
+		row.RuleID = f.RuleID
+		row.RuleName = f.RuleName
+		row.Description = f.Description
+		row.CWEID = f.CWEID
+		row.FixSuggestion = f.FixSuggestion
+		rows = append(rows, row)
+	}
+	return p.repo.BatchCreateSAST(ctx, rows)
 }
 
 // GetScanType returns the scan type this processor handles
@@ -48,9 +86,9 @@ func (p *SASTProcessor) GetScanType() domain.ScanType {
 	return domain.ScanTypeStaticCodeAnalysis
 }
 
-// Query retrieves SAST scan results by task ID
+// Query retrieves all SAST findings of a task
 func (p *SASTProcessor) Query(ctx context.Context, taskID string) (interface{}, error) {
-	return p.repo.FindSASTByTaskID(ctx, taskID)
+	return p.repo.FindSASTByTaskIDs(ctx, []string{taskID})
 }
 
 // BatchQuery retrieves SAST scan results by multiple task IDs
@@ -19,28 +19,72 @@ func NewSCAProcessor(repo repository.Repository) *SCAProcessor {
 	return &SCAProcessor{repo: repo}
 }
 
-// Process handles SCA scan results
+// scaResultPayload mirrors the result map emitted by the SCA scanner
+type scaResultPayload struct {
+	Components []struct {
+		Name               string            `json:"name"`
+		Version            string            `json:"version"`
+		Ecosystem          string            `json:"ecosystem"`
+		License            string            `json:"license"`
+		Direct             bool              `json:"direct"`
+		Source             string            `json:"source"`
+		Advisories         []json.RawMessage `json:"advisories"`
+		RecommendedVersion string            `json:"recommended_version"`
+	} `json:"components"`
+}
+
+// Process handles SCA scan results, persisting one row per component
 func (p *SCAProcessor) Process(ctx context.Context, result *domain.ScanResult) error {
-	scaResult := &model.SCAModel{
-		TaskID:    result.TaskID,
-		AssetID:   result.AssetID,
-		AssetType: string(result.AssetType),
-		Status:    result.Status,
-		Error:     result.Error,
+	newRow := func() *model.SCAModel {
+		return &model.SCAModel{
+			TaskID:          result.TaskID,
+			AssetID:         result.AssetID,
+			AssetType:       result.AssetType.String(),
+			Status:          result.Status,
+			Error:           result.Error,
+			Vulnerabilities: "[]",
+		}
+	}
+
+	if result.Status != "success" {
+		return p.repo.CreateSCA(ctx, newRow())
 	}
 
 	// Parse SCA specific fields from result.Result
-	if result.Status == "success" {
-		jsonBytes, err := json.Marshal(result.Result)
-		if err != nil {
-			return err
-		}
-		if err := json.Unmarshal(jsonBytes, scaResult); err != nil {
-			return err
-		}
+	jsonBytes, err := json.Marshal(result.Result)
+	if err != nil {
+		return err
+	}
+	var payload scaResultPayload
+	if err := json.Unmarshal(jsonBytes, &payload); err != nil {
+		return err
+	}
+
+	// 未识别到依赖时仍保留一条任务状态记录
+	if len(payload.Components) == 0 {
+		return p.repo.CreateSCA(ctx, newRow())
 	}
 
-	return p.repo.CreateSCA(ctx, scaResult)
+	rows := make([]*model.SCAModel, 0, len(payload.Components))
+	for _, c := range payload.Components {
+		row := newRow()
+		row.PackageName = c.Ecosystem + ":" + c.Name
+		row.PackageVersion = c.Version
+		row.License = c.License
+		row.DirectDependency = c.Direct
+		row.DependencyPath = c.Source
+		row.LatestVersion = c.RecommendedVersion
+		row.UpdateAvailable = c.RecommendedVersion != ""
+		if len(c.Advisories) > 0 {
+			vulns, err := json.Marshal(c.Advisories)
+			if err != nil {
+				return err
+			}
+			row.Vulnerabilities = string(vulns)
+		}
+		rows = append(rows, row)
+	}
+	return p.repo.BatchCreateSCA(ctx, rows)
 }
 
 // GetScanType returns the scan type this processor handles
@@ -48,9 +92,9 @@ func (p *SCAProcessor) GetScanType() domain.ScanType {
 	return domain.ScanTypeSca
 }
 
-// Query retrieves SCA scan results by task ID
+// Query retrieves all SCA components of a task
 func (p *SCAProcessor) Query(ctx context.Context, taskID string) (interface{}, error) {
-	return p.repo.FindSCAByTaskID(ctx, taskID)
+	return p.repo.FindSCAByTaskIDs(ctx, []string{taskID})
 }
 
 // BatchQuery retrieves SCA scan results by multiple task IDs
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/storage/minio"
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+// ErrScanLogNotFound 任务没有已上传的命令输出
+var ErrScanLogNotFound = errors.New("scan log not found")
+
+// ScanLogStore reads the raw scanner output uploaded by the scan service.
+// 每次命令执行上传为 <bucket>/<log_prefix>/<任务ID>/<执行ID>.log，执行ID以启动时间结尾，按名称排序即执行顺序
+type ScanLogStore struct {
+	client *minio.Client
+	prefix func(taskID string) string
+}
+
+// NewScanLogStore creates a new ScanLogStore instance
+func NewScanLogStore(client *minio.Client, cfg *config.Config) *ScanLogStore {
+	return &ScanLogStore{client: client, prefix: cfg.GetScanLogPrefix}
+}
+
+// Objects 返回任务的命令输出对象路径，按执行顺序排列
+func (s *ScanLogStore) Objects(ctx context.Context, taskID string) ([]string, error) {
+	if taskID == "" || strings.ContainsAny(taskID, "/\\") {
+		return nil, fmt.Errorf("invalid task id %q", taskID)
+	}
+	prefix := s.prefix(taskID)
+	bucket := strings.SplitN(prefix, "/", 2)[0]
+
+	var objects []string
+	for obj := range s.client.ListObjects(ctx, prefix, true) {
+		if obj.IsDir {
+			continue
+		}
+		objects = append(objects, bucket+"/"+obj.Object.Key)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScanLogNotFound, taskID)
+	}
+	sort.Strings(objects)
+	return objects, nil
+}
+
+// Copy 按执行顺序将命令输出对象依次写入 w
+func (s *ScanLogStore) Copy(ctx context.Context, objects []string, w io.Writer) error {
+	for _, object := range objects {
+		if err := s.client.GetLargeObject(ctx, object, w, miniogo.GetObjectOptions{}); err != nil {
+			return fmt.Errorf("read scan log %s: %w", object, err)
+		}
+	}
+	return nil
+}
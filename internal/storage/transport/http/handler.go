@@ -1,6 +1,8 @@
 package http
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/blackarbiter/go-sac/internal/storage/dto"
@@ -12,11 +14,12 @@ import (
 // Handler handles HTTP requests
 type Handler struct {
 	factory service.StorageProcessorFactory
+	logs    *service.ScanLogStore
 }
 
 // NewHandler creates a new Handler instance
-func NewHandler(factory service.StorageProcessorFactory) *Handler {
-	return &Handler{factory: factory}
+func NewHandler(factory service.StorageProcessorFactory, logs *service.ScanLogStore) *Handler {
+	return &Handler{factory: factory, logs: logs}
 }
 
 // RegisterRoutes registers HTTP routes
@@ -41,6 +44,212 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 		sca.GET("/:task_id", h.handleQuery(domain.ScanTypeSca))
 		sca.POST("/batch", h.handleBatchQuery(domain.ScanTypeSca))
 	}
+
+	// Container image routes
+	image := r.Group("/api/v1/image")
+	{
+		image.GET("/:task_id", h.handleQuery(domain.ScanTypeContainerImageScan))
+		image.POST("/batch", h.handleBatchQuery(domain.ScanTypeContainerImageScan))
+	}
+
+	// Unified SARIF finding routes
+	findings := r.Group("/api/v1/findings")
+	{
+		findings.GET("/latest", h.handleLatestFindings)
+		findings.GET("/:task_id", h.handleFindingQuery)
+		findings.GET("/:task_id/sarif", h.handleSARIFExport)
+		findings.POST("/batch", h.handleFindingBatchQuery)
+		findings.POST("/import", h.handleSARIFImport)
+	}
+
+	// Raw scanner output routes
+	logs := r.Group("/api/v1/logs")
+	{
+		logs.GET("/:task_id", h.handleScanLog)
+		logs.GET("/:task_id/objects", h.handleScanLogObjects)
+	}
+}
+
+// handleFindingQuery returns the stored findings of a task
+func (h *Handler) handleFindingQuery(c *gin.Context) {
+	processor, err := h.factory.GetFindingProcessor()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := processor.Query(c.Request.Context(), c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(result))
+}
+
+// handleLatestFindings returns the findings of the latest stored result of an asset for each requested scan type
+func (h *Handler) handleLatestFindings(c *gin.Context) {
+	var req dto.LatestFindingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+	assetType, err := domain.ParseAssetType(req.AssetType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+	scanTypes := make([]string, 0, len(req.ScanTypes))
+	for _, s := range req.ScanTypes {
+		scanType, err := domain.ParseScanType(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+			return
+		}
+		scanTypes = append(scanTypes, scanType.String())
+	}
+
+	processor, err := h.factory.GetFindingProcessor()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	results, err := processor.Latest(c.Request.Context(), req.AssetID, assetType.String(), scanTypes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(results))
+}
+
+// handleFindingBatchQuery returns the stored findings of multiple tasks
+func (h *Handler) handleFindingBatchQuery(c *gin.Context) {
+	var req struct {
+		TaskIDs []string `json:"task_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	processor, err := h.factory.GetFindingProcessor()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	results, err := processor.BatchQuery(c.Request.Context(), req.TaskIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(results))
+}
+
+// handleSARIFExport returns the findings of a task as a raw SARIF 2.1.0 log
+func (h *Handler) handleSARIFExport(c *gin.Context) {
+	processor, err := h.factory.GetFindingProcessor()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	log, err := processor.Export(c.Request.Context(), []string{c.Param("task_id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "application/sarif+json")
+	c.JSON(http.StatusOK, log)
+}
+
+// handleSARIFImport ingests a SARIF 2.1.0 log produced by a third-party tool
+func (h *Handler) handleSARIFImport(c *gin.Context) {
+	var req dto.SARIFImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+	scanType, err := domain.ParseScanType(req.ScanType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+	assetType, err := domain.ParseAssetType(req.AssetType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+	log, err := domain.DecodeSARIF(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	processor, err := h.factory.GetFindingProcessor()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	result := domain.NewScanResult(req.TaskID, scanType, req.AssetID, assetType)
+	result.Status = "success"
+	result.SARIF = log
+	if err := processor.Ingest(c.Request.Context(), result); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(gin.H{"findings": len(log.Findings())}))
+}
+
+// handleScanLog streams the raw scanner output of a task as plain text
+func (h *Handler) handleScanLog(c *gin.Context) {
+	objects, ok := h.scanLogObjects(c)
+	if !ok {
+		return
+	}
+
+	// 响应头发送后出错只能中断连接
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := h.logs.Copy(c.Request.Context(), objects, c.Writer); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// handleScanLogObjects returns the object paths of a task's raw scanner output
+func (h *Handler) handleScanLogObjects(c *gin.Context) {
+	objects, ok := h.scanLogObjects(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(gin.H{"objects": objects}))
+}
+
+// scanLogObjects lists the log objects of the requested task, writing the error response on failure
+func (h *Handler) scanLogObjects(c *gin.Context) ([]string, bool) {
+	objects, err := h.logs.Objects(c.Request.Context(), c.Param("task_id"))
+	switch {
+	case errors.Is(err, service.ErrScanLogNotFound):
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(404, err.Error()))
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(400, err.Error()))
+		return nil, false
+	}
+	return objects, true
 }
 
 // handleQuery handles single query request
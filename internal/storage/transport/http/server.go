@@ -20,7 +20,7 @@ type Server struct {
 }
 
 // NewServer 创建HTTP服务器实例
-func NewServer(cfg *config.Config, factory service.StorageProcessorFactory) *Server {
+func NewServer(cfg *config.Config, factory service.StorageProcessorFactory, logs *service.ScanLogStore) *Server {
 	// 创建Gin引擎
 	engine := gin.Default()
 
@@ -36,7 +36,7 @@ func NewServer(cfg *config.Config, factory service.StorageProcessorFactory) *Ser
 	}
 
 	// 注册路由
-	handler := NewHandler(factory)
+	handler := NewHandler(factory, logs)
 	handler.RegisterRoutes(engine)
 
 	return server
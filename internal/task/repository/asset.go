@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// AssetRepository 只读访问资产服务维护的资产基表，任务服务据此确定任务所属组织
+type AssetRepository interface {
+	// FindOrganizationID 查找资产所属组织，资产不存在时返回 0
+	FindOrganizationID(ctx context.Context, assetID string) (uint, error)
+}
+
+// assetRepository 是AssetRepository的具体实现
+type assetRepository struct {
+	db *gorm.DB
+}
+
+// NewAssetRepository 创建一个新的资产仓库实例
+func NewAssetRepository(db *gorm.DB) AssetRepository {
+	return &assetRepository{db: db}
+}
+
+// FindOrganizationID 读取 assets_base.organization_id，表由资产服务迁移和写入
+func (r *assetRepository) FindOrganizationID(ctx context.Context, assetID string) (uint, error) {
+	var asset struct {
+		OrganizationID uint
+	}
+	err := r.db.WithContext(ctx).Table("assets_base").Select("organization_id").
+		Where("id = ?", assetID).Take(&asset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return asset.OrganizationID, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPipelineNotFound 流水线不存在
+var ErrPipelineNotFound = errors.New("pipeline not found")
+
+// PipelineEntity 表示扫描流水线数据库实体，子任务通过 tasks.parent_id 关联
+type PipelineEntity struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey"`
+	Name        string    `gorm:"type:varchar(100)"`
+	Status      string    `gorm:"type:varchar(20);not null;index"`
+	AssetID     string    `gorm:"type:varchar(36);not null;index"`
+	AssetType   string    `gorm:"type:varchar(50);not null"`
+	BaseCommit  string    `gorm:"type:varchar(64)"`
+	Definition  []byte    `gorm:"type:json;not null"` // 阶段定义，选项已按 schema 校验并加密凭据
+	Stages      []byte    `gorm:"type:json;not null"` // 各阶段运行状态
+	Summary     []byte    `gorm:"type:json"`          // 全部阶段结束后的结果汇总
+	UserID      uint      `gorm:"type:int;not null;index"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+	CompletedAt *time.Time
+}
+
+// TableName 指定表名
+func (PipelineEntity) TableName() string {
+	return "pipelines"
+}
+
+// Pipeline 表示扫描流水线
+type Pipeline struct {
+	ID          string                                `json:"id"`
+	Name        string                                `json:"name"`
+	Status      string                                `json:"status"`
+	AssetID     string                                `json:"asset_id"`
+	AssetType   string                                `json:"asset_type"`
+	BaseCommit  string                                `json:"base_commit"`
+	Definition  domain.PipelineDefinition             `json:"definition"`
+	Stages      map[string]*domain.PipelineStageState `json:"stages"`
+	Summary     *domain.PipelineSummary               `json:"summary"`
+	UserID      uint                                  `json:"user_id"`
+	CreatedAt   time.Time                             `json:"created_at"`
+	UpdatedAt   time.Time                             `json:"updated_at"`
+	CompletedAt *time.Time                            `json:"completed_at"`
+}
+
+// PipelineRepository 定义流水线仓库接口
+type PipelineRepository interface {
+	// Create 在同一事务中保存流水线及首批子任务
+	Create(ctx context.Context, p *Pipeline, tasks []*Task) error
+	FindByID(ctx context.Context, id string) (*Pipeline, error)
+	// Advance 锁定流水线后由 fn 修改阶段状态，fn 返回的新子任务与流水线在同一事务中保存，
+	// 并发的子任务状态更新因此按顺序推进
+	Advance(ctx context.Context, id string, fn func(p *Pipeline) ([]*Task, error)) error
+}
+
+// pipelineRepository 是PipelineRepository的具体实现
+type pipelineRepository struct {
+	db *gorm.DB
+}
+
+// NewPipelineRepository 创建一个新的流水线仓库实例
+func NewPipelineRepository(db *gorm.DB) PipelineRepository {
+	return &pipelineRepository{db: db}
+}
+
+// Create 保存流水线及首批子任务
+func (r *pipelineRepository) Create(ctx context.Context, p *Pipeline, tasks []*Task) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	now := time.Now()
+	p.CreatedAt, p.UpdatedAt = now, now
+
+	entity, err := convertPipelineToEntity(p)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entity).Error; err != nil {
+			return err
+		}
+		return createChildren(tx, p.ID, tasks)
+	})
+}
+
+// FindByID 根据ID查找流水线
+func (r *pipelineRepository) FindByID(ctx context.Context, id string) (*Pipeline, error) {
+	var entity PipelineEntity
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		return nil, err
+	}
+	return convertPipeline(&entity)
+}
+
+// Advance 在行锁内推进流水线
+func (r *pipelineRepository) Advance(ctx context.Context, id string, fn func(p *Pipeline) ([]*Task, error)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entity PipelineEntity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&entity).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPipelineNotFound
+			}
+			return err
+		}
+		p, err := convertPipeline(&entity)
+		if err != nil {
+			return err
+		}
+
+		tasks, err := fn(p)
+		if err != nil {
+			return err
+		}
+		p.UpdatedAt = time.Now()
+		updated, err := convertPipelineToEntity(p)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(updated).Error; err != nil {
+			return err
+		}
+		return createChildren(tx, p.ID, tasks)
+	})
+}
+
+// createChildren 保存子任务并关联到流水线
+func createChildren(tx *gorm.DB, pipelineID string, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	entities := make([]*TaskEntity, len(tasks))
+	for i, task := range tasks {
+		if task.ID == "" {
+			task.ID = uuid.New().String()
+		}
+		task.ParentID = pipelineID
+		entities[i] = convertToEntity(task)
+	}
+	return tx.Create(entities).Error
+}
+
+// convertPipelineToEntity 将流水线转换为数据库实体
+func convertPipelineToEntity(p *Pipeline) (*PipelineEntity, error) {
+	definition, err := json.Marshal(p.Definition)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := json.Marshal(p.Stages)
+	if err != nil {
+		return nil, err
+	}
+	var summary []byte
+	if p.Summary != nil {
+		if summary, err = json.Marshal(p.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return &PipelineEntity{
+		ID:          p.ID,
+		Name:        p.Name,
+		Status:      p.Status,
+		AssetID:     p.AssetID,
+		AssetType:   p.AssetType,
+		BaseCommit:  p.BaseCommit,
+		Definition:  definition,
+		Stages:      stages,
+		Summary:     summary,
+		UserID:      p.UserID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		CompletedAt: p.CompletedAt,
+	}, nil
+}
+
+// convertPipeline 将数据库实体转换为流水线
+func convertPipeline(entity *PipelineEntity) (*Pipeline, error) {
+	p := &Pipeline{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Status:      entity.Status,
+		AssetID:     entity.AssetID,
+		AssetType:   entity.AssetType,
+		BaseCommit:  entity.BaseCommit,
+		UserID:      entity.UserID,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+		CompletedAt: entity.CompletedAt,
+	}
+	if err := json.Unmarshal(entity.Definition, &p.Definition); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(entity.Stages, &p.Stages); err != nil {
+		return nil, err
+	}
+	if len(entity.Summary) > 0 {
+		if err := json.Unmarshal(entity.Summary, &p.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
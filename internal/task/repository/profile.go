@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/profile"
+	"gorm.io/gorm"
+)
+
+// ErrProfileNotFound 扫描 profile 或指定版本不存在
+var ErrProfileNotFound = errors.New("scan profile not found")
+
+// ProfileEntity 表示扫描 profile 的一个版本
+type ProfileEntity struct {
+	ID          uint      `gorm:"primaryKey"`
+	Name        string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_profile_version"`
+	Version     int       `gorm:"type:int;not null;uniqueIndex:idx_profile_version"`
+	ScanType    string    `gorm:"type:varchar(50);not null;index"`
+	Description string    `gorm:"type:text"`
+	Options     []byte    `gorm:"type:json;not null"`
+	CreatedBy   uint      `gorm:"type:int;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (ProfileEntity) TableName() string {
+	return "scan_profiles"
+}
+
+// ProfileRepository 定义扫描 profile 仓库接口，版本只增不改
+type ProfileRepository interface {
+	// CreateVersion 保存 profile 的新版本，版本号为同名 profile 的最大版本加一
+	CreateVersion(ctx context.Context, p *profile.Profile) error
+	// FindVersion 查找指定版本，version 为 0 时返回最新版本
+	FindVersion(ctx context.Context, name string, version int) (*profile.Profile, error)
+	// ListLatest 列出每个 profile 的最新版本，scanType 为空时不过滤
+	ListLatest(ctx context.Context, scanType string) ([]*profile.Profile, error)
+	// ListVersions 列出 profile 的全部版本，按版本号降序
+	ListVersions(ctx context.Context, name string) ([]*profile.Profile, error)
+}
+
+// profileRepository 是ProfileRepository的具体实现
+type profileRepository struct {
+	db *gorm.DB
+}
+
+// NewProfileRepository 创建一个新的扫描 profile 仓库实例
+func NewProfileRepository(db *gorm.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+// CreateVersion 在事务中分配版本号，并发保存同名 profile 时由唯一索引拒绝重复版本
+func (r *profileRepository) CreateVersion(ctx context.Context, p *profile.Profile) error {
+	options, err := json.Marshal(p.Options)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&ProfileEntity{}).Where("name = ?", p.Name).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		if latest > 0 {
+			var prev ProfileEntity
+			if err := tx.Where("name = ? AND version = ?", p.Name, latest).First(&prev).Error; err != nil {
+				return err
+			}
+			if prev.ScanType != p.ScanType {
+				return errors.New("profile " + p.Name + " already exists for scan type " + prev.ScanType)
+			}
+		}
+
+		entity := &ProfileEntity{
+			Name:        p.Name,
+			Version:     latest + 1,
+			ScanType:    p.ScanType,
+			Description: p.Description,
+			Options:     options,
+			CreatedBy:   p.CreatedBy,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(entity).Error; err != nil {
+			return err
+		}
+		p.Version = entity.Version
+		p.CreatedAt = entity.CreatedAt
+		return nil
+	})
+}
+
+// FindVersion 查找指定版本，version 为 0 时返回最新版本
+func (r *profileRepository) FindVersion(ctx context.Context, name string, version int) (*profile.Profile, error) {
+	var entity ProfileEntity
+	query := r.db.WithContext(ctx).Where("name = ?", name)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	if err := query.Order("version DESC").First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	return convertProfile(&entity)
+}
+
+// ListLatest 列出每个 profile 的最新版本
+func (r *profileRepository) ListLatest(ctx context.Context, scanType string) ([]*profile.Profile, error) {
+	latest := r.db.Model(&ProfileEntity{}).Select("name, MAX(version) AS version").Group("name")
+	query := r.db.WithContext(ctx).Model(&ProfileEntity{}).
+		Joins("JOIN (?) AS latest ON latest.name = scan_profiles.name AND latest.version = scan_profiles.version", latest)
+	if scanType != "" {
+		query = query.Where("scan_profiles.scan_type = ?", scanType)
+	}
+
+	var entities []*ProfileEntity
+	if err := query.Order("scan_profiles.name").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return convertProfiles(entities)
+}
+
+// ListVersions 列出 profile 的全部版本
+func (r *profileRepository) ListVersions(ctx context.Context, name string) ([]*profile.Profile, error) {
+	var entities []*ProfileEntity
+	if err := r.db.WithContext(ctx).Where("name = ?", name).Order("version DESC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, ErrProfileNotFound
+	}
+	return convertProfiles(entities)
+}
+
+// convertProfile 将数据库实体转换为 profile
+func convertProfile(entity *ProfileEntity) (*profile.Profile, error) {
+	p := &profile.Profile{
+		Name:        entity.Name,
+		Version:     entity.Version,
+		ScanType:    entity.ScanType,
+		Description: entity.Description,
+		CreatedBy:   entity.CreatedBy,
+		CreatedAt:   entity.CreatedAt,
+	}
+	if err := json.Unmarshal(entity.Options, &p.Options); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func convertProfiles(entities []*ProfileEntity) ([]*profile.Profile, error) {
+	profiles := make([]*profile.Profile, len(entities))
+	for i, entity := range entities {
+		p, err := convertProfile(entity)
+		if err != nil {
+			return nil, err
+		}
+		profiles[i] = p
+	}
+	return profiles, nil
+}
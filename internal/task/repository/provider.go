@@ -10,6 +10,9 @@ import (
 // ProviderSet 是任务仓库提供者集合
 var ProviderSet = wire.NewSet(
 	ProvideTaskRepository,
+	ProvideProfileRepository,
+	ProvidePipelineRepository,
+	ProvideAssetRepository,
 	mysqlStorage.ProviderSet,
 )
 
@@ -22,3 +25,26 @@ func ProvideTaskRepository(db *gorm.DB, cfg *config.Config) TaskRepository {
 
 	return NewTaskRepository(db, cfg)
 }
+
+// ProvideProfileRepository 提供扫描 profile 仓库实例
+func ProvideProfileRepository(db *gorm.DB) ProfileRepository {
+	if err := db.AutoMigrate(&ProfileEntity{}); err != nil {
+		panic(err)
+	}
+
+	return NewProfileRepository(db)
+}
+
+// ProvidePipelineRepository 提供扫描流水线仓库实例
+func ProvidePipelineRepository(db *gorm.DB) PipelineRepository {
+	if err := db.AutoMigrate(&PipelineEntity{}); err != nil {
+		panic(err)
+	}
+
+	return NewPipelineRepository(db)
+}
+
+// ProvideAssetRepository 提供资产仓库实例，资产表由资产服务迁移
+func ProvideAssetRepository(db *gorm.DB) AssetRepository {
+	return NewAssetRepository(db)
+}
@@ -28,6 +28,9 @@ type TaskEntity struct {
 	CompletedAt *time.Time
 	ErrorMsg    string `gorm:"type:text"`
 	RetryCount  int    `gorm:"type:int;default:0"`
+	Profile     string `gorm:"type:varchar(80);index"` // 扫描 profile 及版本，如 sast-quick@3
+	ParentID    string `gorm:"type:varchar(36);index"` // 所属流水线ID
+	Stage       string `gorm:"type:varchar(64)"`       // 所属流水线阶段
 }
 
 // TableName 指定表名
@@ -52,6 +55,9 @@ type Task struct {
 	CompletedAt *time.Time `json:"completed_at"`
 	ErrorMsg    string     `json:"error_msg"`
 	RetryCount  int        `json:"retry_count"`
+	Profile     string     `json:"profile"`   // 扫描 profile 及版本
+	ParentID    string     `json:"parent_id"` // 所属流水线ID
+	Stage       string     `json:"stage"`     // 所属流水线阶段
 }
 
 // TaskRepository 定义任务仓库接口
@@ -97,6 +103,9 @@ func convertToEntity(task *Task) *TaskEntity {
 		CompletedAt: task.CompletedAt,
 		ErrorMsg:    task.ErrorMsg,
 		RetryCount:  task.RetryCount,
+		Profile:     task.Profile,
+		ParentID:    task.ParentID,
+		Stage:       task.Stage,
 	}
 }
 
@@ -118,6 +127,9 @@ func convertToDomain(entity *TaskEntity) *Task {
 		CompletedAt: entity.CompletedAt,
 		ErrorMsg:    entity.ErrorMsg,
 		RetryCount:  entity.RetryCount,
+		Profile:     entity.Profile,
+		ParentID:    entity.ParentID,
+		Stage:       entity.Stage,
 	}
 }
 
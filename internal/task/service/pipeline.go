@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blackarbiter/go-sac/internal/task/repository"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrPipelineNotFound 流水线不存在
+var ErrPipelineNotFound = repository.ErrPipelineNotFound
+
+// CreatePipelineRequest 创建扫描流水线请求：对同一资产按依赖顺序执行的多个扫描阶段
+type CreatePipelineRequest struct {
+	Name      string                 `json:"name"`
+	AssetID   string                 `json:"asset_id" binding:"required"`
+	AssetType string                 `json:"asset_type" binding:"required"`
+	Stages    []domain.PipelineStage `json:"stages" binding:"required,min=1"`
+	// BaseCommit 代码仓库资产的基线提交，传递给各个源码类扫描阶段
+	BaseCommit string `json:"base_commit"`
+}
+
+// PipelineStageDTO 流水线阶段及其子任务状态
+type PipelineStageDTO struct {
+	Name      string         `json:"name"`
+	ScanType  string         `json:"scan_type"`
+	DependsOn []string       `json:"depends_on,omitempty"`
+	Status    string         `json:"status"`
+	TaskID    string         `json:"task_id,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+	Findings  map[string]int `json:"findings,omitempty"`
+}
+
+// PipelineDTO 表示流水线数据传输对象，Summary 在全部阶段结束后生成
+type PipelineDTO struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name,omitempty"`
+	Status      string                  `json:"status"`
+	AssetID     string                  `json:"asset_id"`
+	AssetType   string                  `json:"asset_type"`
+	Stages      []PipelineStageDTO      `json:"stages"`
+	Summary     *domain.PipelineSummary `json:"summary,omitempty"`
+	UserID      uint                    `json:"user_id"`
+	CreatedAt   string                  `json:"created_at"`
+	UpdatedAt   string                  `json:"updated_at"`
+	CompletedAt string                  `json:"completed_at,omitempty"`
+}
+
+// convertPipelineToDTO 将流水线转换为DTO，阶段按定义顺序排列
+func convertPipelineToDTO(p *repository.Pipeline) *PipelineDTO {
+	dto := &PipelineDTO{
+		ID:        p.ID,
+		Name:      p.Name,
+		Status:    p.Status,
+		AssetID:   p.AssetID,
+		AssetType: p.AssetType,
+		Stages:    make([]PipelineStageDTO, 0, len(p.Definition.Stages)),
+		Summary:   p.Summary,
+		UserID:    p.UserID,
+		CreatedAt: p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	for _, st := range p.Definition.Stages {
+		stage := PipelineStageDTO{Name: st.Name, ScanType: st.ScanType, DependsOn: st.DependsOn}
+		if state := p.Stages[st.Name]; state != nil {
+			stage.Status = state.Status
+			stage.TaskID = state.TaskID
+			stage.Reason = state.Reason
+			stage.Findings = state.Findings
+		}
+		dto.Stages = append(dto.Stages, stage)
+	}
+	if p.CompletedAt != nil {
+		dto.CompletedAt = p.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return dto
+}
+
+// CreatePipeline 校验流水线定义并预先解析各阶段选项（固定 profile 版本、加密凭据），
+// 创建没有依赖的阶段对应的子任务，其余阶段在依赖结束后由 UpdateTaskStatus 推进
+func (s *taskService) CreatePipeline(ctx context.Context, req *CreatePipelineRequest, userID uint) (*PipelineDTO, error) {
+	if _, err := domain.ParseAssetType(req.AssetType); err != nil {
+		return nil, err
+	}
+	def := domain.PipelineDefinition{Stages: req.Stages}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	for i := range def.Stages {
+		st := &def.Stages[i]
+		scanType, _ := domain.ParseScanType(st.ScanType)
+		options, ref, err := s.profiles.ResolveOptions(ctx, scanType, st.Profile, st.ProfileVersion, st.Options)
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %w", st.Name, err)
+		}
+		st.Options = options
+		if ref != nil {
+			st.ProfileVersion = ref.Version
+		}
+	}
+
+	p := &repository.Pipeline{
+		Name:       req.Name,
+		AssetID:    req.AssetID,
+		AssetType:  req.AssetType,
+		BaseCommit: req.BaseCommit,
+		Definition: def,
+		Stages:     def.InitialStates(),
+		UserID:     userID,
+	}
+	tasks := s.planPipeline(ctx, p)
+	if err := s.pipelineRepo.Create(ctx, p, tasks); err != nil {
+		return nil, err
+	}
+	s.publishPipelineTasks(ctx, p.ID, tasks)
+
+	created, err := s.pipelineRepo.FindByID(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return convertPipelineToDTO(created), nil
+}
+
+// GetPipeline 获取流水线及各阶段状态
+func (s *taskService) GetPipeline(ctx context.Context, id string) (*PipelineDTO, error) {
+	p, err := s.pipelineRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertPipelineToDTO(p), nil
+}
+
+// planPipeline 为可以执行的阶段创建子任务，更新流水线状态，全部阶段结束时生成结果汇总
+func (s *taskService) planPipeline(ctx context.Context, p *repository.Pipeline) []*repository.Task {
+	var tasks []*repository.Task
+	// 创建失败的阶段会使下游阶段可以判定为跳过，因此反复规划直到没有新的阶段
+	for stages := p.Definition.Plan(p.Stages); len(stages) > 0; stages = p.Definition.Plan(p.Stages) {
+		for _, st := range stages {
+			state := p.Stages[st.Name]
+			task, err := s.newScanTask(ctx, &CreateScanTaskRequest{
+				AssetID:        p.AssetID,
+				AssetType:      p.AssetType,
+				ScanType:       st.ScanType,
+				Options:        st.Options,
+				Priority:       st.Priority,
+				BaseCommit:     p.BaseCommit,
+				Profile:        st.Profile,
+				ProfileVersion: st.ProfileVersion,
+			}, p.UserID)
+			if err != nil {
+				// 选项在创建流水线时已校验，此处失败通常是外部变化（如 profile 被删除），只影响该阶段
+				state.Status = string(domain.TaskStatusFailed)
+				state.Reason = err.Error()
+				continue
+			}
+			task.Stage = st.Name
+			state.TaskID = task.ID
+			state.Status = task.Status
+			tasks = append(tasks, task)
+		}
+	}
+
+	p.Status = string(p.Definition.Status(p.Stages))
+	if p.Status == string(domain.TaskStatusCompleted) || p.Status == string(domain.TaskStatusFailed) {
+		now := time.Now()
+		p.CompletedAt = &now
+		p.Summary = p.Definition.Summarize(p.Stages)
+	}
+	return tasks
+}
+
+// advancePipeline 记录子任务状态并推进流水线，新创建的子任务在事务提交后发布
+func (s *taskService) advancePipeline(ctx context.Context, task *repository.Task, status, reason string, findings map[string]int) error {
+	var tasks []*repository.Task
+	err := s.pipelineRepo.Advance(ctx, task.ParentID, func(p *repository.Pipeline) ([]*repository.Task, error) {
+		state := p.Stages[task.Stage]
+		if state == nil || state.TaskID != task.ID || domain.IsStageTerminal(state.Status) {
+			return nil, nil
+		}
+		state.Status = status
+		state.Reason = reason
+		if findings != nil {
+			state.Findings = findings
+		}
+
+		tasks = s.planPipeline(ctx, p)
+		return tasks, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to advance pipeline %s: %w", task.ParentID, err)
+	}
+	s.publishPipelineTasks(ctx, task.ParentID, tasks)
+	return nil
+}
+
+// publishPipelineTasks 通过 TaskPublisher 发布子任务，发布失败的子任务标记为失败并继续推进流水线
+func (s *taskService) publishPipelineTasks(ctx context.Context, pipelineID string, tasks []*repository.Task) {
+	for _, task := range tasks {
+		err := s.taskPublisher.PublishScanTask(ctx, task.SubType, task.Priority, task.Payload)
+		if err == nil {
+			continue
+		}
+		msg := fmt.Sprintf("Failed to publish task to message queue: %v", err)
+		_ = s.taskRepo.UpdateStatus(ctx, task.ID, string(domain.TaskStatusFailed), msg)
+		if err := s.advancePipeline(ctx, task, string(domain.TaskStatusFailed), msg, nil); err != nil {
+			logger.Logger.Error("failed to advance pipeline after publish failure",
+				zap.String("pipelineID", pipelineID),
+				zap.String("taskID", task.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// syncPipelineStage 子任务状态变化时推进所属流水线
+func (s *taskService) syncPipelineStage(ctx context.Context, id, status, reason string, findings map[string]int) error {
+	task, err := s.taskRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if task.ParentID == "" {
+		return nil
+	}
+	return s.advancePipeline(ctx, task, status, reason, findings)
+}
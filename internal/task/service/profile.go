@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+
+	"github.com/blackarbiter/go-sac/internal/task/repository"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/profile"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+)
+
+// ErrProfileNotFound 扫描 profile 或指定版本不存在
+var ErrProfileNotFound = repository.ErrProfileNotFound
+
+// CreateProfileRequest 创建扫描 profile（或同名 profile 的新版本）请求
+type CreateProfileRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	ScanType    string                 `json:"scan_type" binding:"required"`
+	Description string                 `json:"description"`
+	Options     map[string]interface{} `json:"options"`
+}
+
+// ProfileService 定义扫描 profile 服务接口
+type ProfileService interface {
+	CreateProfile(ctx context.Context, req *CreateProfileRequest, userID uint) (*profile.Profile, error)
+	GetProfile(ctx context.Context, name string, version int) (*profile.Profile, error)
+	ListProfiles(ctx context.Context, scanType string) ([]*profile.Profile, error)
+	ListProfileVersions(ctx context.Context, name string) ([]*profile.Profile, error)
+	GetSchema(scanType string) (*profile.Schema, error)
+	// ResolveOptions 合并 profile 与任务级选项并校验，name 为空时只校验任务选项
+	ResolveOptions(ctx context.Context, scanType domain.ScanType, name string, version int, overrides map[string]interface{}) (map[string]interface{}, *domain.ScanProfileRef, error)
+}
+
+// profileService 是ProfileService的具体实现
+type profileService struct {
+	repo repository.ProfileRepository
+	keys *crypt.KeyManager // 加密 schema 中标记为 writeOnly 的凭据字段
+}
+
+// NewProfileService 创建一个新的扫描 profile 服务实例
+func NewProfileService(repo repository.ProfileRepository, keys *crypt.KeyManager) ProfileService {
+	return &profileService{repo: repo, keys: keys}
+}
+
+// CreateProfile 校验后保存为新版本，profile 可以只提供部分选项，必填项在创建任务时检查
+func (s *profileService) CreateProfile(ctx context.Context, req *CreateProfileRequest, userID uint) (*profile.Profile, error) {
+	if err := profile.ValidateName(req.Name); err != nil {
+		return nil, err
+	}
+	scanType, err := domain.ParseScanType(req.ScanType)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := profile.SchemaFor(scanType)
+	if err != nil {
+		return nil, err
+	}
+	options := req.Options
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	if err := schema.ValidatePartial(options); err != nil {
+		return nil, err
+	}
+	// 凭据绑定 profile 与扫描目标，复制到其它 profile 或任务中的密文无法解密
+	binding := profile.ProfileBinding(req.Name, profile.CredentialScope(scanType, options))
+	if options, err = schema.SealSecrets(options, s.keys, binding); err != nil {
+		return nil, err
+	}
+
+	p := &profile.Profile{
+		Name:        req.Name,
+		ScanType:    scanType.String(),
+		Description: req.Description,
+		Options:     options,
+		CreatedBy:   userID,
+	}
+	if err := s.repo.CreateVersion(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetProfile 获取指定版本，version 为 0 时返回最新版本
+func (s *profileService) GetProfile(ctx context.Context, name string, version int) (*profile.Profile, error) {
+	return s.repo.FindVersion(ctx, name, version)
+}
+
+// ListProfiles 列出各 profile 的最新版本
+func (s *profileService) ListProfiles(ctx context.Context, scanType string) ([]*profile.Profile, error) {
+	if scanType != "" {
+		parsed, err := domain.ParseScanType(scanType)
+		if err != nil {
+			return nil, err
+		}
+		scanType = parsed.String()
+	}
+	return s.repo.ListLatest(ctx, scanType)
+}
+
+// ListProfileVersions 列出 profile 的全部版本
+func (s *profileService) ListProfileVersions(ctx context.Context, name string) ([]*profile.Profile, error) {
+	return s.repo.ListVersions(ctx, name)
+}
+
+// GetSchema 获取扫描类型的选项 schema
+func (s *profileService) GetSchema(scanType string) (*profile.Schema, error) {
+	parsed, err := domain.ParseScanType(scanType)
+	if err != nil {
+		return nil, err
+	}
+	return profile.SchemaFor(parsed)
+}
+
+// ResolveOptions 合并 profile 与任务级选项并校验，凭据在返回前以扫描目标重新加密：
+// profile 中的密文只能以该 profile 的绑定解密，任务级选项只接受明文凭据
+func (s *profileService) ResolveOptions(ctx context.Context, scanType domain.ScanType, name string, version int, overrides map[string]interface{}) (map[string]interface{}, *domain.ScanProfileRef, error) {
+	var (
+		p   *profile.Profile
+		ref *domain.ScanProfileRef
+		err error
+	)
+	if name != "" {
+		if p, err = s.repo.FindVersion(ctx, name, version); err != nil {
+			return nil, nil, err
+		}
+		ref = p.Ref()
+	}
+
+	options, err := profile.Resolve(scanType, p, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := profile.SchemaFor(scanType)
+	if err != nil {
+		return nil, nil, err
+	}
+	var from string
+	if p != nil {
+		from = profile.ProfileBinding(p.Name, profile.CredentialScope(scanType, p.Options))
+	}
+	if options, err = schema.ResealSecrets(options, s.keys, from, profile.CredentialScope(scanType, options)); err != nil {
+		return nil, nil, err
+	}
+	return options, ref, nil
+}
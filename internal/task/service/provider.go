@@ -4,6 +4,7 @@ import (
 	"github.com/blackarbiter/go-sac/internal/task/repository"
 	"github.com/blackarbiter/go-sac/pkg/config"
 	"github.com/blackarbiter/go-sac/pkg/mq/rabbitmq"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
 	"github.com/google/wire"
 )
 
@@ -11,11 +12,18 @@ import (
 var ProviderSet = wire.NewSet(
 	ProvideTaskService,
 	ProvideTaskPublisher,
+	ProvideProfileService,
 )
 
 // ProvideTaskService 提供任务服务实例
-func ProvideTaskService(repo repository.TaskRepository, publisher *rabbitmq.TaskPublisher) TaskService {
-	return NewTaskService(repo, publisher)
+func ProvideTaskService(repo repository.TaskRepository, pipelineRepo repository.PipelineRepository, assetRepo repository.AssetRepository, publisher *rabbitmq.TaskPublisher, profiles ProfileService) TaskService {
+	return NewTaskService(repo, pipelineRepo, assetRepo, publisher, profiles)
+}
+
+// ProvideProfileService 提供扫描 profile 服务实例
+// 凭据使用 security.aes_key 加密，扫描服务以同一密钥解密，因此不启用自动轮换
+func ProvideProfileService(repo repository.ProfileRepository, cfg *config.Config) ProfileService {
+	return NewProfileService(repo, crypt.NewKeyManager([]byte(cfg.Security.AESKey), 0))
 }
 
 // ProvideTaskPublisher 提供任务发布者实例
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/blackarbiter/go-sac/internal/task/repository"
 	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
 	"github.com/blackarbiter/go-sac/pkg/mq/rabbitmq"
+	"go.uber.org/zap"
 )
 
 // TaskDTO 表示任务数据传输对象
@@ -27,6 +30,9 @@ type TaskDTO struct {
 	CompletedAt string `json:"completed_at,omitempty"`
 	ErrorMsg    string `json:"error_msg,omitempty"`
 	RetryCount  int    `json:"retry_count"`
+	Profile     string `json:"profile,omitempty"`   // 扫描 profile 及版本
+	ParentID    string `json:"parent_id,omitempty"` // 所属流水线ID
+	Stage       string `json:"stage,omitempty"`     // 所属流水线阶段
 }
 
 // CreateScanTaskRequest 表示创建扫描任务请求
@@ -36,6 +42,11 @@ type CreateScanTaskRequest struct {
 	ScanType  string                 `json:"scan_type" binding:"required"`
 	Options   map[string]interface{} `json:"options"`
 	Priority  int                    `json:"priority"`
+	// BaseCommit 代码仓库资产的基线提交（通常取 RepositoryAsset.LastCommitHash），设置时只扫描其后变更的文件
+	BaseCommit string `json:"base_commit"`
+	// Profile 扫描 profile 名称，Options 中的同名选项覆盖 profile；ProfileVersion 为 0 时使用最新版本
+	Profile        string `json:"profile"`
+	ProfileVersion int    `json:"profile_version"`
 }
 
 // CreateAssetTaskRequest 表示创建资产任务请求
@@ -60,6 +71,8 @@ type BatchCreateAssetTaskRequest struct {
 type UpdateTaskStatusRequest struct {
 	Status   string `json:"status" binding:"required,oneof=pending running completed failed cancelled"`
 	ErrorMsg string `json:"error_msg"`
+	// Findings 扫描结束时按严重等级的发现项数量，流水线据此判断下游阶段的执行条件
+	Findings map[string]int `json:"findings"`
 }
 
 // TaskQueryParams 任务查询参数
@@ -89,19 +102,27 @@ type TaskService interface {
 	ListTasks(ctx context.Context, params *TaskQueryParams) (*TaskListResponse, error)
 	CancelTask(ctx context.Context, id string) error
 	BatchCancelTasks(ctx context.Context, ids []string) ([]string, error)
+	CreatePipeline(ctx context.Context, req *CreatePipelineRequest, userID uint) (*PipelineDTO, error)
+	GetPipeline(ctx context.Context, id string) (*PipelineDTO, error)
 }
 
 // taskService 是TaskService的具体实现
 type taskService struct {
 	taskRepo      repository.TaskRepository
+	pipelineRepo  repository.PipelineRepository
+	assetRepo     repository.AssetRepository
 	taskPublisher *rabbitmq.TaskPublisher
+	profiles      ProfileService
 }
 
 // NewTaskService 创建一个新的任务服务实例
-func NewTaskService(taskRepo repository.TaskRepository, taskPublisher *rabbitmq.TaskPublisher) TaskService {
+func NewTaskService(taskRepo repository.TaskRepository, pipelineRepo repository.PipelineRepository, assetRepo repository.AssetRepository, taskPublisher *rabbitmq.TaskPublisher, profiles ProfileService) TaskService {
 	return &taskService{
 		taskRepo:      taskRepo,
+		pipelineRepo:  pipelineRepo,
+		assetRepo:     assetRepo,
 		taskPublisher: taskPublisher,
+		profiles:      profiles,
 	}
 }
 
@@ -120,6 +141,9 @@ func convertToDTO(task *repository.Task) *TaskDTO {
 		UpdatedAt:  task.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		ErrorMsg:   task.ErrorMsg,
 		RetryCount: task.RetryCount,
+		Profile:    task.Profile,
+		ParentID:   task.ParentID,
+		Stage:      task.Stage,
 	}
 
 	if task.StartedAt != nil {
@@ -135,33 +159,69 @@ func convertToDTO(task *repository.Task) *TaskDTO {
 
 // CreateScanTask 创建扫描任务
 func (s *taskService) CreateScanTask(ctx context.Context, req *CreateScanTaskRequest, userID uint) (string, error) {
+	repoTask, err := s.newScanTask(ctx, req, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// 保存到数据库
+	if err := s.taskRepo.Create(ctx, repoTask); err != nil {
+		return "", err
+	}
+
+	// 发布到消息队列
+	if err := s.taskPublisher.PublishScanTask(ctx, req.ScanType, repoTask.Priority, repoTask.Payload); err != nil {
+		// 如果发布失败，更新任务状态为失败
+		_ = s.taskRepo.UpdateStatus(ctx, repoTask.ID, string(domain.TaskStatusFailed), "Failed to publish task to message queue")
+		return "", err
+	}
+
+	return repoTask.ID, nil
+}
+
+// newScanTask 解析请求、按扫描 profile 与 schema 生成校验后的选项并构造任务实体
+func (s *taskService) newScanTask(ctx context.Context, req *CreateScanTaskRequest, userID uint) (*repository.Task, error) {
 	// 解析扫描类型
 	scanType, err := domain.ParseScanType(req.ScanType)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 解析资产类型
 	assetType, err := domain.ParseAssetType(req.AssetType)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	// 合并 profile 选项并按扫描类型的 schema 校验、补全默认值
+	options, profileRef, err := s.profiles.ResolveOptions(ctx, scanType, req.Profile, req.ProfileVersion, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// 所属组织以资产服务的记录为准，不采信客户端提交的值
+	organizationID, err := s.assetRepo.FindOrganizationID(ctx, req.AssetID)
+	if err != nil {
+		return nil, fmt.Errorf("lookup asset organization: %w", err)
 	}
 
 	// 创建任务
-	task, err := domain.NewScanTask(
-		scanType,
-		req.AssetID,
-		assetType,
-		req.Options,
-		domain.TaskPriority(req.Priority),
-		userID,
-	)
+	task, err := domain.NewScanTaskFromPayload(domain.ScanTaskPayload{
+		AssetID:        req.AssetID,
+		AssetType:      assetType,
+		ScanType:       scanType,
+		Options:        options,
+		Priority:       domain.TaskPriority(req.Priority),
+		BaseCommit:     req.BaseCommit,
+		Profile:        profileRef,
+		OrganizationID: organizationID,
+	}, userID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 转换为仓库实体
-	repoTask := &repository.Task{
+	return &repository.Task{
 		ID:        task.ID,
 		Type:      string(task.Type),
 		Status:    string(task.Status),
@@ -171,21 +231,8 @@ func (s *taskService) CreateScanTask(ctx context.Context, req *CreateScanTaskReq
 		AssetType: req.AssetType,
 		Payload:   task.Payload,
 		UserID:    task.UserID,
-	}
-
-	// 保存到数据库
-	if err := s.taskRepo.Create(ctx, repoTask); err != nil {
-		return "", err
-	}
-
-	// 发布到消息队列
-	if err := s.taskPublisher.PublishScanTask(ctx, req.ScanType, int(task.Priority), task.Payload); err != nil {
-		// 如果发布失败，更新任务状态为失败
-		_ = s.taskRepo.UpdateStatus(ctx, repoTask.ID, string(domain.TaskStatusFailed), "Failed to publish task to message queue")
-		return "", err
-	}
-
-	return repoTask.ID, nil
+		Profile:   profileRef.String(),
+	}, nil
 }
 
 // CreateAssetTask 创建资产任务
@@ -242,45 +289,11 @@ func (s *taskService) BatchCreateScanTasks(ctx context.Context, req *BatchCreate
 	taskIDs := make([]string, 0, len(req.Tasks))
 
 	// 创建所有任务
-	for _, taskReq := range req.Tasks {
-		// 解析扫描类型
-		scanType, err := domain.ParseScanType(taskReq.ScanType)
+	for i := range req.Tasks {
+		repoTask, err := s.newScanTask(ctx, &req.Tasks[i], userID)
 		if err != nil {
 			return nil, err
 		}
-
-		// 解析资产类型
-		assetType, err := domain.ParseAssetType(taskReq.AssetType)
-		if err != nil {
-			return nil, err
-		}
-
-		// 创建任务
-		task, err := domain.NewScanTask(
-			scanType,
-			taskReq.AssetID,
-			assetType,
-			taskReq.Options,
-			domain.TaskPriority(taskReq.Priority),
-			userID,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// 转换为仓库实体
-		repoTask := &repository.Task{
-			ID:        task.ID,
-			Type:      string(task.Type),
-			Status:    string(task.Status),
-			Priority:  int(task.Priority),
-			SubType:   taskReq.ScanType, // 存储扫描类型
-			AssetID:   taskReq.AssetID,
-			AssetType: taskReq.AssetType,
-			Payload:   task.Payload,
-			UserID:    task.UserID,
-		}
-
 		tasks = append(tasks, repoTask)
 	}
 
@@ -404,9 +417,12 @@ func (s *taskService) BatchGetTaskStatus(ctx context.Context, ids []string) ([]*
 	return tasks, nil
 }
 
-// UpdateTaskStatus 更新任务状态
+// UpdateTaskStatus 更新任务状态，流水线子任务同时推进所属流水线
 func (s *taskService) UpdateTaskStatus(ctx context.Context, id string, req *UpdateTaskStatusRequest) error {
-	return s.taskRepo.UpdateStatus(ctx, id, req.Status, req.ErrorMsg)
+	if err := s.taskRepo.UpdateStatus(ctx, id, req.Status, req.ErrorMsg); err != nil {
+		return err
+	}
+	return s.syncPipelineStage(ctx, id, req.Status, req.ErrorMsg, req.Findings)
 }
 
 // ListTasks 列出任务
@@ -451,6 +467,7 @@ func (s *taskService) ListTasks(ctx context.Context, params *TaskQueryParams) (*
 }
 
 // CancelTask 取消任务
+// 等待中的任务从消息队列删除；运行中的扫描任务广播取消指令，由执行该任务的扫描服务实例终止
 func (s *taskService) CancelTask(ctx context.Context, id string) error {
 	// 获取任务信息
 	task, err := s.taskRepo.FindByID(ctx, id)
@@ -458,9 +475,10 @@ func (s *taskService) CancelTask(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to find task: %w", err)
 	}
 
-	// 检查任务状态是否为等待扫描
-	if task.Status != string(domain.TaskStatusPending) {
-		return fmt.Errorf("task is not in pending status, current status: %s", task.Status)
+	// 检查任务状态：等待中的任务均可取消，运行中仅支持扫描任务
+	running := task.Status == string(domain.TaskStatusRunning)
+	if task.Status != string(domain.TaskStatusPending) && !(running && task.Type == string(domain.TaskTypeScan)) {
+		return fmt.Errorf("task cannot be cancelled in current status: %s", task.Status)
 	}
 
 	// 从消息队列中删除任务
@@ -471,9 +489,31 @@ func (s *taskService) CancelTask(ctx context.Context, id string) error {
 			return fmt.Errorf("failed to unmarshal task payload: %w", err)
 		}
 
-		// 从消息队列中删除任务
-		if err := s.taskPublisher.DeleteScanTask(ctx, payload.ScanType.String(), task.Priority, task.Payload); err != nil {
-			return fmt.Errorf("failed to delete task from message queue: %w", err)
+		cancelPayload, err := json.Marshal(domain.TaskCancelPayload{
+			TaskID:      payload.TaskID,
+			ScanType:    payload.ScanType,
+			RequestedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal cancel payload: %w", err)
+		}
+
+		if running {
+			// 通知扫描服务终止运行中的任务
+			if err := s.taskPublisher.PublishTaskCancel(ctx, cancelPayload); err != nil {
+				return fmt.Errorf("failed to publish task cancel: %w", err)
+			}
+		} else {
+			// 从消息队列中删除任务
+			if err := s.taskPublisher.DeleteScanTask(ctx, payload.ScanType.String(), task.Priority, task.Payload); err != nil {
+				return fmt.Errorf("failed to delete task from message queue: %w", err)
+			}
+			// 任务可能已被扫描服务取出排队，同时广播取消指令
+			if err := s.taskPublisher.PublishTaskCancel(ctx, cancelPayload); err != nil {
+				logger.Logger.Warn("failed to publish cancel for pending task",
+					zap.String("taskID", id),
+					zap.Error(err))
+			}
 		}
 	} else if task.Type == string(domain.TaskTypeAsset) {
 		// 解析任务操作类型
@@ -493,6 +533,10 @@ func (s *taskService) CancelTask(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
 
+	// 流水线子任务取消后，依赖它的阶段按条件跳过
+	if task.ParentID != "" {
+		return s.advancePipeline(ctx, task, string(domain.TaskStatusCancelled), "Task cancelled by user", nil)
+	}
 	return nil
 }
 
@@ -4,15 +4,31 @@ import (
 	"github.com/blackarbiter/go-sac/internal/task/service"
 )
 
-// 全局任务处理器实例
-var taskHandler *TaskHandler
+// 全局处理器实例
+var (
+	taskHandler     *TaskHandler
+	profileHandler  *ProfileHandler
+	pipelineHandler *PipelineHandler
+)
 
 // InitHandlers 初始化所有处理程序
-func InitHandlers(taskService service.TaskService) {
+func InitHandlers(taskService service.TaskService, profileService service.ProfileService) {
 	taskHandler = NewTaskHandler(taskService)
+	profileHandler = NewProfileHandler(profileService)
+	pipelineHandler = NewPipelineHandler(taskService)
 }
 
 // GetTaskHandler 获取任务处理器实例
 func GetTaskHandler() *TaskHandler {
 	return taskHandler
 }
+
+// GetProfileHandler 获取扫描 profile 处理器实例
+func GetProfileHandler() *ProfileHandler {
+	return profileHandler
+}
+
+// GetPipelineHandler 获取扫描流水线处理器实例
+func GetPipelineHandler() *PipelineHandler {
+	return pipelineHandler
+}
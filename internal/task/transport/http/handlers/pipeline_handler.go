@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blackarbiter/go-sac/internal/task/service"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PipelineHandler 处理扫描流水线相关请求
+type PipelineHandler struct {
+	taskService service.TaskService
+}
+
+// NewPipelineHandler 创建扫描流水线处理程序
+func NewPipelineHandler(taskService service.TaskService) *PipelineHandler {
+	return &PipelineHandler{
+		taskService: taskService,
+	}
+}
+
+// CreatePipeline 处理创建扫描流水线请求
+func (h *PipelineHandler) CreatePipeline(c *gin.Context) {
+	var req service.CreatePipelineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取用户ID（来自JWT中间件）
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	p, err := h.taskService.CreatePipeline(c.Request.Context(), &req, userID.(uint))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPipeline) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if status, body := optionsError(err); status != 0 {
+			c.JSON(status, body)
+			return
+		}
+		logger.Logger.Error("failed to create scan pipeline", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create scan pipeline: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// GetPipeline 处理获取扫描流水线请求，返回各阶段状态及结果汇总
+func (h *PipelineHandler) GetPipeline(c *gin.Context) {
+	id := c.Param("id")
+	p, err := h.taskService.GetPipeline(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrPipelineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Logger.Error("failed to get scan pipeline", zap.Error(err), zap.String("pipeline_id", id))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get scan pipeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
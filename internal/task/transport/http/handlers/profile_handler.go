@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blackarbiter/go-sac/internal/task/service"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"github.com/blackarbiter/go-sac/pkg/profile"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProfileHandler 处理扫描 profile 相关请求
+type ProfileHandler struct {
+	profileService service.ProfileService
+}
+
+// NewProfileHandler 创建扫描 profile 处理程序
+func NewProfileHandler(profileService service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// CreateProfile 处理创建扫描 profile 请求，同名 profile 已存在时保存为新版本
+func (h *ProfileHandler) CreateProfile(c *gin.Context) {
+	var req service.CreateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取用户ID（来自JWT中间件）
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user id not found in context"})
+		return
+	}
+
+	p, err := h.profileService.CreateProfile(c.Request.Context(), &req, userID.(uint))
+	if err != nil {
+		if status, body := optionsError(err); status != 0 {
+			c.JSON(status, body)
+			return
+		}
+		logger.Logger.Error("failed to create scan profile", zap.Error(err), zap.String("name", req.Name))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create scan profile: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// GetProfile 处理获取扫描 profile 请求，未指定 version 时返回最新版本
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	version := 0
+	if v := c.Query("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+			return
+		}
+		version = n
+	}
+
+	p, err := h.profileService.GetProfile(c.Request.Context(), c.Param("name"), version)
+	if err != nil {
+		if errors.Is(err, service.ErrProfileNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Logger.Error("failed to get scan profile", zap.Error(err), zap.String("name", c.Param("name")))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get scan profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// ListProfileVersions 处理列出扫描 profile 全部版本请求
+func (h *ProfileHandler) ListProfileVersions(c *gin.Context) {
+	profiles, err := h.profileService.ListProfileVersions(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrProfileNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Logger.Error("failed to list scan profile versions", zap.Error(err), zap.String("name", c.Param("name")))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list scan profile versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": profiles})
+}
+
+// ListProfiles 处理列出扫描 profile 请求，可按 scan_type 过滤
+func (h *ProfileHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.profileService.ListProfiles(c.Request.Context(), c.Query("scan_type"))
+	if err != nil {
+		logger.Logger.Error("failed to list scan profiles", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list scan profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": profiles})
+}
+
+// GetSchema 处理获取扫描类型选项 schema 请求
+func (h *ProfileHandler) GetSchema(c *gin.Context) {
+	schema, err := h.profileService.GetSchema(c.Param("scan_type"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// optionsError 将扫描选项校验失败与 profile 不存在转换为 400 响应，其它错误返回 0
+func optionsError(err error) (int, gin.H) {
+	var invalid profile.ValidationErrors
+	switch {
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest, gin.H{"error": err.Error(), "details": invalid}
+	case errors.Is(err, service.ErrProfileNotFound), errors.Is(err, profile.ErrInvalidName):
+		return http.StatusBadRequest, gin.H{"error": err.Error()}
+	}
+	return 0, nil
+}
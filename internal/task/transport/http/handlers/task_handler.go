@@ -39,6 +39,10 @@ func (h *TaskHandler) CreateScanTask(c *gin.Context) {
 	// 调用服务层创建任务
 	taskID, err := h.taskService.CreateScanTask(c.Request.Context(), &req, userID.(uint))
 	if err != nil {
+		if status, body := optionsError(err); status != 0 {
+			c.JSON(status, body)
+			return
+		}
 		logger.Logger.Error("failed to create scan task", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create scan task: " + err.Error()})
 		return
@@ -91,6 +95,10 @@ func (h *TaskHandler) BatchCreateScanTasks(c *gin.Context) {
 	// 调用服务层批量创建任务
 	taskIDs, err := h.taskService.BatchCreateScanTasks(c.Request.Context(), &req, userID.(uint))
 	if err != nil {
+		if status, body := optionsError(err); status != 0 {
+			c.JSON(status, body)
+			return
+		}
 		logger.Logger.Error("failed to batch create scan tasks", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to batch create scan tasks: " + err.Error()})
 		return
@@ -12,14 +12,16 @@ import (
 
 // 依赖
 var (
-	taskService service.TaskService
+	taskService    service.TaskService
+	profileService service.ProfileService
 )
 
-// SetTaskService 设置任务服务
-func SetTaskService(svc service.TaskService) {
+// SetTaskService 设置任务服务与扫描 profile 服务
+func SetTaskService(svc service.TaskService, profiles service.ProfileService) {
 	taskService = svc
+	profileService = profiles
 	// 初始化处理程序
-	handlers.InitHandlers(taskService)
+	handlers.InitHandlers(taskService, profileService)
 }
 
 func NewRouter() *gin.Engine {
@@ -55,6 +57,29 @@ func NewRouter() *gin.Engine {
 			tasks.POST("/:id/cancel", h.CancelTask)             // 取消任务
 			tasks.POST("/batch/cancel", h.BatchCancelTasks)     // 批量取消任务
 		}
+
+		pipelines := api.Group("/pipelines")
+		{
+			h := handlers.GetPipelineHandler()
+
+			// 扫描流水线
+			pipelines.POST("", h.CreatePipeline) // 创建流水线
+			pipelines.GET("/:id", h.GetPipeline) // 获取流水线及阶段状态
+		}
+
+		profiles := api.Group("/profiles")
+		{
+			h := handlers.GetProfileHandler()
+
+			// 扫描 profile 管理
+			profiles.POST("", h.CreateProfile)                     // 创建 profile 或保存新版本
+			profiles.GET("", h.ListProfiles)                       // 列出各 profile 最新版本
+			profiles.GET("/:name", h.GetProfile)                   // 获取 profile（?version= 指定版本）
+			profiles.GET("/:name/versions", h.ListProfileVersions) // 列出 profile 全部版本
+		}
+
+		// 扫描类型的选项 schema
+		api.GET("/schemas/:scan_type", handlers.GetProfileHandler().GetSchema)
 	}
 
 	return r
@@ -21,9 +21,9 @@ type Server struct {
 }
 
 // NewServer 创建一个新的HTTP服务器
-func NewServer(cfg *config.Config, taskService service.TaskService) *Server {
+func NewServer(cfg *config.Config, taskService service.TaskService, profileService service.ProfileService) *Server {
 	// 设置任务服务
-	SetTaskService(taskService)
+	SetTaskService(taskService, profileService)
 
 	// 创建路由
 	router := NewRouter()
@@ -61,6 +61,7 @@ type StorageConfig struct {
 		SecretKey string `yaml:"secret_key" mapstructure:"secret_key"`
 		UseSSL    bool   `yaml:"use_ssl" mapstructure:"use_ssl"`
 		Bucket    string `yaml:"bucket"`
+		LogPrefix string `yaml:"log_prefix" mapstructure:"log_prefix"` // 扫描输出日志的对象前缀，按任务ID分目录
 	} `yaml:"minio"`
 }
 
@@ -93,13 +94,40 @@ type ScannerConfig struct {
 		QueueSize  int `yaml:"queue_size" mapstructure:"queue_size"`
 	} `yaml:"concurrency" mapstructure:"concurrency"`
 
-	// 统一的熔断器配置
+	// 熔断器配置，每个扫描类型使用独立的熔断器
 	CircuitBreaker struct {
-		Threshold         uint32        `yaml:"threshold" mapstructure:"threshold"`                   // 总错误阈值
-		CriticalThreshold uint32        `yaml:"critical_threshold" mapstructure:"critical_threshold"` // 严重错误阈值
-		ResetTimeout      time.Duration `yaml:"reset_timeout" mapstructure:"reset_timeout"`           // 重置超时时间
+		Threshold           uint32        `yaml:"threshold" mapstructure:"threshold"`                           // 总错误阈值
+		CriticalThreshold   uint32        `yaml:"critical_threshold" mapstructure:"critical_threshold"`         // 严重错误阈值
+		ResetTimeout        time.Duration `yaml:"reset_timeout" mapstructure:"reset_timeout"`                   // 重置超时时间
+		HalfOpenMaxRequests uint32        `yaml:"half_open_max_requests" mapstructure:"half_open_max_requests"` // 半开状态试探请求数
+
+		// 按扫描类型覆盖阈值，键为扫描类型名称（不区分大小写），未配置的字段继承上面的值
+		Overrides map[string]CircuitBreakerConfig `yaml:"overrides" mapstructure:"overrides"`
+		// DAST 按目标主机的熔断器，未配置的字段继承 DAST 的值
+		DASTHost CircuitBreakerConfig `yaml:"dast_host" mapstructure:"dast_host"`
 	} `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
 
+	// 自适应超时策略，各扫描器的 timeout 作为基准超时
+	TimeoutPolicy TimeoutPolicyConfig `yaml:"timeout_policy" mapstructure:"timeout_policy"`
+
+	// 卡死扫描子进程巡检
+	Watchdog WatchdogConfig `yaml:"watchdog" mapstructure:"watchdog"`
+
+	// 扫描子进程的 cgroup v2 资源限制
+	Cgroup CgroupConfig `yaml:"cgroup" mapstructure:"cgroup"`
+
+	// 扫描子进程的隔离配置，与各扫描器的 security_profile 配合使用
+	ProcessSandbox ProcessSandboxConfig `yaml:"process_sandbox" mapstructure:"process_sandbox"`
+
+	// 外部扫描插件配置
+	Plugins PluginConfig `yaml:"plugins" mapstructure:"plugins"`
+
+	// 扫描命令输出捕获
+	OutputCapture OutputCaptureConfig `yaml:"output_capture" mapstructure:"output_capture"`
+
+	// 上传文件落盘根目录，任务选项中的本地路径（source_path、archive_path、image_path 等）必须位于其下，为空时不接受本地路径
+	UploadRoot string `yaml:"upload_root" mapstructure:"upload_root"`
+
 	// 优先级调度器配置
 	PriorityScheduler struct {
 		ChannelCapacity struct {
@@ -112,6 +140,8 @@ type ScannerConfig struct {
 			Medium float64 `yaml:"medium" mapstructure:"medium"`
 			Low    float64 `yaml:"low" mapstructure:"low"`
 		} `yaml:"priority_weights" mapstructure:"priority_weights"`
+		// AgingThreshold 任务在队列中等待超过该时长后提升一级优先级，避免低优先级任务饿死
+		AgingThreshold time.Duration `yaml:"aging_threshold" mapstructure:"aging_threshold"`
 	} `yaml:"priority_scheduler" mapstructure:"priority_scheduler"`
 
 	SAST struct {
@@ -126,6 +156,8 @@ type ScannerConfig struct {
 			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
 		} `yaml:"security_profile" mapstructure:"security_profile"`
 		Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir string        `yaml:"work_dir" mapstructure:"work_dir"` // 沙箱工作目录根路径
+		Tool    ToolConfig    `yaml:"tool" mapstructure:"tool"`         // 外部SAST工具配置
 	} `yaml:"sast" mapstructure:"sast"`
 	DAST struct {
 		ResourceProfile struct {
@@ -138,7 +170,9 @@ type ScannerConfig struct {
 			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
 			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
 		} `yaml:"security_profile" mapstructure:"security_profile"`
-		Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		Timeout      time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		Crawler      CrawlerConfig `yaml:"crawler" mapstructure:"crawler"`             // 爬虫配置
+		ActiveChecks []string      `yaml:"active_checks" mapstructure:"active_checks"` // 启用的主动检测项，为空时启用全部
 	} `yaml:"dast" mapstructure:"dast"`
 	SCA struct {
 		ResourceProfile struct {
@@ -151,8 +185,360 @@ type ScannerConfig struct {
 			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
 			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
 		} `yaml:"security_profile" mapstructure:"security_profile"`
-		Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		Timeout   time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir   string        `yaml:"work_dir" mapstructure:"work_dir"`       // 沙箱工作目录根路径
+		VulnDBDir string        `yaml:"vuln_db_dir" mapstructure:"vuln_db_dir"` // 离线OSV漏洞库目录
 	} `yaml:"sca" mapstructure:"sca"`
+	Secrets struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout  time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir  string        `yaml:"work_dir" mapstructure:"work_dir"` // 沙箱工作目录根路径
+		Detector SecretsConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"secrets" mapstructure:"secrets"`
+	PortScan struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout time.Duration  `yaml:"timeout" mapstructure:"timeout"`
+		Probe   PortScanConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"port_scan" mapstructure:"port_scan"`
+	Image struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir   string        `yaml:"work_dir" mapstructure:"work_dir"`       // 镜像下载与层解包沙箱目录
+		VulnDBDir string        `yaml:"vuln_db_dir" mapstructure:"vuln_db_dir"` // 离线OSV漏洞库目录，为空时复用 sca.vuln_db_dir
+	} `yaml:"image" mapstructure:"image"`
+	RequirementAnalysis struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+		Materials SecurityMaterialConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"requirement_analysis" mapstructure:"requirement_analysis"`
+	ThreatModeling struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+		Materials SecurityMaterialConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"threat_modeling" mapstructure:"threat_modeling"`
+	HostCheck struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+		Check     HostCheckConfig        `yaml:",inline" mapstructure:",squash"`
+		Materials SecurityMaterialConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"host_check" mapstructure:"host_check"`
+	ComplianceAudit struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+		Audit     ComplianceAuditConfig  `yaml:",inline" mapstructure:",squash"`
+		Materials SecurityMaterialConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"compliance_audit" mapstructure:"compliance_audit"`
+	SecuritySpec struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout   time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir   string                 `yaml:"work_dir" mapstructure:"work_dir"` // 沙箱工作目录根路径
+		Check     SecuritySpecConfig     `yaml:",inline" mapstructure:",squash"`
+		Materials SecurityMaterialConfig `yaml:",inline" mapstructure:",squash"`
+	} `yaml:"security_spec" mapstructure:"security_spec"`
+	BlackBox struct {
+		ResourceProfile struct {
+			MinCPU   int `yaml:"min_cpu" mapstructure:"min_cpu"`
+			MaxCPU   int `yaml:"max_cpu" mapstructure:"max_cpu"`
+			MemoryMB int `yaml:"memory_mb" mapstructure:"memory_mb"`
+		} `yaml:"resource_profile" mapstructure:"resource_profile"`
+		SecurityProfile struct {
+			RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+			RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+			NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+		} `yaml:"security_profile" mapstructure:"security_profile"`
+		Timeout time.Duration  `yaml:"timeout" mapstructure:"timeout"`
+		WorkDir string         `yaml:"work_dir" mapstructure:"work_dir"` // 流量文件下载沙箱目录
+		Replay  BlackBoxConfig `yaml:"replay" mapstructure:"replay"`     // 流量回放配置
+	} `yaml:"blackbox" mapstructure:"blackbox"`
+}
+
+// CgroupConfig cgroup v2 资源限制配置
+type CgroupConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Root    string `yaml:"root" mapstructure:"root"`         // cgroupfs 挂载点
+	Parent  string `yaml:"parent" mapstructure:"parent"`     // 扫描任务父控制组，相对 root，每次执行在其下创建子控制组
+	PidsMax int    `yaml:"pids_max" mapstructure:"pids_max"` // 单次执行的进程数上限
+}
+
+// ProcessSandboxConfig 扫描子进程隔离配置
+type ProcessSandboxConfig struct {
+	Strict         bool     `yaml:"strict" mapstructure:"strict"`                     // security_profile 无法满足时拒绝启动子进程
+	ReadOnlyTarget bool     `yaml:"read_only_target" mapstructure:"read_only_target"` // 以只读绑定挂载方式向扫描工具暴露扫描目标
+	WorkDir        string   `yaml:"work_dir" mapstructure:"work_dir"`                 // 每次执行的临时目录根路径，为空时使用系统临时目录
+	EnvAllowlist   []string `yaml:"env_allowlist" mapstructure:"env_allowlist"`       // 透传给子进程的环境变量，以 * 结尾表示前缀匹配
+}
+
+// TimeoutPolicyConfig 自适应超时策略配置，0 值使用缺省值
+type TimeoutPolicyConfig struct {
+	BaseTimeout     time.Duration      `yaml:"base_timeout" mapstructure:"base_timeout"`         // 扫描器未配置 timeout 时的基准超时
+	PriorityFactor  float64            `yaml:"priority_factor" mapstructure:"priority_factor"`   // 高优先级放宽、低优先级收紧的比例
+	TypeMultipliers map[string]float64 `yaml:"type_multipliers" mapstructure:"type_multipliers"` // 按扫描类型的超时倍数，键为扫描类型名称
+	PerMB           time.Duration      `yaml:"per_mb" mapstructure:"per_mb"`                     // 目标每 MB 附加的时间
+	PerItem         time.Duration      `yaml:"per_item" mapstructure:"per_item"`                 // 目标每个 URL/端口附加的时间
+	SoftRatio       float64            `yaml:"soft_ratio" mapstructure:"soft_ratio"`             // 软超时占硬超时的比例
+	CriticalGrace   time.Duration      `yaml:"critical_grace" mapstructure:"critical_grace"`     // 硬超时后等待进程退出的时间
+	HistoryHeadroom float64            `yaml:"history_headroom" mapstructure:"history_headroom"` // 历史 p95 的放大倍数
+	HistoryWindow   int                `yaml:"history_window" mapstructure:"history_window"`     // 每个扫描类型保留的历史样本数
+	MinSamples      int                `yaml:"min_samples" mapstructure:"min_samples"`           // 启用历史自适应的最少样本数
+	MinTimeout      time.Duration      `yaml:"min_timeout" mapstructure:"min_timeout"`           // 历史自适应结果下限
+	MaxTimeout      time.Duration      `yaml:"max_timeout" mapstructure:"max_timeout"`           // 硬超时上限
+}
+
+// WatchdogConfig 扫描子进程看门狗配置，0 值使用缺省值
+type WatchdogConfig struct {
+	Enabled        bool          `yaml:"enabled" mapstructure:"enabled"`
+	Interval       time.Duration `yaml:"interval" mapstructure:"interval"`               // 巡检间隔
+	SilenceTimeout time.Duration `yaml:"silence_timeout" mapstructure:"silence_timeout"` // 无输出超过此时间上报软超时
+	StallTimeout   time.Duration `yaml:"stall_timeout" mapstructure:"stall_timeout"`     // 无 CPU 进展且无输出超过此时间终止进程组
+	ReapZombies    bool          `yaml:"reap_zombies" mapstructure:"reap_zombies"`       // 回收未被跟踪的僵尸子进程
+}
+
+// CircuitBreakerConfig 单个熔断器的阈值配置，0 值表示继承
+type CircuitBreakerConfig struct {
+	Threshold           uint32        `yaml:"threshold" mapstructure:"threshold"`
+	CriticalThreshold   uint32        `yaml:"critical_threshold" mapstructure:"critical_threshold"`
+	ResetTimeout        time.Duration `yaml:"reset_timeout" mapstructure:"reset_timeout"`
+	HalfOpenMaxRequests uint32        `yaml:"half_open_max_requests" mapstructure:"half_open_max_requests"`
+	IdleTTL             time.Duration `yaml:"idle_ttl" mapstructure:"idle_ttl"` // 仅对按主机的熔断器生效
+}
+
+// mergeInto 用已配置的字段覆盖 settings
+func (c CircuitBreakerConfig) mergeInto(settings scanner.CircuitBreakerSettings) scanner.CircuitBreakerSettings {
+	if c.Threshold > 0 {
+		settings.Threshold = c.Threshold
+	}
+	if c.CriticalThreshold > 0 {
+		settings.CriticalThreshold = c.CriticalThreshold
+	}
+	if c.ResetTimeout > 0 {
+		settings.ResetTimeout = c.ResetTimeout
+	}
+	if c.HalfOpenMaxRequests > 0 {
+		settings.HalfOpenMaxRequests = c.HalfOpenMaxRequests
+	}
+	if c.IdleTTL > 0 {
+		settings.IdleTTL = c.IdleTTL
+	}
+	return settings
+}
+
+// OutputCaptureConfig 扫描命令 stdout/stderr 捕获配置
+// 输出尾部保留在内存中用于错误信息，完整输出流式上传到 MinIO 的 <bucket>/<log_prefix>/<任务ID>/ 下
+type OutputCaptureConfig struct {
+	Upload        bool          `yaml:"upload" mapstructure:"upload"`                 // 是否上传完整输出到对象存储
+	TailBytes     int           `yaml:"tail_bytes" mapstructure:"tail_bytes"`         // 内存中保留的输出尾部字节数
+	UploadTimeout time.Duration `yaml:"upload_timeout" mapstructure:"upload_timeout"` // 命令结束后等待上传完成的时间
+}
+
+// PluginConfig 外部扫描插件配置
+// 插件为插件目录下的可执行文件，通过 describe 握手声明元数据，scan 子命令从标准输入读取任务并以 JSON 行输出事件
+type PluginConfig struct {
+	Enabled           bool          `yaml:"enabled" mapstructure:"enabled"`
+	Dir               string        `yaml:"dir" mapstructure:"dir"`                               // 插件目录
+	WorkDir           string        `yaml:"work_dir" mapstructure:"work_dir"`                     // 插件执行的工作目录根路径
+	HandshakeTimeout  time.Duration `yaml:"handshake_timeout" mapstructure:"handshake_timeout"`   // describe 握手超时
+	DiscoveryInterval time.Duration `yaml:"discovery_interval" mapstructure:"discovery_interval"` // 重新扫描插件目录并健康检查的间隔
+	Timeout           time.Duration `yaml:"timeout" mapstructure:"timeout"`                       // 单次扫描超时
+	SecurityProfile   struct {
+		RunAsUser  int  `yaml:"run_as_user" mapstructure:"run_as_user"`
+		RunAsGroup int  `yaml:"run_as_group" mapstructure:"run_as_group"`
+		NoNewPrivs bool `yaml:"no_new_privs" mapstructure:"no_new_privs"`
+	} `yaml:"security_profile" mapstructure:"security_profile"`
+}
+
+// defaultEnvAllowlist 未配置时透传给扫描子进程的环境变量
+var defaultEnvAllowlist = []string{
+	"PATH", "LANG", "LC_*", "TZ",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+}
+
+// DefaultSecurityMaterialDir 未配置时安全物料规则包的默认目录
+const DefaultSecurityMaterialDir = "./configs/security_materials"
+
+// SecurityMaterialConfig 安全物料规则包配置
+type SecurityMaterialConfig struct {
+	MaterialDir string            `yaml:"material_dir" mapstructure:"material_dir"`           // 版本化安全物料文件目录
+	Versions    map[string]string `yaml:"material_versions" mapstructure:"material_versions"` // 按物料类型固定规则包版本，未配置时使用最高版本
+}
+
+// DefaultPortScanPorts 未配置端口范围时扫描的常用端口
+const DefaultPortScanPorts = "21-23,25,53,80,110,111,135,139,143,389,443,445,465,587,636,993,995,1433,1521,2049,2375,2376,3306,3389,5432,5672,5900,5984,6379,6443,8080,8443,9000,9092,9200,11211,15672,27017"
+
+// PortScanConfig 端口扫描配置
+type PortScanConfig struct {
+	Ports            string        `yaml:"ports" mapstructure:"ports"`                           // 端口范围，如 "22,80,443,8000-8100"
+	HostConcurrency  int           `yaml:"host_concurrency" mapstructure:"host_concurrency"`     // 单个主机的并发连接数
+	MaxParallelHosts int           `yaml:"max_parallel_hosts" mapstructure:"max_parallel_hosts"` // 同时扫描的主机数
+	MaxHosts         int           `yaml:"max_hosts" mapstructure:"max_hosts"`                   // CIDR/多地址解析后的主机数上限
+	RateLimit        int           `yaml:"rate_limit" mapstructure:"rate_limit"`                 // 每秒新建连接数上限，0 表示不限制
+	ConnectTimeout   time.Duration `yaml:"connect_timeout" mapstructure:"connect_timeout"`
+	BannerGrab       bool          `yaml:"banner_grab" mapstructure:"banner_grab"` // 对开放端口读取服务横幅
+	BannerTimeout    time.Duration `yaml:"banner_timeout" mapstructure:"banner_timeout"`
+	TLSProbe         bool          `yaml:"tls_probe" mapstructure:"tls_probe"` // 对开放端口尝试 TLS 握手并提取证书摘要
+}
+
+// DefaultHostBaseline 未配置时主机安全检查使用的基线规则包名称
+const DefaultHostBaseline = "linux-host-baseline"
+
+// HostCheckConfig 主机安全检查配置
+type HostCheckConfig struct {
+	Baseline              string        `yaml:"baseline" mapstructure:"baseline"`                                 // ScanRule 类型安全物料中的基线规则包名称
+	KnownHosts            string        `yaml:"known_hosts" mapstructure:"known_hosts"`                           // 校验主机公钥的 known_hosts 文件，任务未固定主机公钥时使用
+	InsecureIgnoreHostKey bool          `yaml:"insecure_ignore_host_key" mapstructure:"insecure_ignore_host_key"` // 不校验主机公钥，仅用于测试环境
+	ConnectTimeout        time.Duration `yaml:"connect_timeout" mapstructure:"connect_timeout"`
+	LocalRoots            []string      `yaml:"local_roots" mapstructure:"local_roots"`   // 允许 local 传输使用的根目录，为空时禁用 local 传输
+	MaxEvidence           int           `yaml:"max_evidence" mapstructure:"max_evidence"` // 单个控制项保留的证据条数
+	VulnDBDir             string        `yaml:"vuln_db_dir" mapstructure:"vuln_db_dir"`   // 检查过期软件包的离线OSV漏洞库目录，为空时复用 sca.vuln_db_dir
+}
+
+// DefaultStorageAPIURL 未配置时访问的存储服务地址
+const DefaultStorageAPIURL = "http://127.0.0.1:8091"
+
+// ComplianceAuditConfig 合规审计配置
+type ComplianceAuditConfig struct {
+	StorageAPIURL  string        `yaml:"storage_api_url" mapstructure:"storage_api_url"` // 读取资产最近扫描结果的存储服务地址
+	RequestTimeout time.Duration `yaml:"request_timeout" mapstructure:"request_timeout"`
+	Frameworks     []string      `yaml:"frameworks" mapstructure:"frameworks"`     // 默认评估的框架（ComplianceDoc 物料名称），为空时评估全部
+	MaxEvidence    int           `yaml:"max_evidence" mapstructure:"max_evidence"` // 单个控制项保留的证据发现项数
+}
+
+// SecuritySpecConfig 安全规范检查配置
+type SecuritySpecConfig struct {
+	Specs         []string `yaml:"specs" mapstructure:"specs"`                       // 默认检查的安全规范（SecuritySpec 物料名称），为空时检查全部
+	MaxFileSizeKB int      `yaml:"max_file_size_kb" mapstructure:"max_file_size_kb"` // 超过该大小的配置文件跳过
+}
+
+// BlackBoxConfig 黑盒测试流量回放配置
+type BlackBoxConfig struct {
+	MaxRequests    int           `yaml:"max_requests" mapstructure:"max_requests"`       // 单次扫描请求预算（含基线请求）
+	RequestTimeout time.Duration `yaml:"request_timeout" mapstructure:"request_timeout"` // 单个请求超时
+	UserAgent      string        `yaml:"user_agent" mapstructure:"user_agent"`
+	InsecureTLS    bool          `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"` // 跳过证书校验，用于自签名的测试环境
+	Methods        []string      `yaml:"methods" mapstructure:"methods"`                           // 允许回放的请求方法，其它方法的请求跳过
+	Mutations      []string      `yaml:"mutations" mapstructure:"mutations"`                       // 启用的参数变异，为空时启用全部
+	MaxFileSizeMB  int           `yaml:"max_file_size_mb" mapstructure:"max_file_size_mb"`         // HAR/OpenAPI 文件大小上限
+}
+
+// SecretsConfig 敏感信息检测配置
+type SecretsConfig struct {
+	HistoryDepth     int                `yaml:"history_depth" mapstructure:"history_depth"`         // 扫描最近N个提交的新增内容，0 表示只扫描工作区
+	MaxFileSizeKB    int                `yaml:"max_file_size_kb" mapstructure:"max_file_size_kb"`   // 超过该大小的文件跳过
+	EntropyThreshold float64            `yaml:"entropy_threshold" mapstructure:"entropy_threshold"` // 通用高熵规则的香农熵阈值
+	Allowlist        SecretsAllowlist   `yaml:"allowlist" mapstructure:"allowlist"`
+	Rules            []SecretRuleConfig `yaml:"rules" mapstructure:"rules"` // 自定义规则，与内置规则同名时覆盖
+}
+
+// SecretsAllowlist 敏感信息白名单
+type SecretsAllowlist struct {
+	Paths     []string `yaml:"paths" mapstructure:"paths"`         // 文件路径正则
+	Regexes   []string `yaml:"regexes" mapstructure:"regexes"`     // 命中值正则
+	Stopwords []string `yaml:"stopwords" mapstructure:"stopwords"` // 命中值包含这些词时忽略（不区分大小写）
+	Commits   []string `yaml:"commits" mapstructure:"commits"`     // 忽略的提交
+}
+
+// SecretRuleConfig 自定义敏感信息规则
+type SecretRuleConfig struct {
+	ID          string   `yaml:"id" mapstructure:"id"`
+	Description string   `yaml:"description" mapstructure:"description"`
+	Regex       string   `yaml:"regex" mapstructure:"regex"`
+	SecretGroup int      `yaml:"secret_group" mapstructure:"secret_group"` // 密钥所在的捕获组，0 表示整个匹配
+	Entropy     float64  `yaml:"entropy" mapstructure:"entropy"`           // 最小熵，0 表示不校验
+	Keywords    []string `yaml:"keywords" mapstructure:"keywords"`         // 预过滤关键字
+	Severity    string   `yaml:"severity" mapstructure:"severity"`
+}
+
+// CrawlerConfig DAST 爬虫配置
+type CrawlerConfig struct {
+	MaxDepth       int           `yaml:"max_depth" mapstructure:"max_depth"`             // 最大爬取深度
+	MaxRequests    int           `yaml:"max_requests" mapstructure:"max_requests"`       // 单次扫描请求预算（含主动检测）
+	RequestTimeout time.Duration `yaml:"request_timeout" mapstructure:"request_timeout"` // 单个请求超时
+	UserAgent      string        `yaml:"user_agent" mapstructure:"user_agent"`
+	SubmitForms    bool          `yaml:"submit_forms" mapstructure:"submit_forms"`                 // 是否对 POST 表单执行主动检测
+	InsecureTLS    bool          `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"` // 跳过证书校验，用于自签名的测试环境
+}
+
+// ToolConfig 外部扫描工具配置
+// Args 中支持占位符：{target} 扫描目标目录，{output} 结果输出文件，{rules} 按 RuleFlag 展开的规则包参数
+type ToolConfig struct {
+	Path             string   `yaml:"path" mapstructure:"path"`                             // 工具可执行文件路径
+	Args             []string `yaml:"args" mapstructure:"args"`                             // 命令行参数模板
+	RuleFlag         string   `yaml:"rule_flag" mapstructure:"rule_flag"`                   // 规则包参数名，如 --config
+	RulePacks        []string `yaml:"rule_packs" mapstructure:"rule_packs"`                 // 规则包列表
+	OutputFormat     string   `yaml:"output_format" mapstructure:"output_format"`           // 输出格式：sarif, semgrep-json, gosec-json
+	SuccessExitCodes []int    `yaml:"success_exit_codes" mapstructure:"success_exit_codes"` // 视为成功的退出码
 }
 
 func validateConfig(cfg *Config) error {
@@ -323,6 +709,96 @@ func (c *Config) GetScannerConfig(scanType domain.ScanType) (scanner.ResourcePro
 				RunAsGroup:               int64(c.Scanner.SCA.SecurityProfile.RunAsGroup),
 				AllowPrivilegeEscalation: !c.Scanner.SCA.SecurityProfile.NoNewPrivs,
 			}, c.Scanner.SCA.Timeout
+	case domain.ScanTypeSecretsDetection:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.Secrets.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.Secrets.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.Secrets.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.Secrets.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.Secrets.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.Secrets.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.Secrets.Timeout
+	case domain.ScanTypePortScanning:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.PortScan.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.PortScan.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.PortScan.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.PortScan.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.PortScan.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.PortScan.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.PortScan.Timeout
+	case domain.ScanTypeContainerImageScan:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.Image.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.Image.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.Image.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.Image.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.Image.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.Image.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.Image.Timeout
+	case domain.ScanTypeRequirementAnalysis:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.RequirementAnalysis.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.RequirementAnalysis.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.RequirementAnalysis.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.RequirementAnalysis.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.RequirementAnalysis.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.RequirementAnalysis.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.RequirementAnalysis.Timeout
+	case domain.ScanTypeThreatModeling:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.ThreatModeling.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.ThreatModeling.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.ThreatModeling.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.ThreatModeling.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.ThreatModeling.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.ThreatModeling.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.ThreatModeling.Timeout
+	case domain.ScanTypeHostSecurityCheck:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.HostCheck.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.HostCheck.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.HostCheck.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.HostCheck.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.HostCheck.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.HostCheck.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.HostCheck.Timeout
+	case domain.ScanTypeComplianceAudit:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.ComplianceAudit.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.ComplianceAudit.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.ComplianceAudit.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.ComplianceAudit.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.ComplianceAudit.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.ComplianceAudit.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.ComplianceAudit.Timeout
+	case domain.ScanTypeSecuritySpecCheck:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.SecuritySpec.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.SecuritySpec.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.SecuritySpec.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.SecuritySpec.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.SecuritySpec.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.SecuritySpec.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.SecuritySpec.Timeout
+	case domain.ScanTypeBlackBoxTesting:
+		return scanner.ResourceProfile{
+				MinCPU:   c.Scanner.BlackBox.ResourceProfile.MinCPU,
+				MaxCPU:   c.Scanner.BlackBox.ResourceProfile.MaxCPU,
+				MemoryMB: c.Scanner.BlackBox.ResourceProfile.MemoryMB,
+			}, scanner.SecurityConfig{
+				RunAsUser:                int64(c.Scanner.BlackBox.SecurityProfile.RunAsUser),
+				RunAsGroup:               int64(c.Scanner.BlackBox.SecurityProfile.RunAsGroup),
+				AllowPrivilegeEscalation: !c.Scanner.BlackBox.SecurityProfile.NoNewPrivs,
+			}, c.Scanner.BlackBox.Timeout
 	default:
 		return scanner.ResourceProfile{
 				MinCPU:   2,
@@ -336,6 +812,229 @@ func (c *Config) GetScannerConfig(scanType domain.ScanType) (scanner.ResourcePro
 	}
 }
 
+// GetCgroupConfig 获取 cgroup v2 资源限制配置
+func (c *Config) GetCgroupConfig() CgroupConfig {
+	cg := c.Scanner.Cgroup
+	if cg.Root == "" {
+		cg.Root = "/sys/fs/cgroup"
+	}
+	if cg.Parent == "" {
+		cg.Parent = "go-sac"
+	}
+	if cg.PidsMax <= 0 {
+		cg.PidsMax = 512
+	}
+	return cg
+}
+
+// GetProcessSandboxConfig 获取扫描子进程隔离配置
+func (c *Config) GetProcessSandboxConfig() ProcessSandboxConfig {
+	sb := c.Scanner.ProcessSandbox
+	if len(sb.EnvAllowlist) == 0 {
+		sb.EnvAllowlist = append([]string(nil), defaultEnvAllowlist...)
+	}
+	return sb
+}
+
+// GetOutputCaptureConfig 获取扫描命令输出捕获配置
+func (c *Config) GetOutputCaptureConfig() OutputCaptureConfig {
+	oc := c.Scanner.OutputCapture
+	if oc.TailBytes <= 0 {
+		oc.TailBytes = 4 << 10
+	}
+	if oc.UploadTimeout <= 0 {
+		oc.UploadTimeout = 30 * time.Second
+	}
+	return oc
+}
+
+// GetScanLogPrefix 获取任务输出日志在 MinIO 中的路径前缀，格式为 <bucket>/<log_prefix>/<任务ID>/
+func (c *Config) GetScanLogPrefix(taskID string) string {
+	prefix := strings.Trim(c.Storage.MinIO.LogPrefix, "/")
+	if prefix == "" {
+		prefix = "scan-logs"
+	}
+	return fmt.Sprintf("%s/%s/%s/", c.Storage.MinIO.Bucket, prefix, taskID)
+}
+
+// GetPluginConfig 获取外部扫描插件配置
+func (c *Config) GetPluginConfig() PluginConfig {
+	pc := c.Scanner.Plugins
+	if pc.Dir == "" {
+		pc.Dir = "./plugins"
+	}
+	if pc.HandshakeTimeout <= 0 {
+		pc.HandshakeTimeout = 10 * time.Second
+	}
+	if pc.DiscoveryInterval <= 0 {
+		pc.DiscoveryInterval = time.Minute
+	}
+	if pc.Timeout <= 0 {
+		pc.Timeout = 30 * time.Minute
+	}
+	return pc
+}
+
+// GetSASTToolConfig 获取SAST外部工具配置及沙箱工作目录
+func (c *Config) GetSASTToolConfig() (ToolConfig, string) {
+	tool := c.Scanner.SAST.Tool
+	if tool.OutputFormat == "" {
+		tool.OutputFormat = "sarif"
+	}
+	if len(tool.SuccessExitCodes) == 0 {
+		tool.SuccessExitCodes = []int{0}
+	}
+	return tool, c.Scanner.SAST.WorkDir
+}
+
+// GetDASTConfig 获取DAST爬虫配置及启用的主动检测项
+func (c *Config) GetDASTConfig() (CrawlerConfig, []string) {
+	crawler := c.Scanner.DAST.Crawler
+	if crawler.MaxDepth <= 0 {
+		crawler.MaxDepth = 3
+	}
+	if crawler.MaxRequests <= 0 {
+		crawler.MaxRequests = 500
+	}
+	if crawler.RequestTimeout <= 0 {
+		crawler.RequestTimeout = 10 * time.Second
+	}
+	if crawler.UserAgent == "" {
+		crawler.UserAgent = "go-sac-dast/1.0"
+	}
+	return crawler, c.Scanner.DAST.ActiveChecks
+}
+
+// GetSCAConfig 获取SCA离线漏洞库目录及沙箱工作目录
+func (c *Config) GetSCAConfig() (string, string) {
+	return c.Scanner.SCA.VulnDBDir, c.Scanner.SCA.WorkDir
+}
+
+// GetImageScanConfig 获取镜像扫描离线漏洞库目录及沙箱工作目录
+func (c *Config) GetImageScanConfig() (string, string) {
+	vulnDBDir := c.Scanner.Image.VulnDBDir
+	if vulnDBDir == "" {
+		vulnDBDir = c.Scanner.SCA.VulnDBDir
+	}
+	return vulnDBDir, c.Scanner.Image.WorkDir
+}
+
+// GetRequirementAnalysisConfig 获取风险需求识别使用的安全物料配置
+func (c *Config) GetRequirementAnalysisConfig() SecurityMaterialConfig {
+	return c.Scanner.RequirementAnalysis.Materials.withDefaults()
+}
+
+// GetThreatModelingConfig 获取威胁建模使用的安全物料配置
+func (c *Config) GetThreatModelingConfig() SecurityMaterialConfig {
+	return c.Scanner.ThreatModeling.Materials.withDefaults()
+}
+
+// GetHostCheckConfig 获取主机安全检查配置及基线所在的安全物料配置
+func (c *Config) GetHostCheckConfig() (HostCheckConfig, SecurityMaterialConfig) {
+	hc := c.Scanner.HostCheck.Check
+	if hc.Baseline == "" {
+		hc.Baseline = DefaultHostBaseline
+	}
+	if hc.ConnectTimeout <= 0 {
+		hc.ConnectTimeout = 10 * time.Second
+	}
+	if hc.MaxEvidence <= 0 {
+		hc.MaxEvidence = 20
+	}
+	if hc.VulnDBDir == "" {
+		hc.VulnDBDir = c.Scanner.SCA.VulnDBDir
+	}
+	return hc, c.Scanner.HostCheck.Materials.withDefaults()
+}
+
+// GetComplianceAuditConfig 获取合规审计配置及框架映射所在的安全物料配置
+func (c *Config) GetComplianceAuditConfig() (ComplianceAuditConfig, SecurityMaterialConfig) {
+	ac := c.Scanner.ComplianceAudit.Audit
+	if ac.StorageAPIURL == "" {
+		ac.StorageAPIURL = DefaultStorageAPIURL
+	}
+	if ac.RequestTimeout <= 0 {
+		ac.RequestTimeout = 30 * time.Second
+	}
+	if ac.MaxEvidence <= 0 {
+		ac.MaxEvidence = 50
+	}
+	return ac, c.Scanner.ComplianceAudit.Materials.withDefaults()
+}
+
+// GetSecuritySpecConfig 获取安全规范检查配置、规范所在的安全物料配置及工作目录
+func (c *Config) GetSecuritySpecConfig() (SecuritySpecConfig, SecurityMaterialConfig, string) {
+	sc := c.Scanner.SecuritySpec.Check
+	if sc.MaxFileSizeKB <= 0 {
+		sc.MaxFileSizeKB = 1024
+	}
+	return sc, c.Scanner.SecuritySpec.Materials.withDefaults(), c.Scanner.SecuritySpec.WorkDir
+}
+
+// GetBlackBoxConfig 获取黑盒测试流量回放配置及工作目录
+func (c *Config) GetBlackBoxConfig() (BlackBoxConfig, string) {
+	bc := c.Scanner.BlackBox.Replay
+	if bc.MaxRequests <= 0 {
+		bc.MaxRequests = 1000
+	}
+	if bc.RequestTimeout <= 0 {
+		bc.RequestTimeout = 10 * time.Second
+	}
+	if bc.UserAgent == "" {
+		bc.UserAgent = "go-sac-blackbox/1.0"
+	}
+	if len(bc.Methods) == 0 {
+		bc.Methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH"}
+	}
+	if bc.MaxFileSizeMB <= 0 {
+		bc.MaxFileSizeMB = 32
+	}
+	return bc, c.Scanner.BlackBox.WorkDir
+}
+
+func (m SecurityMaterialConfig) withDefaults() SecurityMaterialConfig {
+	if m.MaterialDir == "" {
+		m.MaterialDir = DefaultSecurityMaterialDir
+	}
+	return m
+}
+
+// GetSecretsConfig 获取敏感信息检测配置及沙箱工作目录
+func (c *Config) GetSecretsConfig() (SecretsConfig, string) {
+	detector := c.Scanner.Secrets.Detector
+	if detector.MaxFileSizeKB <= 0 {
+		detector.MaxFileSizeKB = 1024
+	}
+	if detector.EntropyThreshold <= 0 {
+		detector.EntropyThreshold = 3.5
+	}
+	return detector, c.Scanner.Secrets.WorkDir
+}
+
+// GetPortScanConfig 获取端口扫描配置
+func (c *Config) GetPortScanConfig() PortScanConfig {
+	probe := c.Scanner.PortScan.Probe
+	if probe.Ports == "" {
+		probe.Ports = DefaultPortScanPorts
+	}
+	if probe.HostConcurrency <= 0 {
+		probe.HostConcurrency = 100
+	}
+	if probe.MaxParallelHosts <= 0 {
+		probe.MaxParallelHosts = 4
+	}
+	if probe.MaxHosts <= 0 {
+		probe.MaxHosts = 256
+	}
+	if probe.ConnectTimeout <= 0 {
+		probe.ConnectTimeout = 2 * time.Second
+	}
+	if probe.BannerTimeout <= 0 {
+		probe.BannerTimeout = 2 * time.Second
+	}
+	return probe
+}
+
 // GetCircuitBreakerConfig 获取熔断器配置
 func (c *Config) GetCircuitBreakerConfig() (uint32, uint32, time.Duration) {
 	return c.Scanner.CircuitBreaker.Threshold,
@@ -343,6 +1042,64 @@ func (c *Config) GetCircuitBreakerConfig() (uint32, uint32, time.Duration) {
 		c.Scanner.CircuitBreaker.ResetTimeout
 }
 
+// GetCircuitBreakerSettings 获取扫描类型的熔断器配置，host 非空时返回按目标主机的熔断器配置；
+// 可直接作为 scanner.CircuitBreakerSettingsFunc 使用
+func (c *Config) GetCircuitBreakerSettings(scanType domain.ScanType, host string) scanner.CircuitBreakerSettings {
+	cb := c.Scanner.CircuitBreaker
+	settings := scanner.CircuitBreakerSettings{
+		Threshold:           cb.Threshold,
+		CriticalThreshold:   cb.CriticalThreshold,
+		ResetTimeout:        cb.ResetTimeout,
+		HalfOpenMaxRequests: cb.HalfOpenMaxRequests,
+	}
+	for name, override := range cb.Overrides {
+		if t, err := domain.ParseScanType(name); err == nil && t == scanType {
+			settings = override.mergeInto(settings)
+		}
+	}
+	if host != "" {
+		settings = cb.DASTHost.mergeInto(settings)
+	}
+	return settings
+}
+
+// GetTimeoutPolicy 获取自适应超时策略，未知的扫描类型名称被忽略
+func (c *Config) GetTimeoutPolicy() scanner.TimeoutPolicy {
+	tp := c.Scanner.TimeoutPolicy
+	multipliers := make(map[domain.ScanType]float64, len(tp.TypeMultipliers))
+	for name, m := range tp.TypeMultipliers {
+		if t, err := domain.ParseScanType(name); err == nil {
+			multipliers[t] = m
+		}
+	}
+	return scanner.TimeoutPolicy{
+		BaseTimeout:     tp.BaseTimeout,
+		PriorityFactor:  tp.PriorityFactor,
+		TypeMultiplier:  multipliers,
+		PerMB:           tp.PerMB,
+		PerItem:         tp.PerItem,
+		SoftRatio:       tp.SoftRatio,
+		CriticalGrace:   tp.CriticalGrace,
+		HistoryHeadroom: tp.HistoryHeadroom,
+		HistoryWindow:   tp.HistoryWindow,
+		MinSamples:      tp.MinSamples,
+		MinTimeout:      tp.MinTimeout,
+		MaxTimeout:      tp.MaxTimeout,
+	}
+}
+
+// GetWatchdogSettings 获取扫描子进程看门狗配置
+func (c *Config) GetWatchdogSettings() scanner.WatchdogSettings {
+	wd := c.Scanner.Watchdog
+	return scanner.WatchdogSettings{
+		Enabled:        wd.Enabled,
+		Interval:       wd.Interval,
+		SilenceTimeout: wd.SilenceTimeout,
+		StallTimeout:   wd.StallTimeout,
+		ReapZombies:    wd.ReapZombies,
+	}
+}
+
 // GetConcurrencyConfig 获取全局并行配置文件
 func (c *Config) GetConcurrencyConfig() (int, int) {
 	return c.Scanner.Concurrency.MaxWorkers, c.Scanner.Concurrency.QueueSize
@@ -361,6 +1118,18 @@ func (c *Config) GetPrioritySchedulerConfig() (map[string]int, map[string]float6
 		"medium": c.Scanner.PriorityScheduler.PriorityWeights.Medium,
 		"low":    c.Scanner.PriorityScheduler.PriorityWeights.Low,
 	}
+	// 未配置权重时使用默认比例
+	if priorityWeights["high"] <= 0 && priorityWeights["medium"] <= 0 && priorityWeights["low"] <= 0 {
+		priorityWeights = map[string]float64{"high": 0.6, "medium": 0.3, "low": 0.1}
+	}
 
 	return channelCapacity, priorityWeights
 }
+
+// GetPriorityAgingThreshold 获取任务等待多久后提升优先级，默认 2 分钟
+func (c *Config) GetPriorityAgingThreshold() time.Duration {
+	if c.Scanner.PriorityScheduler.AgingThreshold <= 0 {
+		return 2 * time.Minute
+	}
+	return c.Scanner.PriorityScheduler.AgingThreshold
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// 选项中的凭据以扫描目标为绑定加密（crypt.KeyManager.SealStringBound），
+// 执行器只能在同一目标上解密，改写目标后原有凭据失效
+
+// URLOrigin 返回 scheme://host[:port]，主机名小写并省略默认端口
+func URLOrigin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// DASTCredentialScope DAST 与黑盒测试凭据绑定的扫描入口 origin，未指定入口或地址无效时为空
+func DASTCredentialScope(targetURL, domainName string) string {
+	raw := strings.TrimSpace(targetURL)
+	if raw == "" {
+		name := strings.TrimSuffix(strings.TrimSpace(domainName), ".")
+		if name == "" {
+			return ""
+		}
+		raw = "https://" + name + "/"
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return URLOrigin(u)
+}
+
+// HostCredentialScope 主机安全检查 SSH 凭据绑定的目标主机，未指定主机时为空
+func HostCredentialScope(ipAddress string) string {
+	ip := strings.ToLower(strings.TrimSpace(ipAddress))
+	if ip == "" {
+		return ""
+	}
+	return "ssh://" + ip
+}
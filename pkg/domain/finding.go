@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// 统一的严重等级
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// 增量扫描时发现项相对上一次结果的状态
+const (
+	FindingStatusNew       = "new"       // 本次新出现
+	FindingStatusUnchanged = "unchanged" // 上一次结果中已存在
+	FindingStatusFixed     = "fixed"     // 上一次结果中存在、本次已消失
+)
+
+// Finding 扫描器统一输出的安全发现项，与 SARIF result 一一对应
+type Finding struct {
+	RuleID      string                 `json:"rule_id"`
+	RuleName    string                 `json:"rule_name,omitempty"`
+	Severity    string                 `json:"severity"`
+	Message     string                 `json:"message"`
+	Category    string                 `json:"category,omitempty"`
+	CWEID       string                 `json:"cwe_id,omitempty"`
+	Location    FindingLocation        `json:"location"`
+	Fingerprint string                 `json:"fingerprint,omitempty"`
+	Remediation string                 `json:"remediation,omitempty"`
+	Status      string                 `json:"status,omitempty"` // 相对上一次结果的状态，见 FindingStatus*
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+// FindingLocation 发现项位置：物理位置为源码相对路径或 URL，逻辑位置为包、主机端口、字段路径等
+type FindingLocation struct {
+	Path        string `json:"path,omitempty"`
+	StartLine   int    `json:"start_line,omitempty"`
+	EndLine     int    `json:"end_line,omitempty"`
+	Logical     string `json:"logical,omitempty"`
+	LogicalKind string `json:"logical_kind,omitempty"` // SARIF logicalLocation.kind，如 package、module
+}
+
+// FindingRule 规则元数据，对应 SARIF reportingDescriptor
+type FindingRule struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Help        string   `json:"help,omitempty"`
+	HelpURI     string   `json:"help_uri,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// FindingReport 单个工具一次运行的发现项，对应 SARIF run
+type FindingReport struct {
+	Tool           string                 `json:"tool"`
+	ToolVersion    string                 `json:"tool_version,omitempty"`
+	InformationURI string                 `json:"information_uri,omitempty"`
+	Rules          []FindingRule          `json:"rules,omitempty"` // 未列出的规则按发现项自动补全
+	Findings       []Finding              `json:"findings"`
+	Properties     map[string]interface{} `json:"properties,omitempty"` // 运行级别的附加信息，如统计摘要
+}
+
+// StoredFinding 存储服务中已入库的发现项，ID 为入库记录编号
+type StoredFinding struct {
+	ID     uint   `json:"id"`
+	TaskID string `json:"task_id"`
+	Finding
+}
+
+// ScanFindings 资产某一扫描类型最近一次入库结果的发现项，ScannedAt 为入库时间
+type ScanFindings struct {
+	ScanType  string          `json:"scan_type"`
+	TaskID    string          `json:"task_id"`
+	ScannedAt time.Time       `json:"scanned_at"`
+	Findings  []StoredFinding `json:"findings"`
+}
+
+// ComputeFingerprint 按规则、位置与消息计算稳定指纹，不含行号以便代码移动后仍能关联
+func (f *Finding) ComputeFingerprint() string {
+	h := sha256.New()
+	for _, part := range []string{f.RuleID, f.Location.Path, f.Location.Logical, f.Message} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// NormalizeSeverity 将各工具的严重等级统一为 critical/high/medium/low/info，无法识别时返回空
+func NormalizeSeverity(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case SeverityCritical:
+		return SeverityCritical
+	case SeverityHigh, "error":
+		return SeverityHigh
+	case SeverityMedium, "moderate", "warning":
+		return SeverityMedium
+	case SeverityLow, "note":
+		return SeverityLow
+	case SeverityInfo, "informational", "none":
+		return SeverityInfo
+	default:
+		return ""
+	}
+}
+
+// SeverityFromScore 将 CVSS 评分映射为严重等级
+func SeverityFromScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
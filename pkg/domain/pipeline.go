@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// StageStatusWaiting 阶段尚未创建任务（依赖未结束）
+const StageStatusWaiting = "waiting"
+
+// StageStatusSkipped 阶段因依赖失败或条件不满足而跳过
+const StageStatusSkipped = "skipped"
+
+// StageStatusAny 条件中表示依赖以任意状态结束均可
+const StageStatusAny = "any"
+
+// ErrInvalidPipeline 流水线定义不合法
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+var stageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// PipelineStage 流水线中的一个阶段，对应一个扫描子任务
+type PipelineStage struct {
+	Name           string                 `json:"name"`
+	ScanType       string                 `json:"scan_type"`
+	DependsOn      []string               `json:"depends_on,omitempty"`
+	Conditions     []StageCondition       `json:"conditions,omitempty"` // 全部满足才执行；为空时要求所有依赖执行完成
+	Options        map[string]interface{} `json:"options,omitempty"`
+	Profile        string                 `json:"profile,omitempty"`
+	ProfileVersion int                    `json:"profile_version,omitempty"`
+	Priority       int                    `json:"priority,omitempty"`
+}
+
+// StageCondition 阶段执行条件，针对某个直接依赖的结束状态与发现项数量
+// 例如 {"stage": "sast", "max_findings": {"critical": 0}} 表示 SAST 完成且没有严重漏洞
+type StageCondition struct {
+	Stage       string         `json:"stage"`
+	Status      string         `json:"status,omitempty"`       // 依赖的结束状态，默认 completed，any 表示任意状态
+	MaxFindings map[string]int `json:"max_findings,omitempty"` // 按严重等级的发现项数量上限
+}
+
+// PipelineDefinition 流水线定义：扫描阶段组成的有向无环图
+type PipelineDefinition struct {
+	Stages []PipelineStage `json:"stages"`
+}
+
+// PipelineStageState 阶段运行状态，Status 为任务状态或 waiting/skipped
+type PipelineStageState struct {
+	TaskID   string         `json:"task_id,omitempty"`
+	Status   string         `json:"status"`
+	Reason   string         `json:"reason,omitempty"`
+	Findings map[string]int `json:"findings,omitempty"` // 子任务按严重等级的发现项数量
+}
+
+// PipelineSummary 流水线结果汇总
+type PipelineSummary struct {
+	Stages   map[string]int `json:"stages"`   // 按状态统计阶段数
+	Findings map[string]int `json:"findings"` // 所有子任务按严重等级的发现项数量
+	Failed   []string       `json:"failed,omitempty"`
+	Skipped  []string       `json:"skipped,omitempty"`
+}
+
+// IsStageTerminal 判断阶段是否已结束
+func IsStageTerminal(status string) bool {
+	switch status {
+	case string(TaskStatusCompleted), string(TaskStatusFailed), string(TaskStatusCancelled), StageStatusSkipped:
+		return true
+	}
+	return false
+}
+
+// Validate 校验阶段名称、扫描类型、依赖与条件，并确认依赖关系无环
+func (d *PipelineDefinition) Validate() error {
+	if len(d.Stages) == 0 {
+		return invalidPipeline("pipeline has no stages")
+	}
+	stages := make(map[string]*PipelineStage, len(d.Stages))
+	for i := range d.Stages {
+		st := &d.Stages[i]
+		if !stageNamePattern.MatchString(st.Name) {
+			return invalidPipeline("stage %q: name must be 1-64 lowercase letters, digits, '_' or '-'", st.Name)
+		}
+		if _, dup := stages[st.Name]; dup {
+			return invalidPipeline("duplicate stage %q", st.Name)
+		}
+		if _, err := ParseScanType(st.ScanType); err != nil {
+			return invalidPipeline("stage %q: %v", st.Name, err)
+		}
+		stages[st.Name] = st
+	}
+
+	for _, st := range d.Stages {
+		deps := make(map[string]bool, len(st.DependsOn))
+		for _, dep := range st.DependsOn {
+			if _, ok := stages[dep]; !ok {
+				return invalidPipeline("stage %q depends on unknown stage %q", st.Name, dep)
+			}
+			if dep == st.Name {
+				return invalidPipeline("stage %q depends on itself", st.Name)
+			}
+			deps[dep] = true
+		}
+		for _, c := range st.Conditions {
+			if !deps[c.Stage] {
+				return invalidPipeline("stage %q: condition refers to %q which is not a dependency", st.Name, c.Stage)
+			}
+			switch c.Status {
+			case "", StageStatusAny, string(TaskStatusCompleted), string(TaskStatusFailed), string(TaskStatusCancelled), StageStatusSkipped:
+			default:
+				return invalidPipeline("stage %q: unsupported condition status %q", st.Name, c.Status)
+			}
+			for severity, max := range c.MaxFindings {
+				if NormalizeSeverity(severity) != severity || max < 0 {
+					return invalidPipeline("stage %q: invalid max_findings %s=%d", st.Name, severity, max)
+				}
+			}
+		}
+	}
+
+	// Kahn 算法检测环
+	indegree := make(map[string]int, len(d.Stages))
+	dependents := make(map[string][]string)
+	for _, st := range d.Stages {
+		for _, dep := range st.DependsOn {
+			indegree[st.Name]++
+			dependents[dep] = append(dependents[dep], st.Name)
+		}
+	}
+	queue := make([]string, 0, len(d.Stages))
+	for _, st := range d.Stages {
+		if indegree[st.Name] == 0 {
+			queue = append(queue, st.Name)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range dependents[name] {
+			if indegree[next]--; indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	if visited != len(d.Stages) {
+		return invalidPipeline("stages contain a dependency cycle")
+	}
+	return nil
+}
+
+func invalidPipeline(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPipeline, fmt.Sprintf(format, args...))
+}
+
+// InitialStates 返回所有阶段均为 waiting 的状态表
+func (d *PipelineDefinition) InitialStates() map[string]*PipelineStageState {
+	states := make(map[string]*PipelineStageState, len(d.Stages))
+	for _, st := range d.Stages {
+		states[st.Name] = &PipelineStageState{Status: StageStatusWaiting}
+	}
+	return states
+}
+
+// Plan 推进流水线：依赖全部结束的 waiting 阶段按条件决定执行或跳过，跳过会继续向下游传递
+// 返回需要创建子任务的阶段（按定义顺序），调用方创建任务后更新其状态；跳过的阶段直接写入 states
+func (d *PipelineDefinition) Plan(states map[string]*PipelineStageState) []PipelineStage {
+	var start []PipelineStage
+	planned := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, st := range d.Stages {
+			state := states[st.Name]
+			if state == nil || state.Status != StageStatusWaiting || planned[st.Name] {
+				continue
+			}
+			ready := true
+			for _, dep := range st.DependsOn {
+				if states[dep] == nil || !IsStageTerminal(states[dep].Status) {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			if reason := st.blockedBy(states); reason != "" {
+				state.Status = StageStatusSkipped
+				state.Reason = reason
+				changed = true
+				continue
+			}
+			planned[st.Name] = true
+			start = append(start, st)
+		}
+	}
+	return start
+}
+
+// blockedBy 返回阶段不能执行的原因，可以执行时返回空
+func (st *PipelineStage) blockedBy(states map[string]*PipelineStageState) string {
+	if len(st.Conditions) == 0 {
+		for _, dep := range st.DependsOn {
+			if s := states[dep].Status; s != string(TaskStatusCompleted) {
+				return fmt.Sprintf("dependency %s %s", dep, s)
+			}
+		}
+		return ""
+	}
+
+	for _, c := range st.Conditions {
+		dep := states[c.Stage]
+		want := c.Status
+		if want == "" {
+			want = string(TaskStatusCompleted)
+		}
+		if want != StageStatusAny && dep.Status != want {
+			return fmt.Sprintf("dependency %s %s, condition requires %s", c.Stage, dep.Status, want)
+		}
+		severities := make([]string, 0, len(c.MaxFindings))
+		for severity := range c.MaxFindings {
+			severities = append(severities, severity)
+		}
+		sort.Strings(severities)
+		for _, severity := range severities {
+			if n := dep.Findings[severity]; n > c.MaxFindings[severity] {
+				return fmt.Sprintf("dependency %s has %d %s findings, condition allows %d", c.Stage, n, severity, c.MaxFindings[severity])
+			}
+		}
+	}
+	return ""
+}
+
+// Status 计算流水线整体状态：有阶段未结束时为 running（尚未创建任何子任务时为 pending），
+// 全部结束后任一阶段失败或取消为 failed，否则为 completed（条件不满足而跳过不视为失败）
+func (d *PipelineDefinition) Status(states map[string]*PipelineStageState) TaskStatus {
+	started, finished, failed := false, true, false
+	for _, st := range d.Stages {
+		state := states[st.Name]
+		if state == nil {
+			finished = false
+			continue
+		}
+		if state.TaskID != "" {
+			started = true
+		}
+		if !IsStageTerminal(state.Status) {
+			finished = false
+		}
+		if state.Status == string(TaskStatusFailed) || state.Status == string(TaskStatusCancelled) {
+			failed = true
+		}
+	}
+	switch {
+	case !finished && started:
+		return TaskStatusRunning
+	case !finished:
+		return TaskStatusPending
+	case failed:
+		return TaskStatusFailed
+	default:
+		return TaskStatusCompleted
+	}
+}
+
+// Summarize 汇总各阶段状态与发现项数量
+func (d *PipelineDefinition) Summarize(states map[string]*PipelineStageState) *PipelineSummary {
+	summary := &PipelineSummary{Stages: make(map[string]int), Findings: make(map[string]int)}
+	for _, st := range d.Stages {
+		state := states[st.Name]
+		if state == nil {
+			continue
+		}
+		summary.Stages[state.Status]++
+		for severity, n := range state.Findings {
+			summary.Findings[severity] += n
+		}
+		switch state.Status {
+		case string(TaskStatusFailed), string(TaskStatusCancelled):
+			summary.Failed = append(summary.Failed, st.Name)
+		case StageStatusSkipped:
+			summary.Skipped = append(summary.Skipped, st.Name)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,96 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repoPipeline SCA 与 SAST 并行，敏感信息扫描在 SAST 之后，SAST 没有严重漏洞时才执行 DAST
+func repoPipeline() *domain.PipelineDefinition {
+	return &domain.PipelineDefinition{Stages: []domain.PipelineStage{
+		{Name: "sca", ScanType: "SCA"},
+		{Name: "sast", ScanType: "SAST"},
+		{Name: "secrets", ScanType: "SecretsDetection", DependsOn: []string{"sast"}},
+		{Name: "dast", ScanType: "DAST", DependsOn: []string{"sast", "sca"}, Conditions: []domain.StageCondition{
+			{Stage: "sast", MaxFindings: map[string]int{domain.SeverityCritical: 0}},
+			{Stage: "sca", Status: domain.StageStatusAny},
+		}},
+	}}
+}
+
+func stageNames(stages []domain.PipelineStage) []string {
+	names := make([]string, len(stages))
+	for i, st := range stages {
+		names[i] = st.Name
+	}
+	return names
+}
+
+func TestPipelineDefinitionValidate(t *testing.T) {
+	require.NoError(t, repoPipeline().Validate())
+
+	for name, def := range map[string]*domain.PipelineDefinition{
+		"empty":         {},
+		"bad name":      {Stages: []domain.PipelineStage{{Name: "SAST", ScanType: "SAST"}}},
+		"duplicate":     {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST"}, {Name: "a", ScanType: "SCA"}}},
+		"scan type":     {Stages: []domain.PipelineStage{{Name: "a", ScanType: "Nope"}}},
+		"unknown dep":   {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST", DependsOn: []string{"b"}}}},
+		"self dep":      {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST", DependsOn: []string{"a"}}}},
+		"cycle":         {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST", DependsOn: []string{"b"}}, {Name: "b", ScanType: "SCA", DependsOn: []string{"a"}}}},
+		"condition dep": {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST"}, {Name: "b", ScanType: "SCA", Conditions: []domain.StageCondition{{Stage: "a"}}}}},
+		"severity": {Stages: []domain.PipelineStage{{Name: "a", ScanType: "SAST"}, {Name: "b", ScanType: "SCA", DependsOn: []string{"a"},
+			Conditions: []domain.StageCondition{{Stage: "a", MaxFindings: map[string]int{"blocker": 0}}}}}},
+	} {
+		assert.Error(t, def.Validate(), name)
+	}
+}
+
+func TestPipelinePlan(t *testing.T) {
+	def := repoPipeline()
+	states := def.InitialStates()
+
+	assert.Equal(t, []string{"sca", "sast"}, stageNames(def.Plan(states)))
+	assert.Equal(t, domain.TaskStatusPending, def.Status(states))
+	states["sca"].TaskID, states["sca"].Status = "t1", string(domain.TaskStatusRunning)
+	states["sast"].TaskID, states["sast"].Status = "t2", string(domain.TaskStatusRunning)
+	assert.Empty(t, def.Plan(states), "stages already started are not planned again")
+	assert.Equal(t, domain.TaskStatusRunning, def.Status(states))
+
+	states["sast"].Status = string(domain.TaskStatusCompleted)
+	states["sast"].Findings = map[string]int{domain.SeverityCritical: 2, domain.SeverityLow: 1}
+	assert.Equal(t, []string{"secrets"}, stageNames(def.Plan(states)), "dast still waits for sca")
+	states["secrets"].TaskID, states["secrets"].Status = "t3", string(domain.TaskStatusCompleted)
+
+	states["sca"].Status = string(domain.TaskStatusFailed)
+	assert.Empty(t, def.Plan(states))
+	assert.Equal(t, domain.StageStatusSkipped, states["dast"].Status)
+	assert.Contains(t, states["dast"].Reason, "2 critical findings")
+
+	assert.Equal(t, domain.TaskStatusFailed, def.Status(states))
+	summary := def.Summarize(states)
+	assert.Equal(t, []string{"sca"}, summary.Failed)
+	assert.Equal(t, []string{"dast"}, summary.Skipped)
+	assert.Equal(t, 2, summary.Findings[domain.SeverityCritical])
+	assert.Equal(t, 2, summary.Stages[string(domain.TaskStatusCompleted)])
+}
+
+func TestPipelinePlanSkipCascades(t *testing.T) {
+	def := &domain.PipelineDefinition{Stages: []domain.PipelineStage{
+		{Name: "sast", ScanType: "SAST"},
+		{Name: "secrets", ScanType: "SecretsDetection", DependsOn: []string{"sast"}},
+		{Name: "dast", ScanType: "DAST", DependsOn: []string{"secrets"}},
+		{Name: "report", ScanType: "SCA", DependsOn: []string{"dast"}, Conditions: []domain.StageCondition{
+			{Stage: "dast", Status: domain.StageStatusAny},
+		}},
+	}}
+	states := def.InitialStates()
+	def.Plan(states)
+	states["sast"].TaskID, states["sast"].Status = "t1", string(domain.TaskStatusCancelled)
+
+	assert.Equal(t, []string{"report"}, stageNames(def.Plan(states)), "status any runs after a skipped dependency")
+	assert.Equal(t, domain.StageStatusSkipped, states["secrets"].Status)
+	assert.Equal(t, domain.StageStatusSkipped, states["dast"].Status)
+}
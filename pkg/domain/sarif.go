@@ -0,0 +1,579 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SARIF 2.1.0 版本与 schema
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// SARIFFingerprintKey 本系统写入 result.fingerprints 的键
+	SARIFFingerprintKey = "go-sac/v1"
+)
+
+// SARIF 2.1.0 对象模型，仅包含扫描结果交换所需的子集
+
+type SarifLog struct {
+	Schema  string     `json:"$schema,omitempty"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool       SarifTool              `json:"tool"`
+	Results    []SarifResult          `json:"results"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type SarifTool struct {
+	Driver SarifToolComponent `json:"driver"`
+}
+
+type SarifToolComponent struct {
+	Name           string                     `json:"name"`
+	Version        string                     `json:"version,omitempty"`
+	InformationURI string                     `json:"informationUri,omitempty"`
+	Rules          []SarifReportingDescriptor `json:"rules,omitempty"`
+}
+
+type SarifReportingDescriptor struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *SarifMessage           `json:"shortDescription,omitempty"`
+	FullDescription      *SarifMessage           `json:"fullDescription,omitempty"`
+	Help                 *SarifMessage           `json:"help,omitempty"`
+	HelpURI              string                  `json:"helpUri,omitempty"`
+	DefaultConfiguration *SarifRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+}
+
+type SarifRuleConfiguration struct {
+	Level string `json:"level,omitempty"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID              string                 `json:"ruleId,omitempty"`
+	RuleIndex           *int                   `json:"ruleIndex,omitempty"`
+	Level               string                 `json:"level,omitempty"`
+	Message             SarifMessage           `json:"message"`
+	Locations           []SarifLocation        `json:"locations,omitempty"`
+	Fingerprints        map[string]string      `json:"fingerprints,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Fixes               []SarifFix             `json:"fixes,omitempty"`
+	BaselineState       string                 `json:"baselineState,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation *SarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifRegion struct {
+	StartLine int `json:"startLine,omitempty"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type SarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// SarifFix 只读取修复描述，本系统不生成 artifactChanges，因此编码时不输出 fixes
+type SarifFix struct {
+	Description *SarifMessage `json:"description,omitempty"`
+}
+
+// result.properties 中由 Finding 字段占用的键
+const (
+	sarifPropSeverity         = "severity"
+	sarifPropSecuritySeverity = "security-severity"
+	sarifPropCategory         = "category"
+	sarifPropCWE              = "cwe"
+	sarifPropRemediation      = "remediation"
+	sarifPropRuleName         = "rule_name"
+	sarifPropTags             = "tags"
+)
+
+var sarifCWEPattern = regexp.MustCompile(`(?i)cwe[-/:\s]*(\d+)`)
+
+// NewSarifLog 将发现项报告编码为 SARIF 日志，每个报告对应一个 run
+func NewSarifLog(reports ...FindingReport) *SarifLog {
+	log := &SarifLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: make([]SarifRun, 0, len(reports))}
+	for _, report := range reports {
+		log.Runs = append(log.Runs, report.toSarifRun())
+	}
+	return log
+}
+
+// EncodeSARIF 编码为 SARIF JSON
+func EncodeSARIF(reports ...FindingReport) ([]byte, error) {
+	return json.Marshal(NewSarifLog(reports...))
+}
+
+// DecodeSARIF 解析 SARIF JSON，仅接受 2.1.0 版本
+func DecodeSARIF(data []byte) (*SarifLog, error) {
+	var log SarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("invalid sarif: %w", err)
+	}
+	if log.Version != SARIFVersion {
+		return nil, fmt.Errorf("unsupported sarif version %q, expected %s", log.Version, SARIFVersion)
+	}
+	return &log, nil
+}
+
+func (r FindingReport) toSarifRun() SarifRun {
+	// 先登记显式声明的规则，再按发现项补全
+	rules := make([]SarifReportingDescriptor, 0, len(r.Rules))
+	index := make(map[string]int)
+	addRule := func(rule FindingRule) {
+		if i, ok := index[rule.ID]; ok {
+			// 同一规则多个发现项时，规则默认等级取最高
+			if severityRank(rule.Severity) > severityRank(ruleSeverity(rules[i])) {
+				setRuleSeverity(&rules[i], rule.Severity)
+			}
+			return
+		}
+		index[rule.ID] = len(rules)
+		rules = append(rules, rule.toSarif())
+	}
+	for _, rule := range r.Rules {
+		addRule(rule)
+	}
+
+	results := make([]SarifResult, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		var tags []string
+		if f.Category != "" {
+			tags = append(tags, f.Category)
+		}
+		if f.CWEID != "" {
+			tags = append(tags, f.CWEID)
+		}
+		addRule(FindingRule{ID: f.RuleID, Name: f.RuleName, Severity: f.Severity, Tags: tags})
+		results = append(results, f.toSarif(index[f.RuleID]))
+	}
+
+	return SarifRun{
+		Tool: SarifTool{Driver: SarifToolComponent{
+			Name:           r.Tool,
+			Version:        r.ToolVersion,
+			InformationURI: r.InformationURI,
+			Rules:          rules,
+		}},
+		Results:    results,
+		Properties: r.Properties,
+	}
+}
+
+func (rule FindingRule) toSarif() SarifReportingDescriptor {
+	d := SarifReportingDescriptor{ID: rule.ID, Name: rule.Name, HelpURI: rule.HelpURI}
+	if rule.Name != "" {
+		d.ShortDescription = &SarifMessage{Text: rule.Name}
+	}
+	if rule.Description != "" {
+		d.FullDescription = &SarifMessage{Text: rule.Description}
+	}
+	if rule.Help != "" {
+		d.Help = &SarifMessage{Text: rule.Help}
+	}
+	if len(rule.Tags) > 0 {
+		d.Properties = map[string]interface{}{sarifPropTags: rule.Tags}
+	}
+	setRuleSeverity(&d, rule.Severity)
+	return d
+}
+
+func setRuleSeverity(d *SarifReportingDescriptor, severity string) {
+	severity = NormalizeSeverity(severity)
+	if severity == "" {
+		return
+	}
+	d.DefaultConfiguration = &SarifRuleConfiguration{Level: sarifLevel(severity)}
+	if d.Properties == nil {
+		d.Properties = make(map[string]interface{})
+	}
+	d.Properties[sarifPropSecuritySeverity] = securitySeverityScore(severity)
+}
+
+func ruleSeverity(d SarifReportingDescriptor) string {
+	if score, ok := parseScore(d.Properties[sarifPropSecuritySeverity]); ok {
+		return SeverityFromScore(score)
+	}
+	return ""
+}
+
+func (f Finding) toSarif(ruleIndex int) SarifResult {
+	severity := NormalizeSeverity(f.Severity)
+	if severity == "" {
+		severity = SeverityMedium
+	}
+	res := SarifResult{
+		RuleID:    f.RuleID,
+		RuleIndex: &ruleIndex,
+		Level:     sarifLevel(severity),
+		Message:   SarifMessage{Text: firstNonEmptyString(f.Message, f.RuleName, f.RuleID)},
+	}
+
+	var loc SarifLocation
+	if f.Location.Path != "" {
+		loc.PhysicalLocation = &SarifPhysicalLocation{ArtifactLocation: SarifArtifactLocation{URI: f.Location.Path}}
+		if f.Location.StartLine > 0 {
+			loc.PhysicalLocation.Region = &SarifRegion{StartLine: f.Location.StartLine, EndLine: f.Location.EndLine}
+		}
+	}
+	if f.Location.Logical != "" {
+		loc.LogicalLocations = []SarifLogicalLocation{{
+			FullyQualifiedName: f.Location.Logical,
+			Kind:               f.Location.LogicalKind,
+		}}
+	}
+	if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+		res.Locations = []SarifLocation{loc}
+	}
+
+	fingerprint := f.Fingerprint
+	if fingerprint == "" {
+		fingerprint = f.ComputeFingerprint()
+	}
+	res.Fingerprints = map[string]string{SARIFFingerprintKey: fingerprint}
+	res.BaselineState = sarifBaselineState(f.Status)
+
+	props := make(map[string]interface{}, len(f.Properties)+6)
+	for k, v := range f.Properties {
+		props[k] = v
+	}
+	props[sarifPropSeverity] = severity
+	props[sarifPropSecuritySeverity] = securitySeverityScore(severity)
+	setIfNotEmpty(props, sarifPropCategory, f.Category)
+	setIfNotEmpty(props, sarifPropCWE, f.CWEID)
+	setIfNotEmpty(props, sarifPropRemediation, f.Remediation)
+	setIfNotEmpty(props, sarifPropRuleName, f.RuleName)
+	res.Properties = props
+	return res
+}
+
+// Reports 将 SARIF 日志解码为发现项报告，每个 run 对应一个报告
+func (l *SarifLog) Reports() []FindingReport {
+	if l == nil {
+		return nil
+	}
+	reports := make([]FindingReport, 0, len(l.Runs))
+	for _, run := range l.Runs {
+		reports = append(reports, run.report())
+	}
+	return reports
+}
+
+// Findings 返回所有 run 中的发现项
+func (l *SarifLog) Findings() []Finding {
+	var findings []Finding
+	for _, report := range l.Reports() {
+		findings = append(findings, report.Findings...)
+	}
+	return findings
+}
+
+func (run SarifRun) report() FindingReport {
+	driver := run.Tool.Driver
+	report := FindingReport{
+		Tool:           driver.Name,
+		ToolVersion:    driver.Version,
+		InformationURI: driver.InformationURI,
+		Rules:          make([]FindingRule, 0, len(driver.Rules)),
+		Findings:       make([]Finding, 0, len(run.Results)),
+		Properties:     run.Properties,
+	}
+
+	rules := make(map[string]SarifReportingDescriptor, len(driver.Rules))
+	for _, d := range driver.Rules {
+		rules[d.ID] = d
+		report.Rules = append(report.Rules, FindingRule{
+			ID:          d.ID,
+			Name:        firstNonEmptyString(d.Name, messageText(d.ShortDescription)),
+			Description: messageText(d.FullDescription),
+			Help:        messageText(d.Help),
+			HelpURI:     d.HelpURI,
+			Severity:    sarifSeverity("", nil, d),
+			Tags:        stringList(d.Properties[sarifPropTags]),
+		})
+	}
+
+	for _, res := range run.Results {
+		rule, ok := rules[res.RuleID]
+		if !ok && res.RuleIndex != nil && *res.RuleIndex >= 0 && *res.RuleIndex < len(driver.Rules) {
+			rule = driver.Rules[*res.RuleIndex]
+		}
+		report.Findings = append(report.Findings, res.finding(rule))
+	}
+	return report
+}
+
+func (res SarifResult) finding(rule SarifReportingDescriptor) Finding {
+	props := res.Properties
+	tags := append(stringList(props[sarifPropTags]), stringList(rule.Properties[sarifPropTags])...)
+
+	f := Finding{
+		RuleID:   firstNonEmptyString(res.RuleID, rule.ID),
+		RuleName: firstNonEmptyString(propString(props, sarifPropRuleName), rule.Name, messageText(rule.ShortDescription), res.RuleID),
+		Severity: sarifSeverity(res.Level, props, rule),
+		Message:  firstNonEmptyString(res.Message.Text, messageText(rule.FullDescription), messageText(rule.ShortDescription)),
+		Category: propString(props, sarifPropCategory),
+		CWEID:    firstNonEmptyString(normalizeCWE(propString(props, sarifPropCWE)), extractCWETag(tags...)),
+	}
+
+	if len(res.Locations) > 0 {
+		loc := res.Locations[0]
+		if loc.PhysicalLocation != nil {
+			f.Location.Path = loc.PhysicalLocation.ArtifactLocation.URI
+			if region := loc.PhysicalLocation.Region; region != nil {
+				f.Location.StartLine = region.StartLine
+				f.Location.EndLine = region.EndLine
+			}
+		}
+		if len(loc.LogicalLocations) > 0 {
+			ll := loc.LogicalLocations[0]
+			f.Location.Logical = firstNonEmptyString(ll.FullyQualifiedName, ll.Name)
+			f.Location.LogicalKind = ll.Kind
+		}
+	}
+
+	f.Fingerprint = pickFingerprint(res.Fingerprints)
+	if f.Fingerprint == "" {
+		f.Fingerprint = pickFingerprint(res.PartialFingerprints)
+	}
+
+	f.Status = findingStatus(res.BaselineState)
+
+	f.Remediation = propString(props, sarifPropRemediation)
+	if f.Remediation == "" && len(res.Fixes) > 0 {
+		f.Remediation = messageText(res.Fixes[0].Description)
+	}
+	if f.Remediation == "" {
+		f.Remediation = messageText(rule.Help)
+	}
+
+	// 其余属性原样保留
+	for k, v := range props {
+		switch k {
+		case sarifPropSeverity, sarifPropSecuritySeverity, sarifPropCategory, sarifPropCWE, sarifPropRemediation, sarifPropRuleName:
+			continue
+		}
+		if f.Properties == nil {
+			f.Properties = make(map[string]interface{})
+		}
+		f.Properties[k] = v
+	}
+	return f
+}
+
+// sarifBaselineState 发现项状态与 SARIF baselineState 的对应关系，已修复对应 absent
+func sarifBaselineState(status string) string {
+	switch status {
+	case FindingStatusNew, FindingStatusUnchanged:
+		return status
+	case FindingStatusFixed:
+		return "absent"
+	}
+	return ""
+}
+
+func findingStatus(baselineState string) string {
+	switch baselineState {
+	case "new", "unchanged":
+		return baselineState
+	case "updated":
+		return FindingStatusUnchanged
+	case "absent":
+		return FindingStatusFixed
+	}
+	return ""
+}
+
+// sarifSeverity 依次使用显式 severity 属性、security-severity 评分与 level 推断严重等级
+func sarifSeverity(level string, props map[string]interface{}, rule SarifReportingDescriptor) string {
+	if s := NormalizeSeverity(propString(props, sarifPropSeverity)); s != "" {
+		return s
+	}
+	if score, ok := parseScore(props[sarifPropSecuritySeverity]); ok {
+		return SeverityFromScore(score)
+	}
+	if score, ok := parseScore(rule.Properties[sarifPropSecuritySeverity]); ok {
+		return SeverityFromScore(score)
+	}
+	if level == "" && rule.DefaultConfiguration != nil {
+		level = rule.DefaultConfiguration.Level
+	}
+	switch strings.ToLower(level) {
+	case "error":
+		return SeverityHigh
+	case "warning", "":
+		// SARIF 规定 level 缺省为 warning
+		return SeverityMedium
+	case "note":
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// sarifLevel 严重等级映射为 SARIF level
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	case SeverityLow:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// securitySeverityScore 严重等级对应的代表性评分，与代码托管平台的 security-severity 区间一致
+func securitySeverityScore(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "9.5"
+	case SeverityHigh:
+		return "8.0"
+	case SeverityMedium:
+		return "5.5"
+	case SeverityLow:
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
+
+func severityRank(severity string) int {
+	switch NormalizeSeverity(severity) {
+	case SeverityCritical:
+		return 5
+	case SeverityHigh:
+		return 4
+	case SeverityMedium:
+		return 3
+	case SeverityLow:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseScore(v interface{}) (float64, bool) {
+	switch s := v.(type) {
+	case string:
+		score, err := strconv.ParseFloat(s, 64)
+		return score, err == nil
+	case float64:
+		return s, true
+	}
+	return 0, false
+}
+
+// pickFingerprint 优先取本系统的指纹，否则按键名排序取第一个
+func pickFingerprint(fingerprints map[string]string) string {
+	if fp := fingerprints[SARIFFingerprintKey]; fp != "" {
+		return fp
+	}
+	keys := make([]string, 0, len(fingerprints))
+	for k := range fingerprints {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if fingerprints[k] != "" {
+			return fingerprints[k]
+		}
+	}
+	return ""
+}
+
+// extractCWETag 从标签中提取第一个 CWE 编号
+func extractCWETag(tags ...string) string {
+	for _, tag := range tags {
+		if cwe := normalizeCWE(tag); cwe != "" {
+			return cwe
+		}
+	}
+	return ""
+}
+
+// normalizeCWE 统一为 CWE-<id> 形式，去掉 CodeQL 等工具标签中的前导零（cwe-089）
+func normalizeCWE(s string) string {
+	if m := sarifCWEPattern.FindStringSubmatch(s); m != nil {
+		if id := strings.TrimLeft(m[1], "0"); id != "" {
+			return "CWE-" + id
+		}
+	}
+	return ""
+}
+
+func messageText(m *SarifMessage) string {
+	if m == nil {
+		return ""
+	}
+	return m.Text
+}
+
+func propString(props map[string]interface{}, key string) string {
+	s, _ := props[key].(string)
+	return s
+}
+
+func stringList(v interface{}) []string {
+	switch items := v.(type) {
+	case []string:
+		return items
+	case []interface{}:
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func setIfNotEmpty(props map[string]interface{}, key, value string) {
+	if value != "" {
+		props[key] = value
+	}
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+func TestSARIFRoundTrip(t *testing.T) {
+	report := domain.FindingReport{
+		Tool:        "go-sac-sca",
+		ToolVersion: "1.0.0",
+		Findings: []domain.Finding{
+			{
+				RuleID:      "GHSA-xxxx",
+				Severity:    domain.SeverityHigh,
+				Message:     "lodash@4.17.15 is affected by GHSA-xxxx",
+				CWEID:       "CWE-1321",
+				Location:    domain.FindingLocation{Path: "package-lock.json", Logical: "npm:lodash@4.17.15", LogicalKind: "package"},
+				Remediation: "Upgrade lodash to 4.17.21",
+				Properties:  map[string]interface{}{"ecosystem": "npm"},
+			},
+			{
+				RuleID:   "GHSA-xxxx",
+				Severity: domain.SeverityCritical,
+				Message:  "lodash@4.17.10 is affected by GHSA-xxxx",
+				Location: domain.FindingLocation{Path: "sub/package-lock.json", StartLine: 12},
+			},
+		},
+	}
+
+	data, err := domain.EncodeSARIF(report)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	log, err := domain.DecodeSARIF(data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	if len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 1 {
+		t.Fatalf("expected one run with one deduplicated rule, got %+v", log.Runs)
+	}
+	if level := log.Runs[0].Tool.Driver.Rules[0].DefaultConfiguration.Level; level != "error" {
+		t.Errorf("rule level should follow the highest finding severity, got %s", level)
+	}
+
+	reports := log.Reports()
+	if reports[0].Tool != "go-sac-sca" || reports[0].ToolVersion != "1.0.0" {
+		t.Errorf("unexpected tool %s %s", reports[0].Tool, reports[0].ToolVersion)
+	}
+	findings := log.Findings()
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+
+	got := findings[0]
+	want := report.Findings[0]
+	if got.RuleID != want.RuleID || got.Severity != want.Severity || got.Message != want.Message ||
+		got.CWEID != want.CWEID || got.Remediation != want.Remediation || got.Location != want.Location {
+		t.Errorf("finding mismatch:\n got %+v\nwant %+v", got, want)
+	}
+	if got.Properties["ecosystem"] != "npm" {
+		t.Errorf("custom property lost: %v", got.Properties)
+	}
+	if got.Fingerprint != want.ComputeFingerprint() {
+		t.Errorf("fingerprint should default to the computed value, got %s", got.Fingerprint)
+	}
+	if findings[1].Severity != domain.SeverityCritical || findings[1].Location.StartLine != 12 {
+		t.Errorf("unexpected second finding %+v", findings[1])
+	}
+}
+
+func TestDecodeThirdPartySARIF(t *testing.T) {
+	raw := `{
+	  "version": "2.1.0",
+	  "runs": [{
+	    "tool": {"driver": {"name": "CodeQL", "semanticVersion": "2.15.0", "rules": [{
+	      "id": "go/sql-injection",
+	      "name": "SqlInjection",
+	      "shortDescription": {"text": "Database query built from user-controlled sources"},
+	      "help": {"text": "Use parameterized queries"},
+	      "properties": {"tags": ["security", "external/cwe/cwe-089"], "security-severity": "8.8"}
+	    }]}},
+	    "results": [{
+	      "ruleId": "go/sql-injection",
+	      "ruleIndex": 0,
+	      "level": "error",
+	      "message": {"text": "This query depends on a user-provided value."},
+	      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "db/query.go"}, "region": {"startLine": 42}}}],
+	      "partialFingerprints": {"primaryLocationLineHash": "abc123:1"}
+	    }]
+	  }]
+	}`
+
+	log, err := domain.DecodeSARIF([]byte(raw))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	findings := log.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != domain.SeverityHigh {
+		t.Errorf("severity should come from security-severity, got %s", f.Severity)
+	}
+	if f.CWEID != "CWE-89" {
+		t.Errorf("cwe should come from rule tags, got %s", f.CWEID)
+	}
+	if f.RuleName != "SqlInjection" || f.Remediation != "Use parameterized queries" {
+		t.Errorf("rule metadata not applied: %+v", f)
+	}
+	if f.Location.Path != "db/query.go" || f.Location.StartLine != 42 {
+		t.Errorf("unexpected location %+v", f.Location)
+	}
+	if f.Fingerprint != "abc123:1" {
+		t.Errorf("fingerprint should fall back to partialFingerprints, got %s", f.Fingerprint)
+	}
+
+	if _, err := domain.DecodeSARIF([]byte(`{"version":"2.0.0","runs":[]}`)); err == nil {
+		t.Error("expected error for unsupported sarif version")
+	}
+}
+
+func TestScanResultFindings(t *testing.T) {
+	result := domain.NewScanResult("task-1", domain.ScanTypeSecretsDetection, "asset-1", domain.AssetTypeRepository)
+	result.SetSuccess(map[string]interface{}{})
+	result.SetFindings(domain.FindingReport{
+		Tool:     "go-sac-secretsdetection",
+		Findings: []domain.Finding{{RuleID: "aws-access-key", Severity: "HIGH", Fingerprint: "fp-1"}},
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var decoded domain.ScanResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	findings := decoded.Findings()
+	if len(findings) != 1 || findings[0].Fingerprint != "fp-1" || findings[0].Severity != domain.SeverityHigh {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+}
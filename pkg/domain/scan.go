@@ -4,14 +4,27 @@ import "time"
 
 // ScanResult 表示扫描结果
 type ScanResult struct {
-	TaskID    string                 `json:"task_id"`    // 任务ID
-	ScanType  ScanType               `json:"scan_type"`  // 扫描类型
-	AssetID   string                 `json:"asset_id"`   // 资产ID
-	AssetType AssetType              `json:"asset_type"` // 资产类型
-	Status    string                 `json:"status"`     // 扫描状态：success, failed
-	Result    map[string]interface{} `json:"result"`     // 扫描结果
-	Error     string                 `json:"error"`      // 错误信息
-	Timestamp time.Time              `json:"timestamp"`  // 扫描完成时间
+	TaskID     string                 `json:"task_id"`               // 任务ID
+	ScanType   ScanType               `json:"scan_type"`             // 扫描类型
+	AssetID    string                 `json:"asset_id"`              // 资产ID
+	AssetType  AssetType              `json:"asset_type"`            // 资产类型
+	Status     string                 `json:"status"`                // 扫描状态：success, failed
+	Result     map[string]interface{} `json:"result"`                // 扫描结果
+	SARIF      *SarifLog              `json:"sarif,omitempty"`       // SARIF 2.1.0 格式的发现项
+	Error      string                 `json:"error"`                 // 错误信息
+	LogObjects []string               `json:"log_objects,omitempty"` // 扫描命令原始输出在对象存储中的路径
+	Revision   *SourceRevision        `json:"revision,omitempty"`    // 代码仓库扫描的源码版本
+	Timestamp  time.Time              `json:"timestamp"`             // 扫描完成时间
+}
+
+// SourceRevision 代码仓库扫描实际检出的版本
+// Incremental 为 true 时发现项只覆盖 ChangedFiles，其余文件沿用上一次结果
+type SourceRevision struct {
+	Commit       string    `json:"commit"`                  // 检出的提交
+	CommitTime   time.Time `json:"commit_time"`             // 提交时间
+	BaseCommit   string    `json:"base_commit,omitempty"`   // 增量扫描的基线提交
+	Incremental  bool      `json:"incremental"`             // 是否只扫描了变更文件
+	ChangedFiles []string  `json:"changed_files,omitempty"` // 相对基线变更（含删除）的文件，仓库相对路径
 }
 
 // NewScanResult 创建扫描结果
@@ -31,6 +44,16 @@ func (r *ScanResult) SetSuccess(result map[string]interface{}) {
 	r.Result = result
 }
 
+// SetFindings 以 SARIF 记录发现项，Result 中保留扫描器特有的明细与统计
+func (r *ScanResult) SetFindings(reports ...FindingReport) {
+	r.SARIF = NewSarifLog(reports...)
+}
+
+// Findings 返回 SARIF 中的发现项
+func (r *ScanResult) Findings() []Finding {
+	return r.SARIF.Findings()
+}
+
 // SetFailed 设置失败结果
 func (r *ScanResult) SetFailed(err string) {
 	r.Status = "failed"
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 扫描器读取的强类型选项，字段与 pkg/profile/schemas 中对应扫描类型的 schema 一致；
+// 任务创建时选项已按 schema 校验并补全默认值
+
+// SourceOptions 源码类扫描（SAST/SCA/敏感信息）的目标描述
+type SourceOptions struct {
+	RepoURL     string `json:"repo_url,omitempty"`     // 代码仓库地址（RepositoryAsset.RepoURL）
+	Branch      string `json:"branch,omitempty"`       // 分支（RepositoryAsset.Branch）
+	Commit      string `json:"commit,omitempty"`       // 提交哈希
+	SourcePath  string `json:"source_path,omitempty"`  // 已落盘的源码目录，存在时跳过检出
+	ArchivePath string `json:"archive_path,omitempty"` // 已下载到本地的上传文件归档
+}
+
+// SASTOptions 静态代码扫描选项
+type SASTOptions struct {
+	SourceOptions
+	RulePacks []string `json:"rule_packs,omitempty"` // 覆盖配置中的规则包
+}
+
+// SecretsOptions 敏感信息扫描选项
+type SecretsOptions struct {
+	SourceOptions
+	FilePath     string `json:"file_path,omitempty"`     // 已下载到本地的单个上传文件
+	HistoryDepth *int   `json:"history_depth,omitempty"` // 覆盖配置中的历史扫描深度
+}
+
+// DASTOptions 动态应用扫描选项
+type DASTOptions struct {
+	TargetURL  string           `json:"target_url,omitempty"`  // 扫描入口地址
+	DomainName string           `json:"domain_name,omitempty"` // 未指定 target_url 时按 https 访问的域名
+	Exclude    []string         `json:"exclude,omitempty"`     // 不爬取、不探测的 URL 正则
+	Auth       *DASTAuthOptions `json:"auth,omitempty"`        // 登录方式，为空时匿名扫描
+}
+
+// DAST 登录方式
+const (
+	DASTAuthForm   = "form"   // 提交登录表单，会话失效后自动重新登录
+	DASTAuthBearer = "bearer" // 请求头携带令牌
+	DASTAuthCookie = "cookie" // 注入已有会话 Cookie
+	DASTAuthBasic  = "basic"  // HTTP Basic 认证
+)
+
+// DASTAuthOptions DAST 登录配置，Password、Token 与 Cookie 值为 crypt.KeyManager 加密后的密文
+type DASTAuthOptions struct {
+	Type          string            `json:"type"`
+	LoginURL      string            `json:"login_url,omitempty"`
+	UsernameField string            `json:"username_field,omitempty"`
+	PasswordField string            `json:"password_field,omitempty"`
+	Username      string            `json:"username,omitempty"`
+	Password      string            `json:"password,omitempty"`
+	ExtraFields   map[string]string `json:"extra_fields,omitempty"`
+	Token         string            `json:"token,omitempty"`
+	Header        string            `json:"header,omitempty"`
+	Cookies       []DASTCookie      `json:"cookies,omitempty"`
+	LogoutMarkers []string          `json:"logout_markers,omitempty"` // 响应体匹配任一正则即视为会话失效
+	MaxRelogins   *int              `json:"max_relogins,omitempty"`
+}
+
+// DASTCookie 注入的会话 Cookie
+type DASTCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PortScanOptions 端口扫描选项
+type PortScanOptions struct {
+	IPAddress  string `json:"ip_address,omitempty"`  // 逗号分隔的 IP 或 CIDR
+	DomainName string `json:"domain_name,omitempty"` // 解析后扫描的域名
+	Ports      string `json:"ports,omitempty"`       // 覆盖配置中的端口范围
+}
+
+// ImageScanOptions 容器镜像扫描选项
+type ImageScanOptions struct {
+	ImagePath   string `json:"image_path,omitempty"`   // 本地镜像归档或 OCI 布局目录
+	ImageObject string `json:"image_object,omitempty"` // MinIO 中的镜像归档对象路径
+}
+
+// 主机安全检查的传输方式
+const (
+	HostTransportSSH   = "ssh"   // 通过 SSH 登录目标主机执行只读命令
+	HostTransportLocal = "local" // 以本地目录作为目标主机的根文件系统，用于离线检查与测试
+)
+
+// HostCheckOptions 主机安全检查选项
+type HostCheckOptions struct {
+	IPAddress       string          `json:"ip_address,omitempty"`       // 目标主机（IPAsset.IPAddress）
+	Transport       string          `json:"transport,omitempty"`        // ssh（默认）或 local
+	RootDir         string          `json:"root_dir,omitempty"`         // local 传输使用的根目录
+	SSH             *HostSSHOptions `json:"ssh,omitempty"`              // SSH 登录配置
+	BaselineVersion string          `json:"baseline_version,omitempty"` // 固定基线版本，为空时使用配置中的版本或最高版本
+	Controls        []string        `json:"controls,omitempty"`         // 只检查这些控制项，为空时检查全部
+}
+
+// HostSSHOptions SSH 登录配置，Password、PrivateKey 与 Passphrase 为 crypt.KeyManager 加密后的密文
+type HostSSHOptions struct {
+	Port       int    `json:"port,omitempty"`
+	Username   string `json:"username"`
+	Password   string `json:"password,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"` // PEM 格式私钥
+	Passphrase string `json:"passphrase,omitempty"`  // 私钥口令
+	HostKey    string `json:"host_key,omitempty"`    // 固定的主机公钥（authorized_keys 格式）或 SHA256 指纹，优先于 known_hosts
+}
+
+// ComplianceAuditOptions 合规审计选项
+type ComplianceAuditOptions struct {
+	Frameworks []string `json:"frameworks,omitempty"` // 评估的框架（ComplianceDoc 物料名称），为空时使用配置
+	MaxAge     string   `json:"max_age,omitempty"`    // 只采信该时长内入库的扫描结果，如 720h
+}
+
+// SecuritySpecOptions 安全规范检查选项，源码与 CICDConfig 至少提供一项
+type SecuritySpecOptions struct {
+	SourceOptions
+	CICDConfig  interface{} `json:"ci_cd_config,omitempty"` // 流水线配置（RepositoryAsset.CICDConfig），JSON 字符串或对象
+	Specs       []string    `json:"specs,omitempty"`        // 检查的安全规范（SecuritySpec 物料名称），为空时使用配置
+	SpecVersion string      `json:"spec_version,omitempty"` // 固定安全规范版本，为空时使用配置中的版本或最高版本
+}
+
+// BlackBoxOptions 黑盒测试选项，回放上传文件资产中的 HAR 流量或 OpenAPI 描述
+type BlackBoxOptions struct {
+	TargetURL   string           `json:"target_url,omitempty"`   // 回放目标，为空时使用 HAR 中请求最多的站点或 OpenAPI servers
+	DomainName  string           `json:"domain_name,omitempty"`  // 未指定 target_url 时按 https 访问的域名
+	FilePaths   []string         `json:"file_paths,omitempty"`   // 已下载到本地的 HAR/OpenAPI 文件
+	FileObjects []string         `json:"file_objects,omitempty"` // MinIO 中的 HAR/OpenAPI 文件（UploadedFileAsset.FilePath）
+	Exclude     []string         `json:"exclude,omitempty"`      // 不回放的 URL 正则
+	Auth        *DASTAuthOptions `json:"auth,omitempty"`         // 登录方式，为空时使用流量中记录的凭据
+	Mutations   []string         `json:"mutations,omitempty"`    // 覆盖配置中启用的参数变异
+	ForeignIDs  []string         `json:"foreign_ids,omitempty"`  // 属于其它账号的对象 ID，越权检测时替换请求中的 ID
+}
+
+// DecodeOptions 将 Options 解码到强类型选项结构，未知字段忽略
+func (p *ScanTaskPayload) DecodeOptions(v interface{}) error {
+	if len(p.Options) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(p.Options)
+	if err != nil {
+		return fmt.Errorf("encode task options: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode task options: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package domain
 
 import (
 	"encoding/json"
-	"github.com/google/uuid"
+	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TaskStatus 定义任务状态
@@ -71,6 +73,28 @@ type ScanTaskPayload struct {
 	AssetType AssetType              `json:"asset_type"` // 资产类型
 	ScanType  ScanType               `json:"scan_type"`  // 扫描类型
 	Options   map[string]interface{} `json:"options"`    // 扫描选项
+	Priority  TaskPriority           `json:"priority"`   // 任务优先级，用于计算超时
+	// BaseCommit 代码仓库的基线提交（通常为 RepositoryAsset.LastCommitHash），
+	// 设置时只扫描基线与新检出版本之间变更的文件
+	BaseCommit string `json:"base_commit,omitempty"`
+	// Profile 生成 Options 的扫描 profile 及其版本，未使用 profile 时为空
+	Profile *ScanProfileRef `json:"profile,omitempty"`
+	// UserID、OrganizationID 任务所属租户，由任务服务按认证用户与资产所属组织设置，扫描服务调度时按租户公平轮转
+	UserID         uint `json:"user_id,omitempty"`
+	OrganizationID uint `json:"organization_id,omitempty"`
+}
+
+// ScanProfileRef 任务使用的扫描 profile 版本
+type ScanProfileRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+func (r *ScanProfileRef) String() string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s@%d", r.Name, r.Version)
 }
 
 // AssetTaskPayload 资产更新任务的载荷
@@ -78,20 +102,43 @@ type AssetTaskPayload struct {
 	TaskID    string                 `json:"task_id"`    // 任务ID
 	AssetID   string                 `json:"asset_id"`   // 资产ID
 	AssetType AssetType              `json:"asset_type"` // 资产类型
-	Operation string                 `json:"operation"`  // 操作类型：create, update, delete
+	Operation string                 `json:"operation"`  // 操作类型：create, update, delete, commit
 	Data      map[string]interface{} `json:"data"`       // 资产数据
 }
 
+// AssetOperationCommit 扫描结果回写代码仓库资产最近扫描的提交
+const AssetOperationCommit = "commit"
+
+// TaskCancelPayload 取消运行中扫描任务的控制指令
+type TaskCancelPayload struct {
+	TaskID      string    `json:"task_id"`      // 任务ID
+	ScanType    ScanType  `json:"scan_type"`    // 扫描类型
+	RequestedAt time.Time `json:"requested_at"` // 发起取消的时间
+}
+
 // NewScanTask 创建一个新的扫描任务
 func NewScanTask(scanType ScanType, assetID string, assetType AssetType, options map[string]interface{}, priority TaskPriority, userID uint) (*Task, error) {
+	return NewIncrementalScanTask(scanType, assetID, assetType, "", options, priority, userID)
+}
+
+// NewIncrementalScanTask 创建只扫描 baseCommit 之后变更文件的扫描任务，baseCommit 为空时等同于全量扫描
+func NewIncrementalScanTask(scanType ScanType, assetID string, assetType AssetType, baseCommit string, options map[string]interface{}, priority TaskPriority, userID uint) (*Task, error) {
+	return NewScanTaskFromPayload(ScanTaskPayload{
+		AssetID:    assetID,
+		AssetType:  assetType,
+		ScanType:   scanType,
+		Options:    options,
+		Priority:   priority,
+		BaseCommit: baseCommit,
+	}, userID)
+}
+
+// NewScanTaskFromPayload 按载荷创建扫描任务，任务ID由此生成并写入载荷
+func NewScanTaskFromPayload(payload ScanTaskPayload, userID uint) (*Task, error) {
 	taskID := uuid.New().String()
-	payload := ScanTaskPayload{
-		TaskID:    taskID,
-		AssetID:   assetID,
-		AssetType: assetType,
-		ScanType:  scanType,
-		Options:   options,
-	}
+	payload.TaskID = taskID
+	payload.UserID = userID
+	scanType, priority := payload.ScanType, payload.Priority
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
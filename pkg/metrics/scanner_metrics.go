@@ -44,6 +44,89 @@ var (
 		},
 		[]string{"scan_type"},
 	)
+
+	// ScannerCommandMemoryPeak 记录扫描子进程所在控制组的峰值内存
+	ScannerCommandMemoryPeak = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scanner_command_memory_peak_bytes",
+			Help:    "Peak memory usage of scanner command cgroups in bytes",
+			Buckets: prometheus.ExponentialBuckets(16<<20, 2, 10),
+		},
+		[]string{"scan_type"},
+	)
+
+	// ScannerCommandOOMKills 记录扫描子进程被 cgroup OOM 终止的次数
+	ScannerCommandOOMKills = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_command_oom_kills_total",
+			Help: "Total number of scanner commands killed by cgroup OOM",
+		},
+		[]string{"scan_type"},
+	)
+
+	// ScannerCgroupAvailable 记录 cgroup 资源限制是否可用（1 可用，0 降级为不限制）
+	ScannerCgroupAvailable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scanner_cgroup_available",
+			Help: "Whether cgroup v2 resource limits are available for scanner commands",
+		},
+		[]string{"scan_type"},
+	)
+
+	// ScannerCircuitBreakerState 记录各扫描类型熔断器状态（0 关闭，1 打开，2 半开）
+	ScannerCircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scanner_circuit_breaker_state",
+			Help: "Circuit breaker state per scan type (0 closed, 1 open, 2 half-open)",
+		},
+		[]string{"scan_type"},
+	)
+
+	// ScannerCircuitBreakerHostState 记录按目标主机熔断的状态，恢复关闭后删除该序列
+	ScannerCircuitBreakerHostState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scanner_circuit_breaker_host_state",
+			Help: "Circuit breaker state of tripped target hosts (1 open, 2 half-open)",
+		},
+		[]string{"scan_type", "host"},
+	)
+
+	// ScannerCircuitBreakerTransitions 记录熔断器状态变化次数
+	ScannerCircuitBreakerTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_circuit_breaker_transitions_total",
+			Help: "Total number of circuit breaker state transitions",
+		},
+		[]string{"scan_type", "from", "to"},
+	)
+
+	// ScanSchedulerQueueWait 记录扫描任务从进入调度器到开始处理的等待时间，按任务原始优先级区分
+	ScanSchedulerQueueWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scan_scheduler_queue_wait_seconds",
+			Help:    "Time scan tasks wait in the priority scheduler before dispatch",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"priority"},
+	)
+
+	// ScanSchedulerQueueDepth 记录调度器中各优先级等待的任务数
+	ScanSchedulerQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scan_scheduler_queue_depth",
+			Help: "Number of scan tasks waiting in the priority scheduler",
+		},
+		[]string{"priority"},
+	)
+
+	// ScanSchedulerPromotions 记录等待超时提升优先级的任务数
+	ScanSchedulerPromotions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scan_scheduler_promotions_total",
+			Help: "Total number of scan tasks promoted to a higher priority after waiting too long",
+		},
+		[]string{"from", "to"},
+	)
 )
 
 // ScannerMetrics 实现扫描器指标收集
@@ -60,6 +143,15 @@ func (m *ScannerMetrics) Register() {
 	prometheus.MustRegister(ScannerExecutorFailures)
 	prometheus.MustRegister(ScannerTimeouts)
 	prometheus.MustRegister(ScannerCriticalTimeouts)
+	prometheus.MustRegister(ScannerCommandMemoryPeak)
+	prometheus.MustRegister(ScannerCommandOOMKills)
+	prometheus.MustRegister(ScannerCgroupAvailable)
+	prometheus.MustRegister(ScannerCircuitBreakerState)
+	prometheus.MustRegister(ScannerCircuitBreakerHostState)
+	prometheus.MustRegister(ScannerCircuitBreakerTransitions)
+	prometheus.MustRegister(ScanSchedulerQueueWait)
+	prometheus.MustRegister(ScanSchedulerQueueDepth)
+	prometheus.MustRegister(ScanSchedulerPromotions)
 }
 
 // Record 记录指标
@@ -69,17 +161,24 @@ func (m *ScannerMetrics) Record(name string, value float64, tags map[string]stri
 		ScannerExecutionTime.WithLabelValues(tags["scanner_type"]).Observe(value)
 	case "command_errors":
 		ScannerExecutorFailures.WithLabelValues(tags["scanner_type"]).Inc()
+	case "command_oom_kills":
+		ScannerCommandOOMKills.WithLabelValues(tags["scanner_type"]).Add(value)
 	}
 }
 
 // Gauge 设置仪表盘指标
 func (m *ScannerMetrics) Gauge(name string, value float64, tags map[string]string) {
-	// 目前没有仪表盘指标需要实现
+	switch name {
+	case "command_memory_peak_bytes":
+		ScannerCommandMemoryPeak.WithLabelValues(tags["scanner_type"]).Observe(value)
+	case "cgroup_available":
+		ScannerCgroupAvailable.WithLabelValues(tags["scanner_type"]).Set(value)
+	}
 }
 
 // RecordExecutionTime 记录执行时间
 func (m *ScannerMetrics) RecordExecutionTime(scanType domain.ScanType, duration time.Duration) {
-	ScannerExecutionTime.WithLabelValues(string(scanType)).Observe(duration.Seconds())
+	ScannerExecutionTime.WithLabelValues(scanType.String()).Observe(duration.Seconds())
 }
 
 // RecordExecutorFailure 记录执行器失败
@@ -89,10 +188,33 @@ func (m *ScannerMetrics) RecordExecutorFailure(executorType string) {
 
 // RecordTimeout 记录超时事件
 func (m *ScannerMetrics) RecordTimeout(scanType domain.ScanType, severity string, isHard bool) {
-	ScannerTimeouts.WithLabelValues(string(scanType), severity).Inc()
+	ScannerTimeouts.WithLabelValues(scanType.String(), severity).Inc()
 }
 
 // RecordCriticalTimeout 记录严重超时事件
 func (m *ScannerMetrics) RecordCriticalTimeout(scanType domain.ScanType) {
-	ScannerCriticalTimeouts.WithLabelValues(string(scanType)).Inc()
+	ScannerCriticalTimeouts.WithLabelValues(scanType.String()).Inc()
+}
+
+// SetCircuitBreakerState 设置扫描类型熔断器状态；host 非空时为按目标主机的熔断器，state 为 0（关闭）时删除该序列
+func (m *ScannerMetrics) SetCircuitBreakerState(scanType domain.ScanType, host string, state float64) {
+	if host == "" {
+		ScannerCircuitBreakerState.WithLabelValues(scanType.String()).Set(state)
+		return
+	}
+	if state == 0 {
+		ScannerCircuitBreakerHostState.DeleteLabelValues(scanType.String(), host)
+		return
+	}
+	ScannerCircuitBreakerHostState.WithLabelValues(scanType.String(), host).Set(state)
+}
+
+// DeleteCircuitBreakerState 删除已回收的主机熔断器的状态序列
+func (m *ScannerMetrics) DeleteCircuitBreakerState(scanType domain.ScanType, host string) {
+	ScannerCircuitBreakerHostState.DeleteLabelValues(scanType.String(), host)
+}
+
+// RecordCircuitBreakerTransition 记录熔断器状态变化
+func (m *ScannerMetrics) RecordCircuitBreakerTransition(scanType domain.ScanType, from, to string) {
+	ScannerCircuitBreakerTransitions.WithLabelValues(scanType.String(), from, to).Inc()
 }
@@ -6,6 +6,8 @@ const (
 	ResultProcessExchange = "result_process_exchange"
 	NotificationExchange  = "notification_exchange"
 	RetryExchange         = "retry_exchange"
+	// TaskControlExchange 任务控制指令（如取消）广播到所有扫描服务实例
+	TaskControlExchange = "task_control_exchange"
 )
 
 // Queue names
@@ -44,6 +46,9 @@ const (
 	// Result routing pattern
 	ResultStoragePattern = "result.storage"
 
+	// Task control routing key
+	TaskCancelRoutingKey = "control.cancel"
+
 	// Retry patterns
 	RetryPattern  = "retry.#"
 	ManualPattern = "manual.#"
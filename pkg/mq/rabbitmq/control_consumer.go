@@ -0,0 +1,128 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/blackarbiter/go-sac/pkg/mq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// TaskControlConsumer 消费任务控制指令
+// 每个实例声明独占的临时队列绑定到扇出交换机，保证取消指令送达所有扫描服务实例
+type TaskControlConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+	done    chan struct{}
+}
+
+// NewTaskControlConsumer 创建任务控制消费者实例
+func NewTaskControlConsumer(conn *amqp.Connection) (*TaskControlConsumer, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// 检查交换机是否存在
+	err = channel.ExchangeDeclarePassive(
+		TaskControlExchange,
+		"fanout", // exchange类型
+		true,     // durable
+		false,    // auto-delete
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("exchange %s not found, please run setup first: %w", TaskControlExchange, err)
+	}
+
+	queue, err := channel.QueueDeclare(
+		"",    // name - server generated
+		false, // durable
+		true,  // auto-delete
+		true,  // exclusive
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to declare control queue: %w", err)
+	}
+	if err := channel.QueueBind(queue.Name, "", TaskControlExchange, false, nil); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to bind control queue: %w", err)
+	}
+
+	return &TaskControlConsumer{
+		conn:    conn,
+		channel: channel,
+		queue:   queue.Name,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// ConsumeControl 消费本实例的控制队列
+func (c *TaskControlConsumer) ConsumeControl(ctx context.Context, handler mq.MessageHandler) error {
+	return c.consume(ctx, c.queue, handler)
+}
+
+// Consume 实现 Consumer 接口
+func (c *TaskControlConsumer) Consume(ctx context.Context, queueName string, handler mq.MessageHandler) error {
+	return c.consume(ctx, queueName, handler)
+}
+
+// consume 内部消费方法
+func (c *TaskControlConsumer) consume(ctx context.Context, queueName string, handler mq.MessageHandler) error {
+	deliveries, err := c.channel.Consume(
+		queueName,
+		"",    // consumer tag - auto generated
+		true,  // auto-ack，控制指令丢失时由任务超时兜底
+		true,  // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ctx.Done():
+				return
+			case delivery, ok := <-deliveries:
+				if !ok {
+					log.Printf("Control consumer channel closed")
+					return
+				}
+				if err := handler.HandleMessage(ctx, delivery.Body); err != nil {
+					log.Printf("Error processing control message: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close 关闭消费者
+func (c *TaskControlConsumer) Close() error {
+	// 通知消费者goroutine停止
+	close(c.done)
+
+	if err := c.channel.Close(); err != nil {
+		return fmt.Errorf("failed to close channel: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure TaskControlConsumer implements the mq.Consumer interface
+var _ mq.Consumer = (*TaskControlConsumer)(nil)
@@ -102,6 +102,15 @@ func setupExchanges(ch *amqp.Channel) error {
 			NoWait:     false,
 			Arguments:  nil,
 		},
+		{
+			Name:       TaskControlExchange,
+			Type:       "fanout",
+			Durable:    true,
+			AutoDelete: false,
+			Internal:   false,
+			NoWait:     false,
+			Arguments:  nil,
+		},
 	}
 
 	for _, exchange := range exchanges {
@@ -90,6 +90,11 @@ func (p *TaskPublisher) DeleteAssetTask(ctx context.Context, operation string, p
 	return p.delete(ctx, routingKey, 0, payload)
 }
 
+// PublishTaskCancel 向所有扫描服务实例广播取消指令，用于终止运行中的扫描任务
+func (p *TaskPublisher) PublishTaskCancel(ctx context.Context, payload []byte) error {
+	return p.producer.Publish(ctx, TaskControlExchange, TaskCancelRoutingKey, payload)
+}
+
 // Close closes the publisher
 func (p *TaskPublisher) Close() error {
 	return p.producer.Close()
@@ -0,0 +1,119 @@
+// Package profile 定义扫描 profile：按扫描类型的 JSON Schema 校验、补全默认值并带版本的命名扫描选项集合
+package profile
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Profile 命名的扫描选项集合，同名 profile 每次保存生成新版本，已保存的版本不可修改
+type Profile struct {
+	Name        string                 `json:"name"`
+	Version     int                    `json:"version"`
+	ScanType    string                 `json:"scan_type"`
+	Description string                 `json:"description,omitempty"`
+	Options     map[string]interface{} `json:"options"`
+	CreatedBy   uint                   `json:"created_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// Ref 返回任务载荷中记录的 profile 引用
+func (p *Profile) Ref() *domain.ScanProfileRef {
+	return &domain.ScanProfileRef{Name: p.Name, Version: p.Version}
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// ErrInvalidName profile 名称不合法
+var ErrInvalidName = errors.New("profile name must be 1-64 lowercase letters, digits or '-'")
+
+// ValidateName 校验 profile 名称，如 sast-quick、dast-full-authenticated
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+var (
+	schemaMu    sync.Mutex
+	schemaCache = make(map[domain.ScanType]*Schema)
+)
+
+// SchemaFor 返回扫描类型的选项 schema，没有内置 schema 的扫描类型接受任意对象
+func SchemaFor(scanType domain.ScanType) (*Schema, error) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if s, ok := schemaCache[scanType]; ok {
+		return s, nil
+	}
+
+	data, err := schemaFS.ReadFile("schemas/" + strings.ToLower(scanType.String()) + ".json")
+	if err != nil {
+		data = []byte(fmt.Sprintf(`{"title": %q, "type": "object"}`, scanType.String()))
+	}
+	s, err := ParseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", scanType, err)
+	}
+	schemaCache[scanType] = s
+	return s, nil
+}
+
+// Resolve 依次合并 schema 默认值、profile 选项与任务级覆盖，并校验合并结果
+// profile 为 nil 时只校验任务选项并补全默认值；覆盖项中的 nil 值表示删除 profile 中的同名选项。
+// 任务级覆盖不能携带密文，profile 含有凭据时不能把扫描目标改到其它 origin 或主机
+func Resolve(scanType domain.ScanType, p *Profile, overrides map[string]interface{}) (map[string]interface{}, error) {
+	schema, err := SchemaFor(scanType)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil && p.ScanType != scanType.String() {
+		return nil, ValidationErrors{{
+			Path:    "profile",
+			Message: fmt.Sprintf("%s@%d is for %s, not %s", p.Name, p.Version, p.ScanType, scanType),
+		}}
+	}
+
+	if HasSealed(overrides) {
+		return nil, ValidationErrors{{Path: "options", Message: "sealed values cannot be submitted, provide credentials in plaintext"}}
+	}
+
+	merged := make(map[string]interface{})
+	if p != nil {
+		for k, v := range p.Options {
+			merged[k] = v
+		}
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = v
+	}
+
+	options := schema.ApplyDefaults(merged)
+	if err := schema.Validate(options); err != nil {
+		return nil, err
+	}
+	if p != nil && HasSealed(p.Options) {
+		if bound, target := CredentialScope(scanType, p.Options), CredentialScope(scanType, options); bound != target {
+			return nil, ValidationErrors{{
+				Path:    credentialScopeField(scanType),
+				Message: fmt.Sprintf("credentials of %s@%d are bound to %s, not %s", p.Name, p.Version, bound, target),
+			}}
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,210 @@
+package profile_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/profile"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinSchemasParse(t *testing.T) {
+	for _, scanType := range []domain.ScanType{
+		domain.ScanTypeStaticCodeAnalysis,
+		domain.ScanTypeSca,
+		domain.ScanTypeSecretsDetection,
+		domain.ScanTypeDast,
+		domain.ScanTypePortScanning,
+		domain.ScanTypeContainerImageScan,
+	} {
+		schema, err := profile.SchemaFor(scanType)
+		require.NoError(t, err, scanType.String())
+		assert.Equal(t, scanType.String(), schema.Title)
+		assert.NotEmpty(t, schema.Properties, scanType.String())
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := profile.SchemaFor(domain.ScanTypeSecretsDetection)
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(map[string]interface{}{
+		"repo_url":      "https://git.example.com/app.git",
+		"history_depth": float64(50),
+	}))
+
+	err = schema.Validate(map[string]interface{}{
+		"commit":        "not-a-sha",
+		"history_depth": 1.5,
+	})
+	var invalid profile.ValidationErrors
+	require.True(t, errors.As(err, &invalid))
+	paths := make([]string, len(invalid))
+	for i, e := range invalid {
+		paths[i] = e.Path
+	}
+	assert.ElementsMatch(t, []string{"commit", "history_depth"}, paths)
+}
+
+func TestSchemaRequiredAndDefaults(t *testing.T) {
+	schema, err := profile.ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["target"],
+		"properties": {
+			"target": {"type": "string"},
+			"auth": {
+				"type": "object",
+				"properties": {"method": {"type": "string", "enum": ["form", "bearer"], "default": "form"}}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.Error(t, schema.Validate(map[string]interface{}{}))
+	assert.NoError(t, schema.ValidatePartial(map[string]interface{}{}))
+
+	options := schema.ApplyDefaults(map[string]interface{}{"target": "x"})
+	assert.Equal(t, map[string]interface{}{"method": "form"}, options["auth"])
+	assert.NoError(t, schema.Validate(options))
+
+	err = schema.Validate(map[string]interface{}{"target": "x", "auth": map[string]interface{}{"method": "basic"}})
+	assert.ErrorContains(t, err, "auth.method")
+}
+
+func TestResolve(t *testing.T) {
+	p := &profile.Profile{
+		Name:     "secrets-deep",
+		Version:  3,
+		ScanType: domain.ScanTypeSecretsDetection.String(),
+		Options: map[string]interface{}{
+			"history_depth": float64(500),
+			"branch":        "develop",
+		},
+	}
+
+	options, err := profile.Resolve(domain.ScanTypeSecretsDetection, p, map[string]interface{}{
+		"repo_url": "https://git.example.com/app.git",
+		"branch":   nil,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(500), options["history_depth"])
+	assert.Equal(t, "main", options["branch"], "nil override removes the profile value and falls back to the default")
+	assert.Equal(t, "https://git.example.com/app.git", options["repo_url"])
+	assert.Equal(t, "develop", p.Options["branch"], "profile options must not be modified")
+
+	_, err = profile.Resolve(domain.ScanTypeSecretsDetection, p, map[string]interface{}{"history_depth": float64(-1)})
+	assert.ErrorContains(t, err, "history_depth")
+
+	_, err = profile.Resolve(domain.ScanTypeDast, p, nil)
+	var invalid profile.ValidationErrors
+	assert.True(t, errors.As(err, &invalid))
+}
+
+func TestResolveWithoutProfile(t *testing.T) {
+	options, err := profile.Resolve(domain.ScanTypeDast, nil, map[string]interface{}{"target_url": "https://app.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com", options["target_url"])
+
+	_, err = profile.Resolve(domain.ScanTypeDast, nil, map[string]interface{}{"target_url": "app.example.com"})
+	assert.ErrorContains(t, err, "target_url")
+
+	// 没有内置 schema 的扫描类型接受任意选项
+	_, err = profile.Resolve(domain.ScanTypeThreatModeling, nil, map[string]interface{}{"anything": 1})
+	assert.NoError(t, err)
+}
+
+func TestSealSecrets(t *testing.T) {
+	keys := crypt.NewKeyManager([]byte("0123456789abcdef0123456789abcdef"), 0)
+	options, err := profile.Resolve(domain.ScanTypeDast, nil, map[string]interface{}{
+		"target_url": "https://app.example.com",
+		"auth": map[string]interface{}{
+			"type":    "cookie",
+			"cookies": []interface{}{map[string]interface{}{"name": "sid", "value": "abc"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, options, "exclude")
+
+	schema, err := profile.SchemaFor(domain.ScanTypeDast)
+	require.NoError(t, err)
+	scope := profile.CredentialScope(domain.ScanTypeDast, options)
+	assert.Equal(t, "https://app.example.com", scope)
+	sealed, err := schema.SealSecrets(options, keys, scope)
+	require.NoError(t, err)
+
+	auth := sealed["auth"].(map[string]interface{})
+	value := auth["cookies"].([]interface{})[0].(map[string]interface{})["value"].(string)
+	assert.True(t, crypt.IsSealed(value))
+	plaintext, err := keys.OpenStringBound(value, scope)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", plaintext)
+	_, err = keys.OpenStringBound(value, "https://evil.example.com")
+	assert.Error(t, err)
+	assert.Equal(t, "sid", auth["cookies"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Equal(t, float64(5), auth["max_relogins"])
+
+	// 原选项不被修改；不接受外部提交的密文，服务端的密文只能以原绑定解密后重新加密
+	assert.Equal(t, "abc", options["auth"].(map[string]interface{})["cookies"].([]interface{})[0].(map[string]interface{})["value"])
+	_, err = schema.SealSecrets(sealed, keys, scope)
+	assert.ErrorIs(t, err, crypt.ErrAlreadySealed)
+	_, err = schema.ResealSecrets(sealed, keys, "profile:other|"+scope, scope)
+	assert.Error(t, err)
+	resealed, err := schema.ResealSecrets(sealed, keys, scope, "https://app.example.com:8443")
+	require.NoError(t, err)
+	value = resealed["auth"].(map[string]interface{})["cookies"].([]interface{})[0].(map[string]interface{})["value"].(string)
+	plaintext, err = keys.OpenStringBound(value, "https://app.example.com:8443")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", plaintext)
+
+	// 凭据必须有可绑定的扫描目标
+	_, err = schema.SealSecrets(options, keys, "")
+	assert.ErrorIs(t, err, profile.ErrUnscopedSecret)
+
+	// 没有 auth 时不凭空生成带必填字段的对象
+	options, err = profile.Resolve(domain.ScanTypeDast, nil, map[string]interface{}{"target_url": "https://app.example.com"})
+	require.NoError(t, err)
+	assert.NotContains(t, options, "auth")
+}
+
+func TestResolveRejectsCredentialRetarget(t *testing.T) {
+	keys := crypt.NewKeyManager([]byte("0123456789abcdef0123456789abcdef"), 0)
+	schema, err := profile.SchemaFor(domain.ScanTypeDast)
+	require.NoError(t, err)
+	options, err := schema.SealSecrets(map[string]interface{}{
+		"target_url": "https://app.example.com/",
+		"auth":       map[string]interface{}{"type": "bearer", "token": "t0ken"},
+	}, keys, profile.ProfileBinding("dast-app", "https://app.example.com"))
+	require.NoError(t, err)
+	p := &profile.Profile{Name: "dast-app", Version: 1, ScanType: "DAST", Options: options}
+
+	// 同源的入口可以覆盖
+	_, err = profile.Resolve(domain.ScanTypeDast, p, map[string]interface{}{"target_url": "https://APP.example.com:443/account"})
+	assert.NoError(t, err)
+
+	// 把 profile 的凭据用到其它 origin
+	var verrs profile.ValidationErrors
+	_, err = profile.Resolve(domain.ScanTypeDast, p, map[string]interface{}{"target_url": "https://evil.example.com/"})
+	require.ErrorAs(t, err, &verrs)
+	assert.Equal(t, "target_url", verrs[0].Path)
+	_, err = profile.Resolve(domain.ScanTypeDast, p, map[string]interface{}{"target_url": nil, "domain_name": "evil.example.com"})
+	assert.ErrorAs(t, err, &verrs)
+
+	// 任务级选项不能携带密文
+	token := options["auth"].(map[string]interface{})["token"]
+	_, err = profile.Resolve(domain.ScanTypeDast, nil, map[string]interface{}{
+		"target_url": "https://evil.example.com/",
+		"auth":       map[string]interface{}{"type": "bearer", "token": token},
+	})
+	require.ErrorAs(t, err, &verrs)
+	assert.Equal(t, "options", verrs[0].Path)
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, profile.ValidateName("sast-quick"))
+	assert.ErrorIs(t, profile.ValidateName("SAST Quick"), profile.ErrInvalidName)
+	assert.ErrorIs(t, profile.ValidateName("-leading"), profile.ErrInvalidName)
+	assert.ErrorIs(t, profile.ValidateName(""), profile.ErrInvalidName)
+}
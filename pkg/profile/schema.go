@@ -0,0 +1,290 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema 扫描选项的 JSON Schema，只支持描述扫描选项所需的关键字子集：
+// type、properties、required、additionalProperties、items、enum、default、
+// minimum/maximum、minLength/maxLength、pattern、format(uri) 与 writeOnly（凭据字段，保存前加密）
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseSchema 解析并编译 schema，pattern 在解析时预编译
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema %s: invalid pattern: %w", displayPath(path), err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if err := prop.compile(joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// ValidationError 单个选项的校验失败
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors 一次校验发现的全部问题
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = displayPath(v.Path) + ": " + v.Message
+	}
+	return "invalid scan options: " + strings.Join(msgs, "; ")
+}
+
+// Validate 按 schema 校验选项
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate(value, true)
+}
+
+// ValidatePartial 校验选项但不检查顶层必填项，用于保存只提供部分选项的 profile
+func (s *Schema) ValidatePartial(value interface{}) error {
+	return s.validate(value, false)
+}
+
+func (s *Schema) validate(value interface{}, required bool) error {
+	var errs ValidationErrors
+	s.check(value, "", required, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *Schema) check(value interface{}, path string, required bool, errs *ValidationErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		fail("expected %s, got %s", s.Type, typeName(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %v", s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required {
+			for _, name := range s.Required {
+				if _, ok := v[name]; !ok {
+					*errs = append(*errs, ValidationError{Path: joinPath(path, name), Message: "is required"})
+				}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, ValidationError{Path: joinPath(path, name), Message: "is not allowed"})
+				}
+				continue
+			}
+			prop.check(v[name], joinPath(path, name), true, errs)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.check(item, fmt.Sprintf("%s[%d]", path, i), true, errs)
+			}
+		}
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %s", s.Pattern)
+		}
+		if s.Format == "uri" {
+			if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+				fail("must be an absolute URI")
+			}
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				fail("must be >= %v", *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				fail("must be <= %v", *s.Maximum)
+			}
+		}
+	}
+}
+
+// ApplyDefaults 返回补全了 schema 默认值的选项副本，已有的值保持不变
+func (s *Schema) ApplyDefaults(options map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(options)+len(s.Properties))
+	for k, v := range options {
+		out[k] = v
+	}
+	for name, prop := range s.Properties {
+		current, exists := out[name]
+		switch {
+		case !exists && prop.Default != nil:
+			out[name] = prop.Default
+		case prop.Type == "object" && len(prop.Properties) > 0:
+			// 有必填字段的对象由调用方决定是否提供，不因默认值而凭空生成
+			if !exists && len(prop.Required) == 0 {
+				if filled := prop.ApplyDefaults(nil); len(filled) > 0 {
+					out[name] = filled
+				}
+			} else if nested, ok := current.(map[string]interface{}); ok {
+				out[name] = prop.ApplyDefaults(nested)
+			}
+		}
+	}
+	return out
+}
+
+func matchesType(want string, value interface{}) bool {
+	switch want {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == float64(int64(n))
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat 兼容 JSON 解码得到的 float64 与 Go 代码中直接构造的整数
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+		if a, ok := toFloat(e); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "options"
+	}
+	return path
+}
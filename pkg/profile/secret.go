@@ -0,0 +1,141 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+)
+
+// ErrUnscopedSecret 凭据没有可绑定的扫描目标
+var ErrUnscopedSecret = errors.New("credentials require a scan target to bind to")
+
+// CredentialScope 返回选项中凭据绑定的扫描目标：DAST 与黑盒测试为入口 origin，主机安全检查为 SSH 主机
+func CredentialScope(scanType domain.ScanType, options map[string]interface{}) string {
+	str := func(name string) string {
+		s, _ := options[name].(string)
+		return s
+	}
+	switch scanType {
+	case domain.ScanTypeDast, domain.ScanTypeBlackBoxTesting:
+		return domain.DASTCredentialScope(str("target_url"), str("domain_name"))
+	case domain.ScanTypeHostSecurityCheck:
+		return domain.HostCredentialScope(str("ip_address"))
+	}
+	return ""
+}
+
+// credentialScopeField 决定凭据绑定目标的选项，用于校验错误的路径
+func credentialScopeField(scanType domain.ScanType) string {
+	if scanType == domain.ScanTypeHostSecurityCheck {
+		return "ip_address"
+	}
+	return "target_url"
+}
+
+// ProfileBinding profile 中保存的凭据绑定到 profile 名称与扫描目标，
+// 其它 profile 或任务复制密文后无法解密；没有扫描目标时返回空，加密时报错
+func ProfileBinding(name, scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return "profile:" + name + "|" + scope
+}
+
+// HasSealed 判断选项中是否含有密文
+func HasSealed(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			if HasSealed(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if HasSealed(item) {
+				return true
+			}
+		}
+	case string:
+		return crypt.IsSealed(v)
+	}
+	return false
+}
+
+// SealSecrets 返回 writeOnly 字段以 binding 加密后的选项副本，已加密的值视为客户端提交的密文而拒绝
+// profile 与任务选项会落库并经消息队列下发，凭据只以密文形式出现，由执行器在同一目标上解密
+func (s *Schema) SealSecrets(options map[string]interface{}, km *crypt.KeyManager, binding string) (map[string]interface{}, error) {
+	return s.ResealSecrets(options, km, "", binding)
+}
+
+// ResealSecrets 与 SealSecrets 相同，但接受服务端以 from 加密的密文：解密后以 to 重新加密。
+// 密文无法以 from 解密（来自其它 profile、目标或客户端伪造）时报错
+func (s *Schema) ResealSecrets(options map[string]interface{}, km *crypt.KeyManager, from, to string) (map[string]interface{}, error) {
+	reseal := func(v string) (string, error) {
+		if to == "" {
+			return "", ErrUnscopedSecret
+		}
+		if crypt.IsSealed(v) {
+			if from == "" {
+				return "", crypt.ErrAlreadySealed
+			}
+			plaintext, err := km.OpenStringBound(v, from)
+			if err != nil {
+				return "", err
+			}
+			v = plaintext
+		}
+		return km.SealStringBound(v, to)
+	}
+	sealed, err := s.seal(options, "", reseal)
+	if err != nil {
+		return nil, err
+	}
+	out, _ := sealed.(map[string]interface{})
+	return out, nil
+}
+
+func (s *Schema) seal(value interface{}, path string, reseal func(string) (string, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			out[name] = item
+			prop, ok := s.Properties[name]
+			if !ok {
+				continue
+			}
+			sealed, err := prop.seal(item, joinPath(path, name), reseal)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = sealed
+		}
+		return out, nil
+	case []interface{}:
+		if s.Items == nil {
+			return v, nil
+		}
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			sealed, err := s.Items.seal(item, fmt.Sprintf("%s[%d]", path, i), reseal)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = sealed
+		}
+		return out, nil
+	case string:
+		if !s.WriteOnly || v == "" {
+			return v, nil
+		}
+		sealed, err := reseal(v)
+		if err != nil {
+			return nil, fmt.Errorf("seal %s: %w", displayPath(path), err)
+		}
+		return sealed, nil
+	}
+	return value, nil
+}
@@ -1,9 +1,11 @@
 package scanner
 
 import (
+	"errors"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
 )
 
 // ErrorType 定义错误类型
@@ -14,112 +16,293 @@ const (
 	CriticalError                   // 严重错误，如系统错误
 )
 
+// ErrCircuitOpen 熔断器打开或半开试探名额已满时拒绝执行
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// 熔断器配置缺省值，配置为0时使用
+const (
+	defaultBreakerThreshold           = 5
+	defaultBreakerCriticalThreshold   = 3
+	defaultBreakerResetTimeout        = time.Minute
+	defaultBreakerHalfOpenMaxRequests = 1
+	defaultBreakerIdleTTL             = 30 * time.Minute
+)
+
+// CircuitBreakerSettings 熔断器阈值配置
+type CircuitBreakerSettings struct {
+	Threshold           uint32        // 连续失败（临时+严重）达到此值时熔断
+	CriticalThreshold   uint32        // 连续严重错误达到此值时熔断
+	ResetTimeout        time.Duration // 熔断后经过此时间进入半开状态
+	HalfOpenMaxRequests uint32        // 半开状态允许并发的试探请求数，全部成功后关闭
+	IdleTTL             time.Duration // 按主机的熔断器关闭且空闲超过此时间后被回收
+}
+
+// withDefaults 为未配置的字段填充缺省值
+func (s CircuitBreakerSettings) withDefaults() CircuitBreakerSettings {
+	if s.Threshold == 0 {
+		s.Threshold = defaultBreakerThreshold
+	}
+	if s.CriticalThreshold == 0 {
+		s.CriticalThreshold = defaultBreakerCriticalThreshold
+	}
+	if s.ResetTimeout <= 0 {
+		s.ResetTimeout = defaultBreakerResetTimeout
+	}
+	if s.HalfOpenMaxRequests == 0 {
+		s.HalfOpenMaxRequests = defaultBreakerHalfOpenMaxRequests
+	}
+	if s.IdleTTL <= 0 {
+		s.IdleTTL = defaultBreakerIdleTTL
+	}
+	return s
+}
+
+// CircuitBreakerStateChange 熔断器状态变化事件
+type CircuitBreakerStateChange struct {
+	ScanType domain.ScanType
+	Host     string // 按目标主机划分的熔断器，扫描类型级别时为空
+	From     CircuitBreakerState
+	To       CircuitBreakerState
+	At       time.Time
+}
+
 // CircuitBreaker implements the circuit breaker pattern
+//
+// 状态机：Closed 连续失败达到阈值 -> Open；Open 经过 resetTimeout -> HalfOpen；
+// HalfOpen 放行有限个试探请求，任一失败 -> Open，全部成功 -> Closed。
+// 所有方法对 nil 接收者安全，nil 熔断器始终放行。
 type CircuitBreaker struct {
-	transientFailures uint32        // 临时错误计数，如网络超时、临时连接失败等
-	criticalFailures  uint32        // 严重错误计数，如系统错误、权限错误等
-	lastFailure       time.Time     // 最后一次失败的时间戳
-	threshold         uint32        // 总错误阈值，超过此值将触发熔断
-	criticalThreshold uint32        // 严重错误阈值，超过此值将立即触发熔断
-	resetTimeout      time.Duration // 熔断器重置超时时间，超过此时间后尝试恢复
-	mu                sync.RWMutex  // 并发控制锁
+	settings          CircuitBreakerSettings
+	scanType          domain.ScanType
+	host              string
+	state             CircuitBreakerState
+	transientFailures uint32    // 临时错误计数，如网络超时、临时连接失败等
+	criticalFailures  uint32    // 严重错误计数，如系统错误、权限错误等
+	lastFailure       time.Time // 最后一次失败的时间戳
+	openedAt          time.Time // 最近一次进入 Open 的时间
+	halfOpenInFlight  uint32    // 半开状态下已放行且未结束的试探请求
+	halfOpenSuccesses uint32    // 半开状态下成功的试探请求
+	inFlight          uint32    // 已放行且未结束的请求
+	lastUsed          time.Time // 最近一次放行或结束请求的时间，用于回收空闲的主机熔断器
+	onStateChange     func(CircuitBreakerStateChange)
+	mu                sync.Mutex // 并发控制锁
 }
 
 // NewCircuitBreaker creates a new circuit breaker
 // threshold: 总错误阈值，当 transientFailures + criticalFailures >= threshold 时触发熔断
 // criticalThreshold: 严重错误阈值，当 criticalFailures >= criticalThreshold 时立即触发熔断
-// resetTimeout: 熔断器重置超时时间，超过此时间后尝试恢复服务
+// resetTimeout: 熔断器重置超时时间，超过此时间后进入半开状态试探恢复
 func NewCircuitBreaker(threshold, criticalThreshold uint32, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		threshold:         threshold,
-		criticalThreshold: criticalThreshold,
-		resetTimeout:      resetTimeout,
+	return NewCircuitBreakerWithSettings(CircuitBreakerSettings{
+		Threshold:         threshold,
+		CriticalThreshold: criticalThreshold,
+		ResetTimeout:      resetTimeout,
+	})
+}
+
+// NewCircuitBreakerWithSettings 按配置创建熔断器，未配置的字段使用缺省值
+func NewCircuitBreakerWithSettings(settings CircuitBreakerSettings) *CircuitBreaker {
+	return &CircuitBreaker{settings: settings.withDefaults(), lastUsed: time.Now()}
+}
+
+// Allow 申请执行一次请求：Closed 时放行；Open 时拒绝；HalfOpen 时占用一个试探名额，
+// 名额用尽时拒绝。放行后必须以 RecordSuccess、RecordFailure 或 Release 之一结束
+func (cb *CircuitBreaker) Allow() error {
+	if cb == nil {
+		return nil
 	}
+	cb.mu.Lock()
+	now := time.Now()
+	event := cb.advance(now)
+	var err error
+	switch cb.state {
+	case StateOpen:
+		err = ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.halfOpenInFlight+cb.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests {
+			err = ErrCircuitOpen
+		} else {
+			cb.halfOpenInFlight++
+		}
+	}
+	if err == nil {
+		cb.inFlight++
+		cb.lastUsed = now
+	}
+	cb.mu.Unlock()
+	cb.notify(event)
+	return err
 }
 
-// IsOpen checks if the circuit breaker is open
+// IsOpen checks if the circuit breaker rejects new requests, it does not reserve a trial
 func (cb *CircuitBreaker) IsOpen() bool {
-	/**
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
-
-	// 检查严重错误是否超过阈值
-	if atomic.LoadUint32(&cb.criticalFailures) >= cb.criticalThreshold {
-		return true
-	}
-
-	// 检查总错误数是否超过阈值
-	totalFailures := atomic.LoadUint32(&cb.transientFailures) + atomic.LoadUint32(&cb.criticalFailures)
-	if totalFailures >= cb.threshold {
-		// 检查重置超时是否已过
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			cb.reset()
-			return false
-		}
-		return true
-	}*/
-	return false
+	if cb == nil {
+		return false
+	}
+	cb.mu.Lock()
+	event := cb.advance(time.Now())
+	open := cb.state == StateOpen ||
+		(cb.state == StateHalfOpen && cb.halfOpenInFlight+cb.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests)
+	cb.mu.Unlock()
+	cb.notify(event)
+	return open
 }
 
 // RecordFailure records a failure and potentially opens the circuit
 func (cb *CircuitBreaker) RecordFailure(errType ErrorType) {
+	if cb == nil {
+		return
+	}
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
+	now := time.Now()
+	event := cb.advance(now)
+	cb.finishRequest(now)
+	cb.lastFailure = now
 	switch errType {
-	case TransientError:
-		atomic.AddUint32(&cb.transientFailures, 1)
 	case CriticalError:
-		atomic.AddUint32(&cb.criticalFailures, 1)
-		cb.lastFailure = time.Now()
+		cb.criticalFailures++
+	default:
+		cb.transientFailures++
+	}
+
+	switch cb.state {
+	case StateHalfOpen:
+		// 试探失败，重新熔断
+		event = cb.transition(StateOpen, now)
+	case StateClosed:
+		if cb.criticalFailures >= cb.settings.CriticalThreshold ||
+			cb.transientFailures+cb.criticalFailures >= cb.settings.Threshold {
+			event = cb.transition(StateOpen, now)
+		}
 	}
+	cb.mu.Unlock()
+	cb.notify(event)
 }
 
 // RecordSuccess records a success and potentially closes the circuit
 func (cb *CircuitBreaker) RecordSuccess() {
+	if cb == nil {
+		return
+	}
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	atomic.StoreUint32(&cb.transientFailures, 0)
-	atomic.StoreUint32(&cb.criticalFailures, 0)
+	now := time.Now()
+	event := cb.advance(now)
+	cb.finishRequest(now)
+	switch cb.state {
+	case StateHalfOpen:
+		cb.releaseTrial()
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests {
+			event = cb.transition(StateClosed, now)
+		}
+	case StateClosed:
+		cb.transientFailures = 0
+		cb.criticalFailures = 0
+	}
+	cb.mu.Unlock()
+	cb.notify(event)
 }
 
-// reset resets the circuit breaker state
-func (cb *CircuitBreaker) reset() {
-	atomic.StoreUint32(&cb.transientFailures, 0)
-	atomic.StoreUint32(&cb.criticalFailures, 0)
-	cb.lastFailure = time.Time{}
+// Release 结束一次不计入熔断的请求（如用户取消），归还半开状态的试探名额
+func (cb *CircuitBreaker) Release() {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	cb.finishRequest(time.Now())
+	if cb.state == StateHalfOpen {
+		cb.releaseTrial()
+	}
+	cb.mu.Unlock()
 }
 
 // GetFailureCount returns the current failure counts
 func (cb *CircuitBreaker) GetFailureCount() (transient, critical uint32) {
-	return atomic.LoadUint32(&cb.transientFailures), atomic.LoadUint32(&cb.criticalFailures)
+	if cb == nil {
+		return 0, 0
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.transientFailures, cb.criticalFailures
 }
 
 // GetLastFailureTime returns the time of the last failure
 func (cb *CircuitBreaker) GetLastFailureTime() time.Time {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	if cb == nil {
+		return time.Time{}
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
 	return cb.lastFailure
 }
 
 // GetState returns the current state of the circuit breaker
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	if cb == nil {
+		return StateClosed
+	}
+	cb.mu.Lock()
+	event := cb.advance(time.Now())
+	state := cb.state
+	cb.mu.Unlock()
+	cb.notify(event)
+	return state
+}
 
-	if atomic.LoadUint32(&cb.criticalFailures) >= cb.criticalThreshold {
-		return StateOpen
+// advance 熔断超过 resetTimeout 后进入半开状态，调用方需持有锁
+func (cb *CircuitBreaker) advance(now time.Time) *CircuitBreakerStateChange {
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.settings.ResetTimeout {
+		return cb.transition(StateHalfOpen, now)
 	}
+	return nil
+}
 
-	totalFailures := atomic.LoadUint32(&cb.transientFailures) + atomic.LoadUint32(&cb.criticalFailures)
-	if totalFailures >= cb.threshold {
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			return StateHalfOpen
-		}
-		return StateOpen
+// transition 切换状态并重置对应计数，返回待通知的事件，调用方需持有锁
+func (cb *CircuitBreaker) transition(to CircuitBreakerState, now time.Time) *CircuitBreakerStateChange {
+	from := cb.state
+	cb.state = to
+	cb.halfOpenInFlight = 0
+	cb.halfOpenSuccesses = 0
+	switch to {
+	case StateOpen:
+		cb.openedAt = now
+	case StateClosed:
+		cb.transientFailures = 0
+		cb.criticalFailures = 0
+	}
+	if from == to {
+		return nil
+	}
+	return &CircuitBreakerStateChange{ScanType: cb.scanType, Host: cb.host, From: from, To: to, At: now}
+}
+
+// finishRequest 结束一个已放行的请求，调用方需持有锁
+func (cb *CircuitBreaker) finishRequest(now time.Time) {
+	if cb.inFlight > 0 {
+		cb.inFlight--
+	}
+	cb.lastUsed = now
+}
+
+// idle 关闭状态、没有进行中的请求且空闲超过 IdleTTL 时可被回收
+func (cb *CircuitBreaker) idle(now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state == StateClosed && cb.inFlight == 0 && now.Sub(cb.lastUsed) >= cb.settings.IdleTTL
+}
+
+// releaseTrial 归还一个试探名额，调用方需持有锁
+func (cb *CircuitBreaker) releaseTrial() {
+	if cb.halfOpenInFlight > 0 {
+		cb.halfOpenInFlight--
+	}
+}
+
+// notify 在锁外回调状态变化
+func (cb *CircuitBreaker) notify(event *CircuitBreakerStateChange) {
+	if event != nil && cb.onStateChange != nil {
+		cb.onStateChange(*event)
 	}
-	return StateClosed
 }
 
 // CircuitBreakerState represents the state of the circuit breaker
@@ -144,3 +327,113 @@ func (s CircuitBreakerState) String() string {
 		return "UNKNOWN"
 	}
 }
+
+// CircuitBreakerSettingsFunc 返回扫描类型（及目标主机）的熔断器配置，host 为空表示扫描类型级别
+type CircuitBreakerSettingsFunc func(scanType domain.ScanType, host string) CircuitBreakerSettings
+
+// circuitBreakerKey 熔断器登记键
+type circuitBreakerKey struct {
+	scanType domain.ScanType
+	host     string
+}
+
+// CircuitBreakerRegistry 按扫描类型（DAST 可再按目标主机）隔离的熔断器集合，
+// 某一类扫描工具异常只会熔断该类型，不影响其他扫描；关闭且空闲超过 IdleTTL 的主机熔断器在创建新主机熔断器时回收
+type CircuitBreakerRegistry struct {
+	settings       CircuitBreakerSettingsFunc
+	breakers       map[circuitBreakerKey]*CircuitBreaker
+	listeners      []func(CircuitBreakerStateChange)
+	evictListeners []func(scanType domain.ScanType, host string)
+	mu             sync.RWMutex
+}
+
+// NewCircuitBreakerRegistry 创建熔断器登记表，settings 为 nil 时全部使用缺省阈值
+func NewCircuitBreakerRegistry(settings CircuitBreakerSettingsFunc) *CircuitBreakerRegistry {
+	if settings == nil {
+		settings = func(domain.ScanType, string) CircuitBreakerSettings { return CircuitBreakerSettings{} }
+	}
+	return &CircuitBreakerRegistry{
+		settings: settings,
+		breakers: make(map[circuitBreakerKey]*CircuitBreaker),
+	}
+}
+
+// Get 返回扫描类型的熔断器，首次访问时按配置创建
+func (r *CircuitBreakerRegistry) Get(scanType domain.ScanType) *CircuitBreaker {
+	return r.ForHost(scanType, "")
+}
+
+// ForHost 返回扫描类型下某个目标主机的熔断器，host 为空时等同于 Get
+// nil 登记表返回 nil 熔断器，即不熔断
+func (r *CircuitBreakerRegistry) ForHost(scanType domain.ScanType, host string) *CircuitBreaker {
+	if r == nil {
+		return nil
+	}
+	key := circuitBreakerKey{scanType: scanType, host: host}
+	r.mu.RLock()
+	cb, ok := r.breakers[key]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	if cb, ok := r.breakers[key]; ok {
+		r.mu.Unlock()
+		return cb
+	}
+	var evicted []circuitBreakerKey
+	if host != "" {
+		evicted = r.evictIdleLocked(time.Now())
+	}
+	cb = NewCircuitBreakerWithSettings(r.settings(scanType, host))
+	cb.scanType = scanType
+	cb.host = host
+	cb.onStateChange = r.dispatch
+	r.breakers[key] = cb
+	evictListeners := append([]func(domain.ScanType, string){}, r.evictListeners...)
+	r.mu.Unlock()
+
+	for _, k := range evicted {
+		for _, fn := range evictListeners {
+			fn(k.scanType, k.host)
+		}
+	}
+	return cb
+}
+
+// evictIdleLocked 回收空闲的主机熔断器，扫描类型级别的熔断器始终保留，调用方需持有写锁
+func (r *CircuitBreakerRegistry) evictIdleLocked(now time.Time) []circuitBreakerKey {
+	var evicted []circuitBreakerKey
+	for key, cb := range r.breakers {
+		if key.host != "" && cb.idle(now) {
+			delete(r.breakers, key)
+			evicted = append(evicted, key)
+		}
+	}
+	return evicted
+}
+
+// OnEvict 订阅主机熔断器的回收，用于清理按主机的指标序列
+func (r *CircuitBreakerRegistry) OnEvict(fn func(scanType domain.ScanType, host string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictListeners = append(r.evictListeners, fn)
+}
+
+// OnStateChange 订阅所有熔断器的状态变化，回调在状态变化的调用方协程中同步执行
+func (r *CircuitBreakerRegistry) OnStateChange(fn func(CircuitBreakerStateChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// dispatch 将状态变化分发给订阅者
+func (r *CircuitBreakerRegistry) dispatch(event CircuitBreakerStateChange) {
+	r.mu.RLock()
+	listeners := append([]func(CircuitBreakerStateChange){}, r.listeners...)
+	r.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
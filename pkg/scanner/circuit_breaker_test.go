@@ -0,0 +1,146 @@
+package scanner_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_HalfOpenProbing(t *testing.T) {
+	cb := scanner.NewCircuitBreakerWithSettings(scanner.CircuitBreakerSettings{
+		Threshold:           2,
+		CriticalThreshold:   5,
+		ResetTimeout:        20 * time.Millisecond,
+		HalfOpenMaxRequests: 2,
+	})
+
+	// 成功清零连续失败计数
+	require.NoError(t, cb.Allow())
+	cb.RecordFailure(scanner.TransientError)
+	cb.RecordSuccess()
+	cb.RecordFailure(scanner.TransientError)
+	assert.Equal(t, scanner.StateClosed, cb.GetState())
+
+	cb.RecordFailure(scanner.TransientError)
+	assert.Equal(t, scanner.StateOpen, cb.GetState())
+	assert.True(t, cb.IsOpen())
+	assert.True(t, errors.Is(cb.Allow(), scanner.ErrCircuitOpen))
+
+	// 超过 resetTimeout 后半开，只放行两个试探请求
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, cb.IsOpen())
+	require.NoError(t, cb.Allow())
+	require.NoError(t, cb.Allow())
+	assert.Equal(t, scanner.StateHalfOpen, cb.GetState())
+	assert.ErrorIs(t, cb.Allow(), scanner.ErrCircuitOpen)
+
+	// 取消的试探归还名额
+	cb.Release()
+	require.NoError(t, cb.Allow())
+
+	// 试探失败重新熔断
+	cb.RecordFailure(scanner.TransientError)
+	assert.Equal(t, scanner.StateOpen, cb.GetState())
+
+	// 全部试探成功后关闭
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, cb.Allow())
+	require.NoError(t, cb.Allow())
+	cb.RecordSuccess()
+	assert.Equal(t, scanner.StateHalfOpen, cb.GetState())
+	cb.RecordSuccess()
+	assert.Equal(t, scanner.StateClosed, cb.GetState())
+	transient, critical := cb.GetFailureCount()
+	assert.Zero(t, transient+critical)
+}
+
+func TestCircuitBreaker_CriticalThreshold(t *testing.T) {
+	cb := scanner.NewCircuitBreaker(10, 1, time.Minute)
+	cb.RecordFailure(scanner.CriticalError)
+	assert.True(t, cb.IsOpen())
+
+	var nilBreaker *scanner.CircuitBreaker
+	assert.NoError(t, nilBreaker.Allow())
+	assert.False(t, nilBreaker.IsOpen())
+}
+
+func TestCircuitBreakerRegistry_Isolation(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanner.CircuitBreaker.Threshold = 3
+	cfg.Scanner.CircuitBreaker.ResetTimeout = time.Minute
+	cfg.Scanner.CircuitBreaker.Overrides = map[string]config.CircuitBreakerConfig{
+		"dast": {Threshold: 1},
+	}
+	cfg.Scanner.CircuitBreaker.DASTHost = config.CircuitBreakerConfig{Threshold: 2}
+
+	registry := scanner.NewCircuitBreakerRegistry(cfg.GetCircuitBreakerSettings)
+	var mu sync.Mutex
+	var events []scanner.CircuitBreakerStateChange
+	registry.OnStateChange(func(e scanner.CircuitBreakerStateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	// DAST 熔断不影响 SAST/SCA
+	dast := registry.Get(domain.ScanTypeDast)
+	assert.Same(t, dast, registry.Get(domain.ScanTypeDast))
+	dast.RecordFailure(scanner.TransientError)
+	assert.True(t, dast.IsOpen())
+	assert.False(t, registry.Get(domain.ScanTypeStaticCodeAnalysis).IsOpen())
+	assert.False(t, registry.Get(domain.ScanTypeSca).IsOpen())
+
+	// 按主机的熔断器相互独立，阈值来自 dast_host
+	host := registry.ForHost(domain.ScanTypeDast, "a.example.com")
+	assert.NotSame(t, dast, host)
+	host.RecordFailure(scanner.TransientError)
+	assert.False(t, host.IsOpen())
+	host.RecordFailure(scanner.TransientError)
+	assert.True(t, host.IsOpen())
+	assert.False(t, registry.ForHost(domain.ScanTypeDast, "b.example.com").IsOpen())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+	assert.Equal(t, scanner.CircuitBreakerStateChange{
+		ScanType: domain.ScanTypeDast, From: scanner.StateClosed, To: scanner.StateOpen, At: events[0].At,
+	}, events[0])
+	assert.Equal(t, "a.example.com", events[1].Host)
+}
+
+func TestCircuitBreakerRegistry_EvictsIdleHosts(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanner.CircuitBreaker.DASTHost = config.CircuitBreakerConfig{Threshold: 1, ResetTimeout: time.Minute, IdleTTL: 10 * time.Millisecond}
+	registry := scanner.NewCircuitBreakerRegistry(cfg.GetCircuitBreakerSettings)
+	var evicted []string
+	registry.OnEvict(func(scanType domain.ScanType, host string) {
+		assert.Equal(t, domain.ScanTypeDast, scanType)
+		evicted = append(evicted, host)
+	})
+
+	dast := registry.Get(domain.ScanTypeDast)
+	idle := registry.ForHost(domain.ScanTypeDast, "idle.example.com")
+	require.NoError(t, idle.Allow())
+	idle.RecordSuccess()
+	open := registry.ForHost(domain.ScanTypeDast, "down.example.com")
+	open.RecordFailure(scanner.TransientError)
+	require.True(t, open.IsOpen())
+	running := registry.ForHost(domain.ScanTypeDast, "busy.example.com")
+	require.NoError(t, running.Allow())
+
+	// 只回收关闭且没有进行中请求的主机熔断器，扫描类型级别的熔断器始终保留
+	time.Sleep(20 * time.Millisecond)
+	registry.ForHost(domain.ScanTypeDast, "new.example.com")
+	assert.Equal(t, []string{"idle.example.com"}, evicted)
+	assert.NotSame(t, idle, registry.ForHost(domain.ScanTypeDast, "idle.example.com"))
+	assert.Same(t, open, registry.ForHost(domain.ScanTypeDast, "down.example.com"))
+	assert.Same(t, running, registry.ForHost(domain.ScanTypeDast, "busy.example.com"))
+	assert.Same(t, dast, registry.Get(domain.ScanTypeDast))
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/domain"
@@ -33,6 +34,27 @@ type TaskExecutor interface {
 	HealthCheck() error
 }
 
+// ErrExecutionNotFound 执行句柄不存在或已过期
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ExecutionInfo 单次执行的状态与进度时间戳，句柄即任务ID
+type ExecutionInfo struct {
+	Handle     string            `json:"handle"`
+	ScanType   domain.ScanType   `json:"scan_type"`
+	Status     domain.TaskStatus `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  time.Time         `json:"started_at,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"` // 最近一次状态变化或子进程启停
+	FinishedAt time.Time         `json:"finished_at,omitempty"`
+	Processes  int               `json:"processes"` // 正在运行的子进程数
+	Error      string            `json:"error,omitempty"`
+}
+
+// ExecutionTracker 可查询执行详情的执行器
+type ExecutionTracker interface {
+	GetExecution(handle string) (ExecutionInfo, error)
+}
+
 // ContainerConfig represents production-level container configuration
 type ContainerConfig struct {
 	DefaultImage      string
@@ -1,8 +1,11 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/domain"
 	"github.com/blackarbiter/go-sac/pkg/logger"
@@ -13,31 +16,79 @@ import (
 // ScannerFactory defines the interface for scanner factory
 type ScannerFactory interface {
 	RegisterExecutor(scanType domain.ScanType, executor TaskExecutor)
+	UnregisterExecutor(scanType domain.ScanType)
 	GetScanner(scanType domain.ScanType) (TaskExecutor, error)
 	GetMetrics() *metrics.ScannerMetrics
-	GetCircuitBreaker() *CircuitBreaker
+	GetCircuitBreakers() *CircuitBreakerRegistry
 	ListSupportedTypes() []domain.ScanType
 	HealthCheck() error
 	Close() error
 	GetAllScanners() map[domain.ScanType]TaskExecutor
 }
 
+// PluginSource 外部插件来源，Discover 返回当前可用的插件执行器，
+// 执行器按 Meta().SupportedTypes 注册，未变化的插件应返回同一实例
+type PluginSource interface {
+	Discover(ctx context.Context) ([]TaskExecutor, error)
+}
+
 // ScannerFactoryImpl creates and manages scanner instances
 type ScannerFactoryImpl struct {
-	scanners       map[domain.ScanType]TaskExecutor
-	metrics        *metrics.ScannerMetrics
-	circuitBreaker *CircuitBreaker
-	logger         *zap.Logger
-	mu             sync.RWMutex
+	scanners    map[domain.ScanType]TaskExecutor
+	plugins     map[domain.ScanType]TaskExecutor // 由插件注册的扫描类型
+	metrics     *metrics.ScannerMetrics
+	breakers    *CircuitBreakerRegistry // 按扫描类型隔离的熔断器
+	logger      *zap.Logger
+	mu          sync.RWMutex
+	stopPlugins context.CancelFunc
 }
 
 // NewScannerFactory creates a new scanner factory
-func NewScannerFactory(createScanners func() map[domain.ScanType]TaskExecutor, metrics *metrics.ScannerMetrics, circuitBreaker *CircuitBreaker) *ScannerFactoryImpl {
-	return &ScannerFactoryImpl{
-		scanners:       createScanners(),
-		metrics:        metrics,
-		circuitBreaker: circuitBreaker,
-		logger:         logger.Logger,
+// breakers 应与扫描器共用（WithCircuitBreakerRegistry），为 nil 时使用缺省阈值的独立登记表
+func NewScannerFactory(createScanners func() map[domain.ScanType]TaskExecutor, metrics *metrics.ScannerMetrics, breakers *CircuitBreakerRegistry) *ScannerFactoryImpl {
+	if breakers == nil {
+		breakers = NewCircuitBreakerRegistry(nil)
+	}
+	f := &ScannerFactoryImpl{
+		scanners: createScanners(),
+		plugins:  make(map[domain.ScanType]TaskExecutor),
+		metrics:  metrics,
+		breakers: breakers,
+		logger:   logger.Logger,
+	}
+	breakers.OnStateChange(f.onBreakerStateChange)
+	breakers.OnEvict(f.onBreakerEvicted)
+	for scanType := range f.scanners {
+		f.recordBreakerState(scanType, "", breakers.Get(scanType).GetState())
+	}
+	return f
+}
+
+// onBreakerStateChange 记录熔断器状态变化日志和指标
+func (f *ScannerFactoryImpl) onBreakerStateChange(event CircuitBreakerStateChange) {
+	f.logger.Warn("circuit breaker state changed",
+		zap.String("scan_type", event.ScanType.String()),
+		zap.String("host", event.Host),
+		zap.String("from", event.From.String()),
+		zap.String("to", event.To.String()),
+	)
+	if f.metrics != nil {
+		f.metrics.RecordCircuitBreakerTransition(event.ScanType, event.From.String(), event.To.String())
+	}
+	f.recordBreakerState(event.ScanType, event.Host, event.To)
+}
+
+// onBreakerEvicted 删除已回收的主机熔断器的状态指标
+func (f *ScannerFactoryImpl) onBreakerEvicted(scanType domain.ScanType, host string) {
+	if f.metrics != nil {
+		f.metrics.DeleteCircuitBreakerState(scanType, host)
+	}
+}
+
+// recordBreakerState 更新熔断器状态指标
+func (f *ScannerFactoryImpl) recordBreakerState(scanType domain.ScanType, host string, state CircuitBreakerState) {
+	if f.metrics != nil {
+		f.metrics.SetCircuitBreakerState(scanType, host, float64(state))
 	}
 }
 
@@ -46,13 +97,140 @@ func (f *ScannerFactoryImpl) RegisterExecutor(scanType domain.ScanType, executor
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
+	f.registerLocked(scanType, executor)
+}
+
+func (f *ScannerFactoryImpl) registerLocked(scanType domain.ScanType, executor TaskExecutor) {
 	f.scanners[scanType] = executor
+	f.recordBreakerState(scanType, "", f.breakers.Get(scanType).GetState())
 	f.logger.Info("registered executor",
-		zap.String("scan_type", string(scanType)),
+		zap.String("scan_type", scanType.String()),
 		zap.String("executor_type", executor.Meta().Type),
 	)
 }
 
+// UnregisterExecutor removes the executor of a scan type
+func (f *ScannerFactoryImpl) UnregisterExecutor(scanType domain.ScanType) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.unregisterLocked(scanType)
+}
+
+func (f *ScannerFactoryImpl) unregisterLocked(scanType domain.ScanType) {
+	delete(f.scanners, scanType)
+	delete(f.plugins, scanType)
+	f.logger.Info("unregistered executor", zap.String("scan_type", scanType.String()))
+}
+
+// SyncPlugins 发现插件并健康检查，热注册新的或变化的插件，注销已删除或不健康的插件；
+// 内置扫描器优先，插件不能覆盖。注册状态在同一把锁内判断和修改，被替换或注销的插件执行器实现 io.Closer 时关闭
+func (f *ScannerFactoryImpl) SyncPlugins(ctx context.Context, source PluginSource) error {
+	executors, err := source.Discover(ctx)
+	if err != nil {
+		return fmt.Errorf("discover plugins failed: %w", err)
+	}
+
+	healthy := make(map[domain.ScanType]TaskExecutor)
+	for _, executor := range executors {
+		meta := executor.Meta()
+		if err := executor.HealthCheck(); err != nil {
+			f.logger.Warn("plugin health check failed",
+				zap.String("executor_type", meta.Type),
+				zap.String("version", meta.Version),
+				zap.Error(err))
+			continue
+		}
+		for _, scanType := range meta.SupportedTypes {
+			if other, ok := healthy[scanType]; ok {
+				f.logger.Warn("scan type claimed by multiple plugins, keeping the first",
+					zap.String("scan_type", scanType.String()),
+					zap.String("kept", other.Meta().Type),
+					zap.String("ignored", meta.Type))
+				continue
+			}
+			healthy[scanType] = executor
+		}
+	}
+
+	f.mu.Lock()
+	var retired []TaskExecutor
+	for scanType, executor := range f.plugins {
+		if _, ok := healthy[scanType]; !ok {
+			f.unregisterLocked(scanType)
+			retired = append(retired, executor)
+		}
+	}
+	for scanType, executor := range healthy {
+		current, registered := f.scanners[scanType]
+		_, isPlugin := f.plugins[scanType]
+		if registered && !isPlugin {
+			f.logger.Warn("plugin ignored, scan type served by built-in scanner",
+				zap.String("scan_type", scanType.String()),
+				zap.String("executor_type", executor.Meta().Type))
+			continue
+		}
+		if current == executor {
+			continue
+		}
+		if isPlugin {
+			retired = append(retired, current)
+		}
+		f.registerLocked(scanType, executor)
+		f.plugins[scanType] = executor
+	}
+	// 同一插件可能注册多个扫描类型，仍在使用的执行器不关闭
+	inUse := make(map[TaskExecutor]bool, len(f.plugins))
+	for _, executor := range f.plugins {
+		inUse[executor] = true
+	}
+	f.mu.Unlock()
+
+	for _, executor := range retired {
+		if inUse[executor] {
+			continue
+		}
+		inUse[executor] = true
+		if closer, ok := executor.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				f.logger.Warn("close retired plugin failed",
+					zap.String("executor_type", executor.Meta().Type),
+					zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// WatchPlugins 立即同步一次插件，之后按 interval 周期性重新发现，Close 时停止
+func (f *ScannerFactoryImpl) WatchPlugins(ctx context.Context, source PluginSource, interval time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	f.mu.Lock()
+	if f.stopPlugins != nil {
+		f.stopPlugins()
+	}
+	f.stopPlugins = cancel
+	f.mu.Unlock()
+
+	if err := f.SyncPlugins(ctx, source); err != nil {
+		f.logger.Error("plugin sync failed", zap.Error(err))
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := f.SyncPlugins(ctx, source); err != nil {
+					f.logger.Error("plugin sync failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
 // GetScanner returns a scanner for the given scan type
 func (f *ScannerFactoryImpl) GetScanner(scanType domain.ScanType) (TaskExecutor, error) {
 	f.mu.RLock()
@@ -63,13 +241,14 @@ func (f *ScannerFactoryImpl) GetScanner(scanType domain.ScanType) (TaskExecutor,
 		return nil, fmt.Errorf("no executor registered for scan type: %s", scanType)
 	}
 
-	// Check circuit breaker
-	if f.circuitBreaker.IsOpen() {
-		return nil, fmt.Errorf("circuit breaker open for scan type: %s", scanType)
+	// 仅检查该扫描类型的熔断器，试探名额由扫描器执行时申请
+	breaker := f.breakers.Get(scanType)
+	if breaker.IsOpen() {
+		return nil, fmt.Errorf("%w for scan type: %s", ErrCircuitOpen, scanType)
 	}
 
 	// 包装Executor添加监控
-	return NewMonitoredExecutor(executor, f.metrics, f.circuitBreaker, scanType), nil
+	return NewMonitoredExecutor(executor, f.metrics, breaker, scanType), nil
 }
 
 // GetMetrics returns the metrics collector
@@ -77,9 +256,9 @@ func (f *ScannerFactoryImpl) GetMetrics() *metrics.ScannerMetrics {
 	return f.metrics
 }
 
-// GetCircuitBreaker returns the circuit breaker
-func (f *ScannerFactoryImpl) GetCircuitBreaker() *CircuitBreaker {
-	return f.circuitBreaker
+// GetCircuitBreakers returns the per scan type circuit breaker registry
+func (f *ScannerFactoryImpl) GetCircuitBreakers() *CircuitBreakerRegistry {
+	return f.breakers
 }
 
 // ListSupportedTypes returns all supported scan types
@@ -103,7 +282,7 @@ func (f *ScannerFactoryImpl) HealthCheck() error {
 	for scanType, executor := range f.scanners {
 		if err := executor.HealthCheck(); err != nil {
 			f.logger.Error("executor health check failed",
-				zap.String("scan_type", string(scanType)),
+				zap.String("scan_type", scanType.String()),
 				zap.Error(err),
 			)
 			lastErr = err
@@ -117,17 +296,29 @@ func (f *ScannerFactoryImpl) Close() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
+	if f.stopPlugins != nil {
+		f.stopPlugins()
+		f.stopPlugins = nil
+	}
+
 	var lastErr error
 	for scanType, executor := range f.scanners {
 		f.logger.Info("closing executor",
-			zap.String("scan_type", string(scanType)),
+			zap.String("scan_type", scanType.String()),
 			zap.String("executor_type", executor.Meta().Type),
 		)
 	}
 	return lastErr
 }
 
-// GetAllScanners returns all scanners
+// GetAllScanners returns a snapshot of all scanners, plugins may be registered concurrently
 func (f *ScannerFactoryImpl) GetAllScanners() map[domain.ScanType]TaskExecutor {
-	return f.scanners
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	scanners := make(map[domain.ScanType]TaskExecutor, len(f.scanners))
+	for scanType, executor := range f.scanners {
+		scanners[scanType] = executor
+	}
+	return scanners
 }
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -45,17 +46,79 @@ type BaseScanner struct {
 	mu                 sync.RWMutex
 	taskStatusUpdater  TaskStatusUpdater
 	resultPublisher    ResultPublisher
-	circuitBreaker     *scanner.CircuitBreaker // 熔断器
-	defaultTimeout     time.Duration           // 默认超时时间
-	gracefulStopPeriod time.Duration           // 优雅停止时间
+	circuitBreakers    *scanner.CircuitBreakerRegistry // 熔断器登记表，与扫描器工厂共用
+	circuitBreaker     *scanner.CircuitBreaker         // 本扫描类型的熔断器
+	defaultTimeout     time.Duration                   // 默认超时时间
+	gracefulStopPeriod time.Duration                   // 优雅停止时间
 	resourceProfile    scanner.ResourceProfile
+	sandbox            config.ProcessSandboxConfig // 子进程隔离配置
+	executions         *executionRegistry          // 执行状态登记，按任务ID索引
+	outputCapture      config.OutputCaptureConfig  // 命令输出捕获配置
+	logUploader        LogUploader                 // 命令输出上传，nil 时只保留输出尾部
+	logPrefix          func(taskID string) string  // 任务输出日志的对象路径前缀
+	uploadRoot         string                      // 任务选项中本地路径允许的根目录
 }
 
 type BaseScannerOption func(*BaseScanner)
 
+// CommandOption 单次命令执行的选项
+type CommandOption func(*commandOptions)
+
+type commandOptions struct {
+	acceptedExitCodes []int // 视为成功的非零退出码，如发现问题时以 1 退出的扫描工具
+}
+
+// WithAcceptedExitCodes 指定视为成功的退出码，命中时 ExecuteCommand 返回 nil 并按成功计入熔断器
+func WithAcceptedExitCodes(codes ...int) CommandOption {
+	return func(o *commandOptions) {
+		o.acceptedExitCodes = codes
+	}
+}
+
+// accepts 判断命令的执行错误是否为可接受的退出码
+func (o *commandOptions) accepts(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	for _, code := range o.acceptedExitCodes {
+		if code == exitErr.ExitCode() {
+			return true
+		}
+	}
+	return false
+}
+
 type processManager struct {
-	activeProcesses sync.Map
+	activeProcesses sync.Map // execID -> *activeProcess
 	shutdownSignal  chan struct{}
+	signals         chan os.Signal
+	signalsStopped  chan struct{}
+	stopSignalsOnce sync.Once
+}
+
+// activeProcess 正在执行的命令，启动后登记给看门狗巡检
+type activeProcess struct {
+	cmd         *exec.Cmd
+	watch       atomic.Pointer[scanner.WatchedProcess]
+	stalled     atomic.Bool   // 看门狗发现进程组停滞并已终止
+	waited      atomic.Bool   // cmd.Wait 已返回，进程已被回收，PID 可能被复用
+	abandoned   chan struct{} // 看门狗强制终止后仍未退出，执行方不再等待
+	abandonOnce sync.Once
+}
+
+// terminate 由看门狗调用：停滞时优雅终止进程组，升级为严重超时后强制终止并放弃等待
+func (p *activeProcess) terminate(s *BaseScanner, force bool) {
+	if p.waited.Load() {
+		return
+	}
+	if !force {
+		p.stalled.Store(true)
+		s.KillProcessGroup(p.cmd, false)
+		return
+	}
+	s.KillProcessGroup(p.cmd, true)
+	p.abandonOnce.Do(func() { close(p.abandoned) })
 }
 
 type SecurityProfile struct {
@@ -69,11 +132,6 @@ type MetricsRecorder interface {
 	Gauge(name string, value float64, tags map[string]string)
 }
 
-type CgroupManager interface {
-	Apply(pid int) error
-	Cleanup() error
-}
-
 func NewBaseScanner(
 	scanType domain.ScanType,
 	timeoutCtrl *scanner.TimeoutController,
@@ -87,6 +145,7 @@ func NewBaseScanner(
 		logger:      logger.With(zap.String("scanner", scanType.String())),
 		processManager: processManager{
 			shutdownSignal: make(chan struct{}),
+			signalsStopped: make(chan struct{}),
 		},
 		meta: scanner.ExecutorMeta{
 			Type:            scanType.String(),
@@ -96,6 +155,13 @@ func NewBaseScanner(
 		},
 		defaultTimeout:     5 * time.Minute,  // 默认超时时间
 		gracefulStopPeriod: 30 * time.Second, // 默认优雅停止时间
+		executions:         newExecutionRegistry(scanType),
+	}
+	if config != nil {
+		bs.sandbox = config.GetProcessSandboxConfig()
+		bs.outputCapture = config.GetOutputCaptureConfig()
+		bs.logPrefix = config.GetScanLogPrefix
+		bs.uploadRoot = config.Scanner.UploadRoot
 	}
 
 	for _, opt := range opts {
@@ -103,11 +169,17 @@ func NewBaseScanner(
 	}
 
 	bs.setupSignalHandling()
+	// 执行中的子进程交由看门狗巡检
+	timeoutCtrl.Watchdog().AddSource(bs)
 	return bs
 }
 
 // AsyncExecuteWithResult 异步执行带结果的扫描任务
+// 返回的句柄即任务ID，可用于 Cancel/GetStatus
 func (s *BaseScanner) AsyncExecuteWithResult(ctx context.Context, task *domain.ScanTaskPayload, scanFunc func(context.Context) (*domain.ScanResult, error)) (string, error) {
+	if err := s.executions.register(task.TaskID); err != nil {
+		return "", err
+	}
 	go func() {
 		_, _ = s.ExecuteWithResult(ctx, task, scanFunc)
 	}()
@@ -119,10 +191,14 @@ func (s *BaseScanner) classifyError(err error) (string, scanner.ErrorType) {
 	switch {
 	case err == nil:
 		return "success", scanner.TransientError
+	case errors.Is(err, scanner.ErrCriticalTimeout):
+		return "critical_timeout", scanner.CriticalError
 	case errors.Is(err, context.DeadlineExceeded):
 		return "timeout", scanner.TransientError
 	case errors.Is(err, context.Canceled):
 		return "canceled", scanner.TransientError
+	case errors.Is(err, ErrOOMKilled):
+		return "oom_killed", scanner.CriticalError
 	case strings.Contains(err.Error(), "permission denied"):
 		return "permission_denied", scanner.CriticalError
 	case strings.Contains(err.Error(), "resource unavailable"):
@@ -133,117 +209,266 @@ func (s *BaseScanner) classifyError(err error) (string, scanner.ErrorType) {
 }
 
 // ExecuteCommand executes a command with proper process management and resource control
-func (s *BaseScanner) ExecuteCommand(ctx context.Context, task *domain.ScanTaskPayload, cmd *exec.Cmd, tag string) error {
-	// 检查熔断器状态
-	if s.circuitBreaker.IsOpen() {
-		return fmt.Errorf("circuit breaker is open, task rejected")
+// 健康检查命令（tag 为 healthCheck）不经过熔断器
+func (s *BaseScanner) ExecuteCommand(ctx context.Context, task *domain.ScanTaskPayload, cmd *exec.Cmd, tag string, opts ...CommandOption) (err error) {
+	var options commandOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
 
+	// 申请熔断器放行，执行结束时计入结果
+	healthCheck := strings.EqualFold("healthCheck", tag)
+	breaker := s.circuitBreaker
+	if healthCheck {
+		breaker = nil
+	}
+	finish, err := s.acquireCircuit(breaker, task.TaskID)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
 	// 1. 准备执行环境
 	execID := fmt.Sprintf("%s-%d", task.TaskID, time.Now().UnixNano())
-	if !strings.EqualFold("healthCheck", tag) {
+	if !healthCheck {
 		s.logger.Info("starting command execution",
 			zap.String("task_id", task.TaskID),
 			zap.String("exec_id", execID),
 			zap.Strings("command", cmd.Args))
 	}
 
-	// 2. 设置进程属性
-	//s.setProcessAttributes(cmd)
+	// 2. 设置进程属性，严格模式下安全配置无法满足时拒绝启动
+	cleanupSandbox, err := s.setProcessAttributes(cmd, execID)
+	if err != nil {
+		return err
+	}
+	defer cleanupSandbox()
+
+	// 捕获命令输出：尾部用于错误信息，完整输出流式上传到对象存储
+	var output *commandOutput
+	if !healthCheck {
+		output = s.captureOutput(task, cmd, execID)
+		defer s.finishOutput(task, output, execID)
+	}
 
 	// 3. 注册进程
-	s.processManager.activeProcesses.Store(execID, cmd)
+	proc := &activeProcess{cmd: cmd, abandoned: make(chan struct{})}
+	s.processManager.activeProcesses.Store(execID, proc)
 	defer func() {
 		s.processManager.activeProcesses.Delete(execID)
-		// 确保进程被清理
-		if cmd.Process != nil {
+		// 确保进程被清理；Wait 返回后进程已被回收，PID 可能被复用，不再向进程组发送信号，
+		// 残留的子进程由 cgroup 清理
+		if cmd.Process != nil && !proc.waited.Load() {
 			s.KillProcessGroup(cmd, true)
 		}
 	}()
 
-	// 4. 启动进程
+	// 4. 创建本次执行的控制组，失败时降级为不限制资源
+	var cgroup Cgroup
+	if s.cgroupManager != nil {
+		cg, err := s.cgroupManager.Create(execID, s.resourceProfile)
+		if err != nil {
+			s.logger.Warn("cgroup create failed, running without resource limits",
+				zap.String("exec_id", execID),
+				zap.Error(err))
+		} else {
+			cgroup = cg
+			// 确保cgroup资源被清理，残留进程由 cgroup.kill 一并终止
+			defer func() {
+				if err := cgroup.Cleanup(); err != nil {
+					s.logger.Error("cgroup cleanup failed", zap.String("exec_id", execID), zap.Error(err))
+				}
+			}()
+		}
+	}
+
+	// 5. 启动进程：子进程通过 CLONE_INTO_CGROUP 直接创建在控制组内，不支持时启动后再移入
+	cloned := false
+	if cgroup != nil {
+		release, ok := cloneIntoCgroup(cmd, cgroup)
+		defer release()
+		cloned = ok
+	}
 	startTime := time.Now()
-	if err := cmd.Start(); err != nil {
+	if err := s.startProcess(cmd); err != nil {
 		return fmt.Errorf("command start failed: %w", err)
 	}
-
-	// 5. 应用资源限制
-	if s.cgroupManager != nil {
-		if err := s.cgroupManager.Apply(cmd.Process.Pid); err != nil {
-			s.logger.Warn("cgroup apply failed", zap.Error(err))
+	// 关联到任务的执行登记，Cancel 时按进程组终止
+	detach := s.executions.attachProcess(task.TaskID, execID, cmd)
+	defer detach()
+	if cgroup != nil && !cloned {
+		if err := cgroup.Apply(cmd.Process.Pid); err != nil {
+			s.logger.Warn("cgroup apply failed", zap.String("exec_id", execID), zap.Error(err))
 		}
-		// 确保cgroup资源被清理
-		defer func() {
-			if err := s.cgroupManager.Cleanup(); err != nil {
-				s.logger.Error("cgroup cleanup failed", zap.Error(err))
-			}
-		}()
 	}
 
-	// 6. 创建带超时的上下文
-	timeoutCtx, cancel := context.WithTimeout(ctx, s.defaultTimeout)
-	defer cancel()
+	// 6. 按任务的分级超时监控执行，超时从任务开始执行起算
+	deadline, startedAt := s.taskDeadline(task)
+	soft := time.NewTimer(time.Until(startedAt.Add(deadline.Soft)))
+	defer soft.Stop()
+	hard := time.NewTimer(time.Until(startedAt.Add(deadline.Hard)))
+	defer hard.Stop()
+	progress := cpuProgress(cgroup)
+	proc.watch.Store(&scanner.WatchedProcess{
+		ExecID:       execID,
+		TaskID:       task.TaskID,
+		ExecutorType: s.meta.Type,
+		PID:          cmd.Process.Pid,
+		Task:         task,
+		StartedAt:    startedAt,
+		Deadline:     deadline,
+		Terminate:    func(force bool) { proc.terminate(s, force) },
+	})
 
 	// 7. 异步等待进程结束
 	done := make(chan error, 1)
 	go func() {
-		done <- cmd.Wait()
+		err := cmd.Wait()
+		proc.waited.Store(true)
+		done <- err
 		close(done)
 	}()
 
 	// 8. 处理完成、超时或取消
-	select {
-	case err := <-done:
-		execDuration := time.Since(startTime)
-		_, errType := s.classifyError(err)
-		s.recordCommandMetrics(task, cmd, err, execDuration)
-
-		if err != nil {
-			s.circuitBreaker.RecordFailure(errType)
-		} else {
-			s.circuitBreaker.RecordSuccess()
-		}
-
-		return err
-
-	case <-timeoutCtx.Done():
-		s.logger.Warn("command execution timeout",
-			zap.String("task_id", task.TaskID),
-			zap.String("exec_id", execID),
-			zap.Duration("timeout", s.defaultTimeout))
-
-		s.circuitBreaker.RecordFailure(scanner.TransientError)
-
-		// 先尝试优雅停止
-		s.KillProcessGroup(cmd, false)
+	for {
+		select {
+		case err := <-done:
+			execDuration := time.Since(startTime)
+			if err != nil && options.accepts(err) {
+				err = nil
+			}
+			stats := s.cgroupStats(cgroup)
+			if err != nil && stats != nil && stats.OOMKills > 0 {
+				err = fmt.Errorf("%w (memory limit %dMB): %w", ErrOOMKilled, s.resourceProfile.MemoryMB, err)
+			}
+			if err != nil && output != nil {
+				err = output.annotate(err)
+			}
+			if err != nil && s.executions.isCancelled(task.TaskID) {
+				err = fmt.Errorf("%w: %w", context.Canceled, err)
+			} else if err != nil && proc.stalled.Load() {
+				err = fmt.Errorf("%w: process stalled without cpu or output progress: %w", context.DeadlineExceeded, err)
+			}
+			s.recordCommandMetrics(task, cmd, err, execDuration, stats)
+			return err
 
-		// 等待进程结束或超时
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), s.gracefulStopPeriod)
-		defer cancel()
+		case <-soft.C:
+			// 软超时只检查进度，任务继续执行
+			s.handleTimeout(task, scanner.SeveritySoft, deadline, startedAt, progress, nil)
 
-		select {
-		case <-done:
-			s.logger.Info("process stopped gracefully",
+		case <-hard.C:
+			s.logger.Warn("command execution timeout",
+				zap.String("task_id", task.TaskID),
+				zap.String("exec_id", execID),
+				zap.Duration("timeout", deadline.Hard))
+
+			// 先尝试优雅停止，优雅停止期后强制终止
+			s.handleTimeout(task, scanner.SeverityHard, deadline, startedAt, nil, func() {
+				s.KillProcessGroup(cmd, false)
+			})
+			return s.awaitTermination(task, deadline, startedAt, done, func() {
+				s.logger.Warn("graceful stop timeout, forcing kill",
+					zap.String("task_id", task.TaskID),
+					zap.String("exec_id", execID))
+				s.KillProcessGroup(cmd, true)
+			})
+
+		case <-proc.abandoned:
+			s.logger.Error("process group did not exit after watchdog termination, abandoning",
 				zap.String("task_id", task.TaskID),
 				zap.String("exec_id", execID))
-		case <-gracefulCtx.Done():
-			s.logger.Warn("graceful stop timeout, forcing kill",
+			return fmt.Errorf("%w: process group ignored SIGKILL: %w", scanner.ErrCriticalTimeout, context.DeadlineExceeded)
+
+		case <-ctx.Done():
+			s.logger.Warn("command execution canceled",
 				zap.String("task_id", task.TaskID),
 				zap.String("exec_id", execID))
+
 			s.KillProcessGroup(cmd, true)
+			return ctx.Err()
 		}
+	}
+}
 
-		return timeoutCtx.Err()
+// taskDeadline 返回任务的分级超时及起算时间；任务未登记执行时（如健康检查）从当前时间起算
+func (s *BaseScanner) taskDeadline(task *domain.ScanTaskPayload) (scanner.TaskDeadline, time.Time) {
+	startedAt, size := s.executions.timing(task.TaskID)
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	return s.timeoutCtrl.Deadline(task, s.defaultTimeout, size), startedAt
+}
 
-	case <-ctx.Done():
-		s.logger.Warn("command execution canceled",
-			zap.String("task_id", task.TaskID),
-			zap.String("exec_id", execID))
+// SetTargetSize 登记任务的目标规模，之后启动的命令按规模放宽超时
+func (s *BaseScanner) SetTargetSize(task *domain.ScanTaskPayload, size scanner.TargetSize) {
+	s.executions.setTargetSize(task.TaskID, size)
+}
+
+// handleTimeout 将超时事件交给超时控制器；未配置控制器时仅执行终止动作
+func (s *BaseScanner) handleTimeout(task *domain.ScanTaskPayload, severity scanner.TimeoutSeverity, deadline scanner.TaskDeadline, startedAt time.Time, progress func() bool, terminate func()) {
+	if s.timeoutCtrl == nil {
+		if terminate != nil {
+			terminate()
+		}
+		return
+	}
+	s.timeoutCtrl.HandleTimeout(scanner.TimeoutEvent{
+		TaskID:       task.TaskID,
+		ExecutorType: s.meta.Type,
+		Severity:     severity,
+		ElapsedTime:  time.Since(startedAt),
+		Task:         task,
+		Policy:       s.timeoutCtrl.Policy(),
+		Deadline:     deadline,
+		Progress:     progress,
+		Terminate:    terminate,
+	})
+}
 
-		s.circuitBreaker.RecordFailure(scanner.TransientError)
+// awaitTermination 硬超时后等待执行结束：优雅停止期后调用 forceKill，
+// 到达严重超时仍未结束时上报严重超时并返回 scanner.ErrCriticalTimeout
+func (s *BaseScanner) awaitTermination(task *domain.ScanTaskPayload, deadline scanner.TaskDeadline, startedAt time.Time, exited <-chan error, forceKill func()) error {
+	graceful := time.NewTimer(s.gracefulStopPeriod)
+	defer graceful.Stop()
+	wait := time.Until(startedAt.Add(deadline.Critical))
+	if minWait := s.gracefulStopPeriod + time.Second; wait < minWait {
+		wait = minWait
+	}
+	critical := time.NewTimer(wait)
+	defer critical.Stop()
 
-		s.KillProcessGroup(cmd, true)
-		return ctx.Err()
+	for {
+		select {
+		case <-exited:
+			return fmt.Errorf("exceeded hard timeout %s: %w", deadline.Hard, context.DeadlineExceeded)
+		case <-graceful.C:
+			if forceKill != nil {
+				forceKill()
+			}
+		case <-critical.C:
+			s.handleTimeout(task, scanner.SeverityCritical, deadline, startedAt, nil, nil)
+			return fmt.Errorf("%w after %s: %w", scanner.ErrCriticalTimeout, time.Since(startedAt).Round(time.Second), context.DeadlineExceeded)
+		}
+	}
+}
+
+// cpuProgress 返回进度探测：自上次探测以来控制组是否消耗过 CPU；无控制组时返回 nil
+func cpuProgress(cgroup Cgroup) func() bool {
+	if cgroup == nil {
+		return nil
+	}
+	var last int64
+	if stats, err := cgroup.Stats(); err == nil {
+		last = stats.CPUUsageUsec
+	}
+	return func() bool {
+		stats, err := cgroup.Stats()
+		if err != nil {
+			return true
+		}
+		progressed := stats.CPUUsageUsec > last
+		last = stats.CPUUsageUsec
+		return progressed
 	}
 }
 
@@ -253,14 +478,10 @@ func (s *BaseScanner) KillProcessGroup(cmd *exec.Cmd, force bool) {
 		return
 	}
 
+	// 不读取 cmd.ProcessState：它由 ExecuteCommand 中并发执行的 cmd.Wait 写入，
+	// 进程已退出时向进程组发送信号返回 ESRCH，可安全忽略
 	pid := cmd.Process.Pid
 
-	// 检查进程是否已退出
-	if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
-		s.logger.Debug("process already exited", zap.Int("pid", pid))
-		return
-	}
-
 	// 实时检查进程是否存在
 	if process, err := os.FindProcess(pid); err != nil || process == nil {
 		s.logger.Debug("process not found", zap.Int("pid", pid))
@@ -277,15 +498,7 @@ func (s *BaseScanner) KillProcessGroup(cmd *exec.Cmd, force bool) {
 	default:
 		s.killUnixProcess(pid, force)
 	}
-
-	// 确保进程状态更新
-	if cmd.ProcessState == nil {
-		if _, err := cmd.Process.Wait(); err != nil {
-			s.logger.Debug("process wait result",
-				zap.Int("pid", pid),
-				zap.Error(err))
-		}
-	}
+	// 进程由 ExecuteCommand 中的 cmd.Wait 回收，这里不阻塞等待
 }
 
 func (s *BaseScanner) killUnixProcess(pid int, force bool) {
@@ -319,11 +532,6 @@ func (s *BaseScanner) killUnixProcess(pid int, force bool) {
 	} else {
 		s.logger.Debug("process not found", zap.Int("pid", pid))
 	}
-
-	// 处理僵尸进程
-	if _, err := syscall.Wait4(pid, nil, syscall.WNOHANG, nil); err != nil {
-		s.logger.Debug("reaped zombie process", zap.Int("pid", pid))
-	}
 }
 
 func (s *BaseScanner) killWindowsProcess(pid int, force bool) {
@@ -342,10 +550,14 @@ func (s *BaseScanner) killWindowsProcess(pid int, force bool) {
 
 func (s *BaseScanner) setupSignalHandling() {
 	sigs := make(chan os.Signal, 1)
+	s.processManager.signals = sigs
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		select {
+		case <-s.processManager.signalsStopped:
+			return
+
 		case sig := <-sigs:
 			s.logger.Info("received system signal, cleaning up",
 				zap.String("signal", sig.String()))
@@ -358,19 +570,27 @@ func (s *BaseScanner) setupSignalHandling() {
 	}()
 }
 
+// stopSignalHandling 停止监听系统信号，用于被替换后不再接收新任务的执行器，不影响正在运行的进程
+func (s *BaseScanner) stopSignalHandling() {
+	s.processManager.stopSignalsOnce.Do(func() {
+		signal.Stop(s.processManager.signals)
+		close(s.processManager.signalsStopped)
+	})
+}
+
 func (s *BaseScanner) cleanupProcesses() {
 	var wg sync.WaitGroup
 	var activeCount int
 
 	// 创建进程快照避免并发修改
 	s.processManager.activeProcesses.Range(func(key, value interface{}) bool {
-		if cmd, ok := value.(*exec.Cmd); ok && cmd.Process != nil {
+		if proc, ok := value.(*activeProcess); ok && proc.watch.Load() != nil {
 			activeCount++
 			wg.Add(1)
 			go func(c *exec.Cmd) {
 				defer wg.Done()
 				s.KillProcessGroup(c, true)
-			}(cmd)
+			}(proc.cmd)
 		}
 		return true
 	})
@@ -396,38 +616,33 @@ func (s *BaseScanner) cleanupProcesses() {
 	s.processManager.activeProcesses = sync.Map{}
 }
 
-// setProcessAttributes is implemented in platform-specific files
-func (s *BaseScanner) setProcessAttributes(cmd *exec.Cmd) {
-	// 通用属性
-	cmd.SysProcAttr = &syscall.SysProcAttr{}
-
-	// Unix系统设置进程组
-	if runtime.GOOS != "windows" {
-		cmd.SysProcAttr.Setpgid = true
-		// 设置进程组ID为父进程PID
-		// 确保整个进程组能被正确终止
-		cmd.SysProcAttr.Pgid = syscall.Getpid()
-	}
-
-	// 安全相关设置
-	if s.securityProfile.RunAsUser != nil {
-		cmd.SysProcAttr.Credential = &syscall.Credential{
-			Uid:    uint32(*s.securityProfile.RunAsUser),
-			Gid:    uint32(*s.securityProfile.RunAsGroup),
-			Groups: []uint32{},
+// WatchedProcesses 实现 scanner.ProcessSource，返回已启动的子进程
+func (s *BaseScanner) WatchedProcesses() []scanner.WatchedProcess {
+	var procs []scanner.WatchedProcess
+	s.processManager.activeProcesses.Range(func(_, value interface{}) bool {
+		if watch := value.(*activeProcess).watch.Load(); watch != nil {
+			procs = append(procs, *watch)
 		}
-	}
+		return true
+	})
+	return procs
+}
 
-	// Linux 特定的安全设置
-	if runtime.GOOS == "linux" && s.securityProfile.NoNewPrivs {
-		// 在 Linux 上，我们需要使用 prctl 系统调用来设置 NoNewPrivs
-		// 这需要在进程启动后通过其他方式实现
-		s.logger.Info("NoNewPrivs requested, will be set after process start")
+// cgroupStats 读取控制组资源统计，未使用控制组或读取失败时返回 nil
+func (s *BaseScanner) cgroupStats(cgroup Cgroup) *CgroupStats {
+	if cgroup == nil {
+		return nil
 	}
+	stats, err := cgroup.Stats()
+	if err != nil {
+		s.logger.Warn("read cgroup stats failed", zap.Error(err))
+		return nil
+	}
+	return &stats
 }
 
 // recordCommandMetrics 记录命令执行指标
-func (s *BaseScanner) recordCommandMetrics(task *domain.ScanTaskPayload, cmd *exec.Cmd, err error, duration time.Duration) {
+func (s *BaseScanner) recordCommandMetrics(task *domain.ScanTaskPayload, cmd *exec.Cmd, err error, duration time.Duration, stats *CgroupStats) {
 	if s.metricsRecorder == nil {
 		return
 	}
@@ -456,10 +671,12 @@ func (s *BaseScanner) recordCommandMetrics(task *domain.ScanTaskPayload, cmd *ex
 	}
 
 	// 记录资源使用情况
-	if s.cgroupManager != nil {
-		// 这里可以添加资源使用指标的记录
-		s.metricsRecorder.Gauge("command_memory_usage_bytes", 0, tags) // 需要实现实际的内存统计
-		s.metricsRecorder.Gauge("command_cpu_usage_percent", 0, tags)  // 需要实现实际的 CPU 统计
+	if stats != nil {
+		s.metricsRecorder.Gauge("command_memory_peak_bytes", float64(stats.MemoryPeakBytes), tags)
+		s.metricsRecorder.Record("command_cgroup_cpu_seconds", float64(stats.CPUUsageUsec)/1e6, tags)
+		if stats.OOMKills > 0 {
+			s.metricsRecorder.Record("command_oom_kills", float64(stats.OOMKills), tags)
+		}
 	}
 }
 
@@ -468,12 +685,26 @@ func (s *BaseScanner) Meta() scanner.ExecutorMeta {
 	return s.meta
 }
 
+// findingReport 以扫描器自身作为 SARIF 工具构造发现项报告
+func (s *BaseScanner) findingReport(findings []domain.Finding) domain.FindingReport {
+	return domain.FindingReport{
+		Tool:        "go-sac-" + strings.ToLower(s.scanType.String()),
+		ToolVersion: s.meta.Version,
+		Findings:    findings,
+	}
+}
+
 // HealthCheck implements TaskExecutor interface
 func (s *BaseScanner) HealthCheck() error {
-	// 检查cgroups是否可用
+	// 检查cgroups是否可用，不可用时降级运行而非判定扫描器不健康
 	if s.cgroupManager != nil {
-		if err := s.cgroupManager.Apply(os.Getpid()); err != nil {
-			return fmt.Errorf("cgroup health check failed: %w", err)
+		available := 1.0
+		if err := s.cgroupManager.HealthCheck(); err != nil {
+			available = 0
+			s.logger.Warn("cgroup unavailable, scanner processes run without resource limits", zap.Error(err))
+		}
+		if s.metricsRecorder != nil {
+			s.metricsRecorder.Gauge("cgroup_available", available, map[string]string{"scanner_type": s.scanType.String()})
 		}
 	}
 
@@ -519,25 +750,66 @@ func WithMetricsRecorder(mr MetricsRecorder) BaseScannerOption {
 	}
 }
 
-// WithCircuitBreaker 设置熔断器配置
+// WithCircuitBreaker 按配置为扫描器创建独立的熔断器登记表
 func WithCircuitBreaker(config *config.Config) BaseScannerOption {
-	threshold, criticalThreshold, resetTimeout := config.GetCircuitBreakerConfig()
+	return WithCircuitBreakerRegistry(scanner.NewCircuitBreakerRegistry(config.GetCircuitBreakerSettings))
+}
+
+// WithCircuitBreakerRegistry 使用共享的熔断器登记表，使扫描器工厂与扫描器看到同一熔断状态
+func WithCircuitBreakerRegistry(registry *scanner.CircuitBreakerRegistry) BaseScannerOption {
 	return func(bs *BaseScanner) {
-		bs.circuitBreaker = scanner.NewCircuitBreaker(threshold, criticalThreshold, resetTimeout)
+		bs.circuitBreakers = registry
+		bs.circuitBreaker = registry.Get(bs.scanType)
 	}
 }
 
-// ExecuteWithTimeout 执行带超时控制的通用任务
+// acquireCircuit 申请熔断器放行，返回的 finish 在执行结束时调用一次以计入结果；
+// 上下文取消（用户取消或服务停止）不计入熔断，只归还试探名额
+func (s *BaseScanner) acquireCircuit(breaker *scanner.CircuitBreaker, taskID string) (func(error), error) {
+	if err := breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("%w, task %s rejected", err, taskID)
+	}
+	return func(err error) {
+		switch {
+		case err == nil:
+			breaker.RecordSuccess()
+		case errors.Is(err, context.Canceled) || s.executions.isCancelled(taskID):
+			breaker.Release()
+		default:
+			_, errType := s.classifyError(err)
+			breaker.RecordFailure(errType)
+		}
+	}, nil
+}
+
+// ExecuteWithTimeout 执行带超时控制的通用任务，结果计入本扫描类型的熔断器
 func (s *BaseScanner) ExecuteWithTimeout(ctx context.Context, task *domain.ScanTaskPayload, fn func(context.Context) error) error {
+	return s.ExecuteWithBreaker(ctx, task, s.circuitBreaker, fn)
+}
+
+// ExecuteWithBreaker 执行带超时控制的通用任务，结果计入指定的熔断器（如按目标主机的熔断器）
+func (s *BaseScanner) ExecuteWithBreaker(ctx context.Context, task *domain.ScanTaskPayload, breaker *scanner.CircuitBreaker, fn func(context.Context) error) (err error) {
+	// 申请熔断器放行，执行结束时计入结果
+	finish, err := s.acquireCircuit(breaker, task.TaskID)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
 	// 1. 准备执行环境
 	execID := fmt.Sprintf("%s-%d", task.TaskID, time.Now().UnixNano())
 	s.logger.Info("starting task execution",
 		zap.String("task_id", task.TaskID),
 		zap.String("exec_id", execID))
 
-	// 2. 创建带超时的上下文
-	timeoutCtx, cancel := context.WithTimeout(ctx, s.defaultTimeout)
+	// 2. 按任务的分级超时创建上下文，硬超时时取消
+	deadline, startedAt := s.taskDeadline(task)
+	runCtx, cancel := context.WithDeadline(ctx, startedAt.Add(deadline.Hard))
 	defer cancel()
+	soft := time.NewTimer(time.Until(startedAt.Add(deadline.Soft)))
+	defer soft.Stop()
+	hard := time.NewTimer(time.Until(startedAt.Add(deadline.Hard)))
+	defer hard.Stop()
 
 	// 3. 创建结果通道
 	done := make(chan error, 1)
@@ -545,31 +817,41 @@ func (s *BaseScanner) ExecuteWithTimeout(ctx context.Context, task *domain.ScanT
 
 	// 4. 异步执行任务
 	go func() {
-		done <- fn(timeoutCtx)
+		done <- fn(runCtx)
 		close(done)
 	}()
 
 	// 5. 处理完成、超时或取消
-	select {
-	case err := <-done:
-		execDuration := time.Since(startTime)
-		s.recordTaskMetrics(task, err, execDuration)
-		return err
+	for {
+		select {
+		case err := <-done:
+			execDuration := time.Since(startTime)
+			if err != nil && time.Now().After(startedAt.Add(deadline.Hard)) && errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("exceeded hard timeout %s: %w", deadline.Hard, err)
+			}
+			s.recordTaskMetrics(task, err, execDuration)
+			return err
 
-	case <-timeoutCtx.Done():
-		s.logger.Warn("task execution timeout",
-			zap.String("task_id", task.TaskID),
-			zap.String("exec_id", execID),
-			zap.Duration("timeout", s.defaultTimeout))
-		s.recordTaskMetrics(task, timeoutCtx.Err(), time.Since(startTime))
-		return timeoutCtx.Err()
+		case <-soft.C:
+			s.handleTimeout(task, scanner.SeveritySoft, deadline, startedAt, nil, nil)
 
-	case <-ctx.Done():
-		s.logger.Warn("task execution canceled",
-			zap.String("task_id", task.TaskID),
-			zap.String("exec_id", execID))
-		s.recordTaskMetrics(task, ctx.Err(), time.Since(startTime))
-		return ctx.Err()
+		case <-hard.C:
+			s.logger.Warn("task execution timeout",
+				zap.String("task_id", task.TaskID),
+				zap.String("exec_id", execID),
+				zap.Duration("timeout", deadline.Hard))
+			s.handleTimeout(task, scanner.SeverityHard, deadline, startedAt, nil, cancel)
+			err := s.awaitTermination(task, deadline, startedAt, done, nil)
+			s.recordTaskMetrics(task, err, time.Since(startTime))
+			return err
+
+		case <-ctx.Done():
+			s.logger.Warn("task execution canceled",
+				zap.String("task_id", task.TaskID),
+				zap.String("exec_id", execID))
+			s.recordTaskMetrics(task, ctx.Err(), time.Since(startTime))
+			return ctx.Err()
+		}
 	}
 }
 
@@ -608,14 +890,42 @@ func (s *BaseScanner) recordTaskMetrics(task *domain.ScanTaskPayload, err error,
 
 // ExecuteWithResult 执行扫描任务并处理结果
 func (b *BaseScanner) ExecuteWithResult(ctx context.Context, task *domain.ScanTaskPayload, scanFunc func(context.Context) (*domain.ScanResult, error)) (*domain.ScanResult, error) {
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started, err := b.executions.start(task.TaskID, cancel)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		// 排队期间已被取消
+		b.executions.finish(task.TaskID, context.Canceled)
+		_ = b.UpdateTaskStatus(ctx, task.TaskID, domain.TaskStatusCancelled)
+		return nil, fmt.Errorf("scan cancelled: %w", context.Canceled)
+	}
+
 	// 更新任务状态为运行中
 	if err := b.UpdateTaskStatus(ctx, task.TaskID, domain.TaskStatusRunning); err != nil {
+		b.executions.finish(task.TaskID, err)
 		return nil, fmt.Errorf("failed to update task status: %w", err)
 	}
 
-	// 执行扫描任务
-	result, err := scanFunc(ctx)
-	if err != nil {
+	// 执行扫描任务，完成且产出结果的耗时作为超时自适应的历史样本
+	startedAt := time.Now()
+	result, err := scanFunc(execCtx)
+	status := b.executions.finish(task.TaskID, err)
+	if b.timeoutCtrl != nil && err == nil && status == domain.TaskStatusCompleted && result != nil && result.Status == "success" {
+		b.timeoutCtrl.ObserveDuration(b.scanType, time.Since(startedAt))
+	}
+	if result != nil {
+		// 关联本次执行上传的命令输出
+		result.LogObjects = b.executions.logObjects(task.TaskID)
+	}
+	switch {
+	case status == domain.TaskStatusCancelled:
+		_ = b.UpdateTaskStatus(ctx, task.TaskID, domain.TaskStatusCancelled)
+		return nil, fmt.Errorf("scan cancelled: %w", context.Canceled)
+	case err != nil:
 		// 更新任务状态为失败
 		_ = b.UpdateTaskStatus(ctx, task.TaskID, domain.TaskStatusFailed)
 		return nil, fmt.Errorf("scan failed: %w", err)
@@ -634,6 +944,56 @@ func (b *BaseScanner) ExecuteWithResult(ctx context.Context, task *domain.ScanTa
 	return result, nil
 }
 
+// CancelExecution 取消句柄对应的执行：未开始的直接标记取消，
+// 运行中的先向进程组发送 SIGTERM，优雅停止期后仍未退出则强制终止
+func (b *BaseScanner) CancelExecution(handle string) error {
+	procs, cancel, err := b.executions.requestCancel(handle)
+	if err != nil {
+		return err
+	}
+	b.logger.Info("cancelling execution",
+		zap.String("handle", handle),
+		zap.Int("processes", len(procs)))
+
+	for _, p := range procs {
+		b.KillProcessGroup(p.cmd, false)
+	}
+	deadline := time.NewTimer(b.gracefulStopPeriod)
+	defer deadline.Stop()
+	for _, p := range procs {
+		select {
+		case <-p.done:
+		case <-deadline.C:
+			b.logger.Warn("graceful stop timeout, forcing kill", zap.String("handle", handle))
+			for _, p := range procs {
+				select {
+				case <-p.done:
+				default:
+					b.KillProcessGroup(p.cmd, true)
+				}
+			}
+			cancel()
+			return nil
+		}
+	}
+	cancel()
+	return nil
+}
+
+// ExecutionStatus 返回句柄对应执行的当前状态
+func (b *BaseScanner) ExecutionStatus(handle string) (domain.TaskStatus, error) {
+	info, err := b.executions.get(handle)
+	if err != nil {
+		return "", err
+	}
+	return info.Status, nil
+}
+
+// GetExecution 实现 scanner.ExecutionTracker 接口
+func (b *BaseScanner) GetExecution(handle string) (scanner.ExecutionInfo, error) {
+	return b.executions.get(handle)
+}
+
 // UpdateTaskStatus 更新任务状态
 func (b *BaseScanner) UpdateTaskStatus(ctx context.Context, taskID string, status domain.TaskStatus) error {
 	if b.taskStatusUpdater == nil {
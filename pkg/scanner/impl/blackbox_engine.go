@@ -0,0 +1,255 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"go.uber.org/zap"
+)
+
+// BlackBoxReport 一次黑盒测试的汇总
+type BlackBoxReport struct {
+	Target          string            `json:"target"`
+	Sources         []TrafficSource   `json:"sources"`
+	Operations      int               `json:"operations"`
+	Skipped         int               `json:"skipped"` // 不属于回放目标、方法不允许或命中排除规则的接口
+	Requests        int               `json:"requests"`
+	BudgetExhausted bool              `json:"budget_exhausted"`
+	Authenticated   bool              `json:"authenticated"`
+	Relogins        int               `json:"relogins"`
+	SessionLost     bool              `json:"session_lost"`
+	Anomalies       []BlackBoxAnomaly `json:"anomalies"`
+}
+
+// Replay 发送回放请求；anonymous 时不附加登录会话凭据，也不携带 Cookie 容器中的 Cookie
+func (r *Requester) Replay(ctx context.Context, rr *replayRequest, anonymous bool) (*Response, error) {
+	if r.excluded(rr.URL) {
+		return nil, ErrExcludedURL
+	}
+	resp, err := r.replay(ctx, rr, anonymous)
+	if err != nil || anonymous || r.session == nil || !r.session.expired(resp) {
+		return resp, err
+	}
+	if err := r.session.renew(ctx, r); err != nil {
+		return resp, nil
+	}
+	return r.replay(ctx, rr, false)
+}
+
+func (r *Requester) replay(ctx context.Context, rr *replayRequest, anonymous bool) (*Response, error) {
+	var body io.Reader
+	if len(rr.Body) > 0 {
+		body = bytes.NewReader(rr.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, rr.Method, rr.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range rr.Header {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+
+	client := r.client
+	if anonymous {
+		c := *r.client
+		c.Jar = nil
+		client = &c
+	} else if r.session != nil {
+		r.session.apply(req)
+	}
+	return r.exchange(client, req, rr.URL)
+}
+
+// BlackBoxEngine 流量回放与参数变异引擎
+type BlackBoxEngine struct {
+	cfg        config.BlackBoxConfig
+	mutators   []replayMutator
+	logger     *zap.Logger
+	session    *DASTSession
+	exclude    []*regexp.Regexp
+	foreignIDs []string
+}
+
+// BlackBoxEngineOption 回放引擎选项
+type BlackBoxEngineOption func(*BlackBoxEngine)
+
+// WithBlackBoxSession 使用登录会话回放，覆盖流量中记录的凭据
+func WithBlackBoxSession(session *DASTSession) BlackBoxEngineOption {
+	return func(e *BlackBoxEngine) {
+		e.session = session
+	}
+}
+
+// WithBlackBoxExclusions 设置不回放的 URL 规则
+func WithBlackBoxExclusions(exclude []*regexp.Regexp) BlackBoxEngineOption {
+	return func(e *BlackBoxEngine) {
+		e.exclude = exclude
+	}
+}
+
+// WithForeignIDs 设置属于其它账号的对象 ID，用于越权检测
+func WithForeignIDs(ids []string) BlackBoxEngineOption {
+	return func(e *BlackBoxEngine) {
+		e.foreignIDs = ids
+	}
+}
+
+// NewBlackBoxEngine 创建回放引擎
+func NewBlackBoxEngine(cfg config.BlackBoxConfig, mutators []replayMutator, logger *zap.Logger, opts ...BlackBoxEngineOption) *BlackBoxEngine {
+	e := &BlackBoxEngine{cfg: cfg, mutators: mutators, logger: logger}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run 把流量改写到回放目标，逐个接口发送基线请求与变异请求
+func (e *BlackBoxEngine) Run(ctx context.Context, target *url.URL, files []*trafficFile) (*BlackBoxReport, error) {
+	requester := NewRequester(config.CrawlerConfig{
+		MaxRequests:    e.cfg.MaxRequests,
+		RequestTimeout: e.cfg.RequestTimeout,
+		UserAgent:      e.cfg.UserAgent,
+		InsecureTLS:    e.cfg.InsecureTLS,
+	})
+	requester.exclude = e.exclude
+	report := &BlackBoxReport{Target: target.String(), Anomalies: []BlackBoxAnomaly{}}
+	if e.session != nil {
+		requester.session = e.session
+	}
+
+	// 1. 合并各文件的接口，先于登录完成筛选，没有可回放的接口时不发送请求
+	ops, ownIDs := e.plan(requester, target, files, report)
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no replayable requests for %s in traffic files", target)
+	}
+	if e.session != nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		requester.client.Jar = jar
+		if err := e.session.start(ctx, requester, target); err != nil {
+			return nil, err
+		}
+		report.Authenticated = true
+	}
+
+	seen := make(map[string]bool)
+	add := func(a *BlackBoxAnomaly) {
+		key := strings.Join([]string{a.Type, a.Operation, a.In, a.Parameter}, "|")
+		if !seen[key] {
+			seen[key] = true
+			report.Anomalies = append(report.Anomalies, *a)
+		}
+	}
+	env := &mutationEnv{ownIDs: ownIDs, foreignIDs: e.foreignIDs, authenticated: e.session != nil}
+
+	// 2. 基线请求与变异请求
+	reached := 0
+	err := func() error {
+		for _, op := range ops {
+			baseline, err := requester.Replay(ctx, op.Request, false)
+			if err != nil {
+				if done, err := e.stop(ctx, report, err); done {
+					return err
+				}
+				e.logger.Debug("baseline request failed", zap.String("operation", op.Name), zap.Error(err))
+				continue
+			}
+			reached++
+
+			for _, m := range e.mutators {
+				controls := make(map[*replayRequest]*Response)
+				for _, v := range m.Variants(op, env) {
+					out := replayOutcome{Baseline: baseline}
+					out.Response, err = requester.Replay(ctx, v.Request, v.Anonymous)
+					if err == nil && v.Control != nil {
+						if out.Control = controls[v.Control]; out.Control == nil {
+							out.Control, err = requester.Replay(ctx, v.Control, false)
+							controls[v.Control] = out.Control
+						}
+					}
+					if err != nil {
+						if done, err := e.stop(ctx, report, err); done {
+							return err
+						}
+						e.logger.Debug("mutated request failed",
+							zap.String("operation", op.Name),
+							zap.String("mutation", m.Name()),
+							zap.Error(err))
+						continue
+					}
+					if a := m.Analyze(op, v, out); a != nil {
+						add(a)
+					}
+				}
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+	if reached == 0 && !report.BudgetExhausted {
+		return nil, fmt.Errorf("target %s is unreachable", target)
+	}
+
+	report.Requests = requester.Used()
+	if e.session != nil {
+		report.Relogins = e.session.Relogins()
+		report.SessionLost = e.session.Lost()
+	}
+	return report, nil
+}
+
+// stop 判断请求错误是否结束回放：预算耗尽时正常结束，任务取消时返回错误
+func (e *BlackBoxEngine) stop(ctx context.Context, report *BlackBoxReport, err error) (bool, error) {
+	switch {
+	case errors.Is(err, ErrRequestBudgetExhausted):
+		report.BudgetExhausted = true
+		return true, nil
+	case ctx.Err() != nil:
+		return true, ctx.Err()
+	}
+	return false, nil
+}
+
+// plan 改写各文件的接口并去重，统计跳过的接口，返回流量中属于录制账号的对象 ID
+func (e *BlackBoxEngine) plan(r *Requester, target *url.URL, files []*trafficFile, report *BlackBoxReport) ([]*replayOperation, map[string]bool) {
+	methods := make(map[string]bool, len(e.cfg.Methods))
+	for _, m := range e.cfg.Methods {
+		methods[strings.ToUpper(m)] = true
+	}
+	ownIDs := make(map[string]bool)
+	seen := make(map[string]bool)
+	var ops []*replayOperation
+	for _, f := range files {
+		report.Sources = append(report.Sources, TrafficSource{Name: f.Name, Format: f.Format, Operations: len(f.Operations)})
+		for id := range f.IDs {
+			ownIDs[id] = true
+		}
+		for _, op := range f.Operations {
+			rebased := f.rebase(op, target)
+			if rebased == nil || !methods[rebased.Request.Method] || r.excluded(rebased.Request.URL) {
+				report.Skipped++
+				continue
+			}
+			if key := rebased.key(); !seen[key] {
+				seen[key] = true
+				ops = append(ops, rebased)
+			}
+		}
+	}
+	report.Operations = len(ops)
+	return ops, ownIDs
+}
@@ -0,0 +1,456 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 黑盒测试发现的异常类型，输入引发的数据库错误复用 DAST 的 sql_injection
+const (
+	AnomalyServerError     = "server_error"
+	AnomalyErrorDisclosure = "error_disclosure"
+	AnomalyMissingAuth     = "missing_authentication"
+	AnomalyIDOR            = "idor"
+)
+
+// 参数变异
+const (
+	MutationBoundary      = "boundary"
+	MutationTypeConfusion = "type_confusion"
+	MutationAuthRemoval   = "auth_removal"
+	MutationIDOR          = "idor"
+)
+
+var replayMutators = map[string]func() replayMutator{
+	MutationBoundary:      func() replayMutator { return boundaryMutator{} },
+	MutationTypeConfusion: func() replayMutator { return typeConfusionMutator{} },
+	MutationAuthRemoval:   func() replayMutator { return authRemovalMutator{} },
+	MutationIDOR:          func() replayMutator { return idorMutator{} },
+}
+
+// newReplayMutators 按名称创建参数变异，names 为空时返回全部
+func newReplayMutators(names []string) ([]replayMutator, error) {
+	if len(names) == 0 {
+		for name := range replayMutators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	out := make([]replayMutator, 0, len(names))
+	for _, name := range names {
+		factory, ok := replayMutators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown black-box mutation: %s", name)
+		}
+		out = append(out, factory())
+	}
+	return out, nil
+}
+
+// stackTraceSignatures 常见运行时的异常堆栈与调试信息特征
+var stackTraceSignatures = regexp.MustCompile(`(Traceback \(most recent call last\)|goroutine \d+ \[|panic: |runtime error: |Exception in thread "|at [\w$.]+\([\w$]+\.java:\d+\)|System\.[A-Za-z.]*Exception|\.php on line \d+|Fatal error: |NullPointerException|TypeError: .{0,80}\n\s+at |node_modules/)`)
+
+// BlackBoxAnomaly 回放变异请求发现的异常
+type BlackBoxAnomaly struct {
+	Type           string  `json:"type"`
+	Severity       string  `json:"severity"`
+	CVSSScore      float64 `json:"cvss_score"`
+	Method         string  `json:"method"`
+	URL            string  `json:"url"`
+	Operation      string  `json:"operation"`
+	Parameter      string  `json:"parameter,omitempty"`
+	In             string  `json:"in,omitempty"`
+	Mutation       string  `json:"mutation"`
+	Payload        string  `json:"payload,omitempty"`
+	BaselineStatus int     `json:"baseline_status"`
+	Status         int     `json:"status"`
+	Evidence       string  `json:"evidence,omitempty"`
+	Remediation    string  `json:"remediation,omitempty"`
+}
+
+// replayVariant 一个变异请求
+type replayVariant struct {
+	Param     *replayParam // 去掉凭据的变异为空
+	Payload   string
+	Request   *replayRequest
+	Anonymous bool           // 不附加登录会话凭据
+	Control   *replayRequest // 越权检测的对照请求，使用不存在的 ID
+	Severity  string
+}
+
+// replayOutcome 变异请求及其对照的响应
+type replayOutcome struct {
+	Baseline *Response
+	Response *Response
+	Control  *Response
+}
+
+// mutationEnv 生成变异时的上下文
+type mutationEnv struct {
+	ownIDs        map[string]bool // 流量中出现过的对象 ID，属于录制账号
+	foreignIDs    []string        // 属于其它账号的对象 ID
+	authenticated bool            // 扫描使用了登录会话
+}
+
+// replayMutator 参数变异：生成变异请求，并对比基线响应判断是否异常
+type replayMutator interface {
+	Name() string
+	Variants(op *replayOperation, env *mutationEnv) []replayVariant
+	Analyze(op *replayOperation, v replayVariant, out replayOutcome) *BlackBoxAnomaly
+}
+
+func newAnomaly(op *replayOperation, mutation string, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	u := *op.Request.URL
+	u.RawQuery = ""
+	a := &BlackBoxAnomaly{
+		Method:         op.Request.Method,
+		URL:            u.String(),
+		Operation:      op.Name,
+		Mutation:       mutation,
+		Payload:        v.Payload,
+		BaselineStatus: out.Baseline.StatusCode,
+		Status:         out.Response.StatusCode,
+	}
+	if v.Param != nil {
+		a.Parameter = v.Param.Name
+		a.In = v.Param.In
+	}
+	return a
+}
+
+// analyzeInput 边界值与类型混淆共用的判定：出现基线中没有的数据库错误、异常堆栈或服务端错误
+func analyzeInput(op *replayOperation, mutation string, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	resp, baseline := out.Response, out.Baseline
+	a := newAnomaly(op, mutation, v, out)
+	switch {
+	case sqlErrorSignatures.Match(resp.Body) && !sqlErrorSignatures.Match(baseline.Body):
+		a.Type, a.Severity, a.CVSSScore = VulnSQLInjection, SeverityHigh, 8.6
+		a.Evidence = "database error: " + string(sqlErrorSignatures.Find(resp.Body))
+		a.Remediation = "Use parameterized queries and suppress database errors in responses."
+	case stackTraceSignatures.Match(resp.Body) && !stackTraceSignatures.Match(baseline.Body):
+		a.Type, a.Severity, a.CVSSScore = AnomalyErrorDisclosure, SeverityMedium, 5.3
+		a.Evidence = "stack trace: " + matchedLine(resp.Body, stackTraceSignatures)
+		a.Remediation = "Validate input types and ranges, and return generic error responses without stack traces."
+	case resp.StatusCode >= 500 && baseline.StatusCode < 500:
+		a.Type, a.Severity, a.CVSSScore = AnomalyServerError, SeverityMedium, 5.3
+		a.Evidence = fmt.Sprintf("status %d, baseline %d", resp.StatusCode, baseline.StatusCode)
+		a.Remediation = "Validate input types and ranges and reject invalid input with a 4xx response."
+	default:
+		return nil
+	}
+	return a
+}
+
+// boundaryMutator 数值取极值与溢出值，字符串取空串、超长串与特殊字符
+type boundaryMutator struct{}
+
+func (boundaryMutator) Name() string { return MutationBoundary }
+
+var (
+	boundaryNumbers = []string{"0", "-1", "2147483648", "9223372036854775808"}
+	boundaryStrings = []string{"", strings.Repeat("A", 8192), `'"\<%00`}
+)
+
+func (boundaryMutator) Variants(op *replayOperation, _ *mutationEnv) []replayVariant {
+	var out []replayVariant
+	for i := range op.Params {
+		p := &op.Params[i]
+		var values []interface{}
+		switch v := p.Value.(type) {
+		case json.Number:
+			for _, n := range boundaryNumbers {
+				values = append(values, json.Number(n))
+			}
+		case string:
+			if isNumeric(v) {
+				for _, n := range boundaryNumbers {
+					values = append(values, n)
+				}
+			}
+			for _, s := range boundaryStrings {
+				if p.In == ParamInPath && s == "" {
+					continue // 空路径段改变的是路由而不是参数
+				}
+				values = append(values, s)
+			}
+		}
+		for _, value := range values {
+			out = append(out, replayVariant{Param: p, Payload: payloadText(value), Request: op.with(*p, value)})
+		}
+	}
+	return out
+}
+
+func (boundaryMutator) Analyze(op *replayOperation, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	return analyzeInput(op, MutationBoundary, v, out)
+}
+
+// typeConfusionMutator 替换为其它类型的值：JSON 中数字换字符串、字符串换数字/数组/对象，文本参数数字换字母
+type typeConfusionMutator struct{}
+
+func (typeConfusionMutator) Name() string { return MutationTypeConfusion }
+
+func (typeConfusionMutator) Variants(op *replayOperation, _ *mutationEnv) []replayVariant {
+	var out []replayVariant
+	for i := range op.Params {
+		p := &op.Params[i]
+		var values []interface{}
+		if p.In == ParamInJSON {
+			switch p.Value.(type) {
+			case json.Number:
+				values = []interface{}{"abc", true, []interface{}{}}
+			case string:
+				values = []interface{}{json.Number("0"), []interface{}{}, map[string]interface{}{}}
+			case bool:
+				values = []interface{}{"yes", json.Number("2")}
+			}
+		} else if text, ok := p.Value.(string); ok && isNumeric(text) {
+			values = []interface{}{"abc", "1.5"}
+		} else if ok {
+			values = []interface{}{"[]", "{}"}
+		}
+		for _, value := range values {
+			out = append(out, replayVariant{Param: p, Payload: payloadText(value), Request: op.with(*p, value)})
+		}
+	}
+	return out
+}
+
+func (typeConfusionMutator) Analyze(op *replayOperation, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	return analyzeInput(op, MutationTypeConfusion, v, out)
+}
+
+// authRemovalMutator 去掉录制的凭据且不附加登录会话，响应与基线一致说明接口未校验认证
+type authRemovalMutator struct{}
+
+func (authRemovalMutator) Name() string { return MutationAuthRemoval }
+
+func (authRemovalMutator) Variants(op *replayOperation, env *mutationEnv) []replayVariant {
+	if op.Public || (!op.Credentials && !env.authenticated) {
+		return nil
+	}
+	return []replayVariant{{Request: op.withoutCredentials(), Anonymous: true}}
+}
+
+func (authRemovalMutator) Analyze(op *replayOperation, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	resp, baseline := out.Response, out.Baseline
+	if !isSuccess(baseline) || resp.StatusCode != baseline.StatusCode {
+		return nil
+	}
+	similarity := responseSimilarity(baseline, resp)
+	if similarity < 0.9 {
+		return nil
+	}
+	a := newAnomaly(op, MutationAuthRemoval, v, out)
+	a.Type, a.Severity, a.CVSSScore = AnomalyMissingAuth, SeverityHigh, 7.5
+	a.Evidence = fmt.Sprintf("status %d without credentials, response %.0f%% similar to authenticated baseline", resp.StatusCode, similarity*100)
+	a.Remediation = "Enforce authentication on every API endpoint, not only in the client."
+	return a
+}
+
+// idorMutator 把 ID 参数替换为其它账号的 ID；未提供时尝试相邻的数字 ID
+// 同时请求一个不存在的 ID 作对照，接口对任意 ID 都返回相同结构的成功响应时不判为越权
+type idorMutator struct{}
+
+func (idorMutator) Name() string { return MutationIDOR }
+
+func (idorMutator) Variants(op *replayOperation, env *mutationEnv) []replayVariant {
+	var out []replayVariant
+	for i := range op.Params {
+		p := &op.Params[i]
+		if !p.isID() {
+			continue
+		}
+		current := replayValueText(p.Value)
+		severity := SeverityHigh
+		candidates := make([]string, 0, 3)
+		for _, id := range env.foreignIDs {
+			if id != current && len(candidates) < 3 {
+				candidates = append(candidates, id)
+			}
+		}
+		if len(candidates) == 0 {
+			severity = SeverityMedium
+			if n, err := strconv.ParseInt(current, 10, 64); err == nil {
+				for _, next := range []int64{n + 1, n - 1} {
+					if s := strconv.FormatInt(next, 10); next > 0 && !env.ownIDs[s] {
+						candidates = append(candidates, s)
+					}
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		control := op.with(*p, idValueLike(p.Value, nonexistentID(current)))
+		for _, id := range candidates {
+			value := idValueLike(p.Value, id)
+			out = append(out, replayVariant{Param: p, Payload: id, Request: op.with(*p, value), Control: control, Severity: severity})
+		}
+	}
+	return out
+}
+
+func (idorMutator) Analyze(op *replayOperation, v replayVariant, out replayOutcome) *BlackBoxAnomaly {
+	resp, baseline, control := out.Response, out.Baseline, out.Control
+	if !isSuccess(baseline) || !isSuccess(resp) || bytes.Equal(resp.Body, baseline.Body) {
+		return nil
+	}
+	similarity := responseSimilarity(baseline, resp)
+	if similarity < 0.8 {
+		return nil
+	}
+	if control != nil && isSuccess(control) && responseSimilarity(control, resp) >= 0.8 {
+		return nil
+	}
+	a := newAnomaly(op, MutationIDOR, v, out)
+	a.Type, a.Severity, a.CVSSScore = AnomalyIDOR, v.Severity, 6.5
+	if v.Severity == SeverityHigh {
+		a.CVSSScore = 8.1
+	}
+	a.Evidence = fmt.Sprintf("object %s returned with status %d, response %.0f%% similar to own object", v.Payload, resp.StatusCode, similarity*100)
+	if control != nil {
+		a.Evidence += fmt.Sprintf("; nonexistent id returned status %d", control.StatusCode)
+	}
+	a.Remediation = "Check object ownership on the server for every request that references an object by ID."
+	return a
+}
+
+// nonexistentID 生成与 current 形式相同、几乎不可能存在的 ID
+func nonexistentID(current string) string {
+	token := randomToken()
+	switch {
+	case isNumeric(current):
+		n, _ := strconv.ParseUint(token, 16, 32)
+		return strconv.FormatUint(9000000000+n, 10)
+	case len(current) == 36:
+		return "00000000-0000-4000-8000-0000" + token
+	case len(current) == 24:
+		return "0000000000000000" + token
+	}
+	return "nonexistent-" + token
+}
+
+// idValueLike 按原值类型构造 ID：JSON 数字保持数字
+func idValueLike(original interface{}, id string) interface{} {
+	if _, ok := original.(json.Number); ok && isNumeric(id) {
+		return json.Number(id)
+	}
+	return id
+}
+
+// matchedLine 返回第一个匹配所在的行，超长时截断
+func matchedLine(body []byte, re *regexp.Regexp) string {
+	loc := re.FindIndex(body)
+	if loc == nil {
+		return ""
+	}
+	start := bytes.LastIndexByte(body[:loc[0]], '\n') + 1
+	end := len(body)
+	if i := bytes.IndexByte(body[loc[0]:], '\n'); i >= 0 {
+		end = loc[0] + i
+	}
+	if end-start > 160 {
+		end = start + 160
+	}
+	return strings.TrimSpace(string(body[start:end]))
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isSuccess(resp *Response) bool {
+	return resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// payloadText 报告中的载荷，超长字符串截断
+func payloadText(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	if len(s) > 64 {
+		return fmt.Sprintf("%s... (%d bytes)", s[:16], len(s))
+	}
+	return s
+}
+
+// responseSimilarity 比较两个响应的结构：JSON 按字段路径集合，其它按词集合计算 Jaccard 相似度
+func responseSimilarity(a, b *Response) float64 {
+	if isJSONResponse(a) && isJSONResponse(b) {
+		va, errA := decodeJSONBody(a.Body)
+		vb, errB := decodeJSONBody(b.Body)
+		if errA == nil && errB == nil {
+			return jaccard(jsonShape(va), jsonShape(vb))
+		}
+	}
+	return jaccard(wordSet(a.Body), wordSet(b.Body))
+}
+
+func isJSONResponse(resp *Response) bool {
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	return strings.Contains(ct, "json") || (ct == "" && json.Valid(resp.Body))
+}
+
+// jsonShape 字段路径与值类型的集合，数组元素合并为 []
+func jsonShape(v interface{}) map[string]bool {
+	out := make(map[string]bool)
+	var walk func(v interface{}, at string, depth int)
+	walk = func(v interface{}, at string, depth int) {
+		if depth > maxJSONParamDepth {
+			return
+		}
+		switch x := v.(type) {
+		case map[string]interface{}:
+			out[at+"{}"] = true
+			for k, child := range x {
+				walk(child, at+"."+k, depth+1)
+			}
+		case []interface{}:
+			out[at+"[]"] = true
+			for _, item := range x {
+				walk(item, at+"[]", depth+1)
+			}
+		default:
+			out[fmt.Sprintf("%s:%T", at, x)] = true
+		}
+	}
+	walk(v, "", 0)
+	return out
+}
+
+var wordPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_-]*`)
+
+// wordSet 响应中出现的词，只取前 2000 个
+func wordSet(body []byte) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range wordPattern.FindAll(body, 2000) {
+		out[string(w)] = true
+	}
+	return out
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
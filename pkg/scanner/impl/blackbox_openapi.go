@@ -0,0 +1,332 @@
+package scanner_impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// openAPIMethods 按固定顺序生成接口
+var openAPIMethods = []string{"get", "head", "post", "put", "patch", "delete", "options"}
+
+// maxSchemaDepth 根据 schema 生成示例值的最大嵌套深度，同时用于截断循环引用
+const maxSchemaDepth = 6
+
+// openAPIDoc OpenAPI 3.x 或 Swagger 2.0 文档
+type openAPIDoc struct {
+	root    map[string]interface{}
+	swagger bool
+}
+
+// parseOpenAPI 为每个操作生成一个示例请求，参数取 example、default、enum 或按类型生成的值
+func parseOpenAPI(name string, root map[string]interface{}) (*trafficFile, error) {
+	doc := &openAPIDoc{root: root, swagger: root["swagger"] != nil}
+	f := &trafficFile{Name: name, Format: TrafficFormatOpenAPI, IDs: make(map[string]bool)}
+
+	prefix, origin := doc.server()
+	f.Origin = origin
+	paths := asMap(root["paths"])
+	if paths == nil {
+		return nil, fmt.Errorf("parse %s: openapi document has no paths", name)
+	}
+	templates := make([]string, 0, len(paths))
+	for p := range paths {
+		templates = append(templates, p)
+	}
+	sort.Strings(templates)
+
+	_, globalSecurity := root["security"]
+	globalPublic := globalSecurity && len(asList(root["security"])) == 0
+	for _, template := range templates {
+		item := doc.resolve(asMap(paths[template]), 0)
+		if item == nil {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			operation := asMap(item[method])
+			if operation == nil {
+				continue
+			}
+			op := doc.operation(name, template, strings.ToUpper(method), asList(item["parameters"]), operation)
+			op.prefix = prefix
+			op.Public = globalPublic
+			if security, ok := operation["security"]; ok {
+				op.Public = len(asList(security)) == 0
+			}
+			f.Operations = append(f.Operations, op)
+		}
+	}
+	return f, nil
+}
+
+// server 返回接口路径前缀，servers 为绝对地址时同时返回站点
+func (d *openAPIDoc) server() (string, *url.URL) {
+	raw := ""
+	if d.swagger {
+		raw = asString(d.root["basePath"])
+		if host := asString(d.root["host"]); host != "" {
+			scheme := "https"
+			if schemes := asList(d.root["schemes"]); len(schemes) > 0 {
+				scheme = asString(schemes[0])
+			}
+			raw = scheme + "://" + host + raw
+		}
+	} else if servers := asList(d.root["servers"]); len(servers) > 0 {
+		server := asMap(servers[0])
+		raw = asString(server["url"])
+		for name, v := range asMap(server["variables"]) {
+			raw = strings.ReplaceAll(raw, "{"+name+"}", replayValueText(asMap(v)["default"]))
+		}
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", nil
+	}
+	prefix := strings.TrimSuffix(u.Path, "/")
+	if (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return prefix, &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	}
+	return prefix, nil
+}
+
+// operation 生成操作的示例请求，操作级参数覆盖路径级同名参数
+func (d *openAPIDoc) operation(source, template, method string, shared []interface{}, operation map[string]interface{}) *replayOperation {
+	params := make(map[string]map[string]interface{})
+	var order []string
+	for _, raw := range append(append([]interface{}(nil), shared...), asList(operation["parameters"])...) {
+		p := d.resolve(asMap(raw), 0)
+		if p == nil {
+			continue
+		}
+		key := asString(p["in"]) + ":" + asString(p["name"])
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+
+	op := &replayOperation{
+		Source:  source,
+		Name:    method + " " + template,
+		Request: &replayRequest{Method: method, URL: &url.URL{}, Header: make(http.Header)},
+	}
+	query := url.Values{}
+	form := url.Values{}
+	var jsonBody interface{}
+	segs := strings.Split(template, "/")
+	for _, key := range order {
+		p := params[key]
+		name := asString(p["name"])
+		switch asString(p["in"]) {
+		case "path":
+			value := replayValueText(d.paramSample(p))
+			if value == "" {
+				value = "1"
+			}
+			for i, seg := range segs {
+				if seg == "{"+name+"}" {
+					segs[i] = value
+					op.Params = append(op.Params, replayParam{In: ParamInPath, Name: name, Value: value, segment: i})
+				}
+			}
+		case "query":
+			query.Set(name, replayValueText(d.paramSample(p)))
+		case "formData":
+			form.Set(name, replayValueText(d.paramSample(p)))
+		case "body":
+			jsonBody = d.sample(asMap(p["schema"]), 0)
+		}
+	}
+	// 路径参数未声明时按普通值填充，保证请求路径有效
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segs[i] = "1"
+		}
+	}
+	op.path = strings.Join(segs, "/")
+	op.Request.URL.RawQuery = query.Encode()
+
+	if body := d.resolve(asMap(operation["requestBody"]), 0); body != nil {
+		content := asMap(body["content"])
+		mediaTypes := make([]string, 0, len(content))
+		for mt := range content {
+			mediaTypes = append(mediaTypes, mt)
+		}
+		sort.Strings(mediaTypes)
+		for _, mt := range mediaTypes {
+			media := asMap(content[mt])
+			switch {
+			case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+				jsonBody = d.mediaSample(media)
+			case mt == "application/x-www-form-urlencoded" && jsonBody == nil:
+				for k, v := range asMap(d.mediaSample(media)) {
+					form.Set(k, replayValueText(v))
+				}
+			default:
+				continue
+			}
+			break
+		}
+	}
+	switch {
+	case jsonBody != nil:
+		if data, err := json.Marshal(jsonBody); err == nil {
+			op.Request.Body = data
+			op.Request.Header.Set("Content-Type", "application/json")
+		}
+	case len(form) > 0:
+		op.Request.Body = []byte(form.Encode())
+		op.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	op.Params = append(op.Params, queryParams(&url.URL{RawQuery: op.Request.URL.RawQuery})...)
+	op.Params = append(op.Params, bodyParams(op.Request)...)
+	op.Params = limitParams(op.Params)
+	return op
+}
+
+// resolve 展开本文档内的 $ref
+func (d *openAPIDoc) resolve(node map[string]interface{}, depth int) map[string]interface{} {
+	for node != nil && depth <= maxSchemaDepth {
+		ref := asString(node["$ref"])
+		if ref == "" {
+			return node
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil
+		}
+		var cur interface{} = d.root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			cur = asMap(cur)[part]
+		}
+		node = asMap(cur)
+		depth++
+	}
+	return nil
+}
+
+// paramSample 参数的示例值，Swagger 2.0 的类型定义在参数本身
+func (d *openAPIDoc) paramSample(p map[string]interface{}) interface{} {
+	if v, ok := p["example"]; ok {
+		return v
+	}
+	for _, ex := range asMap(p["examples"]) {
+		if v, ok := asMap(ex)["value"]; ok {
+			return v
+		}
+	}
+	if schema := asMap(p["schema"]); schema != nil {
+		return d.sample(schema, 0)
+	}
+	return d.sample(p, 0)
+}
+
+// mediaSample 请求体的示例值
+func (d *openAPIDoc) mediaSample(media map[string]interface{}) interface{} {
+	if v, ok := media["example"]; ok {
+		return v
+	}
+	names := make([]string, 0)
+	examples := asMap(media["examples"])
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v, ok := d.resolve(asMap(examples[name]), 0)["value"]; ok {
+			return v
+		}
+	}
+	return d.sample(asMap(media["schema"]), 0)
+}
+
+// sample 按 schema 生成示例值
+func (d *openAPIDoc) sample(schema map[string]interface{}, depth int) interface{} {
+	schema = d.resolve(schema, 0)
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default"} {
+		if v, ok := schema[key]; ok {
+			return v
+		}
+	}
+	if enum := asList(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+	if all := asList(schema["allOf"]); len(all) > 0 {
+		merged := make(map[string]interface{})
+		for _, sub := range all {
+			for k, v := range asMap(d.sample(asMap(sub), depth+1)) {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options := asList(schema[key]); len(options) > 0 {
+			return d.sample(asMap(options[0]), depth+1)
+		}
+	}
+
+	typ := asString(schema["type"])
+	if typ == "" && schema["properties"] != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		out := make(map[string]interface{})
+		for name, prop := range asMap(schema["properties"]) {
+			if asMap(prop)["readOnly"] == true {
+				continue
+			}
+			if v := d.sample(asMap(prop), depth+1); v != nil {
+				out[name] = v
+			}
+		}
+		return out
+	case "array":
+		if item := d.sample(asMap(schema["items"]), depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		return json.Number("1")
+	case "boolean":
+		return true
+	case "string":
+		switch asString(schema["format"]) {
+		case "uuid":
+			return "00000000-0000-4000-8000-000000000001"
+		case "date":
+			return "2024-01-01"
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "email":
+			return "user@example.com"
+		case "uri", "url":
+			return "https://example.com/"
+		}
+		return "test"
+	}
+	return nil
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func asList(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+func asString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
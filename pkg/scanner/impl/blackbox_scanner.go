@@ -0,0 +1,315 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/blackarbiter/go-sac/pkg/storage/minio"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+	"go.uber.org/zap"
+)
+
+// 黑盒测试任务选项中约定的流量文件字段
+const (
+	OptionFilePaths   = "file_paths"   // 已下载到本地的 HAR/OpenAPI 文件
+	OptionFileObjects = "file_objects" // MinIO 中的 HAR/OpenAPI 文件
+)
+
+// BlackBoxScanner 黑盒测试执行器，回放 HAR 流量或 OpenAPI 描述的接口并变异参数，按响应差异识别异常
+type BlackBoxScanner struct {
+	*BaseScanner
+	replay     config.BlackBoxConfig
+	workDir    string
+	keys       *crypt.KeyManager // 解密登录凭据，与任务服务共用 security.aes_key
+	downloader ObjectDownloader
+}
+
+// NewBlackBoxScanner 创建黑盒测试执行器
+func NewBlackBoxScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &BlackBoxScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeBlackBoxTesting)
+	s.replay, s.workDir = config.GetBlackBoxConfig()
+	s.keys = crypt.NewKeyManager([]byte(config.Security.AESKey), 0)
+
+	// 配置了 MinIO 时支持从对象存储拉取上传的流量文件
+	if mc := config.Storage.MinIO; mc.Endpoint != "" {
+		storage, err := minio.NewStorage(mc.Endpoint, mc.AccessKey, mc.SecretKey, mc.Bucket, mc.UseSSL)
+		if err != nil {
+			logger.Warn("minio client unavailable, file_objects option disabled", zap.Error(err))
+		} else {
+			s.downloader = storage
+		}
+	}
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeBlackBoxTesting,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *BlackBoxScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeBlackBoxTesting, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	var opts domain.BlackBoxOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+	mutationNames := opts.Mutations
+	if len(mutationNames) == 0 {
+		mutationNames = s.replay.Mutations
+	}
+	mutators, err := newReplayMutators(mutationNames)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 1. 加载流量文件并确定回放目标
+	files, err := s.loadTraffic(ctx, task, opts)
+	if err != nil {
+		return fail(err)
+	}
+	target, err := blackBoxTarget(opts, files)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 2. 回放与变异；流量中的请求来自已登录的会话，始终排除退出登录与删除类地址
+	exclude, err := compileExclusions(opts.Exclude, true)
+	if err != nil {
+		return fail(err)
+	}
+	engineOpts := []BlackBoxEngineOption{WithBlackBoxExclusions(exclude), WithForeignIDs(opts.ForeignIDs)}
+	if opts.Auth != nil {
+		session, err := NewDASTSession(opts.Auth, target, s.keys)
+		if err != nil {
+			return fail(err)
+		}
+		engineOpts = append(engineOpts, WithBlackBoxSession(session))
+	}
+
+	s.logger.Info("starting black-box testing",
+		zap.String("task_id", task.TaskID),
+		zap.String("target", target.String()),
+		zap.Int("files", len(files)),
+		zap.Bool("authenticated", opts.Auth != nil),
+		zap.Int("max_requests", s.replay.MaxRequests))
+
+	s.SetTargetSize(task, scanner.TargetSize{Items: s.replay.MaxRequests})
+	var report *BlackBoxReport
+	hostBreaker := s.circuitBreakers.ForHost(domain.ScanTypeBlackBoxTesting, target.Hostname())
+	err = s.ExecuteWithBreaker(ctx, task, hostBreaker, func(ctx context.Context) error {
+		var runErr error
+		report, runErr = NewBlackBoxEngine(s.replay, mutators, s.logger, engineOpts...).Run(ctx, target, files)
+		return runErr
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	result.SetSuccess(map[string]interface{}{
+		"target":           report.Target,
+		"sources":          report.Sources,
+		"operations":       report.Operations,
+		"skipped":          report.Skipped,
+		"requests":         report.Requests,
+		"budget_exhausted": report.BudgetExhausted,
+		"authenticated":    report.Authenticated,
+		"relogins":         report.Relogins,
+		"session_lost":     report.SessionLost,
+		"anomalies":        report.Anomalies,
+		"summary":          summarizeBlackBoxAnomalies(report.Anomalies),
+	})
+	result.SetFindings(s.findingReport(blackBoxFindings(report.Anomalies)))
+	return result, nil
+}
+
+// loadTraffic 读取本地流量文件，并下载对象存储中的流量文件到工作目录
+func (s *BlackBoxScanner) loadTraffic(ctx context.Context, task *domain.ScanTaskPayload, opts domain.BlackBoxOptions) ([]*trafficFile, error) {
+	paths := make([]string, 0, len(opts.FilePaths)+len(opts.FileObjects))
+	for _, p := range opts.FilePaths {
+		if p = strings.TrimSpace(p); p != "" {
+			resolved, err := s.resolveUploadPath(p)
+			if err != nil {
+				return nil, fmt.Errorf("traffic file unavailable: %w", err)
+			}
+			paths = append(paths, resolved)
+		}
+	}
+	if len(paths) == 0 && len(opts.FileObjects) == 0 {
+		return nil, fmt.Errorf("missing %s or %s in task options", OptionFilePaths, OptionFileObjects)
+	}
+	if len(opts.FileObjects) > 0 {
+		if s.downloader == nil {
+			return nil, fmt.Errorf("object storage not configured, cannot fetch %s", strings.Join(opts.FileObjects, ", "))
+		}
+		// 下载的文件解析后即删除
+		workDir, err := s.CreateWorkspace(s.workDir, task)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := os.RemoveAll(workDir); err != nil {
+				s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+			}
+		}()
+		for i, object := range opts.FileObjects {
+			dest := filepath.Join(workDir, fmt.Sprintf("%d-%s", i, sanitizePathComponent(filepath.Base(object))))
+			if err := s.downloader.DownloadFile(ctx, object, dest); err != nil {
+				return nil, fmt.Errorf("download traffic file %s failed: %w", object, err)
+			}
+			paths = append(paths, dest)
+		}
+	}
+	return s.parseTraffic(paths)
+}
+
+// parseTraffic 解析流量文件，超过大小上限的文件报错
+func (s *BlackBoxScanner) parseTraffic(paths []string) ([]*trafficFile, error) {
+	limit := int64(s.replay.MaxFileSizeMB) << 20
+	files := make([]*trafficFile, 0, len(paths))
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("traffic file unavailable: %w", err)
+		}
+		if info.Size() > limit {
+			return nil, fmt.Errorf("traffic file %s exceeds %d MB", filepath.Base(p), s.replay.MaxFileSizeMB)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parseTrafficFile(filepath.Base(p), data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// blackBoxTarget 回放目标：任务选项优先，其次为第一个带站点信息的流量文件
+func blackBoxTarget(opts domain.BlackBoxOptions, files []*trafficFile) (*url.URL, error) {
+	if strings.TrimSpace(opts.TargetURL) != "" || strings.TrimSpace(opts.DomainName) != "" {
+		return dastTarget(domain.DASTOptions{TargetURL: opts.TargetURL, DomainName: opts.DomainName})
+	}
+	for _, f := range files {
+		if f.Origin != nil {
+			return f.Origin, nil
+		}
+	}
+	return nil, fmt.Errorf("missing %s or %s in task options", OptionTargetURL, OptionDomainName)
+}
+
+// summarizeBlackBoxAnomalies 按严重等级和异常类型统计
+func summarizeBlackBoxAnomalies(anomalies []BlackBoxAnomaly) map[string]interface{} {
+	bySeverity := make(map[string]int)
+	byType := make(map[string]int)
+	for _, a := range anomalies {
+		bySeverity[a.Severity]++
+		byType[a.Type]++
+	}
+	return map[string]interface{}{
+		"total":       len(anomalies),
+		"by_severity": bySeverity,
+		"by_type":     byType,
+	}
+}
+
+// blackBoxFindings 转换为统一发现项，物理位置为请求 URL，逻辑位置为接口模板与参数
+func blackBoxFindings(anomalies []BlackBoxAnomaly) []domain.Finding {
+	out := make([]domain.Finding, 0, len(anomalies))
+	for _, a := range anomalies {
+		message := fmt.Sprintf("%s at %s", a.Type, a.Operation)
+		logical, kind := a.Operation, "operation"
+		if a.Parameter != "" {
+			message += fmt.Sprintf(" (%s parameter %s)", a.In, a.Parameter)
+			logical, kind = a.Operation+" "+a.In+":"+a.Parameter, "parameter"
+		}
+		props := map[string]interface{}{
+			"method":          a.Method,
+			"mutation":        a.Mutation,
+			"status":          a.Status,
+			"baseline_status": a.BaselineStatus,
+			"cvss_score":      a.CVSSScore,
+		}
+		if a.Payload != "" {
+			props["payload"] = a.Payload
+		}
+		if a.Evidence != "" {
+			props["evidence"] = a.Evidence
+		}
+		out = append(out, domain.Finding{
+			RuleID:      a.Type,
+			RuleName:    a.Type,
+			Severity:    a.Severity,
+			Message:     message,
+			Category:    "blackbox",
+			Location:    domain.FindingLocation{Path: a.URL, Logical: logical, LogicalKind: kind},
+			Remediation: a.Remediation,
+			Properties:  props,
+		})
+	}
+	return out
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *BlackBoxScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *BlackBoxScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *BlackBoxScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *BlackBoxScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *BlackBoxScanner) HealthCheck() error {
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,357 @@
+package scanner_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newReplayAPI 模拟一个存在越权、缺失认证、错误回显与 SQL 报错的 API，并记录收到的请求
+func newReplayAPI(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		hits []string
+	)
+	users := map[int]string{1: "alice", 2: "bob"}
+	authorized := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer alice-token"
+	}
+	writeJSON := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	mux := http.NewServeMux()
+	// 只校验登录，不校验对象归属
+	mux.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/users/"))
+		name, ok := users[id]
+		if err != nil || !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "name": name, "email": name + "@example.com"})
+	})
+	// 未校验登录
+	mux.HandleFunc("/api/orders", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"orders": []interface{}{map[string]interface{}{"id": 7, "total": 42}}})
+	})
+	// 请求体类型错误时回显堆栈
+	mux.HandleFunc("/api/cart", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		var item struct {
+			ItemID   int64 `json:"item_id"`
+			Quantity int64 `json:"quantity"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "panic: %v\n\ngoroutine 1 [running]:\nmain.addToCart()", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	})
+	// 拼接查询语句
+	mux.HandleFunc("/api/search", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		if strings.Contains(r.URL.Query().Get("q"), "'") {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "You have an error in your SQL syntax")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": []string{"shoes"}})
+	})
+	mux.HandleFunc("/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "up"})
+	})
+	mux.HandleFunc("/v1/users/", func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = "/api" + strings.TrimPrefix(r.URL.Path, "/v1")
+		mux.ServeHTTP(w, r)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), hits...)
+	}
+}
+
+// recordedHAR 在 shop.example.com 上录制的流量，夹带第三方请求、静态资源与删除请求
+const recordedHAR = `{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/users/1",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}, {"name": "Host", "value": "shop.example.com"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 1, \"name\": \"alice\"}"}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/orders?user_id=1",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json"}}},
+  {"request": {"method": "POST", "url": "https://shop.example.com/api/cart",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}, {"name": "Content-Type", "value": "application/json"}],
+    "postData": {"mimeType": "application/json", "text": "{\"item_id\": 5, \"quantity\": 2}"}},
+   "response": {"status": 200, "content": {"mimeType": "application/json"}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/search?q=shoes",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json"}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/users/1",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json"}}},
+  {"request": {"method": "DELETE", "url": "https://shop.example.com/api/cart?item_id=5",
+    "headers": [{"name": "Authorization", "value": "Bearer alice-token"}]},
+   "response": {"status": 204, "content": {}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/static/app.js", "headers": []},
+   "response": {"status": 200, "content": {"mimeType": "application/javascript"}}},
+  {"request": {"method": "POST", "url": "https://collect.analytics.example/v1/event", "headers": [],
+    "postData": {"mimeType": "application/json", "text": "{\"event\": \"view\"}"}},
+   "response": {"status": 204, "content": {}}}
+]}}`
+
+// userAPISpec 描述 /v1 下的用户接口，声明的服务器地址与测试站点不同
+const userAPISpec = `openapi: 3.0.3
+info: {title: users, version: "1.0"}
+servers:
+  - url: https://api.example.com/v1
+security:
+  - bearer: []
+paths:
+  /users/{userId}:
+    get:
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: integer, example: 1}}
+  /health:
+    get:
+      security: []
+components:
+  securitySchemes:
+    bearer: {type: http, scheme: bearer}
+`
+
+func newTestBlackBoxScanner(maxRequests int) *BlackBoxScanner {
+	cfg := &config.Config{}
+	cfg.Scanner.UploadRoot = os.TempDir()
+	cfg.Security.AESKey = testAESKey
+	cfg.Scanner.BlackBox.Timeout = 30 * time.Second
+	cfg.Scanner.BlackBox.Replay = config.BlackBoxConfig{
+		MaxRequests:    maxRequests,
+		RequestTimeout: 5 * time.Second,
+	}
+	return NewBlackBoxScanner(nil, zap.NewNop(), cfg).(*BlackBoxScanner)
+}
+
+func anomaliesByType(result *domain.ScanResult) map[string][]BlackBoxAnomaly {
+	out := make(map[string][]BlackBoxAnomaly)
+	for _, a := range result.Result["anomalies"].([]BlackBoxAnomaly) {
+		out[a.Type] = append(out[a.Type], a)
+	}
+	return out
+}
+
+func TestBlackBoxScanner_ReplaysHARWithMutations(t *testing.T) {
+	srv, hits := newReplayAPI(t)
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{"shop.har": recordedHAR})
+
+	result, err := newTestBlackBoxScanner(500).Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-blackbox",
+		AssetType: domain.AssetTypeUploadedFile,
+		Options: map[string]interface{}{
+			OptionTargetURL: srv.URL,
+			OptionFilePaths: []interface{}{filepath.Join(dir, "shop.har")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, srv.URL+"/", result.Result["target"])
+	assert.Equal(t, []TrafficSource{{Name: "shop.har", Format: TrafficFormatHAR, Operations: 6}}, result.Result["sources"])
+	assert.Equal(t, 4, result.Result["operations"])
+	assert.Equal(t, 2, result.Result["skipped"]) // 第三方站点与 DELETE 请求
+	assert.Equal(t, false, result.Result["budget_exhausted"])
+	assert.Equal(t, false, result.Result["authenticated"])
+
+	byType := anomaliesByType(result)
+
+	require.Len(t, byType[AnomalyIDOR], 1)
+	idor := byType[AnomalyIDOR][0]
+	assert.Equal(t, "GET /api/users/{users_id}", idor.Operation)
+	assert.Equal(t, "users_id", idor.Parameter)
+	assert.Equal(t, ParamInPath, idor.In)
+	assert.Equal(t, "2", idor.Payload)
+	assert.Equal(t, SeverityMedium, idor.Severity)
+	assert.Equal(t, srv.URL+"/api/users/1", idor.URL)
+	assert.Contains(t, idor.Evidence, "nonexistent id returned status 404")
+
+	require.Len(t, byType[AnomalyMissingAuth], 1)
+	assert.Equal(t, "GET /api/orders", byType[AnomalyMissingAuth][0].Operation)
+
+	require.Len(t, byType[AnomalyErrorDisclosure], 2)
+	for _, a := range byType[AnomalyErrorDisclosure] {
+		assert.Equal(t, "POST /api/cart", a.Operation)
+		assert.Equal(t, ParamInJSON, a.In)
+		assert.Contains(t, a.Evidence, "panic: json: cannot unmarshal")
+	}
+
+	require.Len(t, byType[VulnSQLInjection], 1)
+	assert.Equal(t, "q", byType[VulnSQLInjection][0].Parameter)
+	assert.Equal(t, SeverityHigh, byType[VulnSQLInjection][0].Severity)
+	assert.Empty(t, byType[AnomalyServerError], "server errors with a stack trace or SQL error are reported once")
+
+	for _, hit := range hits() {
+		assert.NotContains(t, hit, "DELETE")
+		assert.NotContains(t, hit, "/static/")
+		assert.NotContains(t, hit, "/v1/event")
+	}
+
+	findings := result.Findings()
+	require.Len(t, findings, 5)
+	for _, f := range findings {
+		assert.Equal(t, "blackbox", f.Category)
+		if f.RuleID == AnomalyIDOR {
+			assert.Equal(t, srv.URL+"/api/users/1", f.Location.Path)
+			assert.Equal(t, "GET /api/users/{users_id} path:users_id", f.Location.Logical)
+			assert.Equal(t, "parameter", f.Location.LogicalKind)
+		}
+	}
+}
+
+func TestBlackBoxScanner_OpenAPIWithSession(t *testing.T) {
+	srv, hits := newReplayAPI(t)
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{"users.yaml": userAPISpec})
+
+	result, err := newTestBlackBoxScanner(200).Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID: "task-blackbox-openapi",
+		Options: map[string]interface{}{
+			OptionTargetURL: srv.URL + "/v1",
+			OptionFilePaths: []interface{}{filepath.Join(dir, "users.yaml")},
+			"auth":          map[string]interface{}{"type": "bearer", "token": seal(t, srv.URL, "alice-token")},
+			"foreign_ids":   []interface{}{"2"},
+			"mutations":     []interface{}{MutationAuthRemoval, MutationIDOR},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Result["authenticated"])
+	assert.Equal(t, 2, result.Result["operations"])
+
+	byType := anomaliesByType(result)
+	assert.Empty(t, byType[AnomalyMissingAuth], "public operations are not tested without credentials")
+	require.Len(t, byType[AnomalyIDOR], 1)
+	idor := byType[AnomalyIDOR][0]
+	assert.Equal(t, "GET /users/{userId}", idor.Operation)
+	assert.Equal(t, SeverityHigh, idor.Severity)
+	assert.Equal(t, 8.1, idor.CVSSScore)
+
+	assert.Contains(t, hits(), "GET /v1/health")
+	assert.Contains(t, hits(), "GET /v1/users/2")
+}
+
+func TestBlackBoxScanner_BudgetExhausted(t *testing.T) {
+	srv, hits := newReplayAPI(t)
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{"shop.har": recordedHAR})
+
+	result, err := newTestBlackBoxScanner(5).Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID: "task-blackbox-budget",
+		Options: map[string]interface{}{
+			OptionTargetURL: srv.URL,
+			OptionFilePaths: []interface{}{filepath.Join(dir, "shop.har")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Result["budget_exhausted"])
+	assert.Equal(t, 5, result.Result["requests"])
+	assert.Len(t, hits(), 5)
+}
+
+func TestBlackBoxScanner_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{
+		"shop.har":   recordedHAR,
+		"notes.yaml": "title: not an api\n",
+	})
+	har := filepath.Join(dir, "shop.har")
+
+	for name, tc := range map[string]struct {
+		options map[string]interface{}
+		want    string
+	}{
+		"no files": {
+			options: map[string]interface{}{OptionTargetURL: "http://127.0.0.1:1"},
+			want:    "missing file_paths or file_objects",
+		},
+		"unknown mutation": {
+			options: map[string]interface{}{OptionFilePaths: []interface{}{har}, "mutations": []interface{}{"fuzz"}},
+			want:    "unknown black-box mutation: fuzz",
+		},
+		"object storage": {
+			options: map[string]interface{}{OptionFileObjects: []interface{}{"uploads/shop.har"}},
+			want:    "object storage not configured",
+		},
+		"unsupported file": {
+			options: map[string]interface{}{OptionFilePaths: []interface{}{filepath.Join(dir, "notes.yaml")}},
+			want:    "unsupported traffic file notes.yaml",
+		},
+		"missing file": {
+			options: map[string]interface{}{OptionFilePaths: []interface{}{filepath.Join(dir, "missing.har")}},
+			want:    "traffic file unavailable",
+		},
+		"outside upload root": {
+			options: map[string]interface{}{OptionFilePaths: []interface{}{"/etc/hosts"}},
+			want:    "outside upload root",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := newTestBlackBoxScanner(10).Scan(context.Background(), &domain.ScanTaskPayload{
+				TaskID:  "task-blackbox-invalid",
+				Options: tc.options,
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+			assert.Equal(t, "failed", result.Status)
+		})
+	}
+}
+
+func TestBlackBoxScanner_DefaultTargetFromHAR(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{"shop.har": recordedHAR})
+	files, err := newTestBlackBoxScanner(10).parseTraffic([]string{filepath.Join(dir, "shop.har")})
+	require.NoError(t, err)
+
+	target, err := blackBoxTarget(domain.BlackBoxOptions{}, files)
+	require.NoError(t, err)
+	assert.Equal(t, "https://shop.example.com/", target.String())
+
+	_, err = blackBoxTarget(domain.BlackBoxOptions{}, []*trafficFile{{Name: "spec.yaml"}})
+	assert.EqualError(t, err, "missing target_url or domain_name in task options")
+}
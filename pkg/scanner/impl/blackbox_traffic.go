@@ -0,0 +1,582 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 黑盒测试的流量文件格式
+const (
+	TrafficFormatHAR     = "har"
+	TrafficFormatOpenAPI = "openapi"
+)
+
+// 可变异参数的位置
+const (
+	ParamInQuery = "query"
+	ParamInPath  = "path"
+	ParamInForm  = "form"
+	ParamInJSON  = "json"
+)
+
+// 单个请求最多变异的参数数，避免大请求体耗尽请求预算
+const maxReplayParams = 32
+
+// maxJSONParamDepth JSON 请求体参数提取的最大嵌套深度
+const maxJSONParamDepth = 8
+
+var (
+	// idNamePattern 对象 ID 类参数名，按参数名最后一段匹配
+	idNamePattern = regexp.MustCompile(`(?i)(^|[_-])(id|uuid|guid)$|[a-z0-9]Id$|ID$`)
+	// idValuePattern 对象 ID 类取值：数字、UUID 与 24 位十六进制 ObjectId
+	idValuePattern = regexp.MustCompile(`(?i)^([0-9]{1,19}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|[0-9a-f]{24})$`)
+	// credentialHeaderPattern 携带凭据的请求头
+	credentialHeaderPattern = regexp.MustCompile(`(?i)^(authorization|proxy-authorization|cookie|x-[a-z-]*(api-?key|token|auth)[a-z-]*)$`)
+	// credentialParamPattern 携带凭据的查询参数
+	credentialParamPattern = regexp.MustCompile(`(?i)^(access_?token|api_?key|apikey|auth_?token|token|session_?id|sid)$`)
+)
+
+// replayDropHeaders 回放时不沿用的录制请求头：连接相关、由客户端重新计算或会导致缓存命中的头
+var replayDropHeaders = map[string]bool{
+	"host": true, "content-length": true, "connection": true, "keep-alive": true,
+	"transfer-encoding": true, "upgrade": true, "te": true, "proxy-connection": true,
+	"accept-encoding": true, "if-none-match": true, "if-modified-since": true,
+}
+
+// staticExtensions 不回放的静态资源
+var staticExtensions = map[string]bool{
+	".js": true, ".mjs": true, ".css": true, ".map": true, ".png": true, ".jpg": true, ".jpeg": true,
+	".gif": true, ".svg": true, ".ico": true, ".webp": true, ".woff": true, ".woff2": true, ".ttf": true,
+	".eot": true, ".mp4": true, ".webm": true, ".mp3": true,
+}
+
+// replayRequest 回放的请求
+type replayRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+func (r *replayRequest) clone() *replayRequest {
+	u := *r.URL
+	return &replayRequest{Method: r.Method, URL: &u, Header: r.Header.Clone(), Body: append([]byte(nil), r.Body...)}
+}
+
+// replayParam 可变异的请求参数
+type replayParam struct {
+	In       string
+	Name     string
+	Value    interface{}   // string、json.Number、bool 或 nil
+	segment  int           // 路径参数在接口路径中的段序号
+	jsonPath []interface{} // JSON 请求体中的位置，string 为对象键，int 为数组下标
+}
+
+// isID 判断参数是否指向对象 ID，路径参数只看取值
+func (p replayParam) isID() bool {
+	text, ok := p.Value.(string)
+	if n, isNumber := p.Value.(json.Number); isNumber {
+		text, ok = n.String(), true
+	}
+	if !ok || !idValuePattern.MatchString(text) {
+		return false
+	}
+	if p.In == ParamInPath {
+		return true
+	}
+	name := p.Name
+	if i := strings.LastIndexAny(name, ".]"); i >= 0 {
+		name = name[i+1:]
+	}
+	return idNamePattern.MatchString(name)
+}
+
+// replayOperation 去重后的待测接口
+type replayOperation struct {
+	Source      string // 来源文件
+	Name        string // 接口模板，如 GET /users/{users_id}
+	Request     *replayRequest
+	Params      []replayParam
+	Credentials bool // 请求中带有录制的凭据
+	Public      bool // OpenAPI 显式声明无需认证
+
+	origin string // HAR 中录制的站点，改写目标前有效
+	prefix string // 接口路径前缀（OpenAPI servers 或 basePath）
+	path   string // 接口路径，路径参数已替换为取值
+}
+
+// key 按接口模板与参数名去重
+func (op *replayOperation) key() string {
+	names := make([]string, 0, len(op.Params))
+	for _, p := range op.Params {
+		names = append(names, p.In+":"+p.Name)
+	}
+	sort.Strings(names)
+	return op.Name + "?" + strings.Join(names, "&")
+}
+
+// with 返回把参数 p 替换为 value 的请求
+func (op *replayOperation) with(p replayParam, value interface{}) *replayRequest {
+	req := op.Request.clone()
+	text := replayValueText(value)
+	switch p.In {
+	case ParamInQuery:
+		q := req.URL.Query()
+		q.Set(p.Name, text)
+		req.URL.RawQuery = q.Encode()
+	case ParamInPath:
+		segs := strings.Split(op.path, "/")
+		segs[p.segment] = text
+		req.URL.Path = op.prefix + strings.Join(segs, "/")
+		req.URL.RawPath = ""
+	case ParamInForm:
+		form, err := url.ParseQuery(string(req.Body))
+		if err == nil {
+			form.Set(p.Name, text)
+			req.Body = []byte(form.Encode())
+		}
+	case ParamInJSON:
+		if body, err := setJSONValue(req.Body, p.jsonPath, value); err == nil {
+			req.Body = body
+		}
+	}
+	return req
+}
+
+// withoutCredentials 返回去掉录制凭据的请求
+func (op *replayOperation) withoutCredentials() *replayRequest {
+	req := op.Request.clone()
+	for name := range req.Header {
+		if credentialHeaderPattern.MatchString(name) {
+			req.Header.Del(name)
+		}
+	}
+	q := req.URL.Query()
+	for name := range q {
+		if credentialParamPattern.MatchString(name) {
+			q.Del(name)
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+	return req
+}
+
+// TrafficSource 流量文件概要
+type TrafficSource struct {
+	Name       string `json:"name"`
+	Format     string `json:"format"`
+	Operations int    `json:"operations"`
+}
+
+// trafficFile 解析后的流量文件
+type trafficFile struct {
+	Name       string
+	Format     string
+	Origin     *url.URL // 默认回放目标：HAR 中请求最多的站点，OpenAPI 中第一个绝对地址的 server
+	Operations []*replayOperation
+	IDs        map[string]bool // 流量中出现过的对象 ID，属于录制账号自身
+}
+
+// rebase 把接口改写到回放目标，HAR 中不属于默认站点的请求返回 nil
+func (f *trafficFile) rebase(op *replayOperation, target *url.URL) *replayOperation {
+	if f.Format == TrafficFormatHAR && (f.Origin == nil || op.origin != originURL(f.Origin)) {
+		return nil
+	}
+	out := *op
+	out.Request = op.Request.clone()
+	out.Request.URL.Scheme = target.Scheme
+	out.Request.URL.Host = target.Host
+	if f.Format == TrafficFormatOpenAPI && target.Path != "/" {
+		out.prefix = strings.TrimSuffix(target.Path, "/")
+	}
+	out.Request.URL.Path = out.prefix + out.path
+	out.Request.URL.RawPath = ""
+	return &out
+}
+
+// parseTrafficFile 识别并解析 HAR 或 OpenAPI 文件，OpenAPI 支持 JSON 与 YAML
+func parseTrafficFile(name string, data []byte) (*trafficFile, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var probe struct {
+			Log json.RawMessage `json:"log"`
+		}
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		if len(probe.Log) > 0 {
+			return parseHAR(name, probe.Log)
+		}
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if doc["openapi"] != nil || doc["swagger"] != nil {
+		return parseOpenAPI(name, doc)
+	}
+	return nil, fmt.Errorf("unsupported traffic file %s: expected HAR or OpenAPI", name)
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harLog struct {
+	Entries []struct {
+		Request struct {
+			Method   string         `json:"method"`
+			URL      string         `json:"url"`
+			Headers  []harNameValue `json:"headers"`
+			PostData *struct {
+				MimeType string         `json:"mimeType"`
+				Text     string         `json:"text"`
+				Params   []harNameValue `json:"params"`
+			} `json:"postData"`
+		} `json:"request"`
+		Response struct {
+			Status  int `json:"status"`
+			Content struct {
+				MimeType string `json:"mimeType"`
+				Text     string `json:"text"`
+				Encoding string `json:"encoding"`
+			} `json:"content"`
+		} `json:"response"`
+	} `json:"entries"`
+}
+
+// parseHAR 解析 HAR 录制文件，跳过静态资源，同一接口只保留第一次出现的请求
+func parseHAR(name string, data []byte) (*trafficFile, error) {
+	var log harLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	f := &trafficFile{Name: name, Format: TrafficFormatHAR, IDs: make(map[string]bool)}
+	seen := make(map[string]bool)
+	origins := make(map[string]int)
+	for _, e := range log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		u.Fragment = ""
+		method := strings.ToUpper(e.Request.Method)
+		if method == "" || method == http.MethodConnect {
+			continue
+		}
+		if staticExtensions[strings.ToLower(path.Ext(u.Path))] || isStaticMIME(e.Response.Content.MimeType) {
+			continue
+		}
+
+		req := &replayRequest{Method: method, URL: u, Header: make(http.Header)}
+		for _, h := range e.Request.Headers {
+			if h.Name == "" || strings.HasPrefix(h.Name, ":") || replayDropHeaders[strings.ToLower(h.Name)] {
+				continue
+			}
+			req.Header.Add(h.Name, h.Value)
+		}
+		if pd := e.Request.PostData; pd != nil {
+			req.Body = []byte(pd.Text)
+			if pd.Text == "" && len(pd.Params) > 0 {
+				form := url.Values{}
+				for _, p := range pd.Params {
+					form.Add(p.Name, p.Value)
+				}
+				req.Body = []byte(form.Encode())
+			}
+			if req.Header.Get("Content-Type") == "" && pd.MimeType != "" {
+				req.Header.Set("Content-Type", pd.MimeType)
+			}
+		}
+
+		op := &replayOperation{Source: name, Request: req, origin: originURL(u), path: u.Path}
+		template := harPathParams(op)
+		op.Name = method + " " + template
+		op.Params = append(op.Params, queryParams(u)...)
+		op.Params = append(op.Params, bodyParams(req)...)
+		op.Params = limitParams(op.Params)
+		for header := range req.Header {
+			if credentialHeaderPattern.MatchString(header) {
+				op.Credentials = true
+			}
+		}
+		for param := range u.Query() {
+			if credentialParamPattern.MatchString(param) {
+				op.Credentials = true
+			}
+		}
+		for _, p := range op.Params {
+			if p.isID() {
+				f.IDs[replayValueText(p.Value)] = true
+			}
+		}
+		collectResponseIDs(f.IDs, e.Response.Content.MimeType, e.Response.Content.Text, e.Response.Content.Encoding)
+
+		origins[op.origin]++
+		if key := op.origin + " " + op.key(); !seen[key] {
+			seen[key] = true
+			f.Operations = append(f.Operations, op)
+		}
+	}
+
+	// 默认回放目标为请求最多的站点，数量相同时取先出现的
+	best := 0
+	for _, op := range f.Operations {
+		if n := origins[op.origin]; n > best {
+			best = n
+			f.Origin, _ = url.Parse(op.origin)
+		}
+	}
+	return f, nil
+}
+
+func isStaticMIME(mimeType string) bool {
+	mt, _, _ := mime.ParseMediaType(mimeType)
+	switch {
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "font/"), strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "audio/"):
+		return true
+	case mt == "text/css", mt == "text/javascript", mt == "application/javascript":
+		return true
+	}
+	return false
+}
+
+// harPathParams 把 ID 形式的路径段识别为路径参数，返回接口模板路径
+func harPathParams(op *replayOperation) string {
+	segs := strings.Split(op.path, "/")
+	template := make([]string, len(segs))
+	for i, seg := range segs {
+		template[i] = seg
+		if i == 0 || !idValuePattern.MatchString(seg) {
+			continue
+		}
+		name := "id"
+		if prev := segs[i-1]; i > 1 && prev != "" && !idValuePattern.MatchString(prev) {
+			name = prev + "_id"
+		}
+		template[i] = "{" + name + "}"
+		op.Params = append(op.Params, replayParam{In: ParamInPath, Name: name, Value: seg, segment: i})
+	}
+	return strings.Join(template, "/")
+}
+
+// queryParams 提取查询参数，凭据参数不参与变异
+func queryParams(u *url.URL) []replayParam {
+	q := u.Query()
+	names := make([]string, 0, len(q))
+	for name := range q {
+		if !credentialParamPattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	out := make([]replayParam, 0, len(names))
+	for _, name := range names {
+		out = append(out, replayParam{In: ParamInQuery, Name: name, Value: q.Get(name)})
+	}
+	return out
+}
+
+// bodyParams 按 Content-Type 提取表单字段或 JSON 标量字段，其它请求体不变异
+func bodyParams(req *replayRequest) []replayParam {
+	if len(req.Body) == 0 {
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(req.Body))
+		if err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(form))
+		for name := range form {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out := make([]replayParam, 0, len(names))
+		for _, name := range names {
+			out = append(out, replayParam{In: ParamInForm, Name: name, Value: form.Get(name)})
+		}
+		return out
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		v, err := decodeJSONBody(req.Body)
+		if err != nil {
+			return nil
+		}
+		var out []replayParam
+		walkJSONParams(v, nil, "", 0, &out)
+		return out
+	}
+	return nil
+}
+
+func walkJSONParams(v interface{}, at []interface{}, name string, depth int, out *[]replayParam) {
+	if depth > maxJSONParamDepth {
+		return
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if name != "" {
+				child = name + "." + k
+			}
+			walkJSONParams(x[k], appendPath(at, k), child, depth+1, out)
+		}
+	case []interface{}:
+		// 数组只变异第一个元素
+		if len(x) > 0 {
+			walkJSONParams(x[0], appendPath(at, 0), name+"[0]", depth+1, out)
+		}
+	default:
+		if name != "" {
+			*out = append(*out, replayParam{In: ParamInJSON, Name: name, Value: x, jsonPath: at})
+		}
+	}
+}
+
+func appendPath(at []interface{}, step interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(at)+1), at...), step)
+}
+
+func limitParams(params []replayParam) []replayParam {
+	if len(params) > maxReplayParams {
+		return params[:maxReplayParams]
+	}
+	return params
+}
+
+// decodeJSONBody 解码 JSON，数字保留为 json.Number 以便原样回写
+func decodeJSONBody(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// setJSONValue 替换 JSON 中 at 位置的值
+func setJSONValue(data []byte, at []interface{}, value interface{}) ([]byte, error) {
+	root, err := decodeJSONBody(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(at) == 0 {
+		return json.Marshal(value)
+	}
+	cur := root
+	for i, step := range at {
+		last := i == len(at)-1
+		switch key := step.(type) {
+		case string:
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path mismatch at %v", step)
+			}
+			if last {
+				m[key] = value
+			} else {
+				cur = m[key]
+			}
+		case int:
+			list, ok := cur.([]interface{})
+			if !ok || key >= len(list) {
+				return nil, fmt.Errorf("json path mismatch at %v", step)
+			}
+			if last {
+				list[key] = value
+			} else {
+				cur = list[key]
+			}
+		}
+	}
+	return json.Marshal(root)
+}
+
+// collectResponseIDs 记录录制响应中 ID 字段的取值，这些对象属于录制账号
+func collectResponseIDs(ids map[string]bool, mimeType, text, encoding string) {
+	mt, _, _ := mime.ParseMediaType(mimeType)
+	if text == "" || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+		return
+	}
+	data := []byte(text)
+	if encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return
+		}
+		data = decoded
+	}
+	v, err := decodeJSONBody(data)
+	if err != nil {
+		return
+	}
+	var params []replayParam
+	walkAllJSONParams(v, "", 0, &params)
+	for _, p := range params {
+		if p.isID() {
+			ids[replayValueText(p.Value)] = true
+		}
+	}
+}
+
+// walkAllJSONParams 与 walkJSONParams 相同但遍历数组的全部元素，用于收集响应中的 ID
+func walkAllJSONParams(v interface{}, name string, depth int, out *[]replayParam) {
+	if depth > maxJSONParamDepth {
+		return
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, child := range x {
+			walkAllJSONParams(child, k, depth+1, out)
+		}
+	case []interface{}:
+		for _, item := range x {
+			walkAllJSONParams(item, name, depth+1, out)
+		}
+	default:
+		*out = append(*out, replayParam{In: ParamInJSON, Name: name, Value: x})
+	}
+}
+
+// replayValueText 参数值的文本形式
+func replayValueText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,117 @@
+package scanner_impl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrafficFile_HAR(t *testing.T) {
+	f, err := parseTrafficFile("shop.har", []byte(recordedHAR))
+	require.NoError(t, err)
+	assert.Equal(t, TrafficFormatHAR, f.Format)
+	assert.Equal(t, "https://shop.example.com/", f.Origin.String())
+
+	names := make([]string, 0, len(f.Operations))
+	for _, op := range f.Operations {
+		names = append(names, op.Name)
+	}
+	// 静态资源跳过，重复的 GET /api/users/1 只保留一次
+	assert.Equal(t, []string{
+		"GET /api/users/{users_id}",
+		"GET /api/orders",
+		"POST /api/cart",
+		"GET /api/search",
+		"DELETE /api/cart",
+		"POST /v1/event",
+	}, names)
+
+	users := f.Operations[0]
+	assert.True(t, users.Credentials)
+	assert.Empty(t, users.Request.Header.Get("Host"))
+	assert.Equal(t, []replayParam{{In: ParamInPath, Name: "users_id", Value: "1", segment: 3}}, users.Params)
+
+	cart := f.Operations[2]
+	require.Len(t, cart.Params, 2)
+	assert.Equal(t, "item_id", cart.Params[0].Name)
+	assert.Equal(t, json.Number("5"), cart.Params[0].Value)
+	assert.True(t, cart.Params[0].isID())
+	assert.False(t, cart.Params[1].isID())
+
+	assert.True(t, f.IDs["1"])
+	assert.True(t, f.IDs["5"])
+}
+
+func TestParseTrafficFile_Swagger(t *testing.T) {
+	spec := `{
+  "swagger": "2.0",
+  "host": "legacy.example.com",
+  "basePath": "/api",
+  "schemes": ["http"],
+  "paths": {
+    "/accounts/{accountId}/transfers": {
+      "parameters": [{"name": "accountId", "in": "path", "type": "string", "format": "uuid"}],
+      "post": {
+        "parameters": [
+          {"name": "dry_run", "in": "query", "type": "boolean"},
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Transfer"}}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "Transfer": {
+      "type": "object",
+      "properties": {
+        "amount": {"type": "number"},
+        "currency": {"type": "string", "enum": ["EUR", "USD"]},
+        "id": {"type": "integer", "readOnly": true}
+      }
+    }
+  }
+}`
+	f, err := parseTrafficFile("legacy.json", []byte(spec))
+	require.NoError(t, err)
+	assert.Equal(t, TrafficFormatOpenAPI, f.Format)
+	assert.Equal(t, "http://legacy.example.com/", f.Origin.String())
+	require.Len(t, f.Operations, 1)
+
+	target, _ := url.Parse("https://staging.example.com/")
+	op := f.rebase(f.Operations[0], target)
+	assert.Equal(t, "POST /accounts/{accountId}/transfers", op.Name)
+	assert.Equal(t, "https://staging.example.com/api/accounts/00000000-0000-4000-8000-000000000001/transfers?dry_run=true", op.Request.URL.String())
+	assert.JSONEq(t, `{"amount": 1, "currency": "EUR"}`, string(op.Request.Body))
+	assert.Equal(t, "application/json", op.Request.Header.Get("Content-Type"))
+
+	mutated := op.with(op.Params[0], "11111111-1111-4111-8111-111111111111")
+	assert.Equal(t, "/api/accounts/11111111-1111-4111-8111-111111111111/transfers", mutated.URL.Path)
+	for _, p := range op.Params {
+		if p.Name == "amount" {
+			assert.JSONEq(t, `{"amount": "abc", "currency": "EUR"}`, string(op.with(p, "abc").Body))
+		}
+	}
+}
+
+func TestParseTrafficFile_Unsupported(t *testing.T) {
+	_, err := parseTrafficFile("notes.json", []byte(`{"title": "notes"}`))
+	assert.EqualError(t, err, "unsupported traffic file notes.json: expected HAR or OpenAPI")
+
+	_, err = parseTrafficFile("empty.yaml", []byte("openapi: 3.0.0\n"))
+	assert.EqualError(t, err, "parse empty.yaml: openapi document has no paths")
+}
+
+func TestResponseSimilarity(t *testing.T) {
+	jsonResp := func(body string) *Response {
+		return &Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(body)}
+	}
+	alice := jsonResp(`{"id": 1, "name": "alice", "tags": ["a", "b"]}`)
+	bob := jsonResp(`{"id": 2, "name": "bob", "tags": ["c"]}`)
+	errorBody := jsonResp(`{"error": "not found"}`)
+
+	assert.Equal(t, 1.0, responseSimilarity(alice, bob))
+	assert.Less(t, responseSimilarity(alice, errorBody), 0.5)
+}
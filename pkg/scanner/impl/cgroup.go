@@ -0,0 +1,300 @@
+package scanner_impl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// ErrOOMKilled 子进程因超出 memory.max 被内核 OOM 终止
+var ErrOOMKilled = errors.New("killed by cgroup OOM")
+
+// ErrCgroupUnavailable cgroupfs 不可用，扫描进程将不受资源限制
+var ErrCgroupUnavailable = errors.New("cgroup v2 unavailable")
+
+// cpu.max 使用的调度周期（微秒）
+const cgroupCPUPeriod = 100000
+
+// cgroupNameSanitizer 控制组目录名只保留安全字符
+var cgroupNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// CgroupStats 单次执行的资源使用情况
+type CgroupStats struct {
+	MemoryPeakBytes int64 // memory.peak，内核不支持时取 memory.current
+	OOMKills        int   // memory.events 中的 oom_kill 次数
+	CPUUsageUsec    int64 // cpu.stat 中的 usage_usec
+}
+
+// Cgroup 单次执行的控制组
+type Cgroup interface {
+	// Path 控制组目录，启动时通过 CLONE_INTO_CGROUP 直接在其中创建子进程
+	Path() string
+	Apply(pid int) error
+	Stats() (CgroupStats, error)
+	Cleanup() error
+}
+
+// CgroupManager 按资源画像为每次执行创建独立的控制组
+type CgroupManager interface {
+	Create(execID string, profile scanner.ResourceProfile) (Cgroup, error)
+	// HealthCheck cgroupfs 不可用时返回原因，调用方据此降级为不限制资源
+	HealthCheck() error
+}
+
+// CgroupV2Manager 基于 cgroup v2 统一层级的实现，root 可指向伪造目录用于测试
+type CgroupV2Manager struct {
+	root        string
+	parent      string
+	pidsMax     int
+	controllers map[string]bool
+	initErr     error
+	logger      *zap.Logger
+}
+
+// NewCgroupManagerFromConfig 按配置创建 cgroup 管理器，未启用时返回 nil
+func NewCgroupManagerFromConfig(cfg *config.Config, logger *zap.Logger) CgroupManager {
+	cg := cfg.GetCgroupConfig()
+	if !cg.Enabled {
+		return nil
+	}
+	return NewCgroupV2Manager(cg, logger)
+}
+
+// NewCgroupV2Manager 创建 cgroup v2 管理器，初始化失败时仍返回实例并在 HealthCheck 中报告原因
+func NewCgroupV2Manager(cfg config.CgroupConfig, logger *zap.Logger) *CgroupV2Manager {
+	m := &CgroupV2Manager{
+		root:    cfg.Root,
+		parent:  filepath.Clean(strings.Trim(cfg.Parent, "/")),
+		pidsMax: cfg.PidsMax,
+		logger:  logger,
+	}
+	if m.initErr = m.init(); m.initErr != nil {
+		logger.Warn("cgroup v2 unavailable, scanner processes will run without resource limits",
+			zap.String("root", m.root),
+			zap.Error(m.initErr))
+	}
+	return m
+}
+
+// init 校验 cgroup v2 挂载，创建父控制组并逐级开启 cpu/memory/pids 控制器
+func (m *CgroupV2Manager) init() error {
+	if m.parent == "." || strings.HasPrefix(m.parent, "..") {
+		return fmt.Errorf("%w: invalid parent cgroup %q", ErrCgroupUnavailable, m.parent)
+	}
+	data, err := os.ReadFile(filepath.Join(m.root, "cgroup.controllers"))
+	if err != nil {
+		return fmt.Errorf("%w: %s is not a cgroup v2 mount: %v", ErrCgroupUnavailable, m.root, err)
+	}
+	available := make(map[string]bool)
+	for _, c := range strings.Fields(string(data)) {
+		available[c] = true
+	}
+
+	var enable []string
+	for _, c := range []string{"cpu", "memory", "pids"} {
+		if available[c] {
+			enable = append(enable, "+"+c)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(m.root, m.parent), 0o755); err != nil {
+		return fmt.Errorf("%w: %v", ErrCgroupUnavailable, err)
+	}
+	// 控制器需从根到父控制组逐级下放；根控制组在容器内可能只读，其下放结果以父控制组为准
+	dir := m.root
+	for _, part := range strings.Split(m.parent, string(filepath.Separator)) {
+		if len(enable) > 0 {
+			err := writeCgroupFile(dir, "cgroup.subtree_control", strings.Join(enable, " "))
+			if err != nil && dir != m.root {
+				return fmt.Errorf("%w: enable controllers in %s: %v", ErrCgroupUnavailable, dir, err)
+			}
+		}
+		dir = filepath.Join(dir, part)
+	}
+	if len(enable) > 0 {
+		if err := writeCgroupFile(dir, "cgroup.subtree_control", strings.Join(enable, " ")); err != nil {
+			return fmt.Errorf("%w: enable controllers in %s: %v", ErrCgroupUnavailable, dir, err)
+		}
+	}
+
+	// 实际可用的控制器以父控制组的 cgroup.controllers 为准，伪造目录中不存在时沿用根控制组的列表
+	m.controllers = available
+	if data, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers")); err == nil {
+		m.controllers = make(map[string]bool)
+		for _, c := range strings.Fields(string(data)) {
+			m.controllers[c] = true
+		}
+	}
+	return nil
+}
+
+// HealthCheck 实现 CgroupManager 接口
+func (m *CgroupV2Manager) HealthCheck() error {
+	return m.initErr
+}
+
+// Create 为一次执行创建子控制组：MaxCPU -> cpu.max，MemoryMB -> memory.max，并设置 pids.max
+func (m *CgroupV2Manager) Create(execID string, profile scanner.ResourceProfile) (Cgroup, error) {
+	if m.initErr != nil {
+		return nil, m.initErr
+	}
+	name := cgroupNameSanitizer.ReplaceAllString(execID, "_")
+	if name == "" || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid cgroup name %q", execID)
+	}
+	dir := filepath.Join(m.root, m.parent, name)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cgroup %s failed: %w", dir, err)
+	}
+	cg := &cgroupV2{dir: dir}
+
+	type limit struct {
+		file     string
+		value    string
+		optional bool // 旧内核不存在的接口文件
+	}
+	var limits []limit
+	if profile.MaxCPU > 0 && m.controllers["cpu"] {
+		limits = append(limits, limit{file: "cpu.max", value: fmt.Sprintf("%d %d", profile.MaxCPU*cgroupCPUPeriod, cgroupCPUPeriod)})
+	}
+	if profile.MemoryMB > 0 && m.controllers["memory"] {
+		limits = append(limits,
+			limit{file: "memory.max", value: strconv.FormatInt(int64(profile.MemoryMB)<<20, 10)},
+			limit{file: "memory.swap.max", value: "0", optional: true},
+			// OOM 时终止整个控制组，避免残留半死的子进程
+			limit{file: "memory.oom.group", value: "1", optional: true},
+		)
+	}
+	if m.pidsMax > 0 && m.controllers["pids"] {
+		limits = append(limits, limit{file: "pids.max", value: strconv.Itoa(m.pidsMax)})
+	}
+	for _, l := range limits {
+		if err := writeCgroupFile(dir, l.file, l.value); err != nil {
+			if l.optional {
+				continue
+			}
+			_ = cg.Cleanup()
+			return nil, fmt.Errorf("set %s failed: %w", l.file, err)
+		}
+	}
+	return cg, nil
+}
+
+// cgroupV2 单次执行的子控制组
+type cgroupV2 struct {
+	dir string
+}
+
+// Path 实现 Cgroup 接口
+func (c *cgroupV2) Path() string {
+	return c.dir
+}
+
+// Apply 将已启动的进程移入控制组，仅在无法使用 CLONE_INTO_CGROUP 时使用
+func (c *cgroupV2) Apply(pid int) error {
+	f, err := os.OpenFile(filepath.Join(c.dir, "cgroup.procs"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(strconv.Itoa(pid))
+	return err
+}
+
+// Stats 读取峰值内存、OOM 次数与 CPU 用时
+func (c *cgroupV2) Stats() (CgroupStats, error) {
+	var stats CgroupStats
+	peak, err := readCgroupInt(c.dir, "memory.peak")
+	if errors.Is(err, os.ErrNotExist) {
+		peak, err = readCgroupInt(c.dir, "memory.current")
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return stats, err
+	}
+	stats.MemoryPeakBytes = peak
+
+	events, err := readCgroupKeyed(c.dir, "memory.events")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return stats, err
+	}
+	stats.OOMKills = int(events["oom_kill"])
+
+	cpu, err := readCgroupKeyed(c.dir, "cpu.stat")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return stats, err
+	}
+	stats.CPUUsageUsec = cpu["usage_usec"]
+	return stats, nil
+}
+
+// Cleanup 终止控制组内残留进程并删除控制组
+func (c *cgroupV2) Cleanup() error {
+	// cgroup.kill 需要 5.14+ 内核，不存在时跳过
+	if _, err := os.Stat(filepath.Join(c.dir, "cgroup.kill")); err == nil {
+		_ = writeCgroupFile(c.dir, "cgroup.kill", "1")
+	}
+	for attempt := 0; ; attempt++ {
+		err := os.Remove(c.dir)
+		switch {
+		case err == nil, errors.Is(err, os.ErrNotExist):
+			return nil
+		case errors.Is(err, syscall.EBUSY) && attempt < 10:
+			// 进程退出后控制组短暂保持占用
+			time.Sleep(50 * time.Millisecond)
+		default:
+			return fmt.Errorf("remove cgroup %s failed: %w", c.dir, err)
+		}
+	}
+}
+
+func writeCgroupFile(dir, file, value string) error {
+	f, err := os.OpenFile(filepath.Join(dir, file), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readCgroupInt(dir, file string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readCgroupKeyed 解析 "key value" 形式的接口文件
+func readCgroupKeyed(dir, file string) (map[string]int64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]int64)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values, sc.Err()
+}
@@ -0,0 +1,184 @@
+package scanner_impl
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeCgroupRoot 构造伪造的 cgroupfs 根目录
+func fakeCgroupRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpuset cpu io memory pids\n"), 0o644))
+	return root
+}
+
+// fakeCgroup 伪造 cgroupfs 上的控制组：真实 cgroupfs 中接口文件不阻止 rmdir，清理前先删除普通文件
+type fakeCgroup struct {
+	Cgroup
+}
+
+func (c fakeCgroup) Cleanup() error {
+	entries, err := os.ReadDir(c.Path())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			if err := os.Remove(filepath.Join(c.Path(), e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Cgroup.Cleanup()
+}
+
+func readFakeCgroupFile(t *testing.T, dir, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// recordingMetrics 记录上报的指标
+type recordingMetrics struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (m *recordingMetrics) Record(name string, value float64, _ map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] += value
+}
+
+func (m *recordingMetrics) Gauge(name string, value float64, _ map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] = value
+}
+
+// oomCgroupManager 在创建的控制组中写入 OOM 事件，模拟内核终止子进程
+type oomCgroupManager struct {
+	*CgroupV2Manager
+	dirs []string
+}
+
+func (m *oomCgroupManager) Create(execID string, profile scanner.ResourceProfile) (Cgroup, error) {
+	cg, err := m.CgroupV2Manager.Create(execID, profile)
+	if err != nil {
+		return nil, err
+	}
+	dir := cg.Path()
+	m.dirs = append(m.dirs, dir)
+	if err := os.WriteFile(filepath.Join(dir, "memory.peak"), []byte("268435456\n"), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0o644); err != nil {
+		return nil, err
+	}
+	return fakeCgroup{cg}, nil
+}
+
+func TestCgroupV2Manager_Lifecycle(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	m := NewCgroupV2Manager(config.CgroupConfig{Root: root, Parent: "/go-sac/scanners", PidsMax: 64}, zap.NewNop())
+	require.NoError(t, m.HealthCheck())
+
+	// 控制器逐级下放到父控制组
+	assert.Equal(t, "+cpu +memory +pids", readFakeCgroupFile(t, root, "cgroup.subtree_control"))
+	assert.Equal(t, "+cpu +memory +pids", readFakeCgroupFile(t, filepath.Join(root, "go-sac", "scanners"), "cgroup.subtree_control"))
+
+	created, err := m.Create("task/1:exec", scanner.ResourceProfile{MaxCPU: 2, MemoryMB: 256})
+	require.NoError(t, err)
+	cg := fakeCgroup{created}
+	dir := filepath.Join(root, "go-sac", "scanners", "task_1_exec")
+	assert.Equal(t, "200000 100000", readFakeCgroupFile(t, dir, "cpu.max"))
+	assert.Equal(t, "268435456", readFakeCgroupFile(t, dir, "memory.max"))
+	assert.Equal(t, "0", readFakeCgroupFile(t, dir, "memory.swap.max"))
+	assert.Equal(t, "1", readFakeCgroupFile(t, dir, "memory.oom.group"))
+	assert.Equal(t, "64", readFakeCgroupFile(t, dir, "pids.max"))
+
+	// 伪造目录不在 cgroup2 文件系统上，无法使用 CLONE_INTO_CGROUP，退回启动后移入
+	cmd := exec.Command("true")
+	release, ok := cloneIntoCgroup(cmd, cg)
+	release()
+	assert.False(t, ok)
+	assert.Nil(t, cmd.SysProcAttr)
+	require.NoError(t, cg.Apply(4242))
+	assert.Equal(t, "4242", readFakeCgroupFile(t, dir, "cgroup.procs"))
+
+	// 旧内核没有 memory.peak 时回退到 memory.current
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory.current"), []byte("1048576\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 1500000\nuser_usec 1000000\n"), 0o644))
+	stats, err := cg.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, CgroupStats{MemoryPeakBytes: 1 << 20, CPUUsageUsec: 1500000}, stats)
+
+	require.NoError(t, cg.Cleanup())
+	assert.NoDirExists(t, dir)
+	assert.DirExists(t, filepath.Join(root, "go-sac", "scanners"))
+}
+
+func TestCgroupV2Manager_Unavailable(t *testing.T) {
+	m := NewCgroupV2Manager(config.CgroupConfig{Root: t.TempDir(), Parent: "go-sac"}, zap.NewNop())
+	assert.ErrorIs(t, m.HealthCheck(), ErrCgroupUnavailable)
+	_, err := m.Create("exec", scanner.ResourceProfile{MemoryMB: 128})
+	assert.ErrorIs(t, err, ErrCgroupUnavailable)
+
+	m = NewCgroupV2Manager(config.CgroupConfig{Root: fakeCgroupRoot(t), Parent: "../escape"}, zap.NewNop())
+	assert.ErrorIs(t, m.HealthCheck(), ErrCgroupUnavailable)
+
+	cfg := &config.Config{}
+	assert.Nil(t, NewCgroupManagerFromConfig(cfg, zap.NewNop()))
+}
+
+func TestBaseScanner_ExecuteCommandCgroup(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	root := fakeCgroupRoot(t)
+	cm := &oomCgroupManager{CgroupV2Manager: NewCgroupV2Manager(config.CgroupConfig{Root: root, Parent: "go-sac", PidsMax: 16}, zap.NewNop())}
+	metrics := &recordingMetrics{values: make(map[string]float64)}
+	bs := NewBaseScanner(domain.ScanTypeSca, nil, zap.NewNop(), &config.Config{},
+		WithResourceProfile(scanner.ResourceProfile{MinCPU: 1, MaxCPU: 1, MemoryMB: 256}),
+		WithTimeout(10*time.Second, time.Second),
+		WithCircuitBreaker(&config.Config{}),
+		WithMetricsRecorder(metrics),
+		WithCgroupManager(cm),
+	)
+
+	err := bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-oom"}, exec.Command("sh", "-c", "exit 137"), "scan")
+	require.ErrorIs(t, err, ErrOOMKilled)
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	errType, _ := bs.classifyError(err)
+	assert.Equal(t, "oom_killed", errType)
+
+	assert.Equal(t, float64(1), metrics.values["command_oom_kills"])
+	assert.Equal(t, float64(256<<20), metrics.values["command_memory_peak_bytes"])
+	require.Len(t, cm.dirs, 1)
+	assert.True(t, strings.HasPrefix(filepath.Base(cm.dirs[0]), "task-oom-"))
+	assert.NoDirExists(t, cm.dirs[0])
+
+	// 健康检查不再把服务自身进程移入控制组，不可用时降级而非失败
+	require.NoError(t, bs.HealthCheck())
+	assert.Equal(t, float64(1), metrics.values["cgroup_available"])
+
+	bs.cgroupManager = NewCgroupV2Manager(config.CgroupConfig{Root: t.TempDir(), Parent: "go-sac"}, zap.NewNop())
+	require.NoError(t, bs.HealthCheck())
+	assert.Equal(t, float64(0), metrics.values["cgroup_available"])
+}
@@ -0,0 +1,346 @@
+package scanner_impl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// ComplianceFramework 合规框架的控制项映射（SecurityMaterialTypeComplianceDoc）
+type ComplianceFramework struct {
+	MaterialHeader   `yaml:",inline"`
+	Framework        string              `yaml:"framework"`         // 框架名称，如 OWASP ASVS
+	FrameworkVersion string              `yaml:"framework_version"` // 框架版本，与物料版本无关
+	Controls         []ComplianceControl `yaml:"controls"`
+}
+
+// ComplianceControl 框架控制项及其判定依据：Sources 中任一扫描类型有入库结果时适用，
+// 存在匹配 Match 中任一条件的未修复发现项即不合规
+type ComplianceControl struct {
+	ID      string            `yaml:"id"`
+	Title   string            `yaml:"title"`
+	Sources []string          `yaml:"sources"` // 依据的扫描类型，见 domain.ScanType.String
+	Match   []ComplianceMatch `yaml:"match"`   // 为空时依据扫描的任一发现项都视为不合规
+}
+
+// ComplianceMatch 发现项匹配条件，各字段同时满足，字段内任一取值满足即可
+type ComplianceMatch struct {
+	ScanTypes   []string `yaml:"scan_types"` // 限定扫描类型，为空时不限
+	CWE         []string `yaml:"cwe"`
+	Categories  []string `yaml:"categories"`
+	Rules       []string `yaml:"rules"` // 规则 ID 正则
+	MinSeverity string   `yaml:"min_severity"`
+}
+
+// ComplianceControlResult 控制项评估结果，FindingIDs 为作为证据的入库发现项编号
+type ComplianceControlResult struct {
+	Framework    string   `json:"framework"`
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Status       string   `json:"status"`
+	Severity     string   `json:"severity,omitempty"` // 证据中最高的严重等级
+	Sources      []string `json:"sources"`            // 有入库结果、参与评估的扫描类型
+	FindingIDs   []uint   `json:"finding_ids,omitempty"`
+	FindingCount int      `json:"finding_count"` // 证据总数，FindingIDs 可能被截断
+}
+
+// ComplianceReport 单个框架的评估结果
+type ComplianceReport struct {
+	Ref              MaterialRef               `json:"material"`
+	Framework        string                    `json:"framework"`
+	FrameworkVersion string                    `json:"framework_version"`
+	Controls         []ComplianceControlResult `json:"controls"`
+	Summary          map[string]interface{}    `json:"summary"`
+}
+
+// complianceMatcher 已校验并编译的匹配条件
+type complianceMatcher struct {
+	scanTypes  map[string]bool
+	cwe        map[string]bool
+	categories map[string]bool
+	rules      []*regexp.Regexp
+	minRank    int
+}
+
+// ComplianceMapping 已校验的框架映射
+type ComplianceMapping struct {
+	ref       MaterialRef
+	framework *ComplianceFramework
+	sources   [][]string            // 按控制项，已规范为 domain.ScanType.String
+	matchers  [][]complianceMatcher // 按控制项
+}
+
+// LoadComplianceMappings 从 ComplianceDoc 类型的安全物料中加载指定名称的框架映射，names 为空时加载全部
+func LoadComplianceMappings(store *MaterialStore, names []string, pinned string) ([]*ComplianceMapping, error) {
+	files, err := store.Select(domain.SecurityMaterialTypeComplianceDoc, pinned)
+	if err != nil {
+		return nil, err
+	}
+	files, err = selectNamedMaterials(files, names, "compliance frameworks")
+	if err != nil {
+		return nil, err
+	}
+
+	var mappings []*ComplianceMapping
+	for _, f := range files {
+		var fw ComplianceFramework
+		if err := f.Decode(&fw); err != nil {
+			return nil, err
+		}
+		m, err := newComplianceMapping(&fw, f.Ref())
+		if err != nil {
+			return nil, fmt.Errorf("security material %s: %w", f.path, err)
+		}
+		mappings = append(mappings, m)
+	}
+	if len(mappings) == 0 {
+		return nil, errors.New("no compliance framework mappings found")
+	}
+	return mappings, nil
+}
+
+// newComplianceMapping 校验控制项并编译匹配条件
+func newComplianceMapping(fw *ComplianceFramework, ref MaterialRef) (*ComplianceMapping, error) {
+	if fw.Framework == "" {
+		return nil, errors.New("framework is required")
+	}
+	m := &ComplianceMapping{ref: ref, framework: fw}
+	seen := make(map[string]bool)
+	for _, ctl := range fw.Controls {
+		if ctl.ID == "" || seen[ctl.ID] {
+			return nil, fmt.Errorf("control id %q is empty or duplicated", ctl.ID)
+		}
+		seen[ctl.ID] = true
+		if len(ctl.Sources) == 0 {
+			return nil, fmt.Errorf("control %s: sources are required", ctl.ID)
+		}
+		sources, err := normalizeScanTypes(ctl.Sources)
+		if err != nil {
+			return nil, fmt.Errorf("control %s: %w", ctl.ID, err)
+		}
+
+		matchers := make([]complianceMatcher, 0, len(ctl.Match))
+		for _, match := range ctl.Match {
+			matcher, err := newComplianceMatcher(match)
+			if err != nil {
+				return nil, fmt.Errorf("control %s: %w", ctl.ID, err)
+			}
+			matchers = append(matchers, matcher)
+		}
+		m.sources = append(m.sources, sources)
+		m.matchers = append(m.matchers, matchers)
+	}
+	if len(fw.Controls) == 0 {
+		return nil, errors.New("framework has no controls")
+	}
+	return m, nil
+}
+
+func newComplianceMatcher(match ComplianceMatch) (complianceMatcher, error) {
+	m := complianceMatcher{
+		cwe:        make(map[string]bool),
+		categories: make(map[string]bool),
+	}
+	if len(match.ScanTypes) > 0 {
+		scanTypes, err := normalizeScanTypes(match.ScanTypes)
+		if err != nil {
+			return m, err
+		}
+		m.scanTypes = make(map[string]bool)
+		for _, t := range scanTypes {
+			m.scanTypes[t] = true
+		}
+	}
+	for _, c := range match.CWE {
+		m.cwe[strings.ToUpper(c)] = true
+	}
+	for _, c := range match.Categories {
+		m.categories[strings.ToLower(c)] = true
+	}
+	for _, r := range match.Rules {
+		re, err := regexp.Compile(r)
+		if err != nil {
+			return m, err
+		}
+		m.rules = append(m.rules, re)
+	}
+	if match.MinSeverity != "" {
+		severity := domain.NormalizeSeverity(match.MinSeverity)
+		if severity == "" {
+			return m, fmt.Errorf("invalid min_severity %q", match.MinSeverity)
+		}
+		m.minRank = severityRank(severity)
+	}
+	return m, nil
+}
+
+// normalizeScanTypes 将扫描类型名称统一为 domain.ScanType.String 的形式
+func normalizeScanTypes(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		t, err := domain.ParseScanType(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t.String())
+	}
+	return out, nil
+}
+
+// matches 判断发现项是否满足条件
+func (m *complianceMatcher) matches(scanType string, f *domain.StoredFinding) bool {
+	if m.scanTypes != nil && !m.scanTypes[scanType] {
+		return false
+	}
+	if len(m.cwe) > 0 && !m.cwe[strings.ToUpper(f.CWEID)] {
+		return false
+	}
+	if len(m.categories) > 0 && !m.categories[strings.ToLower(f.Category)] {
+		return false
+	}
+	if len(m.rules) > 0 {
+		matched := false
+		for _, re := range m.rules {
+			if re.MatchString(f.RuleID) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return severityRank(domain.NormalizeSeverity(f.Severity)) >= m.minRank
+}
+
+// complianceSources 返回映射依据的全部扫描类型
+func complianceSources(mappings []*ComplianceMapping) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range mappings {
+		for _, sources := range m.sources {
+			for _, s := range sources {
+				if !seen[s] {
+					seen[s] = true
+					out = append(out, s)
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// evaluate 按资产各扫描类型最近的结果评估框架控制项；已修复的发现项不作为证据
+func (m *ComplianceMapping) evaluate(results map[string]*domain.ScanFindings, maxEvidence int) *ComplianceReport {
+	report := &ComplianceReport{
+		Ref:              m.ref,
+		Framework:        m.framework.Framework,
+		FrameworkVersion: m.framework.FrameworkVersion,
+	}
+	for i, ctl := range m.framework.Controls {
+		res := ComplianceControlResult{
+			Framework: m.ref.Name,
+			ID:        ctl.ID,
+			Title:     ctl.Title,
+			Sources:   []string{},
+		}
+		for _, source := range m.sources[i] {
+			scan, ok := results[source]
+			if !ok {
+				continue
+			}
+			res.Sources = append(res.Sources, source)
+			for j := range scan.Findings {
+				f := &scan.Findings[j]
+				if f.Status == domain.FindingStatusFixed || !matchesAny(m.matchers[i], source, f) {
+					continue
+				}
+				res.FindingCount++
+				if len(res.FindingIDs) < maxEvidence {
+					res.FindingIDs = append(res.FindingIDs, f.ID)
+				}
+				if severity := domain.NormalizeSeverity(f.Severity); severityRank(severity) > severityRank(res.Severity) {
+					res.Severity = severity
+				}
+			}
+		}
+
+		switch {
+		case len(res.Sources) == 0:
+			res.Status = ControlStatusNotApplicable
+		case res.FindingCount > 0:
+			res.Status = ControlStatusFail
+			if res.Severity == "" {
+				res.Severity = domain.SeverityInfo
+			}
+		default:
+			res.Status = ControlStatusPass
+		}
+		report.Controls = append(report.Controls, res)
+	}
+	report.Summary = summarizeCompliance(report.Controls)
+	return report
+}
+
+// matchesAny 没有匹配条件时依据扫描的任一发现项都计入
+func matchesAny(matchers []complianceMatcher, scanType string, f *domain.StoredFinding) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	for i := range matchers {
+		if matchers[i].matches(scanType, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeCompliance 统计控制项结果，合规率只计算适用的控制项
+func summarizeCompliance(controls []ComplianceControlResult) map[string]interface{} {
+	counts := map[string]int{ControlStatusPass: 0, ControlStatusFail: 0, ControlStatusNotApplicable: 0}
+	for _, c := range controls {
+		counts[c.Status]++
+	}
+	summary := map[string]interface{}{
+		"controls":       len(controls),
+		"passed":         counts[ControlStatusPass],
+		"failed":         counts[ControlStatusFail],
+		"not_applicable": counts[ControlStatusNotApplicable],
+	}
+	if applicable := counts[ControlStatusPass] + counts[ControlStatusFail]; applicable > 0 {
+		summary["compliance_rate"] = float64(counts[ControlStatusPass]) / float64(applicable)
+	}
+	return summary
+}
+
+// complianceFindings 不合规的控制项记为发现项，逻辑位置为框架控制项
+func complianceFindings(reports []*ComplianceReport) []domain.Finding {
+	var out []domain.Finding
+	for _, r := range reports {
+		for _, c := range r.Controls {
+			if c.Status != ControlStatusFail {
+				continue
+			}
+			out = append(out, domain.Finding{
+				RuleID:   r.Ref.Name + "/" + c.ID,
+				RuleName: c.Title,
+				Severity: c.Severity,
+				Message:  fmt.Sprintf("%s %s %s is not satisfied: %d findings from %s", r.Framework, r.FrameworkVersion, c.ID, c.FindingCount, strings.Join(c.Sources, ", ")),
+				Category: "compliance",
+				Location: domain.FindingLocation{Logical: r.Framework + " " + c.ID, LogicalKind: "control"},
+				Properties: map[string]interface{}{
+					"framework":         r.Framework,
+					"framework_version": r.FrameworkVersion,
+					"mapping":           r.Ref.Name + "@" + r.Ref.Version,
+					"finding_ids":       c.FindingIDs,
+				},
+			})
+		}
+	}
+	return out
+}
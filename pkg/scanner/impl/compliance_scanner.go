@@ -0,0 +1,241 @@
+package scanner_impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// FindingSource 读取资产各扫描类型最近一次入库的发现项，没有入库结果的扫描类型不返回
+type FindingSource interface {
+	LatestFindings(ctx context.Context, assetID string, assetType domain.AssetType, scanTypes []string) ([]domain.ScanFindings, error)
+}
+
+// StorageFindingSource 通过存储服务的 /api/v1/findings/latest 接口读取发现项
+type StorageFindingSource struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewStorageFindingSource 创建存储服务发现项读取器
+func NewStorageFindingSource(baseURL string, timeout time.Duration) *StorageFindingSource {
+	return &StorageFindingSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// LatestFindings 实现 FindingSource
+func (s *StorageFindingSource) LatestFindings(ctx context.Context, assetID string, assetType domain.AssetType, scanTypes []string) ([]domain.ScanFindings, error) {
+	query := url.Values{"asset_id": {assetID}, "asset_type": {assetType.String()}, "scan_type": scanTypes}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/api/v1/findings/latest?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query stored findings failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Message string                `json:"message"`
+		Data    []domain.ScanFindings `json:"data"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read stored findings failed: %w", err)
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("decode stored findings failed (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query stored findings failed: status %d: %s", resp.StatusCode, body.Message)
+	}
+	return body.Data, nil
+}
+
+// ComplianceAuditScanner 合规审计执行器，汇总资产最近的扫描结果并按框架控制项映射评估
+type ComplianceAuditScanner struct {
+	*BaseScanner
+	audit     config.ComplianceAuditConfig
+	materials config.SecurityMaterialConfig
+	store     materialStoreCache
+	source    FindingSource
+}
+
+// NewComplianceAuditScanner 创建合规审计执行器
+func NewComplianceAuditScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &ComplianceAuditScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeComplianceAudit)
+	s.audit, s.materials = config.GetComplianceAuditConfig()
+	s.source = NewStorageFindingSource(s.audit.StorageAPIURL, s.audit.RequestTimeout)
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeComplianceAudit,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *ComplianceAuditScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeComplianceAudit, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	var opts domain.ComplianceAuditOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+	var maxAge time.Duration
+	if opts.MaxAge != "" {
+		d, err := time.ParseDuration(opts.MaxAge)
+		if err != nil || d <= 0 {
+			return fail(fmt.Errorf("invalid max_age %q", opts.MaxAge))
+		}
+		maxAge = d
+	}
+	if task.AssetID == "" {
+		return fail(errors.New("compliance audit requires an asset"))
+	}
+
+	// 1. 加载框架映射
+	mappings, err := s.loadMappings(opts.Frameworks)
+	if err != nil {
+		return fail(err)
+	}
+	sources := complianceSources(mappings)
+
+	s.logger.Info("starting compliance audit",
+		zap.String("task_id", task.TaskID),
+		zap.String("asset_id", task.AssetID),
+		zap.String("asset_type", task.AssetType.String()),
+		zap.Int("frameworks", len(mappings)),
+		zap.Strings("sources", sources))
+
+	// 2. 读取资产各扫描类型最近的结果，超过 max_age 的结果不采信
+	s.SetTargetSize(task, scanner.TargetSize{Items: len(sources)})
+	var stored []domain.ScanFindings
+	err = s.ExecuteWithTimeout(ctx, task, func(ctx context.Context) error {
+		var queryErr error
+		stored, queryErr = s.source.LatestFindings(ctx, task.AssetID, task.AssetType, sources)
+		return queryErr
+	})
+	if err != nil {
+		return fail(err)
+	}
+	results := make(map[string]*domain.ScanFindings, len(stored))
+	evidence := make([]map[string]interface{}, 0, len(stored))
+	for i := range stored {
+		scan := &stored[i]
+		if maxAge > 0 && time.Since(scan.ScannedAt) > maxAge {
+			continue
+		}
+		results[scan.ScanType] = scan
+		evidence = append(evidence, map[string]interface{}{
+			"scan_type":  scan.ScanType,
+			"task_id":    scan.TaskID,
+			"scanned_at": scan.ScannedAt,
+			"findings":   len(scan.Findings),
+		})
+	}
+
+	// 3. 逐个框架评估
+	reports := make([]*ComplianceReport, 0, len(mappings))
+	for _, m := range mappings {
+		reports = append(reports, m.evaluate(results, s.audit.MaxEvidence))
+	}
+	summary := make(map[string]interface{}, len(reports))
+	for _, r := range reports {
+		summary[r.Ref.Name] = r.Summary
+	}
+	s.logger.Info("compliance audit finished",
+		zap.String("task_id", task.TaskID),
+		zap.String("asset_id", task.AssetID),
+		zap.Any("summary", summary))
+
+	result.SetSuccess(map[string]interface{}{
+		"phase":      domain.LifecyclePhaseComplianceAudit.String(),
+		"scans":      evidence,
+		"frameworks": reports,
+		"summary":    summary,
+	})
+	result.SetFindings(s.findingReport(complianceFindings(reports)))
+	return result, nil
+}
+
+// loadMappings 加载任务指定的框架，未指定时使用配置中的框架
+func (s *ComplianceAuditScanner) loadMappings(frameworks []string) ([]*ComplianceMapping, error) {
+	store, err := s.store.get(s.materials.MaterialDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(frameworks) == 0 {
+		frameworks = s.audit.Frameworks
+	}
+	return LoadComplianceMappings(store, frameworks, pinnedMaterialVersion(s.materials.Versions, domain.SecurityMaterialTypeComplianceDoc))
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *ComplianceAuditScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *ComplianceAuditScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *ComplianceAuditScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *ComplianceAuditScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *ComplianceAuditScanner) HealthCheck() error {
+	if _, err := s.loadMappings(nil); err != nil {
+		return fmt.Errorf("compliance mappings unavailable: %w", err)
+	}
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,189 @@
+package scanner_impl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeFindingSource struct {
+	results   []domain.ScanFindings
+	scanTypes []string
+}
+
+func (f *fakeFindingSource) LatestFindings(_ context.Context, _ string, _ domain.AssetType, scanTypes []string) ([]domain.ScanFindings, error) {
+	f.scanTypes = scanTypes
+	return f.results, nil
+}
+
+func storedFinding(id uint, ruleID, severity, category, cwe string) domain.StoredFinding {
+	return domain.StoredFinding{ID: id, TaskID: "t", Finding: domain.Finding{
+		RuleID: ruleID, Severity: severity, Category: category, CWEID: cwe,
+	}}
+}
+
+func newTestComplianceScanner(t *testing.T, source FindingSource) *ComplianceAuditScanner {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.ComplianceAudit.Timeout = 30 * time.Second
+	cfg.Scanner.ComplianceAudit.Materials.MaterialDir = shippedMaterialDir
+	s := NewComplianceAuditScanner(nil, zap.NewNop(), cfg).(*ComplianceAuditScanner)
+	s.source = source
+	return s
+}
+
+func complianceStatuses(t *testing.T, result *domain.ScanResult, framework string) map[string]ComplianceControlResult {
+	t.Helper()
+	for _, r := range result.Result["frameworks"].([]*ComplianceReport) {
+		if r.Ref.Name == framework {
+			out := make(map[string]ComplianceControlResult)
+			for _, c := range r.Controls {
+				out[c.ID] = c
+			}
+			return out
+		}
+	}
+	t.Fatalf("framework %s not evaluated", framework)
+	return nil
+}
+
+func TestComplianceAuditScanner(t *testing.T) {
+	fixed := storedFinding(9, "sql_injection", domain.SeverityHigh, "dast", "")
+	fixed.Status = domain.FindingStatusFixed
+	source := &fakeFindingSource{results: []domain.ScanFindings{
+		{ScanType: "SAST", TaskID: "sast-1", ScannedAt: time.Now(), Findings: []domain.StoredFinding{
+			storedFinding(1, "go.lang.security.sqli", domain.SeverityHigh, "", "CWE-89"),
+			storedFinding(2, "go.lang.weak-hash", domain.SeverityLow, "", "CWE-328"),
+		}},
+		{ScanType: "DAST", TaskID: "dast-1", ScannedAt: time.Now(), Findings: []domain.StoredFinding{
+			storedFinding(3, "missing_security_header", domain.SeverityLow, "dast", ""),
+			fixed,
+		}},
+		{ScanType: "SCA", TaskID: "sca-1", ScannedAt: time.Now(), Findings: []domain.StoredFinding{
+			storedFinding(4, "GHSA-xxxx", domain.SeverityMedium, "vulnerable-dependency", ""),
+		}},
+		// 超过 max_age 的结果不采信
+		{ScanType: "SecretsDetection", TaskID: "secrets-1", ScannedAt: time.Now().Add(-48 * time.Hour), Findings: []domain.StoredFinding{
+			storedFinding(5, "aws-access-key", domain.SeverityHigh, "secret", "CWE-798"),
+		}},
+	}}
+	s := newTestComplianceScanner(t, source)
+
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-compliance",
+		AssetID:   "3",
+		AssetType: domain.AssetTypeRepository,
+		Options:   map[string]interface{}{"max_age": "24h"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, []string{"ContainerImageScan", "DAST", "HostSecurityCheck", "PortScanning", "SAST", "SCA", "SecretsDetection"}, source.scanTypes)
+	assert.Len(t, result.Result["frameworks"], 4)
+
+	asvs := complianceStatuses(t, result, "owasp-asvs")
+	assert.Equal(t, ControlStatusFail, asvs["V5.3.4"].Status)
+	assert.Equal(t, []uint{1}, asvs["V5.3.4"].FindingIDs) // 已修复的 DAST 发现项不计入
+	assert.Equal(t, []string{"SAST", "DAST"}, asvs["V5.3.4"].Sources)
+	assert.Equal(t, domain.SeverityHigh, asvs["V5.3.4"].Severity)
+	assert.Equal(t, ControlStatusPass, asvs["V5.3.3"].Status)
+	assert.Equal(t, ControlStatusFail, asvs["V6.2.2"].Status)
+	assert.Equal(t, ControlStatusFail, asvs["V14.4.3"].Status)
+	assert.Equal(t, ControlStatusFail, asvs["V14.2.1"].Status)
+	assert.Equal(t, ControlStatusNotApplicable, asvs["V2.10.4"].Status)
+	assert.Equal(t, ControlStatusPass, asvs["V9.1.1"].Status) // DAST 有结果且没有 mixed_content
+	assert.Equal(t, []string{"DAST"}, asvs["V9.1.1"].Sources)
+
+	cis := complianceStatuses(t, result, "cis-controls")
+	assert.Equal(t, ControlStatusNotApplicable, cis["4.1"].Status)
+	assert.Equal(t, ControlStatusFail, cis["7.4"].Status)
+	assert.Equal(t, ControlStatusPass, cis["16.11"].Status) // 依赖漏洞未达到 high
+	assert.Equal(t, ControlStatusFail, cis["16.12"].Status)
+
+	summary := result.Result["summary"].(map[string]interface{})
+	asvsSummary := summary["owasp-asvs"].(map[string]interface{})
+	assert.Equal(t, 12, asvsSummary["controls"])
+	assert.Equal(t, 4, asvsSummary["failed"])
+	assert.Equal(t, 1, asvsSummary["not_applicable"])
+
+	var v534 *domain.Finding
+	for _, f := range result.Findings() {
+		if f.RuleID == "owasp-asvs/V5.3.4" {
+			v534 = &f
+		}
+	}
+	require.NotNil(t, v534)
+	assert.Equal(t, "compliance", v534.Category)
+	assert.Equal(t, domain.SeverityHigh, v534.Severity)
+	assert.Equal(t, "OWASP ASVS V5.3.4", v534.Location.Logical)
+}
+
+func TestComplianceAuditScanner_Frameworks(t *testing.T) {
+	source := &fakeFindingSource{}
+	s := newTestComplianceScanner(t, source)
+	task := &domain.ScanTaskPayload{
+		TaskID:    "task-compliance",
+		AssetID:   "5",
+		AssetType: domain.AssetTypeIP,
+		Options:   map[string]interface{}{"frameworks": []interface{}{"mlps-2.0"}},
+	}
+
+	result, err := s.Scan(context.Background(), task)
+	require.NoError(t, err)
+	reports := result.Result["frameworks"].([]*ComplianceReport)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "MLPS 2.0", reports[0].Framework)
+	for _, c := range reports[0].Controls {
+		assert.Equal(t, ControlStatusNotApplicable, c.Status, c.ID)
+	}
+	assert.NotContains(t, reports[0].Summary, "compliance_rate")
+	assert.Empty(t, result.Findings())
+
+	task.Options = map[string]interface{}{"frameworks": []interface{}{"pci-dss"}}
+	_, err = s.Scan(context.Background(), task)
+	assert.ErrorContains(t, err, "unknown compliance frameworks: pci-dss")
+}
+
+func TestStorageFindingSource(t *testing.T) {
+	scannedAt := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("asset_id") == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":400,"message":"invalid asset type"}`))
+			return
+		}
+		assert.Equal(t, "/api/v1/findings/latest", r.URL.Path)
+		assert.Equal(t, "IP", r.URL.Query().Get("asset_type"))
+		assert.Equal(t, []string{"HostSecurityCheck", "PortScanning"}, r.URL.Query()["scan_type"])
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":    200,
+			"message": "success",
+			"data": []domain.ScanFindings{{
+				ScanType:  "HostSecurityCheck",
+				TaskID:    "host-1",
+				ScannedAt: scannedAt,
+				Findings:  []domain.StoredFinding{storedFinding(42, "HOST-SSH-005", domain.SeverityLow, "ssh", "")},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	source := NewStorageFindingSource(srv.URL+"/", time.Second)
+	results, err := source.LatestFindings(context.Background(), "7", domain.AssetTypeIP, []string{"HostSecurityCheck", "PortScanning"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, scannedAt, results[0].ScannedAt)
+	assert.Equal(t, uint(42), results[0].Findings[0].ID)
+	assert.Equal(t, "HOST-SSH-005", results[0].Findings[0].RuleID)
+
+	_, err = source.LatestFindings(context.Background(), "missing", domain.AssetTypeIP, []string{"PortScanning"})
+	assert.ErrorContains(t, err, "status 400: invalid asset type")
+}
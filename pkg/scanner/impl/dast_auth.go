@@ -0,0 +1,332 @@
+package scanner_impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+)
+
+var (
+	// ErrExcludedURL 地址命中排除规则，不发送请求
+	ErrExcludedURL = errors.New("url excluded from dast scan")
+	// ErrLoginFailed 登录后仍未建立会话
+	ErrLoginFailed = errors.New("dast login failed")
+	// ErrSessionLost 会话失效且无法重新登录
+	ErrSessionLost = errors.New("dast session lost")
+)
+
+// defaultAuthExclusions 登录后扫描默认排除的地址：退出登录会使会话失效，删除类操作会破坏被测数据
+var defaultAuthExclusions = []string{
+	`(?i)(log|sign)[-_]?(out|off)`,
+	`(?i)(^|[/_.?&=-])(delete|destroy|remove)([/_.?&=-]|$)`,
+}
+
+// defaultMaxRelogins 会话失效后默认最多重新登录的次数
+const defaultMaxRelogins = 5
+
+// maxLoginRedirects 提交登录表单后最多跟随的重定向次数
+const maxLoginRedirects = 5
+
+// DASTSession 登录会话：为每个请求附加凭据，识别会话失效并按登录方式重新登录
+// 只有表单登录可以重新登录，令牌、Cookie 与 Basic 认证失效后会话标记为丢失，扫描继续但不再携带有效会话
+type DASTSession struct {
+	auth        domain.DASTAuthOptions // 凭据已解密
+	loginURL    *url.URL
+	markers     []*regexp.Regexp
+	maxRelogins int
+
+	mu     sync.Mutex
+	logins int
+	lost   bool
+}
+
+// NewDASTSession 校验登录配置并解密凭据，login_url 可以是相对扫描入口的路径
+// 凭据绑定扫描入口的 origin 加密，登录地址必须与扫描入口同源，凭据不会发往其它站点
+func NewDASTSession(opts *domain.DASTAuthOptions, target *url.URL, keys *crypt.KeyManager) (*DASTSession, error) {
+	s := &DASTSession{auth: *opts, maxRelogins: defaultMaxRelogins}
+	if opts.MaxRelogins != nil {
+		s.maxRelogins = *opts.MaxRelogins
+	}
+
+	origin := domain.URLOrigin(target)
+	open := func(field, value string) (string, error) {
+		if value == "" {
+			return "", fmt.Errorf("missing auth.%s for %s login", field, opts.Type)
+		}
+		plaintext, err := keys.OpenStringBound(value, origin)
+		if err != nil {
+			return "", fmt.Errorf("decrypt auth.%s: %w", field, err)
+		}
+		return plaintext, nil
+	}
+
+	var err error
+	switch opts.Type {
+	case domain.DASTAuthForm:
+		if opts.LoginURL == "" || opts.Username == "" {
+			return nil, fmt.Errorf("form login requires auth.login_url and auth.username")
+		}
+		if s.loginURL, err = target.Parse(opts.LoginURL); err != nil {
+			return nil, fmt.Errorf("invalid auth.login_url: %w", err)
+		}
+		if s.loginURL.Scheme != "http" && s.loginURL.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported auth.login_url: %s", s.loginURL)
+		}
+		if domain.URLOrigin(s.loginURL) != origin {
+			return nil, fmt.Errorf("auth.login_url %s is not on the scan target origin %s", s.loginURL, origin)
+		}
+		s.loginURL.Fragment = ""
+		if s.auth.UsernameField == "" {
+			s.auth.UsernameField = "username"
+		}
+		if s.auth.PasswordField == "" {
+			s.auth.PasswordField = "password"
+		}
+		if s.auth.Password, err = open("password", opts.Password); err != nil {
+			return nil, err
+		}
+	case domain.DASTAuthBasic:
+		if opts.Username == "" {
+			return nil, fmt.Errorf("basic auth requires auth.username")
+		}
+		if s.auth.Password, err = open("password", opts.Password); err != nil {
+			return nil, err
+		}
+	case domain.DASTAuthBearer:
+		if s.auth.Token, err = open("token", opts.Token); err != nil {
+			return nil, err
+		}
+	case domain.DASTAuthCookie:
+		if len(opts.Cookies) == 0 {
+			return nil, fmt.Errorf("cookie auth requires auth.cookies")
+		}
+		s.auth.Cookies = make([]domain.DASTCookie, len(opts.Cookies))
+		for i, c := range opts.Cookies {
+			value, err := open(fmt.Sprintf("cookies[%d].value", i), c.Value)
+			if err != nil {
+				return nil, err
+			}
+			s.auth.Cookies[i] = domain.DASTCookie{Name: c.Name, Value: value}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", opts.Type)
+	}
+
+	for _, pattern := range opts.LogoutMarkers {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth.logout_markers pattern %q: %w", pattern, err)
+		}
+		s.markers = append(s.markers, re)
+	}
+	return s, nil
+}
+
+// Relogins 返回会话失效后重新登录的次数
+func (s *DASTSession) Relogins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logins == 0 {
+		return 0
+	}
+	return s.logins - 1
+}
+
+// Lost 判断会话是否已丢失
+func (s *DASTSession) Lost() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lost
+}
+
+// start 建立会话并访问扫描入口确认已登录
+func (s *DASTSession) start(ctx context.Context, r *Requester, target *url.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.auth.Type == domain.DASTAuthCookie && r.client.Jar != nil {
+		cookies := make([]*http.Cookie, len(s.auth.Cookies))
+		for i, c := range s.auth.Cookies {
+			cookies[i] = &http.Cookie{Name: c.Name, Value: c.Value}
+		}
+		r.client.Jar.SetCookies(target, cookies)
+	}
+	if err := s.login(ctx, r); err != nil {
+		return err
+	}
+
+	resp, err := r.send(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if s.expired(resp) {
+		return fmt.Errorf("%w: %s is not reachable with the configured %s credentials", ErrLoginFailed, target, s.auth.Type)
+	}
+	return nil
+}
+
+// renew 会话失效后重新登录，超过次数上限或登录方式不支持时标记会话丢失
+func (s *DASTSession) renew(ctx context.Context, r *Requester) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lost {
+		return ErrSessionLost
+	}
+	if s.auth.Type != domain.DASTAuthForm || s.logins > s.maxRelogins {
+		s.lost = true
+		return ErrSessionLost
+	}
+	err := s.login(ctx, r)
+	if err != nil && !errors.Is(err, ErrRequestBudgetExhausted) {
+		s.lost = true
+	}
+	return err
+}
+
+// login 执行表单登录，其它登录方式的凭据在每个请求上附加，无需登录
+func (s *DASTSession) login(ctx context.Context, r *Requester) error {
+	s.logins++
+	if s.auth.Type != domain.DASTAuthForm {
+		return nil
+	}
+
+	// 先访问登录页获取会话 Cookie 与 CSRF 令牌等隐藏字段
+	resp, err := r.send(ctx, http.MethodGet, s.loginURL, nil)
+	if err != nil {
+		return err
+	}
+	method, action, fields := http.MethodPost, s.loginURL, url.Values{}
+	if form := s.loginForm(resp); form != nil {
+		method, action, fields = form.Method, form.URL, form.Params
+	}
+	fields.Set(s.auth.UsernameField, s.auth.Username)
+	fields.Set(s.auth.PasswordField, s.auth.Password)
+	for k, v := range s.auth.ExtraFields {
+		fields.Set(k, v)
+	}
+
+	if method == http.MethodGet {
+		u := *action
+		u.RawQuery = fields.Encode()
+		resp, err = r.send(ctx, http.MethodGet, &u, nil)
+	} else {
+		resp, err = r.send(ctx, http.MethodPost, action, fields)
+	}
+	for i := 0; err == nil && i < maxLoginRedirects; i++ {
+		next := redirectTarget(resp)
+		if next == nil {
+			break
+		}
+		resp, err = r.send(ctx, http.MethodGet, next, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 登录失败的典型表现：拒绝访问、重新渲染登录表单或命中退出登录标记
+	rerendered := (s.isLoginURL(resp.URL) || resp.URL.Path == action.Path) && s.loginForm(resp) != nil
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden ||
+		rerendered || s.matchesMarker(resp) {
+		return fmt.Errorf("%w: %s did not accept the credentials for %s", ErrLoginFailed, action, s.auth.Username)
+	}
+	return nil
+}
+
+// apply 为请求附加凭据
+func (s *DASTSession) apply(req *http.Request) {
+	switch s.auth.Type {
+	case domain.DASTAuthBasic:
+		req.SetBasicAuth(s.auth.Username, s.auth.Password)
+	case domain.DASTAuthBearer:
+		if s.auth.Header == "" {
+			req.Header.Set("Authorization", "Bearer "+s.auth.Token)
+		} else {
+			req.Header.Set(s.auth.Header, s.auth.Token)
+		}
+	}
+}
+
+// expired 判断响应是否表明会话已失效：401、被重定向到登录页或命中退出登录标记
+// 登录页本身的响应不参与判断
+func (s *DASTSession) expired(resp *Response) bool {
+	if s.isLoginURL(resp.URL) {
+		return false
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if next := redirectTarget(resp); next != nil && s.isLoginURL(next) {
+		return true
+	}
+	return s.matchesMarker(resp)
+}
+
+// isLoginURL 判断地址是否为登录页，忽略查询串
+func (s *DASTSession) isLoginURL(u *url.URL) bool {
+	return s.loginURL != nil && sameOrigin(s.loginURL, u) && strings.TrimSuffix(s.loginURL.Path, "/") == strings.TrimSuffix(u.Path, "/")
+}
+
+func (s *DASTSession) matchesMarker(resp *Response) bool {
+	for _, re := range s.markers {
+		if re.Match(resp.Body) {
+			return true
+		}
+	}
+	return false
+}
+
+// loginForm 查找包含密码字段的表单
+func (s *DASTSession) loginForm(resp *Response) *Endpoint {
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return nil
+	}
+	page := &Page{URL: resp.URL, Header: resp.Header, Body: resp.Body}
+	parseHTML(page)
+	for i := range page.Forms {
+		if _, ok := page.Forms[i].Params[s.auth.PasswordField]; ok {
+			return &page.Forms[i]
+		}
+	}
+	return nil
+}
+
+// redirectTarget 返回重定向响应的目标地址
+func redirectTarget(resp *Response) *url.URL {
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return nil
+	}
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return nil
+	}
+	u, err := resp.URL.Parse(loc)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// compileExclusions 编译排除规则，登录后扫描追加默认的退出登录与删除类规则
+func compileExclusions(patterns []string, authenticated bool) ([]*regexp.Regexp, error) {
+	if authenticated {
+		patterns = append(append([]string(nil), patterns...), defaultAuthExclusions...)
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
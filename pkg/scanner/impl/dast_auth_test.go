@@ -0,0 +1,281 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAESKey = "0123456789abcdef0123456789abcdef"
+
+// seal 以扫描目标（URL origin 或 ssh://主机）加密凭据，与任务服务下发的密文一致
+func seal(t *testing.T, scope, plaintext string) string {
+	t.Helper()
+	sealed, err := crypt.NewKeyManager([]byte(testAESKey), 0).SealStringBound(plaintext, scope)
+	require.NoError(t, err)
+	return sealed
+}
+
+// newLoginSite 模拟需要表单登录的站点：登录页带 CSRF 令牌，会话在 expireAfter 个请求后失效并重定向到登录页
+func newLoginSite(t *testing.T, expireAfter int) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		hits     []string
+		sessions = make(map[string]int)
+		nextID   int
+	)
+	loginForm := func(w http.ResponseWriter, msg string) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><body><p>Please sign in %s</p>
+			<form action="/login" method="post">
+			<input type="hidden" name="csrf" value="tok">
+			<input name="user"><input type="password" name="pass">
+			<input type="submit" value="go"></form></body></html>`, msg)
+	}
+	protected := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			c, err := r.Cookie("sid")
+			ok := err == nil && sessions[c.Value] > 0 && sessions[c.Value] <= expireAfter
+			if ok {
+				sessions[c.Value]++
+			}
+			mu.Unlock()
+			if !ok {
+				http.Redirect(w, r, "/login", http.StatusFound)
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			loginForm(w, "")
+			return
+		}
+		if r.FormValue("csrf") != "tok" || r.FormValue("user") != "alice" || r.FormValue("pass") != "s3cret" {
+			loginForm(w, "invalid credentials")
+			return
+		}
+		mu.Lock()
+		nextID++
+		sid := strconv.Itoa(nextID)
+		sessions[sid] = 1
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: sid, HttpOnly: true})
+		http.Redirect(w, r, "/account", http.StatusFound)
+	})
+	mux.HandleFunc("/account", protected(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body>
+			<a href="/account/search?q=test">search</a>
+			<a href="/account/delete?id=1">delete</a>
+			<a href="/logout">logout</a>
+		</body></html>`)
+	}))
+	mux.HandleFunc("/account/search", protected(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<p>results for %s</p>", r.URL.Query().Get("q"))
+	}))
+	mux.HandleFunc("/account/delete", protected(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "deleted")
+	}))
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("sid"); err == nil {
+			mu.Lock()
+			delete(sessions, c.Value)
+			mu.Unlock()
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, r.URL.Path)
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), hits...)
+	}
+}
+
+func formAuth(t *testing.T, origin, password string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":           domain.DASTAuthForm,
+		"login_url":      "/login",
+		"username_field": "user",
+		"password_field": "pass",
+		"username":       "alice",
+		"password":       seal(t, origin, password),
+		"logout_markers": []interface{}{"Please sign in"},
+	}
+}
+
+func TestDASTScanner_FormLoginWithRelogin(t *testing.T) {
+	srv, hits := newLoginSite(t, 4)
+	s := newTestDASTScanner(200)
+
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID: "task-dast-auth",
+		Options: map[string]interface{}{
+			OptionTargetURL: srv.URL + "/account",
+			"auth":          formAuth(t, srv.URL, "s3cret"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Result["authenticated"])
+	assert.Equal(t, false, result.Result["session_lost"])
+	assert.Greater(t, result.Result["relogins"], 0, "session expires every 4 requests and must be renewed")
+
+	var xss []DASTFinding
+	for _, f := range result.Result["findings"].([]DASTFinding) {
+		if f.VulnType == VulnReflectedXSS {
+			xss = append(xss, f)
+		}
+	}
+	require.Len(t, xss, 1, "page behind login must be probed")
+	assert.Equal(t, srv.URL+"/account/search", xss[0].URL)
+
+	assert.NotContains(t, hits(), "/logout")
+	assert.NotContains(t, hits(), "/account/delete")
+}
+
+func TestDASTScanner_FormLoginRejected(t *testing.T) {
+	srv, _ := newLoginSite(t, 100)
+	s := newTestDASTScanner(50)
+
+	_, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID: "task-dast-badauth",
+		Options: map[string]interface{}{
+			OptionTargetURL: srv.URL + "/account",
+			"auth":          formAuth(t, srv.URL, "wrong"),
+		},
+	})
+	assert.ErrorIs(t, err, ErrLoginFailed)
+}
+
+func TestDASTScanner_StaticCredentials(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		cookie, _ := r.Cookie("session")
+		authorized := r.Header.Get("Authorization") == "Bearer t0ken" ||
+			(user == "alice" && pass == "s3cret") ||
+			(cookie != nil && cookie.Value == "c00kie")
+		if !authorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		seen = append(seen, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/private">private</a>`)
+	}))
+	t.Cleanup(srv.Close)
+
+	for name, auth := range map[string]map[string]interface{}{
+		"bearer": {"type": "bearer", "token": seal(t, srv.URL, "t0ken")},
+		"basic":  {"type": "basic", "username": "alice", "password": seal(t, srv.URL, "s3cret")},
+		"cookie": {"type": "cookie", "cookies": []interface{}{
+			map[string]interface{}{"name": "session", "value": seal(t, srv.URL, "c00kie")},
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			seen = nil
+			mu.Unlock()
+
+			result, err := newTestDASTScanner(20).Scan(context.Background(), &domain.ScanTaskPayload{
+				TaskID:  "task-dast-" + name,
+				Options: map[string]interface{}{OptionTargetURL: srv.URL, "auth": auth},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, false, result.Result["session_lost"])
+			mu.Lock()
+			assert.Contains(t, seen, "/private")
+			mu.Unlock()
+		})
+	}
+}
+
+func TestNewDASTSession_RequiresSealedSecrets(t *testing.T) {
+	target, err := dastTarget(domain.DASTOptions{TargetURL: "https://app.example.com"})
+	require.NoError(t, err)
+	keys := crypt.NewKeyManager([]byte(testAESKey), time.Hour)
+
+	_, err = NewDASTSession(&domain.DASTAuthOptions{Type: domain.DASTAuthBearer, Token: "plaintext"}, target, keys)
+	assert.ErrorIs(t, err, crypt.ErrNotSealed)
+
+	_, err = NewDASTSession(&domain.DASTAuthOptions{Type: domain.DASTAuthForm, Username: "alice", Password: seal(t, "https://app.example.com", "x")}, target, keys)
+	assert.Error(t, err, "form login requires login_url")
+
+	session, err := NewDASTSession(&domain.DASTAuthOptions{
+		Type:     domain.DASTAuthForm,
+		LoginURL: "/auth/login",
+		Username: "alice",
+		Password: seal(t, "https://app.example.com", "x"),
+	}, target, keys)
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/auth/login", session.loginURL.String())
+	assert.Equal(t, "x", session.auth.Password)
+
+	// 凭据不会提交到扫描入口以外的 origin
+	for _, loginURL := range []string{"https://evil.example.com/login", "//evil.example.com/login", "http://app.example.com/login", "https://app.example.com:8443/login"} {
+		_, err = NewDASTSession(&domain.DASTAuthOptions{
+			Type:     domain.DASTAuthForm,
+			LoginURL: loginURL,
+			Username: "alice",
+			Password: seal(t, "https://app.example.com", "x"),
+		}, target, keys)
+		assert.ErrorContains(t, err, "not on the scan target origin", loginURL)
+	}
+
+	// 为其它目标加密的凭据在此目标上无法解密
+	other, err := dastTarget(domain.DASTOptions{TargetURL: "https://evil.example.com"})
+	require.NoError(t, err)
+	_, err = NewDASTSession(&domain.DASTAuthOptions{Type: domain.DASTAuthBearer, Token: seal(t, "https://app.example.com", "x")}, other, keys)
+	assert.ErrorContains(t, err, "decrypt auth.token")
+}
+
+func TestCompileExclusions(t *testing.T) {
+	exclude, err := compileExclusions([]string{`/admin/`}, true)
+	require.NoError(t, err)
+	r := &Requester{exclude: exclude}
+	for raw, want := range map[string]bool{
+		"https://app/admin/users":         true,
+		"https://app/logout":              true,
+		"https://app/user/sign-out":       true,
+		"https://app/items/delete?id=1":   true,
+		"https://app/items?action=remove": true,
+		"https://app/items/deleted-list":  false,
+		"https://app/account":             false,
+	} {
+		u, err := dastTarget(domain.DASTOptions{TargetURL: raw})
+		require.NoError(t, err)
+		assert.Equal(t, want, r.excluded(u), raw)
+	}
+
+	_, err = compileExclusions([]string{`(`}, false)
+	assert.Error(t, err)
+}
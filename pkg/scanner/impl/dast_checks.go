@@ -0,0 +1,330 @@
+package scanner_impl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DAST 漏洞类型
+const (
+	VulnMissingSecurityHeader = "missing_security_header"
+	VulnInsecureCookie        = "insecure_cookie"
+	VulnMixedContent          = "mixed_content"
+	VulnInformationDisclosure = "information_disclosure"
+	VulnReflectedXSS          = "reflected_xss"
+	VulnOpenRedirect          = "open_redirect"
+	VulnSQLInjection          = "sql_injection"
+)
+
+var (
+	activeChecksMu sync.RWMutex
+	activeChecks   = map[string]func() ActiveCheck{
+		"reflected_xss": func() ActiveCheck { return reflectedXSSCheck{} },
+		"open_redirect": func() ActiveCheck { return openRedirectCheck{} },
+		"sql_error":     func() ActiveCheck { return sqlErrorCheck{} },
+	}
+)
+
+// RegisterActiveCheck 注册自定义主动检测项，同名注册会覆盖已有实现
+func RegisterActiveCheck(name string, factory func() ActiveCheck) {
+	activeChecksMu.Lock()
+	defer activeChecksMu.Unlock()
+	activeChecks[name] = factory
+}
+
+// NewActiveChecks 按名称创建主动检测项，names 为空时返回全部已注册检测项
+func NewActiveChecks(names []string) ([]ActiveCheck, error) {
+	activeChecksMu.RLock()
+	defer activeChecksMu.RUnlock()
+
+	if len(names) == 0 {
+		for name := range activeChecks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	checks := make([]ActiveCheck, 0, len(names))
+	for _, name := range names {
+		factory, ok := activeChecks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dast active check: %s", name)
+		}
+		checks = append(checks, factory())
+	}
+	return checks, nil
+}
+
+func defaultPassiveChecks() []PassiveCheck {
+	return []PassiveCheck{
+		securityHeadersCheck{},
+		cookieFlagsCheck{},
+		mixedContentCheck{},
+		serverBannerCheck{},
+	}
+}
+
+// securityHeadersCheck 检查 HTML 响应缺失的安全响应头
+type securityHeadersCheck struct{}
+
+func (securityHeadersCheck) Name() string { return "security_headers" }
+
+func (securityHeadersCheck) Inspect(page *Page) []DASTFinding {
+	if !page.IsHTML() || page.StatusCode >= 400 {
+		return nil
+	}
+
+	type rule struct {
+		header      string
+		severity    string
+		score       float64
+		remediation string
+	}
+	rules := []rule{
+		{"Content-Security-Policy", SeverityLow, 3.1, "Define a restrictive Content-Security-Policy to mitigate XSS and data injection."},
+		{"X-Content-Type-Options", SeverityLow, 3.1, "Send X-Content-Type-Options: nosniff."},
+		{"Referrer-Policy", SeverityInfo, 0, "Send a Referrer-Policy such as strict-origin-when-cross-origin."},
+	}
+	// CSP frame-ancestors 可替代 X-Frame-Options
+	if !strings.Contains(strings.ToLower(page.Header.Get("Content-Security-Policy")), "frame-ancestors") {
+		rules = append(rules, rule{"X-Frame-Options", SeverityLow, 4.3, "Send X-Frame-Options: DENY or a CSP frame-ancestors directive to prevent clickjacking."})
+	}
+	if page.URL.Scheme == "https" {
+		rules = append(rules, rule{"Strict-Transport-Security", SeverityMedium, 4.8, "Send Strict-Transport-Security with a long max-age."})
+	}
+
+	var findings []DASTFinding
+	for _, r := range rules {
+		if page.Header.Get(r.header) != "" {
+			continue
+		}
+		findings = append(findings, DASTFinding{
+			URL:         originURL(page.URL),
+			Method:      http.MethodGet,
+			Parameter:   r.header,
+			Severity:    r.severity,
+			VulnType:    VulnMissingSecurityHeader,
+			CVSSScore:   r.score,
+			Evidence:    fmt.Sprintf("response from %s lacks %s", page.URL, r.header),
+			Remediation: r.remediation,
+		})
+	}
+	return findings
+}
+
+// cookieFlagsCheck 检查 Set-Cookie 缺失的 HttpOnly/Secure/SameSite 属性
+type cookieFlagsCheck struct{}
+
+func (cookieFlagsCheck) Name() string { return "cookie_flags" }
+
+func (cookieFlagsCheck) Inspect(page *Page) []DASTFinding {
+	var findings []DASTFinding
+	for _, cookie := range (&http.Response{Header: page.Header}).Cookies() {
+		var missing []string
+		severity, score := SeverityLow, 3.1
+		if !cookie.HttpOnly {
+			missing = append(missing, "HttpOnly")
+		}
+		if !cookie.Secure && page.URL.Scheme == "https" {
+			missing = append(missing, "Secure")
+			severity, score = SeverityMedium, 4.3
+		}
+		if cookie.SameSite == http.SameSiteDefaultMode {
+			missing = append(missing, "SameSite")
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		findings = append(findings, DASTFinding{
+			URL:         originURL(page.URL),
+			Method:      http.MethodGet,
+			Parameter:   cookie.Name,
+			Severity:    severity,
+			VulnType:    VulnInsecureCookie,
+			CVSSScore:   score,
+			Evidence:    "cookie set without " + strings.Join(missing, ", "),
+			Remediation: "Set HttpOnly, Secure and SameSite attributes on session cookies.",
+		})
+	}
+	return findings
+}
+
+// mixedContentCheck 检查 HTTPS 页面引用的 HTTP 子资源
+type mixedContentCheck struct{}
+
+func (mixedContentCheck) Name() string { return "mixed_content" }
+
+func (mixedContentCheck) Inspect(page *Page) []DASTFinding {
+	if page.URL.Scheme != "https" {
+		return nil
+	}
+	var findings []DASTFinding
+	for _, res := range page.Resources {
+		if !strings.HasPrefix(strings.ToLower(res), "http://") {
+			continue
+		}
+		findings = append(findings, DASTFinding{
+			URL:         page.URL.String(),
+			Method:      http.MethodGet,
+			Parameter:   res,
+			Severity:    SeverityMedium,
+			VulnType:    VulnMixedContent,
+			CVSSScore:   4.3,
+			Evidence:    "HTTPS page loads " + res,
+			Remediation: "Load all sub-resources over HTTPS.",
+		})
+	}
+	return findings
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// serverBannerCheck 检查泄露服务端软件及版本的响应头
+type serverBannerCheck struct{}
+
+func (serverBannerCheck) Name() string { return "server_banner" }
+
+func (serverBannerCheck) Inspect(page *Page) []DASTFinding {
+	var findings []DASTFinding
+	for _, header := range []string{"Server", "X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version"} {
+		value := page.Header.Get(header)
+		// 仅有产品名（如 nginx）的 Server 头不视为版本泄露
+		if value == "" || (header == "Server" && !versionPattern.MatchString(value)) {
+			continue
+		}
+		findings = append(findings, DASTFinding{
+			URL:         originURL(page.URL),
+			Method:      http.MethodGet,
+			Parameter:   header,
+			Severity:    SeverityInfo,
+			VulnType:    VulnInformationDisclosure,
+			Evidence:    header + ": " + value,
+			Remediation: "Remove or genericize headers that disclose server software and versions.",
+		})
+	}
+	return findings
+}
+
+// reflectedXSSCheck 注入唯一标记，检查其是否未经编码回显在 HTML 中
+type reflectedXSSCheck struct{}
+
+func (reflectedXSSCheck) Name() string { return "reflected_xss" }
+
+func (reflectedXSSCheck) Probe(ctx context.Context, r *Requester, ep Endpoint, param string) ([]DASTFinding, error) {
+	marker := "gosac" + randomToken()
+	payload := `"'><` + marker + `>`
+	resp, err := r.Send(ctx, ep, param, payload)
+	if err != nil {
+		return nil, err
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if !strings.Contains(ct, "html") || !strings.Contains(string(resp.Body), "<"+marker+">") {
+		return nil, nil
+	}
+	return []DASTFinding{{
+		URL:         ep.baseURL(),
+		Method:      ep.Method,
+		Parameter:   param,
+		Payload:     payload,
+		Severity:    SeverityHigh,
+		VulnType:    VulnReflectedXSS,
+		CVSSScore:   6.1,
+		Evidence:    "payload reflected without encoding",
+		Remediation: "Context-encode user input before rendering and apply a Content-Security-Policy.",
+	}}, nil
+}
+
+// redirectCanary 开放重定向探测使用的外部地址，.invalid 保证不会被真实解析
+const redirectCanary = "gosac-redirect.invalid"
+
+var redirectParamPattern = regexp.MustCompile(`(?i)(url|uri|redirect|next|return|dest|destination|continue|goto|target|callback|forward)`)
+
+// openRedirectCheck 检查重定向类参数是否可跳转到任意外部域名
+type openRedirectCheck struct{}
+
+func (openRedirectCheck) Name() string { return "open_redirect" }
+
+func (openRedirectCheck) Probe(ctx context.Context, r *Requester, ep Endpoint, param string) ([]DASTFinding, error) {
+	value := ep.Params.Get(param)
+	if !redirectParamPattern.MatchString(param) && !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "http") {
+		return nil, nil
+	}
+
+	for _, payload := range []string{"https://" + redirectCanary + "/", "//" + redirectCanary + "/"} {
+		resp, err := r.Send(ctx, ep, param, payload)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+			continue
+		}
+		loc, err := resp.URL.Parse(resp.Header.Get("Location"))
+		if err != nil || !strings.EqualFold(loc.Hostname(), redirectCanary) {
+			continue
+		}
+		return []DASTFinding{{
+			URL:         ep.baseURL(),
+			Method:      ep.Method,
+			Parameter:   param,
+			Payload:     payload,
+			Severity:    SeverityMedium,
+			VulnType:    VulnOpenRedirect,
+			CVSSScore:   6.1,
+			Evidence:    "Location: " + resp.Header.Get("Location"),
+			Remediation: "Only redirect to relative paths or an allowlist of trusted hosts.",
+		}}, nil
+	}
+	return nil, nil
+}
+
+// sqlErrorSignatures 常见数据库的错误回显特征
+var sqlErrorSignatures = regexp.MustCompile(`(?i)(you have an error in your sql syntax|warning: mysql|mysqli?_|SQLSTATE\[|ORA-\d{5}|PostgreSQL.{0,40}ERROR|pg_query\(|unterminated quoted string|syntax error at or near|SQLite3?::|sqlite3\.OperationalError|Microsoft OLE DB Provider for SQL Server|Unclosed quotation mark|java\.sql\.SQLException|quoted string not properly terminated)`)
+
+// sqlErrorCheck 注入引号，检查响应是否出现基线中没有的数据库错误
+type sqlErrorCheck struct{}
+
+func (sqlErrorCheck) Name() string { return "sql_error" }
+
+func (sqlErrorCheck) Probe(ctx context.Context, r *Requester, ep Endpoint, param string) ([]DASTFinding, error) {
+	baseline, err := r.Send(ctx, ep, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if sqlErrorSignatures.Match(baseline.Body) {
+		return nil, nil
+	}
+
+	payload := ep.Params.Get(param) + `'"`
+	resp, err := r.Send(ctx, ep, param, payload)
+	if err != nil {
+		return nil, err
+	}
+	match := sqlErrorSignatures.Find(resp.Body)
+	if match == nil {
+		return nil, nil
+	}
+	return []DASTFinding{{
+		URL:         ep.baseURL(),
+		Method:      ep.Method,
+		Parameter:   param,
+		Payload:     payload,
+		Severity:    SeverityHigh,
+		VulnType:    VulnSQLInjection,
+		CVSSScore:   8.6,
+		Evidence:    "database error: " + string(match),
+		Remediation: "Use parameterized queries and suppress database errors in responses.",
+	}}, nil
+}
+
+func randomToken() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
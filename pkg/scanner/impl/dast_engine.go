@@ -0,0 +1,541 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"go.uber.org/zap"
+	"golang.org/x/net/html"
+)
+
+// maxResponseBody 单个响应读取上限，超出部分截断
+const maxResponseBody = 2 << 20
+
+// ErrRequestBudgetExhausted 请求预算耗尽
+var ErrRequestBudgetExhausted = errors.New("dast request budget exhausted")
+
+// DASTFinding 动态扫描发现项，字段与存储层 DASTModel 一一对应
+type DASTFinding struct {
+	URL         string  `json:"url"`
+	Method      string  `json:"method"`
+	Parameter   string  `json:"parameter,omitempty"`
+	Payload     string  `json:"payload,omitempty"`
+	Severity    string  `json:"severity"`
+	VulnType    string  `json:"vuln_type"`
+	CVSSScore   float64 `json:"cvss_score"`
+	Evidence    string  `json:"evidence,omitempty"`
+	Remediation string  `json:"remediation,omitempty"`
+}
+
+// DASTReport 一次动态扫描的汇总
+type DASTReport struct {
+	Target          string        `json:"target"`
+	PagesCrawled    int           `json:"pages_crawled"`
+	Endpoints       int           `json:"endpoints"`
+	Requests        int           `json:"requests"`
+	BudgetExhausted bool          `json:"budget_exhausted"`
+	Authenticated   bool          `json:"authenticated"`
+	Relogins        int           `json:"relogins"`
+	SessionLost     bool          `json:"session_lost"`
+	Findings        []DASTFinding `json:"findings"`
+}
+
+// Page 爬取到的页面
+type Page struct {
+	URL        *url.URL
+	Depth      int
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Links      []*url.URL
+	Forms      []Endpoint
+	Resources  []string // script/img/iframe/link 等子资源地址
+}
+
+// IsHTML 判断响应是否为 HTML
+func (p *Page) IsHTML() bool {
+	return strings.Contains(strings.ToLower(p.Header.Get("Content-Type")), "text/html")
+}
+
+// Endpoint 可注入参数的请求端点
+// GET 端点的参数来自查询串，POST 端点的参数来自表单字段默认值
+type Endpoint struct {
+	Method string
+	URL    *url.URL
+	Params url.Values
+}
+
+// key 按 方法/路径/参数名 去重，避免参数值变化导致无限爬取
+func (ep Endpoint) key() string {
+	names := make([]string, 0, len(ep.Params))
+	for name := range ep.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return ep.Method + " " + ep.URL.Scheme + "://" + ep.URL.Host + ep.URL.Path + "?" + strings.Join(names, "&")
+}
+
+// baseURL 返回去掉查询串的端点地址，参数信息记录在发现项的 Parameter 中
+func (ep Endpoint) baseURL() string {
+	u := *ep.URL
+	u.RawQuery = ""
+	return u.String()
+}
+
+// Response 简化的 HTTP 响应
+type Response struct {
+	URL        *url.URL
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Requester 带请求预算的 HTTP 客户端，爬虫与主动检测共用同一预算
+// 设置登录会话后为每个请求附加凭据，会话失效时重新登录并重发请求
+type Requester struct {
+	client    *http.Client
+	userAgent string
+	remaining int64
+	used      int64
+	session   *DASTSession
+	exclude   []*regexp.Regexp
+}
+
+// NewRequester 创建不自动跟随重定向的请求器，重定向由爬虫和检测项自行处理
+func NewRequester(cfg config.CrawlerConfig) *Requester {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.InsecureTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- 由配置显式开启
+	}
+	return &Requester{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.RequestTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent: cfg.UserAgent,
+		remaining: int64(cfg.MaxRequests),
+	}
+}
+
+// Used 返回已发出的请求数
+func (r *Requester) Used() int {
+	return int(atomic.LoadInt64(&r.used))
+}
+
+// Do 发送请求，预算耗尽时返回 ErrRequestBudgetExhausted，命中排除规则时返回 ErrExcludedURL
+func (r *Requester) Do(ctx context.Context, method string, u *url.URL, form url.Values) (*Response, error) {
+	if r.excluded(u) {
+		return nil, ErrExcludedURL
+	}
+	resp, err := r.send(ctx, method, u, form)
+	if err != nil || r.session == nil || !r.session.expired(resp) {
+		return resp, err
+	}
+	// 会话无法恢复时返回失效的响应，扫描继续，报告中标记会话丢失
+	if err := r.session.renew(ctx, r); err != nil {
+		return resp, nil
+	}
+	return r.send(ctx, method, u, form)
+}
+
+// excluded 判断地址是否命中排除规则，登录页只由会话访问
+func (r *Requester) excluded(u *url.URL) bool {
+	if r.session != nil && r.session.isLoginURL(u) {
+		return true
+	}
+	raw := u.String()
+	for _, re := range r.exclude {
+		if re.MatchString(raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// send 计入预算并发送单个请求
+func (r *Requester) send(ctx context.Context, method string, u *url.URL, form url.Values) (*Response, error) {
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+	if r.session != nil {
+		r.session.apply(req)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return r.exchange(r.client, req, u)
+}
+
+// exchange 计入预算后发送请求，响应体读取到 maxResponseBody 为止
+func (r *Requester) exchange(client *http.Client, req *http.Request, u *url.URL) (*Response, error) {
+	if atomic.AddInt64(&r.remaining, -1) < 0 {
+		return nil, ErrRequestBudgetExhausted
+	}
+	atomic.AddInt64(&r.used, 1)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, err
+	}
+	return &Response{URL: u, StatusCode: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
+
+// Send 将端点的某个参数替换为 value 后发送
+func (r *Requester) Send(ctx context.Context, ep Endpoint, param, value string) (*Response, error) {
+	params := url.Values{}
+	for k, v := range ep.Params {
+		params[k] = append([]string(nil), v...)
+	}
+	if param != "" {
+		params.Set(param, value)
+	}
+
+	if ep.Method == http.MethodPost {
+		return r.Do(ctx, http.MethodPost, ep.URL, params)
+	}
+	u := *ep.URL
+	u.RawQuery = params.Encode()
+	return r.Do(ctx, http.MethodGet, &u, nil)
+}
+
+// PassiveCheck 被动检测项，只分析爬取到的响应
+type PassiveCheck interface {
+	Name() string
+	Inspect(page *Page) []DASTFinding
+}
+
+// ActiveCheck 主动检测项，针对端点的单个参数发送探测请求
+type ActiveCheck interface {
+	Name() string
+	Probe(ctx context.Context, r *Requester, ep Endpoint, param string) ([]DASTFinding, error)
+}
+
+// DASTEngine 爬虫与检测引擎
+type DASTEngine struct {
+	cfg     config.CrawlerConfig
+	passive []PassiveCheck
+	active  []ActiveCheck
+	logger  *zap.Logger
+	session *DASTSession
+	exclude []*regexp.Regexp
+}
+
+// DASTEngineOption 检测引擎选项
+type DASTEngineOption func(*DASTEngine)
+
+// WithDASTSession 使用登录会话扫描
+func WithDASTSession(session *DASTSession) DASTEngineOption {
+	return func(e *DASTEngine) {
+		e.session = session
+	}
+}
+
+// WithDASTExclusions 设置不爬取、不探测的 URL 规则
+func WithDASTExclusions(exclude []*regexp.Regexp) DASTEngineOption {
+	return func(e *DASTEngine) {
+		e.exclude = exclude
+	}
+}
+
+// NewDASTEngine 创建检测引擎，被动检测固定启用，主动检测由调用方选择
+func NewDASTEngine(cfg config.CrawlerConfig, active []ActiveCheck, logger *zap.Logger, opts ...DASTEngineOption) *DASTEngine {
+	e := &DASTEngine{
+		cfg:     cfg,
+		passive: defaultPassiveChecks(),
+		active:  active,
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run 爬取目标站点并执行检测
+func (e *DASTEngine) Run(ctx context.Context, target *url.URL) (*DASTReport, error) {
+	requester := NewRequester(e.cfg)
+	requester.exclude = e.exclude
+	report := &DASTReport{Target: target.String(), Findings: []DASTFinding{}}
+	if e.session != nil {
+		// 会话 Cookie 由 Cookie 容器维护，重新登录后自动替换
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		requester.client.Jar = jar
+		requester.session = e.session
+		if err := e.session.start(ctx, requester, target); err != nil {
+			return nil, err
+		}
+		report.Authenticated = true
+	}
+	seen := make(map[string]bool)
+	add := func(findings []DASTFinding) {
+		for _, f := range findings {
+			key := strings.Join([]string{f.VulnType, f.Method, f.URL, f.Parameter}, "|")
+			if !seen[key] {
+				seen[key] = true
+				report.Findings = append(report.Findings, f)
+			}
+		}
+	}
+
+	// 1. 爬取并执行被动检测
+	pages, endpoints, err := e.crawl(ctx, requester, target)
+	report.PagesCrawled = len(pages)
+	report.Endpoints = len(endpoints)
+	if errors.Is(err, ErrRequestBudgetExhausted) {
+		report.BudgetExhausted = true
+	} else if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("target %s is unreachable", target)
+	}
+	for _, page := range pages {
+		for _, check := range e.passive {
+			add(check.Inspect(page))
+		}
+	}
+
+	// 2. 主动检测
+activeLoop:
+	for _, ep := range endpoints {
+		params := make([]string, 0, len(ep.Params))
+		for name := range ep.Params {
+			params = append(params, name)
+		}
+		sort.Strings(params)
+
+		for _, param := range params {
+			for _, check := range e.active {
+				findings, err := check.Probe(ctx, requester, ep, param)
+				add(findings)
+				switch {
+				case errors.Is(err, ErrRequestBudgetExhausted):
+					report.BudgetExhausted = true
+					break activeLoop
+				case ctx.Err() != nil:
+					return nil, ctx.Err()
+				case err != nil:
+					e.logger.Debug("active check failed",
+						zap.String("check", check.Name()),
+						zap.String("url", ep.URL.String()),
+						zap.String("param", param),
+						zap.Error(err))
+				}
+			}
+		}
+	}
+
+	report.Requests = requester.Used()
+	if e.session != nil {
+		report.Relogins = e.session.Relogins()
+		report.SessionLost = e.session.Lost()
+	}
+	return report, nil
+}
+
+// crawl 在同源范围内广度优先爬取，返回页面及可注入端点
+func (e *DASTEngine) crawl(ctx context.Context, r *Requester, target *url.URL) ([]*Page, []Endpoint, error) {
+	type item struct {
+		u     *url.URL
+		depth int
+	}
+	var (
+		pages     []*Page
+		endpoints []Endpoint
+		queue     = []item{{u: target, depth: 0}}
+		visited   = make(map[string]bool)
+		epSeen    = make(map[string]bool)
+	)
+	addEndpoint := func(ep Endpoint) {
+		if len(ep.Params) == 0 || epSeen[ep.key()] || r.excluded(ep.URL) {
+			return
+		}
+		epSeen[ep.key()] = true
+		endpoints = append(endpoints, ep)
+	}
+
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return pages, endpoints, err
+		}
+		cur := queue[0]
+		queue = queue[1:]
+
+		getEp := Endpoint{Method: http.MethodGet, URL: cur.u, Params: cur.u.Query()}
+		if visited[getEp.key()] || !sameOrigin(target, cur.u) {
+			continue
+		}
+		visited[getEp.key()] = true
+
+		resp, err := r.Do(ctx, http.MethodGet, cur.u, nil)
+		if errors.Is(err, ErrRequestBudgetExhausted) {
+			return pages, endpoints, err
+		}
+		if err != nil {
+			e.logger.Debug("crawl request failed", zap.String("url", cur.u.String()), zap.Error(err))
+			continue
+		}
+
+		page := &Page{URL: cur.u, Depth: cur.depth, StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body}
+		if loc := resp.Header.Get("Location"); loc != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			if u, err := cur.u.Parse(loc); err == nil {
+				page.Links = append(page.Links, u)
+			}
+		}
+		if page.IsHTML() {
+			parseHTML(page)
+		}
+		pages = append(pages, page)
+		addEndpoint(getEp)
+
+		for _, form := range page.Forms {
+			if !sameOrigin(target, form.URL) {
+				continue
+			}
+			if form.Method == http.MethodGet {
+				u := *form.URL
+				u.RawQuery = form.Params.Encode()
+				page.Links = append(page.Links, &u)
+			} else if e.cfg.SubmitForms {
+				addEndpoint(form)
+			}
+		}
+
+		if cur.depth >= e.cfg.MaxDepth {
+			continue
+		}
+		for _, link := range page.Links {
+			if sameOrigin(target, link) {
+				queue = append(queue, item{u: link, depth: cur.depth + 1})
+			}
+		}
+	}
+	return pages, endpoints, nil
+}
+
+// parseHTML 提取链接、表单和子资源
+func parseHTML(page *Page) {
+	var form *Endpoint
+	resolve := func(ref string) *url.URL {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			return nil
+		}
+		u, err := page.URL.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil
+		}
+		u.Fragment = ""
+		return u
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(page.Body))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if form != nil {
+				page.Forms = append(page.Forms, *form)
+			}
+			return
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "form" && form != nil {
+				page.Forms = append(page.Forms, *form)
+				form = nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			attrs := make(map[string]string, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs[strings.ToLower(a.Key)] = a.Val
+			}
+
+			switch tok.Data {
+			case "a", "area":
+				if u := resolve(attrs["href"]); u != nil {
+					page.Links = append(page.Links, u)
+				}
+			case "frame", "iframe":
+				if u := resolve(attrs["src"]); u != nil {
+					page.Links = append(page.Links, u)
+				}
+				page.Resources = appendNonEmpty(page.Resources, attrs["src"])
+			case "script", "img", "audio", "video", "source", "embed":
+				page.Resources = appendNonEmpty(page.Resources, attrs["src"])
+			case "link":
+				page.Resources = appendNonEmpty(page.Resources, attrs["href"])
+			case "form":
+				if form != nil {
+					page.Forms = append(page.Forms, *form)
+				}
+				action := resolve(attrs["action"])
+				if action == nil {
+					u := *page.URL
+					u.RawQuery = ""
+					action = &u
+				}
+				method := http.MethodGet
+				if strings.EqualFold(attrs["method"], http.MethodPost) {
+					method = http.MethodPost
+				}
+				form = &Endpoint{Method: method, URL: action, Params: url.Values{}}
+			case "input", "textarea", "select":
+				if form == nil || attrs["name"] == "" {
+					continue
+				}
+				switch strings.ToLower(attrs["type"]) {
+				case "submit", "button", "image", "reset", "file":
+					continue
+				}
+				form.Params.Set(attrs["name"], attrs["value"])
+			}
+		}
+	}
+}
+
+func appendNonEmpty(list []string, v string) []string {
+	if v = strings.TrimSpace(v); v != "" {
+		return append(list, v)
+	}
+	return list
+}
+
+// sameOrigin 判断协议、主机与端口是否一致
+func sameOrigin(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && strings.EqualFold(a.Host, b.Host)
+}
+
+// originURL 返回站点根地址，用于站点级发现项去重
+func originURL(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + "/"
+}
@@ -2,18 +2,31 @@ package scanner_impl
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/config"
 
 	"github.com/blackarbiter/go-sac/pkg/domain"
 	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
 	"go.uber.org/zap"
 )
 
+// DAST 任务选项中约定的目标描述字段
+const (
+	OptionTargetURL  = "target_url"  // 扫描入口地址
+	OptionDomainName = "domain_name" // 域名资产（DomainAsset.DomainName），未指定 target_url 时按 https 访问
+)
+
 // DASTScanner 动态应用安全测试扫描器
 type DASTScanner struct {
 	*BaseScanner
+	crawler      config.CrawlerConfig
+	activeChecks []string
+	keys         *crypt.KeyManager // 解密登录凭据，与任务服务共用 security.aes_key
 }
 
 // NewDASTScanner 创建DAST扫描器
@@ -27,6 +40,9 @@ func NewDASTScanner(
 
 	// 从配置获取参数
 	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeDast)
+	s.crawler, s.activeChecks = config.GetDASTConfig()
+	s.keys = crypt.NewKeyManager([]byte(config.Security.AESKey), 0)
+
 	baseOpts := []BaseScannerOption{
 		WithResourceProfile(resourceProfile),
 		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
@@ -49,20 +65,43 @@ func NewDASTScanner(
 func (d *DASTScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
 	result := domain.NewScanResult(task.TaskID, domain.ScanTypeDast, task.AssetID, task.AssetType)
 
+	var opts domain.DASTOptions
+	err := task.DecodeOptions(&opts)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	target, err := dastTarget(opts)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	checks, err := NewActiveChecks(d.activeChecks)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	engineOpts, err := d.engineOptions(opts, target)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
 	d.logger.Info("starting DAST scan",
 		zap.String("task_id", task.TaskID),
-		zap.String("scan_type", string(task.ScanType)))
-	time.Sleep(5 * time.Second)
-
-	// 使用超时控制执行扫描
-	err := d.ExecuteWithTimeout(ctx, task, func(ctx context.Context) error {
-		// 2. 模拟扫描过程
-		select {
-		case <-time.After(10 * time.Second):
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		zap.String("target", target.String()),
+		zap.Bool("authenticated", opts.Auth != nil),
+		zap.Int("max_depth", d.crawler.MaxDepth),
+		zap.Int("max_requests", d.crawler.MaxRequests))
+
+	// 使用超时控制执行扫描，超时按请求预算放宽；目标不可达等失败只计入该主机的熔断器，不影响其他目标
+	d.SetTargetSize(task, scanner.TargetSize{Items: d.crawler.MaxRequests})
+	var report *DASTReport
+	hostBreaker := d.circuitBreakers.ForHost(domain.ScanTypeDast, target.Hostname())
+	err = d.ExecuteWithBreaker(ctx, task, hostBreaker, func(ctx context.Context) error {
+		var runErr error
+		report, runErr = NewDASTEngine(d.crawler, checks, d.logger, engineOpts...).Run(ctx, target)
+		return runErr
 	})
 
 	if err != nil {
@@ -70,10 +109,115 @@ func (d *DASTScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*
 		return result, err
 	}
 
-	result.SetSuccess(task.Options)
+	result.SetSuccess(map[string]interface{}{
+		"target":           report.Target,
+		"pages_crawled":    report.PagesCrawled,
+		"endpoints":        report.Endpoints,
+		"requests":         report.Requests,
+		"budget_exhausted": report.BudgetExhausted,
+		"authenticated":    report.Authenticated,
+		"relogins":         report.Relogins,
+		"session_lost":     report.SessionLost,
+		"findings":         report.Findings,
+		"summary":          summarizeDASTFindings(report.Findings),
+	})
+	result.SetFindings(d.findingReport(dastFindings(report.Findings)))
 	return result, nil
 }
 
+// dastTarget 从任务选项解析扫描入口，只允许 http/https
+func dastTarget(opts domain.DASTOptions) (*url.URL, error) {
+	raw := strings.TrimSpace(opts.TargetURL)
+	if raw == "" {
+		domainName := strings.TrimSpace(opts.DomainName)
+		if domainName == "" {
+			return nil, fmt.Errorf("missing %s or %s in task options", OptionTargetURL, OptionDomainName)
+		}
+		raw = "https://" + strings.TrimSuffix(domainName, ".") + "/"
+	}
+
+	target, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target url: %w", err)
+	}
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("unsupported target url: %s", raw)
+	}
+	target.Fragment = ""
+	if target.Path == "" {
+		target.Path = "/"
+	}
+	return target, nil
+}
+
+// engineOptions 根据任务选项创建登录会话与排除规则
+func (d *DASTScanner) engineOptions(opts domain.DASTOptions, target *url.URL) ([]DASTEngineOption, error) {
+	exclude, err := compileExclusions(opts.Exclude, opts.Auth != nil)
+	if err != nil {
+		return nil, err
+	}
+	engineOpts := []DASTEngineOption{WithDASTExclusions(exclude)}
+	if opts.Auth != nil {
+		session, err := NewDASTSession(opts.Auth, target, d.keys)
+		if err != nil {
+			return nil, err
+		}
+		engineOpts = append(engineOpts, WithDASTSession(session))
+	}
+	return engineOpts, nil
+}
+
+// summarizeDASTFindings 按严重等级和漏洞类型统计发现项
+func summarizeDASTFindings(findings []DASTFinding) map[string]interface{} {
+	bySeverity := make(map[string]int)
+	byType := make(map[string]int)
+	for _, f := range findings {
+		bySeverity[f.Severity]++
+		byType[f.VulnType]++
+	}
+	return map[string]interface{}{
+		"total":       len(findings),
+		"by_severity": bySeverity,
+		"by_type":     byType,
+	}
+}
+
+// dastFindings 转换为统一发现项，物理位置为请求 URL，逻辑位置为注入参数
+func dastFindings(findings []DASTFinding) []domain.Finding {
+	out := make([]domain.Finding, 0, len(findings))
+	for _, f := range findings {
+		message := fmt.Sprintf("%s at %s %s", f.VulnType, f.Method, f.URL)
+		if f.Parameter != "" {
+			message += fmt.Sprintf(" (parameter %s)", f.Parameter)
+		}
+		finding := domain.Finding{
+			RuleID:      f.VulnType,
+			RuleName:    f.VulnType,
+			Severity:    f.Severity,
+			Message:     message,
+			Category:    "dast",
+			Location:    domain.FindingLocation{Path: f.URL},
+			Remediation: f.Remediation,
+			Properties: map[string]interface{}{
+				"method":     f.Method,
+				"cvss_score": f.CVSSScore,
+			},
+		}
+		if f.Parameter != "" {
+			finding.Location.Logical = f.Parameter
+			finding.Location.LogicalKind = "parameter"
+		}
+		if f.Payload != "" {
+			finding.Properties["payload"] = f.Payload
+		}
+		if f.Evidence != "" {
+			finding.Properties["evidence"] = f.Evidence
+		}
+		out = append(out, finding)
+	}
+	return out
+}
+
 // AsyncExecute 实现TaskExecutor接口
 func (d *DASTScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
 	return d.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
@@ -90,12 +234,12 @@ func (d *DASTScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayl
 
 // Cancel 实现TaskExecutor接口
 func (s *DASTScanner) Cancel(handle string) error {
-	return nil
+	return s.BaseScanner.CancelExecution(handle)
 }
 
 // GetStatus 实现TaskExecutor接口
 func (s *DASTScanner) GetStatus(handle string) (domain.TaskStatus, error) {
-	return domain.TaskStatusCompleted, nil
+	return s.BaseScanner.ExecutionStatus(handle)
 }
 
 // HealthCheck 实现TaskExecutor接口
@@ -0,0 +1,186 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newVulnerableSite 模拟一个包含典型漏洞的站点，并记录收到的请求路径
+func newVulnerableSite(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		hits []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Server", "Apache/2.4.1")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body>
+			<a href="/search?q=test">search</a>
+			<a href="/go?next=/home">home</a>
+			<a href="/item?id=1">item</a>
+			<a href="/deep/1">deep</a>
+			<a href="http://other.invalid/">external</a>
+			<form action="/login" method="post"><input name="user" value="a"><input type="submit" name="go"></form>
+		</body></html>`)
+	})
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<p>results for %s</p>", r.URL.Query().Get("q"))
+	})
+	mux.HandleFunc("/go", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("next"), http.StatusFound)
+	})
+	mux.HandleFunc("/item", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if strings.Contains(r.URL.Query().Get("id"), "'") {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "You have an error in your SQL syntax near ''1''")
+			return
+		}
+		fmt.Fprint(w, "<p>item</p>")
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<p>hello %s</p>", html.EscapeString(r.FormValue("user")))
+	})
+	mux.HandleFunc("/deep/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/deep/"), "%d", &n)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<a href="/deep/%d">next</a>`, n+1)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, r.URL.Path)
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), hits...)
+	}
+}
+
+func newTestDASTScanner(maxRequests int) *DASTScanner {
+	cfg := &config.Config{}
+	cfg.Security.AESKey = testAESKey
+	cfg.Scanner.DAST.Timeout = 30 * time.Second
+	cfg.Scanner.DAST.Crawler = config.CrawlerConfig{
+		MaxDepth:       2,
+		MaxRequests:    maxRequests,
+		RequestTimeout: 5 * time.Second,
+		SubmitForms:    true,
+	}
+	return NewDASTScanner(nil, zap.NewNop(), cfg).(*DASTScanner)
+}
+
+func TestDASTScanner_FindsPassiveAndActiveIssues(t *testing.T) {
+	srv, hits := newVulnerableSite(t)
+	s := newTestDASTScanner(200)
+
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-dast",
+		AssetType: domain.AssetTypeDomain,
+		Options:   map[string]interface{}{OptionTargetURL: srv.URL},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, false, result.Result["budget_exhausted"])
+
+	findings := result.Result["findings"].([]DASTFinding)
+	byType := make(map[string][]DASTFinding)
+	for _, f := range findings {
+		byType[f.VulnType] = append(byType[f.VulnType], f)
+	}
+
+	require.Len(t, byType[VulnReflectedXSS], 1)
+	assert.Equal(t, srv.URL+"/search", byType[VulnReflectedXSS][0].URL)
+	assert.Equal(t, "q", byType[VulnReflectedXSS][0].Parameter)
+
+	require.Len(t, byType[VulnOpenRedirect], 1)
+	assert.Equal(t, "next", byType[VulnOpenRedirect][0].Parameter)
+
+	require.Len(t, byType[VulnSQLInjection], 1)
+	assert.Equal(t, srv.URL+"/item", byType[VulnSQLInjection][0].URL)
+	assert.Equal(t, SeverityHigh, byType[VulnSQLInjection][0].Severity)
+
+	// 登录表单对输入做了编码，不应报告 XSS
+	for _, f := range findings {
+		assert.NotEqual(t, srv.URL+"/login", f.URL, "unexpected finding %+v", f)
+	}
+
+	headers := make(map[string]bool)
+	for _, f := range byType[VulnMissingSecurityHeader] {
+		headers[f.Parameter] = true
+		assert.Equal(t, srv.URL+"/", f.URL)
+	}
+	assert.True(t, headers["Content-Security-Policy"])
+	assert.True(t, headers["X-Frame-Options"])
+	assert.False(t, headers["Strict-Transport-Security"], "HSTS only applies to https targets")
+
+	require.Len(t, byType[VulnInsecureCookie], 1)
+	assert.Equal(t, "session", byType[VulnInsecureCookie][0].Parameter)
+	require.Len(t, byType[VulnInformationDisclosure], 1)
+	assert.Contains(t, byType[VulnInformationDisclosure][0].Evidence, "Apache/2.4.1")
+
+	// 深度限制：/deep/1 位于深度1，/deep/2 位于深度2，不会继续爬到 /deep/3
+	assert.Contains(t, hits(), "/deep/2")
+	assert.NotContains(t, hits(), "/deep/3")
+}
+
+func TestDASTScanner_RequestBudget(t *testing.T) {
+	srv, hits := newVulnerableSite(t)
+	s := newTestDASTScanner(3)
+
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-budget",
+		Options: map[string]interface{}{OptionTargetURL: srv.URL},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Result["budget_exhausted"])
+	assert.Equal(t, 3, result.Result["requests"])
+	assert.Len(t, hits(), 3)
+}
+
+func TestDASTTarget(t *testing.T) {
+	u, err := dastTarget(domain.DASTOptions{DomainName: "example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/", u.String())
+
+	_, err = dastTarget(domain.DASTOptions{TargetURL: "file:///etc/passwd"})
+	assert.Error(t, err)
+	_, err = dastTarget(domain.DASTOptions{})
+	assert.Error(t, err)
+}
+
+func TestNewActiveChecks_Registry(t *testing.T) {
+	_, err := NewActiveChecks([]string{"does_not_exist"})
+	assert.Error(t, err)
+
+	checks, err := NewActiveChecks(nil)
+	require.NoError(t, err)
+	assert.Len(t, checks, 3)
+}
@@ -0,0 +1,238 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+)
+
+// executionRetention 已结束执行的状态保留时间，过期后 GetStatus 返回 ErrExecutionNotFound
+const executionRetention = time.Hour
+
+// trackedProcess 执行中启动的子进程，done 在 ExecuteCommand 返回时关闭
+type trackedProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// execution 单次执行的登记信息
+type execution struct {
+	info      scanner.ExecutionInfo
+	cancel    context.CancelFunc // 运行中执行的上下文取消函数
+	cancelled bool
+	processes map[string]*trackedProcess
+	size      scanner.TargetSize // 目标规模，用于计算超时
+	logs      []string           // 已上传的命令输出对象路径
+}
+
+// executionRegistry 按句柄（任务ID）登记执行状态，供 Cancel/GetStatus 查询
+type executionRegistry struct {
+	mu         sync.Mutex
+	scanType   domain.ScanType
+	executions map[string]*execution
+}
+
+func newExecutionRegistry(scanType domain.ScanType) *executionRegistry {
+	return &executionRegistry{
+		scanType:   scanType,
+		executions: make(map[string]*execution),
+	}
+}
+
+// newExecution 创建登记项，调用方需持有锁；同一句柄仍在执行时返回错误
+func (r *executionRegistry) newExecution(handle string, status domain.TaskStatus) (*execution, error) {
+	now := time.Now()
+	for h, e := range r.executions {
+		if !e.info.FinishedAt.IsZero() && now.Sub(e.info.FinishedAt) > executionRetention {
+			delete(r.executions, h)
+		}
+	}
+	if e, ok := r.executions[handle]; ok && e.info.FinishedAt.IsZero() {
+		return nil, fmt.Errorf("execution %s already %s", handle, e.info.Status)
+	}
+	e := &execution{
+		info: scanner.ExecutionInfo{
+			Handle:    handle,
+			ScanType:  r.scanType,
+			Status:    status,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		processes: make(map[string]*trackedProcess),
+	}
+	r.executions[handle] = e
+	return e, nil
+}
+
+// register 登记异步提交、尚未开始的执行
+func (r *executionRegistry) register(handle string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.newExecution(handle, domain.TaskStatusPending)
+	return err
+}
+
+// start 将执行标记为运行中；等待期间已被取消时返回 false
+func (r *executionRegistry) start(handle string, cancel context.CancelFunc) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok || !e.info.FinishedAt.IsZero() {
+		var err error
+		if e, err = r.newExecution(handle, domain.TaskStatusPending); err != nil {
+			return false, err
+		}
+	} else if !e.info.StartedAt.IsZero() {
+		return false, fmt.Errorf("execution %s already %s", handle, e.info.Status)
+	}
+	if e.cancelled {
+		return false, nil
+	}
+	now := time.Now()
+	e.info.Status = domain.TaskStatusRunning
+	e.info.StartedAt = now
+	e.info.UpdatedAt = now
+	e.cancel = cancel
+	return true, nil
+}
+
+// finish 记录执行结束并返回最终状态，已取消的执行保持取消状态
+func (r *executionRegistry) finish(handle string, err error) domain.TaskStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok {
+		if err != nil {
+			return domain.TaskStatusFailed
+		}
+		return domain.TaskStatusCompleted
+	}
+	now := time.Now()
+	switch {
+	case e.cancelled:
+		e.info.Status = domain.TaskStatusCancelled
+	case err != nil:
+		e.info.Status = domain.TaskStatusFailed
+	default:
+		e.info.Status = domain.TaskStatusCompleted
+	}
+	if err != nil {
+		e.info.Error = err.Error()
+	}
+	e.info.FinishedAt = now
+	e.info.UpdatedAt = now
+	e.cancel = nil
+	return e.info.Status
+}
+
+// attachProcess 将子进程关联到执行，返回的 detach 在进程结束后调用
+func (r *executionRegistry) attachProcess(handle, execID string, cmd *exec.Cmd) (detach func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok {
+		return func() {}
+	}
+	p := &trackedProcess{cmd: cmd, done: make(chan struct{})}
+	e.processes[execID] = p
+	e.info.UpdatedAt = time.Now()
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(e.processes, execID)
+		e.info.UpdatedAt = time.Now()
+		close(p.done)
+	}
+}
+
+// requestCancel 标记执行为已取消，返回需要终止的子进程与上下文取消函数
+func (r *executionRegistry) requestCancel(handle string) ([]*trackedProcess, context.CancelFunc, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", scanner.ErrExecutionNotFound, handle)
+	}
+	if !e.info.FinishedAt.IsZero() {
+		return nil, nil, fmt.Errorf("execution %s already %s", handle, e.info.Status)
+	}
+	e.cancelled = true
+	e.info.Status = domain.TaskStatusCancelled
+	e.info.UpdatedAt = time.Now()
+
+	procs := make([]*trackedProcess, 0, len(e.processes))
+	for _, p := range e.processes {
+		procs = append(procs, p)
+	}
+	cancel := e.cancel
+	if cancel == nil {
+		cancel = func() {}
+	}
+	return procs, cancel, nil
+}
+
+// isCancelled 执行是否已被取消
+func (r *executionRegistry) isCancelled(handle string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	return ok && e.cancelled
+}
+
+// setTargetSize 登记执行的目标规模
+func (r *executionRegistry) setTargetSize(handle string, size scanner.TargetSize) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.executions[handle]; ok {
+		e.size = size
+	}
+}
+
+// timing 返回运行中执行的开始时间与目标规模，未在运行时开始时间为零值
+func (r *executionRegistry) timing(handle string) (time.Time, scanner.TargetSize) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok || !e.info.FinishedAt.IsZero() {
+		return time.Time{}, scanner.TargetSize{}
+	}
+	return e.info.StartedAt, e.size
+}
+
+// addLogObject 登记执行上传的命令输出对象
+func (r *executionRegistry) addLogObject(handle, objectPath string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.executions[handle]; ok {
+		e.logs = append(e.logs, objectPath)
+	}
+}
+
+// logObjects 返回执行上传的命令输出对象路径
+func (r *executionRegistry) logObjects(handle string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), e.logs...)
+}
+
+// get 返回执行详情
+func (r *executionRegistry) get(handle string) (scanner.ExecutionInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.executions[handle]
+	if !ok {
+		return scanner.ExecutionInfo{}, fmt.Errorf("%w: %s", scanner.ErrExecutionNotFound, handle)
+	}
+	info := e.info
+	info.Processes = len(e.processes)
+	return info, nil
+}
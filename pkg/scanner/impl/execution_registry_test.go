@@ -0,0 +1,161 @@
+package scanner_impl
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingStatusUpdater 记录上报的任务状态
+type recordingStatusUpdater struct {
+	mu       sync.Mutex
+	statuses []domain.TaskStatus
+}
+
+func (u *recordingStatusUpdater) UpdateTaskStatus(_ context.Context, _ string, status domain.TaskStatus) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.statuses = append(u.statuses, status)
+	return nil
+}
+
+func (u *recordingStatusUpdater) last() domain.TaskStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.statuses) == 0 {
+		return ""
+	}
+	return u.statuses[len(u.statuses)-1]
+}
+
+func newExecutionTestScanner(t *testing.T) (*BaseScanner, *recordingStatusUpdater) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.ProcessSandbox.WorkDir = t.TempDir()
+	bs := NewBaseScanner(domain.ScanTypeSca, nil, zap.NewNop(), cfg,
+		WithTimeout(time.Minute, 2*time.Second),
+		WithCircuitBreaker(cfg),
+	)
+	updater := &recordingStatusUpdater{}
+	bs.SetTaskStatusUpdater(updater)
+	return bs, updater
+}
+
+func waitForStatus(t *testing.T, bs *BaseScanner, handle string, want domain.TaskStatus) scanner.ExecutionInfo {
+	t.Helper()
+	var info scanner.ExecutionInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = bs.GetExecution(handle)
+		return err == nil && info.Status == want
+	}, 5*time.Second, 10*time.Millisecond)
+	return info
+}
+
+func TestBaseScanner_CancelRunningExecution(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	bs, updater := newExecutionTestScanner(t)
+	task := &domain.ScanTaskPayload{TaskID: "task-cancel", ScanType: domain.ScanTypeSca}
+
+	errCh := make(chan error, 1)
+	handle, err := bs.AsyncExecuteWithResult(context.Background(), task, func(ctx context.Context) (*domain.ScanResult, error) {
+		err := bs.ExecuteCommand(ctx, task, exec.Command("sleep", "30"), "scan")
+		errCh <- err
+		return &domain.ScanResult{TaskID: task.TaskID}, err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, task.TaskID, handle)
+
+	// 等待子进程启动
+	require.Eventually(t, func() bool {
+		info, err := bs.GetExecution(handle)
+		return err == nil && info.Processes == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	info, err := bs.GetExecution(handle)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusRunning, info.Status)
+	assert.False(t, info.StartedAt.IsZero())
+
+	start := time.Now()
+	require.NoError(t, bs.CancelExecution(handle))
+	assert.Less(t, time.Since(start), 2*time.Second, "SIGTERM should stop sleep within the graceful period")
+
+	cmdErr := <-errCh
+	assert.True(t, errors.Is(cmdErr, context.Canceled), "command error should be marked cancelled: %v", cmdErr)
+	info = waitForStatus(t, bs, handle, domain.TaskStatusCancelled)
+	assert.False(t, info.FinishedAt.IsZero())
+	assert.Zero(t, info.Processes)
+	require.Eventually(t, func() bool { return updater.last() == domain.TaskStatusCancelled }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, bs.circuitBreaker.IsOpen())
+
+	// 已结束的执行不能再次取消
+	assert.Error(t, bs.CancelExecution(handle))
+	status, err := bs.ExecutionStatus(handle)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusCancelled, status)
+}
+
+func TestBaseScanner_CancelPendingExecution(t *testing.T) {
+	bs, updater := newExecutionTestScanner(t)
+	task := &domain.ScanTaskPayload{TaskID: "task-pending"}
+
+	require.NoError(t, bs.executions.register(task.TaskID))
+	status, err := bs.ExecutionStatus(task.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusPending, status)
+
+	require.NoError(t, bs.CancelExecution(task.TaskID))
+	called := false
+	_, err = bs.ExecuteWithResult(context.Background(), task, func(context.Context) (*domain.ScanResult, error) {
+		called = true
+		return &domain.ScanResult{}, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called, "cancelled execution must not start")
+	assert.Equal(t, []domain.TaskStatus{domain.TaskStatusCancelled}, updater.statuses)
+
+	// 同一句柄可以重新执行
+	_, err = bs.ExecuteWithResult(context.Background(), task, func(context.Context) (*domain.ScanResult, error) {
+		return &domain.ScanResult{}, nil
+	})
+	require.NoError(t, err)
+	status, err = bs.ExecutionStatus(task.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusCompleted, status)
+}
+
+func TestBaseScanner_ExecutionStatus(t *testing.T) {
+	bs, _ := newExecutionTestScanner(t)
+
+	_, err := bs.ExecutionStatus("missing")
+	assert.ErrorIs(t, err, scanner.ErrExecutionNotFound)
+	assert.ErrorIs(t, bs.CancelExecution("missing"), scanner.ErrExecutionNotFound)
+
+	task := &domain.ScanTaskPayload{TaskID: "task-failed"}
+	_, err = bs.ExecuteWithResult(context.Background(), task, func(context.Context) (*domain.ScanResult, error) {
+		return nil, errors.New("tool crashed")
+	})
+	require.Error(t, err)
+	info, err := bs.GetExecution(task.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusFailed, info.Status)
+	assert.Equal(t, "tool crashed", info.Error)
+	assert.Equal(t, domain.ScanTypeSca, info.ScanType)
+
+	// 同一句柄执行中不允许重复提交
+	require.NoError(t, bs.executions.register("task-dup"))
+	_, err = bs.AsyncExecuteWithResult(context.Background(), &domain.ScanTaskPayload{TaskID: "task-dup"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,661 @@
+package scanner_impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// 主机基线控制项的检查方式
+const (
+	HostCheckSSHDConfig    = "sshd_config"
+	HostCheckSysctl        = "sysctl"
+	HostCheckWorldWritable = "world_writable"
+	HostCheckSudoers       = "sudoers"
+	HostCheckPackages      = "packages"
+)
+
+// 控制项检查结果
+const (
+	ControlStatusPass          = "pass"
+	ControlStatusFail          = "fail"
+	ControlStatusError         = "error"          // 无法完成检查，如文件不可读、缺少漏洞库
+	ControlStatusNotApplicable = "not_applicable" // 合规审计中控制项的依据扫描均没有结果
+)
+
+// 检查读取的目标系统文件
+var (
+	sshdConfigPath = "/etc/ssh/sshd_config"
+	sudoersPath    = "/etc/sudoers"
+	procSysDir     = "/proc/sys"
+)
+
+const (
+	hostMaxIncludeDepth    = 8    // sshd_config 与 sudoers 的 include 嵌套上限
+	hostWorldWritableLimit = 1000 // 可写文件查找结果上限
+)
+
+// HostBaselinePack 主机安全基线规则包（SecurityMaterialTypeScanRule）
+type HostBaselinePack struct {
+	MaterialHeader `yaml:",inline"`
+	Controls       []HostControl `yaml:"controls"`
+}
+
+// HostControl 基线控制项，Check 决定检查方式，其余字段为该检查方式的参数
+type HostControl struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Category    string   `yaml:"category"`
+	Severity    string   `yaml:"severity"`
+	Check       string   `yaml:"check"`
+	Key         string   `yaml:"key"`          // sshd_config 关键字或 sysctl 参数名
+	Allowed     []string `yaml:"allowed"`      // 合规取值
+	Max         *int     `yaml:"max"`          // sshd_config 数值上限
+	Default     string   `yaml:"default"`      // sshd_config 未配置时 sshd 的缺省值
+	Paths       []string `yaml:"paths"`        // world_writable 检查的目录
+	Patterns    []string `yaml:"patterns"`     // sudoers 中不允许出现的规则（正则）
+	MinSeverity string   `yaml:"min_severity"` // packages 计入的最低漏洞等级，为空时计入全部
+	Remediation string   `yaml:"remediation"`
+	References  []string `yaml:"references"`
+}
+
+// ControlResult 控制项检查结果及证据
+type ControlResult struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	Status      string   `json:"status"`
+	Message     string   `json:"message"`
+	Evidence    []string `json:"evidence,omitempty"`
+	Remediation string   `json:"remediation,omitempty"`
+	References  []string `json:"references,omitempty"`
+}
+
+// HostBaseline 已校验的主机安全基线
+type HostBaseline struct {
+	Ref      MaterialRef
+	Controls []HostControl
+	patterns map[string][]*regexp.Regexp // 按控制项编译的 sudoers 规则
+}
+
+// LoadHostBaseline 从 ScanRule 类型的安全物料中选取名为 name 的基线，pinned 非空时使用该版本
+func LoadHostBaseline(store *MaterialStore, name, pinned string) (*HostBaseline, error) {
+	files, err := store.Select(domain.SecurityMaterialTypeScanRule, pinned)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.Name != name {
+			continue
+		}
+		var pack HostBaselinePack
+		if err := f.Decode(&pack); err != nil {
+			return nil, err
+		}
+		baseline, err := newHostBaseline(&pack, f.Ref())
+		if err != nil {
+			return nil, fmt.Errorf("security material %s: %w", f.path, err)
+		}
+		return baseline, nil
+	}
+	if pinned != "" {
+		return nil, fmt.Errorf("host baseline %s@%s not found", name, pinned)
+	}
+	return nil, fmt.Errorf("host baseline %s not found", name)
+}
+
+// newHostBaseline 校验控制项参数并编译正则
+func newHostBaseline(pack *HostBaselinePack, ref MaterialRef) (*HostBaseline, error) {
+	b := &HostBaseline{Ref: ref, patterns: make(map[string][]*regexp.Regexp)}
+	seen := make(map[string]bool)
+	for _, ctl := range pack.Controls {
+		if ctl.ID == "" || seen[ctl.ID] {
+			return nil, fmt.Errorf("control id %q is empty or duplicated", ctl.ID)
+		}
+		seen[ctl.ID] = true
+		if ctl.Severity = domain.NormalizeSeverity(ctl.Severity); ctl.Severity == "" {
+			return nil, fmt.Errorf("control %s: invalid severity", ctl.ID)
+		}
+		if ctl.MinSeverity != "" && domain.NormalizeSeverity(ctl.MinSeverity) == "" {
+			return nil, fmt.Errorf("control %s: invalid min_severity %q", ctl.ID, ctl.MinSeverity)
+		}
+
+		switch ctl.Check {
+		case HostCheckSSHDConfig:
+			if ctl.Key == "" || (len(ctl.Allowed) == 0 && ctl.Max == nil) {
+				return nil, fmt.Errorf("control %s: sshd_config check requires key and allowed or max", ctl.ID)
+			}
+		case HostCheckSysctl:
+			if ctl.Key == "" || len(ctl.Allowed) == 0 {
+				return nil, fmt.Errorf("control %s: sysctl check requires key and allowed", ctl.ID)
+			}
+		case HostCheckWorldWritable:
+			if len(ctl.Paths) == 0 {
+				return nil, fmt.Errorf("control %s: world_writable check requires paths", ctl.ID)
+			}
+			for _, p := range ctl.Paths {
+				if !path.IsAbs(p) {
+					return nil, fmt.Errorf("control %s: path %q must be absolute", ctl.ID, p)
+				}
+			}
+		case HostCheckSudoers:
+			if len(ctl.Patterns) == 0 {
+				return nil, fmt.Errorf("control %s: sudoers check requires patterns", ctl.ID)
+			}
+			for _, p := range ctl.Patterns {
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return nil, fmt.Errorf("control %s: %w", ctl.ID, err)
+				}
+				b.patterns[ctl.ID] = append(b.patterns[ctl.ID], re)
+			}
+		case HostCheckPackages:
+		default:
+			return nil, fmt.Errorf("control %s: unsupported check %q", ctl.ID, ctl.Check)
+		}
+		b.Controls = append(b.Controls, ctl)
+	}
+	if len(b.Controls) == 0 {
+		return nil, errors.New("baseline has no controls")
+	}
+	return b, nil
+}
+
+// Select 返回指定的控制项（按基线中的顺序），ids 为空时返回全部
+func (b *HostBaseline) Select(ids []string) ([]HostControl, error) {
+	if len(ids) == 0 {
+		return b.Controls, nil
+	}
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var selected []HostControl
+	for _, ctl := range b.Controls {
+		if wanted[ctl.ID] {
+			selected = append(selected, ctl)
+			delete(wanted, ctl.ID)
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := make([]string, 0, len(wanted))
+		for id := range wanted {
+			unknown = append(unknown, id)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown controls in baseline %s@%s: %s", b.Ref.Name, b.Ref.Version, strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// hostCheckFunc 评估单个控制项，返回是否合规、说明与证据；error 表示无法完成检查
+type hostCheckFunc func(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error)
+
+var hostChecks = map[string]hostCheckFunc{
+	HostCheckSSHDConfig:    checkSSHDConfig,
+	HostCheckSysctl:        checkSysctl,
+	HostCheckWorldWritable: checkWorldWritable,
+	HostCheckSudoers:       checkSudoers,
+	HostCheckPackages:      checkPackages,
+}
+
+// HostChecker 通过 HostTransport 评估基线控制项，同一次检查中的配置文件与包数据库只读取一次
+type HostChecker struct {
+	transport   HostTransport
+	baseline    *HostBaseline
+	maxEvidence int
+	vulnDB      func() (*VulnDB, error) // 只有 packages 检查需要，按需加载
+
+	sshd       map[string]sshdSetting
+	sshdErr    error
+	sudoers    []sudoersRule
+	sudoersErr error
+	sudoersOK  bool
+	packages   []Component
+	pkgErr     error
+	pkgOK      bool
+}
+
+// NewHostChecker 创建主机基线检查器
+func NewHostChecker(transport HostTransport, baseline *HostBaseline, maxEvidence int, vulnDB func() (*VulnDB, error)) *HostChecker {
+	return &HostChecker{transport: transport, baseline: baseline, maxEvidence: maxEvidence, vulnDB: vulnDB}
+}
+
+// Run 依次评估控制项，单个控制项无法完成检查时记为 error 并继续，ctx 结束时中止
+func (c *HostChecker) Run(ctx context.Context, controls []HostControl) ([]ControlResult, error) {
+	results := make([]ControlResult, 0, len(controls))
+	for i := range controls {
+		ctl := &controls[i]
+		res := ControlResult{
+			ID:          ctl.ID,
+			Title:       ctl.Title,
+			Category:    ctl.Category,
+			Severity:    ctl.Severity,
+			Remediation: ctl.Remediation,
+			References:  ctl.References,
+		}
+		pass, message, evidence, err := hostChecks[ctl.Check](ctx, c, ctl)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return results, ctxErr
+		}
+		switch {
+		case err != nil:
+			res.Status, res.Message = ControlStatusError, err.Error()
+		case pass:
+			res.Status, res.Message = ControlStatusPass, message
+		default:
+			res.Status, res.Message = ControlStatusFail, message
+		}
+		if len(evidence) > c.maxEvidence {
+			evidence = evidence[:c.maxEvidence]
+		}
+		res.Evidence = evidence
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// sshdSetting sshd_config 中生效的设置及其来源
+type sshdSetting struct {
+	value  string
+	source string
+}
+
+// sshdConfig 读取并解析 sshd_config，OpenSSH 对同一关键字取第一个出现的值
+func (c *HostChecker) sshdConfig(ctx context.Context) (map[string]sshdSetting, error) {
+	if c.sshd == nil && c.sshdErr == nil {
+		c.sshd = make(map[string]sshdSetting)
+		c.sshdErr = c.parseSSHDConfig(ctx, sshdConfigPath, 0)
+	}
+	return c.sshd, c.sshdErr
+}
+
+// parseSSHDConfig 解析单个配置文件；Match 之后的设置只对匹配的连接生效，基线只评估全局设置，
+// 因此遇到 Match 即停止读取该文件
+func (c *HostChecker) parseSSHDConfig(ctx context.Context, file string, depth int) error {
+	if depth > hostMaxIncludeDepth {
+		return fmt.Errorf("%s: include nesting too deep", file)
+	}
+	data, err := c.transport.ReadFile(ctx, file)
+	if err != nil {
+		return err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value := splitSSHDLine(line)
+		switch key = strings.ToLower(key); key {
+		case "match":
+			return nil
+		case "include":
+			for _, pattern := range strings.Fields(value) {
+				files, err := c.expandInclude(ctx, path.Dir(sshdConfigPath), pattern)
+				if err != nil {
+					return err
+				}
+				for _, f := range files {
+					if err := c.parseSSHDConfig(ctx, f, depth+1); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						return err
+					}
+				}
+			}
+		default:
+			if _, ok := c.sshd[key]; !ok {
+				c.sshd[key] = sshdSetting{value: strings.Trim(value, `"`), source: fmt.Sprintf("%s:%d", file, i+1)}
+			}
+		}
+	}
+	return nil
+}
+
+// splitSSHDLine 拆分关键字与取值，两者之间可以是空白或 '='
+func splitSSHDLine(line string) (string, string) {
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return line, ""
+	}
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return line[:end], value
+}
+
+// expandInclude 展开 include 路径，相对路径相对 base，通配符只支持出现在文件名中
+func (c *HostChecker) expandInclude(ctx context.Context, base, pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		pattern = path.Join(base, pattern)
+	}
+	dir, name := path.Split(pattern)
+	if !strings.ContainsAny(name, "*?[") {
+		return []string{pattern}, nil
+	}
+	names, err := c.transport.ReadDir(ctx, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, n := range names {
+		if ok, _ := path.Match(name, n); ok {
+			files = append(files, path.Join(dir, n))
+		}
+	}
+	return files, nil
+}
+
+// checkSSHDConfig 比较生效值与合规取值或数值上限，未配置时按 sshd 缺省值评估
+func checkSSHDConfig(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error) {
+	settings, err := c.sshdConfig(ctx)
+	if err != nil {
+		return false, "", nil, err
+	}
+	setting, ok := settings[strings.ToLower(ctl.Key)]
+	if !ok {
+		if ctl.Default == "" {
+			return false, fmt.Sprintf("%s is not configured", ctl.Key), nil, nil
+		}
+		setting = sshdSetting{value: ctl.Default, source: "sshd default"}
+	}
+	evidence := []string{fmt.Sprintf("%s %s (%s)", ctl.Key, setting.value, setting.source)}
+
+	if ctl.Max != nil {
+		n, err := strconv.Atoi(setting.value)
+		if err != nil {
+			return false, fmt.Sprintf("%s is %q, expected a number", ctl.Key, setting.value), evidence, nil
+		}
+		if n > *ctl.Max {
+			return false, fmt.Sprintf("%s is %d, expected at most %d", ctl.Key, n, *ctl.Max), evidence, nil
+		}
+		return true, fmt.Sprintf("%s is %d", ctl.Key, n), evidence, nil
+	}
+	for _, allowed := range ctl.Allowed {
+		if strings.EqualFold(setting.value, allowed) {
+			return true, fmt.Sprintf("%s is %s", ctl.Key, setting.value), evidence, nil
+		}
+	}
+	return false, fmt.Sprintf("%s is %s, expected one of %s", ctl.Key, setting.value, strings.Join(ctl.Allowed, ", ")), evidence, nil
+}
+
+// checkSysctl 读取 /proc/sys 下的运行时内核参数
+func checkSysctl(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error) {
+	data, err := c.transport.ReadFile(ctx, path.Join(procSysDir, strings.ReplaceAll(ctl.Key, ".", "/")))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, "", nil, fmt.Errorf("kernel parameter %s not available", ctl.Key)
+	}
+	if err != nil {
+		return false, "", nil, err
+	}
+	value := strings.Join(strings.Fields(string(data)), " ")
+	evidence := []string{fmt.Sprintf("%s = %s", ctl.Key, value)}
+	for _, allowed := range ctl.Allowed {
+		if value == allowed {
+			return true, fmt.Sprintf("%s is %s", ctl.Key, value), evidence, nil
+		}
+	}
+	return false, fmt.Sprintf("%s is %s, expected one of %s", ctl.Key, value, strings.Join(ctl.Allowed, ", ")), evidence, nil
+}
+
+// checkWorldWritable 查找其他用户可写的文件与目录
+func checkWorldWritable(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error) {
+	found, err := c.transport.FindWorldWritable(ctx, ctl.Paths, hostWorldWritableLimit)
+	if err != nil {
+		return false, "", nil, err
+	}
+	if len(found) == 0 {
+		return true, fmt.Sprintf("no world-writable entries under %s", strings.Join(ctl.Paths, ", ")), nil, nil
+	}
+	count := strconv.Itoa(len(found))
+	if len(found) >= hostWorldWritableLimit {
+		count = "at least " + count
+	}
+	return false, fmt.Sprintf("%s world-writable entries under %s", count, strings.Join(ctl.Paths, ", ")), found, nil
+}
+
+// sudoersRule sudoers 中的一条规则（已合并续行）
+type sudoersRule struct {
+	source string
+	text   string
+}
+
+// sudoersRules 读取 /etc/sudoers 及其 include 的文件
+func (c *HostChecker) sudoersRules(ctx context.Context) ([]sudoersRule, error) {
+	if !c.sudoersOK {
+		c.sudoersOK = true
+		c.sudoersErr = c.parseSudoers(ctx, sudoersPath, 0)
+	}
+	return c.sudoers, c.sudoersErr
+}
+
+// parseSudoers 解析 sudoers 文件，处理 #include/@include 与 #includedir/@includedir；
+// includedir 与 sudo 一致，跳过以 ~ 结尾或包含 '.' 的文件名
+func (c *HostChecker) parseSudoers(ctx context.Context, file string, depth int) error {
+	if depth > hostMaxIncludeDepth {
+		return fmt.Errorf("%s: include nesting too deep", file)
+	}
+	data, err := c.transport.ReadFile(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		start := i
+		text := strings.TrimSpace(lines[i])
+		for strings.HasSuffix(text, `\`) && i+1 < len(lines) {
+			i++
+			text = strings.TrimSpace(strings.TrimSuffix(text, `\`)) + " " + strings.TrimSpace(lines[i])
+		}
+
+		directive, arg, _ := strings.Cut(text, " ")
+		arg = strings.TrimSpace(arg)
+		switch directive {
+		case "#includedir", "@includedir":
+			names, err := c.transport.ReadDir(ctx, arg)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				if strings.HasSuffix(name, "~") || strings.Contains(name, ".") {
+					continue
+				}
+				if err := c.parseSudoers(ctx, path.Join(arg, name), depth+1); err != nil {
+					return err
+				}
+			}
+			continue
+		case "#include", "@include":
+			if !path.IsAbs(arg) {
+				arg = path.Join(path.Dir(file), arg)
+			}
+			if err := c.parseSudoers(ctx, arg, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		c.sudoers = append(c.sudoers, sudoersRule{source: fmt.Sprintf("%s:%d", file, start+1), text: text})
+	}
+	return nil
+}
+
+// checkSudoers 查找匹配任一禁止规则的 sudoers 条目
+func checkSudoers(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error) {
+	rules, err := c.sudoersRules(ctx)
+	if err != nil {
+		return false, "", nil, err
+	}
+	var evidence []string
+	for _, rule := range rules {
+		for _, re := range c.baseline.patterns[ctl.ID] {
+			if re.MatchString(rule.text) {
+				evidence = append(evidence, rule.source+": "+rule.text)
+				break
+			}
+		}
+	}
+	if len(evidence) == 0 {
+		return true, fmt.Sprintf("none of %d sudoers rules is affected", len(rules)), nil, nil
+	}
+	return false, fmt.Sprintf("%d sudoers rules are affected", len(evidence)), evidence, nil
+}
+
+// installedPackages 读取 dpkg/apk 包数据库，生态按 os-release 确定
+func (c *HostChecker) installedPackages(ctx context.Context) ([]Component, error) {
+	if c.pkgOK {
+		return c.packages, c.pkgErr
+	}
+	c.pkgOK = true
+
+	var osr *OSRelease
+	for _, rel := range osReleasePaths {
+		if data, err := c.transport.ReadFile(ctx, "/"+rel); err == nil {
+			if osr = parseOSRelease(data); osr != nil {
+				break
+			}
+		}
+	}
+	ecosystem := ""
+	if osr != nil {
+		ecosystem = osr.Ecosystem()
+	}
+
+	var components []Component
+	add := func(file string, parse func([]byte) []Component, fallback string) error {
+		data, err := c.transport.ReadFile(ctx, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, comp := range parse(data) {
+			comp.Source = file
+			comp.Ecosystem = firstNonEmpty(ecosystem, fallback)
+			components = append(components, comp)
+		}
+		return nil
+	}
+	if c.pkgErr = add("/"+dpkgStatusPath, parseDpkgStatus, "Debian"); c.pkgErr != nil {
+		return nil, c.pkgErr
+	}
+	if names, err := c.transport.ReadDir(ctx, "/"+dpkgStatusDirPath); err == nil {
+		for _, name := range names {
+			if c.pkgErr = add("/"+path.Join(dpkgStatusDirPath, name), parseDpkgStatus, "Debian"); c.pkgErr != nil {
+				return nil, c.pkgErr
+			}
+		}
+	}
+	if c.pkgErr = add("/"+apkInstalledPath, parseApkInstalled, "Alpine"); c.pkgErr != nil {
+		return nil, c.pkgErr
+	}
+	if len(components) == 0 {
+		c.pkgErr = errors.New("no dpkg or apk package database found")
+		return nil, c.pkgErr
+	}
+	c.packages = dedupeComponents(components)
+	return c.packages, nil
+}
+
+// checkPackages 查找存在已修复漏洞的软件包，即可以通过升级消除的漏洞
+func checkPackages(ctx context.Context, c *HostChecker, ctl *HostControl) (bool, string, []string, error) {
+	packages, err := c.installedPackages(ctx)
+	if err != nil {
+		return false, "", nil, err
+	}
+	if c.vulnDB == nil {
+		return false, "", nil, errors.New("vuln db not configured")
+	}
+	db, err := c.vulnDB()
+	if err != nil {
+		return false, "", nil, err
+	}
+
+	minRank := severityRank(domain.NormalizeSeverity(ctl.MinSeverity))
+	var evidence []string
+	for _, pkg := range packages {
+		res := matchComponent(db, pkg)
+		var fixable []Advisory
+		for _, a := range res.Advisories {
+			if len(a.FixedVersions) > 0 && severityRank(a.Severity) >= minRank {
+				fixable = append(fixable, a)
+			}
+		}
+		if len(fixable) == 0 {
+			continue
+		}
+		ids := make([]string, 0, len(fixable))
+		for _, a := range fixable {
+			ids = append(ids, a.ID)
+		}
+		evidence = append(evidence, fmt.Sprintf("%s %s -> %s (%s)",
+			pkg.Name, pkg.Version, recommendedVersion(versionComparer(pkg.Ecosystem), fixable), strings.Join(ids, ", ")))
+	}
+	if len(evidence) == 0 {
+		return true, fmt.Sprintf("none of %d installed packages has a pending security update", len(packages)), nil, nil
+	}
+	return false, fmt.Sprintf("%d of %d installed packages have pending security updates", len(evidence), len(packages)), evidence, nil
+}
+
+// summarizeHostChecks 统计各结果的控制项数及不合规项的严重等级分布
+func summarizeHostChecks(results []ControlResult) map[string]interface{} {
+	byStatus := map[string]int{ControlStatusPass: 0, ControlStatusFail: 0, ControlStatusError: 0}
+	failedBySeverity := make(map[string]int)
+	for _, r := range results {
+		byStatus[r.Status]++
+		if r.Status == ControlStatusFail {
+			failedBySeverity[r.Severity]++
+		}
+	}
+	return map[string]interface{}{
+		"controls":           len(results),
+		"passed":             byStatus[ControlStatusPass],
+		"failed":             byStatus[ControlStatusFail],
+		"errors":             byStatus[ControlStatusError],
+		"failed_by_severity": failedBySeverity,
+	}
+}
+
+// hostCheckFindings 不合规的控制项记为发现项，逻辑位置为目标主机
+func hostCheckFindings(host string, ref MaterialRef, results []ControlResult) []domain.Finding {
+	var out []domain.Finding
+	for _, r := range results {
+		if r.Status != ControlStatusFail {
+			continue
+		}
+		props := map[string]interface{}{
+			"baseline": ref.Name + "@" + ref.Version,
+			"evidence": r.Evidence,
+		}
+		if len(r.References) > 0 {
+			props["references"] = r.References
+		}
+		out = append(out, domain.Finding{
+			RuleID:      r.ID,
+			RuleName:    r.Title,
+			Severity:    r.Severity,
+			Message:     r.Message,
+			Category:    r.Category,
+			Location:    domain.FindingLocation{Logical: host, LogicalKind: "host"},
+			Remediation: r.Remediation,
+			Properties:  props,
+		})
+	}
+	return out
+}
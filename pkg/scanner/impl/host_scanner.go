@@ -0,0 +1,342 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/blackarbiter/go-sac/pkg/utils/crypt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostSecurityScanner 主机安全检查执行器，无需在目标主机上安装代理
+type HostSecurityScanner struct {
+	*BaseScanner
+	check     config.HostCheckConfig
+	materials config.SecurityMaterialConfig
+	store     materialStoreCache
+	vulnDB    vulnDBCache
+	keys      *crypt.KeyManager // 解密 SSH 凭据，与任务服务共用 security.aes_key
+}
+
+// NewHostSecurityScanner 创建主机安全检查执行器
+func NewHostSecurityScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &HostSecurityScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeHostSecurityCheck)
+	s.check, s.materials = config.GetHostCheckConfig()
+	s.keys = crypt.NewKeyManager([]byte(config.Security.AESKey), 0)
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeHostSecurityCheck,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *HostSecurityScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeHostSecurityCheck, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	var opts domain.HostCheckOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+	if opts.Transport == "" {
+		opts.Transport = domain.HostTransportSSH
+	}
+
+	// 1. 加载基线并选取控制项
+	baseline, err := s.loadBaseline(opts.BaselineVersion)
+	if err != nil {
+		return fail(err)
+	}
+	controls, err := baseline.Select(opts.Controls)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 2. 确定目标，SSH 目标的连接失败只计入该主机的熔断器
+	host, breaker := opts.RootDir, s.circuitBreaker
+	switch opts.Transport {
+	case domain.HostTransportSSH:
+		if host = strings.TrimSpace(opts.IPAddress); host == "" {
+			return fail(errors.New("missing ip_address in task options"))
+		}
+		breaker = s.circuitBreakers.ForHost(domain.ScanTypeHostSecurityCheck, host)
+	case domain.HostTransportLocal:
+		if opts.RootDir == "" {
+			return fail(errors.New("missing root_dir in task options"))
+		}
+	default:
+		return fail(fmt.Errorf("unsupported transport %q", opts.Transport))
+	}
+
+	s.logger.Info("starting host security check",
+		zap.String("task_id", task.TaskID),
+		zap.String("host", host),
+		zap.String("transport", opts.Transport),
+		zap.String("baseline", baseline.Ref.Name+"@"+baseline.Ref.Version),
+		zap.Int("controls", len(controls)))
+
+	// 3. 连接目标并逐项检查
+	s.SetTargetSize(task, scanner.TargetSize{Items: len(controls)})
+	var results []ControlResult
+	err = s.ExecuteWithBreaker(ctx, task, breaker, func(ctx context.Context) error {
+		transport, err := s.connect(ctx, opts)
+		if err != nil {
+			return err
+		}
+		defer transport.Close()
+
+		checker := NewHostChecker(transport, baseline, s.check.MaxEvidence, func() (*VulnDB, error) {
+			return s.vulnDB.get(s.check.VulnDBDir)
+		})
+		results, err = checker.Run(ctx, controls)
+		return err
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	summary := summarizeHostChecks(results)
+	s.logger.Info("host security check finished",
+		zap.String("task_id", task.TaskID),
+		zap.String("host", host),
+		zap.Any("summary", summary))
+
+	result.SetSuccess(map[string]interface{}{
+		"host":      host,
+		"transport": opts.Transport,
+		"baseline":  baseline.Ref,
+		"controls":  results,
+		"summary":   summary,
+	})
+	report := s.findingReport(hostCheckFindings(host, baseline.Ref, results))
+	report.Properties = map[string]interface{}{"baseline": baseline.Ref}
+	result.SetFindings(report)
+	return result, nil
+}
+
+// loadBaseline 加载配置的基线，任务固定的版本优先于配置中的版本
+func (s *HostSecurityScanner) loadBaseline(pinned string) (*HostBaseline, error) {
+	store, err := s.store.get(s.materials.MaterialDir)
+	if err != nil {
+		return nil, err
+	}
+	if pinned == "" {
+		pinned = pinnedMaterialVersion(s.materials.Versions, domain.SecurityMaterialTypeScanRule)
+	}
+	return LoadHostBaseline(store, s.check.Baseline, pinned)
+}
+
+// connect 按任务选项建立到目标主机的传输
+func (s *HostSecurityScanner) connect(ctx context.Context, opts domain.HostCheckOptions) (HostTransport, error) {
+	if opts.Transport == domain.HostTransportLocal {
+		root, err := s.localRoot(opts.RootDir)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalTransport(root)
+	}
+
+	if opts.SSH == nil || opts.SSH.Username == "" {
+		return nil, errors.New("missing ssh.username in task options")
+	}
+	cfg, err := s.sshClientConfig(opts.SSH, domain.HostCredentialScope(opts.IPAddress))
+	if err != nil {
+		return nil, err
+	}
+	port := opts.SSH.Port
+	if port == 0 {
+		port = 22
+	}
+	return DialSSH(ctx, net.JoinHostPort(opts.IPAddress, strconv.Itoa(port)), cfg)
+}
+
+// localRoot 校验 local 传输的根目录位于配置允许的目录下，防止任务读取扫描节点上的任意文件
+func (s *HostSecurityScanner) localRoot(dir string) (string, error) {
+	if len(s.check.LocalRoots) == 0 {
+		return "", errors.New("local transport is disabled, configure host_check.local_roots to enable it")
+	}
+	root, err := filepath.Abs(dir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("host root unavailable: %w", err)
+	}
+	for _, allowed := range s.check.LocalRoots {
+		base, err := filepath.Abs(allowed)
+		if err == nil {
+			base, err = filepath.EvalSymlinks(base)
+		}
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(base, root); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return root, nil
+		}
+	}
+	return "", fmt.Errorf("host root %s is outside host_check.local_roots", dir)
+}
+
+// sshClientConfig 解密凭据并确定主机公钥校验方式：任务固定的公钥优先，其次是 known_hosts
+// 凭据绑定目标主机加密，scope 与加密时的主机不一致时解密失败
+func (s *HostSecurityScanner) sshClientConfig(opts *domain.HostSSHOptions, scope string) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if opts.PrivateKey != "" {
+		pemKey, err := s.keys.OpenStringBound(opts.PrivateKey, scope)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt ssh private key: %w", err)
+		}
+		var signer ssh.Signer
+		if opts.Passphrase != "" {
+			passphrase, err := s.keys.OpenStringBound(opts.Passphrase, scope)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt ssh passphrase: %w", err)
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(pemKey), []byte(passphrase))
+			if err != nil {
+				return nil, fmt.Errorf("parse ssh private key: %w", err)
+			}
+		} else if signer, err = ssh.ParsePrivateKey([]byte(pemKey)); err != nil {
+			return nil, fmt.Errorf("parse ssh private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		password, err := s.keys.OpenStringBound(opts.Password, scope)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt ssh password: %w", err)
+		}
+		auth = append(auth, ssh.Password(password), ssh.KeyboardInteractive(
+			func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("ssh password or private_key is required")
+	}
+
+	hostKeyCallback, err := s.hostKeyCallback(opts.HostKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:            opts.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         s.check.ConnectTimeout,
+	}, nil
+}
+
+// hostKeyCallback 返回主机公钥校验函数，pinned 可以是 authorized_keys 格式的公钥或 SHA256 指纹
+func (s *HostSecurityScanner) hostKeyCallback(pinned string) (ssh.HostKeyCallback, error) {
+	if pinned = strings.TrimSpace(pinned); pinned != "" {
+		if strings.HasPrefix(pinned, "SHA256:") {
+			return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+				if subtle.ConstantTimeCompare([]byte(ssh.FingerprintSHA256(key)), []byte(pinned)) != 1 {
+					return fmt.Errorf("host key mismatch for %s: got %s", hostname, ssh.FingerprintSHA256(key))
+				}
+				return nil
+			}, nil
+		}
+		want, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pinned))
+		if err != nil {
+			return nil, fmt.Errorf("parse ssh.host_key: %w", err)
+		}
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			if !bytes.Equal(key.Marshal(), want.Marshal()) {
+				return fmt.Errorf("host key mismatch for %s: got %s", hostname, ssh.FingerprintSHA256(key))
+			}
+			return nil
+		}, nil
+	}
+	if s.check.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if s.check.KnownHosts == "" {
+		return nil, errors.New("host key verification requires ssh.host_key or host_check.known_hosts")
+	}
+	callback, err := knownhosts.New(s.check.KnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("load known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *HostSecurityScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *HostSecurityScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *HostSecurityScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *HostSecurityScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *HostSecurityScanner) HealthCheck() error {
+	if _, err := s.loadBaseline(""); err != nil {
+		return fmt.Errorf("host baseline unavailable: %w", err)
+	}
+	if s.check.KnownHosts != "" && !s.check.InsecureIgnoreHostKey {
+		if _, err := os.Stat(s.check.KnownHosts); err != nil {
+			s.logger.Warn("known_hosts unavailable, ssh targets require a pinned host_key", zap.Error(err))
+		}
+	}
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,228 @@
+package scanner_impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var hostOSVFixtures = map[string]string{
+	"Debian/DSA-5532-1.json": `{"id":"DSA-5532-1","summary":"openssl security update","database_specific":{"severity":"high"},
+		"affected":[{"package":{"ecosystem":"Debian:12","name":"openssl"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"0"},{"fixed":"3.0.11-1~deb12u2"}]}]}]}`,
+	"Debian/DLA-curl.json": `{"id":"DLA-1000-1","summary":"curl cookie handling","database_specific":{"severity":"low"},
+		"affected":[{"package":{"ecosystem":"Debian:12","name":"curl"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"0"},{"fixed":"7.88.1-10+deb12u5"}]}]}]}`,
+	"Debian/DLA-nofix.json": `{"id":"DLA-1001-1","summary":"unfixed","database_specific":{"severity":"critical"},
+		"affected":[{"package":{"ecosystem":"Debian:12","name":"tar"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"0"}]}]}]}`,
+}
+
+// hostRootFixtures 模拟一台部分合规的 Debian 主机
+var hostRootFixtures = map[string]string{
+	"etc/os-release": "ID=debian\nVERSION_ID=\"12\"\n",
+	// Include 在前，其中的设置优先；Match 块中的设置不参与评估
+	"etc/ssh/sshd_config": "Include /etc/ssh/sshd_config.d/*.conf\n" +
+		"PermitRootLogin yes\n" +
+		"PasswordAuthentication no\n" +
+		"X11Forwarding yes\n" +
+		"Match User backup\n" +
+		"  PasswordAuthentication yes\n" +
+		"  PermitEmptyPasswords yes\n",
+	"etc/ssh/sshd_config.d/50-hardening.conf": "PermitRootLogin no\nMaxAuthTries=3\n",
+	"etc/ssh/sshd_config.d/README":            "PermitUserEnvironment yes\n",
+	"etc/sudoers": "Defaults env_reset\n" +
+		"root ALL=(ALL:ALL) ALL\n" +
+		"@includedir /etc/sudoers.d\n",
+	"etc/sudoers.d/deploy": "deploy ALL=(root) \\\n    NOPASSWD: /usr/bin/systemctl\n",
+	// includedir 跳过文件名包含 '.' 的文件
+	"etc/sudoers.d/legacy.bak":                       "ALL ALL=(ALL) ALL\n",
+	"proc/sys/kernel/randomize_va_space":             "2\n",
+	"proc/sys/net/ipv4/ip_forward":                   "1\n",
+	"proc/sys/net/ipv4/conf/all/accept_redirects":    "0\n",
+	"proc/sys/net/ipv4/conf/all/send_redirects":      "0\n",
+	"proc/sys/net/ipv4/conf/all/accept_source_route": "0\n",
+	"proc/sys/net/ipv4/tcp_syncookies":               "1\n",
+	"proc/sys/fs/suid_dumpable":                      "0\n",
+	"var/lib/dpkg/status": "Package: openssl\nStatus: install ok installed\nVersion: 3.0.11-1~deb12u1\n\n" +
+		"Package: curl\nStatus: install ok installed\nVersion: 7.88.1-10+deb12u4\n\n" +
+		"Package: tar\nStatus: install ok installed\nVersion: 1.34+dfsg-1.2\n",
+	"usr/bin/tool": "#!/bin/sh\n",
+	"etc/motd":     "welcome\n",
+}
+
+func writeHostRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFixtures(t, root, hostRootFixtures)
+	require.NoError(t, os.Chmod(filepath.Join(root, "etc/motd"), 0o666))
+	// 设置了粘滞位的共享目录不计入
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "var/log/shared"), 0o755))
+	require.NoError(t, os.Chmod(filepath.Join(root, "var/log/shared"), 0o777|os.ModeSticky))
+	return root
+}
+
+func newTestHostScanner(t *testing.T, localRoots ...string) *HostSecurityScanner {
+	t.Helper()
+	dbDir := t.TempDir()
+	writeFixtures(t, dbDir, hostOSVFixtures)
+
+	cfg := &config.Config{}
+	cfg.Security.AESKey = testAESKey
+	cfg.Scanner.HostCheck.Timeout = 30 * time.Second
+	cfg.Scanner.HostCheck.Materials.MaterialDir = shippedMaterialDir
+	cfg.Scanner.HostCheck.Check.LocalRoots = localRoots
+	cfg.Scanner.HostCheck.Check.VulnDBDir = dbDir
+	return NewHostSecurityScanner(nil, zap.NewNop(), cfg).(*HostSecurityScanner)
+}
+
+func hostTask(options map[string]interface{}) *domain.ScanTaskPayload {
+	return &domain.ScanTaskPayload{
+		TaskID:    "task-host",
+		AssetID:   "9",
+		AssetType: domain.AssetTypeIP,
+		Options:   options,
+	}
+}
+
+func TestHostSecurityScanner_LocalTransport(t *testing.T) {
+	root := writeHostRoot(t)
+	s := newTestHostScanner(t, filepath.Dir(root))
+
+	result, err := s.Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport": domain.HostTransportLocal,
+		"root_dir":  root,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+
+	controls := result.Result["controls"].([]ControlResult)
+	statuses := make(map[string]string)
+	byID := make(map[string]ControlResult)
+	for _, c := range controls {
+		statuses[c.ID] = c.Status
+		byID[c.ID] = c
+	}
+	assert.Equal(t, map[string]string{
+		"HOST-SSH-001":  ControlStatusPass,
+		"HOST-SSH-002":  ControlStatusPass,
+		"HOST-SSH-003":  ControlStatusPass,
+		"HOST-SSH-004":  ControlStatusPass,
+		"HOST-SSH-005":  ControlStatusFail,
+		"HOST-SSH-006":  ControlStatusPass,
+		"HOST-SSH-007":  ControlStatusPass,
+		"HOST-FS-001":   ControlStatusFail,
+		"HOST-SUDO-001": ControlStatusFail,
+		"HOST-SUDO-002": ControlStatusPass,
+		"HOST-KERN-001": ControlStatusPass,
+		"HOST-KERN-002": ControlStatusFail,
+		"HOST-KERN-003": ControlStatusPass,
+		"HOST-KERN-004": ControlStatusPass,
+		"HOST-KERN-005": ControlStatusPass,
+		"HOST-KERN-006": ControlStatusPass,
+		"HOST-KERN-007": ControlStatusPass,
+		"HOST-KERN-008": ControlStatusError,
+		"HOST-PKG-001":  ControlStatusFail,
+		"HOST-PKG-002":  ControlStatusFail,
+	}, statuses)
+
+	assert.Equal(t, []string{"PermitRootLogin no (/etc/ssh/sshd_config.d/50-hardening.conf:1)"}, byID["HOST-SSH-001"].Evidence)
+	assert.Equal(t, []string{"MaxAuthTries 3 (/etc/ssh/sshd_config.d/50-hardening.conf:2)"}, byID["HOST-SSH-004"].Evidence)
+	assert.Equal(t, []string{"PermitEmptyPasswords no (sshd default)"}, byID["HOST-SSH-003"].Evidence)
+	assert.Equal(t, []string{"/etc/motd"}, byID["HOST-FS-001"].Evidence)
+	assert.Equal(t, []string{"/etc/sudoers.d/deploy:1: deploy ALL=(root) NOPASSWD: /usr/bin/systemctl"}, byID["HOST-SUDO-001"].Evidence)
+	assert.Equal(t, []string{"net.ipv4.ip_forward = 1"}, byID["HOST-KERN-002"].Evidence)
+	assert.Equal(t, []string{"openssl 3.0.11-1~deb12u1 -> 3.0.11-1~deb12u2 (DSA-5532-1)"}, byID["HOST-PKG-001"].Evidence)
+	assert.Equal(t, []string{
+		"curl 7.88.1-10+deb12u4 -> 7.88.1-10+deb12u5 (DLA-1000-1)",
+		"openssl 3.0.11-1~deb12u1 -> 3.0.11-1~deb12u2 (DSA-5532-1)",
+	}, byID["HOST-PKG-002"].Evidence)
+
+	summary := result.Result["summary"].(map[string]interface{})
+	assert.Equal(t, 20, summary["controls"])
+	assert.Equal(t, 6, summary["failed"])
+	assert.Equal(t, 1, summary["errors"])
+
+	findings := result.Findings()
+	require.Len(t, findings, 6)
+	f := findings[0]
+	assert.Equal(t, "HOST-SSH-005", f.RuleID)
+	assert.Equal(t, "ssh", f.Category)
+	assert.Equal(t, domain.SeverityLow, f.Severity)
+	assert.Equal(t, root, f.Location.Logical)
+	assert.Equal(t, "linux-host-baseline@1.0.0", f.Properties["baseline"])
+}
+
+func TestHostSecurityScanner_SelectedControls(t *testing.T) {
+	root := writeHostRoot(t)
+	s := newTestHostScanner(t, root)
+
+	result, err := s.Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport":        domain.HostTransportLocal,
+		"root_dir":         root,
+		"baseline_version": "1.0.0",
+		"controls":         []interface{}{"HOST-KERN-001", "HOST-SSH-005"},
+	}))
+	require.NoError(t, err)
+	controls := result.Result["controls"].([]ControlResult)
+	require.Len(t, controls, 2)
+	assert.Equal(t, "HOST-SSH-005", controls[0].ID)
+	assert.Equal(t, "HOST-KERN-001", controls[1].ID)
+
+	_, err = s.Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport": domain.HostTransportLocal,
+		"root_dir":  root,
+		"controls":  []interface{}{"HOST-NOPE"},
+	}))
+	assert.ErrorContains(t, err, "unknown controls in baseline linux-host-baseline@1.0.0: HOST-NOPE")
+
+	_, err = s.Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport":        domain.HostTransportLocal,
+		"root_dir":         root,
+		"baseline_version": "9.9",
+	}))
+	assert.ErrorContains(t, err, "version 9.9")
+}
+
+func TestHostSecurityScanner_LocalRootsEnforced(t *testing.T) {
+	root := writeHostRoot(t)
+
+	_, err := newTestHostScanner(t).Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport": domain.HostTransportLocal,
+		"root_dir":  root,
+	}))
+	assert.ErrorContains(t, err, "local transport is disabled")
+
+	_, err = newTestHostScanner(t, t.TempDir()).Scan(context.Background(), hostTask(map[string]interface{}{
+		"transport": domain.HostTransportLocal,
+		"root_dir":  root,
+	}))
+	assert.ErrorContains(t, err, "outside host_check.local_roots")
+}
+
+func TestHostSecurityScanner_SSHOptions(t *testing.T) {
+	s := newTestHostScanner(t)
+
+	_, err := s.Scan(context.Background(), hostTask(map[string]interface{}{
+		"ssh": map[string]interface{}{"username": "audit", "password": seal(t, "ssh://10.0.0.5", "pw")},
+	}))
+	assert.ErrorContains(t, err, "missing ip_address")
+
+	// 凭据必须是为目标主机加密的密文
+	_, err = s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: "plaintext"}, "ssh://10.0.0.5")
+	assert.ErrorContains(t, err, "decrypt ssh password")
+	_, err = s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: seal(t, "ssh://10.0.0.6", "pw")}, "ssh://10.0.0.5")
+	assert.ErrorContains(t, err, "decrypt ssh password")
+
+	// 未固定主机公钥且未配置 known_hosts 时拒绝连接
+	_, err = s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: seal(t, "ssh://10.0.0.5", "pw")}, "ssh://10.0.0.5")
+	assert.ErrorContains(t, err, "host key verification requires")
+}
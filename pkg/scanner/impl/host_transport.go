@@ -0,0 +1,362 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// hostMaxOutput 单个远程命令输出的上限，包数据库通常只有数 MB
+const hostMaxOutput = 32 << 20
+
+// hostMaxSymlinks 本地根目录中解析符号链接的层数上限，与 Linux 的 ELOOP 限制一致
+const hostMaxSymlinks = 40
+
+// hostNotFoundStatus 远程读取命令在目标不存在时使用的退出码
+const hostNotFoundStatus = 44
+
+// HostTransport 主机安全检查读取目标系统的通道，路径均为目标系统上的绝对路径；
+// 检查只读取文件与目录，不在目标主机上做任何修改
+type HostTransport interface {
+	// Name 返回传输方式，见 domain.HostTransport*
+	Name() string
+	// ReadFile 读取文件内容，文件不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)
+	ReadFile(ctx context.Context, path string) ([]byte, error)
+	// ReadDir 返回目录下的文件名（按名称排序），目录不存在时返回 fs.ErrNotExist
+	ReadDir(ctx context.Context, path string) ([]string, error)
+	// FindWorldWritable 返回 roots 下其他用户可写的普通文件与未设置粘滞位的目录，最多 limit 个，不跟随符号链接
+	FindWorldWritable(ctx context.Context, roots []string, limit int) ([]string, error)
+	Close() error
+}
+
+// LocalTransport 以本地目录作为目标系统的根文件系统，符号链接按该目录为根解析，
+// 用于检查挂载的主机快照或解开的系统镜像，也便于在没有真实主机时测试
+type LocalTransport struct {
+	root string
+}
+
+// NewLocalTransport 创建本地根目录传输
+func NewLocalTransport(root string) (*LocalTransport, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("host root unavailable: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("host root %s is not a directory", root)
+	}
+	return &LocalTransport{root: abs}, nil
+}
+
+// Name 实现 HostTransport
+func (t *LocalTransport) Name() string {
+	return "local"
+}
+
+// resolve 将目标系统上的路径解析为本地路径，绝对符号链接与 .. 都不会越出根目录
+func (t *LocalTransport) resolve(target string) (string, error) {
+	resolved := "/"
+	parts := strings.Split(target, "/")
+	links := 0
+	for len(parts) > 0 {
+		part := parts[0]
+		parts = parts[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		local := filepath.Join(t.root, filepath.FromSlash(next))
+		info, err := os.Lstat(local)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			// 不存在的路径原样保留，由后续的读取返回 fs.ErrNotExist
+			resolved = next
+			continue
+		}
+		if links++; links > hostMaxSymlinks {
+			return "", fmt.Errorf("%s: too many levels of symbolic links", target)
+		}
+		dest, err := os.Readlink(local)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(dest) {
+			resolved = "/"
+		}
+		parts = append(strings.Split(dest, "/"), parts...)
+	}
+	return filepath.Join(t.root, filepath.FromSlash(resolved)), nil
+}
+
+// ReadFile 实现 HostTransport
+func (t *LocalTransport) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	local, err := t.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, unwrapPathError(err))
+	}
+	return data, nil
+}
+
+// ReadDir 实现 HostTransport
+func (t *LocalTransport) ReadDir(ctx context.Context, name string) ([]string, error) {
+	local, err := t.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(local)
+	if err != nil {
+		return nil, fmt.Errorf("read dir %s: %w", name, unwrapPathError(err))
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// FindWorldWritable 实现 HostTransport
+func (t *LocalTransport) FindWorldWritable(ctx context.Context, roots []string, limit int) ([]string, error) {
+	var found []string
+	for _, root := range roots {
+		local, err := t.resolve(root)
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.WalkDir(local, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// 与 find 一致，跳过不可读的目录
+				return nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if !d.Type().IsRegular() && !d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			mode := info.Mode()
+			if mode.Perm()&0o002 == 0 || (mode.IsDir() && mode&fs.ModeSticky != 0) {
+				return nil
+			}
+			rel, err := filepath.Rel(t.root, p)
+			if err != nil {
+				return err
+			}
+			found = append(found, "/"+filepath.ToSlash(rel))
+			if len(found) >= limit {
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) >= limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+// Close 实现 HostTransport
+func (t *LocalTransport) Close() error {
+	return nil
+}
+
+// unwrapPathError 去掉 *fs.PathError 中的本地路径，错误信息只保留目标系统上的路径
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
+
+// SSHTransport 通过 SSH 在目标主机上执行只读命令，每个操作使用独立的会话
+type SSHTransport struct {
+	client *ssh.Client
+}
+
+// DialSSH 连接目标主机并完成认证，握手受 cfg.Timeout 与 ctx 约束
+func DialSSH(ctx context.Context, addr string, cfg *ssh.ClientConfig) (*SSHTransport, error) {
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect %s failed: %w", addr, err)
+	}
+	var deadline time.Time
+	if cfg.Timeout > 0 {
+		deadline = time.Now().Add(cfg.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return &SSHTransport{client: ssh.NewClient(c, chans, reqs)}, nil
+}
+
+// Name 实现 HostTransport
+func (t *SSHTransport) Name() string {
+	return "ssh"
+}
+
+// sshOutput 远程命令的执行结果
+type sshOutput struct {
+	stdout []byte
+	stderr string
+	status int
+}
+
+// run 在新会话中执行命令，ctx 取消时关闭会话
+func (t *SSHTransport) run(ctx context.Context, cmd string) (*sshOutput, error) {
+	session, err := t.client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("open ssh session failed: %w", err)
+	}
+	defer session.Close()
+
+	stdout := &cappedBuffer{max: hostMaxOutput}
+	stderr := newRingBuffer(1024)
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run("LC_ALL=C; export LC_ALL; " + cmd) }()
+	select {
+	case <-ctx.Done():
+		session.Close()
+		return nil, ctx.Err()
+	case err = <-done:
+	}
+
+	if stdout.overflow {
+		return nil, fmt.Errorf("remote command output exceeds %d bytes", hostMaxOutput)
+	}
+	out := &sshOutput{stdout: stdout.Bytes(), stderr: strings.TrimSpace(string(stderr.Bytes()))}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		out.status = exitErr.ExitStatus()
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("remote command failed: %w", err)
+	}
+	return out, nil
+}
+
+// read 执行读取命令，约定的退出码转换为 fs.ErrNotExist
+func (t *SSHTransport) read(ctx context.Context, what, name, cmd string) ([]byte, error) {
+	out, err := t.run(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", what, name, err)
+	}
+	switch out.status {
+	case 0:
+		return out.stdout, nil
+	case hostNotFoundStatus:
+		return nil, fmt.Errorf("%s %s: %w", what, name, fs.ErrNotExist)
+	default:
+		return nil, fmt.Errorf("%s %s: exit status %d: %s", what, name, out.status, out.stderr)
+	}
+}
+
+// ReadFile 实现 HostTransport
+func (t *SSHTransport) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	q := shellQuote(name)
+	return t.read(ctx, "read", name, fmt.Sprintf("if [ -e %s ]; then cat -- %s; else exit %d; fi", q, q, hostNotFoundStatus))
+}
+
+// ReadDir 实现 HostTransport
+func (t *SSHTransport) ReadDir(ctx context.Context, name string) ([]string, error) {
+	q := shellQuote(name)
+	out, err := t.read(ctx, "read dir", name, fmt.Sprintf("if [ -d %s ]; then ls -1A -- %s; else exit %d; fi", q, q, hostNotFoundStatus))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FindWorldWritable 实现 HostTransport，不可读的目录被忽略，find 因此返回的非零退出码不视为失败
+func (t *SSHTransport) FindWorldWritable(ctx context.Context, roots []string, limit int) ([]string, error) {
+	quoted := make([]string, 0, len(roots))
+	for _, root := range roots {
+		quoted = append(quoted, shellQuote(root))
+	}
+	cmd := fmt.Sprintf(
+		"for d in %s; do [ -e \"$d\" ] && find \"$d\" -xdev \\( -type f -o \\( -type d ! -perm -1000 \\) \\) -perm -0002 -print 2>/dev/null; done | head -n %d",
+		strings.Join(quoted, " "), limit)
+	out, err := t.run(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("find world-writable files: %w", err)
+	}
+	var found []string
+	for _, line := range strings.Split(string(out.stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			found = append(found, line)
+		}
+	}
+	return found, nil
+}
+
+// Close 实现 HostTransport
+func (t *SSHTransport) Close() error {
+	return t.client.Close()
+}
+
+// shellQuote 按 POSIX shell 单引号规则转义参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cappedBuffer 超过上限后丢弃后续输出并记录溢出
+type cappedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || b.Len()+len(p) > b.max {
+		b.overflow = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
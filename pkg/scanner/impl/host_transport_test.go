@@ -0,0 +1,194 @@
+package scanner_impl
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestLocalTransport_SymlinksStayInRoot(t *testing.T) {
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("leak"), 0o644))
+
+	root := t.TempDir()
+	writeFixtures(t, root, map[string]string{
+		"etc/real.conf": "inside",
+		"secret":        "root secret",
+	})
+	// 绝对链接与 .. 都按目标根目录解析
+	require.NoError(t, os.Symlink("/etc/real.conf", filepath.Join(root, "etc/abs.conf")))
+	require.NoError(t, os.Symlink("../../../../../../secret", filepath.Join(root, "etc/up.conf")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "etc/host.conf")))
+	require.NoError(t, os.Symlink("loop", filepath.Join(root, "etc/loop")))
+
+	tr, err := NewLocalTransport(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	data, err := tr.ReadFile(ctx, "/etc/abs.conf")
+	require.NoError(t, err)
+	assert.Equal(t, "inside", string(data))
+
+	data, err = tr.ReadFile(ctx, "/etc/up.conf")
+	require.NoError(t, err)
+	assert.Equal(t, "root secret", string(data))
+
+	_, err = tr.ReadFile(ctx, "/etc/host.conf")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NotContains(t, err.Error(), root)
+
+	_, err = tr.ReadFile(ctx, "/etc/loop")
+	assert.ErrorContains(t, err, "too many levels of symbolic links")
+
+	names, err := tr.ReadDir(ctx, "/etc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abs.conf", "host.conf", "loop", "real.conf", "up.conf"}, names)
+}
+
+// startTestSSHServer 启动只支持 exec 的 SSH 服务，命令在本机 sh 中执行
+func startTestSSHServer(t *testing.T, password string) (string, ssh.PublicKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+			if string(pw) != password {
+				return nil, assert.AnError
+			}
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, cfg)
+		}
+	}()
+	return ln.Addr().String(), signer.PublicKey()
+}
+
+func serveTestSSHConn(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			newCh.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer ch.Close()
+			for req := range chReqs {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+					req.Reply(false, nil)
+					return
+				}
+				req.Reply(true, nil)
+
+				cmd := exec.Command("sh", "-c", payload.Command)
+				cmd.Stdout, cmd.Stderr = ch, ch.Stderr()
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					if exitErr, ok := err.(*exec.ExitError); ok {
+						status = uint32(exitErr.ExitCode())
+					}
+				}
+				msg := make([]byte, 4)
+				binary.BigEndian.PutUint32(msg, status)
+				ch.SendRequest("exit-status", false, msg)
+				return
+			}
+		}()
+	}
+}
+
+func TestSSHTransport(t *testing.T) {
+	addr, hostKey := startTestSSHServer(t, "s3cret")
+	dir := t.TempDir()
+	writeFixtures(t, dir, map[string]string{
+		"it's here.conf": "PermitRootLogin no\n",
+		"sub/b":          "",
+		"sub/a b":        "",
+	})
+	require.NoError(t, os.Chmod(filepath.Join(dir, "sub/b"), 0o666))
+
+	s := newTestHostScanner(t)
+	scope := domain.HostCredentialScope("127.0.0.1")
+	cfg, err := s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: seal(t, scope, "s3cret"), HostKey: ssh.FingerprintSHA256(hostKey)}, scope)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tr, err := DialSSH(ctx, addr, cfg)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	data, err := tr.ReadFile(ctx, filepath.Join(dir, "it's here.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "PermitRootLogin no\n", string(data))
+
+	_, err = tr.ReadFile(ctx, filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	names, err := tr.ReadDir(ctx, filepath.Join(dir, "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a b", "b"}, names)
+
+	found, err := tr.FindWorldWritable(ctx, []string{dir, filepath.Join(dir, "none")}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "sub/b")}, found)
+
+	// 固定的主机公钥不一致时拒绝连接
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ssh.NewPublicKey(other)
+	require.NoError(t, err)
+	cfg, err = s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: seal(t, scope, "s3cret"), HostKey: string(ssh.MarshalAuthorizedKey(otherKey))}, scope)
+	require.NoError(t, err)
+	_, err = DialSSH(ctx, addr, cfg)
+	assert.ErrorContains(t, err, "host key mismatch")
+
+	// 认证失败
+	cfg, err = s.sshClientConfig(&domain.HostSSHOptions{Username: "audit", Password: seal(t, scope, "wrong"), HostKey: string(ssh.MarshalAuthorizedKey(hostKey))}, scope)
+	require.NoError(t, err)
+	_, err = DialSSH(ctx, addr, cfg)
+	assert.ErrorContains(t, err, "unable to authenticate")
+}
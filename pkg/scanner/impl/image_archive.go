@@ -0,0 +1,412 @@
+package scanner_impl
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// OCI 与 Docker 清单媒体类型
+const (
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var digestPattern = regexp.MustCompile(`^sha(256|512):[a-f0-9]{64,128}$`)
+
+// ImageInfo 镜像元数据
+type ImageInfo struct {
+	// Digest OCI 布局为清单摘要；docker save 归档不含清单，使用镜像 ID（配置摘要）
+	Digest       string   `json:"digest"`
+	ConfigDigest string   `json:"config_digest"`
+	Size         int64    `json:"size"` // 配置与全部层（压缩后）的大小之和
+	RepoTags     []string `json:"repo_tags,omitempty"`
+	OS           string   `json:"os,omitempty"`
+	Architecture string   `json:"architecture,omitempty"`
+	Layers       []string `json:"layers"`
+}
+
+// imageLayout 已解开的镜像归档，layerPaths 与 Info.Layers 一一对应
+type imageLayout struct {
+	Info       ImageInfo
+	layerPaths []string
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+type ociIndex struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// dockerManifestEntry docker save 归档中 manifest.json 的条目
+type dockerManifestEntry struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// extractImageArchive 解开镜像 tar 归档（docker save 或 OCI 归档，支持 gzip 压缩）
+func extractImageArchive(archivePath, dest string) error {
+	if err := os.MkdirAll(dest, 0o750); err != nil {
+		return err
+	}
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, closeFn, err := maybeGzip(f)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	return extractTar(r, dest)
+}
+
+// maybeGzip 按魔数识别 gzip 压缩，zstd 等其他压缩格式返回错误
+func maybeGzip(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gz, func() { gz.Close() }, nil
+	case len(magic) == 4 && magic[0] == 0x28 && magic[1] == 0xb5 && magic[2] == 0x2f && magic[3] == 0xfd:
+		return nil, nil, errors.New("zstd compressed layers are not supported")
+	default:
+		return br, func() {}, nil
+	}
+}
+
+// openImageLayout 识别解开后的目录是 OCI 布局（index.json）还是 docker save 格式（manifest.json）
+func openImageLayout(dir string) (*imageLayout, error) {
+	if _, err := os.Stat(filepath.Join(dir, "index.json")); err == nil {
+		return openOCILayout(dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err == nil {
+		return openDockerArchive(dir)
+	}
+	return nil, errors.New("neither index.json nor manifest.json found, not an OCI layout or docker archive")
+}
+
+func openOCILayout(dir string) (*imageLayout, error) {
+	var index ociIndex
+	if err := readJSONFile(filepath.Join(dir, "index.json"), &index); err != nil {
+		return nil, fmt.Errorf("read index.json failed: %w", err)
+	}
+
+	// 多架构镜像逐级展开索引，优先选择 linux/amd64
+	var desc *ociDescriptor
+	for depth := 0; depth < 4; depth++ {
+		desc = selectManifest(index.Manifests)
+		if desc == nil {
+			return nil, errors.New("no image manifest in index")
+		}
+		if desc.MediaType != mediaTypeOCIIndex && desc.MediaType != mediaTypeDockerManifestList {
+			break
+		}
+		blob, err := blobPath(dir, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		index = ociIndex{}
+		if err := readJSONFile(blob, &index); err != nil {
+			return nil, fmt.Errorf("read nested index failed: %w", err)
+		}
+	}
+
+	manifestPath, err := blobPath(dir, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ociManifest
+	if err := readJSONFile(manifestPath, &manifest); err != nil {
+		return nil, fmt.Errorf("read manifest failed: %w", err)
+	}
+
+	layout := &imageLayout{Info: ImageInfo{
+		Digest:       desc.Digest,
+		ConfigDigest: manifest.Config.Digest,
+		Size:         manifest.Config.Size,
+	}}
+	for _, key := range []string{"org.opencontainers.image.ref.name", "io.containerd.image.name"} {
+		if ref := desc.Annotations[key]; ref != "" {
+			layout.Info.RepoTags = append(layout.Info.RepoTags, ref)
+		}
+	}
+	configPath, err := blobPath(dir, manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := layout.readConfig(configPath); err != nil {
+		return nil, err
+	}
+	for _, l := range manifest.Layers {
+		p, err := blobPath(dir, l.Digest)
+		if err != nil {
+			return nil, err
+		}
+		layout.Info.Size += l.Size
+		layout.Info.Layers = append(layout.Info.Layers, l.Digest)
+		layout.layerPaths = append(layout.layerPaths, p)
+	}
+	return layout, nil
+}
+
+func openDockerArchive(dir string) (*imageLayout, error) {
+	var entries []dockerManifestEntry
+	if err := readJSONFile(filepath.Join(dir, "manifest.json"), &entries); err != nil {
+		return nil, fmt.Errorf("read manifest.json failed: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("empty manifest.json")
+	}
+	if len(entries) > 1 {
+		return nil, fmt.Errorf("archive contains %d images, only single-image archives are supported", len(entries))
+	}
+	entry := entries[0]
+
+	configPath, err := safeJoin(dir, entry.Config)
+	if err != nil {
+		return nil, err
+	}
+	configDigest, configSize, err := fileDigest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	layout := &imageLayout{Info: ImageInfo{
+		Digest:       configDigest,
+		ConfigDigest: configDigest,
+		Size:         configSize,
+		RepoTags:     entry.RepoTags,
+	}}
+	if err := layout.readConfig(configPath); err != nil {
+		return nil, err
+	}
+	for _, l := range entry.Layers {
+		p, err := safeJoin(dir, l)
+		if err != nil {
+			return nil, err
+		}
+		digest, size, err := fileDigest(p)
+		if err != nil {
+			return nil, err
+		}
+		layout.Info.Size += size
+		layout.Info.Layers = append(layout.Info.Layers, digest)
+		layout.layerPaths = append(layout.layerPaths, p)
+	}
+	return layout, nil
+}
+
+// readConfig 读取镜像配置中的平台信息
+func (l *imageLayout) readConfig(configPath string) error {
+	var cfg struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	}
+	if err := readJSONFile(configPath, &cfg); err != nil {
+		return fmt.Errorf("read image config failed: %w", err)
+	}
+	l.Info.OS, l.Info.Architecture = cfg.OS, cfg.Architecture
+	return nil
+}
+
+// selectManifest 选择 linux/amd64 清单，没有平台信息时取第一个
+func selectManifest(manifests []ociDescriptor) *ociDescriptor {
+	for i := range manifests {
+		p := manifests[i].Platform
+		if p != nil && p.OS == "linux" && p.Architecture == "amd64" {
+			return &manifests[i]
+		}
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+	return &manifests[0]
+}
+
+// blobPath 返回 OCI 布局中摘要对应的 blob 路径，校验摘要格式防止目录穿越
+func blobPath(dir, digest string) (string, error) {
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("invalid digest: %q", digest)
+	}
+	alg, hexPart, _ := strings.Cut(digest, ":")
+	return filepath.Join(dir, "blobs", alg, hexPart), nil
+}
+
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// imageFileOfInterest 判断层内文件是否需要落盘：OS 包数据库、os-release 及语言依赖清单
+func imageFileOfInterest(name string) bool {
+	switch {
+	case name == dpkgStatusPath, name == apkInstalledPath:
+		return true
+	case strings.HasPrefix(name, dpkgStatusDirPath+"/"):
+		return true
+	}
+	for _, p := range rpmSqlitePaths {
+		if name == p {
+			return true
+		}
+	}
+	for _, p := range osReleasePaths {
+		if name == p {
+			return true
+		}
+	}
+	_, ok := lockfileParsers[path.Base(name)]
+	return ok && !inSkippedDir(name)
+}
+
+// unpack 按顺序应用镜像层，仅提取 keep 选中的文件，并处理 whiteout 删除标记
+func (l *imageLayout) unpack(ctx context.Context, rootfs string, keep func(name string) bool) error {
+	if err := os.MkdirAll(rootfs, 0o750); err != nil {
+		return err
+	}
+	var total int64
+	for i, layerPath := range l.layerPaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := applyLayer(layerPath, rootfs, keep, &total); err != nil {
+			return fmt.Errorf("apply layer %s failed: %w", l.Info.Layers[i], err)
+		}
+	}
+	return nil
+}
+
+func applyLayer(layerPath, rootfs string, keep func(name string) bool, total *int64) error {
+	f, err := os.Open(layerPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, closeFn, err := maybeGzip(f)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	// 记录本层写入的文件，opaque 目录只清除下层内容
+	written := make(map[string]bool)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		name = strings.TrimPrefix(name, "/")
+		dir, base := path.Dir(name), path.Base(name)
+
+		switch {
+		case base == ".wh..wh..opq":
+			if err := clearOpaqueDir(rootfs, dir, written); err != nil {
+				return err
+			}
+		case strings.HasPrefix(base, ".wh."):
+			target, err := safeJoin(rootfs, path.Join(dir, strings.TrimPrefix(base, ".wh.")))
+			if err != nil {
+				return err
+			}
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+		case hdr.Typeflag == tar.TypeReg && keep(name):
+			target, err := safeJoin(rootfs, name)
+			if err != nil {
+				return err
+			}
+			*total += hdr.Size
+			if *total > maxExtractSize {
+				return fmt.Errorf("image content exceeds %d bytes", maxExtractSize)
+			}
+			if err := writeFile(target, tr, hdr.Size); err != nil {
+				return err
+			}
+			written[name] = true
+		}
+	}
+}
+
+// clearOpaqueDir 删除 opaque 目录下来自下层的文件
+func clearOpaqueDir(rootfs, dir string, written map[string]bool) error {
+	target, err := safeJoin(rootfs, dir)
+	if err != nil {
+		return err
+	}
+	err = filepath.WalkDir(target, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(rootfs, p)
+		if err != nil {
+			return err
+		}
+		if !written[filepath.ToSlash(rel)] {
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,619 @@
+package scanner_impl
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// rpm 4.16+ 使用 sqlite 格式的包数据库
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OS 包数据库在镜像根文件系统中的位置
+var (
+	dpkgStatusPath    = "var/lib/dpkg/status"
+	dpkgStatusDirPath = "var/lib/dpkg/status.d" // distroless 镜像按包拆分的 status 文件
+	apkInstalledPath  = "lib/apk/db/installed"
+	rpmSqlitePaths    = []string{"var/lib/rpm/rpmdb.sqlite", "usr/lib/sysimage/rpm/rpmdb.sqlite"}
+	osReleasePaths    = []string{"etc/os-release", "usr/lib/os-release"}
+)
+
+// OSRelease /etc/os-release 中识别发行版所需的字段
+type OSRelease struct {
+	ID         string `json:"id"`
+	VersionID  string `json:"version_id"`
+	PrettyName string `json:"pretty_name,omitempty"`
+}
+
+// Ecosystem 返回 OSV 中对应的发行版生态名
+func (r *OSRelease) Ecosystem() string {
+	switch r.ID {
+	case "debian":
+		// testing/sid 没有 VERSION_ID
+		if r.VersionID == "" {
+			return "Debian"
+		}
+		return "Debian:" + majorVersion(r.VersionID)
+	case "ubuntu":
+		return "Ubuntu:" + r.VersionID
+	case "alpine":
+		parts := strings.SplitN(r.VersionID, ".", 3)
+		if len(parts) >= 2 {
+			return "Alpine:v" + parts[0] + "." + parts[1]
+		}
+		return "Alpine"
+	case "almalinux":
+		return "AlmaLinux:" + majorVersion(r.VersionID)
+	case "rocky":
+		return "Rocky Linux:" + majorVersion(r.VersionID)
+	case "rhel":
+		return "Red Hat"
+	case "centos":
+		return "CentOS:" + majorVersion(r.VersionID)
+	case "fedora":
+		return "Fedora:" + r.VersionID
+	case "opensuse-leap", "opensuse-tumbleweed":
+		return "openSUSE"
+	case "sles":
+		return "SUSE"
+	default:
+		return r.ID
+	}
+}
+
+func majorVersion(v string) string {
+	major, _, _ := strings.Cut(v, ".")
+	return major
+}
+
+// detectOSRelease 读取根文件系统中的 os-release，无法识别时返回 nil
+func detectOSRelease(rootfs string) *OSRelease {
+	for _, rel := range osReleasePaths {
+		data, err := os.ReadFile(filepath.Join(rootfs, rel))
+		if err != nil {
+			continue
+		}
+		if osr := parseOSRelease(data); osr != nil {
+			return osr
+		}
+	}
+	return nil
+}
+
+// parseOSRelease 解析 os-release 文件，缺少 ID 时返回 nil
+func parseOSRelease(data []byte) *OSRelease {
+	osr := &OSRelease{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			osr.ID = strings.ToLower(value)
+		case "VERSION_ID":
+			osr.VersionID = value
+		case "PRETTY_NAME":
+			osr.PrettyName = value
+		}
+	}
+	if osr.ID == "" {
+		return nil
+	}
+	return osr
+}
+
+// CollectOSPackages 读取 dpkg/apk/rpm 包数据库，ecosystem 为空时按包管理器推断
+func CollectOSPackages(rootfs string, osr *OSRelease) ([]Component, error) {
+	ecosystem := ""
+	if osr != nil {
+		ecosystem = osr.Ecosystem()
+	}
+
+	var components []Component
+	add := func(found []Component, source, fallback string) {
+		for i := range found {
+			found[i].Source = source
+			found[i].Ecosystem = firstNonEmpty(ecosystem, fallback)
+		}
+		components = append(components, found...)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(rootfs, dpkgStatusPath)); err == nil {
+		add(parseDpkgStatus(data), dpkgStatusPath, "Debian")
+	}
+	if entries, err := os.ReadDir(filepath.Join(rootfs, dpkgStatusDirPath)); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			rel := filepath.ToSlash(filepath.Join(dpkgStatusDirPath, e.Name()))
+			data, err := os.ReadFile(filepath.Join(rootfs, rel))
+			if err != nil {
+				return nil, err
+			}
+			add(parseDpkgStatus(data), rel, "Debian")
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(rootfs, apkInstalledPath)); err == nil {
+		add(parseApkInstalled(data), apkInstalledPath, "Alpine")
+	}
+	for _, rel := range rpmSqlitePaths {
+		path := filepath.Join(rootfs, rel)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		found, err := readRPMSqlite(path)
+		if err != nil {
+			return nil, fmt.Errorf("read rpm database %s failed: %w", rel, err)
+		}
+		add(found, rel, "Red Hat")
+		break
+	}
+	return dedupeComponents(components), nil
+}
+
+// parseDpkgStatus 解析 dpkg status 文件，只保留已安装的包
+func parseDpkgStatus(data []byte) []Component {
+	var components []Component
+	for _, para := range bytes.Split(data, []byte("\n\n")) {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(string(para), "\n") {
+			// 以空白开头的是上一字段的续行（如 Description），无需保留
+			if line == "" || line[0] == ' ' || line[0] == '\t' {
+				continue
+			}
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				fields[key] = strings.TrimSpace(value)
+			}
+		}
+		name, version := fields["Package"], fields["Version"]
+		if name == "" || version == "" {
+			continue
+		}
+		// distroless 的 status.d 条目没有 Status 字段
+		if status, ok := fields["Status"]; ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		// Source 字段格式为 "name" 或 "name (version)"
+		source, _, _ := strings.Cut(fields["Source"], " ")
+		components = append(components, Component{
+			Name:          name,
+			Version:       version,
+			Direct:        true,
+			SourcePackage: source,
+		})
+	}
+	return components
+}
+
+// parseApkInstalled 解析 apk installed 数据库
+func parseApkInstalled(data []byte) []Component {
+	var components []Component
+	var current Component
+	flush := func() {
+		if current.Name != "" && current.Version != "" {
+			current.Direct = true
+			components = append(components, current)
+		}
+		current = Component{}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			current.Name = value
+		case 'V':
+			current.Version = value
+		case 'o':
+			current.SourcePackage = value
+		case 'L':
+			current.License = value
+		}
+	}
+	flush()
+	return components
+}
+
+// readRPMSqlite 读取 rpmdb.sqlite 中的包头
+func readRPMSqlite(path string) ([]Component, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&immutable=1")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT blob FROM Packages")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []Component
+	for rows.Next() {
+		var blob []byte
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		c, err := parseRPMHeader(blob)
+		if err != nil {
+			return nil, err
+		}
+		// gpg-pubkey 是导入的签名公钥，并非软件包
+		if c.Name == "gpg-pubkey" {
+			continue
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
+
+// rpm 头部中用到的标签
+const (
+	rpmTagName      = 1000
+	rpmTagVersion   = 1001
+	rpmTagRelease   = 1002
+	rpmTagEpoch     = 1003
+	rpmTagLicense   = 1014
+	rpmTagSourceRPM = 1044
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeI18NString  = 9
+	rpmHeaderIndexSize = 16
+)
+
+// parseRPMHeader 解析 rpm 包头 blob：索引条目数、数据区长度、索引条目（tag/type/offset/count）及数据区
+func parseRPMHeader(blob []byte) (Component, error) {
+	if len(blob) < 8 {
+		return Component{}, errors.New("rpm header too short")
+	}
+	il := int(binary.BigEndian.Uint32(blob[0:4]))
+	dl := int(binary.BigEndian.Uint32(blob[4:8]))
+	dataStart := 8 + il*rpmHeaderIndexSize
+	if il <= 0 || dl < 0 || dataStart+dl > len(blob) {
+		return Component{}, errors.New("invalid rpm header")
+	}
+	store := blob[dataStart : dataStart+dl]
+
+	var (
+		c     = Component{Direct: true}
+		epoch = -1
+		rel   string
+	)
+	for i := 0; i < il; i++ {
+		entry := blob[8+i*rpmHeaderIndexSize:]
+		tag := binary.BigEndian.Uint32(entry[0:4])
+		typ := binary.BigEndian.Uint32(entry[4:8])
+		offset := int(binary.BigEndian.Uint32(entry[8:12]))
+		if offset < 0 || offset >= len(store) {
+			continue
+		}
+		switch {
+		case typ == rpmTypeInt32 && tag == rpmTagEpoch && offset+4 <= len(store):
+			epoch = int(binary.BigEndian.Uint32(store[offset:]))
+		case typ == rpmTypeString || typ == rpmTypeI18NString:
+			end := bytes.IndexByte(store[offset:], 0)
+			if end < 0 {
+				continue
+			}
+			value := string(store[offset : offset+end])
+			switch tag {
+			case rpmTagName:
+				c.Name = value
+			case rpmTagVersion:
+				c.Version = value
+			case rpmTagRelease:
+				rel = value
+			case rpmTagLicense:
+				c.License = value
+			case rpmTagSourceRPM:
+				c.SourcePackage = sourceRPMName(value)
+			}
+		}
+	}
+	if c.Name == "" || c.Version == "" {
+		return Component{}, errors.New("rpm header missing name or version")
+	}
+	if rel != "" {
+		c.Version += "-" + rel
+	}
+	if epoch > 0 {
+		c.Version = strconv.Itoa(epoch) + ":" + c.Version
+	}
+	return c, nil
+}
+
+// sourceRPMName 从 openssl-3.0.7-24.el9.src.rpm 中提取源码包名
+func sourceRPMName(srpm string) string {
+	name := strings.TrimSuffix(srpm, ".src.rpm")
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(name, "-")
+		if idx <= 0 {
+			return ""
+		}
+		name = name[:idx]
+	}
+	return name
+}
+
+// compareDebianVersions 按 dpkg 规则比较 [epoch:]upstream[-revision]
+func compareDebianVersions(a, b string) int {
+	aEpoch, aUp, aRev := splitDebianVersion(a)
+	bEpoch, bUp, bRev := splitDebianVersion(b)
+	if aEpoch != bEpoch {
+		if aEpoch < bEpoch {
+			return -1
+		}
+		return 1
+	}
+	if c := dpkgVerRevCmp(aUp, bUp); c != 0 {
+		return c
+	}
+	return dpkgVerRevCmp(aRev, bRev)
+}
+
+func splitDebianVersion(v string) (int, string, string) {
+	epoch := 0
+	if e, rest, ok := strings.Cut(v, ":"); ok {
+		if n, err := strconv.Atoi(e); err == nil {
+			epoch, v = n, rest
+		}
+	}
+	if idx := strings.LastIndex(v, "-"); idx >= 0 {
+		return epoch, v[:idx], v[idx+1:]
+	}
+	return epoch, v, ""
+}
+
+// dpkgVerRevCmp 交替比较非数字段与数字段，'~' 排在一切字符（包括结尾）之前
+func dpkgVerRevCmp(a, b string) int {
+	order := func(s string, i int) int {
+		if i >= len(s) {
+			return 0
+		}
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			return 0
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			return int(c)
+		case c == '~':
+			return -1
+		default:
+			return int(c) + 256
+		}
+	}
+	isDigit := func(s string, i int) bool { return i < len(s) && s[i] >= '0' && s[i] <= '9' }
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a, i)) || (j < len(b) && !isDigit(b, j)) {
+			ac, bc := order(a, i), order(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		diff := 0
+		for isDigit(a, i) && isDigit(b, j) {
+			if diff == 0 {
+				diff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if isDigit(a, i) {
+			return 1
+		}
+		if isDigit(b, j) {
+			return -1
+		}
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+	return 0
+}
+
+// compareRPMVersions 按 rpmvercmp 规则比较 [epoch:]version[-release]
+func compareRPMVersions(a, b string) int {
+	aEpoch, aVer, aRel := splitDebianVersion(a)
+	bEpoch, bVer, bRel := splitDebianVersion(b)
+	if aEpoch != bEpoch {
+		if aEpoch < bEpoch {
+			return -1
+		}
+		return 1
+	}
+	if c := rpmVerCmp(aVer, bVer); c != 0 {
+		return c
+	}
+	// 一方缺少 release 时只比较 version，与 OSV 中不带 release 的修复版本兼容
+	if aRel == "" || bRel == "" {
+		return 0
+	}
+	return rpmVerCmp(aRel, bRel)
+}
+
+// rpmVerCmp 将版本拆为字母段与数字段逐段比较，数字段新于字母段，'~' 表示预发布
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	isAlnum := func(c byte) bool {
+		return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+
+	for len(a) > 0 || len(b) > 0 {
+		for len(a) > 0 && !isAlnum(a[0]) && a[0] != '~' && a[0] != '^' {
+			a = a[1:]
+		}
+		for len(b) > 0 && !isAlnum(b[0]) && b[0] != '~' && b[0] != '^' {
+			b = b[1:]
+		}
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			switch {
+			case a == "":
+				return -1
+			case b == "":
+				return 1
+			case !strings.HasPrefix(a, "^"):
+				return 1
+			case !strings.HasPrefix(b, "^"):
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := isDigit(a[0])
+		take := func(s string) (string, string) {
+			i := 0
+			for i < len(s) && isAlnum(s[i]) && isDigit(s[i]) == numeric {
+				i++
+			}
+			return s[:i], s[i:]
+		}
+		var x, y string
+		x, a = take(a)
+		y, b = take(b)
+		if y == "" {
+			// 段类型不同：数字段较新
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		if numeric {
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if len(x) != len(y) {
+				return sign(len(x) - len(y))
+			}
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+// apk 版本后缀的排序：预发布 < 正式版 < 补丁/快照
+var apkSuffixOrder = map[string]int{
+	"alpha": -4, "beta": -3, "pre": -2, "rc": -1, "": 0,
+	"cvs": 1, "svn": 2, "git": 3, "hg": 4, "p": 5,
+}
+
+// compareAPKVersions 比较 apk 版本（如 3.1.4-r5、1.2.3_rc1-r0、1.0a）
+func compareAPKVersions(a, b string) int {
+	aVer, aRel := splitAPKRelease(a)
+	bVer, bRel := splitAPKRelease(b)
+	aMain, aSuffix, aSuffixNum := splitAPKSuffix(aVer)
+	bMain, bSuffix, bSuffixNum := splitAPKSuffix(bVer)
+
+	if c := compareSegments(splitAPKMain(aMain), splitAPKMain(bMain)); c != 0 {
+		return c
+	}
+	if aSuffix != bSuffix {
+		return sign(apkSuffixOrder[aSuffix] - apkSuffixOrder[bSuffix])
+	}
+	if c := compareSegment(aSuffixNum, bSuffixNum); c != 0 {
+		return c
+	}
+	return compareSegment(aRel, bRel)
+}
+
+func splitAPKRelease(v string) (string, string) {
+	if idx := strings.LastIndex(v, "-r"); idx >= 0 {
+		return v[:idx], v[idx+2:]
+	}
+	return v, "0"
+}
+
+func splitAPKSuffix(v string) (string, string, string) {
+	main, suffix, ok := strings.Cut(v, "_")
+	if !ok {
+		return v, "", "0"
+	}
+	i := 0
+	for i < len(suffix) && (suffix[i] < '0' || suffix[i] > '9') {
+		i++
+	}
+	return main, suffix[:i], orZero(suffix[i:])
+}
+
+// splitAPKMain 拆分主版本号，末尾字母（如 1.0a）作为独立段
+func splitAPKMain(v string) []string {
+	var segments []string
+	for _, part := range strings.Split(v, ".") {
+		i := len(part)
+		for i > 0 && part[i-1] >= 'a' && part[i-1] <= 'z' {
+			i--
+		}
+		if i > 0 && i < len(part) {
+			segments = append(segments, part[:i], part[i:])
+			continue
+		}
+		segments = append(segments, part)
+	}
+	return segments
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
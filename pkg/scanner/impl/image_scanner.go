@@ -0,0 +1,237 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/blackarbiter/go-sac/pkg/storage/minio"
+	"go.uber.org/zap"
+)
+
+// 镜像扫描任务选项中约定的目标描述字段
+const (
+	OptionImagePath   = "image_path"   // 本地镜像 tar 归档（docker save/OCI 归档）或已解开的 OCI 布局目录
+	OptionImageObject = "image_object" // MinIO 中的镜像归档对象路径
+)
+
+// ObjectDownloader 从对象存储下载文件
+type ObjectDownloader interface {
+	DownloadFile(ctx context.Context, objectPath, dest string) error
+}
+
+// ImageScanner 容器镜像扫描器
+type ImageScanner struct {
+	*BaseScanner
+	vulnDBDir  string
+	workDir    string
+	vulnDB     vulnDBCache
+	downloader ObjectDownloader
+}
+
+// NewImageScanner 创建容器镜像扫描器
+func NewImageScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &ImageScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeContainerImageScan)
+	s.vulnDBDir, s.workDir = config.GetImageScanConfig()
+
+	// 配置了 MinIO 时支持从对象存储拉取镜像归档
+	if mc := config.Storage.MinIO; mc.Endpoint != "" {
+		storage, err := minio.NewStorage(mc.Endpoint, mc.AccessKey, mc.SecretKey, mc.Bucket, mc.UseSSL)
+		if err != nil {
+			logger.Warn("minio client unavailable, image_object option disabled", zap.Error(err))
+		} else {
+			s.downloader = storage
+		}
+	}
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeContainerImageScan,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *ImageScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	// 创建扫描结果
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeContainerImageScan, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	// 1. 加载离线漏洞库
+	db, err := s.vulnDB.get(s.vulnDBDir)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 2. 创建沙箱工作目录并定位镜像
+	workDir, err := s.CreateWorkspace(s.workDir, task)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+		}
+	}()
+
+	layoutDir, err := s.prepareImage(ctx, task, workDir)
+	if err != nil {
+		return fail(err)
+	}
+	layout, err := openImageLayout(layoutDir)
+	if err != nil {
+		return fail(err)
+	}
+	s.SetTargetSize(task, scanner.TargetSize{Bytes: dirSize(layoutDir)})
+
+	s.logger.Info("starting image scan",
+		zap.String("task_id", task.TaskID),
+		zap.String("digest", layout.Info.Digest),
+		zap.Strings("repo_tags", layout.Info.RepoTags),
+		zap.Int("layers", len(layout.Info.Layers)))
+
+	// 3. 应用镜像层，仅提取包数据库与依赖清单
+	rootfs := filepath.Join(workDir, "rootfs")
+	if err := layout.unpack(ctx, rootfs, imageFileOfInterest); err != nil {
+		return fail(err)
+	}
+
+	// 4. 收集 OS 包与语言依赖
+	osr := detectOSRelease(rootfs)
+	osPackages, err := CollectOSPackages(rootfs, osr)
+	if err != nil {
+		return fail(err)
+	}
+	appPackages, err := CollectComponents(rootfs)
+	if err != nil {
+		return fail(fmt.Errorf("collect components failed: %w", err))
+	}
+
+	// 5. 匹配漏洞
+	components := append(osPackages, appPackages...)
+	results := make([]SCAComponentResult, 0, len(components))
+	for _, c := range components {
+		results = append(results, matchComponent(db, c))
+	}
+
+	summary := summarizeSCAResults(results)
+	s.logger.Info("image scan finished",
+		zap.String("task_id", task.TaskID),
+		zap.String("digest", layout.Info.Digest),
+		zap.Int("os_packages", len(osPackages)),
+		zap.Int("app_packages", len(appPackages)),
+		zap.Any("summary", summary))
+
+	// 设置成功结果
+	result.SetSuccess(map[string]interface{}{
+		"image":      layout.Info,
+		"os":         osr,
+		"components": results,
+		"summary":    summary,
+	})
+	report := s.findingReport(scaFindings(results))
+	report.Properties = map[string]interface{}{"image_digest": layout.Info.Digest}
+	result.SetFindings(report)
+	return result, nil
+}
+
+// prepareImage 返回镜像布局目录：目录直接使用，归档文件解开到 workDir/image
+func (s *ImageScanner) prepareImage(ctx context.Context, task *domain.ScanTaskPayload, workDir string) (string, error) {
+	var opts domain.ImageScanOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return "", err
+	}
+	imagePath := strings.TrimSpace(opts.ImagePath)
+	if imagePath != "" {
+		resolved, err := s.resolveUploadPath(imagePath)
+		if err != nil {
+			return "", fmt.Errorf("image unavailable: %w", err)
+		}
+		imagePath = resolved
+	}
+	if object := strings.TrimSpace(opts.ImageObject); imagePath == "" && object != "" {
+		if s.downloader == nil {
+			return "", fmt.Errorf("object storage not configured, cannot fetch %s", object)
+		}
+		imagePath = filepath.Join(workDir, "image.tar")
+		if err := s.downloader.DownloadFile(ctx, object, imagePath); err != nil {
+			return "", fmt.Errorf("download image %s failed: %w", object, err)
+		}
+	}
+	if imagePath == "" {
+		return "", fmt.Errorf("missing %s or %s in task options", OptionImagePath, OptionImageObject)
+	}
+
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return "", fmt.Errorf("image unavailable: %w", err)
+	}
+	if info.IsDir() {
+		return imagePath, nil
+	}
+	layoutDir := filepath.Join(workDir, "image")
+	if err := extractImageArchive(imagePath, layoutDir); err != nil {
+		return "", fmt.Errorf("extract image archive failed: %w", err)
+	}
+	return layoutDir, nil
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *ImageScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *ImageScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *ImageScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *ImageScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *ImageScanner) HealthCheck() error {
+	if _, err := os.Stat(s.vulnDBDir); err != nil {
+		return fmt.Errorf("image vuln db unavailable: %w", err)
+	}
+	return s.BaseScanner.HealthCheck()
+}
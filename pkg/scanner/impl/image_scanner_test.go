@@ -0,0 +1,345 @@
+package scanner_impl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testDpkgStatus = `Package: openssl
+Status: install ok installed
+Version: 3.0.11-1~deb12u1
+Source: openssl
+
+Package: libssl3
+Status: install ok installed
+Version: 3.0.11-1~deb12u1
+Source: openssl
+
+Package: zlib1g
+Status: install ok installed
+Version: 1:1.2.13.dfsg-1
+Source: zlib (1:1.2.13.dfsg-1)
+
+Package: removed-pkg
+Status: deinstall ok config-files
+Version: 1.0
+`
+
+var imageOSVFixtures = map[string]string{
+	"Debian/DSA-5532-1.json": `{"id":"DSA-5532-1","aliases":["CVE-2023-5363"],"summary":"openssl security update",
+		"affected":[{"package":{"ecosystem":"Debian:12","name":"openssl"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"0"},{"fixed":"3.0.11-1~deb12u2"}]}]}]}`,
+	"Debian/DLA-zlib.json": `{"id":"DLA-0000-1","summary":"zlib fixed long ago",
+		"affected":[{"package":{"ecosystem":"Debian:12","name":"zlib"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"0"},{"fixed":"1:1.2.11.dfsg-1"}]}]}]}`,
+	"PyPI/PYSEC-2021-9.json": osvFixtures["PyPI/PYSEC-2021-9.json"],
+}
+
+// tarLayer 生成一个未压缩的镜像层
+func tarLayer(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// testImageLayers 基础层安装 openssl 与应用依赖，上层删除一份依赖清单
+func testImageLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		tarLayer(t, map[string]string{
+			"usr/lib/os-release":         "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\nVERSION_ID=\"12\"\n",
+			"var/lib/dpkg/status":        testDpkgStatus,
+			"app/requirements.txt":       "Django==3.2.0\n",
+			"legacy/requirements.txt":    "requests==2.25.1\n",
+			"usr/share/doc/openssl/NEWS": "not extracted",
+		}),
+		tarLayer(t, map[string]string{
+			"legacy/.wh.requirements.txt": "",
+		}),
+	}
+}
+
+// writeDockerArchive 按 docker save 格式写出镜像归档
+func writeDockerArchive(t *testing.T, path string, layers [][]byte) {
+	t.Helper()
+	configJSON := []byte(`{"os":"linux","architecture":"amd64"}`)
+	files := map[string][]byte{"config.json": configJSON}
+	manifest := dockerManifestEntry{Config: "config.json", RepoTags: []string{"example/app:1.0"}}
+	for i, l := range layers {
+		name := fmt.Sprintf("layer%d/layer.tar", i)
+		files[name] = l
+		manifest.Layers = append(manifest.Layers, name)
+	}
+	data, err := json.Marshal([]dockerManifestEntry{manifest})
+	require.NoError(t, err)
+	files["manifest.json"] = data
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	tw := tar.NewWriter(f)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, f.Close())
+}
+
+// writeOCILayout 写出 OCI 镜像布局目录，层使用 gzip 压缩，返回清单摘要
+func writeOCILayout(t *testing.T, dir string, layers [][]byte) string {
+	t.Helper()
+	writeBlob := func(data []byte) ociDescriptor {
+		digest := sha256Digest(data)
+		path, err := blobPath(dir, digest)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		return ociDescriptor{Digest: digest, Size: int64(len(data))}
+	}
+
+	manifest := ociManifest{MediaType: "application/vnd.oci.image.manifest.v1+json"}
+	manifest.Config = writeBlob([]byte(`{"os":"linux","architecture":"arm64"}`))
+	for _, l := range layers {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write(l)
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		manifest.Layers = append(manifest.Layers, writeBlob(buf.Bytes()))
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	desc := writeBlob(data)
+	desc.MediaType = manifest.MediaType
+	desc.Annotations = map[string]string{"org.opencontainers.image.ref.name": "example/app:1.0"}
+
+	index, err := json.Marshal(ociIndex{Manifests: []ociDescriptor{desc}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.json"), index, 0o644))
+	return desc.Digest
+}
+
+func newTestImageScanner(t *testing.T) *ImageScanner {
+	t.Helper()
+	dbDir := t.TempDir()
+	writeFixtures(t, dbDir, imageOSVFixtures)
+
+	cfg := &config.Config{}
+	cfg.Scanner.UploadRoot = os.TempDir()
+	cfg.Scanner.Image.Timeout = 30 * time.Second
+	cfg.Scanner.Image.WorkDir = t.TempDir()
+	cfg.Scanner.Image.VulnDBDir = dbDir
+	return NewImageScanner(nil, zap.NewNop(), cfg).(*ImageScanner)
+}
+
+func imageAdvisories(t *testing.T, result *domain.ScanResult) map[string][]string {
+	t.Helper()
+	components, ok := result.Result["components"].([]SCAComponentResult)
+	require.True(t, ok)
+	advisories := make(map[string][]string)
+	for _, c := range components {
+		advisories[c.Ecosystem+":"+c.Name] = nil
+		for _, a := range c.Advisories {
+			advisories[c.Ecosystem+":"+c.Name] = append(advisories[c.Ecosystem+":"+c.Name], a.ID)
+		}
+	}
+	return advisories
+}
+
+func TestImageScanner_DockerArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "image.tar")
+	writeDockerArchive(t, archive, testImageLayers(t))
+
+	s := newTestImageScanner(t)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-image",
+		AssetID:   "7",
+		AssetType: domain.AssetTypeImage,
+		Options:   map[string]interface{}{OptionImagePath: archive},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+
+	info := result.Result["image"].(ImageInfo)
+	assert.Equal(t, []string{"example/app:1.0"}, info.RepoTags)
+	assert.Equal(t, info.ConfigDigest, info.Digest)
+	assert.Len(t, info.Layers, 2)
+	assert.Equal(t, "amd64", info.Architecture)
+	assert.Positive(t, info.Size)
+
+	osr := result.Result["os"].(*OSRelease)
+	assert.Equal(t, "Debian:12", osr.Ecosystem())
+
+	// 二进制包 libssl3 通过源码包 openssl 命中；被 whiteout 删除的清单不再出现
+	assert.Equal(t, map[string][]string{
+		"Debian:12:openssl": {"DSA-5532-1"},
+		"Debian:12:libssl3": {"DSA-5532-1"},
+		"Debian:12:zlib1g":  nil,
+		"PyPI:django":       {"PYSEC-2021-9"},
+	}, imageAdvisories(t, result))
+
+	summary := result.Result["summary"].(map[string]interface{})
+	assert.Equal(t, 3, summary["vulnerable"])
+}
+
+func TestImageScanner_OCILayout(t *testing.T) {
+	dir := t.TempDir()
+	digest := writeOCILayout(t, dir, testImageLayers(t))
+
+	s := newTestImageScanner(t)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-oci",
+		Options: map[string]interface{}{OptionImagePath: dir},
+	})
+	require.NoError(t, err)
+
+	info := result.Result["image"].(ImageInfo)
+	assert.Equal(t, digest, info.Digest)
+	assert.Equal(t, []string{"example/app:1.0"}, info.RepoTags)
+	assert.Equal(t, "arm64", info.Architecture)
+	assert.Contains(t, imageAdvisories(t, result), "Debian:12:libssl3")
+}
+
+func TestImageScanner_MissingImage(t *testing.T) {
+	s := newTestImageScanner(t)
+	_, err := s.Scan(context.Background(), &domain.ScanTaskPayload{TaskID: "task-empty"})
+	assert.ErrorContains(t, err, OptionImagePath)
+
+	_, err = s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-outside",
+		Options: map[string]interface{}{OptionImagePath: "/etc"},
+	})
+	assert.ErrorContains(t, err, "outside upload root")
+
+	_, err = s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-object",
+		Options: map[string]interface{}{OptionImageObject: "images/app.tar"},
+	})
+	assert.ErrorContains(t, err, "object storage not configured")
+}
+
+func TestParseApkInstalled(t *testing.T) {
+	components := parseApkInstalled([]byte("P:busybox\nV:1.36.1-r15\no:busybox\nL:GPL-2.0-only\n\nP:ssl_client\nV:1.36.1-r15\no:busybox\n"))
+	require.Len(t, components, 2)
+	assert.Equal(t, "busybox", components[0].Name)
+	assert.Equal(t, "1.36.1-r15", components[0].Version)
+	assert.Equal(t, "GPL-2.0-only", components[0].License)
+	assert.Equal(t, "busybox", components[1].SourcePackage)
+}
+
+// rpmHeaderBlob 构造仅包含字符串与 int32 标签的 rpm 包头
+func rpmHeaderBlob(strs map[uint32]string, epoch int) []byte {
+	var index, store bytes.Buffer
+	entry := func(tag, typ uint32, offset int) {
+		_ = binary.Write(&index, binary.BigEndian, [4]uint32{tag, typ, uint32(offset), 1})
+	}
+	for _, tag := range []uint32{rpmTagName, rpmTagVersion, rpmTagRelease, rpmTagLicense, rpmTagSourceRPM} {
+		if v, ok := strs[tag]; ok {
+			entry(tag, rpmTypeString, store.Len())
+			store.WriteString(v)
+			store.WriteByte(0)
+		}
+	}
+	if epoch >= 0 {
+		for store.Len()%4 != 0 {
+			store.WriteByte(0)
+		}
+		entry(rpmTagEpoch, rpmTypeInt32, store.Len())
+		_ = binary.Write(&store, binary.BigEndian, uint32(epoch))
+	}
+	var blob bytes.Buffer
+	_ = binary.Write(&blob, binary.BigEndian, [2]uint32{uint32(index.Len() / rpmHeaderIndexSize), uint32(store.Len())})
+	blob.Write(index.Bytes())
+	blob.Write(store.Bytes())
+	return blob.Bytes()
+}
+
+func TestCollectOSPackages_RPMSqlite(t *testing.T) {
+	rootfs := t.TempDir()
+	writeFixtures(t, rootfs, map[string]string{"etc/os-release": "ID=\"rocky\"\nVERSION_ID=\"9.3\"\n"})
+	dbPath := filepath.Join(rootfs, rpmSqlitePaths[0])
+	require.NoError(t, os.MkdirAll(filepath.Dir(dbPath), 0o755))
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE Packages (hnum INTEGER PRIMARY KEY AUTOINCREMENT, blob BLOB NOT NULL)")
+	require.NoError(t, err)
+	for _, blob := range [][]byte{
+		rpmHeaderBlob(map[uint32]string{
+			rpmTagName: "openssl-libs", rpmTagVersion: "3.0.7", rpmTagRelease: "24.el9",
+			rpmTagLicense: "ASL 2.0", rpmTagSourceRPM: "openssl-3.0.7-24.el9.src.rpm",
+		}, 1),
+		rpmHeaderBlob(map[uint32]string{rpmTagName: "gpg-pubkey", rpmTagVersion: "350d275d", rpmTagRelease: "6279464b"}, -1),
+	} {
+		_, err = db.Exec("INSERT INTO Packages (blob) VALUES (?)", blob)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	osr := detectOSRelease(rootfs)
+	require.NotNil(t, osr)
+	components, err := CollectOSPackages(rootfs, osr)
+	require.NoError(t, err)
+	require.Len(t, components, 1)
+	assert.Equal(t, Component{
+		Name:          "openssl-libs",
+		Version:       "1:3.0.7-24.el9",
+		Ecosystem:     "Rocky Linux:9",
+		License:       "ASL 2.0",
+		Direct:        true,
+		Source:        rpmSqlitePaths[0],
+		SourcePackage: "openssl",
+	}, components[0])
+}
+
+func TestDistroVersionComparers(t *testing.T) {
+	cases := []struct {
+		compare func(a, b string) int
+		a, b    string
+		want    int
+	}{
+		{compareDebianVersions, "3.0.11-1~deb12u1", "3.0.11-1~deb12u2", -1},
+		{compareDebianVersions, "3.0.11-1~deb12u1", "3.0.11-1", -1},
+		{compareDebianVersions, "1:1.2.13.dfsg-1", "1.2.14", 1},
+		{compareDebianVersions, "2.36-9+deb12u4", "2.36-9+deb12u4", 0},
+		{compareRPMVersions, "1:3.0.7-24.el9", "3.0.8-1.el9", 1},
+		{compareRPMVersions, "3.0.7-24.el9", "3.0.7-25.el9", -1},
+		{compareRPMVersions, "1.0~rc1-1", "1.0-1", -1},
+		{compareAPKVersions, "1.36.1-r15", "1.36.1-r2", 1},
+		{compareAPKVersions, "3.1.4_rc1-r0", "3.1.4-r0", -1},
+		{compareAPKVersions, "3.1.4_p1-r0", "3.1.4-r0", 1},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.compare(c.a, c.b), "%s vs %s", c.a, c.b)
+	}
+}
@@ -0,0 +1,234 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+const (
+	// outputChunkQueue 等待上传的输出块数，上传跟不上时丢弃后续输出而不阻塞扫描进程
+	outputChunkQueue = 256
+	// outputPartSize 流式上传的分片大小，未知长度上传时每个执行最多缓冲一个分片
+	outputPartSize = 5 << 20
+	// defaultTailBytes 未配置时内存中保留的输出尾部字节数
+	defaultTailBytes = 4 << 10
+	// outputWaitDelay 进程退出后等待输出管道关闭的时间，防止逃逸的孙进程持有管道使 Wait 永不返回
+	outputWaitDelay = 5 * time.Second
+)
+
+// LogUploader 流式上传扫描命令输出，*minio.Client（pkg/storage/minio）满足该接口
+type LogUploader interface {
+	PutLargeObject(ctx context.Context, objectPath string, reader io.Reader, size int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+}
+
+// WithLogUploader 将命令输出上传到对象存储，uploader 为 nil 时只保留输出尾部
+func WithLogUploader(uploader LogUploader) BaseScannerOption {
+	return func(bs *BaseScanner) {
+		bs.logUploader = uploader
+	}
+}
+
+// ringBuffer 固定容量的环形缓冲，只保留最后写入的字节
+type ringBuffer struct {
+	buf  []byte
+	pos  int
+	full bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	if size <= 0 {
+		size = defaultTailBytes
+	}
+	return &ringBuffer{buf: make([]byte, size)}
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= len(r.buf) {
+		copy(r.buf, p[n-len(r.buf):])
+		r.pos, r.full = 0, true
+		return n, nil
+	}
+	written := copy(r.buf[r.pos:], p)
+	if written < n {
+		copy(r.buf, p[written:])
+		r.full = true
+	}
+	r.pos = (r.pos + n) % len(r.buf)
+	if r.pos == 0 {
+		r.full = true
+	}
+	return n, nil
+}
+
+// Bytes 按写入顺序返回保留的字节
+func (r *ringBuffer) Bytes() []byte {
+	if !r.full {
+		return append([]byte(nil), r.buf[:r.pos]...)
+	}
+	return append(append([]byte(nil), r.buf[r.pos:]...), r.buf[:r.pos]...)
+}
+
+// commandOutput 单次命令执行的输出捕获：stdout/stderr 合并写入环形缓冲，
+// 配置了上传器时首次输出后开始流式上传到 objectPath
+type commandOutput struct {
+	mu         sync.Mutex
+	tail       *ringBuffer
+	uploader   LogUploader
+	objectPath string
+	chunks     chan []byte
+	uploaded   chan error
+	cancel     context.CancelFunc
+	closed     bool
+	written    int64
+	dropped    int64
+}
+
+// Write 实现 io.Writer，始终成功以免影响扫描器自身对输出的处理
+func (o *commandOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tail.Write(p)
+	o.written += int64(len(p))
+	if o.uploader == nil || o.closed {
+		return len(p), nil
+	}
+	if o.chunks == nil {
+		o.startUpload()
+	}
+	select {
+	case o.chunks <- append([]byte(nil), p...):
+	default:
+		o.dropped += int64(len(p))
+	}
+	return len(p), nil
+}
+
+// startUpload 启动流式上传，调用方需持有锁
+func (o *commandOutput) startUpload() {
+	ctx, cancel := context.WithCancel(context.Background())
+	pr, pw := io.Pipe()
+	o.chunks = make(chan []byte, outputChunkQueue)
+	o.uploaded = make(chan error, 1)
+	o.cancel = cancel
+
+	go func(chunks <-chan []byte) {
+		for chunk := range chunks {
+			// 上传失败后管道返回错误，继续排空队列
+			_, _ = pw.Write(chunk)
+		}
+		pw.Close()
+	}(o.chunks)
+
+	go func() {
+		_, err := o.uploader.PutLargeObject(ctx, o.objectPath, pr, -1, minio.PutObjectOptions{
+			ContentType: "text/plain; charset=utf-8",
+			PartSize:    outputPartSize,
+		})
+		pr.CloseWithError(err)
+		o.uploaded <- err
+	}()
+}
+
+// close 停止捕获并等待上传完成；返回上传的对象路径，未上传时为空
+func (o *commandOutput) close(timeout time.Duration) (string, error) {
+	o.mu.Lock()
+	o.closed = true
+	chunks := o.chunks
+	o.mu.Unlock()
+	if chunks == nil {
+		return "", nil
+	}
+	close(chunks)
+	defer o.cancel()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-o.uploaded:
+		if err != nil {
+			return "", fmt.Errorf("upload command output: %w", err)
+		}
+		return o.objectPath, nil
+	case <-timer.C:
+		return "", fmt.Errorf("upload command output: timed out after %s", timeout)
+	}
+}
+
+// annotate 在命令失败的错误信息后附加输出尾部
+func (o *commandOutput) annotate(err error) error {
+	o.mu.Lock()
+	tail := strings.TrimSpace(string(o.tail.Bytes()))
+	truncated := o.written > int64(len(o.tail.buf))
+	o.mu.Unlock()
+	if tail == "" {
+		return err
+	}
+	if truncated {
+		// 去掉被截断的首行
+		if i := strings.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+		tail = "...\n" + tail
+	}
+	return fmt.Errorf("%w\noutput:\n%s", err, tail)
+}
+
+// captureOutput 将命令的 stdout/stderr 接入输出捕获，扫描器已设置的输出目标保持不变
+func (s *BaseScanner) captureOutput(task *domain.ScanTaskPayload, cmd *exec.Cmd, execID string) *commandOutput {
+	output := &commandOutput{tail: newRingBuffer(s.outputCapture.TailBytes)}
+	if s.logUploader != nil && s.outputCapture.Upload && s.logPrefix != nil {
+		output.uploader = s.logUploader
+		output.objectPath = s.logPrefix(task.TaskID) + execID + ".log"
+	}
+
+	// 同一个输出目标只包装一次，保持 exec 对 Stdout == Stderr 的单管道处理
+	shared := cmd.Stdout != nil && cmd.Stdout == cmd.Stderr
+	cmd.Stdout = teeOutput(output, cmd.Stdout)
+	if shared {
+		cmd.Stderr = cmd.Stdout
+	} else {
+		cmd.Stderr = teeOutput(output, cmd.Stderr)
+	}
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = outputWaitDelay
+	}
+	return output
+}
+
+// finishOutput 结束输出捕获，上传成功的对象登记到任务的执行记录
+func (s *BaseScanner) finishOutput(task *domain.ScanTaskPayload, output *commandOutput, execID string) {
+	path, err := output.close(s.outputCapture.UploadTimeout)
+	if err != nil {
+		s.logger.Warn("command output not stored",
+			zap.String("task_id", task.TaskID),
+			zap.String("exec_id", execID),
+			zap.Error(err))
+		return
+	}
+	if output.dropped > 0 {
+		s.logger.Warn("command output truncated, upload could not keep up",
+			zap.String("task_id", task.TaskID),
+			zap.String("exec_id", execID),
+			zap.Int64("dropped_bytes", output.dropped))
+	}
+	if path != "" {
+		s.executions.addLogObject(task.TaskID, path)
+	}
+}
+
+func teeOutput(output *commandOutput, w io.Writer) io.Writer {
+	if w == nil {
+		return output
+	}
+	return io.MultiWriter(output, w)
+}
@@ -0,0 +1,106 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeUploader 在内存中记录上传的对象
+type fakeUploader struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	err     error
+}
+
+func (f *fakeUploader) PutLargeObject(ctx context.Context, objectPath string, reader io.Reader, size int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	if f.err != nil {
+		return minio.UploadInfo{}, f.err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[objectPath] = data
+	return minio.UploadInfo{Size: int64(len(data))}, nil
+}
+
+func newCaptureTestScanner(t *testing.T, uploader LogUploader) *BaseScanner {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.ProcessSandbox.WorkDir = t.TempDir()
+	cfg.Scanner.OutputCapture.Upload = true
+	cfg.Scanner.OutputCapture.TailBytes = 64
+	cfg.Storage.MinIO.Bucket = "scan-results"
+	return NewBaseScanner(domain.ScanTypeSca, nil, zap.NewNop(), cfg, WithLogUploader(uploader))
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := newRingBuffer(8)
+	r.Write([]byte("abc"))
+	assert.Equal(t, "abc", string(r.Bytes()))
+	r.Write([]byte("defgh"))
+	assert.Equal(t, "abcdefgh", string(r.Bytes()))
+	r.Write([]byte("ij"))
+	assert.Equal(t, "cdefghij", string(r.Bytes()))
+	r.Write([]byte("0123456789"))
+	assert.Equal(t, "23456789", string(r.Bytes()))
+}
+
+func TestExecuteCommand_UploadsOutput(t *testing.T) {
+	uploader := &fakeUploader{objects: make(map[string][]byte)}
+	bs := newCaptureTestScanner(t, uploader)
+	task := &domain.ScanTaskPayload{TaskID: "capture-ok", ScanType: domain.ScanTypeSca}
+
+	result, err := bs.ExecuteWithResult(context.Background(), task, func(ctx context.Context) (*domain.ScanResult, error) {
+		// 扫描器自己读取 stdout 时仍能拿到完整输出
+		var stdout bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo report; echo progress >&2")
+		cmd.Stdout = &stdout
+		if err := bs.ExecuteCommand(ctx, task, cmd, ""); err != nil {
+			return nil, err
+		}
+		assert.Equal(t, "report\n", stdout.String())
+		return domain.NewScanResult(task.TaskID, domain.ScanTypeSca, "", domain.AssetTypeRepository), nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.LogObjects, 1)
+	path := result.LogObjects[0]
+	assert.True(t, strings.HasPrefix(path, "scan-results/scan-logs/capture-ok/capture-ok-"), path)
+	assert.True(t, strings.HasSuffix(path, ".log"), path)
+	log := string(uploader.objects[path])
+	assert.Contains(t, log, "report\n")
+	assert.Contains(t, log, "progress\n")
+}
+
+func TestExecuteCommand_FailureIncludesOutputTail(t *testing.T) {
+	uploader := &fakeUploader{objects: make(map[string][]byte), err: errors.New("minio down")}
+	bs := newCaptureTestScanner(t, uploader)
+	task := &domain.ScanTaskPayload{TaskID: "capture-fail", ScanType: domain.ScanTypeSca}
+
+	// 输出超过尾部容量时只保留最后几行，上传失败不影响命令结果
+	cmd := exec.Command("sh", "-c", "for i in 1 2 3 4 5 6 7 8 9; do echo line-$i-padding; done; echo 'fatal: db locked' >&2; exit 3")
+	err := bs.ExecuteCommand(context.Background(), task, cmd, "")
+	require.Error(t, err)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Contains(t, err.Error(), "fatal: db locked")
+	assert.NotContains(t, err.Error(), "line-1-")
+	assert.Empty(t, uploader.objects)
+}
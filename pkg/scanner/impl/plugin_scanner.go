@@ -0,0 +1,605 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// PluginProtocolVersion 插件协议版本
+//
+// 握手：<plugin> describe 向 stdout 输出一个 PluginDescriptor JSON 后以 0 退出。
+// 扫描：<plugin> scan 从 stdin 读取一个 PluginScanRequest JSON，向 stdout 逐行输出 PluginEvent JSON，
+// 以 0 退出表示成功；stderr 仅用于诊断，失败时截取后写入错误信息。
+const PluginProtocolVersion = 1
+
+// 插件事件类型
+const (
+	PluginEventProgress = "progress" // 进度，Progress 取值 0~1
+	PluginEventFinding  = "finding"  // 一个发现项
+	PluginEventRule     = "rule"     // 规则元数据，可选
+	PluginEventResult   = "result"   // 附加到扫描结果的插件特有数据，多次输出时合并
+	PluginEventLog      = "log"      // 日志
+	PluginEventError    = "error"    // 致命错误，扫描判定失败
+)
+
+// pluginMaxLineBytes 单行事件的长度上限
+const pluginMaxLineBytes = 4 << 20
+
+// PluginDescriptor describe 握手返回的插件元数据
+type PluginDescriptor struct {
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	ProtocolVersion int      `json:"protocol_version"`
+	SupportedTypes  []string `json:"supported_types"` // 扫描类型名称，如 SCA、SecuritySpecCheck
+	ResourceProfile struct {
+		MinCPU      int  `json:"min_cpu"`
+		MaxCPU      int  `json:"max_cpu"`
+		MemoryMB    int  `json:"memory_mb"`
+		RequiresGPU bool `json:"requires_gpu"`
+	} `json:"resource_profile"`
+}
+
+// PluginScanRequest scan 子命令的标准输入
+type PluginScanRequest struct {
+	ProtocolVersion int                     `json:"protocol_version"`
+	ScanType        string                  `json:"scan_type"`
+	AssetType       string                  `json:"asset_type"`
+	WorkDir         string                  `json:"work_dir"` // 本次执行的临时工作目录，扫描结束后删除
+	Task            *domain.ScanTaskPayload `json:"task"`
+}
+
+// PluginEvent scan 子命令输出的一行事件
+type PluginEvent struct {
+	Type     string                 `json:"type"`
+	Progress float64                `json:"progress,omitempty"`
+	Message  string                 `json:"message,omitempty"`
+	Level    string                 `json:"level,omitempty"`
+	Finding  *domain.Finding        `json:"finding,omitempty"`
+	Rule     *domain.FindingRule    `json:"rule,omitempty"`
+	Result   map[string]interface{} `json:"result,omitempty"`
+}
+
+// scanTypes 解析声明的扫描类型，忽略无法识别的名称
+func (d PluginDescriptor) scanTypes() []domain.ScanType {
+	types := make([]domain.ScanType, 0, len(d.SupportedTypes))
+	for _, name := range d.SupportedTypes {
+		if t, err := domain.ParseScanType(name); err == nil && t != domain.ScanTypeUnknown {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (d PluginDescriptor) validate() error {
+	if d.Name == "" {
+		return errors.New("plugin name is required")
+	}
+	if d.ProtocolVersion != PluginProtocolVersion {
+		return fmt.Errorf("unsupported plugin protocol version %d, expected %d", d.ProtocolVersion, PluginProtocolVersion)
+	}
+	if len(d.scanTypes()) == 0 {
+		return fmt.Errorf("plugin %s declares no known scan type: %v", d.Name, d.SupportedTypes)
+	}
+	return nil
+}
+
+func (d PluginDescriptor) meta() scanner.ExecutorMeta {
+	rp := scanner.ResourceProfile{
+		MinCPU:      d.ResourceProfile.MinCPU,
+		MaxCPU:      d.ResourceProfile.MaxCPU,
+		MemoryMB:    d.ResourceProfile.MemoryMB,
+		RequiresGPU: d.ResourceProfile.RequiresGPU,
+	}
+	if rp.MaxCPU <= 0 {
+		rp.MaxCPU = 1
+	}
+	if rp.MinCPU <= 0 {
+		rp.MinCPU = 1
+	}
+	if rp.MemoryMB <= 0 {
+		rp.MemoryMB = 512
+	}
+	return scanner.ExecutorMeta{
+		Type:            "plugin:" + d.Name,
+		Version:         d.Version,
+		SupportedTypes:  d.scanTypes(),
+		ResourceProfile: rp,
+	}
+}
+
+func parseDescriptor(data []byte) (PluginDescriptor, error) {
+	var desc PluginDescriptor
+	if err := json.Unmarshal(bytes.TrimSpace(data), &desc); err != nil {
+		return desc, fmt.Errorf("invalid plugin descriptor: %w", err)
+	}
+	return desc, desc.validate()
+}
+
+// PluginScanner 以子进程协议运行的外部扫描插件
+type PluginScanner struct {
+	*BaseScanner
+	path    string
+	desc    PluginDescriptor
+	workDir string
+}
+
+// NewPluginScanner 根据握手得到的描述创建插件执行器，每次扫描经由 BaseScanner.ExecuteCommand 启动插件进程
+func NewPluginScanner(
+	path string,
+	desc PluginDescriptor,
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) (*PluginScanner, error) {
+	if err := desc.validate(); err != nil {
+		return nil, err
+	}
+	pc := config.GetPluginConfig()
+	meta := desc.meta()
+	s := &PluginScanner{path: path, desc: desc, workDir: pc.WorkDir}
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(meta.ResourceProfile),
+		WithSecurityProfile(pc.SecurityProfile.RunAsUser, pc.SecurityProfile.RunAsGroup, pc.SecurityProfile.NoNewPrivs),
+		WithTimeout(pc.Timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+		withExecutorMeta(meta),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		meta.SupportedTypes[0],
+		timeoutCtrl,
+		logger.With(zap.String("plugin", desc.Name)),
+		config,
+		baseOpts...,
+	)
+	return s, nil
+}
+
+// withExecutorMeta 使用插件声明的元数据代替按扫描类型生成的默认值
+func withExecutorMeta(meta scanner.ExecutorMeta) BaseScannerOption {
+	return func(bs *BaseScanner) {
+		bs.meta = meta
+	}
+}
+
+// Scan 启动插件进程执行扫描
+func (s *PluginScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	scanType := task.ScanType
+	if !s.supports(scanType) {
+		scanType = s.scanType
+	}
+	result := domain.NewScanResult(task.TaskID, scanType, task.AssetID, task.AssetType)
+
+	workDir, err := s.CreateWorkspace(s.workDir, task)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+		}
+	}()
+
+	request, err := json.Marshal(PluginScanRequest{
+		ProtocolVersion: PluginProtocolVersion,
+		ScanType:        scanType.String(),
+		AssetType:       task.AssetType.String(),
+		WorkDir:         workDir,
+		Task:            task,
+	})
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	collector := newPluginCollector(s, task)
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.path, "scan")
+	cmd.Dir = workDir
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = collector
+	cmd.Stderr = &limitedBuffer{buf: &stderr, limit: 64 << 10}
+
+	err = s.ExecuteCommand(ctx, task, cmd, "")
+	collector.flush()
+	if err == nil {
+		err = collector.err()
+	}
+	if err != nil {
+		err = fmt.Errorf("plugin %s failed: %w: %s", s.desc.Name, err, truncate(stderr.String(), 512))
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	data := map[string]interface{}{}
+	for k, v := range collector.result {
+		data[k] = v
+	}
+	data["plugin"] = s.desc.Name
+	data["version"] = s.desc.Version
+	data["findings"] = collector.findings
+	data["summary"] = summarizeFindings(collector.findings)
+	result.SetSuccess(data)
+	result.SetFindings(domain.FindingReport{
+		Tool:        s.desc.Name,
+		ToolVersion: s.desc.Version,
+		Rules:       collector.rules,
+		Findings:    collector.findings,
+	})
+	return result, nil
+}
+
+func (s *PluginScanner) supports(scanType domain.ScanType) bool {
+	for _, t := range s.meta.SupportedTypes {
+		if t == scanType {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginCollector 按行解析插件标准输出中的事件
+type pluginCollector struct {
+	scanner  *PluginScanner
+	task     *domain.ScanTaskPayload
+	mu       sync.Mutex
+	pending  []byte
+	findings []domain.Finding
+	rules    []domain.FindingRule
+	result   map[string]interface{}
+	failure  error
+}
+
+func newPluginCollector(s *PluginScanner, task *domain.ScanTaskPayload) *pluginCollector {
+	return &pluginCollector{scanner: s, task: task, findings: []domain.Finding{}, result: map[string]interface{}{}}
+}
+
+// Write 实现 io.Writer，由 exec 的输出复制协程调用
+func (c *pluginCollector) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, p...)
+	for {
+		idx := bytes.IndexByte(c.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		c.handleLine(c.pending[:idx])
+		c.pending = c.pending[idx+1:]
+	}
+	if len(c.pending) > pluginMaxLineBytes {
+		c.setFailure(fmt.Errorf("plugin event exceeds %d bytes", pluginMaxLineBytes))
+		c.pending = nil
+	}
+	return len(p), nil
+}
+
+// flush 处理最后一行未以换行结尾的事件
+func (c *pluginCollector) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) > 0 {
+		c.handleLine(c.pending)
+		c.pending = nil
+	}
+}
+
+func (c *pluginCollector) err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failure
+}
+
+func (c *pluginCollector) setFailure(err error) {
+	if c.failure == nil {
+		c.failure = err
+	}
+}
+
+func (c *pluginCollector) handleLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	var event PluginEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		c.setFailure(fmt.Errorf("invalid plugin event: %w", err))
+		return
+	}
+
+	logger := c.scanner.logger.With(zap.String("task_id", c.task.TaskID))
+	switch event.Type {
+	case PluginEventProgress:
+		logger.Debug("plugin progress", zap.Float64("progress", event.Progress), zap.String("message", event.Message))
+		if c.scanner.metricsRecorder != nil {
+			c.scanner.metricsRecorder.Gauge("plugin_progress", event.Progress, map[string]string{
+				"plugin":  c.scanner.desc.Name,
+				"task_id": c.task.TaskID,
+			})
+		}
+	case PluginEventFinding:
+		if event.Finding == nil || event.Finding.RuleID == "" {
+			c.setFailure(errors.New("plugin finding event without rule_id"))
+			return
+		}
+		f := *event.Finding
+		if severity := domain.NormalizeSeverity(f.Severity); severity != "" {
+			f.Severity = severity
+		} else {
+			f.Severity = domain.SeverityMedium
+		}
+		c.findings = append(c.findings, f)
+	case PluginEventRule:
+		if event.Rule != nil && event.Rule.ID != "" {
+			c.rules = append(c.rules, *event.Rule)
+		}
+	case PluginEventResult:
+		for k, v := range event.Result {
+			c.result[k] = v
+		}
+	case PluginEventLog:
+		switch strings.ToLower(event.Level) {
+		case "error":
+			logger.Error("plugin log", zap.String("message", event.Message))
+		case "warn", "warning":
+			logger.Warn("plugin log", zap.String("message", event.Message))
+		default:
+			logger.Info("plugin log", zap.String("message", event.Message))
+		}
+	case PluginEventError:
+		c.setFailure(fmt.Errorf("plugin reported error: %s", event.Message))
+	default:
+		logger.Debug("unknown plugin event ignored", zap.String("type", event.Type))
+	}
+}
+
+// summarizeFindings 按严重等级统计统一发现项
+func summarizeFindings(findings []domain.Finding) map[string]interface{} {
+	bySeverity := make(map[string]int)
+	for _, f := range findings {
+		bySeverity[f.Severity]++
+	}
+	return map[string]interface{}{
+		"total":       len(findings),
+		"by_severity": bySeverity,
+	}
+}
+
+// limitedBuffer 超出上限后丢弃写入，避免异常插件耗尽内存
+type limitedBuffer struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remain := b.limit - b.buf.Len(); remain > 0 {
+		if len(p) > remain {
+			b.buf.Write(p[:remain])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *PluginScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *PluginScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *PluginScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *PluginScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口，经由 ExecuteCommand 重新握手并确认插件未被替换
+func (s *PluginScanner) HealthCheck() error {
+	var stdout bytes.Buffer
+	cmd := exec.Command(s.path, "describe")
+	cmd.Stdout = &limitedBuffer{buf: &stdout, limit: pluginMaxLineBytes}
+	if err := s.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{}, cmd, "healthCheck"); err != nil {
+		return fmt.Errorf("plugin %s handshake failed: %w", s.desc.Name, err)
+	}
+	desc, err := parseDescriptor(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	if desc.Name != s.desc.Name || desc.Version != s.desc.Version {
+		return fmt.Errorf("plugin %s changed to %s@%s, rediscovery required", s.desc.Name, desc.Name, desc.Version)
+	}
+	return s.BaseScanner.HealthCheck()
+}
+
+// Close 停止监听系统信号并退出看门狗巡检，由扫描器工厂在插件被替换或注销后调用；
+// 已在执行的命令仍受自身超时控制
+func (s *PluginScanner) Close() error {
+	s.stopSignalHandling()
+	s.timeoutCtrl.Watchdog().RemoveSource(s.BaseScanner)
+	return nil
+}
+
+// PluginDirectory 从插件目录发现插件，实现 scanner.PluginSource
+type PluginDirectory struct {
+	dir         string
+	handshake   *BaseScanner // 以插件的安全配置执行 describe 握手
+	timeoutCtrl *scanner.TimeoutController
+	logger      *zap.Logger
+	config      *config.Config
+	opts        []BaseScannerOption
+	mu          sync.Mutex
+	cache       map[string]*pluginEntry // 插件路径 -> 上次握手结果
+}
+
+// pluginEntry 以文件修改时间与大小判断插件是否被替换
+type pluginEntry struct {
+	modTime  time.Time
+	size     int64
+	executor *PluginScanner
+}
+
+// NewPluginDirectory 创建插件目录发现器，opts 应用到每个插件执行器
+func NewPluginDirectory(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) *PluginDirectory {
+	pc := config.GetPluginConfig()
+	handshakeOpts := append([]BaseScannerOption{
+		WithSecurityProfile(pc.SecurityProfile.RunAsUser, pc.SecurityProfile.RunAsGroup, pc.SecurityProfile.NoNewPrivs),
+		withExecutorMeta(scanner.ExecutorMeta{Type: "plugin:handshake", Version: "1.0.0"}),
+	}, opts...)
+	// 握手超时在插件扫描器选项之后设置，不被覆盖
+	handshakeOpts = append(handshakeOpts, WithTimeout(pc.HandshakeTimeout, time.Second))
+	return &PluginDirectory{
+		dir:         pc.Dir,
+		handshake:   NewBaseScanner(domain.ScanTypeUnknown, timeoutCtrl, logger.With(zap.String("plugin", "handshake")), config, handshakeOpts...),
+		timeoutCtrl: timeoutCtrl,
+		logger:      logger,
+		config:      config,
+		opts:        opts,
+		cache:       make(map[string]*pluginEntry),
+	}
+}
+
+// describe 执行 describe 握手，与扫描相同经由 ExecuteCommand 在独立进程组、受限环境与控制组中运行
+func (d *PluginDirectory) describe(ctx context.Context, path string) (PluginDescriptor, error) {
+	var desc PluginDescriptor
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, "describe")
+	cmd.Stdout = &limitedBuffer{buf: &stdout, limit: pluginMaxLineBytes}
+	cmd.Stderr = &limitedBuffer{buf: &stderr, limit: 4096}
+	if err := d.handshake.ExecuteCommand(ctx, &domain.ScanTaskPayload{}, cmd, "healthCheck"); err != nil {
+		return desc, fmt.Errorf("plugin describe failed: %w: %s", err, truncate(stderr.String(), 512))
+	}
+	return parseDescriptor(stdout.Bytes())
+}
+
+// checkPluginOwner 插件及插件目录须属于 root 或服务用户，且不可被组或其他用户写入，避免被低权限用户替换
+func checkPluginOwner(path string, info os.FileInfo) error {
+	if perm := info.Mode().Perm(); perm&0o022 != 0 {
+		return fmt.Errorf("%s is group or world writable (%s)", path, perm)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Uid != 0 && int(st.Uid) != os.Geteuid() {
+		return fmt.Errorf("%s is owned by uid %d, expected root or the service user", path, st.Uid)
+	}
+	return nil
+}
+
+// Discover 实现 scanner.PluginSource，文件未变化的插件复用已有执行器，握手失败的插件被跳过
+func (d *PluginDirectory) Discover(ctx context.Context) ([]scanner.TaskExecutor, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read plugin dir failed: %w", err)
+	}
+	dirInfo, err := os.Stat(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("stat plugin dir failed: %w", err)
+	}
+	if err := checkPluginOwner(d.dir, dirInfo); err != nil {
+		return nil, fmt.Errorf("plugin dir rejected: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var executors []scanner.TaskExecutor
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(d.dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		if err := checkPluginOwner(path, info); err != nil {
+			d.logger.Warn("plugin rejected", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		seen[path] = true
+
+		if cached, ok := d.cache[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			executors = append(executors, cached.executor)
+			continue
+		}
+
+		d.retire(path)
+		desc, err := d.describe(ctx, path)
+		if err != nil {
+			d.logger.Warn("plugin handshake failed", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		executor, err := NewPluginScanner(path, desc, d.timeoutCtrl, d.logger, d.config, d.opts...)
+		if err != nil {
+			d.logger.Warn("plugin rejected", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		d.logger.Info("plugin discovered",
+			zap.String("path", path),
+			zap.String("name", desc.Name),
+			zap.String("version", desc.Version),
+			zap.Strings("supported_types", desc.SupportedTypes))
+		d.cache[path] = &pluginEntry{modTime: info.ModTime(), size: info.Size(), executor: executor}
+		executors = append(executors, executor)
+	}
+
+	for path := range d.cache {
+		if !seen[path] {
+			d.retire(path)
+		}
+	}
+
+	// 按插件名排序，多个插件声明同一扫描类型时结果稳定
+	sort.Slice(executors, func(i, j int) bool {
+		return executors[i].Meta().Type < executors[j].Meta().Type
+	})
+	return executors, nil
+}
+
+// retire 丢弃已删除或被替换的插件执行器并将其移出看门狗巡检，其正在运行的扫描不受影响
+func (d *PluginDirectory) retire(path string) {
+	if cached, ok := d.cache[path]; ok {
+		_ = cached.executor.Close()
+		delete(d.cache, path)
+	}
+}
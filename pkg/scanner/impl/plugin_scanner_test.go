@@ -0,0 +1,237 @@
+package scanner_impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePluginScript 声明 SecuritySpecCheck 的插件，scan 时回显任务ID并输出一个发现项
+const fakePluginScript = `#!/bin/sh
+case "$1" in
+describe)
+  echo '{"name":"fake-iac","version":"VERSION","protocol_version":1,"supported_types":["SecuritySpecCheck"],"resource_profile":{"max_cpu":1,"memory_mb":128}}'
+  ;;
+scan)
+  req=$(cat)
+  echo '{"type":"progress","progress":0.5,"message":"parsing"}'
+  echo '{"type":"rule","rule":{"id":"IAC-001","name":"public-bucket","severity":"high"}}'
+  printf '{"type":"finding","finding":{"rule_id":"IAC-001","severity":"HIGH","message":"bucket is public","location":{"path":"main.tf","start_line":3}}}\n'
+  case "$req" in
+  *'"task_id":"task-fail"'*) echo '{"type":"error","message":"parser crashed"}' ;;
+  esac
+  printf '{"type":"result","result":{"files":1}}'
+  ;;
+*)
+  exit 2
+  ;;
+esac
+`
+
+func writeFakePlugin(t *testing.T, dir, version string) string {
+	t.Helper()
+	path := filepath.Join(dir, "fake-iac")
+	script := strings.ReplaceAll(fakePluginScript, "VERSION", version)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func newPluginTestConfig(t *testing.T, dir string) *config.Config {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.Plugins.Dir = dir
+	cfg.Scanner.Plugins.WorkDir = t.TempDir()
+	cfg.Scanner.ProcessSandbox.WorkDir = t.TempDir()
+	return cfg
+}
+
+func TestPluginScanner_Scan(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFakePlugin(t, dir, "1.0.0")
+	cfg := newPluginTestConfig(t, dir)
+
+	desc, err := NewPluginDirectory(nil, zap.NewNop(), cfg).describe(context.Background(), path)
+	require.NoError(t, err)
+	s, err := NewPluginScanner(path, desc, nil, zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	meta := s.Meta()
+	assert.Equal(t, "plugin:fake-iac", meta.Type)
+	assert.Equal(t, "1.0.0", meta.Version)
+	assert.Equal(t, []domain.ScanType{domain.ScanTypeSecuritySpecCheck}, meta.SupportedTypes)
+	assert.Equal(t, 128, meta.ResourceProfile.MemoryMB)
+
+	task := &domain.ScanTaskPayload{TaskID: "task-ok", AssetID: "1", AssetType: domain.AssetTypeRepository, ScanType: domain.ScanTypeSecuritySpecCheck}
+	result, err := s.SyncExecute(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, "fake-iac", result.Result["plugin"])
+	assert.EqualValues(t, 1, result.Result["files"], "last event without trailing newline must be parsed")
+
+	findings := result.Findings()
+	require.Len(t, findings, 1)
+	assert.Equal(t, "IAC-001", findings[0].RuleID)
+	assert.Equal(t, domain.SeverityHigh, findings[0].Severity)
+	assert.Equal(t, "public-bucket", findings[0].RuleName)
+	assert.Equal(t, domain.FindingLocation{Path: "main.tf", StartLine: 3}, findings[0].Location)
+	assert.Equal(t, "fake-iac", result.SARIF.Runs[0].Tool.Driver.Name)
+
+	status, err := s.GetStatus(task.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TaskStatusCompleted, status)
+
+	// 插件输出 error 事件时扫描失败
+	result, err = s.Scan(context.Background(), &domain.ScanTaskPayload{TaskID: "task-fail", ScanType: domain.ScanTypeSecuritySpecCheck})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parser crashed")
+	assert.Equal(t, "failed", result.Status)
+}
+
+func TestPluginDirectory_RejectsWritable(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFakePlugin(t, dir, "1.0.0")
+	source := NewPluginDirectory(nil, zap.NewNop(), newPluginTestConfig(t, dir))
+
+	executors, err := source.Discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, executors, 1)
+
+	// 其他用户可写的插件被跳过，已注册的执行器随之下线
+	require.NoError(t, os.Chmod(path, 0o757))
+	executors, err = source.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, executors)
+
+	// 组可写的插件目录整体拒绝
+	require.NoError(t, os.Chmod(path, 0o755))
+	require.NoError(t, os.Chmod(dir, 0o775))
+	_, err = source.Discover(context.Background())
+	assert.ErrorContains(t, err, "plugin dir rejected")
+}
+
+func TestParseDescriptor_Rejects(t *testing.T) {
+	_, err := parseDescriptor([]byte(`{"name":"x","protocol_version":2,"supported_types":["SCA"]}`))
+	assert.Error(t, err)
+	_, err = parseDescriptor([]byte(`{"name":"x","protocol_version":1,"supported_types":["Nope"]}`))
+	assert.Error(t, err)
+	_, err = parseDescriptor([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestScannerFactory_SyncPlugins(t *testing.T) {
+	if logger.Logger == nil {
+		logger.Logger = zap.NewNop()
+	}
+	dir := t.TempDir()
+	cfg := newPluginTestConfig(t, dir)
+	builtin := NewSCAScanner(nil, zap.NewNop(), cfg)
+	factory := scanner.NewScannerFactory(func() map[domain.ScanType]scanner.TaskExecutor {
+		return map[domain.ScanType]scanner.TaskExecutor{domain.ScanTypeSca: builtin}
+	}, nil, nil)
+	source := NewPluginDirectory(nil, zap.NewNop(), cfg)
+
+	// 插件目录为空
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Len(t, factory.GetAllScanners(), 1)
+
+	// 新插件被发现并热注册
+	writeFakePlugin(t, dir, "1.0.0")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644))
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	first, ok := factory.GetAllScanners()[domain.ScanTypeSecuritySpecCheck]
+	require.True(t, ok)
+	assert.Equal(t, "1.0.0", first.Meta().Version)
+
+	// 未变化时复用同一执行器
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Same(t, first, factory.GetAllScanners()[domain.ScanTypeSecuritySpecCheck])
+
+	// 插件被替换后重新握手并注册新版本
+	path := writeFakePlugin(t, dir, "1.1.0")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, future, future))
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Equal(t, "1.1.0", factory.GetAllScanners()[domain.ScanTypeSecuritySpecCheck].Meta().Version)
+
+	// 插件不能覆盖内置扫描器
+	sca := []byte(`#!/bin/sh
+echo '{"name":"fake-sca","version":"1","protocol_version":1,"supported_types":["SCA"]}'
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fake-sca"), sca, 0o755))
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Same(t, builtin, factory.GetAllScanners()[domain.ScanTypeSca])
+
+	// 插件删除后注销
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	_, ok = factory.GetAllScanners()[domain.ScanTypeSecuritySpecCheck]
+	assert.False(t, ok)
+	assert.Same(t, builtin, factory.GetAllScanners()[domain.ScanTypeSca])
+}
+
+// stubPlugin 只实现同步插件所需的方法，记录被关闭的次数
+type stubPlugin struct {
+	scanner.TaskExecutor
+	meta   scanner.ExecutorMeta
+	closed int
+}
+
+func (p *stubPlugin) Meta() scanner.ExecutorMeta { return p.meta }
+func (p *stubPlugin) HealthCheck() error         { return nil }
+func (p *stubPlugin) Close() error {
+	p.closed++
+	return nil
+}
+
+type stubPluginSource []scanner.TaskExecutor
+
+func (s *stubPluginSource) Discover(context.Context) ([]scanner.TaskExecutor, error) {
+	return *s, nil
+}
+
+func TestScannerFactory_SyncPluginsClosesRetired(t *testing.T) {
+	if logger.Logger == nil {
+		logger.Logger = zap.NewNop()
+	}
+	factory := scanner.NewScannerFactory(func() map[domain.ScanType]scanner.TaskExecutor {
+		return map[domain.ScanType]scanner.TaskExecutor{}
+	}, nil, nil)
+	v1 := &stubPlugin{meta: scanner.ExecutorMeta{Type: "iac", Version: "1", SupportedTypes: []domain.ScanType{
+		domain.ScanTypeSecuritySpecCheck, domain.ScanTypeComplianceAudit,
+	}}}
+	v2 := &stubPlugin{meta: scanner.ExecutorMeta{Type: "iac", Version: "2", SupportedTypes: []domain.ScanType{
+		domain.ScanTypeSecuritySpecCheck,
+	}}}
+
+	source := &stubPluginSource{v1}
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Same(t, v1, factory.GetAllScanners()[domain.ScanTypeComplianceAudit])
+
+	// 被替换的扫描类型改由新插件处理，旧插件仍服务其它扫描类型，不关闭
+	*source = stubPluginSource{v2, v1}
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Same(t, v2, factory.GetAllScanners()[domain.ScanTypeSecuritySpecCheck])
+	assert.Zero(t, v1.closed)
+
+	*source = stubPluginSource{v2}
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Equal(t, 1, v1.closed)
+	assert.Zero(t, v2.closed)
+
+	*source = nil
+	require.NoError(t, factory.SyncPlugins(context.Background(), source))
+	assert.Empty(t, factory.GetAllScanners())
+	assert.Equal(t, 1, v1.closed)
+	assert.Equal(t, 1, v2.closed)
+}
@@ -0,0 +1,485 @@
+package scanner_impl
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"go.uber.org/zap"
+)
+
+// maxBannerSize 横幅读取上限
+const maxBannerSize = 512
+
+// PortScanTarget 端口扫描目标，Host 为展示名称（域名或IP），IP 为实际连接地址
+type PortScanTarget struct {
+	Host string
+	IP   netip.Addr
+}
+
+// TLSCertSummary TLS 证书摘要
+type TLSCertSummary struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	DNSNames           []string  `json:"dns_names,omitempty"`
+	SerialNumber       string    `json:"serial_number"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	Expired            bool      `json:"expired"`
+	SelfSigned         bool      `json:"self_signed"`
+	Fingerprint        string    `json:"fingerprint_sha256"`
+	TLSVersion         string    `json:"tls_version"`
+	CipherSuite        string    `json:"cipher_suite"`
+}
+
+// OpenPort 开放端口信息
+type OpenPort struct {
+	Port     int             `json:"port"`
+	Protocol string          `json:"protocol"`
+	Service  string          `json:"service"`
+	Banner   string          `json:"banner,omitempty"`
+	TLS      *TLSCertSummary `json:"tls,omitempty"`
+}
+
+// HostResult 单个主机的扫描结果
+type HostResult struct {
+	Host      string     `json:"host"`
+	IP        string     `json:"ip"`
+	OpenPorts []OpenPort `json:"open_ports"`
+	Closed    int        `json:"closed"`
+	Filtered  int        `json:"filtered"` // 连接超时，通常被防火墙丢弃
+}
+
+// ParsePorts 解析端口范围表达式（如 "22,80,443,8000-8100"），返回去重排序后的端口列表
+func ParsePorts(spec string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			lo, hi = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		end, err := strconv.Atoi(hi)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		if start < 1 || end > 65535 || start > end {
+			return nil, fmt.Errorf("port range out of bounds: %q", part)
+		}
+		for p := start; p <= end; p++ {
+			seen[p] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, errors.New("empty port list")
+	}
+
+	ports := make([]int, 0, len(seen))
+	for p := range seen {
+		ports = append(ports, p)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// ExpandHosts 将 IP 或 CIDR 展开为主机地址列表，IPv4 网段跳过网络地址与广播地址
+func ExpandHosts(spec string, maxHosts int) ([]netip.Addr, error) {
+	if !strings.Contains(spec, "/") {
+		addr, err := netip.ParseAddr(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip address %q", spec)
+		}
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+
+	prefix, err := netip.ParsePrefix(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr %q", spec)
+	}
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits >= 31 || 1<<hostBits > maxHosts+2 {
+		return nil, fmt.Errorf("cidr %s exceeds max hosts %d", spec, maxHosts)
+	}
+
+	var hosts []netip.Addr
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		hosts = append(hosts, addr)
+	}
+	if prefix.Addr().Is4() && hostBits >= 2 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	if len(hosts) > maxHosts {
+		return nil, fmt.Errorf("cidr %s exceeds max hosts %d", spec, maxHosts)
+	}
+	return hosts, nil
+}
+
+// rateLimiter 简单的令牌间隔限速器，perSecond <= 0 时不限速
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{ticker: time.NewTicker(time.Second / time.Duration(perSecond))}
+}
+
+// Wait 阻塞直到获得下一次连接许可
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-l.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *rateLimiter) Stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
+
+// PortScanEngine TCP connect 端口扫描引擎
+type PortScanEngine struct {
+	cfg     config.PortScanConfig
+	limiter *rateLimiter
+	logger  *zap.Logger
+}
+
+// NewPortScanEngine 创建端口扫描引擎，调用方需在结束后调用 Close 释放限速器
+func NewPortScanEngine(cfg config.PortScanConfig, logger *zap.Logger) *PortScanEngine {
+	return &PortScanEngine{
+		cfg:     cfg,
+		limiter: newRateLimiter(cfg.RateLimit),
+		logger:  logger,
+	}
+}
+
+// Close 释放引擎资源
+func (e *PortScanEngine) Close() {
+	e.limiter.Stop()
+}
+
+// Run 扫描全部目标，主机间并发数受 MaxParallelHosts 限制，主机内并发数受 HostConcurrency 限制
+func (e *PortScanEngine) Run(ctx context.Context, targets []PortScanTarget, ports []int) ([]HostResult, error) {
+	results := make([]HostResult, len(targets))
+	sem := make(chan struct{}, e.cfg.MaxParallelHosts)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return results, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int, target PortScanTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.scanHost(ctx, target, ports)
+		}(i, target)
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+// scanHost 对单个主机执行端口探测
+func (e *PortScanEngine) scanHost(ctx context.Context, target PortScanTarget, ports []int) HostResult {
+	result := HostResult{Host: target.Host, IP: target.IP.String(), OpenPorts: []OpenPort{}}
+
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := e.cfg.HostConcurrency
+	if workers > len(ports) {
+		workers = len(ports)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range jobs {
+				open, state := e.probePort(ctx, target, port)
+				mu.Lock()
+				switch {
+				case open != nil:
+					result.OpenPorts = append(result.OpenPorts, *open)
+				case state == portFiltered:
+					result.Filtered++
+				case state == portClosed:
+					result.Closed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, port := range ports {
+		if err := e.limiter.Wait(ctx); err != nil {
+			break
+		}
+		select {
+		case jobs <- port:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(result.OpenPorts, func(i, j int) bool { return result.OpenPorts[i].Port < result.OpenPorts[j].Port })
+	e.logger.Debug("host scanned",
+		zap.String("host", target.Host),
+		zap.String("ip", result.IP),
+		zap.Int("open", len(result.OpenPorts)),
+		zap.Int("filtered", result.Filtered))
+	return result
+}
+
+type portState int
+
+const (
+	portClosed portState = iota
+	portFiltered
+	portOpen
+	portSkipped // 上下文取消，未完成探测
+)
+
+// probePort 建立 TCP 连接判断端口状态，开放时按配置读取横幅并尝试 TLS 握手
+func (e *PortScanEngine) probePort(ctx context.Context, target PortScanTarget, port int) (*OpenPort, portState) {
+	addr := net.JoinHostPort(target.IP.String(), strconv.Itoa(port))
+	dialer := net.Dialer{Timeout: e.cfg.ConnectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return nil, portSkipped
+		case errors.Is(err, os.ErrDeadlineExceeded) || isTimeout(err):
+			return nil, portFiltered
+		default:
+			return nil, portClosed
+		}
+	}
+
+	open := &OpenPort{Port: port, Protocol: "tcp"}
+	if e.cfg.BannerGrab {
+		open.Banner = readBanner(conn, e.cfg.BannerTimeout)
+	}
+	_ = conn.Close()
+
+	// 未主动发送横幅的服务可能是 TLS 或 HTTP
+	if open.Banner == "" && e.cfg.TLSProbe {
+		open.TLS, open.Banner = e.probeTLS(ctx, addr, target.Host)
+	}
+	if open.Banner == "" && open.TLS == nil && e.cfg.BannerGrab {
+		open.Banner = e.probeHTTP(ctx, addr, target.Host)
+	}
+	open.Service = identifyService(port, open.Banner, open.TLS != nil)
+	return open, portOpen
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// readBanner 在超时时间内读取服务端主动发送的数据
+func readBanner(conn net.Conn, timeout time.Duration) string {
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, maxBannerSize)
+	n, _ := conn.Read(buf)
+	return sanitizeBanner(buf[:n])
+}
+
+// probeTLS 尝试 TLS 握手并提取证书摘要，成功时顺带发送 HTTP 请求获取横幅
+func (e *PortScanEngine) probeTLS(ctx context.Context, addr, serverName string) (*TLSCertSummary, string) {
+	if _, err := netip.ParseAddr(serverName); err == nil {
+		serverName = ""
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: e.cfg.ConnectTimeout},
+		Config: &tls.Config{
+			ServerName: serverName,
+			// 只采集证书信息，不校验证书链
+			InsecureSkipVerify: true, // #nosec G402
+		},
+	}
+	hsCtx, cancel := context.WithTimeout(ctx, e.cfg.ConnectTimeout+e.cfg.BannerTimeout)
+	defer cancel()
+	conn, err := dialer.DialContext(hsCtx, "tcp", addr)
+	if err != nil {
+		return nil, ""
+	}
+	defer conn.Close()
+
+	tlsConn := conn.(*tls.Conn)
+	summary := summarizeTLS(tlsConn.ConnectionState())
+	var banner string
+	if e.cfg.BannerGrab {
+		banner = httpHead(tlsConn, serverName, e.cfg.BannerTimeout)
+	}
+	return summary, banner
+}
+
+// probeHTTP 发送 HTTP HEAD 请求，返回状态行与 Server 头
+func (e *PortScanEngine) probeHTTP(ctx context.Context, addr, host string) string {
+	dialer := net.Dialer{Timeout: e.cfg.ConnectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	return httpHead(conn, host, e.cfg.BannerTimeout)
+}
+
+func httpHead(conn net.Conn, host string, timeout time.Duration) string {
+	if host == "" {
+		host = conn.RemoteAddr().String()
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := fmt.Fprintf(conn, "HEAD / HTTP/1.0\r\nHost: %s\r\nUser-Agent: go-sac-portscan/1.0\r\n\r\n", host); err != nil {
+		return ""
+	}
+
+	reader := bufio.NewReaderSize(conn, maxBannerSize)
+	status, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(status, "HTTP/") {
+		return ""
+	}
+	banner := strings.TrimSpace(status)
+	for i := 0; i < 50; i++ {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil || line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Server") {
+			banner += " | Server: " + strings.TrimSpace(value)
+			break
+		}
+	}
+	return sanitizeBanner([]byte(banner))
+}
+
+// summarizeTLS 提取叶子证书摘要
+func summarizeTLS(state tls.ConnectionState) *TLSCertSummary {
+	summary := &TLSCertSummary{
+		TLSVersion:  tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	if len(state.PeerCertificates) == 0 {
+		return summary
+	}
+	cert := state.PeerCertificates[0]
+	fingerprint := sha256.Sum256(cert.Raw)
+	summary.Subject = cert.Subject.String()
+	summary.Issuer = cert.Issuer.String()
+	summary.DNSNames = cert.DNSNames
+	summary.SerialNumber = cert.SerialNumber.String()
+	summary.SignatureAlgorithm = cert.SignatureAlgorithm.String()
+	summary.NotBefore = cert.NotBefore
+	summary.NotAfter = cert.NotAfter
+	summary.Expired = time.Now().After(cert.NotAfter)
+	summary.SelfSigned = cert.Subject.String() == cert.Issuer.String() && cert.CheckSignatureFrom(cert) == nil
+	summary.Fingerprint = hex.EncodeToString(fingerprint[:])
+	return summary
+}
+
+// sanitizeBanner 将不可打印字符替换为 '.'，并截断为单行摘要
+func sanitizeBanner(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		switch {
+		case c == '\r' || c == '\n' || c == '\t':
+			b.WriteByte(' ')
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('.')
+		}
+	}
+	return truncate(strings.Join(strings.Fields(b.String()), " "), 256)
+}
+
+// wellKnownPorts 常用端口与服务名的对应关系，横幅无法识别时使用
+var wellKnownPorts = map[int]string{
+	21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp", 53: "dns", 80: "http", 110: "pop3",
+	111: "rpcbind", 135: "msrpc", 139: "netbios-ssn", 143: "imap", 389: "ldap", 443: "https",
+	445: "microsoft-ds", 465: "smtps", 587: "submission", 636: "ldaps", 993: "imaps", 995: "pop3s",
+	1433: "mssql", 1521: "oracle", 2049: "nfs", 2375: "docker", 2376: "docker-tls", 3306: "mysql",
+	3389: "rdp", 5432: "postgresql", 5672: "amqp", 5900: "vnc", 5984: "couchdb", 6379: "redis",
+	6443: "kubernetes-api", 8080: "http-proxy", 8443: "https-alt", 9000: "http-alt", 9092: "kafka",
+	9200: "elasticsearch", 11211: "memcached", 15672: "rabbitmq-management", 27017: "mongodb",
+}
+
+// identifyService 根据横幅特征识别服务，无法识别时回退到常用端口表
+func identifyService(port int, banner string, isTLS bool) string {
+	lower := strings.ToLower(banner)
+	var service string
+	switch {
+	case strings.HasPrefix(banner, "SSH-"):
+		service = "ssh"
+	case strings.HasPrefix(banner, "HTTP/"):
+		service = "http"
+	case strings.HasPrefix(banner, "220") && strings.Contains(lower, "ftp"):
+		service = "ftp"
+	case strings.HasPrefix(banner, "220") && strings.Contains(lower, "smtp"):
+		service = "smtp"
+	case strings.HasPrefix(banner, "+OK"):
+		service = "pop3"
+	case strings.HasPrefix(banner, "* OK"):
+		service = "imap"
+	case strings.HasPrefix(banner, "RFB "):
+		service = "vnc"
+	case strings.HasPrefix(banner, "-ERR") || strings.HasPrefix(banner, "-NOAUTH") || strings.HasPrefix(banner, "-DENIED"):
+		service = "redis"
+	case strings.Contains(lower, "mysql") || strings.Contains(lower, "mariadb"):
+		service = "mysql"
+	default:
+		// 端口表中的名称已区分明文与 TLS 版本（如 imap/imaps），无需再追加后缀
+		if service = wellKnownPorts[port]; service == "" {
+			service = "unknown"
+		}
+		if isTLS && service == "unknown" {
+			return "tls"
+		}
+		return service
+	}
+
+	if isTLS {
+		if service == "http" {
+			return "https"
+		}
+		return service + "/tls"
+	}
+	return service
+}
@@ -0,0 +1,287 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// 端口扫描任务选项中约定的目标描述字段
+const (
+	OptionIPAddress = "ip_address" // IP 资产（IPAsset.IPAddress），支持逗号分隔的 IP 或 CIDR
+	OptionPorts     = "ports"      // 任务级覆盖的端口范围
+)
+
+// PortScanner 端口扫描器
+type PortScanner struct {
+	*BaseScanner
+	probe    config.PortScanConfig
+	resolver *net.Resolver
+}
+
+// NewPortScanner 创建端口扫描器
+func NewPortScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &PortScanner{resolver: net.DefaultResolver}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypePortScanning)
+	s.probe = config.GetPortScanConfig()
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypePortScanning,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *PortScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypePortScanning, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	var opts domain.PortScanOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+
+	spec := s.probe.Ports
+	if override := strings.TrimSpace(opts.Ports); override != "" {
+		spec = override
+	}
+	ports, err := ParsePorts(spec)
+	if err != nil {
+		return fail(err)
+	}
+	targets, err := s.resolveTargets(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+
+	s.logger.Info("starting port scan",
+		zap.String("task_id", task.TaskID),
+		zap.Int("hosts", len(targets)),
+		zap.Int("ports", len(ports)),
+		zap.Int("rate_limit", s.probe.RateLimit))
+
+	// 使用超时控制执行扫描，超时按探测的端口数放宽
+	s.SetTargetSize(task, scanner.TargetSize{Items: len(ports) * len(targets)})
+	var hosts []HostResult
+	err = s.ExecuteWithTimeout(ctx, task, func(ctx context.Context) error {
+		engine := NewPortScanEngine(s.probe, s.logger)
+		defer engine.Close()
+		var runErr error
+		hosts, runErr = engine.Run(ctx, targets, ports)
+		return runErr
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	result.SetSuccess(map[string]interface{}{
+		"hosts":         hosts,
+		"ports_scanned": len(ports) * len(targets),
+		"summary":       summarizePortScan(hosts),
+	})
+	result.SetFindings(s.findingReport(portScanFindings(hosts)))
+	return result, nil
+}
+
+// resolveTargets 从任务选项解析扫描目标，域名解析为全部地址并保留域名用于 TLS SNI
+func (s *PortScanner) resolveTargets(ctx context.Context, opts domain.PortScanOptions) ([]PortScanTarget, error) {
+	var targets []PortScanTarget
+	seen := make(map[netip.Addr]bool)
+	add := func(host string, addr netip.Addr) {
+		if !seen[addr] {
+			seen[addr] = true
+			targets = append(targets, PortScanTarget{Host: host, IP: addr})
+		}
+	}
+
+	for _, spec := range strings.Split(opts.IPAddress, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		addrs, err := ExpandHosts(spec, s.probe.MaxHosts)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			add(addr.String(), addr)
+		}
+	}
+
+	if name := strings.TrimSuffix(strings.TrimSpace(opts.DomainName), "."); name != "" {
+		addrs, err := s.resolver.LookupNetIP(ctx, "ip", name)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s failed: %w", name, err)
+		}
+		for _, addr := range addrs {
+			add(name, addr.Unmap())
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("missing %s or %s in task options", OptionIPAddress, OptionDomainName)
+	}
+	if len(targets) > s.probe.MaxHosts {
+		return nil, fmt.Errorf("%d targets exceed max hosts %d", len(targets), s.probe.MaxHosts)
+	}
+	return targets, nil
+}
+
+// summarizePortScan 统计开放端口、服务分布及证书问题
+func summarizePortScan(hosts []HostResult) map[string]interface{} {
+	byService := make(map[string]int)
+	openPorts, filtered, liveHosts, expiredCerts, selfSigned := 0, 0, 0, 0, 0
+	for _, h := range hosts {
+		if len(h.OpenPorts) > 0 {
+			liveHosts++
+		}
+		filtered += h.Filtered
+		for _, p := range h.OpenPorts {
+			openPorts++
+			byService[p.Service]++
+			if p.TLS != nil && p.TLS.Expired {
+				expiredCerts++
+			}
+			if p.TLS != nil && p.TLS.SelfSigned {
+				selfSigned++
+			}
+		}
+	}
+	return map[string]interface{}{
+		"hosts":             len(hosts),
+		"hosts_with_open":   liveHosts,
+		"open_ports":        openPorts,
+		"filtered_ports":    filtered,
+		"by_service":        byService,
+		"expired_certs":     expiredCerts,
+		"self_signed_certs": selfSigned,
+	}
+}
+
+// portScanFindings 开放端口记为提示级发现项，过期与自签名证书单独记为发现项
+func portScanFindings(hosts []HostResult) []domain.Finding {
+	var out []domain.Finding
+	for _, h := range hosts {
+		for _, p := range h.OpenPorts {
+			endpoint := net.JoinHostPort(h.IP, strconv.Itoa(p.Port))
+			location := domain.FindingLocation{Logical: h.Host + "/" + p.Protocol + "/" + strconv.Itoa(p.Port), LogicalKind: "endpoint"}
+			props := map[string]interface{}{
+				"host":     h.Host,
+				"ip":       h.IP,
+				"port":     p.Port,
+				"protocol": p.Protocol,
+				"service":  p.Service,
+			}
+			if p.Banner != "" {
+				props["banner"] = p.Banner
+			}
+			out = append(out, domain.Finding{
+				RuleID:     "open-port",
+				RuleName:   "Open port",
+				Severity:   domain.SeverityInfo,
+				Message:    fmt.Sprintf("%s is open (%s)", endpoint, firstNonEmpty(p.Service, "unknown")),
+				Category:   "exposure",
+				Location:   location,
+				Properties: props,
+			})
+			if p.TLS == nil {
+				continue
+			}
+			certProps := map[string]interface{}{
+				"subject":            p.TLS.Subject,
+				"issuer":             p.TLS.Issuer,
+				"not_after":          p.TLS.NotAfter,
+				"fingerprint_sha256": p.TLS.Fingerprint,
+			}
+			if p.TLS.Expired {
+				out = append(out, domain.Finding{
+					RuleID:      "tls-expired-certificate",
+					RuleName:    "Expired TLS certificate",
+					Severity:    domain.SeverityHigh,
+					Message:     fmt.Sprintf("certificate served on %s expired at %s", endpoint, p.TLS.NotAfter.Format(time.RFC3339)),
+					Category:    "tls",
+					CWEID:       "CWE-298",
+					Location:    location,
+					Remediation: "Renew the certificate",
+					Properties:  certProps,
+				})
+			}
+			if p.TLS.SelfSigned {
+				out = append(out, domain.Finding{
+					RuleID:      "tls-self-signed-certificate",
+					RuleName:    "Self-signed TLS certificate",
+					Severity:    domain.SeverityMedium,
+					Message:     fmt.Sprintf("certificate served on %s is self-signed (%s)", endpoint, p.TLS.Subject),
+					Category:    "tls",
+					CWEID:       "CWE-295",
+					Location:    location,
+					Remediation: "Use a certificate issued by a trusted CA",
+					Properties:  certProps,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *PortScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *PortScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *PortScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *PortScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *PortScanner) HealthCheck() error {
+	if _, err := ParsePorts(s.probe.Ports); err != nil {
+		return err
+	}
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,201 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestPortScanner(rateLimit int) *PortScanner {
+	cfg := &config.Config{}
+	cfg.Scanner.PortScan.Timeout = 30 * time.Second
+	cfg.Scanner.PortScan.Probe = config.PortScanConfig{
+		HostConcurrency: 4,
+		RateLimit:       rateLimit,
+		ConnectTimeout:  time.Second,
+		BannerGrab:      true,
+		BannerTimeout:   200 * time.Millisecond,
+		TLSProbe:        true,
+	}
+	return NewPortScanner(nil, zap.NewNop(), cfg).(*PortScanner)
+}
+
+// listenBanner 启动发送固定横幅的 TCP 服务
+func listenBanner(t *testing.T, banner string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(banner))
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort 返回一个当前未监听的本地端口
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+func serverPort(t *testing.T, srv *httptest.Server) int {
+	t.Helper()
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPortScanner_LocalListeners(t *testing.T) {
+	sshPort := listenBanner(t, "SSH-2.0-OpenSSH_9.6\r\n")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+	})
+	httpSrv := httptest.NewServer(handler)
+	t.Cleanup(httpSrv.Close)
+	tlsSrv := httptest.NewTLSServer(handler)
+	t.Cleanup(tlsSrv.Close)
+	closed := closedPort(t)
+
+	ports := []int{sshPort, serverPort(t, httpSrv), serverPort(t, tlsSrv), closed}
+	specs := make([]string, 0, len(ports))
+	for _, p := range ports {
+		specs = append(specs, strconv.Itoa(p))
+	}
+
+	s := newTestPortScanner(0)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-ports",
+		AssetType: domain.AssetTypeIP,
+		Options: map[string]interface{}{
+			OptionIPAddress: "127.0.0.1",
+			OptionPorts:     strings.Join(specs, ","),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, 4, result.Result["ports_scanned"])
+
+	hosts := result.Result["hosts"].([]HostResult)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "127.0.0.1", hosts[0].IP)
+	assert.Equal(t, 1, hosts[0].Closed)
+
+	open := make(map[int]OpenPort)
+	for _, p := range hosts[0].OpenPorts {
+		open[p.Port] = p
+	}
+	require.Len(t, open, 3)
+
+	assert.Equal(t, "ssh", open[sshPort].Service)
+	assert.Equal(t, "SSH-2.0-OpenSSH_9.6", open[sshPort].Banner)
+	assert.Nil(t, open[sshPort].TLS)
+
+	plain := open[serverPort(t, httpSrv)]
+	assert.Equal(t, "http", plain.Service)
+	assert.Contains(t, plain.Banner, "Server: nginx/1.25.3")
+	assert.Nil(t, plain.TLS)
+
+	secure := open[serverPort(t, tlsSrv)]
+	assert.Equal(t, "https", secure.Service)
+	require.NotNil(t, secure.TLS)
+	assert.Contains(t, secure.TLS.Subject, "Acme Co")
+	assert.True(t, secure.TLS.SelfSigned)
+	assert.False(t, secure.TLS.Expired)
+	assert.Len(t, secure.TLS.Fingerprint, 64)
+	assert.NotEmpty(t, secure.TLS.TLSVersion)
+
+	summary := result.Result["summary"].(map[string]interface{})
+	assert.Equal(t, 3, summary["open_ports"])
+	assert.Equal(t, 1, summary["self_signed_certs"])
+}
+
+func TestPortScanEngine_RateLimit(t *testing.T) {
+	ports := make([]int, 0, 10)
+	for i := 0; i < 10; i++ {
+		ports = append(ports, closedPort(t))
+	}
+
+	cfg := newTestPortScanner(50).probe
+	engine := NewPortScanEngine(cfg, zap.NewNop())
+	defer engine.Close()
+
+	start := time.Now()
+	hosts, err := engine.Run(context.Background(), []PortScanTarget{{Host: "localhost", IP: netip.MustParseAddr("127.0.0.1")}}, ports)
+	require.NoError(t, err)
+	// 50 次/秒的限速下 10 次连接至少需要 9 个间隔
+	assert.GreaterOrEqual(t, time.Since(start), 170*time.Millisecond)
+	assert.Empty(t, hosts[0].OpenPorts)
+}
+
+func TestPortScanner_MissingTarget(t *testing.T) {
+	s := newTestPortScanner(0)
+	_, err := s.Scan(context.Background(), &domain.ScanTaskPayload{TaskID: "task-empty"})
+	assert.Error(t, err)
+
+	_, err = s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-too-large",
+		Options: map[string]interface{}{OptionIPAddress: "10.0.0.0/16"},
+	})
+	assert.ErrorContains(t, err, "exceeds max hosts")
+}
+
+func TestParsePorts(t *testing.T) {
+	ports, err := ParsePorts("443, 80,8000-8003,80")
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443, 8000, 8001, 8002, 8003}, ports)
+
+	for _, bad := range []string{"", "0", "65536", "90-80", "http"} {
+		_, err := ParsePorts(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestExpandHosts(t *testing.T) {
+	hosts, err := ExpandHosts("192.168.1.0/30", 256)
+	require.NoError(t, err)
+	assert.Equal(t, "[192.168.1.1 192.168.1.2]", fmt.Sprint(hosts))
+
+	hosts, err = ExpandHosts("::ffff:10.0.0.1", 256)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", hosts[0].String())
+
+	_, err = ExpandHosts("10.0.0.0/23", 256)
+	assert.Error(t, err)
+	_, err = ExpandHosts("not-an-ip", 256)
+	assert.Error(t, err)
+}
+
+func TestIdentifyService(t *testing.T) {
+	assert.Equal(t, "ssh", identifyService(2222, "SSH-2.0-dropbear", false))
+	assert.Equal(t, "https", identifyService(8443, "HTTP/1.1 200 OK", true))
+	assert.Equal(t, "smtp/tls", identifyService(465, "220 mail.example.com ESMTP Postfix", true))
+	assert.Equal(t, "imaps", identifyService(993, "", true))
+	assert.Equal(t, "tls", identifyService(40000, "", true))
+	assert.Equal(t, "redis", identifyService(6379, "", false))
+}
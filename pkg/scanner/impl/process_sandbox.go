@@ -0,0 +1,188 @@
+package scanner_impl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// ErrSecurityProfileUnsatisfied 严格模式下 security_profile 无法满足，拒绝启动扫描进程
+var ErrSecurityProfileUnsatisfied = errors.New("security profile cannot be honored")
+
+// errSandboxUnsupported 当前平台不支持的隔离能力
+var errSandboxUnsupported = errors.New("not supported on this platform")
+
+// setProcessAttributes 按 SecurityProfile 设置子进程属性：独立进程组、降权、精简环境变量与独立临时目录
+// 返回的 cleanup 在进程结束后删除临时目录；严格模式下配置无法满足时返回错误
+func (s *BaseScanner) setProcessAttributes(cmd *exec.Cmd, execID string) (func(), error) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// 独立进程组，超时或取消时按进程组整体终止
+	cmd.SysProcAttr.Setpgid = true
+	cmd.SysProcAttr.Pgid = 0
+
+	cred, err := s.processCredential()
+	if err != nil {
+		if err := s.sandboxViolation("run_as_user", err); err != nil {
+			return nil, err
+		}
+	}
+	cmd.SysProcAttr.Credential = cred
+
+	if s.securityProfile.NoNewPrivs && !noNewPrivsSupported {
+		if err := s.sandboxViolation("no_new_privs", errSandboxUnsupported); err != nil {
+			return nil, err
+		}
+	}
+
+	// 独立临时目录作为 HOME/TMPDIR，避免工具读写服务用户的缓存与配置
+	root := s.sandbox.WorkDir
+	if root == "" {
+		root = os.TempDir()
+	}
+	if err := os.MkdirAll(root, 0o711); err != nil {
+		return nil, fmt.Errorf("create exec dir root failed: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(root, "exec-"+sanitizePathComponent(execID)+"-")
+	if err != nil {
+		return nil, fmt.Errorf("create exec dir failed: %w", err)
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			s.logger.Warn("remove exec dir failed", zap.String("dir", tmpDir), zap.Error(err))
+		}
+	}
+	if cred != nil {
+		if err := os.Chown(tmpDir, int(cred.Uid), int(cred.Gid)); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("chown exec dir failed: %w", err)
+		}
+	}
+
+	cmd.Env = scrubEnv(cmd.Env, s.sandbox.EnvAllowlist, "HOME="+tmpDir, "TMPDIR="+tmpDir)
+	if cmd.Dir == "" {
+		cmd.Dir = tmpDir
+	}
+	return cleanup, nil
+}
+
+// processCredential 返回子进程需要切换到的用户，无需切换时返回 nil
+// RunAsUser 为 0 视为未配置；非 root 运行且与目标用户不一致时返回错误
+func (s *BaseScanner) processCredential() (*syscall.Credential, error) {
+	if s.securityProfile.RunAsUser == nil || *s.securityProfile.RunAsUser <= 0 {
+		return nil, nil
+	}
+	uid := *s.securityProfile.RunAsUser
+	gid := uid
+	if s.securityProfile.RunAsGroup != nil && *s.securityProfile.RunAsGroup > 0 {
+		gid = *s.securityProfile.RunAsGroup
+	}
+	if os.Geteuid() == uid && os.Getegid() == gid {
+		return nil, nil
+	}
+	if os.Geteuid() != 0 {
+		return nil, fmt.Errorf("switching to uid %d gid %d requires root, service runs as uid %d", uid, gid, os.Geteuid())
+	}
+	// 清空附加组，避免继承服务进程的组权限
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: []uint32{}}, nil
+}
+
+// sandboxViolation 处理无法满足的隔离要求：严格模式返回错误，否则告警后继续
+func (s *BaseScanner) sandboxViolation(setting string, cause error) error {
+	if s.sandbox.Strict {
+		return fmt.Errorf("%w: %s: %v", ErrSecurityProfileUnsatisfied, setting, cause)
+	}
+	s.logger.Warn("security profile setting not enforced",
+		zap.String("setting", setting),
+		zap.Error(cause))
+	return nil
+}
+
+// ReadOnlyTarget 按配置将扫描目标只读绑定挂载到 workDir 下，返回扫描工具应使用的路径
+// 调用方需在扫描结束后、删除 workDir 之前调用 release
+func (s *BaseScanner) ReadOnlyTarget(target, workDir string) (string, func(), error) {
+	noop := func() {}
+	if !s.sandbox.ReadOnlyTarget {
+		return target, noop, nil
+	}
+	mountPoint := filepath.Join(workDir, "target-ro")
+	if err := os.Mkdir(mountPoint, 0o755); err != nil {
+		return "", noop, fmt.Errorf("create mount point failed: %w", err)
+	}
+	if err := bindReadOnly(target, mountPoint); err != nil {
+		_ = os.Remove(mountPoint)
+		if err := s.sandboxViolation("read_only_target", err); err != nil {
+			return "", noop, err
+		}
+		return target, noop, nil
+	}
+	release := func() {
+		if err := unbind(mountPoint); err != nil {
+			s.logger.Error("unmount read-only target failed", zap.String("mount_point", mountPoint), zap.Error(err))
+			return
+		}
+		_ = os.Remove(mountPoint)
+	}
+	return mountPoint, release, nil
+}
+
+// scrubEnv 只保留白名单中的服务环境变量，调用方显式追加的变量与 overrides 优先
+func scrubEnv(env []string, allowlist []string, overrides ...string) []string {
+	inherited := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		inherited[kv] = true
+	}
+
+	var keys []string
+	values := make(map[string]string)
+	set := func(kv string) {
+		key, _, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return
+		}
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = kv
+	}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if envAllowed(key, allowlist) {
+			set(kv)
+		}
+	}
+	for _, kv := range env {
+		if !inherited[kv] {
+			set(kv)
+		}
+	}
+	for _, kv := range overrides {
+		set(kv)
+	}
+
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, values[key])
+	}
+	return result
+}
+
+func envAllowed(key string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package scanner_impl
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+const (
+	noNewPrivsSupported = true
+	prSetNoNewPrivs     = 38         // PR_SET_NO_NEW_PRIVS
+	cgroup2SuperMagic   = 0x63677270 // CGROUP2_SUPER_MAGIC
+)
+
+// cloneIntoCgroup 打开控制组目录并设置 CLONE_INTO_CGROUP，子进程从创建起即受资源限制；
+// 目录不在 cgroup2 文件系统上时返回 false，由调用方在启动后移入。release 在启动后关闭目录
+func cloneIntoCgroup(cmd *exec.Cmd, cgroup Cgroup) (release func(), ok bool) {
+	dir, err := os.OpenFile(cgroup.Path(), os.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return func() {}, false
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Fstatfs(int(dir.Fd()), &st); err != nil || st.Type != cgroup2SuperMagic {
+		dir.Close()
+		return func() {}, false
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+	return func() { dir.Close() }, true
+}
+
+// startProcess 启动子进程，NoNewPrivs 时在独占线程上设置 PR_SET_NO_NEW_PRIVS 后再 fork
+// 该标志按线程生效且不可撤销，goroutine 退出时保持锁定使运行时销毁该线程，不影响服务其他线程
+func (s *BaseScanner) startProcess(cmd *exec.Cmd) error {
+	if !s.securityProfile.NoNewPrivs {
+		return cmd.Start()
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+			errCh <- fmt.Errorf("set no_new_privs failed: %w", errno)
+			return
+		}
+		errCh <- cmd.Start()
+	}()
+	return <-errCh
+}
+
+// bindReadOnly 将 src 只读绑定挂载到 dst
+func bindReadOnly(src, dst string) error {
+	if err := syscall.Mount(src, dst, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return err
+	}
+	if err := syscall.Mount("", dst, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_REC, ""); err != nil {
+		_ = syscall.Unmount(dst, syscall.MNT_DETACH)
+		return err
+	}
+	return nil
+}
+
+func unbind(dst string) error {
+	return syscall.Unmount(dst, syscall.MNT_DETACH)
+}
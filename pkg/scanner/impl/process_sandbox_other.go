@@ -0,0 +1,27 @@
+//go:build !linux
+
+package scanner_impl
+
+import (
+	"os/exec"
+)
+
+const noNewPrivsSupported = false
+
+// cloneIntoCgroup 非 Linux 平台不支持 CLONE_INTO_CGROUP
+func cloneIntoCgroup(cmd *exec.Cmd, cgroup Cgroup) (release func(), ok bool) {
+	return func() {}, false
+}
+
+// startProcess 非 Linux 平台不支持 PR_SET_NO_NEW_PRIVS，直接启动
+func (s *BaseScanner) startProcess(cmd *exec.Cmd) error {
+	return cmd.Start()
+}
+
+func bindReadOnly(src, dst string) error {
+	return errSandboxUnsupported
+}
+
+func unbind(dst string) error {
+	return errSandboxUnsupported
+}
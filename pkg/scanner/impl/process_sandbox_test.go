@@ -0,0 +1,179 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newSandboxTestScanner(t *testing.T, sandbox config.ProcessSandboxConfig, opts ...BaseScannerOption) *BaseScanner {
+	t.Helper()
+	cfg := &config.Config{}
+	// t.TempDir 及其上级目录为 0700，降权后的子进程需要能够穿越
+	sandbox.WorkDir = t.TempDir()
+	require.NoError(t, os.Chmod(sandbox.WorkDir, 0o711))
+	require.NoError(t, os.Chmod(filepath.Dir(sandbox.WorkDir), 0o711))
+	cfg.Scanner.ProcessSandbox = sandbox
+	opts = append([]BaseScannerOption{
+		WithTimeout(10*time.Second, time.Second),
+		WithCircuitBreaker(cfg),
+	}, opts...)
+	return NewBaseScanner(domain.ScanTypeStaticCodeAnalysis, nil, zap.NewNop(), cfg, opts...)
+}
+
+func TestScrubEnv(t *testing.T) {
+	t.Setenv("GO_SAC_DB_PASSWORD", "secret")
+	t.Setenv("LC_ALL", "C.UTF-8")
+
+	env := scrubEnv(append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "HOME=/caller"),
+		[]string{"PATH", "LC_*"}, "HOME=/sandbox")
+	joined := "\n" + strings.Join(env, "\n") + "\n"
+	assert.NotContains(t, joined, "GO_SAC_DB_PASSWORD")
+	assert.Contains(t, joined, "\nLC_ALL=C.UTF-8\n")
+	assert.Contains(t, joined, "\nGIT_TERMINAL_PROMPT=0\n")
+	assert.Contains(t, joined, "\nHOME=/sandbox\n")
+	assert.NotContains(t, joined, "HOME=/caller")
+}
+
+func TestBaseScanner_ExecuteCommandSandbox(t *testing.T) {
+	if _, err := os.Stat("/proc/self/status"); err != nil {
+		t.Skip("procfs not available")
+	}
+	t.Setenv("GO_SAC_DB_PASSWORD", "secret")
+	bs := newSandboxTestScanner(t, config.ProcessSandboxConfig{EnvAllowlist: []string{"PATH"}},
+		WithSecurityProfile(0, 0, true))
+
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", `echo "home=$HOME tmp=$TMPDIR pwd=$(pwd) secret=$GO_SAC_DB_PASSWORD"
+grep NoNewPrivs /proc/self/status
+echo "pid=$$ pgrp=$(cut -d' ' -f5 /proc/$$/stat)"`)
+	cmd.Stdout = &out
+	require.NoError(t, bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-sandbox"}, cmd, "scan"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	var home, tmp, pwd string
+	for _, field := range strings.Fields(lines[0]) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "home":
+			home = value
+		case "tmp":
+			tmp = value
+		case "pwd":
+			pwd = value
+		case "secret":
+			assert.Empty(t, value)
+		}
+	}
+	assert.Equal(t, bs.sandbox.WorkDir, filepath.Dir(home))
+	assert.True(t, strings.HasPrefix(filepath.Base(home), "exec-task-sandbox-"))
+	assert.Equal(t, home, tmp)
+	assert.Equal(t, home, pwd)
+	assert.NoDirExists(t, home)
+
+	assert.Equal(t, "1", strings.TrimSpace(strings.TrimPrefix(lines[1], "NoNewPrivs:")))
+
+	var pid, pgrp string
+	for _, field := range strings.Fields(lines[2]) {
+		key, value, _ := strings.Cut(field, "=")
+		if key == "pid" {
+			pid = value
+		} else {
+			pgrp = value
+		}
+	}
+	assert.Equal(t, pid, pgrp, "child must lead its own process group")
+
+	// 服务自身线程不受 PR_SET_NO_NEW_PRIVS 影响
+	status, err := os.ReadFile("/proc/self/status")
+	require.NoError(t, err)
+	assert.Contains(t, string(status), "NoNewPrivs:\t0")
+}
+
+func TestBaseScanner_ExecuteCommandCredentials(t *testing.T) {
+	const nobody = 65534
+	if os.Geteuid() != 0 {
+		// 非 root 无法切换用户：严格模式拒绝启动，非严格模式告警后继续
+		bs := newSandboxTestScanner(t, config.ProcessSandboxConfig{Strict: true}, WithSecurityProfile(nobody, nobody, false))
+		err := bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-strict"}, exec.Command("true"), "scan")
+		assert.ErrorIs(t, err, ErrSecurityProfileUnsatisfied)
+
+		bs = newSandboxTestScanner(t, config.ProcessSandboxConfig{}, WithSecurityProfile(nobody, nobody, false))
+		assert.NoError(t, bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-lenient"}, exec.Command("true"), "scan"))
+		return
+	}
+
+	bs := newSandboxTestScanner(t, config.ProcessSandboxConfig{Strict: true}, WithSecurityProfile(nobody, nobody, true))
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", `echo "$(id -u) $(id -g) $(id -G)"; touch "$HOME/written"`)
+	cmd.Stdout = &out
+	require.NoError(t, bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-nobody"}, cmd, "scan"))
+	assert.Equal(t, "65534 65534 65534", strings.TrimSpace(out.String()))
+
+	// 工作目录移交给降权用户
+	base := t.TempDir()
+	require.NoError(t, os.Chmod(base, 0o711))
+	workDir, err := bs.CreateWorkspace(filepath.Join(base, "ws"), &domain.ScanTaskPayload{TaskID: "task-nobody"})
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Dir(workDir))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o751), info.Mode().Perm())
+	cmd = exec.Command("touch", filepath.Join(workDir, "report.out"))
+	require.NoError(t, bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-nobody"}, cmd, "scan"))
+}
+
+func TestBaseScanner_ReadOnlyTarget(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0o644))
+
+	bs := newSandboxTestScanner(t, config.ProcessSandboxConfig{})
+	target, release, err := bs.ReadOnlyTarget(src, t.TempDir())
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, src, target, "disabled by default")
+
+	workDir := t.TempDir()
+	bs = newSandboxTestScanner(t, config.ProcessSandboxConfig{ReadOnlyTarget: true})
+	target, release, err = bs.ReadOnlyTarget(src, workDir)
+	require.NoError(t, err)
+	if target == src {
+		// 无挂载权限时非严格模式回退为原目录，严格模式拒绝
+		bs.sandbox.Strict = true
+		_, _, err = bs.ReadOnlyTarget(src, workDir)
+		assert.ErrorIs(t, err, ErrSecurityProfileUnsatisfied)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(target, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+	assert.Error(t, os.WriteFile(filepath.Join(target, "new.go"), nil, 0o644))
+	release()
+	assert.NoDirExists(t, target)
+	assert.FileExists(t, filepath.Join(src, "main.go"))
+}
+
+func TestExecuteCommand_NoGroupKillAfterWait(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	cfg := &config.Config{}
+	cfg.Scanner.ProcessSandbox.WorkDir = t.TempDir()
+	bs := NewBaseScanner(domain.ScanTypeStaticCodeAnalysis, nil, zap.New(core), cfg, WithTimeout(10*time.Second, time.Second))
+
+	// 进程退出并被回收后 PID 可能已被复用，执行结束时不再向其进程组发送信号
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bs.ExecuteCommand(context.Background(), &domain.ScanTaskPayload{TaskID: "task-reaped"}, exec.Command("true"), "scan"))
+	}
+	assert.Zero(t, logs.FilterMessage("terminating process group").Len())
+}
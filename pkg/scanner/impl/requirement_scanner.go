@@ -0,0 +1,139 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// RequirementAnalysisScanner 风险需求识别，分析需求或设计文档中的安全敏感需求
+type RequirementAnalysisScanner struct {
+	*BaseScanner
+	materials config.SecurityMaterialConfig
+	store     materialStoreCache
+}
+
+// NewRequirementAnalysisScanner 创建风险需求识别扫描器
+func NewRequirementAnalysisScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &RequirementAnalysisScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeRequirementAnalysis)
+	s.materials = config.GetRequirementAnalysisConfig()
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeRequirementAnalysis,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *RequirementAnalysisScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return runRiskAnalysis(ctx, s.BaseScanner, &s.store, s.materials, task, riskAnalysisFields...)
+}
+
+// runRiskAnalysis 风险需求识别与威胁建模共用的文本分析流程
+func runRiskAnalysis(
+	ctx context.Context,
+	base *BaseScanner,
+	cache *materialStoreCache,
+	materials config.SecurityMaterialConfig,
+	task *domain.ScanTaskPayload,
+	keys ...string,
+) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, base.scanType, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+	store, err := cache.get(materials.MaterialDir)
+	if err != nil {
+		return fail(err)
+	}
+	analyzer, err := NewRiskAnalyzer(store, materials)
+	if err != nil {
+		return fail(err)
+	}
+
+	fields := collectTextFields(task.Options, keys...)
+	if len(fields) == 0 {
+		return fail(fmt.Errorf("no text to analyze, expected one of %v in task options", keys))
+	}
+	risks := analyzer.Analyze(fields)
+
+	base.logger.Info("risk analysis finished",
+		zap.String("task_id", task.TaskID),
+		zap.String("scan_type", base.scanType.String()),
+		zap.Int("fields", len(fields)),
+		zap.Int("risks", len(risks)))
+
+	result.SetSuccess(map[string]interface{}{
+		"risks":     risks,
+		"materials": analyzer.Materials(),
+		"summary":   summarizeRisks(risks, len(fields)),
+	})
+	result.SetFindings(base.findingReport(riskFindings(risks)))
+	return result, nil
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *RequirementAnalysisScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *RequirementAnalysisScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *RequirementAnalysisScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *RequirementAnalysisScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口，校验规则包可加载
+func (s *RequirementAnalysisScanner) HealthCheck() error {
+	store, err := s.store.get(s.materials.MaterialDir)
+	if err != nil {
+		return err
+	}
+	if _, err := NewRiskAnalyzer(store, s.materials); err != nil {
+		return err
+	}
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,227 @@
+package scanner_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// 仓库自带的安全物料，测试同时校验其可被加载
+const shippedMaterialDir = "../../../configs/security_materials"
+
+var materialFixtures = map[string]string{
+	"words/v1.yaml": `type: SensitiveWords
+name: words
+version: 1.0.0
+categories:
+  - id: SW-AUTH
+    name: 身份认证
+    severity: high
+    words: [登录, password]
+    requirements: [密码须加盐哈希存储]
+`,
+	"words/v1.1.yaml": `type: sensitivewords
+name: words
+version: 1.1.0
+categories:
+  - id: SW-AUTH
+    name: 身份认证
+    severity: high
+    words: [登录, password, 验证码]
+    requirements: [密码须加盐哈希存储]
+  - id: SW-PAYMENT
+    name: 支付交易
+    severity: critical
+    fields: [business_value]
+    words: [支付]
+`,
+	"stride/stride.json": `{"type":"ThreatModel","name":"stride","version":"2.0.0","rules":[
+		{"id":"STRIDE-I-001","category":"Information Disclosure","title":"敏感信息泄露","severity":"high",
+		 "keywords":["redis"],"patterns":["明文.{0,4}存储"],"requirements":["敏感数据须加密存储"]},
+		{"id":"STRIDE-E-001","category":"elevation_of_privilege","title":"越权","severity":"medium",
+		 "fields":["components"],"keywords":["admin"]}]}`,
+	"README.md": "not a material",
+}
+
+func newTestRequirementScanner(t *testing.T, dir string, versions map[string]string) *RequirementAnalysisScanner {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.RequirementAnalysis.Timeout = 30 * time.Second
+	cfg.Scanner.RequirementAnalysis.Materials = config.SecurityMaterialConfig{MaterialDir: dir, Versions: versions}
+	return NewRequirementAnalysisScanner(nil, zap.NewNop(), cfg).(*RequirementAnalysisScanner)
+}
+
+func risksByRule(t *testing.T, result *domain.ScanResult) map[string]RiskItem {
+	t.Helper()
+	risks, ok := result.Result["risks"].([]RiskItem)
+	require.True(t, ok)
+	byRule := make(map[string]RiskItem)
+	for _, r := range risks {
+		byRule[r.RuleID] = r
+	}
+	return byRule
+}
+
+func TestRequirementAnalysisScanner_Scan(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, materialFixtures)
+
+	s := newTestRequirementScanner(t, dir, nil)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-req",
+		AssetID:   "3",
+		AssetType: domain.AssetTypeRequirement,
+		Options: map[string]interface{}{
+			OptionBusinessValue:      "用户通过手机验证码登录后完成支付，会话缓存在 Redis 中",
+			OptionAcceptanceCriteria: `["登录失败 5 次锁定账户", "Password 明文存储于配置文件", "支付成功后发送通知"]`,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+
+	byRule := risksByRule(t, result)
+	require.Len(t, byRule, 3)
+
+	auth := byRule["SW-AUTH"]
+	assert.Equal(t, "SensitiveWords", auth.Source)
+	assert.Equal(t, "words@1.1.0", auth.Material)
+	assert.Equal(t, SeverityHigh, auth.Severity)
+	assert.Equal(t, []string{"密码须加盐哈希存储"}, auth.Requirements)
+	matches := make(map[string]string)
+	for _, ev := range auth.Evidence {
+		matches[ev.Path+":"+ev.Match] = ev.Snippet
+	}
+	assert.Contains(t, matches, "business_value:登录")
+	assert.Contains(t, matches, "business_value:验证码")
+	assert.Contains(t, matches, "acceptance_criteria[0]:登录")
+	assert.Equal(t, "Password 明文存储于配置文件", matches["acceptance_criteria[1]:Password"])
+
+	// 支付分类只适用于业务价值字段
+	payment := byRule["SW-PAYMENT"]
+	require.Len(t, payment.Evidence, 1)
+	assert.Equal(t, "business_value", payment.Evidence[0].Path)
+
+	disclosure := byRule["STRIDE-I-001"]
+	assert.Equal(t, "InformationDisclosure", disclosure.Category)
+	assert.Len(t, disclosure.Evidence, 2)
+
+	// 排序：critical 在前
+	risks := result.Result["risks"].([]RiskItem)
+	assert.Equal(t, "SW-PAYMENT", risks[0].RuleID)
+
+	materials := result.Result["materials"].([]MaterialRef)
+	assert.Equal(t, []MaterialRef{
+		{Type: "SensitiveWords", Name: "words", Version: "1.1.0", Path: "words/v1.1.yaml"},
+		{Type: "ThreatModel", Name: "stride", Version: "2.0.0", Path: "stride/stride.json"},
+	}, materials)
+}
+
+func TestThreatModelingScanner_DesignDocument(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, materialFixtures)
+
+	cfg := &config.Config{}
+	cfg.Scanner.ThreatModeling.Materials.MaterialDir = dir
+	s := NewThreatModelingScanner(nil, zap.NewNop(), cfg).(*ThreatModelingScanner)
+
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:    "task-design",
+		AssetType: domain.AssetTypeDesignDocument,
+		Options: map[string]interface{}{
+			OptionComponents: []interface{}{
+				map[string]interface{}{"name": "admin-console", "desc": "后台管理"},
+				map[string]interface{}{"name": "gateway", "desc": "API 网关"},
+			},
+			OptionDependencies: []interface{}{"Redis 7.0", "catalog-service"},
+		},
+	})
+	require.NoError(t, err)
+
+	byRule := risksByRule(t, result)
+	require.Contains(t, byRule, "STRIDE-E-001")
+	assert.Equal(t, "ElevationOfPrivilege", byRule["STRIDE-E-001"].Category)
+	assert.Equal(t, "components[0].name", byRule["STRIDE-E-001"].Evidence[0].Path)
+	assert.Equal(t, "dependencies[0]", byRule["STRIDE-I-001"].Evidence[0].Path)
+	assert.NotContains(t, byRule, "SW-AUTH")
+
+	summary := result.Result["summary"].(map[string]interface{})
+	assert.Equal(t, 6, summary["fields_analyzed"])
+}
+
+func TestRequirementAnalysisScanner_PinnedVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, materialFixtures)
+
+	// viper 会将配置键转为小写
+	s := newTestRequirementScanner(t, dir, map[string]string{"sensitivewords": "1.0.0"})
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-pinned",
+		Options: map[string]interface{}{OptionBusinessValue: "短信验证码登录与支付"},
+	})
+	require.NoError(t, err)
+	byRule := risksByRule(t, result)
+	assert.Equal(t, "words@1.0.0", byRule["SW-AUTH"].Material)
+	assert.Len(t, byRule["SW-AUTH"].Evidence, 1)
+	assert.NotContains(t, byRule, "SW-PAYMENT")
+
+	s = newTestRequirementScanner(t, dir, map[string]string{"ThreatModel": "9.9.9"})
+	_, err = s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-missing-version",
+		Options: map[string]interface{}{OptionBusinessValue: "登录"},
+	})
+	assert.ErrorContains(t, err, "version 9.9.9")
+}
+
+func TestRequirementAnalysisScanner_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, materialFixtures)
+	s := newTestRequirementScanner(t, dir, nil)
+	_, err := s.Scan(context.Background(), &domain.ScanTaskPayload{TaskID: "task-empty"})
+	assert.ErrorContains(t, err, "no text to analyze")
+
+	bad := t.TempDir()
+	writeFixtures(t, bad, map[string]string{
+		"stride.yaml": "type: ThreatModel\nname: bad\nversion: 1.0.0\nrules:\n  - id: X\n    category: Phishing\n    keywords: [a]\n",
+		"words.yaml":  materialFixtures["words/v1.yaml"],
+	})
+	s = newTestRequirementScanner(t, bad, nil)
+	assert.ErrorContains(t, s.HealthCheck(), "unknown STRIDE category")
+
+	dup := t.TempDir()
+	writeFixtures(t, dup, map[string]string{"a.yaml": materialFixtures["words/v1.yaml"], "b.yaml": materialFixtures["words/v1.yaml"]})
+	store, err := LoadMaterialStore(dup)
+	require.NoError(t, err)
+	_, err = store.Select(domain.SecurityMaterialTypeSensitiveWords, "")
+	assert.ErrorContains(t, err, "duplicate security material")
+}
+
+func TestShippedSecurityMaterials(t *testing.T) {
+	store, err := LoadMaterialStore(shippedMaterialDir)
+	require.NoError(t, err)
+	analyzer, err := NewRiskAnalyzer(store, config.SecurityMaterialConfig{})
+	require.NoError(t, err)
+	assert.Len(t, analyzer.Materials(), 2)
+
+	risks := analyzer.Analyze([]TextField{{Field: OptionBusinessValue, Path: OptionBusinessValue, Text: "管理员可在后台批量导出用户手机号"}})
+	categories := make(map[string]bool)
+	for _, r := range risks {
+		categories[r.Category] = true
+	}
+	assert.True(t, categories["个人敏感信息"])
+	assert.True(t, categories["ElevationOfPrivilege"])
+	assert.True(t, categories["InformationDisclosure"])
+}
+
+func TestIndexKeyword(t *testing.T) {
+	assert.Equal(t, -1, indexKeyword("product catalog", "log"))
+	assert.Equal(t, 4, indexKeyword("the log file", "log"))
+	assert.Equal(t, 6, indexKeyword("用户登录", "登录"))
+	assert.Equal(t, 6, indexKeyword("sso_x sso-login", "sso"))
+	assert.Equal(t, -1, indexKeyword("", "a"))
+}
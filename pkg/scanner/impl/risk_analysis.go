@@ -0,0 +1,466 @@
+package scanner_impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// 需求/设计文档资产中参与分析的字段，与任务选项中的键一致
+const (
+	OptionBusinessValue      = "business_value"      // RequirementAsset.BusinessValue
+	OptionAcceptanceCriteria = "acceptance_criteria" // RequirementAsset.AcceptanceCriteria，JSON 数组
+	OptionComponents         = "components"          // DesignDocumentAsset.Components，JSON 数组
+	OptionDependencies       = "dependencies"        // DesignDocumentAsset.Dependencies，JSON 数组
+)
+
+// riskAnalysisFields 需求与设计文档共用同一组字段，任务中只需提供资产实际拥有的字段
+var riskAnalysisFields = []string{OptionBusinessValue, OptionAcceptanceCriteria, OptionComponents, OptionDependencies}
+
+// STRIDE 威胁分类，键为去除空白、下划线与连字符后的小写形式
+var strideCategories = map[string]string{
+	"spoofing":              "Spoofing",
+	"tampering":             "Tampering",
+	"repudiation":           "Repudiation",
+	"informationdisclosure": "InformationDisclosure",
+	"denialofservice":       "DenialOfService",
+	"elevationofprivilege":  "ElevationOfPrivilege",
+}
+
+var categoryNormalizer = strings.NewReplacer(" ", "", "_", "", "-", "")
+
+const (
+	maxRiskEvidence = 20 // 单个风险项保留的证据上限
+	snippetRunes    = 30 // 证据片段中命中词前后保留的字符数
+)
+
+// SensitiveWordsPack 需求敏感词规则包（SecurityMaterialTypeSensitiveWords）
+type SensitiveWordsPack struct {
+	MaterialHeader `yaml:",inline"`
+	Categories     []SensitiveWordCategory `yaml:"categories"`
+}
+
+// SensitiveWordCategory 敏感词分类，命中任一词即生成该分类的风险项
+type SensitiveWordCategory struct {
+	ID           string   `yaml:"id"`
+	Name         string   `yaml:"name"`
+	Severity     string   `yaml:"severity"`
+	Fields       []string `yaml:"fields"` // 适用字段，为空时适用全部字段
+	Words        []string `yaml:"words"`
+	Requirements []string `yaml:"requirements"` // 推荐的安全需求
+}
+
+// ThreatModelPack STRIDE 威胁规则包（SecurityMaterialTypeThreatModel）
+type ThreatModelPack struct {
+	MaterialHeader `yaml:",inline"`
+	Rules          []ThreatRule `yaml:"rules"`
+}
+
+// ThreatRule STRIDE 威胁规则，关键字与正则任一命中即成立
+type ThreatRule struct {
+	ID           string   `yaml:"id"`
+	Category     string   `yaml:"category"` // STRIDE 分类
+	Title        string   `yaml:"title"`
+	Severity     string   `yaml:"severity"`
+	Fields       []string `yaml:"fields"` // 适用字段，为空时适用全部字段
+	Keywords     []string `yaml:"keywords"`
+	Patterns     []string `yaml:"patterns"`
+	Requirements []string `yaml:"requirements"` // 推荐的安全需求
+}
+
+// TextField 待分析的文本片段，Path 标识在字段中的位置，如 components[0].name
+type TextField struct {
+	Field string
+	Path  string
+	Text  string
+}
+
+// RiskEvidence 风险项的命中证据
+type RiskEvidence struct {
+	Field   string `json:"field"`
+	Path    string `json:"path"`
+	Match   string `json:"match"`
+	Snippet string `json:"snippet"`
+}
+
+// RiskItem 文本分析得到的风险项
+type RiskItem struct {
+	RuleID       string         `json:"rule_id"`
+	Source       string         `json:"source"`   // 规则来源的物料类型：SensitiveWords / ThreatModel
+	Category     string         `json:"category"` // 敏感词分类或 STRIDE 分类
+	Title        string         `json:"title"`
+	Severity     string         `json:"severity"`
+	Material     string         `json:"material"` // 规则包 name@version
+	Evidence     []RiskEvidence `json:"evidence"`
+	Requirements []string       `json:"recommended_requirements"`
+}
+
+// riskMatcher 编译后的单条规则
+type riskMatcher struct {
+	item     RiskItem
+	fields   map[string]bool
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// RiskAnalyzer 组合敏感词与威胁规则包的文本分析引擎
+type RiskAnalyzer struct {
+	matchers  []*riskMatcher
+	materials []MaterialRef
+}
+
+// NewRiskAnalyzer 从安全物料中选取敏感词与 STRIDE 规则包并编译
+func NewRiskAnalyzer(store *MaterialStore, materials config.SecurityMaterialConfig) (*RiskAnalyzer, error) {
+	a := &RiskAnalyzer{}
+
+	wordFiles, err := store.Select(domain.SecurityMaterialTypeSensitiveWords, pinnedMaterialVersion(materials.Versions, domain.SecurityMaterialTypeSensitiveWords))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range wordFiles {
+		var pack SensitiveWordsPack
+		if err := f.Decode(&pack); err != nil {
+			return nil, err
+		}
+		if err := a.addSensitiveWords(&pack, f.Ref()); err != nil {
+			return nil, fmt.Errorf("security material %s: %w", f.path, err)
+		}
+	}
+
+	threatFiles, err := store.Select(domain.SecurityMaterialTypeThreatModel, pinnedMaterialVersion(materials.Versions, domain.SecurityMaterialTypeThreatModel))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range threatFiles {
+		var pack ThreatModelPack
+		if err := f.Decode(&pack); err != nil {
+			return nil, err
+		}
+		if err := a.addThreatModel(&pack, f.Ref()); err != nil {
+			return nil, fmt.Errorf("security material %s: %w", f.path, err)
+		}
+	}
+	return a, nil
+}
+
+// Materials 返回引擎使用的规则包
+func (a *RiskAnalyzer) Materials() []MaterialRef {
+	return a.materials
+}
+
+func (a *RiskAnalyzer) addSensitiveWords(pack *SensitiveWordsPack, ref MaterialRef) error {
+	a.materials = append(a.materials, ref)
+	for _, c := range pack.Categories {
+		if c.ID == "" || len(c.Words) == 0 {
+			return fmt.Errorf("sensitive word category %q requires id and words", c.Name)
+		}
+		a.matchers = append(a.matchers, &riskMatcher{
+			item: RiskItem{
+				RuleID:       c.ID,
+				Source:       ref.Type,
+				Category:     firstNonEmpty(c.Name, c.ID),
+				Title:        "需求涉及" + firstNonEmpty(c.Name, c.ID),
+				Severity:     normalizeSeverity(c.Severity),
+				Material:     ref.Name + "@" + ref.Version,
+				Requirements: c.Requirements,
+			},
+			fields:   fieldSet(c.Fields),
+			keywords: lowerAll(c.Words),
+		})
+	}
+	return nil
+}
+
+func (a *RiskAnalyzer) addThreatModel(pack *ThreatModelPack, ref MaterialRef) error {
+	a.materials = append(a.materials, ref)
+	for _, r := range pack.Rules {
+		category, ok := strideCategories[strings.ToLower(categoryNormalizer.Replace(r.Category))]
+		if !ok {
+			return fmt.Errorf("threat rule %s: unknown STRIDE category %q", r.ID, r.Category)
+		}
+		if r.ID == "" || len(r.Keywords)+len(r.Patterns) == 0 {
+			return fmt.Errorf("threat rule %q requires id and keywords or patterns", r.Title)
+		}
+		m := &riskMatcher{
+			item: RiskItem{
+				RuleID:       r.ID,
+				Source:       ref.Type,
+				Category:     category,
+				Title:        r.Title,
+				Severity:     normalizeSeverity(r.Severity),
+				Material:     ref.Name + "@" + ref.Version,
+				Requirements: r.Requirements,
+			},
+			fields:   fieldSet(r.Fields),
+			keywords: lowerAll(r.Keywords),
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return fmt.Errorf("threat rule %s: invalid pattern %q: %w", r.ID, p, err)
+			}
+			m.patterns = append(m.patterns, re)
+		}
+		a.matchers = append(a.matchers, m)
+	}
+	return nil
+}
+
+// Analyze 对文本片段逐条应用规则，同一规则的证据合并为一个风险项
+func (a *RiskAnalyzer) Analyze(fields []TextField) []RiskItem {
+	var items []RiskItem
+	for _, m := range a.matchers {
+		item := m.item
+		seen := make(map[string]bool)
+		for _, f := range fields {
+			if len(m.fields) > 0 && !m.fields[f.Field] {
+				continue
+			}
+			for _, ev := range m.match(f) {
+				key := ev.Path + "\x00" + strings.ToLower(ev.Match)
+				if seen[key] || len(item.Evidence) >= maxRiskEvidence {
+					continue
+				}
+				seen[key] = true
+				item.Evidence = append(item.Evidence, ev)
+			}
+		}
+		if len(item.Evidence) > 0 {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if ri, rj := severityRank(items[i].Severity), severityRank(items[j].Severity); ri != rj {
+			return ri > rj
+		}
+		return items[i].RuleID < items[j].RuleID
+	})
+	return items
+}
+
+// match 返回文本中每个关键字与正则的首次命中
+func (m *riskMatcher) match(f TextField) []RiskEvidence {
+	var evidence []RiskEvidence
+	text, lower := f.Text, strings.ToLower(f.Text)
+	// 个别字符小写后字节长度会变化，此时证据改从小写文本截取以保证偏移一致
+	if len(lower) != len(text) {
+		text = lower
+	}
+	for _, kw := range m.keywords {
+		if start := indexKeyword(lower, kw); start >= 0 {
+			evidence = append(evidence, RiskEvidence{
+				Field:   f.Field,
+				Path:    f.Path,
+				Match:   text[start : start+len(kw)],
+				Snippet: snippet(text, start, start+len(kw)),
+			})
+		}
+	}
+	for _, re := range m.patterns {
+		if loc := re.FindStringIndex(f.Text); loc != nil && loc[1] > loc[0] {
+			evidence = append(evidence, RiskEvidence{
+				Field:   f.Field,
+				Path:    f.Path,
+				Match:   f.Text[loc[0]:loc[1]],
+				Snippet: snippet(f.Text, loc[0], loc[1]),
+			})
+		}
+	}
+	return evidence
+}
+
+// indexKeyword 查找关键字，英文关键字要求词边界以避免 log 命中 catalog，中文直接按子串匹配
+func indexKeyword(text, kw string) int {
+	if kw == "" {
+		return -1
+	}
+	wordLike := isWordRune(firstRune(kw)) && firstRune(kw) < utf8.RuneSelf
+	for offset := 0; offset < len(text); {
+		idx := strings.Index(text[offset:], kw)
+		if idx < 0 {
+			return -1
+		}
+		start, end := offset+idx, offset+idx+len(kw)
+		if !wordLike || (!isWordRune(lastRune(text[:start])) && !isWordRune(firstRune(text[end:]))) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// snippet 截取命中位置前后的上下文
+func snippet(text string, start, end int) string {
+	from := start
+	for i := 0; i < snippetRunes && from > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(text[:from])
+		from -= size
+	}
+	to := end
+	for i := 0; i < snippetRunes && to < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[to:])
+		to += size
+	}
+	s := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		s = "..." + s
+	}
+	if to < len(text) {
+		s += "..."
+	}
+	return s
+}
+
+// collectTextFields 从任务选项中提取文本片段，JSON 数组/对象（含 JSON 字符串形式）逐元素展开
+func collectTextFields(options map[string]interface{}, keys ...string) []TextField {
+	var fields []TextField
+	for _, key := range keys {
+		v, ok := options[key]
+		if !ok {
+			continue
+		}
+		flattenText(key, key, v, &fields)
+	}
+	return fields
+}
+
+func flattenText(field, path string, v interface{}, out *[]TextField) {
+	switch val := v.(type) {
+	case string:
+		text := strings.TrimSpace(val)
+		if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+				flattenText(field, path, decoded, out)
+				return
+			}
+		}
+		if text != "" {
+			*out = append(*out, TextField{Field: field, Path: path, Text: text})
+		}
+	case []byte:
+		flattenText(field, path, string(val), out)
+	case json.RawMessage:
+		flattenText(field, path, string(val), out)
+	case []string:
+		for i, s := range val {
+			flattenText(field, path+"["+strconv.Itoa(i)+"]", s, out)
+		}
+	case []interface{}:
+		for i, item := range val {
+			flattenText(field, path+"["+strconv.Itoa(i)+"]", item, out)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flattenText(field, path+"."+k, val[k], out)
+		}
+	}
+}
+
+// riskFindings 转换为统一发现项，逻辑位置为首个命中证据的字段路径
+func riskFindings(items []RiskItem) []domain.Finding {
+	out := make([]domain.Finding, 0, len(items))
+	for _, item := range items {
+		finding := domain.Finding{
+			RuleID:   item.RuleID,
+			RuleName: item.Title,
+			Severity: item.Severity,
+			Message:  item.Title,
+			Category: item.Category,
+			Properties: map[string]interface{}{
+				"source":   item.Source,
+				"material": item.Material,
+				"evidence": item.Evidence,
+			},
+		}
+		if len(item.Evidence) > 0 {
+			finding.Location = domain.FindingLocation{Logical: item.Evidence[0].Path, LogicalKind: "field"}
+		}
+		if len(item.Requirements) > 0 {
+			finding.Properties["recommended_requirements"] = item.Requirements
+			finding.Remediation = strings.Join(item.Requirements, "; ")
+		}
+		out = append(out, finding)
+	}
+	return out
+}
+
+// summarizeRisks 按严重等级与分类统计风险项
+func summarizeRisks(items []RiskItem, fields int) map[string]interface{} {
+	severities := make(map[string]int)
+	categories := make(map[string]int)
+	for _, item := range items {
+		severities[item.Severity]++
+		categories[item.Category]++
+	}
+	return map[string]interface{}{
+		"fields_analyzed": fields,
+		"risks":           len(items),
+		"by_severity":     severities,
+		"by_category":     categories,
+	}
+}
+
+// severityRank 严重等级排序权重
+func severityRank(s string) int {
+	switch s {
+	case SeverityCritical:
+		return 4
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func fieldSet(fields []string) map[string]bool {
+	if len(fields) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	return set
+}
+
+func lowerAll(words []string) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -0,0 +1,260 @@
+package scanner_impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// SAST 工具输出格式
+const (
+	FormatSARIF       = "sarif"
+	FormatSemgrepJSON = "semgrep-json"
+	FormatGosecJSON   = "gosec-json"
+)
+
+// 统一的严重等级
+const (
+	SeverityCritical = domain.SeverityCritical
+	SeverityHigh     = domain.SeverityHigh
+	SeverityMedium   = domain.SeverityMedium
+	SeverityLow      = domain.SeverityLow
+	SeverityInfo     = domain.SeverityInfo
+)
+
+// SASTFinding 结构化的静态扫描发现项
+type SASTFinding struct {
+	FilePath      string `json:"file_path"`
+	LineNumber    int    `json:"line_number"`
+	Severity      string `json:"severity"`
+	RuleID        string `json:"rule_id"`
+	RuleName      string `json:"rule_name"`
+	Description   string `json:"description"`
+	CWEID         string `json:"cwe_id,omitempty"`
+	FixSuggestion string `json:"fix_suggestion,omitempty"`
+}
+
+// SASTReport 工具输出解析结果
+type SASTReport struct {
+	Tool     string        `json:"tool"`
+	Version  string        `json:"version,omitempty"`
+	Findings []SASTFinding `json:"findings"`
+}
+
+var cwePattern = regexp.MustCompile(`(?i)cwe[-/:\s]*(\d+)`)
+
+// ParseSASTReport 按格式解析工具输出，文件路径统一转换为相对 baseDir 的路径
+func ParseSASTReport(format string, data []byte, baseDir string) (*SASTReport, error) {
+	var (
+		report *SASTReport
+		err    error
+	)
+	switch strings.ToLower(format) {
+	case "", FormatSARIF:
+		report, err = parseSARIFReport(data)
+	case FormatSemgrepJSON:
+		report, err = parseSemgrepReport(data)
+	case FormatGosecJSON:
+		report, err = parseGosecReport(data)
+	default:
+		return nil, fmt.Errorf("unsupported sast output format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report.Findings {
+		report.Findings[i].FilePath = relativePath(report.Findings[i].FilePath, baseDir)
+	}
+	return report, nil
+}
+
+func parseSARIFReport(data []byte) (*SASTReport, error) {
+	log, err := domain.DecodeSARIF(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sarif output: %w", err)
+	}
+
+	report := &SASTReport{Findings: []SASTFinding{}}
+	for _, run := range log.Reports() {
+		if report.Tool == "" {
+			report.Tool = run.Tool
+			report.Version = run.ToolVersion
+		}
+		for _, f := range run.Findings {
+			report.Findings = append(report.Findings, SASTFinding{
+				FilePath:      f.Location.Path,
+				LineNumber:    f.Location.StartLine,
+				Severity:      f.Severity,
+				RuleID:        f.RuleID,
+				RuleName:      f.RuleName,
+				Description:   f.Message,
+				CWEID:         f.CWEID,
+				FixSuggestion: f.Remediation,
+			})
+		}
+	}
+	return report, nil
+}
+
+// FindingReport 转换为统一的发现项报告
+func (r *SASTReport) FindingReport() domain.FindingReport {
+	findings := make([]domain.Finding, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		findings = append(findings, domain.Finding{
+			RuleID:      f.RuleID,
+			RuleName:    f.RuleName,
+			Severity:    f.Severity,
+			Message:     f.Description,
+			CWEID:       f.CWEID,
+			Location:    domain.FindingLocation{Path: f.FilePath, StartLine: f.LineNumber},
+			Remediation: f.FixSuggestion,
+		})
+	}
+	return domain.FindingReport{Tool: r.Tool, ToolVersion: r.Version, Findings: findings}
+}
+
+type semgrepOutput struct {
+	Version string `json:"version"`
+	Results []struct {
+		CheckID string `json:"check_id"`
+		Path    string `json:"path"`
+		Start   struct {
+			Line int `json:"line"`
+		} `json:"start"`
+		Extra struct {
+			Message  string                 `json:"message"`
+			Severity string                 `json:"severity"`
+			Fix      string                 `json:"fix"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"extra"`
+	} `json:"results"`
+}
+
+func parseSemgrepReport(data []byte) (*SASTReport, error) {
+	var out semgrepOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid semgrep output: %w", err)
+	}
+
+	report := &SASTReport{Tool: "semgrep", Version: out.Version, Findings: []SASTFinding{}}
+	for _, res := range out.Results {
+		var cweTags []string
+		switch v := res.Extra.Metadata["cwe"].(type) {
+		case string:
+			cweTags = append(cweTags, v)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					cweTags = append(cweTags, s)
+				}
+			}
+		}
+
+		severity := SeverityMedium
+		switch strings.ToUpper(res.Extra.Severity) {
+		case "ERROR":
+			severity = SeverityHigh
+		case "INFO":
+			severity = SeverityLow
+		}
+
+		ruleName := res.CheckID
+		if idx := strings.LastIndex(ruleName, "."); idx >= 0 {
+			ruleName = ruleName[idx+1:]
+		}
+
+		report.Findings = append(report.Findings, SASTFinding{
+			FilePath:      res.Path,
+			LineNumber:    res.Start.Line,
+			Severity:      severity,
+			RuleID:        res.CheckID,
+			RuleName:      ruleName,
+			Description:   res.Extra.Message,
+			CWEID:         extractCWE(cweTags...),
+			FixSuggestion: res.Extra.Fix,
+		})
+	}
+	return report, nil
+}
+
+type gosecOutput struct {
+	GosecVersion string `json:"GosecVersion"`
+	Issues       []struct {
+		Severity string `json:"severity"`
+		CWE      struct {
+			ID string `json:"id"`
+		} `json:"cwe"`
+		RuleID  string `json:"rule_id"`
+		Details string `json:"details"`
+		File    string `json:"file"`
+		Line    string `json:"line"`
+	} `json:"Issues"`
+}
+
+func parseGosecReport(data []byte) (*SASTReport, error) {
+	var out gosecOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid gosec output: %w", err)
+	}
+
+	report := &SASTReport{Tool: "gosec", Version: out.GosecVersion, Findings: []SASTFinding{}}
+	for _, issue := range out.Issues {
+		// gosec 的行号可能是范围，如 "12-15"
+		line, _ := strconv.Atoi(strings.SplitN(issue.Line, "-", 2)[0])
+		cwe := ""
+		if issue.CWE.ID != "" {
+			cwe = "CWE-" + issue.CWE.ID
+		}
+		report.Findings = append(report.Findings, SASTFinding{
+			FilePath:    issue.File,
+			LineNumber:  line,
+			Severity:    strings.ToLower(issue.Severity),
+			RuleID:      issue.RuleID,
+			RuleName:    issue.RuleID,
+			Description: issue.Details,
+			CWEID:       cwe,
+		})
+	}
+	return report, nil
+}
+
+// extractCWE 从标签中提取第一个 CWE 编号，统一为 CWE-<id> 形式
+func extractCWE(tags ...string) string {
+	for _, tag := range tags {
+		if m := cwePattern.FindStringSubmatch(tag); m != nil {
+			return "CWE-" + m[1]
+		}
+	}
+	return ""
+}
+
+// relativePath 将工具输出的路径（可能为 file:// URI 或绝对路径）转换为相对源码根目录的路径
+func relativePath(p, baseDir string) string {
+	if strings.HasPrefix(p, "file://") {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+	}
+	if baseDir != "" && filepath.IsAbs(p) {
+		if rel, err := filepath.Rel(baseDir, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(p)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,8 +1,13 @@
 package scanner_impl
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/config"
@@ -14,6 +19,8 @@ import (
 // SASTScanner 静态代码分析扫描器
 type SASTScanner struct {
 	*BaseScanner
+	tool    config.ToolConfig
+	workDir string
 }
 
 // NewSASTScanner 创建SAST扫描器
@@ -27,6 +34,7 @@ func NewSASTScanner(
 
 	// 从配置获取参数
 	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeStaticCodeAnalysis)
+	s.tool, s.workDir = config.GetSASTToolConfig()
 
 	baseOpts := []BaseScannerOption{
 		WithResourceProfile(resourceProfile),
@@ -50,19 +58,160 @@ func NewSASTScanner(
 func (s *SASTScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
 	// 创建扫描结果
 	result := domain.NewScanResult(task.TaskID, domain.ScanTypeStaticCodeAnalysis, task.AssetID, task.AssetType)
+	var opts domain.SASTOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	// 1. 创建沙箱工作目录
+	workDir, err := s.CreateWorkspace(s.workDir, task)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+		}
+	}()
+
+	// 2. 检出代码仓库
+	srcDir, err := s.CheckoutSource(ctx, task, workDir)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
 
-	// 执行代码扫描命令
-	s.logger.Info("SAST execute", zap.Duration("within time ", s.defaultTimeout))
-	cmd := exec.CommandContext(ctx, "sleep", "3")
-	if err := s.ExecuteCommand(ctx, task, cmd, ""); err != nil {
+	// 3. 增量扫描时只把变更文件交给工具
+	rev, err := s.ResolveRevision(ctx, task, srcDir)
+	if err != nil {
 		result.SetFailed(err.Error())
 		return result, err
 	}
+	result.Revision = rev
+	scanDir, scanFiles := srcDir, -1
+	if rev != nil && rev.Incremental {
+		if scanDir, scanFiles, err = s.ScopeToChanges(srcDir, workDir, rev); err != nil {
+			result.SetFailed(err.Error())
+			return result, err
+		}
+	}
+
+	// 4. 执行代码扫描工具并解析输出
+	report := &SASTReport{Tool: filepath.Base(s.tool.Path), Findings: []SASTFinding{}}
+	if scanFiles != 0 {
+		s.logger.Info("SAST execute", zap.String("tool", s.tool.Path), zap.Duration("within time ", s.defaultTimeout))
+		target, release, err := s.ReadOnlyTarget(scanDir, workDir)
+		if err != nil {
+			result.SetFailed(err.Error())
+			return result, err
+		}
+		defer release()
+		if report, err = s.runTool(ctx, task, target, workDir, opts.RulePacks); err != nil {
+			result.SetFailed(err.Error())
+			return result, err
+		}
+	}
+
 	// 设置成功结果
-	result.SetSuccess(task.Options)
+	result.SetSuccess(map[string]interface{}{
+		"tool":     report.Tool,
+		"version":  report.Version,
+		"findings": report.Findings,
+		"summary":  summarizeSASTFindings(report.Findings),
+	})
+	result.SetFindings(report.FindingReport())
 	return result, nil
 }
 
+// runTool 执行配置的SAST工具，输出写入文件或标准输出；rulePacks 非空时替换配置中的规则包
+func (s *SASTScanner) runTool(ctx context.Context, task *domain.ScanTaskPayload, srcDir, workDir string, rulePacks []string) (*SASTReport, error) {
+	if s.tool.Path == "" {
+		return nil, errors.New("sast tool path not configured")
+	}
+
+	outputFile := filepath.Join(workDir, "report.out")
+	if len(rulePacks) == 0 {
+		rulePacks = s.tool.RulePacks
+	}
+	args, usesOutputFile := s.buildToolArgs(srcDir, outputFile, rulePacks)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.tool.Path, args...)
+	cmd.Dir = srcDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// 工具发现问题时以非零码退出属于正常结果，不计入熔断
+	if err := s.ExecuteCommand(ctx, task, cmd, "", WithAcceptedExitCodes(s.tool.SuccessExitCodes...)); err != nil {
+		return nil, fmt.Errorf("sast tool failed: %w: %s", err, truncate(stderr.String(), 512))
+	}
+
+	output := stdout.Bytes()
+	if usesOutputFile {
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("read sast output failed: %w", err)
+		}
+		output = data
+	}
+
+	report, err := ParseSASTReport(s.tool.OutputFormat, output, srcDir)
+	if err != nil {
+		return nil, err
+	}
+	if report.Tool == "" {
+		report.Tool = filepath.Base(s.tool.Path)
+	}
+	return report, nil
+}
+
+// buildToolArgs 展开参数模板中的占位符
+func (s *SASTScanner) buildToolArgs(srcDir, outputFile string, rulePacks []string) ([]string, bool) {
+	args := make([]string, 0, len(s.tool.Args)+2*len(rulePacks))
+	usesOutputFile := false
+	for _, arg := range s.tool.Args {
+		switch arg {
+		case "{rules}":
+			for _, pack := range rulePacks {
+				if s.tool.RuleFlag != "" {
+					args = append(args, s.tool.RuleFlag)
+				}
+				args = append(args, pack)
+			}
+		case "{target}":
+			args = append(args, srcDir)
+		case "{output}":
+			args = append(args, outputFile)
+			usesOutputFile = true
+		default:
+			args = append(args, arg)
+		}
+	}
+	return args, usesOutputFile
+}
+
+// summarizeSASTFindings 按严重等级统计发现项
+func summarizeSASTFindings(findings []SASTFinding) map[string]interface{} {
+	bySeverity := make(map[string]int)
+	for _, f := range findings {
+		bySeverity[f.Severity]++
+	}
+	return map[string]interface{}{
+		"total":       len(findings),
+		"by_severity": bySeverity,
+	}
+}
+
+// truncate 截断过长的工具输出
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
 // AsyncExecute 实现TaskExecutor接口
 func (s *SASTScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
 	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
@@ -79,15 +228,18 @@ func (s *SASTScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayl
 
 // Cancel 实现TaskExecutor接口
 func (s *SASTScanner) Cancel(handle string) error {
-	return nil
+	return s.BaseScanner.CancelExecution(handle)
 }
 
 // GetStatus 实现TaskExecutor接口
 func (s *SASTScanner) GetStatus(handle string) (domain.TaskStatus, error) {
-	return domain.TaskStatusCompleted, nil
+	return s.BaseScanner.ExecutionStatus(handle)
 }
 
 // HealthCheck 实现TaskExecutor接口
 func (s *SASTScanner) HealthCheck() error {
+	if _, err := exec.LookPath(s.tool.Path); err != nil {
+		return fmt.Errorf("sast tool unavailable: %w", err)
+	}
 	return s.BaseScanner.HealthCheck()
 }
@@ -0,0 +1,179 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const cannedSARIF = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "fake-semgrep", "version": "1.0.0", "rules": [{
+      "id": "go.lang.security.sqli",
+      "name": "sql-injection",
+      "shortDescription": {"text": "SQL injection"},
+      "help": {"text": "Use parameterized queries"},
+      "properties": {"tags": ["security", "CWE-89: SQL Injection"], "security-severity": "8.1"}
+    }]}},
+    "results": [{
+      "ruleId": "go.lang.security.sqli",
+      "level": "error",
+      "message": {"text": "user input flows into db.Query"},
+      "locations": [{"physicalLocation": {
+        "artifactLocation": {"uri": "main.go"},
+        "region": {"startLine": 12}
+      }}]
+    }]
+  }]
+}`
+
+// writeFakeTool 生成一个把固定 SARIF 写入 {output} 的假工具
+func writeFakeTool(t *testing.T, dir string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.sarif"), []byte(cannedSARIF), 0o644))
+	script := "#!/bin/sh\ncp \"" + filepath.Join(dir, "report.sarif") + "\" \"$1\"\nexit 1\n"
+	toolPath := filepath.Join(dir, "fake-sast")
+	require.NoError(t, os.WriteFile(toolPath, []byte(script), 0o755))
+	return toolPath
+}
+
+func newTestSASTScanner(t *testing.T, toolPath string) *SASTScanner {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.UploadRoot = os.TempDir()
+	cfg.Scanner.SAST.Timeout = 30 * time.Second
+	cfg.Scanner.SAST.WorkDir = t.TempDir()
+	cfg.Scanner.SAST.Tool = config.ToolConfig{
+		Path:             toolPath,
+		Args:             []string{"{output}", "{rules}", "{target}"},
+		RuleFlag:         "--config",
+		RulePacks:        []string{"p/golang"},
+		OutputFormat:     FormatSARIF,
+		SuccessExitCodes: []int{0, 1},
+	}
+	return NewSASTScanner(nil, zap.NewNop(), cfg).(*SASTScanner)
+}
+
+func TestSASTScanner_ScanWithFakeTool(t *testing.T) {
+	toolDir := t.TempDir()
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main\n"), 0o644))
+
+	s := newTestSASTScanner(t, writeFakeTool(t, toolDir))
+	task := &domain.ScanTaskPayload{
+		TaskID:    "task-1",
+		AssetID:   "1",
+		AssetType: domain.AssetTypeRepository,
+		ScanType:  domain.ScanTypeStaticCodeAnalysis,
+		Options:   map[string]interface{}{OptionSourcePath: srcDir},
+	}
+
+	result, err := s.Scan(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, "fake-semgrep", result.Result["tool"])
+
+	findings, ok := result.Result["findings"].([]SASTFinding)
+	require.True(t, ok)
+	require.Len(t, findings, 1)
+	assert.Equal(t, SASTFinding{
+		FilePath:      "main.go",
+		LineNumber:    12,
+		Severity:      SeverityHigh,
+		RuleID:        "go.lang.security.sqli",
+		RuleName:      "sql-injection",
+		Description:   "user input flows into db.Query",
+		CWEID:         "CWE-89",
+		FixSuggestion: "Use parameterized queries",
+	}, findings[0])
+
+	// 同一发现项以 SARIF 输出
+	require.NotNil(t, result.SARIF)
+	require.Len(t, result.SARIF.Runs, 1)
+	assert.Equal(t, "fake-semgrep", result.SARIF.Runs[0].Tool.Driver.Name)
+	sarifFindings := result.Findings()
+	require.Len(t, sarifFindings, 1)
+	assert.Equal(t, "go.lang.security.sqli", sarifFindings[0].RuleID)
+	assert.Equal(t, domain.FindingLocation{Path: "main.go", StartLine: 12}, sarifFindings[0].Location)
+	assert.Equal(t, "CWE-89", sarifFindings[0].CWEID)
+	assert.NotEmpty(t, sarifFindings[0].Fingerprint)
+
+	// 工作目录应在扫描后清理
+	entries, err := os.ReadDir(s.workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSASTScanner_ToolFailure(t *testing.T) {
+	dir := t.TempDir()
+	toolPath := filepath.Join(dir, "broken-sast")
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho boom >&2\nexit 2\n"), 0o755))
+
+	s := newTestSASTScanner(t, toolPath)
+	task := &domain.ScanTaskPayload{
+		TaskID:  "task-2",
+		Options: map[string]interface{}{OptionSourcePath: t.TempDir()},
+	}
+
+	result, err := s.Scan(context.Background(), task)
+	require.Error(t, err)
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "boom")
+}
+
+func TestSASTScanner_SuccessExitCodeKeepsBreakerClosed(t *testing.T) {
+	toolDir := t.TempDir()
+	srcDir := t.TempDir()
+	s := newTestSASTScanner(t, writeFakeTool(t, toolDir))
+
+	// 假工具每次发现问题都以 1 退出，连续执行超过熔断阈值后熔断器仍应关闭
+	for i := 0; i < 8; i++ {
+		task := &domain.ScanTaskPayload{
+			TaskID:  fmt.Sprintf("task-exit-%d", i),
+			Options: map[string]interface{}{OptionSourcePath: srcDir},
+		}
+		result, err := s.Scan(context.Background(), task)
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+	}
+	assert.Equal(t, scanner.StateClosed, s.circuitBreaker.GetState())
+	transient, critical := s.circuitBreaker.GetFailureCount()
+	assert.Zero(t, transient)
+	assert.Zero(t, critical)
+}
+
+func TestParseSASTReport_SemgrepAndGosec(t *testing.T) {
+	semgrep := `{"version":"1.50.0","results":[{"check_id":"python.flask.xss","path":"/src/app.py","start":{"line":7},
+		"extra":{"message":"XSS","severity":"WARNING","metadata":{"cwe":["CWE-79: Cross-site Scripting"]}}}]}`
+	report, err := ParseSASTReport(FormatSemgrepJSON, []byte(semgrep), "/src")
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, "app.py", report.Findings[0].FilePath)
+	assert.Equal(t, "CWE-79", report.Findings[0].CWEID)
+	assert.Equal(t, SeverityMedium, report.Findings[0].Severity)
+	assert.Equal(t, "xss", report.Findings[0].RuleName)
+
+	gosec := `{"GosecVersion":"2.18","Issues":[{"severity":"HIGH","cwe":{"id":"22"},"rule_id":"G304",
+		"details":"Potential file inclusion","file":"/src/pkg/io.go","line":"20-22"}]}`
+	report, err = ParseSASTReport(FormatGosecJSON, []byte(gosec), "/src")
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, "pkg/io.go", report.Findings[0].FilePath)
+	assert.Equal(t, 20, report.Findings[0].LineNumber)
+	assert.Equal(t, "CWE-22", report.Findings[0].CWEID)
+	assert.Equal(t, SeverityHigh, report.Findings[0].Severity)
+
+	_, err = ParseSASTReport("xml", nil, "")
+	assert.Error(t, err)
+}
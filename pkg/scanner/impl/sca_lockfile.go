@@ -0,0 +1,554 @@
+package scanner_impl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OSV 生态名称
+const (
+	EcosystemGo    = "Go"
+	EcosystemNpm   = "npm"
+	EcosystemPyPI  = "PyPI"
+	EcosystemMaven = "Maven"
+	EcosystemCargo = "crates.io"
+)
+
+// Component 归一化后的依赖组件
+type Component struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	License   string `json:"license,omitempty"`
+	Direct    bool   `json:"direct"`
+	Source    string `json:"source"` // 声明该组件的清单文件（相对源码根目录）
+	// SourcePackage OS 包对应的源码包名（dpkg Source、apk origin、rpm SOURCERPM）
+	SourcePackage string `json:"source_package,omitempty"`
+}
+
+// lockfileParser 解析单个清单文件
+type lockfileParser func(data []byte) ([]Component, error)
+
+// lockfileParsers 按文件名注册的清单解析器
+var lockfileParsers = map[string]lockfileParser{
+	"go.mod":            parseGoMod,
+	"go.sum":            parseGoSum,
+	"package-lock.json": parsePackageLock,
+	"yarn.lock":         parseYarnLock,
+	"requirements.txt":  parseRequirements,
+	"poetry.lock":       parsePoetryLock,
+	"pom.xml":           parsePomXML,
+	"Cargo.lock":        parseCargoLock,
+}
+
+// skipDirs 遍历时跳过的目录，依赖目录中的清单由上层锁文件覆盖
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+// CollectComponents 遍历源码目录，解析所有支持的清单文件并去重
+func CollectComponents(root string) ([]Component, error) {
+	var components []Component
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		found, _, err := parseManifest(root, path)
+		components = append(components, found...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dedupeComponents(components), nil
+}
+
+// CollectManifestComponents 只解析 dirs（相对 root）中直接包含的清单文件，
+// 返回去重后的组件与实际存在的清单文件相对路径
+func CollectManifestComponents(root string, dirs []string) ([]Component, []string, error) {
+	var (
+		components []Component
+		manifests  []string
+	)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, e := range entries {
+			if _, ok := lockfileParsers[e.Name()]; !ok || !e.Type().IsRegular() {
+				continue
+			}
+			path := filepath.Join(root, filepath.FromSlash(dir), e.Name())
+			found, ok, err := parseManifest(root, path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				manifests = append(manifests, relativePath(path, root))
+			}
+			components = append(components, found...)
+		}
+	}
+	return dedupeComponents(components), manifests, nil
+}
+
+// ManifestDirs 返回变更文件中清单文件所在的目录（仓库相对路径），依赖目录中的文件不计入
+func ManifestDirs(changed []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, rel := range changed {
+		if _, ok := lockfileParsers[path.Base(rel)]; !ok || inSkippedDir(rel) {
+			continue
+		}
+		dir := path.Dir(rel)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// parseManifest 解析单个清单文件，文件名不受支持或被同目录清单覆盖时返回 false
+func parseManifest(root, path string) ([]Component, bool, error) {
+	name := filepath.Base(path)
+	parser, ok := lockfileParsers[name]
+	if !ok {
+		return nil, false, nil
+	}
+	// 同目录存在 go.mod 时 go.sum 只作为补充，不重复解析
+	if name == "go.sum" {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), "go.mod")); err == nil {
+			return nil, true, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	found, err := parser(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("parse %s failed: %w", relativePath(path, root), err)
+	}
+	source := relativePath(path, root)
+	for i := range found {
+		found[i].Source = source
+	}
+	return found, true, nil
+}
+
+// dedupeComponents 按 生态/名称/版本 去重，保留直接依赖标记与许可证
+func dedupeComponents(components []Component) []Component {
+	index := make(map[string]int, len(components))
+	result := make([]Component, 0, len(components))
+	for _, c := range components {
+		key := c.Ecosystem + "|" + c.Name + "|" + c.Version
+		if i, ok := index[key]; ok {
+			result[i].Direct = result[i].Direct || c.Direct
+			if result[i].License == "" {
+				result[i].License = c.License
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, c)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Ecosystem != result[j].Ecosystem {
+			return result[i].Ecosystem < result[j].Ecosystem
+		}
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// parseGoMod 解析 require 指令，// indirect 标记的为间接依赖
+func parseGoMod(data []byte) ([]Component, error) {
+	var components []Component
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "//"):
+			continue
+		case strings.HasPrefix(line, "require ("):
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		indirect := strings.Contains(line, "// indirect")
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		components = append(components, Component{
+			Name:      fields[0],
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+			Direct:    !indirect,
+		})
+	}
+	return components, scanner.Err()
+}
+
+// parseGoSum 解析 go.sum，忽略仅校验 go.mod 的条目
+func parseGoSum(data []byte) ([]Component, error) {
+	var components []Component
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		components = append(components, Component{
+			Name:      fields[0],
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+		})
+	}
+	return components, scanner.Err()
+}
+
+type packageLockEntry struct {
+	Version      string                      `json:"version"`
+	License      interface{}                 `json:"license"`
+	Dependencies map[string]packageLockEntry `json:"dependencies"`
+}
+
+// parsePackageLock 同时支持 lockfileVersion 1 的 dependencies 与 2/3 的 packages
+func parsePackageLock(data []byte) ([]Component, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version         string            `json:"version"`
+			License         interface{}       `json:"license"`
+			Link            bool              `json:"link"`
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		} `json:"packages"`
+		Dependencies map[string]packageLockEntry `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	if len(lock.Packages) > 0 {
+		root := lock.Packages[""]
+		direct := make(map[string]bool)
+		for name := range root.Dependencies {
+			direct[name] = true
+		}
+		for name := range root.DevDependencies {
+			direct[name] = true
+		}
+
+		for path, pkg := range lock.Packages {
+			idx := strings.LastIndex(path, "node_modules/")
+			if path == "" || idx < 0 || pkg.Link || pkg.Version == "" {
+				continue
+			}
+			name := path[idx+len("node_modules/"):]
+			components = append(components, Component{
+				Name:      name,
+				Version:   pkg.Version,
+				Ecosystem: EcosystemNpm,
+				License:   npmLicense(pkg.License),
+				Direct:    direct[name] && idx == 0,
+			})
+		}
+		return components, nil
+	}
+
+	var walk func(deps map[string]packageLockEntry)
+	walk = func(deps map[string]packageLockEntry) {
+		for name, dep := range deps {
+			if dep.Version != "" {
+				components = append(components, Component{
+					Name:      name,
+					Version:   dep.Version,
+					Ecosystem: EcosystemNpm,
+					License:   npmLicense(dep.License),
+				})
+			}
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return components, nil
+}
+
+// npmLicense 兼容字符串和旧式 {type: ...} 两种许可证写法
+func npmLicense(v interface{}) string {
+	switch l := v.(type) {
+	case string:
+		return l
+	case map[string]interface{}:
+		if t, ok := l["type"].(string); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+// parseYarnLock 支持 yarn v1 与 berry 两种锁文件格式
+func parseYarnLock(data []byte) ([]Component, error) {
+	var (
+		components []Component
+		current    string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(raw, " ") && strings.HasSuffix(line, ":") {
+			// 条目头，如 "@babel/core@^7.0.0", "@babel/core@^7.1.0":
+			spec := strings.TrimSpace(strings.SplitN(strings.TrimSuffix(line, ":"), ",", 2)[0])
+			current = yarnPackageName(strings.Trim(spec, `"`))
+			continue
+		}
+
+		if current == "" || !strings.HasPrefix(line, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(line, "version"))
+		version = strings.Trim(strings.TrimSpace(strings.TrimPrefix(version, ":")), `"`)
+		if version != "" && current != "__metadata" {
+			components = append(components, Component{
+				Name:      current,
+				Version:   version,
+				Ecosystem: EcosystemNpm,
+			})
+		}
+		current = ""
+	}
+	return components, scanner.Err()
+}
+
+// yarnPackageName 从 name@range 中提取包名，兼容 scope 包
+func yarnPackageName(spec string) string {
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		return spec[:idx]
+	}
+	return spec
+}
+
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*===?\s*([^\s;#,]+)`)
+
+// parseRequirements 只识别固定版本（== / ===）的依赖，范围约束无法确定实际安装版本
+func parseRequirements(data []byte) ([]Component, error) {
+	var components []Component
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		m := requirementPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		components = append(components, Component{
+			Name:      normalizePyPIName(m[1]),
+			Version:   m[2],
+			Ecosystem: EcosystemPyPI,
+			Direct:    true,
+		})
+	}
+	return components, scanner.Err()
+}
+
+// normalizePyPIName 按 PEP 503 规范化包名
+func normalizePyPIName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+func parsePoetryLock(data []byte) ([]Component, error) {
+	var components []Component
+	for _, pkg := range parseTOMLPackages(data) {
+		if pkg["name"] == "" || pkg["version"] == "" {
+			continue
+		}
+		components = append(components, Component{
+			Name:      normalizePyPIName(pkg["name"]),
+			Version:   pkg["version"],
+			Ecosystem: EcosystemPyPI,
+		})
+	}
+	return components, nil
+}
+
+func parseCargoLock(data []byte) ([]Component, error) {
+	var components []Component
+	for _, pkg := range parseTOMLPackages(data) {
+		// 没有 source 的是工作区内的本地 crate
+		if pkg["name"] == "" || pkg["version"] == "" || pkg["source"] == "" {
+			continue
+		}
+		components = append(components, Component{
+			Name:      pkg["name"],
+			Version:   pkg["version"],
+			Ecosystem: EcosystemCargo,
+		})
+	}
+	return components, nil
+}
+
+// parseTOMLPackages 提取锁文件中 [[package]] 表的字符串键值
+// poetry.lock 与 Cargo.lock 只需要这部分，无需完整的 TOML 解析
+func parseTOMLPackages(data []byte) []map[string]string {
+	var (
+		packages []map[string]string
+		current  map[string]string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			current = nil
+			if line == "[[package]]" {
+				current = make(map[string]string)
+				packages = append(packages, current)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if !strings.HasPrefix(value, `"`) {
+			continue
+		}
+		current[strings.TrimSpace(key)] = strings.Trim(value, `"`)
+	}
+	return packages
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+type pomProject struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	DependencyManagement struct {
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parsePomXML 解析直接声明的依赖，支持属性替换与 dependencyManagement 中的版本
+func parsePomXML(data []byte) ([]Component, error) {
+	var project pomProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+
+	props := map[string]string{
+		"project.version":        firstNonEmpty(project.Version, project.Parent.Version),
+		"project.groupId":        firstNonEmpty(project.GroupID, project.Parent.GroupID),
+		"project.parent.version": project.Parent.Version,
+	}
+	for _, entry := range project.Properties.Entries {
+		props[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	resolve := func(s string) string {
+		return pomPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(s), func(m string) string {
+			if v, ok := props[m[2:len(m)-1]]; ok {
+				return v
+			}
+			return m
+		})
+	}
+
+	managed := make(map[string]string)
+	for _, dep := range project.DependencyManagement.Dependencies {
+		managed[resolve(dep.GroupID)+":"+resolve(dep.ArtifactID)] = resolve(dep.Version)
+	}
+
+	var components []Component
+	for _, dep := range project.Dependencies {
+		name := resolve(dep.GroupID) + ":" + resolve(dep.ArtifactID)
+		version := resolve(dep.Version)
+		if version == "" {
+			version = managed[name]
+		}
+		// 无法解析的属性或版本范围不参与匹配
+		if version == "" || strings.Contains(version, "${") || strings.ContainsAny(version, "[(,") {
+			continue
+		}
+		components = append(components, Component{
+			Name:      name,
+			Version:   version,
+			Ecosystem: EcosystemMaven,
+			Direct:    true,
+		})
+	}
+	return components, nil
+}
@@ -2,7 +2,9 @@ package scanner_impl
 
 import (
 	"context"
-	"os/exec"
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/config"
@@ -15,6 +17,18 @@ import (
 // SCAScanner 软件成分分析扫描器
 type SCAScanner struct {
 	*BaseScanner
+	vulnDBDir string
+	workDir   string
+	vulnDB    vulnDBCache
+}
+
+// SCAComponentResult 组件及其命中的漏洞
+type SCAComponentResult struct {
+	Component
+	Advisories    []Advisory `json:"advisories,omitempty"`
+	FixedVersions []string   `json:"fixed_versions,omitempty"`
+	// RecommendedVersion 可同时修复所有已知漏洞的最低版本
+	RecommendedVersion string `json:"recommended_version,omitempty"`
 }
 
 // NewSCAScanner 创建SCA扫描器
@@ -28,6 +42,8 @@ func NewSCAScanner(
 
 	// 从配置获取参数
 	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeSca)
+	s.vulnDBDir, s.workDir = config.GetSCAConfig()
+
 	baseOpts := []BaseScannerOption{
 		WithResourceProfile(resourceProfile),
 		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
@@ -51,17 +67,215 @@ func (s *SCAScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*d
 	// 创建扫描结果
 	result := domain.NewScanResult(task.TaskID, domain.ScanTypeSca, task.AssetID, task.AssetType)
 
-	// 执行目录扫描命令
-	cmd := exec.CommandContext(ctx, "sleep", "5")
-	if err := s.ExecuteCommand(ctx, task, cmd, ""); err != nil {
+	// 1. 加载离线漏洞库
+	db, err := s.vulnDB.get(s.vulnDBDir)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	// 2. 创建沙箱工作目录并准备源码（仓库检出或上传归档解压）
+	workDir, err := s.CreateWorkspace(s.workDir, task)
+	if err != nil {
 		result.SetFailed(err.Error())
 		return result, err
 	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+		}
+	}()
+
+	srcDir, err := s.CheckoutSource(ctx, task, workDir)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	rev, err := s.ResolveRevision(ctx, task, srcDir)
+	if err != nil {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+	result.Revision = rev
+
+	// 3. 解析依赖清单；增量扫描时只重新解析清单有变更的目录，
+	// 锁文件变化会连带同目录的其它清单一起重新解析并计入变更范围
+	var components []Component
+	if rev != nil && rev.Incremental {
+		var manifests []string
+		components, manifests, err = CollectManifestComponents(srcDir, ManifestDirs(rev.ChangedFiles))
+		rev.ChangedFiles = mergeChangedFiles(rev.ChangedFiles, manifests)
+	} else {
+		components, err = CollectComponents(srcDir)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		err = fmt.Errorf("collect components failed: %w", err)
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	// 4. 匹配漏洞
+	results := make([]SCAComponentResult, 0, len(components))
+	for _, c := range components {
+		results = append(results, matchComponent(db, c))
+	}
+
+	summary := summarizeSCAResults(results)
+	s.logger.Info("sca scan finished",
+		zap.String("task_id", task.TaskID),
+		zap.Int("components", len(results)),
+		zap.Any("summary", summary))
+
 	// 设置成功结果
-	result.SetSuccess(task.Options)
+	result.SetSuccess(map[string]interface{}{
+		"components": results,
+		"summary":    summary,
+	})
+	result.SetFindings(s.findingReport(scaFindings(results)))
 	return result, nil
 }
 
+// mergeChangedFiles 将连带重新解析的清单加入变更文件列表，保持有序去重
+func mergeChangedFiles(changed, extra []string) []string {
+	seen := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		seen[f] = true
+	}
+	for _, f := range extra {
+		if !seen[f] {
+			seen[f] = true
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// matchComponent 匹配组件漏洞，OS 发行版的公告通常按源码包发布，二进制包名未命中时再按源码包匹配
+func matchComponent(db *VulnDB, c Component) SCAComponentResult {
+	advisories := db.Match(c)
+	if c.SourcePackage != "" && c.SourcePackage != c.Name {
+		src := c
+		src.Name = c.SourcePackage
+		seen := make(map[string]bool, len(advisories))
+		for _, a := range advisories {
+			seen[a.ID] = true
+		}
+		for _, a := range db.Match(src) {
+			if !seen[a.ID] {
+				advisories = append(advisories, a)
+			}
+		}
+		sort.Slice(advisories, func(i, j int) bool { return advisories[i].ID < advisories[j].ID })
+	}
+
+	compare := versionComparer(c.Ecosystem)
+	return SCAComponentResult{
+		Component:          c,
+		Advisories:         advisories,
+		FixedVersions:      mergeFixedVersions(compare, advisories),
+		RecommendedVersion: recommendedVersion(compare, advisories),
+	}
+}
+
+// mergeFixedVersions 合并各公告的修复版本，升序去重
+func mergeFixedVersions(compare func(a, b string) int, advisories []Advisory) []string {
+	seen := make(map[string]bool)
+	var versions []string
+	for _, a := range advisories {
+		for _, v := range a.FixedVersions {
+			if !seen[v] {
+				seen[v] = true
+				versions = append(versions, v)
+			}
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return compare(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// recommendedVersion 取各公告最低修复版本中的最大值
+func recommendedVersion(compare func(a, b string) int, advisories []Advisory) string {
+	recommended := ""
+	for _, a := range advisories {
+		if len(a.FixedVersions) == 0 {
+			continue
+		}
+		if recommended == "" || compare(a.FixedVersions[0], recommended) > 0 {
+			recommended = a.FixedVersions[0]
+		}
+	}
+	return recommended
+}
+
+// scaFindings 每个组件命中的每条公告转换为一个发现项，物理位置为声明组件的清单文件
+func scaFindings(results []SCAComponentResult) []domain.Finding {
+	var out []domain.Finding
+	for _, r := range results {
+		pkg := r.Ecosystem + ":" + r.Name + "@" + r.Version
+		for _, a := range r.Advisories {
+			message := fmt.Sprintf("%s is affected by %s", pkg, a.ID)
+			if a.Summary != "" {
+				message += ": " + a.Summary
+			}
+			finding := domain.Finding{
+				RuleID:   a.ID,
+				RuleName: a.ID,
+				Severity: domain.NormalizeSeverity(a.Severity),
+				Message:  message,
+				Category: "vulnerable-dependency",
+				Location: domain.FindingLocation{Path: r.Source, Logical: pkg, LogicalKind: "package"},
+				Properties: map[string]interface{}{
+					"ecosystem": r.Ecosystem,
+					"package":   r.Name,
+					"version":   r.Version,
+					"direct":    r.Direct,
+				},
+			}
+			if len(a.Aliases) > 0 {
+				finding.Properties["aliases"] = a.Aliases
+			}
+			if len(a.FixedVersions) > 0 {
+				finding.Properties["fixed_versions"] = a.FixedVersions
+			}
+			if r.RecommendedVersion != "" {
+				finding.Remediation = "Upgrade " + r.Name + " to " + r.RecommendedVersion
+			}
+			out = append(out, finding)
+		}
+	}
+	return out
+}
+
+// summarizeSCAResults 统计组件与漏洞数量
+func summarizeSCAResults(results []SCAComponentResult) map[string]interface{} {
+	ecosystems := make(map[string]int)
+	severities := make(map[string]int)
+	vulnerable, advisories := 0, 0
+	for _, r := range results {
+		ecosystems[r.Ecosystem]++
+		if len(r.Advisories) == 0 {
+			continue
+		}
+		vulnerable++
+		advisories += len(r.Advisories)
+		for _, a := range r.Advisories {
+			severities[firstNonEmpty(a.Severity, "unknown")]++
+		}
+	}
+	return map[string]interface{}{
+		"components":  len(results),
+		"vulnerable":  vulnerable,
+		"advisories":  advisories,
+		"ecosystems":  ecosystems,
+		"by_severity": severities,
+	}
+}
+
 // AsyncExecute 实现TaskExecutor接口
 func (s *SCAScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
 	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
@@ -78,15 +292,18 @@ func (s *SCAScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPaylo
 
 // Cancel 实现TaskExecutor接口
 func (s *SCAScanner) Cancel(handle string) error {
-	return nil
+	return s.BaseScanner.CancelExecution(handle)
 }
 
 // GetStatus 实现TaskExecutor接口
 func (s *SCAScanner) GetStatus(handle string) (domain.TaskStatus, error) {
-	return domain.TaskStatusCompleted, nil
+	return s.BaseScanner.ExecutionStatus(handle)
 }
 
 // HealthCheck 实现TaskExecutor接口
 func (s *SCAScanner) HealthCheck() error {
+	if _, err := os.Stat(s.vulnDBDir); err != nil {
+		return fmt.Errorf("sca vuln db unavailable: %w", err)
+	}
 	return s.BaseScanner.HealthCheck()
 }
@@ -0,0 +1,386 @@
+package scanner_impl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// scaFixtures 覆盖所有支持的清单格式，Cargo.lock 等文件名被 .gitignore 忽略，因此在测试中生成
+var scaFixtures = map[string]string{
+	"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	github.com/gin-gonic/gin v1.9.0
+	golang.org/x/net v0.7.0 // indirect
+)
+`,
+	"web/package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"lodash": "^4.17.0"}},
+    "node_modules/lodash": {"version": "4.17.20", "license": "MIT"},
+    "node_modules/minimist": {"version": "1.2.5", "license": "MIT"}
+  }
+}`,
+	"web/yarn.lock": `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.1.2"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.1.2.tgz"
+`,
+	"py/requirements.txt": `# pinned
+Django==3.2.0
+requests[security]==2.25.1 ; python_version >= "3.6"
+flask>=2.0
+-r other.txt
+`,
+	"py/poetry.lock": `[[package]]
+name = "PyYAML"
+version = "5.3.1"
+description = "YAML parser"
+
+[package.dependencies]
+foo = "*"
+`,
+	"java/pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <version>1.0.0</version>
+  <properties><jackson.version>2.9.10</jackson.version></properties>
+  <dependencyManagement><dependencies>
+    <dependency><groupId>org.apache.logging.log4j</groupId><artifactId>log4j-core</artifactId><version>2.14.1</version></dependency>
+  </dependencies></dependencyManagement>
+  <dependencies>
+    <dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>${jackson.version}</version></dependency>
+    <dependency><groupId>org.apache.logging.log4j</groupId><artifactId>log4j-core</artifactId></dependency>
+  </dependencies>
+</project>`,
+	"rust/Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+
+[[package]]
+name = "smallvec"
+version = "1.6.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+	"web/node_modules/ignored/package-lock.json": `{"packages": {"node_modules/evil": {"version": "1.0.0"}}}`,
+}
+
+var osvFixtures = map[string]string{
+	"Go/GO-2023-1571.json": `{"id":"GO-2023-1571","aliases":["CVE-2022-41723"],"summary":"HPACK decoder DoS",
+		"affected":[{"package":{"ecosystem":"Go","name":"golang.org/x/net"},
+		"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"0.7.0"}]}]}]}`,
+	"npm/GHSA-35jh-r3h4-6jhm.json": `{"id":"GHSA-35jh-r3h4-6jhm","aliases":["CVE-2021-23337"],"summary":"Command Injection in lodash",
+		"database_specific":{"severity":"HIGH"},
+		"affected":[{"package":{"ecosystem":"npm","name":"lodash"},
+		"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"4.17.21"}]}]}]}`,
+	"PyPI/PYSEC-2021-9.json": `{"id":"PYSEC-2021-9","summary":"Django path traversal",
+		"affected":[{"package":{"ecosystem":"PyPI","name":"django"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"3.2"},{"fixed":"3.2.1"},{"introduced":"3.1"},{"fixed":"3.1.9"}]}]}]}`,
+	"Maven/GHSA-jfh8-c2jp-5v3q.json": `{"id":"GHSA-jfh8-c2jp-5v3q","aliases":["CVE-2021-44228"],"summary":"Log4Shell",
+		"database_specific":{"severity":"CRITICAL"},
+		"affected":[{"package":{"ecosystem":"Maven","name":"org.apache.logging.log4j:log4j-core"},
+		"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"2.0-beta9"},{"fixed":"2.15.0"}]}]}]}`,
+	"crates.io/RUSTSEC-2021-0003.json": `{"id":"RUSTSEC-2021-0003","summary":"Buffer overflow in SmallVec::insert_many",
+		"affected":[{"package":{"ecosystem":"crates.io","name":"smallvec"},
+		"versions":["1.6.0"],"ranges":[{"type":"SEMVER","events":[{"introduced":"1.6.0"},{"fixed":"1.6.1"}]}]}]}`,
+	"npm/withdrawn.json": `{"id":"GHSA-withdrawn","withdrawn":"2022-01-01T00:00:00Z",
+		"affected":[{"package":{"ecosystem":"npm","name":"minimist"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"}]}]}]}`,
+}
+
+func writeFixtures(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func newTestSCAScanner(t *testing.T) *SCAScanner {
+	t.Helper()
+	dbDir := t.TempDir()
+	writeFixtures(t, dbDir, osvFixtures)
+
+	cfg := &config.Config{}
+	cfg.Scanner.UploadRoot = os.TempDir()
+	cfg.Scanner.SCA.Timeout = 30 * time.Second
+	cfg.Scanner.SCA.WorkDir = t.TempDir()
+	cfg.Scanner.SCA.VulnDBDir = dbDir
+	return NewSCAScanner(nil, zap.NewNop(), cfg).(*SCAScanner)
+}
+
+func TestCollectComponents(t *testing.T) {
+	root := t.TempDir()
+	writeFixtures(t, root, scaFixtures)
+
+	components, err := CollectComponents(root)
+	require.NoError(t, err)
+
+	byName := make(map[string]Component)
+	for _, c := range components {
+		byName[c.Ecosystem+":"+c.Name] = c
+	}
+	assert.Len(t, components, 11)
+	assert.NotContains(t, byName, "npm:evil")
+
+	assert.Equal(t, Component{Name: "github.com/gin-gonic/gin", Version: "v1.9.0", Ecosystem: EcosystemGo, Direct: true, Source: "go.mod"}, byName["Go:github.com/gin-gonic/gin"])
+	assert.False(t, byName["Go:golang.org/x/net"].Direct)
+	assert.Equal(t, Component{Name: "lodash", Version: "4.17.20", Ecosystem: EcosystemNpm, License: "MIT", Direct: true, Source: "web/package-lock.json"}, byName["npm:lodash"])
+	assert.False(t, byName["npm:minimist"].Direct)
+	assert.Equal(t, "7.1.2", byName["npm:@babel/core"].Version)
+	assert.Equal(t, "3.2.0", byName["PyPI:django"].Version)
+	assert.Equal(t, "2.25.1", byName["PyPI:requests"].Version)
+	assert.NotContains(t, byName, "PyPI:flask")
+	assert.Equal(t, "5.3.1", byName["PyPI:pyyaml"].Version)
+	assert.Equal(t, "2.9.10", byName["Maven:com.fasterxml.jackson.core:jackson-databind"].Version)
+	assert.Equal(t, "2.14.1", byName["Maven:org.apache.logging.log4j:log4j-core"].Version)
+	assert.Equal(t, "1.6.0", byName["crates.io:smallvec"].Version)
+	assert.NotContains(t, byName, "crates.io:app")
+}
+
+func TestSCAScanner_ScanMatchesOfflineDB(t *testing.T) {
+	srcDir := t.TempDir()
+	writeFixtures(t, srcDir, scaFixtures)
+
+	s := newTestSCAScanner(t)
+	task := &domain.ScanTaskPayload{
+		TaskID:    "task-sca",
+		AssetID:   "1",
+		AssetType: domain.AssetTypeRepository,
+		ScanType:  domain.ScanTypeSca,
+		Options:   map[string]interface{}{OptionSourcePath: srcDir},
+	}
+
+	result, err := s.Scan(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+
+	components, ok := result.Result["components"].([]SCAComponentResult)
+	require.True(t, ok)
+
+	advisories := make(map[string][]string)
+	fixed := make(map[string]string)
+	for _, c := range components {
+		for _, a := range c.Advisories {
+			advisories[c.Name] = append(advisories[c.Name], a.ID)
+		}
+		fixed[c.Name] = c.RecommendedVersion
+	}
+	assert.Equal(t, map[string][]string{
+		"lodash":                              {"GHSA-35jh-r3h4-6jhm"},
+		"django":                              {"PYSEC-2021-9"},
+		"org.apache.logging.log4j:log4j-core": {"GHSA-jfh8-c2jp-5v3q"},
+		"smallvec":                            {"RUSTSEC-2021-0003"},
+	}, advisories)
+	assert.Equal(t, "4.17.21", fixed["lodash"])
+	assert.Equal(t, "3.2.1", fixed["django"])
+	assert.Equal(t, "2.15.0", fixed["org.apache.logging.log4j:log4j-core"])
+	// golang.org/x/net v0.7.0 即修复版本，不应命中
+	assert.Empty(t, fixed["golang.org/x/net"])
+
+	summary := result.Result["summary"].(map[string]interface{})
+	assert.Equal(t, 4, summary["vulnerable"])
+}
+
+func TestVulnDB_ConcurrentMatch(t *testing.T) {
+	dbDir := t.TempDir()
+	writeFixtures(t, dbDir, osvFixtures)
+	db, err := LoadVulnDB(dbDir)
+	require.NoError(t, err)
+
+	// 漏洞库在扫描间共享，并发匹配不能修改记录
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			advisories := db.Match(Component{Ecosystem: EcosystemPyPI, Name: "Django", Version: "3.1.5"})
+			if assert.Len(t, advisories, 1) {
+				assert.Equal(t, []string{"3.1.9", "3.2.1"}, advisories[0].FixedVersions)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSCAScanner_ScanArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "upload.tar.gz")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	content := []byte(scaFixtures["web/package-lock.json"])
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "app/package-lock.json", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	s := newTestSCAScanner(t)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:  "task-archive",
+		Options: map[string]interface{}{OptionArchivePath: archive},
+	})
+	require.NoError(t, err)
+	components := result.Result["components"].([]SCAComponentResult)
+	require.Len(t, components, 2)
+	assert.Equal(t, "app/package-lock.json", components[0].Source)
+}
+
+func TestExtractArchive_RejectsPathTraversal(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	tw := tar.NewWriter(f)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../../escape.txt", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, f.Close())
+
+	err = extractArchive(archive, filepath.Join(t.TempDir(), "src"))
+	assert.ErrorContains(t, err, "illegal path")
+}
+
+func TestCheckoutSource_ConfinedToUploadRoot(t *testing.T) {
+	uploadRoot := t.TempDir()
+	inside := filepath.Join(uploadRoot, "repo")
+	require.NoError(t, os.Mkdir(inside, 0o755))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(uploadRoot, "link")))
+
+	s := newTestSCAScanner(t)
+	s.uploadRoot = uploadRoot
+	checkout := func(key, path string) (string, error) {
+		return s.checkoutSource(context.Background(), &domain.ScanTaskPayload{
+			TaskID:  "task-upload",
+			Options: map[string]interface{}{key: path},
+		}, t.TempDir(), 1)
+	}
+
+	srcDir, err := checkout(OptionSourcePath, inside)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(inside), filepath.Base(srcDir))
+
+	// 根目录外的路径与指向根目录外的符号链接都被拒绝
+	for _, path := range []string{outside, filepath.Join(uploadRoot, "link"), filepath.Join(uploadRoot, "..", filepath.Base(outside))} {
+		_, err = checkout(OptionSourcePath, path)
+		assert.ErrorContains(t, err, "outside upload root", path)
+	}
+	_, err = checkout(OptionArchivePath, "/etc/passwd")
+	assert.ErrorContains(t, err, "outside upload root")
+
+	// 未配置上传根目录时不接受本地路径
+	s.uploadRoot = ""
+	_, err = checkout(OptionSourcePath, inside)
+	assert.ErrorContains(t, err, "upload root not configured")
+}
+
+func TestIsRemoteRepoURL(t *testing.T) {
+	for _, u := range []string{
+		"https://git.example.com/app.git",
+		"ssh://git@git.example.com:2222/app.git",
+		"git://git.example.com/app.git",
+		"git@github.com:org/app.git",
+	} {
+		assert.True(t, isRemoteRepoURL(u), u)
+	}
+	// 本地路径与 file:// 等协议会绕过上传根目录的限制
+	for _, u := range []string{
+		"/etc",
+		"./repo",
+		"../secrets",
+		"file:///var/lib/go-sac",
+		"ext::sh -c touch% /tmp/pwned",
+		"http://git.example.com/app.git",
+		"--upload-pack=touch /tmp/pwned",
+		"https:///app.git",
+	} {
+		assert.False(t, isRemoteRepoURL(u), u)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0rc1", "1.0", -1},
+		{"2.0-beta9", "2.0", -1},
+		{"2.0-beta9", "2.14.1", -1},
+		{"1.0.post1", "1.0", 1},
+		{"1.0.post1", "1.0.1", -1},
+		{"5.3.0.Final", "5.3.0", 0},
+		{"v0.0.0-20200101000000-abcdef", "v0.1.0", -1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, compareVersions(c.a, c.b), "%s vs %s", c.a, c.b)
+	}
+}
+
+func TestSCAScanner_IncrementalLockfileChange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git(t, repo, "init", "--quiet")
+	writeFixtures(t, repo, scaFixtures)
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "--quiet", "-m", "initial")
+	base := gitHead(t, repo)
+
+	// 只有 web/yarn.lock 变化，同目录的 package-lock.json 连带重新解析
+	writeFixtures(t, repo, map[string]string{
+		"web/yarn.lock": scaFixtures["web/yarn.lock"] + "\n",
+		"README.md":     "docs\n",
+	})
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "--quiet", "-m", "bump")
+
+	s := newTestSCAScanner(t)
+	result, err := s.Scan(context.Background(), &domain.ScanTaskPayload{
+		TaskID:     "task-sca-delta",
+		AssetID:    "1",
+		AssetType:  domain.AssetTypeRepository,
+		ScanType:   domain.ScanTypeSca,
+		Options:    map[string]interface{}{OptionSourcePath: repo},
+		BaseCommit: base,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Revision)
+	assert.True(t, result.Revision.Incremental)
+	assert.Equal(t, []string{"README.md", "web/package-lock.json", "web/yarn.lock"}, result.Revision.ChangedFiles)
+
+	for _, c := range result.Result["components"].([]SCAComponentResult) {
+		assert.Equal(t, EcosystemNpm, c.Ecosystem, c.Name)
+	}
+	findings := result.Findings()
+	require.Len(t, findings, 1)
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", findings[0].RuleID)
+	assert.Equal(t, "web/package-lock.json", findings[0].Location.Path)
+}
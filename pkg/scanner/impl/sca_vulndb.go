@@ -0,0 +1,379 @@
+package scanner_impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// osvEntry OSV 漏洞记录中匹配所需的字段
+type osvEntry struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Withdrawn string   `json:"withdrawn"`
+	Affected  []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string     `json:"type"`
+			Events []osvEvent `json:"events"`
+		} `json:"ranges"`
+		Versions         []string               `json:"versions"`
+		DatabaseSpecific map[string]interface{} `json:"database_specific"`
+	} `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+// Advisory 组件命中的漏洞公告
+type Advisory struct {
+	ID            string   `json:"id"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Severity      string   `json:"severity,omitempty"`
+	FixedVersions []string `json:"fixed_versions,omitempty"`
+}
+
+// VulnDB 离线 OSV 漏洞库，按 生态/包名 建立索引
+type VulnDB struct {
+	dir      string
+	loadedAt time.Time
+	entries  map[string][]*osvEntry
+}
+
+// LoadVulnDB 递归加载目录下的 OSV JSON 文件
+// 目录结构与 osv.dev 按生态导出的数据一致，如 <dir>/Go/GO-2023-0001.json
+func LoadVulnDB(dir string) (*VulnDB, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("vuln db dir not configured")
+	}
+	db := &VulnDB{dir: dir, loadedAt: time.Now(), entries: make(map[string][]*osvEntry)}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var entry osvEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("invalid osv record %s: %w", path, err)
+		}
+		if entry.ID == "" || entry.Withdrawn != "" {
+			return nil
+		}
+		sortOSVEvents(&entry)
+		seen := make(map[string]bool)
+		for _, affected := range entry.Affected {
+			key := vulnKey(affected.Package.Ecosystem, affected.Package.Name)
+			if !seen[key] {
+				seen[key] = true
+				db.entries[key] = append(db.entries[key], &entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load vuln db failed: %w", err)
+	}
+	return db, nil
+}
+
+// sortOSVEvents 按版本排序各范围的事件：OSV 规范要求按版本顺序评估事件，数据源不保证有序；
+// 加载后记录在扫描间共享只读，不能在匹配时排序
+func sortOSVEvents(entry *osvEntry) {
+	for i := range entry.Affected {
+		compare := versionComparer(entry.Affected[i].Package.Ecosystem)
+		for j := range entry.Affected[i].Ranges {
+			events := entry.Affected[i].Ranges[j].Events
+			sort.SliceStable(events, func(a, b int) bool {
+				return compare(eventVersion(events[a]), eventVersion(events[b])) < 0
+			})
+		}
+	}
+}
+
+// Size 返回已索引的包数量
+func (db *VulnDB) Size() int {
+	return len(db.entries)
+}
+
+// Match 返回影响指定组件版本的漏洞公告
+func (db *VulnDB) Match(c Component) []Advisory {
+	compare := versionComparer(c.Ecosystem)
+	var advisories []Advisory
+	for _, entry := range db.entries[vulnKey(c.Ecosystem, c.Name)] {
+		affected := false
+		var fixed []string
+		for _, a := range entry.Affected {
+			if vulnKey(a.Package.Ecosystem, a.Package.Name) != vulnKey(c.Ecosystem, c.Name) {
+				continue
+			}
+			for _, v := range a.Versions {
+				if compare(v, c.Version) == 0 {
+					affected = true
+				}
+			}
+			for _, r := range a.Ranges {
+				// GIT 范围基于提交哈希，无法与包版本比较
+				if r.Type == "GIT" {
+					continue
+				}
+				// 事件已在加载时按版本排序
+				inRange := false
+				for _, ev := range r.Events {
+					switch {
+					case ev.Introduced != "":
+						if ev.Introduced == "0" || compare(c.Version, ev.Introduced) >= 0 {
+							inRange = true
+						}
+					case ev.Fixed != "":
+						fixed = append(fixed, ev.Fixed)
+						if compare(c.Version, ev.Fixed) >= 0 {
+							inRange = false
+						}
+					case ev.LastAffected != "":
+						if compare(c.Version, ev.LastAffected) > 0 {
+							inRange = false
+						}
+					}
+				}
+				affected = affected || inRange
+			}
+		}
+		if !affected {
+			continue
+		}
+		advisories = append(advisories, Advisory{
+			ID:            entry.ID,
+			Aliases:       entry.Aliases,
+			Summary:       entry.Summary,
+			Severity:      osvSeverity(entry),
+			FixedVersions: fixedVersionsAbove(compare, fixed, c.Version),
+		})
+	}
+	sort.Slice(advisories, func(i, j int) bool { return advisories[i].ID < advisories[j].ID })
+	return advisories
+}
+
+// eventVersion 返回事件对应的版本，introduced 为 "0" 时表示最早版本
+func eventVersion(ev osvEvent) string {
+	return firstNonEmpty(ev.Introduced, ev.Fixed, ev.LastAffected)
+}
+
+// vulnKey 生成索引键，PyPI 包名大小写及分隔符不敏感
+func vulnKey(ecosystem, name string) string {
+	if ecosystem == EcosystemPyPI {
+		name = normalizePyPIName(name)
+	}
+	return ecosystem + "|" + name
+}
+
+// osvSeverity 读取 GHSA 等数据源提供的定性严重等级
+func osvSeverity(entry *osvEntry) string {
+	if s, ok := entry.DatabaseSpecific["severity"].(string); ok {
+		return normalizeSeverity(s)
+	}
+	for _, a := range entry.Affected {
+		if s, ok := a.DatabaseSpecific["severity"].(string); ok {
+			return normalizeSeverity(s)
+		}
+	}
+	return ""
+}
+
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(s) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "moderate", "medium":
+		return SeverityMedium
+	case "low":
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// fixedVersionsAbove 返回高于当前版本的修复版本，升序去重
+func fixedVersionsAbove(compare func(a, b string) int, fixed []string, current string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range fixed {
+		if seen[v] || compare(v, current) <= 0 {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return compare(result[i], result[j]) < 0 })
+	return result
+}
+
+// versionComparer 按生态选择版本比较算法，OS 发行版生态名带版本后缀（如 Debian:12）
+func versionComparer(ecosystem string) func(a, b string) int {
+	distro, _, _ := strings.Cut(ecosystem, ":")
+	switch distro {
+	case "Debian", "Ubuntu":
+		return compareDebianVersions
+	case "Alpine":
+		return compareAPKVersions
+	case "Red Hat", "AlmaLinux", "Rocky Linux", "CentOS", "Fedora", "openSUSE", "SUSE", "Mageia":
+		return compareRPMVersions
+	default:
+		return compareVersions
+	}
+}
+
+// compareVersions 通用版本比较，覆盖 semver、PEP 440 与 Maven 的常见写法
+// 按数字段逐段比较，带预发布后缀的版本小于对应正式版本
+func compareVersions(a, b string) int {
+	a, b = normalizeVersion(a), normalizeVersion(b)
+	if a == b {
+		return 0
+	}
+	aMain, aPre := splitPrerelease(a)
+	bMain, bPre := splitPrerelease(b)
+
+	if c := compareSegments(splitVersion(aMain), splitVersion(bMain)); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegments(splitVersion(aPre), splitVersion(bPre))
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	// 构建元数据与 Go 伪版本的 +incompatible 不参与比较
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		v = v[:idx]
+	}
+	return v
+}
+
+// splitPrerelease 分离预发布标识，兼容 1.0.0-rc1、1.0rc1、1.0.0.Final 等写法
+func splitPrerelease(v string) (string, string) {
+	if idx := strings.Index(v, "-"); idx >= 0 {
+		return v[:idx], v[idx+1:]
+	}
+	for i := 0; i < len(v); i++ {
+		if (v[i] >= 'a' && v[i] <= 'z') || (v[i] >= 'A' && v[i] <= 'Z') {
+			pre := strings.TrimLeft(v[i:], ".")
+			main := strings.TrimRight(v[:i], ".")
+			// Maven 的 Final/RELEASE/GA 等同于正式版本
+			switch strings.ToLower(pre) {
+			case "final", "release", "ga":
+				return main, ""
+			}
+			// PEP 440 的 post 版本高于正式版本但低于下一个补丁版本，追加 .0.N 段处理
+			if strings.HasPrefix(strings.ToLower(pre), "post") {
+				return main + ".0." + orZero(strings.TrimPrefix(strings.ToLower(pre), "post")), ""
+			}
+			return main, pre
+		}
+	}
+	return v, ""
+}
+
+func splitVersion(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+}
+
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y string
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareSegment(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareSegment 数字段按数值比较，缺失的段视为 0，非数字段按字典序比较
+func compareSegment(x, y string) int {
+	xn, xErr := strconv.ParseUint(orZero(x), 10, 64)
+	yn, yErr := strconv.ParseUint(orZero(y), 10, 64)
+	switch {
+	case xErr == nil && yErr == nil:
+		switch {
+		case xn < yn:
+			return -1
+		case xn > yn:
+			return 1
+		}
+		return 0
+	case xErr == nil:
+		return -1
+	case yErr == nil:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// vulnDBCache 在多次扫描间复用已加载的漏洞库，目录更新后自动重新加载
+type vulnDBCache struct {
+	mu sync.Mutex
+	db *VulnDB
+}
+
+// get 返回最新的漏洞库，目录修改时间晚于加载时间时重新加载
+func (c *vulnDBCache) get(dir string) (*VulnDB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("vuln db unavailable: %w", err)
+	}
+	if c.db != nil && c.db.dir == dir && !info.ModTime().After(c.db.loadedAt) {
+		return c.db, nil
+	}
+	db, err := LoadVulnDB(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.db = db
+	return db, nil
+}
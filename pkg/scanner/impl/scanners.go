@@ -14,15 +14,26 @@ func CreateDefaultScanners(
 	metrics MetricsRecorder,
 	cgroup CgroupManager,
 	cfg *config.Config,
+	opts ...BaseScannerOption,
 ) map[domain.ScanType]scanner.TaskExecutor {
 	commonOpts := []BaseScannerOption{
 		WithMetricsRecorder(metrics),
 		WithCgroupManager(cgroup),
 	}
+	commonOpts = append(commonOpts, opts...)
 
 	return map[domain.ScanType]scanner.TaskExecutor{
-		domain.ScanTypeStaticCodeAnalysis: NewSASTScanner(timeoutCtrl, logger, cfg, commonOpts...),
-		domain.ScanTypeDast:               NewDASTScanner(timeoutCtrl, logger, cfg, commonOpts...),
-		domain.ScanTypeSca:                NewSCAScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeStaticCodeAnalysis:  NewSASTScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeDast:                NewDASTScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeSca:                 NewSCAScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeSecretsDetection:    NewSecretsScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypePortScanning:        NewPortScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeContainerImageScan:  NewImageScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeRequirementAnalysis: NewRequirementAnalysisScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeThreatModeling:      NewThreatModelingScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeHostSecurityCheck:   NewHostSecurityScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeComplianceAudit:     NewComplianceAuditScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeSecuritySpecCheck:   NewSecuritySpecScanner(timeoutCtrl, logger, cfg, commonOpts...),
+		domain.ScanTypeBlackBoxTesting:     NewBlackBoxScanner(timeoutCtrl, logger, cfg, commonOpts...),
 	}
 }
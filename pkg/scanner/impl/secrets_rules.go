@@ -0,0 +1,322 @@
+package scanner_impl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+)
+
+// allowMarker 行内注释包含该标记时跳过检测
+const allowMarker = "gosac:allow"
+
+// genericSecretRuleID 基于关键字与熵的通用规则
+const genericSecretRuleID = "generic-high-entropy"
+
+// SecretRule 敏感信息检测规则
+type SecretRule struct {
+	ID          string
+	Description string
+	Severity    string
+	Pattern     *regexp.Regexp
+	SecretGroup int      // 密钥所在的捕获组，0 表示整个匹配
+	MinEntropy  float64  // 最小香农熵，0 表示不校验
+	Keywords    []string // 预过滤关键字（小写），行内不含任何关键字时跳过正则匹配
+}
+
+// SecretFinding 敏感信息发现项，Secret 字段已脱敏
+type SecretFinding struct {
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	FilePath    string  `json:"file_path"`
+	LineNumber  int     `json:"line_number"`
+	Commit      string  `json:"commit,omitempty"`
+	Secret      string  `json:"secret"`
+	Fingerprint string  `json:"fingerprint"`
+	Entropy     float64 `json:"entropy,omitempty"`
+}
+
+// defaultSecretRules 内置规则集
+func defaultSecretRules(entropyThreshold float64) []SecretRule {
+	return []SecretRule{
+		{
+			ID:          "aws-access-key-id",
+			Description: "AWS access key ID",
+			Severity:    SeverityHigh,
+			Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`),
+			SecretGroup: 1,
+			Keywords:    []string{"akia", "asia", "abia", "acca"},
+		},
+		{
+			ID:          "aws-secret-access-key",
+			Description: "AWS secret access key",
+			Severity:    SeverityCritical,
+			Pattern:     regexp.MustCompile(`(?i)aws.{0,20}(?:secret|private).{0,20}['"\s:=]+([A-Za-z0-9/+=]{40})\b`),
+			SecretGroup: 1,
+			MinEntropy:  4.0,
+			Keywords:    []string{"aws"},
+		},
+		{
+			ID:          "private-key",
+			Description: "Private key",
+			Severity:    SeverityCritical,
+			Pattern:     regexp.MustCompile(`-----BEGIN ((?:RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`),
+			Keywords:    []string{"private key"},
+		},
+		{
+			ID:          "jwt",
+			Description: "JSON Web Token",
+			Severity:    SeverityMedium,
+			Pattern:     regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`),
+			SecretGroup: 1,
+			Keywords:    []string{"eyj"},
+		},
+		{
+			ID:          "github-token",
+			Description: "GitHub token",
+			Severity:    SeverityHigh,
+			Pattern:     regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,})\b`),
+			SecretGroup: 1,
+			Keywords:    []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_"},
+		},
+		{
+			ID:          "slack-token",
+			Description: "Slack token",
+			Severity:    SeverityHigh,
+			Pattern:     regexp.MustCompile(`\b(xox[baprs]-[0-9A-Za-z-]{10,})\b`),
+			SecretGroup: 1,
+			Keywords:    []string{"xox"},
+		},
+		{
+			ID:          genericSecretRuleID,
+			Description: "High-entropy string assigned to a credential-like name",
+			Severity:    SeverityMedium,
+			Pattern:     regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|pwd|api[_-]?key|access[_-]?key|auth|credential)[\w.-]*["']?\s*[:=]{1,2}\s*["'` + "`" + `]?([A-Za-z0-9+/=_\-.~]{16,})`),
+			SecretGroup: 1,
+			MinEntropy:  entropyThreshold,
+			Keywords:    []string{"secret", "token", "pass", "pwd", "key", "auth", "credential"},
+		},
+	}
+}
+
+// compileSecretRules 合并内置规则与自定义规则，自定义规则同名时覆盖内置规则
+func compileSecretRules(cfg config.SecretsConfig) ([]SecretRule, error) {
+	rules := defaultSecretRules(cfg.EntropyThreshold)
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		index[r.ID] = i
+	}
+
+	for _, rc := range cfg.Rules {
+		pattern, err := regexp.Compile(rc.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret rule %s: %w", rc.ID, err)
+		}
+		if rc.SecretGroup > pattern.NumSubexp() {
+			return nil, fmt.Errorf("secret rule %s: secret_group %d out of range", rc.ID, rc.SecretGroup)
+		}
+		keywords := make([]string, 0, len(rc.Keywords))
+		for _, k := range rc.Keywords {
+			keywords = append(keywords, strings.ToLower(k))
+		}
+		rule := SecretRule{
+			ID:          rc.ID,
+			Description: rc.Description,
+			Severity:    firstNonEmpty(strings.ToLower(rc.Severity), SeverityMedium),
+			Pattern:     pattern,
+			SecretGroup: rc.SecretGroup,
+			MinEntropy:  rc.Entropy,
+			Keywords:    keywords,
+		}
+		if i, ok := index[rc.ID]; ok {
+			rules[i] = rule
+		} else {
+			index[rc.ID] = len(rules)
+			rules = append(rules, rule)
+		}
+	}
+
+	// 通用熵规则误报率最高，放到最后以便具体规则优先命中
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].ID != genericSecretRuleID && rules[j].ID == genericSecretRuleID
+	})
+	return rules, nil
+}
+
+// secretAllowlist 编译后的白名单
+type secretAllowlist struct {
+	paths     []*regexp.Regexp
+	regexes   []*regexp.Regexp
+	stopwords []string
+	commits   map[string]bool
+}
+
+func compileSecretAllowlist(cfg config.SecretsAllowlist) (*secretAllowlist, error) {
+	al := &secretAllowlist{commits: make(map[string]bool)}
+	for _, p := range cfg.Paths {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist path %q: %w", p, err)
+		}
+		al.paths = append(al.paths, re)
+	}
+	for _, p := range cfg.Regexes {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist regex %q: %w", p, err)
+		}
+		al.regexes = append(al.regexes, re)
+	}
+	for _, w := range cfg.Stopwords {
+		al.stopwords = append(al.stopwords, strings.ToLower(w))
+	}
+	for _, c := range cfg.Commits {
+		al.commits[c] = true
+	}
+	return al, nil
+}
+
+func (al *secretAllowlist) pathAllowed(path string) bool {
+	for _, re := range al.paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (al *secretAllowlist) secretAllowed(secret string) bool {
+	for _, re := range al.regexes {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	lower := strings.ToLower(secret)
+	for _, w := range al.stopwords {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretDetector 按规则逐行检测敏感信息
+type SecretDetector struct {
+	rules     []SecretRule
+	allowlist *secretAllowlist
+}
+
+// NewSecretDetector 根据配置编译规则与白名单
+func NewSecretDetector(cfg config.SecretsConfig) (*SecretDetector, error) {
+	rules, err := compileSecretRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := compileSecretAllowlist(cfg.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretDetector{rules: rules, allowlist: allowlist}, nil
+}
+
+// DetectLine 检测单行内容，path 与 lineNumber 仅用于填充发现项
+func (d *SecretDetector) DetectLine(path string, lineNumber int, line string) []SecretFinding {
+	if strings.Contains(line, allowMarker) {
+		return nil
+	}
+	lower := strings.ToLower(line)
+
+	var (
+		findings []SecretFinding
+		spans    [][2]int
+	)
+	for _, rule := range d.rules {
+		if len(rule.Keywords) > 0 && !containsAny(lower, rule.Keywords) {
+			continue
+		}
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(line, -1) {
+			start, end := m[2*rule.SecretGroup], m[2*rule.SecretGroup+1]
+			// 同一位置已被先前规则命中时不重复报告（如通用熵规则与具体规则重叠）
+			if start < 0 || overlaps(spans, start, end) {
+				continue
+			}
+			secret := line[start:end]
+			entropy := shannonEntropy(secret)
+			if rule.MinEntropy > 0 && entropy < rule.MinEntropy {
+				continue
+			}
+			if d.allowlist.secretAllowed(secret) {
+				continue
+			}
+			spans = append(spans, [2]int{start, end})
+			findings = append(findings, SecretFinding{
+				RuleID:      rule.ID,
+				Description: rule.Description,
+				Severity:    rule.Severity,
+				FilePath:    path,
+				LineNumber:  lineNumber,
+				Secret:      redactSecret(secret),
+				Fingerprint: secretFingerprint(rule.ID, secret),
+				Entropy:     math.Round(entropy*100) / 100,
+			})
+		}
+	}
+	return findings
+}
+
+func overlaps(spans [][2]int, start, end int) bool {
+	for _, sp := range spans {
+		if start < sp[1] && sp[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy 计算字符串的香农熵（bit/字符）
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactSecret 只保留前4个字符，其余以固定长度掩码替代，避免泄露长度
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "********"
+	}
+	return secret[:4] + "********"
+}
+
+// secretFingerprint 生成不可逆指纹，用于跨任务追踪同一密钥
+func secretFingerprint(ruleID, secret string) string {
+	sum := sha256.Sum256([]byte(ruleID + ":" + secret))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,382 @@
+package scanner_impl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// OptionHistoryDepth 任务级覆盖的 git 历史扫描深度
+const OptionHistoryDepth = "history_depth"
+
+// SecretsScanner 敏感信息扫描器
+type SecretsScanner struct {
+	*BaseScanner
+	detector config.SecretsConfig
+	workDir  string
+}
+
+// NewSecretsScanner 创建敏感信息扫描器
+func NewSecretsScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &SecretsScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeSecretsDetection)
+	s.detector, s.workDir = config.GetSecretsConfig()
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeSecretsDetection,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *SecretsScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	// 创建扫描结果
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeSecretsDetection, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	detector, err := NewSecretDetector(s.detector)
+	if err != nil {
+		return fail(err)
+	}
+	collector := newSecretCollector()
+
+	var opts domain.SecretsOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+
+	// 1. 单个上传文件直接扫描
+	if filePath := strings.TrimSpace(opts.FilePath); filePath != "" {
+		if err := s.scanFile(detector, collector, filePath, filepath.Base(filePath)); err != nil {
+			return fail(err)
+		}
+		result.SetSuccess(collector.result())
+		result.SetFindings(s.findingReport(collector.sarifFindings()))
+		return result, nil
+	}
+
+	// 2. 创建沙箱工作目录并准备源码
+	workDir, err := s.CreateWorkspace(s.workDir, task)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+		}
+	}()
+
+	historyDepth := s.detector.HistoryDepth
+	if opts.HistoryDepth != nil {
+		historyDepth = *opts.HistoryDepth
+	}
+	srcDir, err := s.CheckoutSourceWithHistory(ctx, task, workDir, historyDepth+1)
+	if err != nil {
+		return fail(err)
+	}
+
+	rev, err := s.ResolveRevision(ctx, task, srcDir)
+	if err != nil {
+		return fail(err)
+	}
+	result.Revision = rev
+
+	// 3. 扫描工作区，增量扫描时只扫描变更文件
+	if rev != nil && rev.Incremental {
+		for _, rel := range existingChanges(srcDir, rev) {
+			if inSkippedDir(rel) {
+				continue
+			}
+			if err := s.scanFile(detector, collector, filepath.Join(srcDir, filepath.FromSlash(rel)), rel); err != nil {
+				return fail(err)
+			}
+		}
+	} else if err := s.scanTree(ctx, detector, collector, srcDir); err != nil {
+		return fail(err)
+	}
+
+	// 4. 扫描提交历史中新增的内容，增量扫描时为基线之后的全部提交
+	switch {
+	case rev == nil:
+		if historyDepth > 0 {
+			s.logger.Info("source is not a git repository, skip history scan", zap.String("task_id", task.TaskID))
+		}
+	case rev.Incremental:
+		if err := s.scanHistory(ctx, task, detector, collector, srcDir, rev.BaseCommit+".."+rev.Commit); err != nil {
+			return fail(err)
+		}
+	case historyDepth > 0:
+		if err := s.scanHistory(ctx, task, detector, collector, srcDir, "-n", strconv.Itoa(historyDepth)); err != nil {
+			return fail(err)
+		}
+	}
+
+	s.logger.Info("secrets scan finished",
+		zap.String("task_id", task.TaskID),
+		zap.Int("files", collector.files),
+		zap.Int("commits", collector.commits),
+		zap.Int("findings", len(collector.findings)))
+
+	result.SetSuccess(collector.result())
+	result.SetFindings(s.findingReport(collector.sarifFindings()))
+	return result, nil
+}
+
+// scanTree 遍历工作区文件
+func (s *SecretsScanner) scanTree(ctx context.Context, detector *SecretDetector, collector *secretCollector, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return s.scanFile(detector, collector, path, relativePath(path, root))
+	})
+}
+
+// scanFile 扫描单个文件，跳过白名单路径、超大文件与二进制文件
+func (s *SecretsScanner) scanFile(detector *SecretDetector, collector *secretCollector, path, rel string) error {
+	if detector.allowlist.pathAllowed(rel) {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > int64(s.detector.MaxFileSizeKB)*1024 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if isBinary(data) {
+		return nil
+	}
+
+	collector.files++
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for line := 1; sc.Scan(); line++ {
+		collector.add(detector.DetectLine(rel, line, sc.Text()))
+	}
+	return sc.Err()
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// scanHistory 解析 git log -p 输出，只检测每个提交新增的行；selector 为提交数限制或提交范围
+func (s *SecretsScanner) scanHistory(ctx context.Context, task *domain.ScanTaskPayload, detector *SecretDetector, collector *secretCollector, srcDir string, selector ...string) error {
+	var stdout, stderr bytes.Buffer
+	args := []string{"-C", srcDir, "log", "-p", "-U0", "--no-color", "--no-ext-diff", "--format=commit %H"}
+	cmd := s.gitCommand(ctx, append(args, selector...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := s.ExecuteCommand(ctx, task, cmd, "history"); err != nil {
+		return fmt.Errorf("git log failed: %w: %s", err, truncate(stderr.String(), 512))
+	}
+
+	var (
+		commit string
+		file   string
+		line   int
+	)
+	sc := bufio.NewScanner(&stdout)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		text := sc.Text()
+		switch {
+		case strings.HasPrefix(text, "commit "):
+			commit = strings.TrimPrefix(text, "commit ")
+			file = ""
+			collector.commits++
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			if file == "/dev/null" || inSkippedDir(file) || detector.allowlist.pathAllowed(file) {
+				file = ""
+			}
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeaderPattern.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case strings.HasPrefix(text, "+") && file != "" && !detector.allowlist.commits[commit]:
+			findings := detector.DetectLine(file, line, text[1:])
+			for i := range findings {
+				findings[i].Commit = commit
+			}
+			collector.add(findings)
+			line++
+		}
+	}
+	return sc.Err()
+}
+
+// inSkippedDir 判断相对路径是否位于遍历时跳过的依赖目录中
+func inSkippedDir(rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		if skipDirs[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary 以前 8000 字节中是否包含 NUL 判断二进制文件，与 git 的判定方式一致
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// secretCollector 汇总发现项，同一文件中的同一密钥只保留首次出现（工作区优先于历史）
+type secretCollector struct {
+	findings []SecretFinding
+	seen     map[string]bool
+	files    int
+	commits  int
+}
+
+func newSecretCollector() *secretCollector {
+	return &secretCollector{findings: []SecretFinding{}, seen: make(map[string]bool)}
+}
+
+func (c *secretCollector) add(findings []SecretFinding) {
+	for _, f := range findings {
+		key := f.Fingerprint + "|" + f.FilePath
+		if c.seen[key] {
+			continue
+		}
+		c.seen[key] = true
+		c.findings = append(c.findings, f)
+	}
+}
+
+func (c *secretCollector) result() map[string]interface{} {
+	byRule := make(map[string]int)
+	bySeverity := make(map[string]int)
+	inHistory := 0
+	for _, f := range c.findings {
+		byRule[f.RuleID]++
+		bySeverity[f.Severity]++
+		if f.Commit != "" {
+			inHistory++
+		}
+	}
+	return map[string]interface{}{
+		"findings":        c.findings,
+		"files_scanned":   c.files,
+		"commits_scanned": c.commits,
+		"summary": map[string]interface{}{
+			"total":       len(c.findings),
+			"in_history":  inHistory,
+			"by_rule":     byRule,
+			"by_severity": bySeverity,
+		},
+	}
+}
+
+// sarifFindings 转换为统一发现项，密钥已脱敏，指纹沿用检测器计算的值
+func (c *secretCollector) sarifFindings() []domain.Finding {
+	out := make([]domain.Finding, 0, len(c.findings))
+	for _, f := range c.findings {
+		finding := domain.Finding{
+			RuleID:      f.RuleID,
+			RuleName:    f.RuleID,
+			Severity:    f.Severity,
+			Message:     f.Description,
+			Category:    "secret",
+			CWEID:       "CWE-798",
+			Location:    domain.FindingLocation{Path: f.FilePath, StartLine: f.LineNumber},
+			Fingerprint: f.Fingerprint,
+			Remediation: "Revoke and rotate the credential, then remove it from the repository history",
+			Properties:  map[string]interface{}{"secret": f.Secret},
+		}
+		if f.Commit != "" {
+			finding.Properties["commit"] = f.Commit
+		}
+		if f.Entropy > 0 {
+			finding.Properties["entropy"] = f.Entropy
+		}
+		out = append(out, finding)
+	}
+	return out
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *SecretsScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *SecretsScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *SecretsScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *SecretsScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口
+func (s *SecretsScanner) HealthCheck() error {
+	if _, err := NewSecretDetector(s.detector); err != nil {
+		return err
+	}
+	return s.BaseScanner.HealthCheck()
+}
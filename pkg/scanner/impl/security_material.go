@@ -0,0 +1,218 @@
+package scanner_impl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// MaterialHeader 安全物料文件头，同一类型下按 Name 区分规则包、按 Version 区分版本
+type MaterialHeader struct {
+	Type        string `yaml:"type" json:"type"`
+	Name        string `yaml:"name" json:"name"`
+	Version     string `yaml:"version" json:"version"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// MaterialRef 扫描结果中记录实际使用的规则包，便于追溯
+type MaterialRef struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+// materialFile 已读取的安全物料文件，正文按需解码为具体规则包
+type materialFile struct {
+	MaterialHeader
+	kind domain.SecurityMaterialType
+	path string
+	data []byte
+}
+
+// Ref 返回规则包引用
+func (f *materialFile) Ref() MaterialRef {
+	return MaterialRef{Type: f.kind.String(), Name: f.Name, Version: f.Version, Path: f.path}
+}
+
+// Decode 将文件正文解码为具体规则包（YAML，JSON 作为其子集同样支持）
+func (f *materialFile) Decode(out interface{}) error {
+	if err := yaml.Unmarshal(f.data, out); err != nil {
+		return fmt.Errorf("decode security material %s failed: %w", f.path, err)
+	}
+	return nil
+}
+
+// MaterialStore 按类型索引目录下的版本化安全物料文件
+type MaterialStore struct {
+	dir      string
+	loadedAt time.Time
+	files    map[domain.SecurityMaterialType][]*materialFile
+}
+
+// LoadMaterialStore 递归读取目录下的 *.yaml/*.yml/*.json 安全物料文件
+func LoadMaterialStore(dir string) (*MaterialStore, error) {
+	store := &MaterialStore{
+		dir:      dir,
+		loadedAt: time.Now(),
+		files:    make(map[domain.SecurityMaterialType][]*materialFile),
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f := &materialFile{data: data}
+		if err := yaml.Unmarshal(data, &f.MaterialHeader); err != nil {
+			return fmt.Errorf("parse security material %s failed: %w", path, err)
+		}
+		if f.kind, err = domain.ParseSecurityMaterialType(f.Type); err != nil {
+			return fmt.Errorf("security material %s: %w", path, err)
+		}
+		if f.Name == "" || f.Version == "" {
+			return fmt.Errorf("security material %s: name and version are required", path)
+		}
+		f.path, _ = filepath.Rel(dir, path)
+		f.path = filepath.ToSlash(f.path)
+		store.files[f.kind] = append(store.files[f.kind], f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Select 返回某类型下各规则包的选中版本：pinned 非空时只取该版本，否则取各规则包的最高版本
+func (s *MaterialStore) Select(kind domain.SecurityMaterialType, pinned string) ([]*materialFile, error) {
+	latest := make(map[string]*materialFile)
+	for _, f := range s.files[kind] {
+		if pinned != "" && f.Version != pinned {
+			continue
+		}
+		cur, ok := latest[f.Name]
+		if ok && compareVersions(f.Version, cur.Version) == 0 {
+			return nil, fmt.Errorf("duplicate security material %s %s@%s: %s and %s", kind, f.Name, f.Version, cur.path, f.path)
+		}
+		if !ok || compareVersions(f.Version, cur.Version) > 0 {
+			latest[f.Name] = f
+		}
+	}
+	if len(latest) == 0 {
+		if pinned != "" {
+			return nil, fmt.Errorf("no %s security material with version %s in %s", kind, pinned, s.dir)
+		}
+		return nil, fmt.Errorf("no %s security material in %s", kind, s.dir)
+	}
+
+	selected := make([]*materialFile, 0, len(latest))
+	for _, f := range latest {
+		selected = append(selected, f)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	return selected, nil
+}
+
+// selectNamedMaterials 按名称筛选规则包，names 为空时返回全部，存在未找到的名称时报错
+func selectNamedMaterials(files []*materialFile, names []string, what string) ([]*materialFile, error) {
+	if len(names) == 0 {
+		return files, nil
+	}
+	missing := make(map[string]bool, len(names))
+	for _, n := range names {
+		missing[n] = true
+	}
+	selected := make([]*materialFile, 0, len(names))
+	for _, f := range files {
+		if _, ok := missing[f.Name]; ok {
+			delete(missing, f.Name)
+			selected = append(selected, f)
+		}
+	}
+	if len(missing) > 0 {
+		unknown := make([]string, 0, len(missing))
+		for n := range missing {
+			unknown = append(unknown, n)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown %s: %s", what, strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// pinnedMaterialVersion 查找配置中固定的版本，viper 会将键转为小写，这里按物料类型解析后比较
+func pinnedMaterialVersion(versions map[string]string, kind domain.SecurityMaterialType) string {
+	for key, version := range versions {
+		if t, err := domain.ParseSecurityMaterialType(key); err == nil && t == kind {
+			return version
+		}
+	}
+	return ""
+}
+
+// materialStoreCache 在多次扫描间复用已加载的安全物料，文件更新后自动重新加载
+type materialStoreCache struct {
+	mu    sync.Mutex
+	store *MaterialStore
+}
+
+// get 返回最新的安全物料，目录下任一文件修改时间晚于加载时间时重新加载
+func (c *materialStoreCache) get(dir string) (*MaterialStore, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modTime, err := latestModTime(dir)
+	if err != nil {
+		return nil, fmt.Errorf("security materials unavailable: %w", err)
+	}
+	if c.store != nil && c.store.dir == dir && !modTime.After(c.store.loadedAt) {
+		return c.store, nil
+	}
+	store, err := LoadMaterialStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.store = store
+	return store, nil
+}
+
+func latestModTime(dir string) (time.Time, error) {
+	var latest time.Time
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest, err
+}
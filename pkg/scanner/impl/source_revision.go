@@ -0,0 +1,141 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"go.uber.org/zap"
+)
+
+// ResolveRevision 读取检出源码的当前提交；任务带有基线提交时计算两者之间变更（含删除）的文件
+// 源码不是 git 仓库时返回 nil；基线提交不在历史中（如强制推送后）时退化为全量扫描
+func (s *BaseScanner) ResolveRevision(ctx context.Context, task *domain.ScanTaskPayload, srcDir string) (*domain.SourceRevision, error) {
+	if _, err := os.Stat(filepath.Join(srcDir, ".git")); err != nil {
+		if task.BaseCommit != "" {
+			s.logger.Warn("source is not a git repository, base commit ignored",
+				zap.String("task_id", task.TaskID),
+				zap.String("base_commit", task.BaseCommit))
+		}
+		return nil, nil
+	}
+
+	head, err := s.gitOutput(ctx, task, nil, "-C", srcDir, "log", "-1", "--format=%H%x00%cI")
+	if err != nil {
+		return nil, fmt.Errorf("resolve head commit failed: %w", err)
+	}
+	commit, committed, _ := strings.Cut(strings.TrimSpace(head), "\x00")
+	rev := &domain.SourceRevision{Commit: commit}
+	if t, err := time.Parse(time.RFC3339, committed); err == nil {
+		rev.CommitTime = t
+	}
+
+	base := strings.TrimSpace(task.BaseCommit)
+	if base == "" {
+		return rev, nil
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base commit: %s", base)
+	}
+	// --batch-check 对不存在的对象输出 missing 而不是以非零状态退出，避免计入熔断
+	check, err := s.gitOutput(ctx, task, strings.NewReader(base+"^{commit}\n"), "-C", srcDir, "cat-file", "--batch-check")
+	if err != nil {
+		return nil, fmt.Errorf("check base commit failed: %w", err)
+	}
+	if strings.HasSuffix(strings.TrimSpace(check), " missing") {
+		s.logger.Warn("base commit not found in history, falling back to full scan",
+			zap.String("task_id", task.TaskID),
+			zap.String("base_commit", base))
+		return rev, nil
+	}
+
+	// 重命名按删除加新增处理，旧路径上的发现项才能被标记为已修复
+	diff, err := s.gitOutput(ctx, task, nil, "-C", srcDir, "diff", "--name-only", "-z", "--no-renames", base, commit)
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s..%s failed: %w", base, commit, err)
+	}
+	rev.BaseCommit = base
+	rev.Incremental = true
+	rev.ChangedFiles = []string{}
+	for _, path := range strings.Split(diff, "\x00") {
+		if path != "" {
+			rev.ChangedFiles = append(rev.ChangedFiles, path)
+		}
+	}
+	sort.Strings(rev.ChangedFiles)
+
+	s.logger.Info("incremental scan",
+		zap.String("task_id", task.TaskID),
+		zap.String("base_commit", base),
+		zap.String("commit", commit),
+		zap.Int("changed_files", len(rev.ChangedFiles)))
+	return rev, nil
+}
+
+// gitOutput 执行 git 命令并返回标准输出
+func (s *BaseScanner) gitOutput(ctx context.Context, task *domain.ScanTaskPayload, stdin io.Reader, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := s.gitCommand(ctx, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := s.ExecuteCommand(ctx, task, cmd, "revision"); err != nil {
+		return "", fmt.Errorf("%w: %s", err, truncate(stderr.String(), 512))
+	}
+	return stdout.String(), nil
+}
+
+// existingChanges 返回变更文件中仍存在于工作区的普通文件
+func existingChanges(srcDir string, rev *domain.SourceRevision) []string {
+	var files []string
+	for _, rel := range rev.ChangedFiles {
+		info, err := os.Lstat(filepath.Join(srcDir, filepath.FromSlash(rel)))
+		if err == nil && info.Mode().IsRegular() {
+			files = append(files, rel)
+		}
+	}
+	return files
+}
+
+// ScopeToChanges 将变更文件按原有相对路径复制到 workDir/changes，供只接受目录参数的扫描工具使用
+// 返回目录与复制的文件数，工具输出的路径仍是仓库相对路径
+func (s *BaseScanner) ScopeToChanges(srcDir, workDir string, rev *domain.SourceRevision) (string, int, error) {
+	scoped := filepath.Join(workDir, "changes")
+	if err := os.MkdirAll(scoped, 0o750); err != nil {
+		return "", 0, fmt.Errorf("create change set dir failed: %w", err)
+	}
+	files := existingChanges(srcDir, rev)
+	for _, rel := range files {
+		target, err := safeJoin(scoped, filepath.FromSlash(rel))
+		if err != nil {
+			return "", 0, err
+		}
+		if err := copyFile(filepath.Join(srcDir, filepath.FromSlash(rel)), target); err != nil {
+			return "", 0, fmt.Errorf("copy changed file %s failed: %w", rel, err)
+		}
+	}
+	if err := s.chownForProcess(scoped); err != nil {
+		return "", 0, fmt.Errorf("chown change set failed: %w", err)
+	}
+	return scoped, len(files), nil
+}
+
+func copyFile(src, target string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return writeFile(target, f, info.Size())
+}
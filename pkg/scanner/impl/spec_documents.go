@@ -0,0 +1,550 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 安全规范检查支持的配置类型
+const (
+	SpecKindDockerfile = "dockerfile" // Dockerfile / Containerfile
+	SpecKindKubernetes = "kubernetes" // 含 apiVersion 与 kind 的 YAML 清单
+	SpecKindTerraform  = "terraform"  // Terraform HCL（*.tf）
+	SpecKindCICD       = "cicd"       // 流水线配置：仓库中的 CI 文件与 RepositoryAsset.CICDConfig
+)
+
+// specNodeKind 文档树节点类型
+type specNodeKind int
+
+const (
+	specScalar specNodeKind = iota
+	specObject
+	specList
+)
+
+// specNode 配置文件解析后的通用文档树，各类文件统一为对象、数组与标量，保留行号用于定位
+type specNode struct {
+	kind   specNodeKind
+	value  interface{} // 标量：string、float64、bool 或 nil
+	keys   []string    // 对象键的原始顺序
+	fields map[string]*specNode
+	items  []*specNode
+	line   int
+}
+
+func newSpecObject(line int) *specNode {
+	return &specNode{kind: specObject, fields: make(map[string]*specNode), line: line}
+}
+
+func newSpecList(line int) *specNode {
+	return &specNode{kind: specList, line: line}
+}
+
+func newSpecScalar(v interface{}, line int) *specNode {
+	return &specNode{kind: specScalar, value: v, line: line}
+}
+
+// set 设置对象字段，重复键以后者为准
+func (n *specNode) set(key string, v *specNode) {
+	if _, ok := n.fields[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.fields[key] = v
+}
+
+// get 读取对象字段
+func (n *specNode) get(key string) *specNode {
+	if n == nil || n.kind != specObject {
+		return nil
+	}
+	return n.fields[key]
+}
+
+// isNull 节点是否为 null 标量
+func (n *specNode) isNull() bool {
+	return n.kind == specScalar && n.value == nil
+}
+
+// text 标量的字符串形式，整数值的数字不带小数部分
+func (n *specNode) text() (string, bool) {
+	if n.kind != specScalar {
+		return "", false
+	}
+	switch v := n.value.(type) {
+	case nil:
+		return "null", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// specDocument 参与评估的单个文档，如一个 Kubernetes 资源、一个 Terraform 块或一个 Dockerfile
+type specDocument struct {
+	Kind string
+	Path string // 源码相对路径，任务选项中的配置为空
+	Name string // 文档标识，如 Deployment/web、aws_s3_bucket.logs
+	Root *specNode
+}
+
+// detectSpecKind 按文件路径判断配置类型，普通 YAML 需解析后确认是否为 Kubernetes 清单
+func detectSpecKind(rel string) string {
+	base := path.Base(rel)
+	lower := strings.ToLower(base)
+	switch {
+	case lower == "dockerfile" || lower == "containerfile" ||
+		strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile"):
+		return SpecKindDockerfile
+	case strings.HasSuffix(lower, ".tf"):
+		return SpecKindTerraform
+	}
+	ext := path.Ext(lower)
+	if ext != ".yaml" && ext != ".yml" {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(rel, ".github/workflows/") && path.Dir(rel) == ".github/workflows",
+		rel == ".circleci/config.yml",
+		lower == ".gitlab-ci.yml", lower == ".drone.yml",
+		lower == "azure-pipelines.yml", lower == "bitbucket-pipelines.yml":
+		return SpecKindCICD
+	}
+	return SpecKindKubernetes
+}
+
+// parseSpecFile 按类型解析配置文件，不含 Kubernetes 资源的 YAML 返回空
+func parseSpecFile(kind, rel string, data []byte) ([]*specDocument, error) {
+	switch kind {
+	case SpecKindDockerfile:
+		root, err := parseDockerfile(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*specDocument{{Kind: kind, Path: rel, Name: rel, Root: root}}, nil
+	case SpecKindTerraform:
+		return parseTerraform(rel, data)
+	case SpecKindKubernetes:
+		nodes, err := parseYAMLDocuments(data)
+		if err != nil {
+			return nil, err
+		}
+		var docs []*specDocument
+		for _, n := range nodes {
+			apiVersion, _ := n.get("apiVersion").textOrEmpty()
+			resKind, _ := n.get("kind").textOrEmpty()
+			if apiVersion == "" || resKind == "" {
+				continue
+			}
+			name := resKind
+			if metaName, ok := n.get("metadata").get("name").textOrEmpty(); ok {
+				name += "/" + metaName
+			}
+			docs = append(docs, &specDocument{Kind: kind, Path: rel, Name: name, Root: n})
+		}
+		return docs, nil
+	case SpecKindCICD:
+		nodes, err := parseYAMLDocuments(data)
+		if err != nil {
+			return nil, err
+		}
+		docs := make([]*specDocument, 0, len(nodes))
+		for _, n := range nodes {
+			docs = append(docs, &specDocument{Kind: kind, Path: rel, Name: rel, Root: n})
+		}
+		return docs, nil
+	}
+	return nil, fmt.Errorf("unsupported spec kind %q", kind)
+}
+
+// textOrEmpty 读取非空字符串标量
+func (n *specNode) textOrEmpty() (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	s, ok := n.text()
+	if !ok || n.isNull() || s == "" {
+		return "", false
+	}
+	return s, true
+}
+
+// parseCICDConfig 解析任务选项中的 CICDConfig：资产中保存的 JSON 字符串或已解码的对象
+func parseCICDConfig(v interface{}) (*specNode, error) {
+	var data []byte
+	switch c := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(c) == "" {
+			return nil, nil
+		}
+		data = []byte(c)
+	default:
+		encoded, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("encode ci_cd_config: %w", err)
+		}
+		data = encoded
+	}
+	nodes, err := parseYAMLDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse ci_cd_config: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	if nodes[0].kind == specScalar {
+		// 资产接口将 CICDConfig 字符串再次编码为 JSON 保存，这里展开一层
+		if s, ok := nodes[0].value.(string); ok {
+			if raw, isRaw := v.(string); isRaw && strings.HasPrefix(strings.TrimSpace(raw), `"`) {
+				return parseCICDConfig(s)
+			}
+		}
+		return nil, errors.New("ci_cd_config is not an object")
+	}
+	return nodes[0], nil
+}
+
+// maxSpecDepth 文档树的最大嵌套深度，防止别名展开导致的递归过深
+const maxSpecDepth = 64
+
+// parseYAMLDocuments 解析多文档 YAML（JSON 作为其子集同样支持），跳过空文档
+func parseYAMLDocuments(data []byte) ([]*specNode, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var nodes []*specNode
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		n, err := convertYAMLNode(doc.Content[0], 0)
+		if err != nil {
+			return nil, err
+		}
+		if n.isNull() {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+}
+
+// convertYAMLNode 将 yaml.Node 转为文档树，展开别名与合并键（<<）
+func convertYAMLNode(n *yaml.Node, depth int) (*specNode, error) {
+	if depth > maxSpecDepth {
+		return nil, errors.New("yaml document is nested too deeply")
+	}
+	switch n.Kind {
+	case yaml.AliasNode:
+		return convertYAMLNode(n.Alias, depth+1)
+	case yaml.SequenceNode:
+		list := newSpecList(n.Line)
+		for _, c := range n.Content {
+			item, err := convertYAMLNode(c, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+		}
+		return list, nil
+	case yaml.MappingNode:
+		obj := newSpecObject(n.Line)
+		var merged []*specNode
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			val, err := convertYAMLNode(v, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if k.Tag == "!!merge" || (k.Value == "<<" && k.Tag != "!!str") {
+				if val.kind == specList {
+					merged = append(merged, val.items...)
+				} else {
+					merged = append(merged, val)
+				}
+				continue
+			}
+			obj.set(k.Value, val)
+		}
+		// 显式键优先于合并进来的键
+		for _, m := range merged {
+			if m.kind != specObject {
+				continue
+			}
+			for _, k := range m.keys {
+				if _, ok := obj.fields[k]; !ok {
+					obj.set(k, m.fields[k])
+				}
+			}
+		}
+		return obj, nil
+	default:
+		return newSpecScalar(yamlScalarValue(n), n.Line), nil
+	}
+}
+
+// yamlScalarValue 按解析出的标签转换标量
+func yamlScalarValue(n *yaml.Node) interface{} {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err == nil {
+			return b
+		}
+	case "!!int", "!!float":
+		var f float64
+		if err := n.Decode(&f); err == nil {
+			return f
+		}
+	}
+	return n.Value
+}
+
+// parseDockerfile 将 Dockerfile 解析为文档树：
+//
+//	instructions: 全部指令 {cmd, value, args, flags, vars, line}
+//	args:         首个 FROM 之前的 ARG
+//	stages:       构建阶段 {name, from, image, tag, digest, platform, stage_ref, user, instructions}
+//
+// user 为阶段内最后一条 USER 指令的取值（基于前序阶段构建时继承其取值），未设置时缺省
+func parseDockerfile(data []byte) (*specNode, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	root := newSpecObject(1)
+	all := newSpecList(1)
+	globalArgs := newSpecList(1)
+	stages := newSpecList(1)
+	stageUsers := make(map[string]string)
+	var stage *specNode
+
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i])
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		start := i + 1
+		// 续行：行尾反斜杠，续行之间的空行与注释行忽略
+		for strings.HasSuffix(text, `\`) && i+1 < len(lines) {
+			i++
+			next := strings.TrimSpace(lines[i])
+			if next == "" || strings.HasPrefix(next, "#") {
+				continue
+			}
+			text = strings.TrimSpace(strings.TrimSuffix(text, `\`)) + " " + next
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(text, `\`))
+
+		cmd, rest, _ := strings.Cut(text, " ")
+		cmd = strings.ToLower(cmd)
+		rest = strings.TrimSpace(rest)
+
+		// heredoc：RUN <<EOF ... EOF
+		if j := strings.Index(rest, "<<"); j >= 0 && (cmd == "run" || cmd == "copy" || cmd == "add") {
+			var word string
+			if f := strings.Fields(strings.TrimPrefix(rest[j+2:], "-")); len(f) > 0 {
+				word = strings.Trim(f[0], `"'`)
+			}
+			if word != "" && !strings.HasPrefix(word, "<") {
+				var body []string
+				for i+1 < len(lines) {
+					i++
+					if strings.TrimSpace(lines[i]) == word {
+						break
+					}
+					body = append(body, lines[i])
+				}
+				rest += "\n" + strings.Join(body, "\n")
+			}
+		}
+
+		inst := dockerInstruction(cmd, rest, start)
+		all.items = append(all.items, inst)
+
+		if cmd == "from" {
+			stage = dockerStage(inst, len(stages.items), stageUsers)
+			stages.items = append(stages.items, stage)
+			continue
+		}
+		if stage == nil {
+			if cmd == "arg" {
+				globalArgs.items = append(globalArgs.items, inst)
+			}
+			continue
+		}
+		stage.get("instructions").items = append(stage.get("instructions").items, inst)
+		if cmd == "user" {
+			user := newSpecScalar(rest, start)
+			stage.set("user", user)
+			stageUsers[strings.ToLower(stage.fields["name"].value.(string))] = rest
+		}
+	}
+	if len(all.items) == 0 {
+		return nil, errors.New("empty dockerfile")
+	}
+	if len(stages.items) == 0 {
+		return nil, errors.New("dockerfile has no FROM instruction")
+	}
+	root.set("instructions", all)
+	root.set("args", globalArgs)
+	root.set("stages", stages)
+	return root, nil
+}
+
+// dockerInstruction 解析单条指令的参数、--flag 与 ENV/ARG/LABEL 的键值
+func dockerInstruction(cmd, rest string, line int) *specNode {
+	inst := newSpecObject(line)
+	inst.set("cmd", newSpecScalar(cmd, line))
+	inst.set("value", newSpecScalar(rest, line))
+
+	flags := newSpecObject(line)
+	body := rest
+	for strings.HasPrefix(body, "--") {
+		tok, remain, _ := strings.Cut(body, " ")
+		name, val, hasVal := strings.Cut(strings.TrimPrefix(tok, "--"), "=")
+		if hasVal {
+			flags.set(name, newSpecScalar(val, line))
+		} else {
+			flags.set(name, newSpecScalar(true, line))
+		}
+		body = strings.TrimSpace(remain)
+	}
+	inst.set("flags", flags)
+
+	args := newSpecList(line)
+	var execForm []string
+	if strings.HasPrefix(body, "[") && json.Unmarshal([]byte(body), &execForm) == nil {
+		for _, a := range execForm {
+			args.items = append(args.items, newSpecScalar(a, line))
+		}
+	} else {
+		for _, a := range strings.Fields(body) {
+			args.items = append(args.items, newSpecScalar(a, line))
+		}
+	}
+	inst.set("args", args)
+
+	switch cmd {
+	case "env", "arg", "label":
+		inst.set("vars", dockerVars(cmd, body, line))
+	}
+	return inst
+}
+
+// dockerVars 解析 key=value 列表（值可带引号），兼容旧式 ENV key value
+func dockerVars(cmd, body string, line int) *specNode {
+	vars := newSpecObject(line)
+	if cmd == "env" && !strings.Contains(strings.SplitN(body, " ", 2)[0], "=") {
+		key, val, _ := strings.Cut(body, " ")
+		if key != "" {
+			vars.set(key, newSpecScalar(strings.TrimSpace(val), line))
+		}
+		return vars
+	}
+	for body != "" {
+		var key, val string
+		eq := strings.IndexAny(body, "= ")
+		if eq < 0 || body[eq] == ' ' {
+			// ARG name 不带默认值
+			if eq < 0 {
+				key, body = body, ""
+			} else {
+				key, body = body[:eq], strings.TrimSpace(body[eq:])
+			}
+			vars.set(key, newSpecScalar(nil, line))
+			continue
+		}
+		key, body = body[:eq], body[eq+1:]
+		if body != "" && (body[0] == '"' || body[0] == '\'') {
+			quote := body[0]
+			end := 1
+			for end < len(body) && (body[end] != quote || body[end-1] == '\\') {
+				end++
+			}
+			val = strings.ReplaceAll(body[1:min(end, len(body))], `\`+string(quote), string(quote))
+			body = body[min(end+1, len(body)):]
+		} else {
+			val, body, _ = strings.Cut(body, " ")
+		}
+		vars.set(key, newSpecScalar(val, line))
+		body = strings.TrimSpace(body)
+	}
+	return vars
+}
+
+// dockerStage 由 FROM 指令创建构建阶段，基于前序阶段时继承其 USER
+func dockerStage(from *specNode, index int, stageUsers map[string]string) *specNode {
+	line := from.line
+	stage := newSpecObject(line)
+	var ref, alias string
+	args := from.get("args").items
+	if len(args) > 0 {
+		ref, _ = args[0].text()
+	}
+	if len(args) >= 3 {
+		if as, _ := args[1].text(); strings.EqualFold(as, "as") {
+			alias, _ = args[2].text()
+		}
+	}
+	name := alias
+	if name == "" {
+		name = strconv.Itoa(index)
+	}
+	stage.set("name", newSpecScalar(name, line))
+	stage.set("from", newSpecScalar(ref, line))
+	if platform := from.get("flags").get("platform"); platform != nil {
+		stage.set("platform", platform)
+	}
+
+	if user, ok := stageUsers[strings.ToLower(ref)]; ok {
+		stage.set("stage_ref", newSpecScalar(true, line))
+		if user != "" {
+			stage.set("user", newSpecScalar(user, line))
+		}
+	} else {
+		stage.set("stage_ref", newSpecScalar(false, line))
+		image, tag, digest := splitImageRef(ref)
+		stage.set("image", newSpecScalar(image, line))
+		if tag != "" {
+			stage.set("tag", newSpecScalar(tag, line))
+		}
+		if digest != "" {
+			stage.set("digest", newSpecScalar(digest, line))
+		}
+	}
+	stage.set("instructions", newSpecList(line))
+	// 后续阶段可以按名称或序号引用本阶段
+	stageUsers[strings.ToLower(name)] = stageUsers[strings.ToLower(ref)]
+	return stage
+}
+
+// splitImageRef 拆分镜像引用 registry:port/repo:tag@digest
+func splitImageRef(ref string) (image, tag, digest string) {
+	image = ref
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	return image, tag, digest
+}
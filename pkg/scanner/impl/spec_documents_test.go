@@ -0,0 +1,197 @@
+package scanner_impl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func specTexts(nodes []*specNode) []string {
+	var out []string
+	for _, n := range nodes {
+		s, _ := n.text()
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestDetectSpecKind(t *testing.T) {
+	cases := map[string]string{
+		"Dockerfile":                    SpecKindDockerfile,
+		"build/Dockerfile.prod":         SpecKindDockerfile,
+		"images/api.dockerfile":         SpecKindDockerfile,
+		"infra/main.tf":                 SpecKindTerraform,
+		".github/workflows/release.yml": SpecKindCICD,
+		".gitlab-ci.yml":                SpecKindCICD,
+		".circleci/config.yml":          SpecKindCICD,
+		"deploy/app.yaml":               SpecKindKubernetes,
+		".github/workflows/x/a.yml":     SpecKindKubernetes,
+		"main.go":                       "",
+	}
+	for rel, want := range cases {
+		assert.Equal(t, want, detectSpecKind(rel), rel)
+	}
+}
+
+func TestParseDockerfile(t *testing.T) {
+	root, err := parseDockerfile([]byte(`# syntax=docker/dockerfile:1
+ARG BASE=debian:12
+FROM ${BASE} AS base
+USER app
+ENV A=1 \
+    B="two words"
+RUN <<EOF
+apt-get update
+EOF
+
+FROM base
+RUN ["echo", "hi"]
+FROM --platform=linux/amd64 nginx:1.27@sha256:abc AS web
+`))
+	require.NoError(t, err)
+
+	stages := root.get("stages")
+	require.Len(t, stages.items, 3)
+	assert.Equal(t, []string{"${BASE}", "base", "nginx:1.27@sha256:abc"}, specTexts(mustResolve(t, root, "stages[*].from")))
+	assert.Equal(t, []string{"app"}, specTexts(mustResolve(t, root, "stages[1].user"))) // 继承 base 阶段的 USER
+	assert.Empty(t, mustResolve(t, root, "stages[-1].user"))
+	assert.Equal(t, []string{"nginx", "1.27", "sha256:abc", "linux/amd64"},
+		specTexts(mustResolve(t, root, "stages[2]", "image", "tag", "digest", "platform")))
+	assert.Equal(t, []string{"true"}, specTexts(mustResolve(t, root, "stages[1].stage_ref")))
+
+	assert.Equal(t, []string{"debian:12"}, specTexts(mustResolve(t, root, "args[0].vars.BASE")))
+	assert.Equal(t, []string{"1", "two words"}, specTexts(mustResolve(t, root, "stages[0].instructions[*].vars.*")))
+	heredoc := mustResolve(t, root, "stages[0].instructions[-1].value")
+	require.Len(t, heredoc, 1)
+	assert.Contains(t, specTexts(heredoc)[0], "apt-get update")
+	assert.Equal(t, []string{"echo", "hi"}, specTexts(mustResolve(t, root, "stages[1].instructions[-1].args[*]")))
+	assert.Equal(t, 7, mustResolve(t, root, "stages[0].instructions[-1]")[0].line)
+}
+
+func TestParseTerraform(t *testing.T) {
+	docs, err := parseTerraform("main.tf", []byte(`# comment
+variable "region" { default = "us-east-1" }
+
+resource "aws_instance" "web" {
+  ami           = data.aws_ami.ubuntu.id
+  count         = 2
+  tags          = { Name = "web-${var.env}", "team" = "sec" }
+  /* block comment */
+  user_data     = <<-EOT
+    #!/bin/bash
+    echo ok
+  EOT
+  ebs_block_device {
+    encrypted = false
+  }
+  ebs_block_device {
+    encrypted = true
+  }
+  lifecycle { ignore_changes = [tags] }
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+`))
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, []string{"variable.region", "aws_instance.web", "data.aws_ami.ubuntu"},
+		[]string{docs[0].Name, docs[1].Name, docs[2].Name})
+
+	web := docs[1].Root
+	assert.Equal(t, []string{"resource", "aws_instance", "web"}, specTexts(mustResolve(t, web, "", "block", "type", "name")))
+	assert.Equal(t, []string{"data.aws_ami.ubuntu.id", "2", "web-${var.env}", "sec"},
+		specTexts(mustResolve(t, web, "config", "ami", "count", "tags.Name", `tags["team"]`)))
+	assert.Equal(t, []string{"false", "true"}, specTexts(mustResolve(t, web, "config.ebs_block_device[*].encrypted")))
+	assert.Equal(t, []string{"tags"}, specTexts(mustResolve(t, web, "config.lifecycle[0].ignore_changes[*]")))
+	assert.Contains(t, specTexts(mustResolve(t, web, "config.user_data"))[0], "echo ok")
+	assert.Equal(t, 14, mustResolve(t, web, "config.ebs_block_device[0].encrypted")[0].line)
+
+	_, err = parseTerraform("bad.tf", []byte("resource \"x\" \"y\" {\n  a = [1, 2\n}\n"))
+	assert.Error(t, err)
+}
+
+func TestSpecPath(t *testing.T) {
+	nodes, err := parseYAMLDocuments([]byte(`
+base: &base {image: "nginx:1.27", env: {DB_PASSWORD: x, API_KEY: y, MODE: prod}}
+jobs:
+  a:
+    <<: *base
+    steps: [{uses: "actions/checkout@v4"}, {run: make}]
+  "b.c":
+    image: alpine
+`))
+	require.NoError(t, err)
+	root := nodes[0]
+
+	assert.Equal(t, []string{"nginx:1.27"}, specTexts(mustResolve(t, root, "jobs.a.image"))) // 合并键
+	assert.Equal(t, []string{"alpine"}, specTexts(mustResolve(t, root, `jobs["b.c"].image`)))
+	assert.ElementsMatch(t, []string{"nginx:1.27", "nginx:1.27", "alpine"}, specTexts(mustResolve(t, root, "**.image")))
+	assert.Equal(t, []string{"make"}, specTexts(mustResolve(t, root, "jobs.a.steps[-1].run")))
+	assert.ElementsMatch(t, []string{"x", "y", "x", "y"}, specTexts(mustResolve(t, root, `**[~"(?i)(password|key)$"]`)))
+	assert.Equal(t, []string{"nginx:1.27", "alpine"}, specTexts(mustResolve(t, root, "jobs.*.image")))
+
+	for _, bad := range []string{"a..b", "a[1", `a[~"(["]`, "a[x]", "a.[0]"} {
+		_, err := compileSpecPath(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSpecCheck(t *testing.T) {
+	nodes, err := parseYAMLDocuments([]byte(`{ports: [22, 443], user: root, caps: [NET_ADMIN], password: hunter2}`))
+	require.NoError(t, err)
+	root := nodes[0]
+
+	eval := func(a SpecAssertion) bool {
+		t.Helper()
+		c, err := compileSpecCheck(a)
+		require.NoError(t, err)
+		ok, _ := c.eval(root)
+		return ok
+	}
+	assert.True(t, eval(SpecAssertion{Path: "user", Op: "exists"}))
+	assert.False(t, eval(SpecAssertion{Path: "group", Op: "equals", Value: "root"})) // 缺失路径不满足肯定断言
+	assert.True(t, eval(SpecAssertion{Path: "group", Op: "not_equals", Value: "root"}))
+	assert.True(t, eval(SpecAssertion{Path: "ports[*]", Op: "contains", Value: 443}))
+	assert.False(t, eval(SpecAssertion{Path: "ports[*]", Op: "lt", Value: 100}))
+	assert.True(t, eval(SpecAssertion{Path: "ports[*]", Op: "gte", Value: 22}))
+	assert.False(t, eval(SpecAssertion{Path: "caps[*]", Op: "not_in", Value: []interface{}{"SYS_ADMIN", "NET_ADMIN"}}))
+	assert.True(t, eval(SpecAssertion{Any: []SpecAssertion{
+		{Path: "user", Op: "not_equals", Value: "root"},
+		{Path: "ports[*]", Op: "not_contains", Value: 80},
+	}}))
+
+	c, err := compileSpecCheck(SpecAssertion{Path: "password", Op: "absent"})
+	require.NoError(t, err)
+	_, failures := c.eval(root)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "password = ******", failures[0].describe(true))
+	assert.Equal(t, `password = "hunter2"`, failures[0].describe(false))
+
+	_, err = compileSpecCheck(SpecAssertion{Path: "user", Op: "like"})
+	assert.Error(t, err)
+	_, err = compileSpecCheck(SpecAssertion{Path: "user", Op: "matches", Value: "("})
+	assert.Error(t, err)
+}
+
+// mustResolve 依次解析 base 下的各路径，首个路径之后的路径相对于首个路径的结果
+func mustResolve(t *testing.T, root *specNode, base string, rest ...string) []*specNode {
+	t.Helper()
+	p, err := compileSpecPath(base)
+	require.NoError(t, err)
+	nodes := p.resolve(root)
+	if len(rest) == 0 {
+		return nodes
+	}
+	var out []*specNode
+	for _, n := range nodes {
+		for _, raw := range rest {
+			sub, err := compileSpecPath(raw)
+			require.NoError(t, err)
+			out = append(out, sub.resolve(n)...)
+		}
+	}
+	return out
+}
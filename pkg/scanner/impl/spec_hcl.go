@@ -0,0 +1,512 @@
+package scanner_impl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseTerraform 将 Terraform 文件的每个顶层块解析为一个文档：
+//
+//	{block, labels, type, name, config}
+//
+// resource/data 块的 type、name 为两个标签，其余块的 name 为第一个标签；
+// config 为块体：属性按值解析，无法静态求值的表达式保留原文，嵌套块按类型组成数组，块标签记在 "@labels"
+func parseTerraform(rel string, data []byte) ([]*specDocument, error) {
+	p := &hclParser{src: data}
+	p.indexLines()
+	body, err := p.parseBody(true)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*specDocument
+	for _, b := range body.blocks {
+		root := newSpecObject(b.line)
+		root.set("block", newSpecScalar(b.typ, b.line))
+		labels := newSpecList(b.line)
+		for _, l := range b.labels {
+			labels.items = append(labels.items, newSpecScalar(l, b.line))
+		}
+		root.set("labels", labels)
+		name := b.typ
+		switch {
+		case (b.typ == "resource" || b.typ == "data") && len(b.labels) >= 2:
+			root.set("type", newSpecScalar(b.labels[0], b.line))
+			root.set("name", newSpecScalar(b.labels[1], b.line))
+			name = b.labels[0] + "." + b.labels[1]
+			if b.typ == "data" {
+				name = "data." + name
+			}
+		case len(b.labels) > 0:
+			root.set("name", newSpecScalar(b.labels[0], b.line))
+			name = b.typ + "." + b.labels[0]
+		}
+		root.set("config", b.body.node())
+		docs = append(docs, &specDocument{Kind: SpecKindTerraform, Path: rel, Name: name, Root: root})
+	}
+	return docs, nil
+}
+
+// hclBody HCL 块体
+type hclBody struct {
+	line   int
+	attrs  []string
+	values map[string]*specNode
+	blocks []*hclBlock
+}
+
+type hclBlock struct {
+	typ    string
+	labels []string
+	body   *hclBody
+	line   int
+}
+
+// node 将块体转为文档树，同类型的嵌套块按出现顺序组成数组
+func (b *hclBody) node() *specNode {
+	obj := newSpecObject(b.line)
+	for _, k := range b.attrs {
+		obj.set(k, b.values[k])
+	}
+	for _, blk := range b.blocks {
+		child := blk.body.node()
+		child.line = blk.line
+		if len(blk.labels) > 0 {
+			labels := newSpecList(blk.line)
+			for _, l := range blk.labels {
+				labels.items = append(labels.items, newSpecScalar(l, blk.line))
+			}
+			child.set("@labels", labels)
+		}
+		list := obj.get(blk.typ)
+		if list == nil || list.kind != specList {
+			list = newSpecList(blk.line)
+			obj.set(blk.typ, list)
+		}
+		list.items = append(list.items, child)
+	}
+	return obj
+}
+
+// hclParser 面向安全规范检查的 HCL 原生语法解析器：解析块、属性与字面量（字符串、数字、布尔、null、
+// 元组、对象、heredoc），引用、函数调用与运算表达式保留原文
+type hclParser struct {
+	src        []byte
+	pos        int
+	lineStarts []int
+}
+
+func (p *hclParser) indexLines() {
+	p.lineStarts = []int{0}
+	for i, c := range p.src {
+		if c == '\n' {
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
+}
+
+func (p *hclParser) lineAt(pos int) int {
+	return sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > pos })
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.lineAt(p.pos), fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace 跳过空白与注释；newlines 为 false 时在换行处停止
+func (p *hclParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n':
+			if !newlines {
+				return
+			}
+			p.pos++
+		case c == '#' || (c == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/'):
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '*':
+			end := bytes.Index(p.src[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func isHCLIdentByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && (c == '-' || (c >= '0' && c <= '9')))
+}
+
+func (p *hclParser) ident() string {
+	start := p.pos
+	for !p.eof() && isHCLIdentByte(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// parseBody 解析块体直到 '}'（顶层为文件结尾）
+func (p *hclParser) parseBody(top bool) (*hclBody, error) {
+	body := &hclBody{line: p.lineAt(p.pos), values: make(map[string]*specNode)}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			if top {
+				return body, nil
+			}
+			return nil, p.errorf("unexpected end of file, expecting '}'")
+		}
+		if p.peek() == '}' {
+			if top {
+				return nil, p.errorf("unexpected '}'")
+			}
+			p.pos++
+			return body, nil
+		}
+
+		line := p.lineAt(p.pos)
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("unexpected character %q", p.peek())
+		}
+		p.skipSpace(false)
+
+		if p.peek() == '=' {
+			p.pos++
+			val, err := p.parseExpr("}")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := body.values[name]; !ok {
+				body.attrs = append(body.attrs, name)
+			}
+			body.values[name] = val
+			continue
+		}
+
+		blk := &hclBlock{typ: name, line: line}
+		for {
+			p.skipSpace(false)
+			switch c := p.peek(); {
+			case c == '"':
+				label, err := p.quoted()
+				if err != nil {
+					return nil, err
+				}
+				blk.labels = append(blk.labels, label)
+				continue
+			case isHCLIdentByte(c, true):
+				blk.labels = append(blk.labels, p.ident())
+				continue
+			case c == '{':
+				p.pos++
+			default:
+				return nil, p.errorf("expecting '=' or '{' after %q", name)
+			}
+			break
+		}
+		inner, err := p.parseBody(false)
+		if err != nil {
+			return nil, err
+		}
+		inner.line = line
+		blk.body = inner
+		body.blocks = append(body.blocks, blk)
+	}
+}
+
+// parseExpr 解析表达式；字面量以外的表达式保留原文，stops 为当前上下文中结束表达式的字符
+func (p *hclParser) parseExpr(stops string) (*specNode, error) {
+	p.skipSpace(false)
+	start := p.pos
+	line := p.lineAt(p.pos)
+	val, err := p.parseLiteral()
+	if err == nil && val != nil {
+		p.skipSpace(false)
+		if p.eof() || strings.IndexByte(stops, p.peek()) >= 0 || p.peek() == '\n' {
+			return val, nil
+		}
+	}
+	// 非字面量或字面量参与运算：按括号与字符串配对读取原文
+	p.pos = start
+	raw, err := p.rawExpr(stops)
+	if err != nil {
+		return nil, err
+	}
+	return newSpecScalar(raw, line), nil
+}
+
+// parseLiteral 解析字面量，不是字面量时返回 nil
+func (p *hclParser) parseLiteral() (*specNode, error) {
+	line := p.lineAt(p.pos)
+	switch c := p.peek(); {
+	case c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return newSpecScalar(s, line), nil
+	case c == '<' && bytes.HasPrefix(p.src[p.pos:], []byte("<<")):
+		s, ok := p.heredoc()
+		if !ok {
+			return nil, nil
+		}
+		return newSpecScalar(s, line), nil
+	case c == '[':
+		p.pos++
+		list := newSpecList(line)
+		for {
+			p.skipSpace(true)
+			if p.peek() == ']' {
+				p.pos++
+				return list, nil
+			}
+			if p.eof() {
+				return nil, p.errorf("unterminated tuple")
+			}
+			item, err := p.parseExpr(",]")
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+			p.skipSpace(true)
+			if p.peek() == ',' {
+				p.pos++
+			}
+		}
+	case c == '{':
+		p.pos++
+		obj := newSpecObject(line)
+		for {
+			p.skipSpace(true)
+			if p.peek() == '}' {
+				p.pos++
+				return obj, nil
+			}
+			if p.eof() {
+				return nil, p.errorf("unterminated object")
+			}
+			var key string
+			if p.peek() == '"' {
+				k, err := p.quoted()
+				if err != nil {
+					return nil, err
+				}
+				key = k
+			} else {
+				key = p.ident()
+			}
+			p.skipSpace(false)
+			if key == "" || (p.peek() != '=' && p.peek() != ':') {
+				// for 表达式或计算键，整体按原文处理
+				return nil, nil
+			}
+			p.pos++
+			val, err := p.parseExpr(",}")
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, val)
+			p.skipSpace(true)
+			if p.peek() == ',' {
+				p.pos++
+			}
+		}
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-", p.peek()) >= 0 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+		if err != nil {
+			return nil, nil
+		}
+		return newSpecScalar(f, line), nil
+	case isHCLIdentByte(c, true):
+		start := p.pos
+		word := p.ident()
+		// 标识符后紧跟 . ( [ 为引用或函数调用
+		if !p.eof() && strings.IndexByte(".([", p.peek()) >= 0 {
+			p.pos = start
+			return nil, nil
+		}
+		switch word {
+		case "true":
+			return newSpecScalar(true, line), nil
+		case "false":
+			return newSpecScalar(false, line), nil
+		case "null":
+			return newSpecScalar(nil, line), nil
+		}
+		p.pos = start
+	}
+	return nil, nil
+}
+
+// quoted 读取带引号的字符串，${...} 插值与 %{...} 指令保留原文
+func (p *hclParser) quoted() (string, error) {
+	p.pos++ // 开头的引号
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(e)
+			}
+			p.pos++
+		case (c == '$' || c == '%') && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+			start := p.pos
+			p.pos += 2
+			if err := p.skipBalanced('}'); err != nil {
+				return "", err
+			}
+			sb.Write(p.src[start:p.pos])
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// heredoc 读取 <<EOT / <<-EOT 字符串
+func (p *hclParser) heredoc() (string, bool) {
+	start := p.pos
+	p.pos += 2
+	indent := false
+	if p.peek() == '-' {
+		indent = true
+		p.pos++
+	}
+	marker := p.ident()
+	if marker == "" || p.peek() != '\n' && p.peek() != '\r' {
+		p.pos = start
+		return "", false
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+	p.pos++
+	var lines []string
+	for !p.eof() {
+		line := string(p.src[p.pos:])
+		next := len(p.src)
+		if end := bytes.IndexByte(p.src[p.pos:], '\n'); end >= 0 {
+			line, next = string(p.src[p.pos:p.pos+end]), p.pos+end+1
+		}
+		if strings.TrimSpace(line) == marker {
+			// 结束标记之后的换行属于属性分隔，不在这里消费
+			p.pos += len(line)
+			break
+		}
+		p.pos = next
+		if indent {
+			line = strings.TrimLeft(line, " \t")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// skipBalanced 跳过直到与之配对的 close，期间识别嵌套括号与字符串
+func (p *hclParser) skipBalanced(close byte) error {
+	stack := []byte{close}
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			if _, err := p.quoted(); err != nil {
+				return err
+			}
+			continue
+		case '(':
+			stack = append(stack, ')')
+		case '[':
+			stack = append(stack, ']')
+		case '{':
+			stack = append(stack, '}')
+		case ')', ']', '}':
+			if c != stack[len(stack)-1] {
+				return p.errorf("unbalanced %q", c)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				p.pos++
+				return nil
+			}
+		}
+		p.pos++
+	}
+	return p.errorf("unterminated expression, expecting %q", close)
+}
+
+// rawExpr 读取表达式原文，直到括号外的换行或 stops 中的字符
+func (p *hclParser) rawExpr(stops string) (string, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '\n' || strings.IndexByte(stops, c) >= 0:
+			return strings.TrimSpace(string(p.src[start:p.pos])), nil
+		case c == '#' || (c == '/' && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '/' || p.src[p.pos+1] == '*')):
+			raw := strings.TrimSpace(string(p.src[start:p.pos]))
+			p.skipSpace(false)
+			if p.eof() || p.peek() == '\n' || strings.IndexByte(stops, p.peek()) >= 0 {
+				return raw, nil
+			}
+		case c == '"':
+			if _, err := p.quoted(); err != nil {
+				return "", err
+			}
+		case c == '(' || c == '[' || c == '{':
+			p.pos++
+			close := map[byte]byte{'(': ')', '[': ']', '{': '}'}[c]
+			if err := p.skipBalanced(close); err != nil {
+				return "", err
+			}
+		case c == '<' && bytes.HasPrefix(p.src[p.pos:], []byte("<<")):
+			if _, ok := p.heredoc(); !ok {
+				p.pos += 2
+			}
+		default:
+			p.pos++
+		}
+	}
+	return strings.TrimSpace(string(p.src[start:p.pos])), nil
+}
@@ -0,0 +1,708 @@
+package scanner_impl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+)
+
+// SecuritySpec 安全规范（SecuritySpec 类型安全物料），以声明式规则描述配置文件必须满足的条件
+type SecuritySpec struct {
+	MaterialHeader `yaml:",inline"`
+	Rules          []SpecRule `yaml:"rules"`
+}
+
+// SpecRule 安全规范规则：
+//
+//	kinds   适用的配置类型（dockerfile、kubernetes、terraform、cicd）
+//	filter  文档级条件，全部满足时规则适用于该文档
+//	select  评估对象的路径，为空时评估文档本身，每个选中的节点单独评估
+//	when    评估对象的前置条件，全部满足时才检查 assert
+//	assert  评估对象必须满足的断言，任一不满足即产生违规
+type SpecRule struct {
+	ID          string          `yaml:"id"`
+	Title       string          `yaml:"title"`
+	Description string          `yaml:"description,omitempty"`
+	Severity    string          `yaml:"severity"`
+	Category    string          `yaml:"category,omitempty"`
+	CWE         string          `yaml:"cwe,omitempty"`
+	Remediation string          `yaml:"remediation,omitempty"`
+	Redact      bool            `yaml:"redact,omitempty"` // 证据中隐藏取值，用于凭据类规则
+	Kinds       []string        `yaml:"kinds"`
+	Filter      []SpecAssertion `yaml:"filter,omitempty"`
+	Select      []string        `yaml:"select,omitempty"`
+	When        []SpecAssertion `yaml:"when,omitempty"`
+	Assert      []SpecAssertion `yaml:"assert"`
+}
+
+// SpecAssertion 路径断言，或由 any 给出的一组断言（任一满足即可）
+//
+// 路径以 . 分隔字段，[n] 取数组元素（负数从末尾计），[*] 或 * 取全部元素或字段值，** 取自身及全部后代，
+// 含特殊字符的字段名写作 ["name"]，[~"regex"] 取键名匹配正则的字段；路径为空时指评估对象本身。
+// exists/absent 判断路径是否存在非 null 的值，contains/not_contains 判断取到的值中是否有等于 value 的；
+// 其余操作对取到的每个值都必须成立，路径不存在时肯定形式（equals、in、matches、比较）不成立，否定形式成立
+type SpecAssertion struct {
+	Path  string          `yaml:"path,omitempty"`
+	Op    string          `yaml:"op,omitempty"`
+	Value interface{}     `yaml:"value,omitempty"`
+	Any   []SpecAssertion `yaml:"any,omitempty"`
+}
+
+// 断言操作
+const (
+	SpecOpExists      = "exists"
+	SpecOpAbsent      = "absent"
+	SpecOpContains    = "contains"
+	SpecOpNotContains = "not_contains"
+	SpecOpEquals      = "equals"
+	SpecOpNotEquals   = "not_equals"
+	SpecOpIn          = "in"
+	SpecOpNotIn       = "not_in"
+	SpecOpMatches     = "matches"
+	SpecOpNotMatches  = "not_matches"
+	SpecOpGT          = "gt"
+	SpecOpGTE         = "gte"
+	SpecOpLT          = "lt"
+	SpecOpLTE         = "lte"
+)
+
+// SpecViolation 违反安全规范的配置
+type SpecViolation struct {
+	Spec        string `json:"spec"` // 规则所属规范 name@version
+	RuleID      string `json:"rule_id"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category,omitempty"`
+	CWE         string `json:"cwe,omitempty"`
+	Kind        string `json:"kind"`
+	Path        string `json:"path,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Resource    string `json:"resource"`
+	Evidence    string `json:"evidence"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// specPathStep 路径中的一步
+type specPathStep struct {
+	field     string
+	keyRegex  *regexp.Regexp
+	index     int
+	hasIndex  bool
+	wildcard  bool
+	recursive bool
+}
+
+type specPath struct {
+	raw   string
+	steps []specPathStep
+}
+
+// compileSpecPath 解析路径表达式
+func compileSpecPath(raw string) (specPath, error) {
+	p := specPath{raw: raw}
+	s := strings.TrimSpace(raw)
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "."):
+			s = s[1:]
+			if s == "" || s[0] == '.' || s[0] == '[' {
+				return p, fmt.Errorf("invalid path %q: empty field", raw)
+			}
+			continue
+		case strings.HasPrefix(s, `[~"`):
+			end := strings.Index(s, `"]`)
+			if end < 0 {
+				return p, fmt.Errorf("invalid path %q: unterminated key pattern", raw)
+			}
+			re, err := regexp.Compile(s[3:end])
+			if err != nil {
+				return p, fmt.Errorf("invalid path %q: %w", raw, err)
+			}
+			p.steps = append(p.steps, specPathStep{keyRegex: re})
+			s = s[end+2:]
+		case strings.HasPrefix(s, `["`):
+			end := strings.Index(s, `"]`)
+			if end < 0 {
+				return p, fmt.Errorf("invalid path %q: unterminated quoted field", raw)
+			}
+			p.steps = append(p.steps, specPathStep{field: s[2:end]})
+			s = s[end+2:]
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return p, fmt.Errorf("invalid path %q: unterminated index", raw)
+			}
+			idx := strings.TrimSpace(s[1:end])
+			if idx == "*" {
+				p.steps = append(p.steps, specPathStep{wildcard: true})
+			} else {
+				n, err := strconv.Atoi(idx)
+				if err != nil {
+					return p, fmt.Errorf("invalid path %q: bad index %q", raw, idx)
+				}
+				p.steps = append(p.steps, specPathStep{index: n, hasIndex: true})
+			}
+			s = s[end+1:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			switch field := s[:end]; field {
+			case "**":
+				p.steps = append(p.steps, specPathStep{recursive: true})
+			case "*":
+				p.steps = append(p.steps, specPathStep{wildcard: true})
+			default:
+				p.steps = append(p.steps, specPathStep{field: field})
+			}
+			s = s[end:]
+		}
+		if s != "" && s[0] != '.' && s[0] != '[' {
+			return p, fmt.Errorf("invalid path %q", raw)
+		}
+	}
+	return p, nil
+}
+
+// resolve 返回路径在 node 下取到的全部节点
+func (p specPath) resolve(node *specNode) []*specNode {
+	cur := []*specNode{node}
+	for _, step := range p.steps {
+		var next []*specNode
+		for _, n := range cur {
+			switch {
+			case step.recursive:
+				next = appendDescendants(next, n)
+			case step.keyRegex != nil:
+				if n.kind != specObject {
+					continue
+				}
+				for _, k := range n.keys {
+					if step.keyRegex.MatchString(k) {
+						next = append(next, n.fields[k])
+					}
+				}
+			case step.wildcard:
+				switch n.kind {
+				case specObject:
+					for _, k := range n.keys {
+						next = append(next, n.fields[k])
+					}
+				case specList:
+					next = append(next, n.items...)
+				}
+			case step.hasIndex:
+				if n.kind != specList {
+					continue
+				}
+				i := step.index
+				if i < 0 {
+					i += len(n.items)
+				}
+				if i >= 0 && i < len(n.items) {
+					next = append(next, n.items[i])
+				}
+			default:
+				if v := n.get(step.field); v != nil {
+					next = append(next, v)
+				}
+			}
+		}
+		cur = next
+	}
+	return cur
+}
+
+func appendDescendants(out []*specNode, n *specNode) []*specNode {
+	out = append(out, n)
+	switch n.kind {
+	case specObject:
+		for _, k := range n.keys {
+			out = appendDescendants(out, n.fields[k])
+		}
+	case specList:
+		for _, item := range n.items {
+			out = appendDescendants(out, item)
+		}
+	}
+	return out
+}
+
+// specCheck 编译后的断言
+type specCheck struct {
+	path   specPath
+	op     string
+	values []string // equals/in 的取值
+	number float64
+	re     *regexp.Regexp
+	any    []*specCheck
+}
+
+// compileSpecCheck 校验并编译断言
+func compileSpecCheck(a SpecAssertion) (*specCheck, error) {
+	if len(a.Any) > 0 {
+		if a.Path != "" || a.Op != "" {
+			return nil, errors.New("assertion with any cannot also set path or op")
+		}
+		c := &specCheck{}
+		for _, sub := range a.Any {
+			compiled, err := compileSpecCheck(sub)
+			if err != nil {
+				return nil, err
+			}
+			c.any = append(c.any, compiled)
+		}
+		return c, nil
+	}
+
+	path, err := compileSpecPath(a.Path)
+	if err != nil {
+		return nil, err
+	}
+	c := &specCheck{path: path, op: a.Op}
+	switch a.Op {
+	case SpecOpExists, SpecOpAbsent:
+	case SpecOpEquals, SpecOpNotEquals, SpecOpContains, SpecOpNotContains:
+		if a.Value == nil {
+			return nil, fmt.Errorf("%s on %q requires a value", a.Op, a.Path)
+		}
+		c.values = []string{specValueText(a.Value)}
+	case SpecOpIn, SpecOpNotIn:
+		list, ok := a.Value.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("%s on %q requires a non-empty list value", a.Op, a.Path)
+		}
+		for _, v := range list {
+			c.values = append(c.values, specValueText(v))
+		}
+	case SpecOpMatches, SpecOpNotMatches:
+		pattern, ok := a.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s on %q requires a regular expression", a.Op, a.Path)
+		}
+		if c.re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%s on %q: %w", a.Op, a.Path, err)
+		}
+	case SpecOpGT, SpecOpGTE, SpecOpLT, SpecOpLTE:
+		n, ok := specNumber(a.Value)
+		if !ok {
+			return nil, fmt.Errorf("%s on %q requires a number", a.Op, a.Path)
+		}
+		c.number = n
+	default:
+		return nil, fmt.Errorf("unknown assertion op %q", a.Op)
+	}
+	return c, nil
+}
+
+// specValueText 规则中取值的字符串形式，与 specNode.text 一致
+func specValueText(v interface{}) string {
+	switch t := v.(type) {
+	case int:
+		return strconv.Itoa(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// specNumber 将规则取值或配置中的标量转为数值，字符串形式的数字同样支持
+func specNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// specFailure 不成立的断言：路径、取到的值（未取到时为空）与定位节点
+type specFailure struct {
+	path string
+	node *specNode
+	at   *specNode
+}
+
+// describe 证据文本，redact 时隐藏取值
+func (f specFailure) describe(redact bool) string {
+	name := f.path
+	if name == "" {
+		name = "value"
+	}
+	switch {
+	case f.node == nil:
+		return name + " is not set"
+	case redact && f.node.kind == specScalar:
+		return name + " = ******"
+	}
+	return name + " = " + specNodeSummary(f.node)
+}
+
+// eval 评估断言，不成立时返回原因；any 组全部不成立时返回每个分支的原因
+func (c *specCheck) eval(subject *specNode) (bool, []specFailure) {
+	if len(c.any) > 0 {
+		var failures []specFailure
+		for _, sub := range c.any {
+			ok, f := sub.eval(subject)
+			if ok {
+				return true, nil
+			}
+			failures = append(failures, f...)
+		}
+		return false, failures
+	}
+
+	var values []*specNode
+	for _, n := range c.path.resolve(subject) {
+		if !n.isNull() {
+			values = append(values, n)
+		}
+	}
+	missing := []specFailure{{path: c.path.raw, at: subject}}
+	switch c.op {
+	case SpecOpExists:
+		return len(values) > 0, missing
+	case SpecOpAbsent:
+		if len(values) > 0 {
+			return false, []specFailure{{path: c.path.raw, node: values[0], at: values[0]}}
+		}
+		return true, nil
+	case SpecOpContains, SpecOpNotContains:
+		for _, v := range values {
+			if text, ok := v.text(); ok && text == c.values[0] {
+				if c.op == SpecOpNotContains {
+					return false, []specFailure{{path: c.path.raw, node: v, at: v}}
+				}
+				return true, nil
+			}
+		}
+		if c.op == SpecOpContains {
+			f := missing[0]
+			if len(values) > 0 {
+				f.at = values[0]
+			}
+			return false, []specFailure{{path: c.path.raw + " contains " + strconv.Quote(c.values[0]), at: f.at}}
+		}
+		return true, nil
+	}
+	if len(values) == 0 {
+		switch c.op {
+		case SpecOpNotEquals, SpecOpNotIn, SpecOpNotMatches:
+			return true, nil
+		}
+		return false, missing
+	}
+	for _, v := range values {
+		if !c.holds(v) {
+			return false, []specFailure{{path: c.path.raw, node: v, at: v}}
+		}
+	}
+	return true, nil
+}
+
+// holds 单个值是否满足断言；对象与数组只满足否定形式的断言
+func (c *specCheck) holds(n *specNode) bool {
+	text, scalar := n.text()
+	switch c.op {
+	case SpecOpEquals, SpecOpIn:
+		return scalar && containsString(c.values, text)
+	case SpecOpNotEquals, SpecOpNotIn:
+		return !scalar || !containsString(c.values, text)
+	case SpecOpMatches:
+		return scalar && c.re.MatchString(text)
+	case SpecOpNotMatches:
+		return !scalar || !c.re.MatchString(text)
+	}
+	v, ok := specNumber(n.value)
+	if !scalar || !ok {
+		return false
+	}
+	switch c.op {
+	case SpecOpGT:
+		return v > c.number
+	case SpecOpGTE:
+		return v >= c.number
+	case SpecOpLT:
+		return v < c.number
+	case SpecOpLTE:
+		return v <= c.number
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// specNodeSummary 证据中展示的取值，过长的标量截断
+func specNodeSummary(n *specNode) string {
+	switch n.kind {
+	case specObject:
+		return "{...}"
+	case specList:
+		return "[...]"
+	}
+	text, _ := n.text()
+	if s, ok := n.value.(string); ok {
+		text = strconv.Quote(truncateRunes(s, 120))
+	}
+	return text
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
+
+// compiledSpecRule 编译后的规则
+type compiledSpecRule struct {
+	spec   MaterialRef
+	rule   SpecRule
+	kinds  map[string]bool
+	filter []*specCheck
+	selors []specPath
+	when   []*specCheck
+	assert []*specCheck
+}
+
+// SpecEngine 加载的全部安全规范规则
+type SpecEngine struct {
+	specs []MaterialRef
+	rules []*compiledSpecRule
+}
+
+// LoadSecuritySpecs 加载 names 指定的安全规范（为空时加载全部），pinned 非空时只使用该版本
+func LoadSecuritySpecs(store *MaterialStore, names []string, pinned string) (*SpecEngine, error) {
+	files, err := store.Select(domain.SecurityMaterialTypeSecuritySpec, pinned)
+	if err != nil {
+		return nil, err
+	}
+	files, err = selectNamedMaterials(files, names, "security specs")
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &SpecEngine{}
+	seen := make(map[string]string)
+	for _, f := range files {
+		var spec SecuritySpec
+		if err := f.Decode(&spec); err != nil {
+			return nil, err
+		}
+		ref := f.Ref()
+		for _, r := range spec.Rules {
+			if prev, ok := seen[r.ID]; ok {
+				return nil, fmt.Errorf("security material %s: duplicate rule %s (also in %s)", f.path, r.ID, prev)
+			}
+			seen[r.ID] = f.path
+			compiled, err := compileSpecRule(ref, r)
+			if err != nil {
+				return nil, fmt.Errorf("security material %s: rule %s: %w", f.path, r.ID, err)
+			}
+			engine.rules = append(engine.rules, compiled)
+		}
+		engine.specs = append(engine.specs, ref)
+	}
+	if len(engine.rules) == 0 {
+		return nil, errors.New("no security spec rules found")
+	}
+	return engine, nil
+}
+
+// compileSpecRule 校验规则并编译路径与断言
+func compileSpecRule(ref MaterialRef, r SpecRule) (*compiledSpecRule, error) {
+	if r.ID == "" || r.Title == "" {
+		return nil, errors.New("id and title are required")
+	}
+	if domain.NormalizeSeverity(r.Severity) == "" {
+		return nil, fmt.Errorf("invalid severity %q", r.Severity)
+	}
+	if len(r.Kinds) == 0 {
+		return nil, errors.New("kinds is required")
+	}
+	if len(r.Assert) == 0 {
+		return nil, errors.New("assert is required")
+	}
+	c := &compiledSpecRule{spec: ref, rule: r, kinds: make(map[string]bool)}
+	c.rule.Severity = domain.NormalizeSeverity(r.Severity)
+	for _, k := range r.Kinds {
+		switch k {
+		case SpecKindDockerfile, SpecKindKubernetes, SpecKindTerraform, SpecKindCICD:
+			c.kinds[k] = true
+		default:
+			return nil, fmt.Errorf("unknown kind %q", k)
+		}
+	}
+	for _, s := range r.Select {
+		p, err := compileSpecPath(s)
+		if err != nil {
+			return nil, err
+		}
+		c.selors = append(c.selors, p)
+	}
+	var err error
+	if c.filter, err = compileSpecChecks(r.Filter); err != nil {
+		return nil, err
+	}
+	if c.when, err = compileSpecChecks(r.When); err != nil {
+		return nil, err
+	}
+	if c.assert, err = compileSpecChecks(r.Assert); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func compileSpecChecks(list []SpecAssertion) ([]*specCheck, error) {
+	out := make([]*specCheck, 0, len(list))
+	for _, a := range list {
+		c, err := compileSpecCheck(a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func allHold(checks []*specCheck, n *specNode) bool {
+	for _, c := range checks {
+		if ok, _ := c.eval(n); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Kinds 规则涉及的配置类型
+func (e *SpecEngine) Kinds() map[string]bool {
+	kinds := make(map[string]bool)
+	for _, r := range e.rules {
+		for k := range r.kinds {
+			kinds[k] = true
+		}
+	}
+	return kinds
+}
+
+// Evaluate 对文档评估全部适用规则，每个评估对象只报告第一个不成立的断言
+func (e *SpecEngine) Evaluate(doc *specDocument) []SpecViolation {
+	var out []SpecViolation
+	for _, r := range e.rules {
+		if !r.kinds[doc.Kind] || !allHold(r.filter, doc.Root) {
+			continue
+		}
+		subjects := []*specNode{doc.Root}
+		if len(r.selors) > 0 {
+			subjects = subjects[:0]
+			for _, p := range r.selors {
+				subjects = append(subjects, p.resolve(doc.Root)...)
+			}
+		}
+		for _, subject := range subjects {
+			if subject.isNull() || !allHold(r.when, subject) {
+				continue
+			}
+			for _, c := range r.assert {
+				ok, failures := c.eval(subject)
+				if ok {
+					continue
+				}
+				line := subject.line
+				if at := failures[0].at; at != nil && at.line > 0 {
+					line = at.line
+				}
+				evidence := make([]string, 0, len(failures))
+				for _, f := range failures {
+					evidence = append(evidence, f.describe(r.rule.Redact))
+				}
+				out = append(out, SpecViolation{
+					Spec:        r.spec.Name + "@" + r.spec.Version,
+					RuleID:      r.rule.ID,
+					Title:       r.rule.Title,
+					Severity:    r.rule.Severity,
+					Category:    r.rule.Category,
+					CWE:         r.rule.CWE,
+					Kind:        doc.Kind,
+					Path:        doc.Path,
+					Line:        line,
+					Resource:    doc.Name,
+					Evidence:    strings.Join(evidence, "; "),
+					Remediation: r.rule.Remediation,
+				})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// summarizeSpecViolations 按严重等级与规则统计违规
+func summarizeSpecViolations(violations []SpecViolation) map[string]interface{} {
+	bySeverity := make(map[string]int)
+	byRule := make(map[string]int)
+	for _, v := range violations {
+		bySeverity[v.Severity]++
+		byRule[v.RuleID]++
+	}
+	return map[string]interface{}{
+		"violations":  len(violations),
+		"by_severity": bySeverity,
+		"by_rule":     byRule,
+	}
+}
+
+// sortSpecViolations 按文件、行号与规则排序，使结果稳定
+func sortSpecViolations(violations []SpecViolation) {
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.RuleID < b.RuleID
+	})
+}
+
+// specFindings 将违规转换为统一发现项
+func specFindings(violations []SpecViolation) []domain.Finding {
+	out := make([]domain.Finding, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, domain.Finding{
+			RuleID:      v.RuleID,
+			RuleName:    v.Title,
+			Severity:    v.Severity,
+			Message:     fmt.Sprintf("%s: %s (%s)", v.Title, v.Evidence, v.Resource),
+			Category:    v.Category,
+			CWEID:       v.CWE,
+			Location:    domain.FindingLocation{Path: v.Path, StartLine: v.Line, Logical: v.Resource, LogicalKind: v.Kind},
+			Remediation: v.Remediation,
+			Properties: map[string]interface{}{
+				"spec":     v.Spec,
+				"evidence": v.Evidence,
+			},
+		})
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+package scanner_impl
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// SecuritySpecScanner 安全规范检查，按声明式规则检查 Dockerfile、Kubernetes 清单、Terraform 与流水线配置
+type SecuritySpecScanner struct {
+	*BaseScanner
+	check     config.SecuritySpecConfig
+	materials config.SecurityMaterialConfig
+	workDir   string
+	store     materialStoreCache
+}
+
+// NewSecuritySpecScanner 创建安全规范检查执行器
+func NewSecuritySpecScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &SecuritySpecScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeSecuritySpecCheck)
+	s.check, s.materials, s.workDir = config.GetSecuritySpecConfig()
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeSecuritySpecCheck,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// specParseError 无法解析的配置文件，不影响其它文件的检查
+type specParseError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// specCollector 汇总解析出的文档
+type specCollector struct {
+	engine      *SpecEngine
+	kinds       map[string]bool
+	maxSize     int64
+	files       int
+	documents   map[string]int
+	violations  []SpecViolation
+	parseErrors []specParseError
+}
+
+// Scan 实现扫描接口
+func (s *SecuritySpecScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	result := domain.NewScanResult(task.TaskID, domain.ScanTypeSecuritySpecCheck, task.AssetID, task.AssetType)
+	fail := func(err error) (*domain.ScanResult, error) {
+		result.SetFailed(err.Error())
+		return result, err
+	}
+
+	var opts domain.SecuritySpecOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return fail(err)
+	}
+	cicd, err := parseCICDConfig(opts.CICDConfig)
+	if err != nil {
+		return fail(err)
+	}
+	hasSource := strings.TrimSpace(opts.RepoURL) != "" || strings.TrimSpace(opts.SourcePath) != "" || strings.TrimSpace(opts.ArchivePath) != ""
+	if !hasSource && cicd == nil {
+		return fail(fmt.Errorf("missing %s, %s or ci_cd_config in task options", OptionRepoURL, OptionSourcePath))
+	}
+
+	// 1. 加载安全规范
+	engine, err := s.loadEngine(opts.Specs, opts.SpecVersion)
+	if err != nil {
+		return fail(err)
+	}
+	collector := &specCollector{
+		engine:    engine,
+		kinds:     engine.Kinds(),
+		maxSize:   int64(s.check.MaxFileSizeKB) * 1024,
+		documents: make(map[string]int),
+	}
+
+	// 2. 检查源码中的配置文件，增量扫描时只检查变更文件
+	if hasSource {
+		workDir, err := s.CreateWorkspace(s.workDir, task)
+		if err != nil {
+			return fail(err)
+		}
+		defer func() {
+			if err := os.RemoveAll(workDir); err != nil {
+				s.logger.Warn("remove workspace failed", zap.String("dir", workDir), zap.Error(err))
+			}
+		}()
+
+		srcDir, err := s.CheckoutSource(ctx, task, workDir)
+		if err != nil {
+			return fail(err)
+		}
+		rev, err := s.ResolveRevision(ctx, task, srcDir)
+		if err != nil {
+			return fail(err)
+		}
+		result.Revision = rev
+
+		if rev != nil && rev.Incremental {
+			for _, rel := range existingChanges(srcDir, rev) {
+				if inSkippedDir(rel) {
+					continue
+				}
+				if err := collector.checkFile(filepath.Join(srcDir, filepath.FromSlash(rel)), rel); err != nil {
+					return fail(err)
+				}
+			}
+		} else if err := collector.checkTree(ctx, srcDir); err != nil {
+			return fail(err)
+		}
+	}
+
+	// 3. 检查资产登记的流水线配置
+	if cicd != nil {
+		collector.add(&specDocument{Kind: SpecKindCICD, Name: "ci_cd_config", Root: cicd})
+	}
+	sortSpecViolations(collector.violations)
+
+	summary := summarizeSpecViolations(collector.violations)
+	summary["files"] = collector.files
+	summary["documents"] = collector.documents
+	s.logger.Info("security spec check finished",
+		zap.String("task_id", task.TaskID),
+		zap.Int("files", collector.files),
+		zap.Int("violations", len(collector.violations)),
+		zap.Int("parse_errors", len(collector.parseErrors)))
+
+	result.SetSuccess(map[string]interface{}{
+		"specs":        engine.specs,
+		"violations":   collector.violations,
+		"parse_errors": collector.parseErrors,
+		"summary":      summary,
+	})
+	result.SetFindings(s.findingReport(specFindings(collector.violations)))
+	return result, nil
+}
+
+// loadEngine 加载任务指定的安全规范，未指定时使用配置；版本以任务选项优先
+func (s *SecuritySpecScanner) loadEngine(specs []string, version string) (*SpecEngine, error) {
+	store, err := s.store.get(s.materials.MaterialDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		specs = s.check.Specs
+	}
+	if version == "" {
+		version = pinnedMaterialVersion(s.materials.Versions, domain.SecurityMaterialTypeSecuritySpec)
+	}
+	return LoadSecuritySpecs(store, specs, version)
+}
+
+// checkTree 遍历源码目录
+func (c *specCollector) checkTree(ctx context.Context, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return c.checkFile(path, relativePath(path, root))
+	})
+}
+
+// checkFile 解析并检查单个文件，跳过不适用的类型与超大文件；
+// 普通 YAML 可能是 Helm 模板等非清单文件，解析失败时不记录
+func (c *specCollector) checkFile(path, rel string) error {
+	kind := detectSpecKind(rel)
+	if kind == "" || !c.kinds[kind] {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > c.maxSize {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	docs, err := parseSpecFile(kind, rel, data)
+	if err != nil {
+		if kind != SpecKindKubernetes {
+			c.parseErrors = append(c.parseErrors, specParseError{Path: rel, Error: err.Error()})
+		}
+		return nil
+	}
+	if len(docs) > 0 {
+		c.files++
+	}
+	for _, doc := range docs {
+		c.add(doc)
+	}
+	return nil
+}
+
+// add 评估单个文档
+func (c *specCollector) add(doc *specDocument) {
+	c.documents[doc.Kind]++
+	c.violations = append(c.violations, c.engine.Evaluate(doc)...)
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *SecuritySpecScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *SecuritySpecScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *SecuritySpecScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *SecuritySpecScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口，校验安全规范可加载
+func (s *SecuritySpecScanner) HealthCheck() error {
+	if _, err := s.loadEngine(nil, ""); err != nil {
+		return fmt.Errorf("security specs unavailable: %w", err)
+	}
+	return s.BaseScanner.HealthCheck()
+}
@@ -0,0 +1,90 @@
+package scanner_impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// ThreatModelingScanner 威胁建模，按 STRIDE 规则识别需求或设计文档中的威胁
+type ThreatModelingScanner struct {
+	*BaseScanner
+	materials config.SecurityMaterialConfig
+	store     materialStoreCache
+}
+
+// NewThreatModelingScanner 创建威胁建模扫描器
+func NewThreatModelingScanner(
+	timeoutCtrl *scanner.TimeoutController,
+	logger *zap.Logger,
+	config *config.Config,
+	opts ...BaseScannerOption,
+) scanner.TaskExecutor {
+	s := &ThreatModelingScanner{}
+
+	// 从配置获取参数
+	resourceProfile, securityConfig, timeout := config.GetScannerConfig(domain.ScanTypeThreatModeling)
+	s.materials = config.GetThreatModelingConfig()
+
+	baseOpts := []BaseScannerOption{
+		WithResourceProfile(resourceProfile),
+		WithSecurityProfile(int(securityConfig.RunAsUser), int(securityConfig.RunAsGroup), !securityConfig.AllowPrivilegeEscalation),
+		WithTimeout(timeout, 30*time.Second),
+		WithCircuitBreaker(config),
+	}
+	baseOpts = append(baseOpts, opts...)
+
+	s.BaseScanner = NewBaseScanner(
+		domain.ScanTypeThreatModeling,
+		timeoutCtrl,
+		logger,
+		config,
+		baseOpts...,
+	)
+	return s
+}
+
+// Scan 实现扫描接口
+func (s *ThreatModelingScanner) Scan(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return runRiskAnalysis(ctx, s.BaseScanner, &s.store, s.materials, task, riskAnalysisFields...)
+}
+
+// AsyncExecute 实现TaskExecutor接口
+func (s *ThreatModelingScanner) AsyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (string, error) {
+	return s.BaseScanner.AsyncExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// SyncExecute 实现 TaskExecutor 接口
+func (s *ThreatModelingScanner) SyncExecute(ctx context.Context, task *domain.ScanTaskPayload) (*domain.ScanResult, error) {
+	return s.BaseScanner.ExecuteWithResult(ctx, task, func(ctx context.Context) (*domain.ScanResult, error) {
+		return s.Scan(ctx, task)
+	})
+}
+
+// Cancel 实现TaskExecutor接口
+func (s *ThreatModelingScanner) Cancel(handle string) error {
+	return s.BaseScanner.CancelExecution(handle)
+}
+
+// GetStatus 实现TaskExecutor接口
+func (s *ThreatModelingScanner) GetStatus(handle string) (domain.TaskStatus, error) {
+	return s.BaseScanner.ExecutionStatus(handle)
+}
+
+// HealthCheck 实现TaskExecutor接口，校验规则包可加载
+func (s *ThreatModelingScanner) HealthCheck() error {
+	store, err := s.store.get(s.materials.MaterialDir)
+	if err != nil {
+		return err
+	}
+	if _, err := NewRiskAnalyzer(store, s.materials); err != nil {
+		return err
+	}
+	return s.BaseScanner.HealthCheck()
+}
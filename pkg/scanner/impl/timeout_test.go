@@ -0,0 +1,119 @@
+package scanner_impl
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTimeoutTestScanner(t *testing.T, timeout time.Duration) (*BaseScanner, *scanner.TimeoutController) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Scanner.ProcessSandbox.WorkDir = t.TempDir()
+	tc := scanner.NewTimeoutController(nil)
+	tc.SetPolicy(scanner.TimeoutPolicy{CriticalGrace: 10 * time.Millisecond, MinSamples: 1, MinTimeout: time.Millisecond})
+	bs := NewBaseScanner(domain.ScanTypeSca, tc, zap.NewNop(), cfg,
+		WithTimeout(timeout, 100*time.Millisecond),
+		WithCircuitBreaker(cfg),
+	)
+	return bs, tc
+}
+
+func TestExecuteCommand_HardTimeout(t *testing.T) {
+	bs, _ := newTimeoutTestScanner(t, 300*time.Millisecond)
+	task := &domain.ScanTaskPayload{TaskID: "timeout-cmd", ScanType: domain.ScanTypeSca}
+
+	// 进程忽略 SIGTERM，优雅停止期后被强制终止
+	cmd := exec.Command("sh", "-c", "trap '' TERM; sleep 30")
+	start := time.Now()
+	err := bs.ExecuteCommand(context.Background(), task, cmd, "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, scanner.ErrCriticalTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	transient, critical := bs.circuitBreaker.GetFailureCount()
+	assert.Equal(t, uint32(1), transient)
+	assert.Zero(t, critical)
+}
+
+func TestExecuteWithTimeout_CriticalTimeout(t *testing.T) {
+	bs, _ := newTimeoutTestScanner(t, 50*time.Millisecond)
+	task := &domain.ScanTaskPayload{TaskID: "timeout-fn", ScanType: domain.ScanTypeSca}
+
+	// 任务不响应取消，到达严重超时后升级并按严重错误计入熔断
+	release := make(chan struct{})
+	defer close(release)
+	err := bs.ExecuteWithTimeout(context.Background(), task, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, scanner.ErrCriticalTimeout)
+
+	_, critical := bs.circuitBreaker.GetFailureCount()
+	assert.Equal(t, uint32(1), critical)
+}
+
+func TestExecuteWithResult_ObservesDuration(t *testing.T) {
+	bs, tc := newTimeoutTestScanner(t, time.Hour)
+	task := &domain.ScanTaskPayload{TaskID: "history", ScanType: domain.ScanTypeSca}
+	run := func(scanFunc func() *domain.ScanResult) {
+		_, err := bs.ExecuteWithResult(context.Background(), task, func(ctx context.Context) (*domain.ScanResult, error) {
+			time.Sleep(20 * time.Millisecond)
+			return scanFunc(), nil
+		})
+		require.NoError(t, err)
+	}
+
+	// 没有产出结果的执行不计入历史样本
+	run(func() *domain.ScanResult { return nil })
+	run(func() *domain.ScanResult {
+		return domain.NewScanResult(task.TaskID, domain.ScanTypeSca, "", domain.AssetTypeRepository)
+	})
+	assert.Equal(t, time.Hour, tc.Deadline(task, time.Hour, scanner.TargetSize{}).Hard)
+
+	// 成功耗时成为历史样本，后续任务的硬超时按 p95 收紧
+	run(func() *domain.ScanResult {
+		result := domain.NewScanResult(task.TaskID, domain.ScanTypeSca, "", domain.AssetTypeRepository)
+		result.SetSuccess(map[string]interface{}{})
+		return result
+	})
+	d := tc.Deadline(task, time.Hour, scanner.TargetSize{})
+	assert.Less(t, d.Hard, time.Second)
+	assert.GreaterOrEqual(t, d.Hard, 20*time.Millisecond)
+}
+
+func TestExecuteCommand_WatchdogStall(t *testing.T) {
+	bs, tc := newTimeoutTestScanner(t, time.Hour)
+	tc.SetWatchdogSettings(scanner.WatchdogSettings{SilenceTimeout: 50 * time.Millisecond, StallTimeout: 100 * time.Millisecond})
+	task := &domain.ScanTaskPayload{TaskID: "watchdog-stall", ScanType: domain.ScanTypeSca}
+
+	// 进程既不退出也没有进展，由看门狗而非硬超时终止
+	done := make(chan error, 1)
+	go func() {
+		done <- bs.ExecuteCommand(context.Background(), task, exec.Command("sleep", "30"), "")
+	}()
+
+	var err error
+	require.Eventually(t, func() bool {
+		tc.Watchdog().Check()
+		select {
+		case err = <-done:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 30*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "stalled")
+	assert.Empty(t, bs.WatchedProcesses())
+}
@@ -0,0 +1,386 @@
+package scanner_impl
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// 扫描任务选项中约定的目标描述字段
+const (
+	OptionRepoURL     = "repo_url"     // 代码仓库地址（RepositoryAsset.RepoURL）
+	OptionBranch      = "branch"       // 分支（RepositoryAsset.Branch）
+	OptionCommit      = "commit"       // 提交哈希（RepositoryAsset.LastCommitHash）
+	OptionSourcePath  = "source_path"  // 已落盘的源码目录，存在时跳过检出
+	OptionArchivePath = "archive_path" // 已下载到本地的上传文件归档（zip/tar/tar.gz）
+	OptionFilePath    = "file_path"    // 已下载到本地的单个上传文件
+)
+
+const (
+	defaultBranch = "main"
+	// maxExtractSize 归档解压总大小上限，防止解压炸弹
+	maxExtractSize int64 = 2 << 30
+)
+
+// CreateWorkspace 在 root 下为任务创建独立的沙箱工作目录
+// root 为空时使用系统临时目录，调用方负责在扫描结束后删除
+func (s *BaseScanner) CreateWorkspace(root string, task *domain.ScanTaskPayload) (string, error) {
+	if root == "" {
+		root = os.TempDir()
+	}
+	// 降权运行的扫描进程需要穿越根目录访问自己的工作目录
+	rootMode := os.FileMode(0o750)
+	cred, _ := s.processCredential()
+	if cred != nil {
+		rootMode = 0o751
+	}
+	if err := os.MkdirAll(root, rootMode); err != nil {
+		return "", fmt.Errorf("create workspace root failed: %w", err)
+	}
+
+	prefix := fmt.Sprintf("%s-%s-", strings.ToLower(s.scanType.String()), sanitizePathComponent(task.TaskID))
+	dir, err := os.MkdirTemp(root, prefix)
+	if err != nil {
+		return "", fmt.Errorf("create workspace failed: %w", err)
+	}
+	if cred != nil {
+		if err := os.Chown(dir, int(cred.Uid), int(cred.Gid)); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("chown workspace failed: %w", err)
+		}
+	}
+	return dir, nil
+}
+
+// chownForProcess 将服务进程写入的文件移交给降权后的扫描进程用户
+func (s *BaseScanner) chownForProcess(dir string) error {
+	cred, _ := s.processCredential()
+	if cred == nil {
+		return nil
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, int(cred.Uid), int(cred.Gid))
+	})
+}
+
+// CheckoutSource 准备扫描源码，返回源码所在目录
+// 优先使用 source_path 选项，其次解压 archive_path；否则按 repo_url/branch/commit 将仓库检出到 workDir/src
+func (s *BaseScanner) CheckoutSource(ctx context.Context, task *domain.ScanTaskPayload, workDir string) (string, error) {
+	return s.CheckoutSourceWithHistory(ctx, task, workDir, 1)
+}
+
+// CheckoutSourceWithHistory 同 CheckoutSource，但未指定提交时保留最近 depth 个提交的历史
+// depth <= 0 表示完整克隆
+func (s *BaseScanner) CheckoutSourceWithHistory(ctx context.Context, task *domain.ScanTaskPayload, workDir string, depth int) (string, error) {
+	srcDir, err := s.checkoutSource(ctx, task, workDir, depth)
+	if err != nil {
+		return "", err
+	}
+	// 后续扫描命令按源码大小放宽超时
+	s.SetTargetSize(task, scanner.TargetSize{Bytes: dirSize(srcDir)})
+	return srcDir, nil
+}
+
+// resolveUploadPath 解析任务选项中的本地路径，解析符号链接后必须位于上传根目录下
+func (s *BaseScanner) resolveUploadPath(path string) (string, error) {
+	if s.uploadRoot == "" {
+		return "", fmt.Errorf("upload root not configured, local path %s rejected", path)
+	}
+	root, err := filepath.EvalSymlinks(s.uploadRoot)
+	if err != nil {
+		return "", fmt.Errorf("upload root unavailable: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside upload root", path)
+	}
+	return resolved, nil
+}
+
+// checkoutSource 按任务选项定位、解压或检出源码
+func (s *BaseScanner) checkoutSource(ctx context.Context, task *domain.ScanTaskPayload, workDir string, depth int) (string, error) {
+	var opts domain.SourceOptions
+	if err := task.DecodeOptions(&opts); err != nil {
+		return "", err
+	}
+
+	if sourcePath := strings.TrimSpace(opts.SourcePath); sourcePath != "" {
+		sourcePath, err := s.resolveUploadPath(sourcePath)
+		if err != nil {
+			return "", fmt.Errorf("source path unavailable: %w", err)
+		}
+		info, err := os.Stat(sourcePath)
+		if err != nil {
+			return "", fmt.Errorf("source path unavailable: %w", err)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("source path is not a directory: %s", sourcePath)
+		}
+		return sourcePath, nil
+	}
+
+	if archivePath := strings.TrimSpace(opts.ArchivePath); archivePath != "" {
+		archivePath, err := s.resolveUploadPath(archivePath)
+		if err != nil {
+			return "", fmt.Errorf("archive path unavailable: %w", err)
+		}
+		srcDir := filepath.Join(workDir, "src")
+		if err := extractArchive(archivePath, srcDir); err != nil {
+			return "", fmt.Errorf("extract archive failed: %w", err)
+		}
+		if err := s.chownForProcess(srcDir); err != nil {
+			return "", fmt.Errorf("chown extracted source failed: %w", err)
+		}
+		return srcDir, nil
+	}
+
+	repoURL := strings.TrimSpace(opts.RepoURL)
+	if repoURL == "" {
+		return "", fmt.Errorf("missing %s or %s in task options", OptionRepoURL, OptionSourcePath)
+	}
+	if !isRemoteRepoURL(repoURL) {
+		return "", fmt.Errorf("invalid repository url: %s, only https, ssh and git remotes are allowed", repoURL)
+	}
+
+	branch := strings.TrimSpace(opts.Branch)
+	if branch == "" {
+		branch = defaultBranch
+	}
+	commit := strings.TrimSpace(opts.Commit)
+	srcDir := filepath.Join(workDir, "src")
+
+	s.logger.Info("checking out repository",
+		zap.String("task_id", task.TaskID),
+		zap.String("repo_url", repoURL),
+		zap.String("branch", branch),
+		zap.String("commit", commit))
+
+	// 指定提交或增量扫描的基线提交时需要完整历史，否则按需浅克隆
+	cloneArgs := []string{"clone", "--quiet", "--branch", branch}
+	if commit == "" && task.BaseCommit == "" && depth > 0 {
+		cloneArgs = append(cloneArgs, "--depth", strconv.Itoa(depth))
+	}
+	cloneArgs = append(cloneArgs, "--", repoURL, srcDir)
+	if err := s.ExecuteCommand(ctx, task, s.gitCommand(ctx, cloneArgs...), "checkout"); err != nil {
+		return "", fmt.Errorf("git clone failed: %w", err)
+	}
+
+	if commit != "" {
+		if err := s.ExecuteCommand(ctx, task, s.gitCommand(ctx, "-C", srcDir, "checkout", "--quiet", "--detach", commit), "checkout"); err != nil {
+			return "", fmt.Errorf("git checkout %s failed: %w", commit, err)
+		}
+	}
+
+	return srcDir, nil
+}
+
+// scpLikeRepoURL git 的 scp 风格 ssh 地址，如 git@github.com:org/app.git
+var scpLikeRepoURL = regexp.MustCompile(`^(?:[A-Za-z0-9._-]+@)?[A-Za-z0-9][A-Za-z0-9.-]*:[^:]`)
+
+// isRemoteRepoURL 仓库地址只接受远程协议；本地路径与 file:// 会绕过上传根目录的限制
+func isRemoteRepoURL(repoURL string) bool {
+	if strings.HasPrefix(repoURL, "-") {
+		return false
+	}
+	if u, err := url.Parse(repoURL); err == nil && strings.Contains(repoURL, "://") {
+		switch strings.ToLower(u.Scheme) {
+		case "https", "ssh", "git":
+			return u.Host != ""
+		}
+		return false
+	}
+	return scpLikeRepoURL.MatchString(repoURL)
+}
+
+// gitCommand 构造禁用交互提示的 git 命令，传输协议限定为远程协议（含子模块与重定向）
+func (s *BaseScanner) gitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL=https:ssh:git")
+	return cmd
+}
+
+// extractArchive 按扩展名解压 zip/tar/tar.gz 归档到 dest
+func extractArchive(archivePath, dest string) error {
+	if err := os.MkdirAll(dest, 0o750); err != nil {
+		return err
+	}
+
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"), strings.HasSuffix(lower, ".war"):
+		return extractZip(archivePath, dest)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(gz, dest)
+	case strings.HasSuffix(lower, ".tar"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return extractTar(f, dest)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+	}
+}
+
+func extractZip(archivePath, dest string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var total int64
+	for _, f := range r.File {
+		target, err := safeJoin(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o750); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		total += int64(f.UncompressedSize64)
+		if total > maxExtractSize {
+			return fmt.Errorf("archive exceeds %d bytes", maxExtractSize)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(target, rc, maxExtractSize)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := safeJoin(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > maxExtractSize {
+				return fmt.Errorf("archive exceeds %d bytes", maxExtractSize)
+			}
+			if err := writeFile(target, tr, hdr.Size); err != nil {
+				return err
+			}
+		default:
+			// 忽略符号链接、设备文件等，避免逃逸沙箱
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader, limit int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, io.LimitReader(r, limit))
+	return err
+}
+
+// safeJoin 拼接归档内路径并拒绝目录穿越（zip slip）
+func safeJoin(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	if target != filepath.Clean(dest) && !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+	return target, nil
+}
+
+// sanitizePathComponent 过滤路径中的非法字符，避免任务ID造成目录穿越
+func sanitizePathComponent(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// dirSize 统计目录下普通文件的总大小，忽略无法访问的文件
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
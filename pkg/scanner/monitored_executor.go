@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"runtime"
 	"time"
 
@@ -9,7 +10,7 @@ import (
 	"github.com/blackarbiter/go-sac/pkg/metrics"
 )
 
-// MonitoredExecutor 是一个装饰器，用于为扫描器添加监控和熔断功能
+// MonitoredExecutor 是一个装饰器，用于为扫描器添加监控并上报所属扫描类型的熔断状态
 type MonitoredExecutor struct {
 	executor       TaskExecutor
 	metrics        *metrics.ScannerMetrics
@@ -57,12 +58,13 @@ func (m *MonitoredExecutor) AsyncExecute(ctx context.Context, task *domain.ScanT
 		"error_type":   "none",
 	}
 
-	if err != nil {
+	// 执行结果由扫描器按命令计入熔断器，此处只统计
+	switch {
+	case errors.Is(err, context.Canceled):
+		tags["error_type"] = "cancelled"
+	case err != nil:
 		tags["error_type"] = "execution_error"
 		m.metrics.RecordExecutorFailure(m.executor.Meta().Type)
-		m.circuitBreaker.RecordFailure(TransientError)
-	} else {
-		m.circuitBreaker.RecordSuccess()
 	}
 
 	// 记录队列等待时间
@@ -75,7 +77,9 @@ func (m *MonitoredExecutor) AsyncExecute(ctx context.Context, task *domain.ScanT
 	m.metrics.Gauge("memory_usage_bytes", float64(memoryDelta), tags)
 
 	// 记录任务状态
-	if err != nil {
+	if tags["error_type"] == "cancelled" {
+		m.metrics.Record("task_cancelled", 1, tags)
+	} else if err != nil {
 		m.metrics.Record("task_errors", 1, tags)
 	} else {
 		m.metrics.Record("task_success", 1, tags)
@@ -109,8 +113,10 @@ func (m *MonitoredExecutor) Cancel(handle string) error {
 	// 记录取消操作时间
 	m.metrics.Record("cancel_operation_seconds", duration.Seconds(), tags)
 
-	// 记录取消操作状态
-	if err != nil {
+	// 记录取消操作状态，执行不存在（已过期或不属于本实例）单独统计
+	if errors.Is(err, ErrExecutionNotFound) {
+		m.metrics.Record("cancel_not_found", 1, tags)
+	} else if err != nil {
 		m.metrics.Record("cancel_errors", 1, tags)
 	} else {
 		m.metrics.Record("cancel_success", 1, tags)
@@ -144,6 +150,15 @@ func (m *MonitoredExecutor) GetStatus(handle string) (domain.TaskStatus, error)
 	return status, err
 }
 
+// GetExecution 返回执行详情，被装饰的执行器未实现 ExecutionTracker 时返回 ErrExecutionNotFound
+func (m *MonitoredExecutor) GetExecution(handle string) (ExecutionInfo, error) {
+	tracker, ok := m.executor.(ExecutionTracker)
+	if !ok {
+		return ExecutionInfo{}, ErrExecutionNotFound
+	}
+	return tracker.GetExecution(handle)
+}
+
 // HealthCheck 实现 TaskExecutor 接口
 func (m *MonitoredExecutor) HealthCheck() error {
 	start := time.Now()
@@ -202,12 +217,13 @@ func (m *MonitoredExecutor) SyncExecute(ctx context.Context, task *domain.ScanTa
 		"error_type":   "none",
 	}
 
-	if err != nil {
+	// 执行结果由扫描器按命令计入熔断器，此处只统计
+	switch {
+	case errors.Is(err, context.Canceled):
+		tags["error_type"] = "cancelled"
+	case err != nil:
 		tags["error_type"] = "execution_error"
 		m.metrics.RecordExecutorFailure(m.executor.Meta().Type)
-		m.circuitBreaker.RecordFailure(TransientError)
-	} else {
-		m.circuitBreaker.RecordSuccess()
 	}
 
 	// 记录队列等待时间
@@ -220,7 +236,9 @@ func (m *MonitoredExecutor) SyncExecute(ctx context.Context, task *domain.ScanTa
 	m.metrics.Gauge("memory_usage_bytes", float64(memoryDelta), tags)
 
 	// 记录任务状态
-	if err != nil {
+	if tags["error_type"] == "cancelled" {
+		m.metrics.Record("task_cancelled", 1, tags)
+	} else if err != nil {
 		m.metrics.Record("task_errors", 1, tags)
 	} else {
 		m.metrics.Record("task_success", 1, tags)
@@ -2,6 +2,9 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,20 +14,88 @@ import (
 	"go.uber.org/zap"
 )
 
+// 超时策略缺省值，策略字段为0时使用
+const (
+	defaultBaseTimeout     = 5 * time.Minute
+	defaultSoftRatio       = 0.6
+	defaultCriticalGrace   = time.Minute
+	defaultHistoryHeadroom = 3.0
+	defaultHistoryWindow   = 200
+	defaultMinSamples      = 20
+	defaultMinTimeout      = time.Minute
+)
+
+// ErrCriticalTimeout 硬超时终止后进程仍未退出，按严重错误计入熔断器
+var ErrCriticalTimeout = errors.New("critical timeout: process did not exit after termination")
+
 // TimeoutController manages task timeouts and retries
+//
+// 每个任务的超时由 Deadline 按策略计算：
+// 上限 = 基准超时 × 扫描类型倍数 × 优先级系数 + 目标规模附加时间；
+// 同类型成功执行样本足够时取 min(上限, max(p95 × HistoryHeadroom + 规模附加时间, MinTimeout))。
 type TimeoutController struct {
-	executors      map[string]TaskExecutor
-	watchdog       *WatchdogService
-	circuitBreaker *CircuitBreaker
-	logger         *zap.Logger
-	metrics        *metrics.ScannerMetrics
+	policy   TimeoutPolicy
+	history  map[domain.ScanType]*durationHistory
+	watchdog *WatchdogService
+	logger   *zap.Logger
+	metrics  *metrics.ScannerMetrics
+	mu       sync.RWMutex
 }
 
 // TimeoutPolicy defines timeout behavior
 type TimeoutPolicy struct {
-	BaseTimeout    time.Duration
-	PriorityFactor float64
-	TypeMultiplier map[domain.ScanType]float64
+	BaseTimeout    time.Duration               // 扫描器未配置超时时的基准超时
+	PriorityFactor float64                     // 高优先级放宽、低优先级收紧的比例，如 0.2 表示 ±20%
+	TypeMultiplier map[domain.ScanType]float64 // 按扫描类型的超时倍数
+
+	PerMB           time.Duration // 目标每 MB（仓库/镜像大小）附加的时间
+	PerItem         time.Duration // 目标每个条目（URL、端口等）附加的时间
+	SoftRatio       float64       // 软超时占硬超时的比例，到达后检查进度
+	CriticalGrace   time.Duration // 硬超时终止后等待进程退出的时间，仍未退出视为严重超时
+	HistoryHeadroom float64       // 历史 p95 的放大倍数
+	HistoryWindow   int           // 每个扫描类型保留的历史样本数
+	MinSamples      int           // 启用历史自适应所需的最少样本数
+	MinTimeout      time.Duration // 历史自适应计算结果的下限
+	MaxTimeout      time.Duration // 硬超时上限，0 表示不限制
+}
+
+// withDefaults 为未配置的字段填充缺省值
+func (p TimeoutPolicy) withDefaults() TimeoutPolicy {
+	if p.BaseTimeout <= 0 {
+		p.BaseTimeout = defaultBaseTimeout
+	}
+	if p.SoftRatio <= 0 || p.SoftRatio >= 1 {
+		p.SoftRatio = defaultSoftRatio
+	}
+	if p.CriticalGrace <= 0 {
+		p.CriticalGrace = defaultCriticalGrace
+	}
+	if p.HistoryHeadroom <= 0 {
+		p.HistoryHeadroom = defaultHistoryHeadroom
+	}
+	if p.HistoryWindow <= 0 {
+		p.HistoryWindow = defaultHistoryWindow
+	}
+	if p.MinSamples <= 0 {
+		p.MinSamples = defaultMinSamples
+	}
+	if p.MinTimeout <= 0 {
+		p.MinTimeout = defaultMinTimeout
+	}
+	return p
+}
+
+// TargetSize 扫描目标规模，用于按规模放宽超时
+type TargetSize struct {
+	Bytes int64 // 仓库、镜像等目标的大小
+	Items int   // URL、端口等目标条目数
+}
+
+// TaskDeadline 单个任务的分级超时，均为从任务开始执行起算的时长
+type TaskDeadline struct {
+	Soft     time.Duration // 到达后检查进度
+	Hard     time.Duration // 到达后终止进程组
+	Critical time.Duration // 终止后到达此时间进程仍未退出时升级为严重超时
 }
 
 // TimeoutEvent represents a timeout occurrence
@@ -35,6 +106,12 @@ type TimeoutEvent struct {
 	ElapsedTime  time.Duration
 	Task         *domain.ScanTaskPayload
 	Policy       TimeoutPolicy
+	Deadline     TaskDeadline
+
+	// Progress 软超时时检查任务自上次检查以来是否仍有进展，nil 表示无法判断
+	Progress func() bool
+	// Terminate 硬超时时终止任务的进程组
+	Terminate func()
 }
 
 // TimeoutSeverity indicates timeout severity
@@ -46,162 +123,257 @@ const (
 	SeverityCritical
 )
 
+// String returns the metric label of the severity
+func (s TimeoutSeverity) String() string {
+	switch s {
+	case SeveritySoft:
+		return "soft"
+	case SeverityHard:
+		return "hard"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
 // NewTimeoutController creates a new timeout controller
 func NewTimeoutController(metrics *metrics.ScannerMetrics) *TimeoutController {
-	return &TimeoutController{
-		executors: make(map[string]TaskExecutor),
-		watchdog:  NewWatchdogService(),
-		metrics:   metrics,
-		logger:    logger.Logger,
+	log := logger.Logger
+	if log == nil {
+		log = zap.NewNop()
+	}
+	tc := &TimeoutController{
+		policy:  TimeoutPolicy{}.withDefaults(),
+		history: make(map[domain.ScanType]*durationHistory),
+		metrics: metrics,
+		logger:  log,
 	}
+	tc.watchdog = NewWatchdogService(WatchdogSettings{}, tc.handleWatchdogEvent)
+	return tc
 }
 
-// HandleTimeout processes timeout events
-func (tc *TimeoutController) HandleTimeout(event TimeoutEvent) {
-	if tc.circuitBreaker.IsOpen() {
-		tc.logger.Warn("circuit breaker open, skipping timeout handling")
-		return
-	}
+// SetPolicy 设置超时策略，未配置的字段使用缺省值
+func (tc *TimeoutController) SetPolicy(policy TimeoutPolicy) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.policy = policy.withDefaults()
+}
 
-	executor, exists := tc.executors[event.ExecutorType]
-	if !exists {
-		tc.logger.Error("unknown executor type", zap.String("type", event.ExecutorType))
-		return
+// Watchdog 返回巡检扫描子进程的看门狗，nil 控制器返回 nil
+func (tc *TimeoutController) Watchdog() *WatchdogService {
+	if tc == nil {
+		return nil
 	}
+	return tc.watchdog
+}
 
-	if err := executor.HealthCheck(); err != nil {
-		tc.metrics.RecordExecutorFailure(event.ExecutorType)
-		tc.circuitBreaker.RecordFailure(TransientError)
-		return
-	}
+// SetWatchdogSettings 设置看门狗配置
+func (tc *TimeoutController) SetWatchdogSettings(settings WatchdogSettings) {
+	tc.watchdog.SetSettings(settings)
+}
 
-	switch event.Severity {
-	case SeveritySoft:
-		tc.handleSoftTimeout(event, executor)
-	case SeverityHard:
-		tc.handleHardTimeout(event, executor)
-	case SeverityCritical:
-		tc.handleCriticalTimeout(event)
-	}
+// StartWatchdog 运行看门狗巡检直到 ctx 取消，未启用时立即返回
+func (tc *TimeoutController) StartWatchdog(ctx context.Context) {
+	tc.watchdog.Run(ctx)
 }
 
-// handleSoftTimeout handles non-critical timeouts
-func (tc *TimeoutController) handleSoftTimeout(event TimeoutEvent, executor TaskExecutor) {
-	tc.metrics.RecordTimeout(event.Task.ScanType, "soft", false)
-	tc.logger.Warn("soft timeout warning",
-		zap.String("taskID", event.TaskID),
-		zap.Duration("elapsed", event.ElapsedTime),
-	)
+// WatchdogSnapshot 返回看门狗当前监视的全部进程
+func (tc *TimeoutController) WatchdogSnapshot() WatchdogSnapshot {
+	return tc.Watchdog().Snapshot()
+}
 
-	go tc.runDiagnostics(event)
+// handleWatchdogEvent 补全看门狗事件的超时策略后按严重等级处理
+func (tc *TimeoutController) handleWatchdogEvent(event TimeoutEvent) {
+	event.Policy = tc.Policy()
+	tc.HandleTimeout(event)
 }
 
-// handleHardTimeout handles critical timeouts
-func (tc *TimeoutController) handleHardTimeout(event TimeoutEvent, executor TaskExecutor) {
-	tc.metrics.RecordTimeout(event.Task.ScanType, "hard", true)
+// Policy 返回当前超时策略
+func (tc *TimeoutController) Policy() TimeoutPolicy {
+	if tc == nil {
+		return TimeoutPolicy{}.withDefaults()
+	}
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.policy
+}
 
-	if err := executor.Cancel(event.TaskID); err != nil {
-		tc.logger.Error("task cancellation failed",
-			zap.String("taskID", event.TaskID),
-			zap.Error(err),
-		)
-		return
+// Deadline 计算任务的分级超时；base 为扫描器配置的超时，为0时使用策略的 BaseTimeout。
+// nil 控制器按缺省策略计算且不使用历史数据
+func (tc *TimeoutController) Deadline(task *domain.ScanTaskPayload, base time.Duration, size TargetSize) TaskDeadline {
+	policy := tc.Policy()
+	if base <= 0 {
+		base = policy.BaseTimeout
 	}
 
-	go tc.cleanupTaskResources(event, executor)
-}
+	multiplier := 1.0
+	if m, ok := policy.TypeMultiplier[task.ScanType]; ok && m > 0 {
+		multiplier = m
+	}
+	switch task.Priority {
+	case domain.PriorityHigh:
+		multiplier *= 1 + policy.PriorityFactor
+	case domain.PriorityLow:
+		multiplier *= math.Max(1-policy.PriorityFactor, 0.1)
+	}
 
-// handleCriticalTimeout handles system-critical timeouts
-func (tc *TimeoutController) handleCriticalTimeout(event TimeoutEvent) {
-	tc.logger.Error("critical timeout detected",
-		zap.String("taskID", event.TaskID),
-		zap.Duration("elapsed", event.ElapsedTime),
-	)
+	sizeExtra := time.Duration(float64(size.Bytes)/(1<<20)*float64(policy.PerMB)) +
+		time.Duration(size.Items)*policy.PerItem
+	hard := time.Duration(float64(base)*multiplier) + sizeExtra
 
-	tc.circuitBreaker.RecordFailure(CriticalError)
-	tc.metrics.RecordCriticalTimeout(event.Task.ScanType)
-}
+	if p95, ok := tc.p95(task.ScanType, policy); ok {
+		adaptive := time.Duration(float64(p95)*policy.HistoryHeadroom) + sizeExtra
+		if adaptive < policy.MinTimeout {
+			adaptive = policy.MinTimeout
+		}
+		if adaptive < hard {
+			hard = adaptive
+		}
+	}
+	if policy.MaxTimeout > 0 && hard > policy.MaxTimeout {
+		hard = policy.MaxTimeout
+	}
 
-// runDiagnostics performs timeout diagnostics
-func (tc *TimeoutController) runDiagnostics(event TimeoutEvent) {
-	// TODO: Implement diagnostic logic
+	return TaskDeadline{
+		Soft:     time.Duration(float64(hard) * policy.SoftRatio),
+		Hard:     hard,
+		Critical: hard + policy.CriticalGrace,
+	}
 }
 
-// cleanupTaskResources cleans up resources after timeout
-func (tc *TimeoutController) cleanupTaskResources(event TimeoutEvent, executor TaskExecutor) {
-	// TODO: Implement resource cleanup
+// ObserveDuration 记录一次成功执行的耗时，作为该扫描类型的历史样本
+func (tc *TimeoutController) ObserveDuration(scanType domain.ScanType, elapsed time.Duration) {
+	if tc == nil {
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	h, ok := tc.history[scanType]
+	if !ok {
+		h = newDurationHistory(tc.policy.HistoryWindow)
+		tc.history[scanType] = h
+	}
+	h.add(elapsed)
 }
 
-// WatchdogService monitors task execution
-type WatchdogService struct {
-	monitorInterval time.Duration
-	timeoutChannel  chan<- TimeoutEvent
-	taskRegistry    *TaskRegistry
+// p95 返回扫描类型的历史 p95 耗时，样本不足时返回 false
+func (tc *TimeoutController) p95(scanType domain.ScanType, policy TimeoutPolicy) (time.Duration, bool) {
+	if tc == nil {
+		return 0, false
+	}
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	h, ok := tc.history[scanType]
+	if !ok || h.len() < policy.MinSamples {
+		return 0, false
+	}
+	return h.quantile(0.95), true
 }
 
-// NewWatchdogService creates a new watchdog service
-func NewWatchdogService() *WatchdogService {
-	return &WatchdogService{
-		monitorInterval: 5 * time.Second,
-		taskRegistry:    NewTaskRegistry(),
+// HandleTimeout processes timeout events
+func (tc *TimeoutController) HandleTimeout(event TimeoutEvent) {
+	if tc == nil {
+		return
+	}
+	switch event.Severity {
+	case SeveritySoft:
+		tc.handleSoftTimeout(event)
+	case SeverityHard:
+		tc.handleHardTimeout(event)
+	case SeverityCritical:
+		tc.handleCriticalTimeout(event)
 	}
 }
 
-// StartHeartbeatCheck begins heartbeat monitoring
-func (w *WatchdogService) StartHeartbeatCheck(ctx context.Context) {
-	ticker := time.NewTicker(w.monitorInterval)
-	defer ticker.Stop()
+// handleSoftTimeout 记录软超时并检查进度，无进展时告警，任务继续执行
+func (tc *TimeoutController) handleSoftTimeout(event TimeoutEvent) {
+	tc.recordTimeout(event, false)
+	fields := []zap.Field{
+		zap.String("taskID", event.TaskID),
+		zap.String("executor", event.ExecutorType),
+		zap.Duration("elapsed", event.ElapsedTime),
+		zap.Duration("hard_timeout", event.Deadline.Hard),
+	}
+	if event.Progress != nil && !event.Progress() {
+		tc.logger.Warn("soft timeout reached without progress", fields...)
+		return
+	}
+	tc.logger.Info("soft timeout reached, task still progressing", fields...)
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			w.checkHeartbeats()
-		case <-ctx.Done():
-			return
-		}
+// handleHardTimeout 记录硬超时并终止任务的进程组
+func (tc *TimeoutController) handleHardTimeout(event TimeoutEvent) {
+	tc.recordTimeout(event, true)
+	tc.logger.Warn("hard timeout reached, terminating task",
+		zap.String("taskID", event.TaskID),
+		zap.String("executor", event.ExecutorType),
+		zap.Duration("elapsed", event.ElapsedTime),
+	)
+	if event.Terminate != nil {
+		event.Terminate()
 	}
 }
 
-// checkHeartbeats verifies task heartbeats
-func (w *WatchdogService) checkHeartbeats() {
-	w.taskRegistry.Range(func(taskID string, state *TaskState) bool {
-		if time.Since(state.LastBeat) > w.monitorInterval*2 {
-			w.handleStalledTask(taskID)
-			w.taskRegistry.Delete(taskID)
-		}
-		return true
-	})
+// handleCriticalTimeout 记录严重超时；执行方以 ErrCriticalTimeout 结束任务，
+// 该错误按严重错误计入所属扫描类型的熔断器
+func (tc *TimeoutController) handleCriticalTimeout(event TimeoutEvent) {
+	tc.logger.Error("critical timeout detected, process did not exit after termination",
+		zap.String("taskID", event.TaskID),
+		zap.String("executor", event.ExecutorType),
+		zap.Duration("elapsed", event.ElapsedTime),
+	)
+	tc.recordTimeout(event, true)
+	if tc.metrics != nil && event.Task != nil {
+		tc.metrics.RecordCriticalTimeout(event.Task.ScanType)
+	}
 }
 
-// handleStalledTask processes stalled tasks
-func (w *WatchdogService) handleStalledTask(taskID string) {
-	// TODO: Implement stalled task handling
+// recordTimeout 按严重等级记录超时指标
+func (tc *TimeoutController) recordTimeout(event TimeoutEvent, isHard bool) {
+	if tc.metrics == nil || event.Task == nil {
+		return
+	}
+	tc.metrics.RecordTimeout(event.Task.ScanType, event.Severity.String(), isHard)
 }
 
-// TaskRegistry manages task state
-type TaskRegistry struct {
-	states sync.Map
+// durationHistory 固定窗口的耗时样本
+type durationHistory struct {
+	samples []time.Duration
+	next    int
+	full    bool
 }
 
-// NewTaskRegistry creates a new task registry
-func NewTaskRegistry() *TaskRegistry {
-	return &TaskRegistry{}
+func newDurationHistory(window int) *durationHistory {
+	return &durationHistory{samples: make([]time.Duration, window)}
 }
 
-// TaskState represents task execution state
-type TaskState struct {
-	LastBeat time.Time
-	Status   domain.TaskStatus
+func (h *durationHistory) add(d time.Duration) {
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
 }
 
-// Range iterates over task states
-func (r *TaskRegistry) Range(f func(taskID string, state *TaskState) bool) {
-	r.states.Range(func(key, value interface{}) bool {
-		return f(key.(string), value.(*TaskState))
-	})
+func (h *durationHistory) len() int {
+	if h.full {
+		return len(h.samples)
+	}
+	return h.next
 }
 
-// Delete removes a task from registry
-func (r *TaskRegistry) Delete(taskID string) {
-	r.states.Delete(taskID)
+// quantile 返回样本的 q 分位数（最近秩法）
+func (h *durationHistory) quantile(q float64) time.Duration {
+	n := h.len()
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), h.samples[:n]...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(q*float64(n))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
 }
@@ -0,0 +1,72 @@
+package scanner_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutController_Deadline(t *testing.T) {
+	tc := scanner.NewTimeoutController(nil)
+	tc.SetPolicy(scanner.TimeoutPolicy{
+		PriorityFactor:  0.5,
+		TypeMultiplier:  map[domain.ScanType]float64{domain.ScanTypeDast: 2},
+		PerMB:           time.Second,
+		PerItem:         100 * time.Millisecond,
+		SoftRatio:       0.5,
+		CriticalGrace:   time.Minute,
+		HistoryHeadroom: 2,
+		MinSamples:      3,
+		MinTimeout:      time.Minute,
+		MaxTimeout:      time.Hour,
+	})
+
+	sast := &domain.ScanTaskPayload{ScanType: domain.ScanTypeStaticCodeAnalysis, Priority: domain.PriorityMedium}
+	d := tc.Deadline(sast, 10*time.Minute, scanner.TargetSize{})
+	assert.Equal(t, scanner.TaskDeadline{Soft: 5 * time.Minute, Hard: 10 * time.Minute, Critical: 11 * time.Minute}, d)
+
+	// 优先级、扫描类型倍数与目标规模
+	high := &domain.ScanTaskPayload{ScanType: domain.ScanTypeStaticCodeAnalysis, Priority: domain.PriorityHigh}
+	assert.Equal(t, 15*time.Minute, tc.Deadline(high, 10*time.Minute, scanner.TargetSize{}).Hard)
+	low := &domain.ScanTaskPayload{ScanType: domain.ScanTypeStaticCodeAnalysis, Priority: domain.PriorityLow}
+	assert.Equal(t, 5*time.Minute, tc.Deadline(low, 10*time.Minute, scanner.TargetSize{}).Hard)
+	dast := &domain.ScanTaskPayload{ScanType: domain.ScanTypeDast, Priority: domain.PriorityMedium}
+	assert.Equal(t, 20*time.Minute+50*time.Second, tc.Deadline(dast, 10*time.Minute, scanner.TargetSize{Items: 500}).Hard)
+	assert.Equal(t, 10*time.Minute+2*time.Minute, tc.Deadline(sast, 10*time.Minute, scanner.TargetSize{Bytes: 120 << 20}).Hard)
+	assert.Equal(t, time.Hour, tc.Deadline(sast, 3*time.Hour, scanner.TargetSize{}).Hard)
+
+	// 样本足够后按历史 p95 收紧，但不低于 MinTimeout，也不超过策略上限
+	for _, d := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		tc.ObserveDuration(domain.ScanTypeStaticCodeAnalysis, d)
+	}
+	assert.Equal(t, 6*time.Minute, tc.Deadline(sast, 10*time.Minute, scanner.TargetSize{}).Hard)
+	assert.Equal(t, 4*time.Minute, tc.Deadline(sast, 4*time.Minute, scanner.TargetSize{}).Hard)
+	assert.Equal(t, 10*time.Minute, tc.Deadline(dast, 5*time.Minute, scanner.TargetSize{}).Hard, "history of other scan types is ignored")
+
+	// nil 控制器按缺省策略计算
+	var nilCtrl *scanner.TimeoutController
+	assert.Equal(t, 5*time.Minute, nilCtrl.Deadline(sast, 0, scanner.TargetSize{}).Hard)
+}
+
+func TestTimeoutController_HandleTimeout(t *testing.T) {
+	tc := scanner.NewTimeoutController(nil)
+	task := &domain.ScanTaskPayload{TaskID: "t1", ScanType: domain.ScanTypeSca}
+
+	probed := false
+	tc.HandleTimeout(scanner.TimeoutEvent{TaskID: "t1", Task: task, Severity: scanner.SeveritySoft, Progress: func() bool {
+		probed = true
+		return false
+	}})
+	assert.True(t, probed, "soft timeout should check progress")
+
+	terminated := false
+	tc.HandleTimeout(scanner.TimeoutEvent{TaskID: "t1", Task: task, Severity: scanner.SeverityHard, Terminate: func() {
+		terminated = true
+	}})
+	assert.True(t, terminated, "hard timeout should terminate the task")
+
+	assert.Equal(t, "critical", scanner.SeverityCritical.String())
+}
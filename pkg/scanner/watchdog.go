@@ -0,0 +1,410 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 看门狗缺省值，配置为0时使用
+const (
+	defaultWatchdogInterval = 5 * time.Second
+	defaultSilenceTimeout   = 2 * time.Minute
+	defaultStallTimeout     = 5 * time.Minute
+)
+
+// WatchdogSettings 看门狗配置
+type WatchdogSettings struct {
+	Enabled        bool
+	Interval       time.Duration // 巡检间隔
+	SilenceTimeout time.Duration // 进程组持续无输出（wchar 不增长）超过此时间上报软超时
+	StallTimeout   time.Duration // 进程组既无 CPU 进展也无输出超过此时间上报硬超时并终止
+	ReapZombies    bool          // 回收不受任何执行跟踪的僵尸子进程
+}
+
+// withDefaults 为未配置的字段填充缺省值
+func (s WatchdogSettings) withDefaults() WatchdogSettings {
+	if s.Interval <= 0 {
+		s.Interval = defaultWatchdogInterval
+	}
+	if s.SilenceTimeout <= 0 {
+		s.SilenceTimeout = defaultSilenceTimeout
+	}
+	if s.StallTimeout <= 0 {
+		s.StallTimeout = defaultStallTimeout
+	}
+	return s
+}
+
+// WatchedProcess 正在执行的扫描子进程，PID 为其进程组组长
+type WatchedProcess struct {
+	ExecID       string
+	TaskID       string
+	ExecutorType string
+	PID          int
+	Task         *domain.ScanTaskPayload
+	StartedAt    time.Time
+	Deadline     TaskDeadline
+	// Terminate 终止进程组，force 为 true 时发送 SIGKILL 并放弃等待进程退出
+	Terminate func(force bool)
+}
+
+// ProcessSource 向看门狗提供正在执行的子进程，由扫描器实现
+type ProcessSource interface {
+	WatchedProcesses() []WatchedProcess
+}
+
+// WatchedProcessStatus 单个被监视进程的状态
+type WatchedProcessStatus struct {
+	ExecID       string          `json:"exec_id"`
+	TaskID       string          `json:"task_id"`
+	ExecutorType string          `json:"executor_type"`
+	ScanType     domain.ScanType `json:"scan_type"`
+	PID          int             `json:"pid"`
+	Processes    int             `json:"processes"` // 进程组内存活的进程数
+	State        string          `json:"state"`     // 组长进程的 /proc 状态，如 R、S、D
+	StartedAt    time.Time       `json:"started_at"`
+	Elapsed      time.Duration   `json:"elapsed"`
+	CPUTime      time.Duration   `json:"cpu_time"`
+	WrittenBytes uint64          `json:"written_bytes"`
+	CPUIdle      time.Duration   `json:"cpu_idle"`    // 距上次 CPU 进展的时间
+	OutputIdle   time.Duration   `json:"output_idle"` // 距上次输出的时间
+	Severity     string          `json:"severity,omitempty"`
+	Terminated   bool            `json:"terminated"`
+}
+
+// WatchdogSnapshot 看门狗当前监视的全部进程
+type WatchdogSnapshot struct {
+	CheckedAt     time.Time              `json:"checked_at"`
+	Processes     []WatchedProcessStatus `json:"processes"`
+	ReapedZombies uint64                 `json:"reaped_zombies"`
+}
+
+// procStat 单个进程的 /proc 采样
+type procStat struct {
+	PID     int
+	PPID    int
+	PGID    int
+	State   byte
+	CPUTime time.Duration
+	Written uint64
+}
+
+// watchState 被监视进程的跟踪状态
+type watchState struct {
+	proc         WatchedProcess
+	cpu          time.Duration
+	written      uint64
+	lastCPU      time.Time
+	lastOutput   time.Time
+	members      int
+	state        byte
+	severity     TimeoutSeverity
+	escalated    bool // 已上报 severity 级别的事件
+	terminatedAt time.Time
+}
+
+// WatchdogService 巡检扫描子进程，发现卡死时按严重等级上报超时事件
+//
+// 进程组采样自 /proc：无输出但仍消耗 CPU 为软超时；CPU 与输出都停滞为硬超时，
+// 发送 SIGTERM 终止进程组；终止后超过 CriticalGrace 仍存活为严重超时，强制终止并放弃等待。
+// 同时回收不受执行跟踪、连续两次巡检都处于僵尸状态的子进程（如容器内作为 1 号进程时被收养的孤儿）。
+type WatchdogService struct {
+	settings WatchdogSettings
+	handle   func(TimeoutEvent)
+	logger   *zap.Logger
+
+	sources   map[ProcessSource]struct{}
+	watched   map[string]*watchState
+	zombies   map[int]bool // 上次巡检时的僵尸子进程
+	reaped    uint64
+	checkedAt time.Time
+	mu        sync.Mutex
+}
+
+// NewWatchdogService creates a new watchdog service
+func NewWatchdogService(settings WatchdogSettings, handle func(TimeoutEvent)) *WatchdogService {
+	log := logger.Logger
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &WatchdogService{
+		settings: settings.withDefaults(),
+		handle:   handle,
+		logger:   log,
+		sources:  make(map[ProcessSource]struct{}),
+		watched:  make(map[string]*watchState),
+		zombies:  make(map[int]bool),
+	}
+}
+
+// SetSettings 更新看门狗配置，下一次巡检生效
+func (w *WatchdogService) SetSettings(settings WatchdogSettings) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.settings = settings.withDefaults()
+}
+
+// Settings 返回当前配置
+func (w *WatchdogService) Settings() WatchdogSettings {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.settings
+}
+
+// AddSource 登记进程来源，nil 看门狗忽略
+func (w *WatchdogService) AddSource(source ProcessSource) {
+	if w == nil || source == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sources[source] = struct{}{}
+}
+
+// RemoveSource 注销进程来源
+func (w *WatchdogService) RemoveSource(source ProcessSource) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.sources, source)
+}
+
+// Run 按巡检间隔检查，直到 ctx 取消；未启用时立即返回
+func (w *WatchdogService) Run(ctx context.Context) {
+	settings := w.Settings()
+	if !settings.Enabled {
+		return
+	}
+	ticker := time.NewTicker(settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check 执行一次巡检：采样全部被监视进程组、上报超时事件并回收僵尸子进程
+func (w *WatchdogService) Check() {
+	now := time.Now()
+	table, err := readProcTable()
+	if err != nil {
+		w.logger.Debug("watchdog cannot sample processes", zap.Error(err))
+	}
+
+	var events []TimeoutEvent
+	var kills []func()
+	w.mu.Lock()
+	w.checkedAt = now
+	live := make(map[string]bool)
+	tracked := make(map[int]bool)
+	for source := range w.sources {
+		for _, proc := range source.WatchedProcesses() {
+			live[proc.ExecID] = true
+			tracked[proc.PID] = true
+			st, ok := w.watched[proc.ExecID]
+			if !ok {
+				st = &watchState{proc: proc, lastCPU: now, lastOutput: now}
+				w.watched[proc.ExecID] = st
+			}
+			if table == nil {
+				continue
+			}
+			if event, kill := w.inspect(st, table, now); event != nil || kill != nil {
+				if event != nil {
+					events = append(events, *event)
+				}
+				if kill != nil {
+					kills = append(kills, kill)
+				}
+			}
+		}
+	}
+	for id := range w.watched {
+		if !live[id] {
+			delete(w.watched, id)
+		}
+	}
+	var reap []int
+	if table != nil && w.settings.ReapZombies {
+		reap = w.zombieCandidates(table, tracked)
+	}
+	w.mu.Unlock()
+
+	// 事件与终止动作在锁外执行，处理方可以同步读取快照
+	for _, kill := range kills {
+		kill()
+	}
+	for _, event := range events {
+		if w.handle != nil {
+			w.handle(event)
+		}
+	}
+	for _, pid := range reap {
+		if err := reapZombie(pid); err != nil {
+			w.logger.Debug("reap zombie failed", zap.Int("pid", pid), zap.Error(err))
+			continue
+		}
+		w.mu.Lock()
+		w.reaped++
+		w.mu.Unlock()
+		w.logger.Info("reaped zombie child process", zap.Int("pid", pid))
+	}
+}
+
+// inspect 按进程组采样更新跟踪状态，返回需要上报的事件以及需要立即执行的强制终止
+func (w *WatchdogService) inspect(st *watchState, table map[int]procStat, now time.Time) (*TimeoutEvent, func()) {
+	var cpu time.Duration
+	var written uint64
+	members := 0
+	for _, p := range table {
+		if p.PGID != st.proc.PID || p.State == 'Z' {
+			continue
+		}
+		members++
+		cpu += p.CPUTime
+		written += p.Written
+	}
+	st.members = members
+	if leader, ok := table[st.proc.PID]; ok {
+		st.state = leader.State
+	}
+	if members == 0 {
+		// 进程组已全部退出，等待执行方回收
+		return nil, nil
+	}
+
+	if cpu > st.cpu {
+		st.lastCPU = now
+	}
+	if written > st.written {
+		st.lastOutput = now
+	}
+	st.cpu, st.written = cpu, written
+	cpuIdle, outputIdle := now.Sub(st.lastCPU), now.Sub(st.lastOutput)
+
+	// 已由看门狗终止：超过 CriticalGrace 仍存活则升级为严重超时
+	if !st.terminatedAt.IsZero() {
+		grace := st.proc.Deadline.Critical - st.proc.Deadline.Hard
+		if st.severity == SeverityCritical || now.Sub(st.terminatedAt) < grace {
+			return nil, nil
+		}
+		st.severity = SeverityCritical
+		event := w.event(st, SeverityCritical, now)
+		return &event, func() { st.proc.Terminate(true) }
+	}
+
+	switch {
+	case cpuIdle >= w.settings.StallTimeout && outputIdle >= w.settings.StallTimeout:
+		st.severity, st.escalated = SeverityHard, true
+		st.terminatedAt = now
+		event := w.event(st, SeverityHard, now)
+		event.Terminate = func() { st.proc.Terminate(false) }
+		return &event, nil
+
+	case outputIdle >= w.settings.SilenceTimeout:
+		if st.escalated {
+			return nil, nil
+		}
+		st.severity, st.escalated = SeveritySoft, true
+		event := w.event(st, SeveritySoft, now)
+		event.Progress = func() bool { return cpuIdle < w.settings.Interval*2 }
+		return &event, nil
+
+	default:
+		// 输出恢复后重新计算静默期
+		st.escalated = false
+		return nil, nil
+	}
+}
+
+// event 构造被监视进程的超时事件
+func (w *WatchdogService) event(st *watchState, severity TimeoutSeverity, now time.Time) TimeoutEvent {
+	return TimeoutEvent{
+		TaskID:       st.proc.TaskID,
+		ExecutorType: st.proc.ExecutorType,
+		Severity:     severity,
+		ElapsedTime:  now.Sub(st.proc.StartedAt),
+		Task:         st.proc.Task,
+		Deadline:     st.proc.Deadline,
+	}
+}
+
+// zombieCandidates 返回连续两次巡检都处于僵尸状态、且不属于任何被监视执行的子进程；
+// 被监视进程由执行方的 cmd.Wait 回收，不能在这里抢先回收
+func (w *WatchdogService) zombieCandidates(table map[int]procStat, tracked map[int]bool) []int {
+	self := os.Getpid()
+	current := make(map[int]bool)
+	var reap []int
+	for pid, p := range table {
+		if p.PPID != self || p.State != 'Z' || tracked[pid] {
+			continue
+		}
+		if w.zombies[pid] {
+			reap = append(reap, pid)
+			continue
+		}
+		current[pid] = true
+	}
+	w.zombies = current
+	return reap
+}
+
+// Snapshot 返回当前监视的全部进程，按开始时间排序
+func (w *WatchdogService) Snapshot() WatchdogSnapshot {
+	if w == nil {
+		return WatchdogSnapshot{}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	snapshot := WatchdogSnapshot{
+		CheckedAt:     w.checkedAt,
+		Processes:     make([]WatchedProcessStatus, 0, len(w.watched)),
+		ReapedZombies: w.reaped,
+	}
+	for _, st := range w.watched {
+		status := WatchedProcessStatus{
+			ExecID:       st.proc.ExecID,
+			TaskID:       st.proc.TaskID,
+			ExecutorType: st.proc.ExecutorType,
+			PID:          st.proc.PID,
+			Processes:    st.members,
+			StartedAt:    st.proc.StartedAt,
+			Elapsed:      now.Sub(st.proc.StartedAt),
+			CPUTime:      st.cpu,
+			WrittenBytes: st.written,
+			CPUIdle:      now.Sub(st.lastCPU),
+			OutputIdle:   now.Sub(st.lastOutput),
+			Terminated:   !st.terminatedAt.IsZero(),
+		}
+		if st.proc.Task != nil {
+			status.ScanType = st.proc.Task.ScanType
+		}
+		if st.state != 0 {
+			status.State = string(st.state)
+		}
+		if st.escalated || status.Terminated {
+			status.Severity = st.severity.String()
+		}
+		snapshot.Processes = append(snapshot.Processes, status)
+	}
+	sort.Slice(snapshot.Processes, func(i, j int) bool {
+		return snapshot.Processes[i].StartedAt.Before(snapshot.Processes[j].StartedAt)
+	})
+	return snapshot
+}
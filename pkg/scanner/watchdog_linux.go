@@ -0,0 +1,98 @@
+//go:build linux
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clockTick /proc/<pid>/stat 中 utime/stime 的单位（USER_HZ 固定为100）
+const clockTick = 10 * time.Millisecond
+
+// readProcTable 读取 /proc 下全部进程的状态、CPU 时间与写入字节数，按 PID 索引
+func readProcTable() (map[int]procStat, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[int]procStat, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// 进程可能在遍历期间退出，跳过读取失败的条目
+		p, err := readProcStat(pid)
+		if err != nil {
+			continue
+		}
+		p.Written = readProcWritten(pid)
+		table[pid] = p
+	}
+	return table, nil
+}
+
+// readProcStat 解析 /proc/<pid>/stat；进程名可能包含空格与括号，从最后一个 ')' 之后按字段切分
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return procStat{}, err
+	}
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// 字段依次为 state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt cmajflt utime stime ...
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 13 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	pgid, _ := strconv.Atoi(fields[2])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	return procStat{
+		PID:     pid,
+		PPID:    ppid,
+		PGID:    pgid,
+		State:   fields[0][0],
+		CPUTime: time.Duration(utime+stime) * clockTick,
+	}, nil
+}
+
+// readProcWritten 读取 /proc/<pid>/io 的 wchar（进程写出的字节数，含管道与文件），无权限时返回0
+func readProcWritten(pid int) uint64 {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		if value, ok := strings.CutPrefix(lines.Text(), "wchar:"); ok {
+			n, _ := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// reapZombie 非阻塞地回收僵尸子进程
+func reapZombie(pid int) error {
+	var status syscall.WaitStatus
+	wpid, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil)
+	if err != nil {
+		return err
+	}
+	if wpid != pid {
+		return fmt.Errorf("process %d not reapable yet", pid)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package scanner
+
+import "errors"
+
+var errProcUnsupported = errors.New("process sampling requires /proc")
+
+// readProcTable 非 Linux 平台没有 /proc，看门狗只跟踪进程不做采样
+func readProcTable() (map[int]procStat, error) {
+	return nil, errProcUnsupported
+}
+
+func reapZombie(pid int) error {
+	return errProcUnsupported
+}
@@ -0,0 +1,194 @@
+//go:build linux
+
+package scanner_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/domain"
+	"github.com/blackarbiter/go-sac/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource 以单个子进程作为看门狗的进程来源
+type fakeSource struct {
+	proc scanner.WatchedProcess
+}
+
+func (f *fakeSource) WatchedProcesses() []scanner.WatchedProcess {
+	return []scanner.WatchedProcess{f.proc}
+}
+
+// eventRecorder 记录看门狗事件，硬超时按控制器的方式执行终止
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []scanner.TimeoutEvent
+}
+
+func (r *eventRecorder) handle(e scanner.TimeoutEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	if e.Terminate != nil {
+		e.Terminate()
+	}
+}
+
+func (r *eventRecorder) severities() []scanner.TimeoutSeverity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []scanner.TimeoutSeverity
+	for _, e := range r.events {
+		out = append(out, e.Severity)
+	}
+	return out
+}
+
+func startWatched(t *testing.T, script string) (*exec.Cmd, *fakeSource) {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		_ = cmd.Wait()
+	})
+	task := &domain.ScanTaskPayload{TaskID: "wd-" + t.Name(), ScanType: domain.ScanTypeSca}
+	return cmd, &fakeSource{proc: scanner.WatchedProcess{
+		ExecID:    task.TaskID + "-1",
+		TaskID:    task.TaskID,
+		PID:       cmd.Process.Pid,
+		Task:      task,
+		StartedAt: time.Now(),
+		Deadline:  scanner.TaskDeadline{Hard: time.Minute, Critical: time.Minute + 100*time.Millisecond},
+		Terminate: func(force bool) {
+			sig := syscall.SIGTERM
+			if force {
+				sig = syscall.SIGKILL
+			}
+			_ = syscall.Kill(-cmd.Process.Pid, sig)
+		},
+	}}
+}
+
+func TestWatchdog_StalledProcess(t *testing.T) {
+	recorder := &eventRecorder{}
+	wd := scanner.NewWatchdogService(scanner.WatchdogSettings{
+		SilenceTimeout: 50 * time.Millisecond,
+		StallTimeout:   200 * time.Millisecond,
+	}, recorder.handle)
+
+	// 子进程组既不消耗 CPU 也没有输出
+	cmd, source := startWatched(t, "sleep 30")
+	wd.AddSource(source)
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	wd.Check()
+	snapshot := wd.Snapshot()
+	require.Len(t, snapshot.Processes, 1)
+	status := snapshot.Processes[0]
+	assert.Equal(t, source.proc.TaskID, status.TaskID)
+	assert.Equal(t, cmd.Process.Pid, status.PID)
+	assert.Equal(t, domain.ScanTypeSca, status.ScanType)
+	assert.GreaterOrEqual(t, status.Processes, 1)
+	assert.False(t, status.Terminated)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorder.severities()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(30 * time.Millisecond)
+		wd.Check()
+	}
+	// 先因无输出上报软超时，停滞超过 StallTimeout 后上报硬超时并终止进程组
+	assert.Equal(t, []scanner.TimeoutSeverity{scanner.SeveritySoft, scanner.SeverityHard}, recorder.severities())
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stalled process group was not terminated")
+	}
+
+	wd.Check()
+	assert.Equal(t, "hard", wd.Snapshot().Processes[0].Severity)
+	assert.True(t, wd.Snapshot().Processes[0].Terminated)
+
+	wd.RemoveSource(source)
+	wd.Check()
+	assert.Empty(t, wd.Snapshot().Processes)
+}
+
+func TestWatchdog_IgnoresTermEscalatesToCritical(t *testing.T) {
+	recorder := &eventRecorder{}
+	wd := scanner.NewWatchdogService(scanner.WatchdogSettings{
+		SilenceTimeout: 50 * time.Millisecond,
+		StallTimeout:   100 * time.Millisecond,
+	}, recorder.handle)
+
+	// 忽略 SIGTERM，终止后超过 CriticalGrace 仍存活
+	cmd, source := startWatched(t, "trap '' TERM; sleep 30")
+	wd.AddSource(source)
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorder.severities()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(30 * time.Millisecond)
+		wd.Check()
+	}
+	assert.Equal(t, []scanner.TimeoutSeverity{scanner.SeveritySoft, scanner.SeverityHard, scanner.SeverityCritical}, recorder.severities())
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("critical timeout should force kill the process group")
+	}
+}
+
+func TestWatchdog_BusyProcessIsNotStalled(t *testing.T) {
+	recorder := &eventRecorder{}
+	wd := scanner.NewWatchdogService(scanner.WatchdogSettings{
+		SilenceTimeout: 50 * time.Millisecond,
+		StallTimeout:   150 * time.Millisecond,
+	}, recorder.handle)
+
+	// 持续消耗 CPU 但没有输出，只上报一次软超时
+	_, source := startWatched(t, "while :; do :; done")
+	wd.AddSource(source)
+	for i := 0; i < 10; i++ {
+		time.Sleep(50 * time.Millisecond)
+		wd.Check()
+	}
+	assert.Equal(t, []scanner.TimeoutSeverity{scanner.SeveritySoft}, recorder.severities())
+	status := wd.Snapshot().Processes[0]
+	assert.Positive(t, status.CPUTime)
+	assert.Equal(t, "soft", status.Severity)
+}
+
+func TestWatchdog_ReapsUntrackedZombies(t *testing.T) {
+	wd := scanner.NewWatchdogService(scanner.WatchdogSettings{ReapZombies: true}, nil)
+
+	// 启动后不调用 Wait 的子进程退出后成为僵尸
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Start())
+	pid := cmd.Process.Pid
+	statPath := fmt.Sprintf("/proc/%d/stat", pid)
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(statPath)
+		return err == nil && strings.Contains(string(data), ") Z ")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// 首次发现只记录，连续两次处于僵尸状态才回收
+	wd.Check()
+	_, err := os.Stat(statPath)
+	require.NoError(t, err)
+	wd.Check()
+	_, err = os.Stat(statPath)
+	assert.True(t, os.IsNotExist(err), "zombie should be reaped")
+	assert.EqualValues(t, 1, wd.Snapshot().ReapedZombies)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/metrics"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 调度器的优先级，下标越小优先级越高
+const (
+	classHigh = iota
+	classMedium
+	classLow
+	classCount
+)
+
+var classNames = [classCount]string{"high", "medium", "low"}
+
+// queuedDelivery 调度器中等待处理的消息
+type queuedDelivery struct {
+	msg      amqp.Delivery
+	priority int       // 进入调度器时的优先级
+	tenant   string    // 所属租户，为空时归入匿名租户
+	enqueued time.Time // 进入调度器的时间，用于统计等待时间
+	since    time.Time // 进入当前优先级的时间，用于老化提升
+}
+
+// tenantOf 从扫描任务载荷中取租户：优先按资产所属组织，其次按用户，两者均由任务服务在服务端确定
+func tenantOf(body []byte) string {
+	var p struct {
+		UserID         uint `json:"user_id"`
+		OrganizationID uint `json:"organization_id"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return ""
+	}
+	switch {
+	case p.OrganizationID > 0:
+		return "org:" + strconv.FormatUint(uint64(p.OrganizationID), 10)
+	case p.UserID > 0:
+		return "user:" + strconv.FormatUint(uint64(p.UserID), 10)
+	}
+	return ""
+}
+
+// priorityClass 同一优先级的等待队列，按租户轮转出队
+type priorityClass struct {
+	quantum float64 // 每轮分配的调度份额
+	deficit float64 // 尚未用完的份额
+	granted bool    // 本轮已分配份额
+	tenants map[string][]*queuedDelivery
+	order   []string // 有等待任务的租户，按轮转顺序排列
+	size    int
+}
+
+func (c *priorityClass) push(d *queuedDelivery) {
+	if len(c.tenants[d.tenant]) == 0 {
+		c.order = append(c.order, d.tenant)
+	}
+	c.tenants[d.tenant] = append(c.tenants[d.tenant], d)
+	c.size++
+}
+
+// pop 取轮转到的租户的第一个任务，该租户还有任务时排到队尾
+func (c *priorityClass) pop() *queuedDelivery {
+	tenant := c.order[0]
+	c.order = c.order[1:]
+	q := c.tenants[tenant]
+	d := q[0]
+	q[0] = nil
+	if len(q) > 1 {
+		c.tenants[tenant] = q[1:]
+		c.order = append(c.order, tenant)
+	} else {
+		delete(c.tenants, tenant)
+	}
+	c.size--
+	if c.size == 0 {
+		c.deficit, c.granted = 0, false
+	}
+	return d
+}
+
+// expire 取出在本优先级等待超过 cutoff 的任务，各租户队列按进入时间有序，只需检查队首
+func (c *priorityClass) expire(cutoff time.Time) []*queuedDelivery {
+	var out []*queuedDelivery
+	order := c.order[:0]
+	for _, tenant := range c.order {
+		q := c.tenants[tenant]
+		n := 0
+		for n < len(q) && q[n].since.Before(cutoff) {
+			out = append(out, q[n])
+			q[n] = nil
+			n++
+		}
+		if n == len(q) {
+			delete(c.tenants, tenant)
+			continue
+		}
+		c.tenants[tenant] = q[n:]
+		order = append(order, tenant)
+	}
+	c.order = order
+	c.size -= len(out)
+	if c.size == 0 {
+		c.deficit, c.granted = 0, false
+	}
+	return out
+}
+
+// fairQueue 按权重在优先级之间做差额轮转（deficit round robin），优先级内按租户轮转；
+// 等待超过老化时长的任务提升一级优先级。只由调度协程访问，不加锁
+type fairQueue struct {
+	classes [classCount]*priorityClass
+	cursor  int
+	aging   time.Duration
+	pending [classCount]int // 按进入时的优先级统计的等待任务数，用于限制从通道取出的数量
+}
+
+// newFairQueue 按权重创建队列：权重最小的优先级每轮处理 1 个任务，其它按比例放大；权重为 0 的优先级只在其它优先级为空时处理
+func newFairQueue(weights map[string]float64, aging time.Duration) *fairQueue {
+	minWeight := 0.0
+	for _, name := range classNames {
+		if w := weights[name]; w > 0 && (minWeight == 0 || w < minWeight) {
+			minWeight = w
+		}
+	}
+	q := &fairQueue{aging: aging}
+	for i, name := range classNames {
+		c := &priorityClass{tenants: make(map[string][]*queuedDelivery)}
+		if w := weights[name]; w > 0 {
+			c.quantum = w / minWeight
+		}
+		q.classes[i] = c
+	}
+	return q
+}
+
+func (q *fairQueue) len() int {
+	n := 0
+	for _, c := range q.classes {
+		n += c.size
+	}
+	return n
+}
+
+func (q *fairQueue) push(d *queuedDelivery) {
+	q.classes[d.priority].push(d)
+	q.pending[d.priority]++
+}
+
+// pop 按差额轮转选出下一个任务，队列为空时返回 nil
+func (q *fairQueue) pop(now time.Time) *queuedDelivery {
+	if q.len() == 0 {
+		return nil
+	}
+	q.promote(now)
+	d := q.next()
+	q.pending[d.priority]--
+	return d
+}
+
+func (q *fairQueue) next() *queuedDelivery {
+	// 每个非空且权重大于 0 的优先级在一轮内至少处理一个任务，两轮内必然选出
+	for i := 0; i < 2*classCount; i++ {
+		c := q.classes[q.cursor]
+		if c.size > 0 && c.quantum > 0 {
+			if !c.granted {
+				c.deficit += c.quantum
+				c.granted = true
+			}
+			if c.deficit >= 1 {
+				c.deficit--
+				return c.pop()
+			}
+		}
+		c.granted = false
+		q.cursor = (q.cursor + 1) % classCount
+	}
+	for _, c := range q.classes {
+		if c.size > 0 {
+			return c.pop()
+		}
+	}
+	return nil
+}
+
+// promote 把等待超过老化时长的任务提升一级，提升后重新计时
+func (q *fairQueue) promote(now time.Time) {
+	if q.aging <= 0 {
+		return
+	}
+	cutoff := now.Add(-q.aging)
+	for i := classHigh + 1; i < classCount; i++ {
+		for _, d := range q.classes[i].expire(cutoff) {
+			d.since = now
+			q.classes[i-1].push(d)
+			metrics.ScanSchedulerPromotions.WithLabelValues(classNames[i], classNames[i-1]).Inc()
+		}
+	}
+}
+
+// recordDepth 更新各优先级的等待任务数指标
+func (q *fairQueue) recordDepth() {
+	for i, c := range q.classes {
+		metrics.ScanSchedulerQueueDepth.WithLabelValues(classNames[i]).Set(float64(c.size))
+	}
+}
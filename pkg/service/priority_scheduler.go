@@ -2,19 +2,19 @@ package service
 
 import (
 	"context"
-	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/blackarbiter/go-sac/pkg/config"
 	"github.com/blackarbiter/go-sac/pkg/errors"
 	"github.com/blackarbiter/go-sac/pkg/logger"
+	"github.com/blackarbiter/go-sac/pkg/metrics"
 	"github.com/blackarbiter/go-sac/pkg/mq"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
-// PriorityScheduler 新增优先级调度器结构体
+// PriorityScheduler 优先级调度器：消费者把消息投递到各优先级通道，调度器按权重公平地取出处理
 type PriorityScheduler struct {
 	HighPriorityChan chan amqp.Delivery
 	MedPriorityChan  chan amqp.Delivery
@@ -23,7 +23,7 @@ type PriorityScheduler struct {
 	Handler          mq.MessageHandler
 	Mu               sync.Mutex   // 状态锁
 	State            *SystemState // 系统状态管理器
-	priorityWeights  map[string]float64
+	queue            *fairQueue
 }
 
 // NewPriorityScheduler creates a new PriorityScheduler instance
@@ -36,87 +36,90 @@ func NewPriorityScheduler(handler mq.MessageHandler, state *SystemState, cfg *co
 		LowPriorityChan:  make(chan amqp.Delivery, channelCapacity["low"]),
 		Handler:          handler,
 		State:            state,
-		priorityWeights:  priorityWeights,
+		queue:            newFairQueue(priorityWeights, cfg.GetPriorityAgingThreshold()),
 	}
 }
 
+// Start 调度循环：没有等待的任务时阻塞在通道上，背压时暂停处理
 func (s *PriorityScheduler) Start(ctx context.Context) {
-	const (
-		normalSleep  = 100 * time.Millisecond
-		backoffSleep = 500 * time.Millisecond
-	)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			// 背压状态检查
-			if s.State.ShouldStopProcessing() {
-				time.Sleep(backoffSleep)
-				continue
-			}
+	const backoffSleep = 500 * time.Millisecond
 
-			// 精确获取各通道消息数（避免竞态）
-			highCount := len(s.HighPriorityChan)
-			medCount := len(s.MedPriorityChan)
-			lowCount := len(s.LowPriorityChan)
+	for ctx.Err() == nil {
+		s.intake()
+		if s.queue.len() == 0 {
+			s.wait(ctx)
+			continue
+		}
 
-			totalWeight := 0
-			if highCount > 0 {
-				totalWeight += int(s.priorityWeights["high"] * 100)
-			}
-			if medCount > 0 {
-				totalWeight += int(s.priorityWeights["medium"] * 100)
-			}
-			if lowCount > 0 {
-				totalWeight += int(s.priorityWeights["low"] * 100)
+		// 背压状态检查
+		if s.State.ShouldStopProcessing() {
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoffSleep):
 			}
+			continue
+		}
 
-			if totalWeight == 0 {
-				time.Sleep(normalSleep)
-				continue
-			}
+		d := s.queue.pop(time.Now())
+		s.queue.recordDepth()
+		wait := time.Since(d.enqueued)
+		metrics.ScanSchedulerQueueWait.WithLabelValues(classNames[d.priority]).Observe(wait.Seconds())
+		logger.Logger.Debug("Priority Scheduler dispatch",
+			zap.String("priority", classNames[d.priority]),
+			zap.String("tenant", d.tenant),
+			zap.Duration("wait", wait))
+		s.processWithPriority(ctx, d.msg, classNames[d.priority])
+	}
+}
 
-			// 生成随机数决定本次处理的优先级
-			r := rand.Intn(totalWeight)
-			logger.Logger.Info("Priority Scheduler",
-				zap.Int("High Count", highCount),
-				zap.Int("Medium Count", medCount),
-				zap.Int("Low Count", lowCount),
-				zap.Int("Total Weight", totalWeight),
-				zap.Int("Random Number", r))
-			var msg amqp.Delivery
-
-			// 根据权重选择通道并尝试非阻塞读取
-			highWeight := int(s.priorityWeights["high"] * 100)
-			medWeight := int(s.priorityWeights["medium"] * 100)
-
-			switch {
-			case highCount > 0 && r < highWeight:
-				select {
-				case msg = <-s.HighPriorityChan:
-					s.processWithPriority(ctx, msg, "high")
-				default:
-					time.Sleep(normalSleep)
-				}
-			case medCount > 0 && r < (highWeight+medWeight):
-				select {
-				case msg = <-s.MedPriorityChan:
-					s.processWithPriority(ctx, msg, "medium")
-				default:
-					time.Sleep(normalSleep)
-				}
-			case lowCount > 0:
-				select {
-				case msg = <-s.LowPriorityChan:
-					s.processWithPriority(ctx, msg, "low")
-				default:
-					time.Sleep(normalSleep)
-				}
-			}
+// channels 按优先级排列的通道
+func (s *PriorityScheduler) channels() [classCount]chan amqp.Delivery {
+	return [classCount]chan amqp.Delivery{s.HighPriorityChan, s.MedPriorityChan, s.LowPriorityChan}
+}
+
+// intake 非阻塞地把通道中的消息移入调度队列；每个优先级最多取出通道容量个，
+// 其余消息留在通道中，使消费者在通道满时仍按原有方式背压
+func (s *PriorityScheduler) intake() {
+	for i, ch := range s.channels() {
+		for s.queue.pending[i] < max(cap(ch), 1) && s.tryEnqueue(i, ch) {
 		}
 	}
+	s.queue.recordDepth()
+}
+
+func (s *PriorityScheduler) tryEnqueue(priority int, ch chan amqp.Delivery) bool {
+	select {
+	case msg := <-ch:
+		s.enqueue(priority, msg)
+		return true
+	default:
+		return false
+	}
+}
+
+// wait 调度队列为空时阻塞到任一通道有消息或任务取消
+func (s *PriorityScheduler) wait(ctx context.Context) {
+	chans := s.channels()
+	select {
+	case <-ctx.Done():
+	case msg := <-chans[classHigh]:
+		s.enqueue(classHigh, msg)
+	case msg := <-chans[classMedium]:
+		s.enqueue(classMedium, msg)
+	case msg := <-chans[classLow]:
+		s.enqueue(classLow, msg)
+	}
+}
+
+func (s *PriorityScheduler) enqueue(priority int, msg amqp.Delivery) {
+	now := time.Now()
+	s.queue.push(&queuedDelivery{
+		msg:      msg,
+		priority: priority,
+		tenant:   tenantOf(msg.Body),
+		enqueued: now,
+		since:    now,
+	})
 }
 
 func (s *PriorityScheduler) processWithPriority(ctx context.Context, msg amqp.Delivery, priority string) {
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blackarbiter/go-sac/pkg/config"
+	"github.com/blackarbiter/go-sac/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func queued(priority int, tenant string, at time.Time) *queuedDelivery {
+	return &queuedDelivery{
+		msg:      amqp.Delivery{Body: []byte(fmt.Sprintf("%s/%d", tenant, priority))},
+		priority: priority,
+		tenant:   tenant,
+		enqueued: at,
+		since:    at,
+	}
+}
+
+func TestFairQueue_WeightedShares(t *testing.T) {
+	now := time.Now()
+	q := newFairQueue(map[string]float64{"high": 0.6, "medium": 0.3, "low": 0.1}, 0)
+	for i := 0; i < 100; i++ {
+		for p := classHigh; p < classCount; p++ {
+			q.push(queued(p, "", now))
+		}
+	}
+
+	var served [classCount]int
+	for i := 0; i < 100; i++ {
+		served[q.pop(now).priority]++
+	}
+	assert.InDelta(t, 60, served[classHigh], 1)
+	assert.InDelta(t, 30, served[classMedium], 1)
+	assert.InDelta(t, 10, served[classLow], 1)
+
+	// 高优先级耗尽后其余优先级独占处理能力
+	for q.len() > 0 {
+		q.pop(now)
+	}
+	assert.Equal(t, [classCount]int{}, q.pending)
+	assert.Nil(t, q.pop(now))
+}
+
+func TestFairQueue_TenantRoundRobin(t *testing.T) {
+	now := time.Now()
+	q := newFairQueue(map[string]float64{"high": 1, "medium": 1, "low": 1}, 0)
+	for i := 0; i < 4; i++ {
+		q.push(queued(classMedium, "org:1", now))
+	}
+	q.push(queued(classMedium, "user:7", now))
+	q.push(queued(classMedium, "org:2", now))
+
+	var tenants []string
+	for q.len() > 0 {
+		tenants = append(tenants, q.pop(now).tenant)
+	}
+	assert.Equal(t, []string{"org:1", "user:7", "org:2", "org:1", "org:1", "org:1"}, tenants)
+}
+
+func TestFairQueue_AgingPreventsStarvation(t *testing.T) {
+	start := time.Now()
+	aging := time.Minute
+	// 只给高优先级分配权重，低优先级任务只能通过老化得到处理
+	q := newFairQueue(map[string]float64{"high": 1}, aging)
+	q.push(queued(classLow, "user:2", start))
+	for i := 0; i < 10; i++ {
+		q.push(queued(classHigh, "user:1", start))
+	}
+
+	assert.Equal(t, classHigh, q.pop(start).priority)
+	assert.Equal(t, classHigh, q.pop(start.Add(aging)).priority)
+
+	// 第一次老化提升到中优先级，重新计时
+	assert.Equal(t, classHigh, q.pop(start.Add(aging+time.Second)).priority)
+	assert.Equal(t, 1, q.classes[classMedium].size)
+
+	// 第二次老化提升到高优先级，与其它租户轮转
+	at := start.Add(2*aging + 2*time.Second)
+	assert.Equal(t, "user:1", q.pop(at).tenant)
+	d := q.pop(at)
+	assert.Equal(t, "user:2", d.tenant)
+	assert.Equal(t, classLow, d.priority, "wait time is reported under the original priority")
+	assert.Equal(t, 0, q.pending[classLow])
+}
+
+func TestTenantOf(t *testing.T) {
+	assert.Equal(t, "org:3", tenantOf([]byte(`{"task_id": "t", "user_id": 9, "organization_id": 3}`)))
+	assert.Equal(t, "user:9", tenantOf([]byte(`{"task_id": "t", "user_id": 9}`)))
+	assert.Equal(t, "", tenantOf([]byte(`{"task_id": "t"}`)))
+	assert.Equal(t, "", tenantOf([]byte(`not json`)))
+}
+
+type recordingHandler struct {
+	mu     sync.Mutex
+	bodies []string
+	done   chan struct{}
+	want   int
+}
+
+func (h *recordingHandler) HandleMessage(_ context.Context, message []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bodies = append(h.bodies, string(message))
+	if len(h.bodies) == h.want {
+		close(h.done)
+	}
+	return nil
+}
+
+func TestPriorityScheduler_Start(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	cfg := &config.Config{}
+	cfg.Scanner.PriorityScheduler.ChannelCapacity.High = 2
+	cfg.Scanner.PriorityScheduler.ChannelCapacity.Medium = 2
+	cfg.Scanner.PriorityScheduler.ChannelCapacity.Low = 2
+
+	handler := &recordingHandler{done: make(chan struct{}), want: 5}
+	s := NewPriorityScheduler(handler, NewSystemState(), cfg)
+	s.LowPriorityChan <- amqp.Delivery{Body: []byte("low-1")}
+	s.HighPriorityChan <- amqp.Delivery{Body: []byte("high-1")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(stopped)
+	}()
+
+	// 空闲时阻塞在通道上，新消息到达后立即处理
+	s.MedPriorityChan <- amqp.Delivery{Body: []byte("medium-1")}
+	s.LowPriorityChan <- amqp.Delivery{Body: []byte("low-2")}
+	s.HighPriorityChan <- amqp.Delivery{Body: []byte("high-2")}
+
+	select {
+	case <-handler.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not process all messages")
+	}
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after cancel")
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	require.Len(t, handler.bodies, 5)
+	assert.ElementsMatch(t, []string{"low-1", "high-1", "medium-1", "low-2", "high-2"}, handler.bodies)
+	assert.Equal(t, "high-1", handler.bodies[0], "high priority is served first when both are waiting")
+}
@@ -60,3 +60,8 @@ func (s *Storage) GetPresignedURL(ctx context.Context, path string, expiry time.
 	}
 	return url.String(), nil
 }
+
+// DownloadFile 下载对象到本地文件
+func (s *Storage) DownloadFile(ctx context.Context, path, dest string) error {
+	return s.client.FGetObject(ctx, s.bucketName, path, dest, minio.GetObjectOptions{})
+}
@@ -10,6 +10,11 @@ import (
 
 // Encrypt 使用AES-GCM加密数据
 func Encrypt(plaintext []byte, key []byte) ([]byte, error) {
+	return EncryptWithAAD(plaintext, key, nil)
+}
+
+// EncryptWithAAD 使用AES-GCM加密数据，aad 作为附加数据参与认证，解密时必须提供相同的 aad
+func EncryptWithAAD(plaintext []byte, key []byte, aad []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -25,11 +30,16 @@ func Encrypt(plaintext []byte, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
 }
 
 // Decrypt 使用AES-GCM解密数据
 func Decrypt(ciphertext []byte, key []byte) ([]byte, error) {
+	return DecryptWithAAD(ciphertext, key, nil)
+}
+
+// DecryptWithAAD 使用AES-GCM解密数据并校验附加数据
+func DecryptWithAAD(ciphertext []byte, key []byte, aad []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -46,5 +56,5 @@ func Decrypt(ciphertext []byte, key []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return gcm.Open(nil, nonce, ciphertext, aad)
 }
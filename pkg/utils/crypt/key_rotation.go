@@ -64,3 +64,10 @@ func (km *KeyManager) DecryptWithHistory(ciphertext []byte) ([]byte, error) {
 	}
 	return nil, errors.New("decryption failed with all keys")
 }
+
+// Encrypt 使用当前密钥加密
+func (km *KeyManager) Encrypt(plaintext []byte) ([]byte, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return Encrypt(plaintext, km.currentKey)
+}
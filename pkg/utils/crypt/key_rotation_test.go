@@ -52,3 +52,50 @@ func TestKeyManager(t *testing.T) {
 	// 可以添加更多验证逻辑，例如测试解密超过历史限制的旧密钥时应该失败
 	// 但是这需要调整KeyManager的设计，使其更容易测试
 }
+
+func TestKeyManagerSealString(t *testing.T) {
+	km := crypt.NewKeyManager([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+
+	sealed, err := km.SealString("s3cret")
+	if err != nil {
+		t.Fatal("加密失败:", err)
+	}
+	if !crypt.IsSealed(sealed) || sealed == "s3cret" {
+		t.Fatalf("unexpected sealed value %q", sealed)
+	}
+
+	// 密文只能由服务端生成，已加密的值被拒绝
+	if _, err := km.SealString(sealed); err != crypt.ErrAlreadySealed {
+		t.Errorf("sealed value should be rejected, got %v", err)
+	}
+
+	plaintext, err := km.OpenString(sealed)
+	if err != nil || plaintext != "s3cret" {
+		t.Errorf("OpenString = %q, %v", plaintext, err)
+	}
+
+	if _, err := km.OpenString("s3cret"); err != crypt.ErrNotSealed {
+		t.Errorf("plaintext should be rejected, got %v", err)
+	}
+}
+
+func TestKeyManagerSealStringBound(t *testing.T) {
+	km := crypt.NewKeyManager([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+
+	sealed, err := km.SealStringBound("s3cret", "https://app.example.com")
+	if err != nil {
+		t.Fatal("加密失败:", err)
+	}
+	plaintext, err := km.OpenStringBound(sealed, "https://app.example.com")
+	if err != nil || plaintext != "s3cret" {
+		t.Errorf("OpenStringBound = %q, %v", plaintext, err)
+	}
+
+	// 绑定到其它目标或未绑定时无法解密
+	if _, err := km.OpenStringBound(sealed, "https://evil.example.com"); err == nil {
+		t.Error("value bound to another origin should not be opened")
+	}
+	if _, err := km.OpenString(sealed); err == nil {
+		t.Error("bound value should not be opened without its binding")
+	}
+}
@@ -0,0 +1,67 @@
+package crypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// SealedPrefix 标记经 KeyManager 加密并 base64 编码的字符串，用于在 JSON 选项中保存凭据
+const SealedPrefix = "enc:"
+
+var (
+	// ErrNotSealed 字符串不是加密后的格式
+	ErrNotSealed = errors.New("value is not sealed")
+	// ErrAlreadySealed 待加密的字符串已是密文，密文只能由服务端生成，不接受客户端提交
+	ErrAlreadySealed = errors.New("value is already sealed")
+)
+
+// IsSealed 判断字符串是否已加密
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, SealedPrefix)
+}
+
+// SealString 加密字符串，不绑定用途
+func (km *KeyManager) SealString(s string) (string, error) {
+	return km.SealStringBound(s, "")
+}
+
+// OpenString 解密 SealString 生成的字符串，依次尝试当前和历史密钥
+func (km *KeyManager) OpenString(s string) (string, error) {
+	return km.OpenStringBound(s, "")
+}
+
+// SealStringBound 加密字符串并绑定到 binding（如凭据所属的目标 origin），
+// binding 作为 GCM 附加数据参与认证，只能以相同的 binding 解密；已加密的字符串返回 ErrAlreadySealed
+func (km *KeyManager) SealStringBound(s, binding string) (string, error) {
+	if IsSealed(s) {
+		return "", ErrAlreadySealed
+	}
+	km.mu.RLock()
+	ciphertext, err := EncryptWithAAD([]byte(s), km.currentKey, []byte(binding))
+	km.mu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+	return SealedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// OpenStringBound 解密 SealStringBound 生成的字符串，依次尝试当前和历史密钥，binding 不一致时失败
+func (km *KeyManager) OpenStringBound(s, binding string) (string, error) {
+	if !IsSealed(s) {
+		return "", ErrNotSealed
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, SealedPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	for _, key := range append([][]byte{km.currentKey}, km.previousKeys...) {
+		if plaintext, err := DecryptWithAAD(ciphertext, key, []byte(binding)); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", errors.New("decryption failed with all keys")
+}